@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// AdminMigrateDashboardsSchema upgrades the stored dashboards to the latest schema version,
+// in all organizations or in the one given by the orgId query parameter.
+func (hs *HTTPServer) AdminMigrateDashboardsSchema(c *models.ReqContext) response.Response {
+	cmd := models.MigrateDashboardsSchemaCommand{OrgId: c.QueryInt64("orgId")}
+	if err := hs.dashboardService.MigrateDashboardsSchema(c.Req.Context(), &cmd); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to migrate dashboards", err)
+	}
+
+	return response.JSON(http.StatusOK, cmd.Result)
+}
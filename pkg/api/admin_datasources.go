@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources/references"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// AdminMigrateDataSourceReferences rewrites the references to a data source in the dashboards, library panels,
+// alert rules and query history of the current organization.
+func (hs *HTTPServer) AdminMigrateDataSourceReferences(c *models.ReqContext) response.Response {
+	cmd := references.MigrateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+	cmd.User = c.SignedInUser
+
+	result, err := hs.dataSourceReferencesService.Migrate(c.Req.Context(), &cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrDataSourceNotFound):
+			return response.Error(http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, references.ErrMissingSource), errors.Is(err, references.ErrMissingTarget),
+			errors.Is(err, references.ErrConflictingTargets), errors.Is(err, references.ErrSameDataSource),
+			errors.Is(err, references.ErrInvalidVariable):
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to migrate data source references", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/admin/sessions
+func (hs *HTTPServer) AdminSearchUserSessions(c *models.ReqContext) response.Response {
+	perPage := c.QueryInt("perpage")
+	if perPage <= 0 {
+		perPage = 100
+	}
+	page := c.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+
+	query := &models.SearchUserSessionsQuery{
+		UserId:     c.QueryInt64("userId"),
+		OrgId:      c.QueryInt64("orgId"),
+		Query:      c.Query("query"),
+		SeenBefore: c.QueryInt64("seenBefore"),
+		SeenAfter:  c.QueryInt64("seenAfter"),
+		Page:       page,
+		Limit:      perPage,
+	}
+
+	if err := hs.AuthTokenService.SearchUserSessions(c.Req.Context(), query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search user sessions", err)
+	}
+
+	var activeTokenID int64
+	if c.UserToken != nil {
+		activeTokenID = c.UserToken.Id
+	}
+
+	result := dtos.SearchUserSessionsResult{
+		TotalCount: query.Result.TotalCount,
+		Sessions:   make([]*dtos.UserSession, 0, len(query.Result.Sessions)),
+		Page:       page,
+		PerPage:    perPage,
+	}
+	for _, session := range query.Result.Sessions {
+		result.Sessions = append(result.Sessions, &dtos.UserSession{
+			UserToken: *userTokenToDTO(&session.UserToken, activeTokenID),
+			UserId:    session.UserId,
+			Login:     session.Login,
+			Email:     session.Email,
+			Name:      session.Name,
+		})
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// POST /api/admin/sessions/revoke
+func (hs *HTTPServer) AdminRevokeUserSessions(c *models.ReqContext) response.Response {
+	form := dtos.RevokeUserSessionsForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd := models.RevokeUserSessionsCmd{
+		UserId: form.UserId,
+		OrgId:  form.OrgId,
+	}
+
+	if form.OlderThan != "" {
+		olderThan, err := gtime.ParseDuration(form.OlderThan)
+		if err != nil || olderThan <= 0 {
+			return response.Error(http.StatusBadRequest, "olderThan is not a valid duration", err)
+		}
+		cmd.CreatedBefore = time.Now().Add(-olderThan).Unix()
+	}
+
+	// never log out the admin performing the revocation
+	if c.UserToken != nil {
+		cmd.ExcludeTokenId = c.UserToken.Id
+	}
+
+	if err := hs.AuthTokenService.RevokeUserSessions(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, models.ErrRevokeSessionsFilterMissing) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to revoke user sessions", err)
+	}
+
+	var userUID string
+	if cmd.UserId > 0 {
+		userUID = strconv.FormatInt(cmd.UserId, 10)
+	}
+	hs.logAuditEvent(c, audit.NewEvent(c, audit.ActionSessionsBulkRevoke, audit.KindUser, userUID).WithChange(nil, util.DynMap{
+		"userId":        cmd.UserId,
+		"orgId":         cmd.OrgId,
+		"createdBefore": cmd.CreatedBefore,
+		"count":         cmd.Result,
+	}))
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "User sessions revoked",
+		"count":   cmd.Result,
+	})
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/audit/audittest"
+	"github.com/grafana/grafana/pkg/services/auth"
+)
+
+func TestAdminSessionsAPIEndpoint(t *testing.T) {
+	t.Run("When searching user sessions", func(t *testing.T) {
+		adminSessionsScenario(t, "Should return sessions with user information", &models.UserToken{Id: 1}, nil, func(sc *scenarioContext) {
+			var query *models.SearchUserSessionsQuery
+			sc.userAuthTokenService.SearchUserSessionsProvider = func(ctx context.Context, q *models.SearchUserSessionsQuery) error {
+				query = q
+				q.Result = models.SearchUserSessionsQueryResult{
+					TotalCount: 2,
+					Sessions: []*models.UserSession{
+						{
+							UserToken: models.UserToken{Id: 1, UserId: 2, ClientIp: "127.0.0.1", CreatedAt: time.Now().Unix()},
+							Login:     "alice",
+						},
+					},
+				}
+				return nil
+			}
+
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"orgId": "3", "query": "ali", "perpage": "1"}).exec()
+			assert.Equal(t, 200, sc.resp.Code)
+
+			assert.Equal(t, int64(3), query.OrgId)
+			assert.Equal(t, "ali", query.Query)
+			assert.Equal(t, 1, query.Limit)
+			assert.Equal(t, 1, query.Page)
+
+			result := sc.ToJSON()
+			assert.Equal(t, int64(2), result.Get("totalCount").MustInt64())
+			session := result.Get("sessions").GetIndex(0)
+			assert.Equal(t, "alice", session.Get("login").MustString())
+			assert.Equal(t, int64(2), session.Get("userId").MustInt64())
+			assert.Equal(t, "127.0.0.1", session.Get("clientIp").MustString())
+			assert.True(t, session.Get("isActive").MustBool())
+		})
+	})
+
+	t.Run("When revoking user sessions", func(t *testing.T) {
+		form := &dtos.RevokeUserSessionsForm{OrgId: 3, OlderThan: "7d"}
+		adminSessionsScenario(t, "Should revoke sessions but the caller's", &models.UserToken{Id: 5}, form, func(sc *scenarioContext) {
+			var cmd *models.RevokeUserSessionsCmd
+			sc.userAuthTokenService.RevokeUserSessionsProvider = func(ctx context.Context, c *models.RevokeUserSessionsCmd) error {
+				cmd = c
+				c.Result = 4
+				return nil
+			}
+
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 200, sc.resp.Code)
+			assert.Equal(t, int64(4), sc.ToJSON().Get("count").MustInt64())
+
+			assert.Equal(t, int64(3), cmd.OrgId)
+			assert.Equal(t, int64(5), cmd.ExcludeTokenId)
+			assert.InDelta(t, time.Now().Add(-7*24*time.Hour).Unix(), cmd.CreatedBefore, 5)
+
+			require.Len(t, sc.auditService.Events, 1)
+			assert.Equal(t, audit.ActionSessionsBulkRevoke, sc.auditService.Events[0].Action)
+			assert.Equal(t, testUserID, sc.auditService.Events[0].ActorID)
+		})
+
+		form = &dtos.RevokeUserSessionsForm{OlderThan: "not a duration"}
+		adminSessionsScenario(t, "Should return bad request for invalid age", nil, form, func(sc *scenarioContext) {
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+
+		adminSessionsScenario(t, "Should return bad request without filters", nil, &dtos.RevokeUserSessionsForm{}, func(sc *scenarioContext) {
+			sc.userAuthTokenService.RevokeUserSessionsProvider = func(ctx context.Context, c *models.RevokeUserSessionsCmd) error {
+				return models.ErrRevokeSessionsFilterMissing
+			}
+
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+	})
+}
+
+func adminSessionsScenario(t *testing.T, desc string, token *models.UserToken, form *dtos.RevokeUserSessionsForm, fn scenarioFunc) {
+	t.Run(desc, func(t *testing.T) {
+		fakeAuthTokenService := auth.NewFakeUserAuthTokenService()
+		fakeAuditService := audittest.NewFakeAuditService()
+
+		hs := HTTPServer{
+			AuthTokenService: fakeAuthTokenService,
+			auditService:     fakeAuditService,
+		}
+
+		sc := setupScenarioContext(t, "/")
+		sc.userAuthTokenService = fakeAuthTokenService
+		sc.auditService = fakeAuditService
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserId = testUserID
+			sc.context.OrgId = testOrgID
+			sc.context.IsGrafanaAdmin = true
+			sc.context.UserToken = token
+
+			if form != nil {
+				c.Req.Body = mockRequestBody(form)
+				return hs.AdminRevokeUserSessions(c)
+			}
+			return hs.AdminSearchUserSessions(c)
+		})
+
+		sc.m.Get("/", sc.defaultHandler)
+		sc.m.Post("/", sc.defaultHandler)
+
+		fn(sc)
+	})
+}
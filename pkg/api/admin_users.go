@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -204,7 +205,12 @@ func (hs *HTTPServer) AdminLogoutUser(c *models.ReqContext) response.Response {
 		return response.Error(400, "You cannot logout yourself", nil)
 	}
 
-	return hs.logoutUserFromAllDevicesInternal(c.Req.Context(), userID)
+	resp := hs.logoutUserFromAllDevicesInternal(c.Req.Context(), userID)
+	if resp.Status() == http.StatusOK {
+		hs.logAuditEvent(c, audit.NewEvent(c, audit.ActionSessionsBulkRevoke, audit.KindUser, strconv.FormatInt(userID, 10)))
+	}
+
+	return resp
 }
 
 // GET /api/admin/users/:id/auth-tokens
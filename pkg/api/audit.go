@@ -0,0 +1,59 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// logAuditEvent records an action performed through the API in the audit log.
+func (hs *HTTPServer) logAuditEvent(c *models.ReqContext, event *audit.Event) {
+	if hs.auditService == nil {
+		return
+	}
+	hs.auditService.Log(c.Req.Context(), event)
+}
+
+// aclAuditSummary reduces dashboard and folder permissions to the fields that
+// can be changed through the API.
+func aclAuditSummary(acl []*models.DashboardAclInfoDTO) []dtos.DashboardAclUpdateItem {
+	items := make([]dtos.DashboardAclUpdateItem, 0, len(acl))
+	for _, item := range acl {
+		items = append(items, dtos.DashboardAclUpdateItem{
+			UserID:     item.UserId,
+			TeamID:     item.TeamId,
+			Role:       item.Role,
+			Permission: item.Permission,
+		})
+	}
+	return items
+}
+
+// datasourceAuditSummary describes a data source without its secure fields.
+func datasourceAuditSummary(ds *models.DataSource) util.DynMap {
+	if ds == nil {
+		return nil
+	}
+	return util.DynMap{
+		"name":      ds.Name,
+		"type":      ds.Type,
+		"url":       ds.Url,
+		"access":    ds.Access,
+		"isDefault": ds.IsDefault,
+		"jsonData":  ds.JsonData,
+	}
+}
+
+// dashboardAuditSummary describes a saved or deleted dashboard without its panels.
+func dashboardAuditSummary(dash *models.Dashboard, message string) util.DynMap {
+	summary := util.DynMap{
+		"title":    dash.Title,
+		"folderId": dash.FolderId,
+		"version":  dash.Version,
+	}
+	if message != "" {
+		summary["message"] = message
+	}
+	return summary
+}
@@ -38,12 +38,44 @@ func (hs *HTTPServer) commentsCreate(c *models.ReqContext) response.Response {
 	}
 	comment, err := hs.commentsService.Create(c.Req.Context(), c.OrgId, c.SignedInUser, cmd)
 	if err != nil {
-		if errors.Is(err, comments.ErrPermissionDenied) {
-			return response.Error(http.StatusForbidden, "permission denied", err)
-		}
-		return response.Error(http.StatusInternalServerError, "internal error", err)
+		return commentsErrorResponse(err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
 		"comment": comment,
 	})
 }
+
+func (hs *HTTPServer) commentsUpdate(c *models.ReqContext) response.Response {
+	cmd := comments.UpdateCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	comment, err := hs.commentsService.Update(c.Req.Context(), c.OrgId, c.SignedInUser, cmd)
+	if err != nil {
+		return commentsErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"comment": comment,
+	})
+}
+
+func (hs *HTTPServer) commentsDelete(c *models.ReqContext) response.Response {
+	cmd := comments.DeleteCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := hs.commentsService.Delete(c.Req.Context(), c.OrgId, c.SignedInUser, cmd); err != nil {
+		return commentsErrorResponse(err)
+	}
+	return response.Success("Comment deleted")
+}
+
+func commentsErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, comments.ErrPermissionDenied):
+		return response.Error(http.StatusForbidden, "permission denied", err)
+	case errors.Is(err, comments.ErrCommentNotFound):
+		return response.Error(http.StatusNotFound, "comment not found", err)
+	}
+	return response.Error(http.StatusInternalServerError, "internal error", err)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -263,7 +264,7 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 		hs.log.Error("Failed to disconnect library elements", "dashboard", dash.Id, "user", c.SignedInUser.UserId, "error", err)
 	}
 
-	err = hs.dashboardService.DeleteDashboard(c.Req.Context(), dash.Id, c.OrgId)
+	err = hs.dashboardService.TrashDashboard(c.Req.Context(), c.SignedInUser, dash.Id, c.OrgId)
 	if err != nil {
 		var dashboardErr models.DashboardErr
 		if ok := errors.As(err, &dashboardErr); ok {
@@ -283,6 +284,8 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 		}
 	}
 
+	hs.logAuditEvent(c, audit.NewEvent(c, audit.ActionDashboardDelete, audit.KindDashboard, dash.Uid).WithChange(dashboardAuditSummary(dash, ""), nil))
+
 	if hs.Live != nil {
 		err := hs.Live.GrafanaScope.Dashboards.DashboardDeleted(c.OrgId, c.ToUserDisplayDTO(), dash.Uid)
 		if err != nil {
@@ -374,6 +377,10 @@ func (hs *HTTPServer) postDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		allowUiUpdate = hs.ProvisioningService.GetAllowUIUpdatesFromConfig(provisioningData.Name)
 	}
 
+	if err := hs.dashboardService.MigrateDashboardSchema(ctx, dash); err != nil {
+		return response.Error(400, "Error while migrating the dashboard schema", err)
+	}
+
 	// clean up all unnecessary library panels JSON properties so we store a minimum JSON
 	err = hs.LibraryPanelService.CleanLibraryPanelsForDashboard(dash)
 	if err != nil {
@@ -433,6 +440,12 @@ func (hs *HTTPServer) postDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		return response.Error(500, "Error while connecting library panels", err)
 	}
 
+	auditAction := audit.ActionDashboardUpdate
+	if dashboard.Version == 1 {
+		auditAction = audit.ActionDashboardCreate
+	}
+	hs.logAuditEvent(c, audit.NewEvent(c, auditAction, audit.KindDashboard, dashboard.Uid).WithChange(nil, dashboardAuditSummary(dashboard, cmd.Message)))
+
 	c.TimeRequest(metrics.MApiDashboardSave)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"status":  "success",
@@ -645,11 +658,63 @@ func (hs *HTTPServer) GetDashboardVersion(c *models.ReqContext) response.Respons
 		Created:       res.Created,
 		Message:       res.Message,
 		CreatedBy:     creator,
+		Tag:           res.Tag,
 	}
 
 	return response.JSON(http.StatusOK, dashVersionMeta)
 }
 
+// PUT /api/dashboards/uid/:uid/versions/:id/tag tags a dashboard version, tagged versions are never deleted.
+func (hs *HTTPServer) TagDashboardVersion(c *models.ReqContext) response.Response {
+	apiCmd := dtos.TagDashboardVersionCommand{}
+	if err := web.Bind(c.Req, &apiCmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	tag := strings.TrimSpace(apiCmd.Tag)
+	if tag == "" {
+		return response.Error(http.StatusBadRequest, "tag is required", nil)
+	}
+	return hs.setDashboardVersionTag(c, tag)
+}
+
+// DELETE /api/dashboards/uid/:uid/versions/:id/tag removes the tag of a dashboard version.
+func (hs *HTTPServer) UntagDashboardVersion(c *models.ReqContext) response.Response {
+	return hs.setDashboardVersionTag(c, "")
+}
+
+func (hs *HTTPServer) setDashboardVersionTag(c *models.ReqContext, tag string) response.Response {
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.OrgId, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	version, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 32)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	cmd := dashver.TagDashboardVersionCommand{DashboardID: dash.Id, OrgID: c.OrgId, Version: int(version), Tag: tag}
+	if err := hs.dashboardVersionService.Tag(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard version not found", err)
+		}
+		if errors.Is(err, dashver.ErrDashboardVersionTagTooLong) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to tag dashboard version", err)
+	}
+
+	if tag == "" {
+		return response.Success("Dashboard version tag removed")
+	}
+	return response.Success("Dashboard version tagged")
+}
+
 // POST /api/dashboards/calculate-diff performs diffs on two dashboards
 func (hs *HTTPServer) CalculateDashboardDiff(c *models.ReqContext) response.Response {
 	apiOptions := dtos.CalculateDiffOptions{}
@@ -730,6 +795,62 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *models.ReqContext) response.Resp
 	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
 }
 
+// POST /api/dashboards/uid/:uid/merge merges the changes made to a dashboard since the version
+// it was loaded from with the changes made to the saved dashboard in the meantime.
+func (hs *HTTPServer) MergeDashboard(c *models.ReqContext) response.Response {
+	cmd := dtos.MergeDashboardCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.OrgId, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	versionQuery := dashver.GetDashboardVersionQuery{DashboardID: dash.Id, Version: cmd.BaseVersion, OrgID: c.OrgId}
+	baseVersion, err := hs.dashboardVersionService.Get(c.Req.Context(), &versionQuery)
+	if err != nil {
+		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+			return response.Error(404, "Dashboard version not found", err)
+		}
+		return response.Error(500, "Unable to merge dashboard", err)
+	}
+
+	// the three versions are upgraded to the latest schema version, otherwise the changes of the migrations
+	// would be merged as changes of one of the edits
+	for _, data := range []*simplejson.Json{baseVersion.Data, cmd.Dashboard, dash.Data} {
+		// dashboards which are not objects are rejected by the merge
+		if data == nil {
+			continue
+		}
+		if _, err := data.Map(); err != nil {
+			continue
+		}
+		if err := hs.dashboardService.MigrateDashboardSchema(c.Req.Context(), &models.Dashboard{OrgId: c.OrgId, Data: data}); err != nil {
+			return response.Error(500, "Unable to merge dashboard", err)
+		}
+	}
+
+	result, err := dashdiffs.Merge(baseVersion.Data, cmd.Dashboard, dash.Data)
+	if err != nil {
+		if errors.Is(err, dashdiffs.ErrInvalidMergeTarget) {
+			return response.Error(http.StatusBadRequest, "Dashboard is invalid", err)
+		}
+		return response.Error(500, "Unable to merge dashboard", err)
+	}
+
+	if len(result.Conflicts) > 0 {
+		return response.JSON(http.StatusConflict, result)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
 // RestoreDashboardVersion restores a dashboard to the given version.
 func (hs *HTTPServer) RestoreDashboardVersion(c *models.ReqContext) response.Response {
 	var dashID int64
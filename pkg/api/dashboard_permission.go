@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -132,14 +133,17 @@ func (hs *HTTPServer) UpdateDashboardPermissions(c *models.ReqContext) response.
 		return response.Error(403, "Cannot remove own admin permission for a folder", nil)
 	}
 
+	old, err := g.GetAcl()
+	if err != nil {
+		return response.Error(500, "Error while checking dashboard permissions", err)
+	}
+	auditEvent := audit.NewEvent(c, audit.ActionDashboardPermissionsUpdate, audit.KindDashboard, dash.Uid).WithChange(aclAuditSummary(old), apiCmd.Items)
+
 	if !hs.AccessControl.IsDisabled() {
-		old, err := g.GetAcl()
-		if err != nil {
-			return response.Error(500, "Error while checking dashboard permissions", err)
-		}
 		if err := hs.updateDashboardAccessControl(c.Req.Context(), dash.OrgId, dash.Uid, false, items, old); err != nil {
 			return response.Error(500, "Failed to update permissions", err)
 		}
+		hs.logAuditEvent(c, auditEvent)
 		return response.Success("Dashboard permissions updated")
 	}
 
@@ -151,6 +155,7 @@ func (hs *HTTPServer) UpdateDashboardPermissions(c *models.ReqContext) response.
 		return response.Error(500, "Failed to create permission", err)
 	}
 
+	hs.logAuditEvent(c, auditEvent)
 	return response.Success("Dashboard permissions updated")
 }
 
@@ -81,10 +81,16 @@ func (hs *HTTPServer) QueryPublicDashboard(c *models.ReqContext) response.Respon
 		return response.Error(http.StatusBadRequest, "invalid panel ID", err)
 	}
 
+	queryDTO := models.PublicDashboardQueryDTO{}
+	if err := web.Bind(c.Req, &queryDTO); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
 	reqDTO, err := hs.dashboardService.BuildPublicDashboardMetricRequest(
 		c.Req.Context(),
 		web.Params(c.Req)[":uid"],
 		panelId,
+		queryDTO,
 	)
 	if err != nil {
 		return handleDashboardErr(http.StatusInternalServerError, "Failed to get queries for public dashboard", err)
@@ -98,6 +104,22 @@ func (hs *HTTPServer) QueryPublicDashboard(c *models.ReqContext) response.Respon
 	return hs.toJsonStreamingResponse(resp)
 }
 
+// GetPublicDashboardAnnotations returns the annotations shown on a public dashboard
+// GET /api/public/dashboards/:uid/annotations
+func (hs *HTTPServer) GetPublicDashboardAnnotations(c *models.ReqContext) response.Response {
+	queryDTO := models.PublicDashboardQueryDTO{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	}
+
+	annotations, err := hs.dashboardService.GetPublicDashboardAnnotations(c.Req.Context(), web.Params(c.Req)[":uid"], queryDTO)
+	if err != nil {
+		return handleDashboardErr(http.StatusInternalServerError, "Failed to get annotations for public dashboard", err)
+	}
+
+	return response.JSON(http.StatusOK, annotations)
+}
+
 // util to help us unpack a dashboard err or use default http code and message
 func handleDashboardErr(defaultCode int, defaultMsg string, err error) response.Response {
 	var dashboardErr models.DashboardErr
@@ -285,6 +285,8 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 			},
 		},
 		&fakeOAuthTokenService{},
+		nil,
+		nil,
 	)
 
 	setup := func(enabled bool) (*webtest.Server, *dashboards.FakeDashboardService) {
@@ -331,6 +333,7 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 			mock.Anything,
 			"abc123",
 			int64(2),
+			mock.Anything,
 		).Return(dtos.MetricRequest{
 			Queries: []*simplejson.Json{
 				simplejson.MustJson([]byte(`
@@ -390,6 +393,7 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 			mock.Anything,
 			"abc123",
 			int64(2),
+			mock.Anything,
 		).Return(dtos.MetricRequest{
 			Queries: []*simplejson.Json{
 				simplejson.MustJson([]byte(`
@@ -427,6 +431,7 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 			mock.Anything,
 			"abc123",
 			int64(2),
+			mock.Anything,
 		).Return(dtos.MetricRequest{
 			Queries: []*simplejson.Json{
 				simplejson.MustJson([]byte(`
@@ -505,3 +510,56 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 	})
 }
+
+func TestAPIGetPublicDashboardAnnotations(t *testing.T) {
+	setup := func(enabled bool) (*webtest.Server, *dashboards.FakeDashboardService) {
+		fakeDashboardService := &dashboards.FakeDashboardService{}
+
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.Features = featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards, enabled)
+			hs.dashboardService = fakeDashboardService
+		}), fakeDashboardService
+	}
+
+	t.Run("Status code is 404 when feature toggle is disabled", func(t *testing.T) {
+		server, _ := setup(false)
+
+		resp, err := server.Send(server.NewGetRequest("/api/public/dashboards/abc123/annotations"))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Returns annotations for the time range chosen by the viewer", func(t *testing.T) {
+		server, fakeDashboardService := setup(true)
+
+		fakeDashboardService.On(
+			"GetPublicDashboardAnnotations",
+			mock.Anything,
+			"abc123",
+			models.PublicDashboardQueryDTO{From: "now-6h", To: "now"},
+		).Return([]*models.PublicDashboardAnnotation{
+			{PanelId: 2, Time: 1000, TimeEnd: 1000, Text: "deploy", Tags: []string{}, Color: "red"},
+		}, nil)
+
+		resp, err := server.Send(server.NewGetRequest("/api/public/dashboards/abc123/annotations?from=now-6h&to=now"))
+		require.NoError(t, err)
+		bodyBytes, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.JSONEq(t, `[{"panelId": 2, "time": 1000, "timeEnd": 1000, "text": "deploy", "tags": [], "color": "red"}]`, string(bodyBytes))
+	})
+
+	t.Run("Status code is 400 when the time range is too large", func(t *testing.T) {
+		server, fakeDashboardService := setup(true)
+
+		fakeDashboardService.On("GetPublicDashboardAnnotations", mock.Anything, "abc123", mock.Anything).
+			Return(nil, models.ErrPublicDashboardTimeRangeTooLarge)
+
+		resp, err := server.Send(server.NewGetRequest("/api/public/dashboards/abc123/annotations?from=now-1y&to=now"))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
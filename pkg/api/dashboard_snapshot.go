@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -157,7 +158,8 @@ func (hs *HTTPServer) GetDashboardSnapshot(c *models.ReqContext) response.Respon
 		return response.Error(404, "Snapshot not found", nil)
 	}
 
-	query := &dashboardsnapshots.GetDashboardSnapshotQuery{Key: key}
+	// the dashboard is written as stored, without being decoded
+	query := &dashboardsnapshots.GetDashboardSnapshotQuery{Key: key, Raw: true}
 
 	err := hs.dashboardsnapshotsService.GetDashboardSnapshot(c.Req.Context(), query)
 	if err != nil {
@@ -168,22 +170,82 @@ func (hs *HTTPServer) GetDashboardSnapshot(c *models.ReqContext) response.Respon
 
 	// expired snapshots should also be removed from db
 	if snapshot.Expires.Before(time.Now()) {
+		if snapshot.DashboardReader != nil {
+			if err := snapshot.DashboardReader.Close(); err != nil {
+				plog.Warn("Failed to close snapshot dashboard", "key", key, "err", err)
+			}
+		}
 		return response.Error(404, "Dashboard snapshot not found", err)
 	}
 
-	dto := dtos.DashboardFullWithMeta{
-		Dashboard: snapshot.Dashboard,
-		Meta: dtos.DashboardMeta{
-			Type:       models.DashTypeSnapshot,
-			IsSnapshot: true,
-			Created:    snapshot.Created,
-			Expires:    snapshot.Expires,
-		},
+	meta := dtos.DashboardMeta{
+		Type:       models.DashTypeSnapshot,
+		IsSnapshot: true,
+		Created:    snapshot.Created,
+		Expires:    snapshot.Expires,
 	}
 
 	metrics.MApiDashboardSnapshotGet.Inc()
 
-	return response.JSON(http.StatusOK, dto).SetHeader("Cache-Control", "public, max-age=3600")
+	if snapshot.DashboardReader == nil {
+		dto := dtos.DashboardFullWithMeta{
+			Dashboard: snapshot.Dashboard,
+			Meta:      meta,
+		}
+		return response.JSON(http.StatusOK, dto).SetHeader("Cache-Control", "public, max-age=3600")
+	}
+
+	return &dashboardSnapshotResponse{meta: meta, dashboard: snapshot.DashboardReader}
+}
+
+// dashboardSnapshotResponse copies the dashboard of a snapshot to the response while it is read from the storage,
+// the body is the same as for a dtos.DashboardFullWithMeta. The dashboard is closed once written.
+type dashboardSnapshotResponse struct {
+	meta      dtos.DashboardMeta
+	dashboard io.ReadCloser
+}
+
+func (r *dashboardSnapshotResponse) Status() int {
+	return http.StatusOK
+}
+
+func (r *dashboardSnapshotResponse) Body() []byte {
+	return nil
+}
+
+func (r *dashboardSnapshotResponse) WriteTo(c *models.ReqContext) {
+	defer func() {
+		if err := r.dashboard.Close(); err != nil {
+			c.Logger.Warn("Failed to close snapshot dashboard", "err", err)
+		}
+	}()
+
+	meta, err := json.Marshal(r.meta)
+	if err != nil {
+		response.Error(500, "Failed to encode dashboard snapshot", err).WriteTo(c)
+		return
+	}
+
+	c.Resp.Header().Set("Content-Type", "application/json")
+	c.Resp.Header().Set("Cache-Control", "public, max-age=3600")
+	c.Resp.WriteHeader(http.StatusOK)
+	if err := writeDashboardSnapshot(c.Resp, meta, r.dashboard); err != nil {
+		// the status has already been sent, the client gets a truncated body
+		c.Logger.Error("Failed to write dashboard snapshot", "err", err)
+	}
+}
+
+func writeDashboardSnapshot(w io.Writer, meta []byte, dashboard io.Reader) error {
+	for _, part := range [][]byte{[]byte(`{"meta":`), meta, []byte(`,"dashboard":`)} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	if _, err := io.Copy(w, dashboard); err != nil {
+		return err
+	}
+	_, err := w.Write([]byte("}\n"))
+	return err
 }
 
 func deleteExternalDashboardSnapshot(externalUrl string) error {
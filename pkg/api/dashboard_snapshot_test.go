@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -226,5 +227,31 @@ func TestDashboardSnapshotAPIEndpoint_singleSnapshot(t *testing.T) {
 
 				assert.Equal(t, int64(100), id.MustInt64())
 			}, sqlmock)
+
+		loggedInUserScenarioWithRole(t, "Should return the raw dashboard of a snapshot when calling GET on",
+			"GET", "/api/snapshots/12345", "/api/snapshots/:key", models.ROLE_EDITOR, func(sc *scenarioContext) {
+				dashSnapSvc := dashboardsnapshots.NewMockService(t)
+				dashSnapSvc.On("GetDashboardSnapshot", mock.Anything, mock.AnythingOfType("*dashboardsnapshots.GetDashboardSnapshotQuery")).Run(func(args mock.Arguments) {
+					q := args.Get(1).(*dashboardsnapshots.GetDashboardSnapshotQuery)
+					assert.True(t, q.Raw)
+					q.Result = &dashboardsnapshots.DashboardSnapshot{
+						Id:              1,
+						Key:             "12345",
+						DashboardReader: io.NopCloser(strings.NewReader(`{"id":200}`)),
+						Expires:         time.Now().Add(time.Duration(1000) * time.Second),
+					}
+				}).Return(nil)
+				hs := &HTTPServer{dashboardsnapshotsService: dashSnapSvc}
+				sc.handlerFunc = hs.GetDashboardSnapshot
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{"key": "12345"}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+				respJSON, err := simplejson.NewJson(sc.resp.Body.Bytes())
+				require.NoError(t, err)
+
+				assert.Equal(t, "public, max-age=3600", sc.resp.Header().Get("Cache-Control"))
+				assert.Equal(t, int64(200), respJSON.Get("dashboard").Get("id").MustInt64())
+				assert.True(t, respJSON.Get("meta").Get("isSnapshot").MustBool())
+			}, sqlmock)
 	})
 }
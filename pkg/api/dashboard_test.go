@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/models"
@@ -395,7 +396,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 					q := args.Get(1).(*models.GetDashboardQuery)
 					q.Result = models.NewDashboard("test")
 				}).Return(nil)
-				dashboardService.On("DeleteDashboard", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).Return(nil)
+				dashboardService.On("TrashDashboard", mock.Anything, mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).Return(nil)
 
 				hs.callDeleteDashboardByUID(t, sc, dashboardService)
 
@@ -497,7 +498,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 					q := args.Get(1).(*models.GetDashboardQuery)
 					q.Result = models.NewDashboard("test")
 				}).Return(nil)
-				dashboardService.On("DeleteDashboard", mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).Return(nil)
+				dashboardService.On("TrashDashboard", mock.Anything, mock.Anything, mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).Return(nil)
 				hs.callDeleteDashboardByUID(t, sc, dashboardService)
 
 				assert.Equal(t, 200, sc.resp.Code)
@@ -597,6 +598,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			}
 
 			dashboardService := dashboards.NewFakeDashboardService(t)
+			dashboardService.On("MigrateDashboardSchema", mock.Anything, mock.AnythingOfType("*models.Dashboard")).Return(nil)
 			dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).
 				Return(&models.Dashboard{Id: dashID, Uid: "uid", Title: "Dash", Slug: "dash", Version: 2}, nil)
 
@@ -629,6 +631,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			}
 
 			dashboardService := dashboards.NewFakeDashboardService(t)
+			dashboardService.On("MigrateDashboardSchema", mock.Anything, mock.AnythingOfType("*models.Dashboard")).Return(nil)
 			dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).
 				Return(&models.Dashboard{Id: dashID, Uid: "uid", Title: "Dash", Slug: "dash", Version: 2}, nil)
 
@@ -708,6 +711,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 
 			for _, tc := range testCases {
 				dashboardService := dashboards.NewFakeDashboardService(t)
+				dashboardService.On("MigrateDashboardSchema", mock.Anything, mock.AnythingOfType("*models.Dashboard")).Return(nil)
 				dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).Return(nil, tc.SaveError)
 
 				postDashboardScenario(t, fmt.Sprintf("Expect '%s' error when calling POST on", tc.SaveError.Error()),
@@ -789,6 +793,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			q := args.Get(1).(*models.GetDashboardQuery)
 			q.Result = fakeDash
 		}).Return(nil)
+		dashboardService.On("MigrateDashboardSchema", mock.Anything, mock.AnythingOfType("*models.Dashboard")).Return(nil)
 		dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).Run(func(args mock.Arguments) {
 			cmd := args.Get(1).(*dashboards.SaveDashboardDTO)
 			cmd.Dashboard = &models.Dashboard{
@@ -826,6 +831,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			q := args.Get(1).(*models.GetDashboardQuery)
 			q.Result = fakeDash
 		}).Return(nil)
+		dashboardService.On("MigrateDashboardSchema", mock.Anything, mock.AnythingOfType("*models.Dashboard")).Return(nil)
 		dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).Run(func(args mock.Arguments) {
 			cmd := args.Get(1).(*dashboards.SaveDashboardDTO)
 			cmd.Dashboard = &models.Dashboard{
@@ -852,6 +858,142 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			}, mockSQLStore)
 	})
 
+	t.Run("Given dashboard saved since it was loaded", func(t *testing.T) {
+		base := simplejson.NewFromAny(map[string]interface{}{
+			"title":  "Dash",
+			"panels": []interface{}{map[string]interface{}{"id": 1, "title": "CPU"}, map[string]interface{}{"id": 2, "title": "Memory"}},
+		})
+		saved := simplejson.NewFromAny(map[string]interface{}{
+			"uid":     "uid",
+			"title":   "Dash",
+			"version": 2,
+			"panels":  []interface{}{map[string]interface{}{"id": 1, "title": "CPU saved"}, map[string]interface{}{"id": 2, "title": "Memory"}},
+		})
+		fakeDash := models.NewDashboardFromJson(saved)
+		fakeDash.Id = 2
+		fakeDash.HasAcl = false
+
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+			q := args.Get(1).(*models.GetDashboardQuery)
+			q.Result = fakeDash
+		}).Return(nil)
+		// the three versions are upgraded to the latest schema version before merging
+		var migrated []*simplejson.Json
+		dashboardService.On("MigrateDashboardSchema", mock.Anything, mock.AnythingOfType("*models.Dashboard")).Run(func(args mock.Arguments) {
+			migrated = append(migrated, args.Get(1).(*models.Dashboard).Data)
+		}).Return(nil)
+
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedDashboardVersion = &dashver.DashboardVersion{DashboardID: 2, Version: 1, Data: base}
+		mockSQLStore := mockstore.NewSQLStoreMock()
+
+		t.Run("should merge changes to other panels", func(t *testing.T) {
+			cmd := dtos.MergeDashboardCommand{
+				BaseVersion: 1,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"title":  "Dash",
+					"panels": []interface{}{map[string]interface{}{"id": 1, "title": "CPU"}, map[string]interface{}{"id": 2, "title": "Memory edited"}},
+				}),
+			}
+			mergeDashboardScenario(t, "When calling POST on", "/api/dashboards/uid/uid/merge", "/api/dashboards/uid/:uid/merge",
+				dashboardService, fakeDashboardVersionService, cmd, func(sc *scenarioContext) {
+					callMergeDashboard(sc)
+					require.Equal(t, 200, sc.resp.Code)
+
+					result := dashdiffs.MergeResult{}
+					require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &result))
+					assert.Empty(t, result.Conflicts)
+					assert.Equal(t, 2, result.Dashboard.Get("version").MustInt())
+					assert.Equal(t, "CPU saved", result.Dashboard.Get("panels").GetIndex(0).Get("title").MustString())
+					assert.Equal(t, "Memory edited", result.Dashboard.Get("panels").GetIndex(1).Get("title").MustString())
+
+					require.Len(t, migrated, 3)
+					assert.Same(t, base, migrated[0])
+					assert.Equal(t, "Memory edited", migrated[1].Get("panels").GetIndex(1).Get("title").MustString())
+					assert.Same(t, fakeDash.Data, migrated[2])
+				}, mockSQLStore)
+		})
+
+		t.Run("should return conflicts for changes to the same panel", func(t *testing.T) {
+			cmd := dtos.MergeDashboardCommand{
+				BaseVersion: 1,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"title":  "Dash",
+					"panels": []interface{}{map[string]interface{}{"id": 1, "title": "CPU edited"}, map[string]interface{}{"id": 2, "title": "Memory"}},
+				}),
+			}
+			mergeDashboardScenario(t, "When calling POST on", "/api/dashboards/uid/uid/merge", "/api/dashboards/uid/:uid/merge",
+				dashboardService, fakeDashboardVersionService, cmd, func(sc *scenarioContext) {
+					callMergeDashboard(sc)
+					require.Equal(t, 409, sc.resp.Code)
+
+					result := dashdiffs.MergeResult{}
+					require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &result))
+					require.Len(t, result.Conflicts, 1)
+					assert.Equal(t, "panels[id=1]", result.Conflicts[0].Path)
+				}, mockSQLStore)
+		})
+	})
+
+	t.Run("Given dashboard version being tagged", func(t *testing.T) {
+		fakeDash := models.NewDashboard("Dash")
+		fakeDash.Id = 2
+		fakeDash.HasAcl = false
+
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+			q := args.Get(1).(*models.GetDashboardQuery)
+			q.Result = fakeDash
+		}).Return(nil)
+		mockSQLStore := mockstore.NewSQLStoreMock()
+
+		tagScenario := func(t *testing.T, fakeDashboardVersionService *dashvertest.FakeDashboardVersionService, cmd dtos.TagDashboardVersionCommand, fn scenarioFunc) {
+			hs := HTTPServer{
+				Cfg:                     setting.NewCfg(),
+				dashboardService:        dashboardService,
+				SQLStore:                mockSQLStore,
+				Features:                featuremgmt.WithFeatures(),
+				dashboardVersionService: fakeDashboardVersionService,
+			}
+
+			sc := setupScenarioContext(t, "/api/dashboards/uid/uid/versions/1/tag")
+			sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+				c.Req.Body = mockRequestBody(cmd)
+				c.Req.Header.Add("Content-Type", "application/json")
+				sc.context = c
+				sc.context.SignedInUser = &models.SignedInUser{OrgId: testOrgID, UserId: testUserID}
+				sc.context.OrgRole = models.ROLE_ADMIN
+				return hs.TagDashboardVersion(c)
+			})
+			sc.m.Put("/api/dashboards/uid/:uid/versions/:id/tag", sc.defaultHandler)
+			fn(sc)
+		}
+
+		t.Run("should tag the version", func(t *testing.T) {
+			tagScenario(t, dashvertest.NewDashboardVersionServiceFake(), dtos.TagDashboardVersionCommand{Tag: "release-1"}, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 200, sc.resp.Code)
+			})
+		})
+
+		t.Run("should return 404 when the version does not exist", func(t *testing.T) {
+			fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+			fakeDashboardVersionService.ExpectedError = dashver.ErrDashboardVersionNotFound
+			tagScenario(t, fakeDashboardVersionService, dtos.TagDashboardVersionCommand{Tag: "release-1"}, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 404, sc.resp.Code)
+			})
+		})
+
+		t.Run("should return 400 when the tag is blank", func(t *testing.T) {
+			tagScenario(t, dashvertest.NewDashboardVersionServiceFake(), dtos.TagDashboardVersionCommand{Tag: "  "}, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+				assert.Equal(t, 400, sc.resp.Code)
+			})
+		})
+	})
+
 	t.Run("Given provisioned dashboard", func(t *testing.T) {
 		mockSQLStore := mockstore.NewSQLStoreMock()
 		dashboardStore := dashboards.NewFakeDashboardStore(t)
@@ -999,6 +1141,10 @@ func callRestoreDashboardVersion(sc *scenarioContext) {
 	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
 }
 
+func callMergeDashboard(sc *scenarioContext) {
+	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+}
+
 func callPostDashboardShouldReturnSuccess(sc *scenarioContext) {
 	callPostDashboard(sc)
 
@@ -1117,6 +1263,40 @@ func restoreDashboardVersionScenario(t *testing.T, desc string, url string, rout
 	})
 }
 
+func mergeDashboardScenario(t *testing.T, desc string, url string, routePattern string,
+	mock *dashboards.FakeDashboardService, fakeDashboardVersionService *dashvertest.FakeDashboardVersionService,
+	cmd dtos.MergeDashboardCommand, fn scenarioFunc, sqlStore sqlstore.Store) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		hs := HTTPServer{
+			Cfg:                     setting.NewCfg(),
+			dashboardService:        mock,
+			SQLStore:                sqlStore,
+			Features:                featuremgmt.WithFeatures(),
+			dashboardVersionService: fakeDashboardVersionService,
+		}
+
+		sc := setupScenarioContext(t, url)
+		sc.sqlStore = sqlStore
+		sc.dashboardVersionService = fakeDashboardVersionService
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(cmd)
+			c.Req.Header.Add("Content-Type", "application/json")
+			sc.context = c
+			sc.context.SignedInUser = &models.SignedInUser{
+				OrgId:  testOrgID,
+				UserId: testUserID,
+			}
+			sc.context.OrgRole = models.ROLE_ADMIN
+
+			return hs.MergeDashboard(c)
+		})
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 func setupDashboardCoremodel(t *testing.T) (*registry.Static, *registry.Generic) {
 	// TODO abstract and generalize this further for wider reuse
 	t.Helper()
@@ -1184,6 +1364,11 @@ func (l *mockLibraryElementService) ConnectElementsToDashboard(c context.Context
 	return nil
 }
 
+// PatchElement updates an element.
+func (l *mockLibraryElementService) PatchElement(c context.Context, signedInUser *models.SignedInUser, cmd libraryelements.PatchLibraryElementCommand, UID string) (libraryelements.LibraryElementDTO, error) {
+	return libraryelements.LibraryElementDTO{}, nil
+}
+
 // DisconnectElementsFromDashboard disconnects elements from a specific dashboard.
 func (l *mockLibraryElementService) DisconnectElementsFromDashboard(c context.Context, dashboardID int64) error {
 	return nil
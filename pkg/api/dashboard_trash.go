@@ -0,0 +1,156 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/store"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GetDashboardTrash lists the deleted dashboards and folders that can still be restored.
+// Organization administrators see everything in the trash, other users only what they deleted.
+func (hs *HTTPServer) GetDashboardTrash(c *models.ReqContext) response.Response {
+	query := models.GetDashboardTrashQuery{OrgId: c.OrgId}
+	if !c.SignedInUser.HasRole(models.ROLE_ADMIN) {
+		query.DeletedBy = c.UserId
+	}
+
+	if err := hs.dashboardService.GetDashboardTrash(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get trash", err)
+	}
+
+	result := make([]*models.DashboardTrashDTO, 0, len(query.Result))
+	for _, item := range query.Result {
+		result = append(result, hs.toDashboardTrashDTO(item))
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// RestoreDashboardFromTrash restores a deleted dashboard or folder with its versions and permissions.
+func (hs *HTTPServer) RestoreDashboardFromTrash(c *models.ReqContext) response.Response {
+	item, rsp := hs.getDashboardTrashItem(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	// the user needs to be allowed to create the dashboard or folder where it is restored to
+	var folderID int64
+	if item.FolderUid != "" {
+		folder, err := hs.folderService.GetFolderByUID(c.Req.Context(), c.SignedInUser, c.OrgId, item.FolderUid)
+		if err != nil && !errors.Is(err, models.ErrFolderNotFound) && !errors.Is(err, models.ErrDashboardNotFound) {
+			return apierrors.ToFolderErrorResponse(err)
+		}
+		if folder != nil {
+			folderID = folder.Id
+		}
+	}
+	g := guardian.New(c.Req.Context(), folderID, c.OrgId, c.SignedInUser)
+	if canCreate, err := g.CanCreate(folderID, item.IsFolder); err != nil || !canCreate {
+		return dashboardGuardianResponse(err)
+	}
+
+	cmd := models.RestoreDashboardFromTrashCommand{Id: item.Id, OrgId: c.OrgId}
+	if err := hs.dashboardService.RestoreDashboardFromTrash(c.Req.Context(), &cmd); err != nil {
+		return toDashboardTrashErrorResponse(err, "Failed to restore from trash")
+	}
+
+	for _, dash := range cmd.Result {
+		hs.saveDashboardTrashEntityEvent(c, dash, store.EntityEventTypeCreate)
+	}
+
+	kind := audit.KindDashboard
+	if item.IsFolder {
+		kind = audit.KindFolder
+	}
+	hs.logAuditEvent(c, audit.NewEvent(c, audit.ActionDashboardRestore, kind, item.Uid).WithChange(nil, dashboardAuditSummary(cmd.Result[0], "")))
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"id":      cmd.Result[0].Id,
+		"uid":     cmd.Result[0].Uid,
+		"title":   cmd.Result[0].Title,
+		"url":     cmd.Result[0].GetUrl(),
+		"message": fmt.Sprintf("%s restored", item.Title),
+	})
+}
+
+// DeleteDashboardFromTrash permanently deletes a dashboard or folder from the trash.
+func (hs *HTTPServer) DeleteDashboardFromTrash(c *models.ReqContext) response.Response {
+	item, rsp := hs.getDashboardTrashItem(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	if err := hs.dashboardService.DeleteDashboardFromTrash(c.Req.Context(), c.OrgId, item.Id); err != nil {
+		return toDashboardTrashErrorResponse(err, "Failed to delete from trash")
+	}
+
+	return response.Success(fmt.Sprintf("%s permanently deleted", item.Title))
+}
+
+// getDashboardTrashItem returns the trash item in the request if the signed in user is allowed to manage it.
+func (hs *HTTPServer) getDashboardTrashItem(c *models.ReqContext) (*models.DashboardTrash, response.Response) {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return nil, response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	query := models.GetDashboardTrashItemQuery{Id: id, OrgId: c.OrgId}
+	if err := hs.dashboardService.GetDashboardTrashItem(c.Req.Context(), &query); err != nil {
+		return nil, toDashboardTrashErrorResponse(err, "Failed to get trash item")
+	}
+
+	if !c.SignedInUser.HasRole(models.ROLE_ADMIN) && query.Result.DeletedBy != c.UserId {
+		return nil, toDashboardTrashErrorResponse(models.ErrDashboardTrashItemNotFound, "")
+	}
+
+	return query.Result, nil
+}
+
+func (hs *HTTPServer) toDashboardTrashDTO(item *models.DashboardTrash) *models.DashboardTrashDTO {
+	return &models.DashboardTrashDTO{
+		Id:        item.Id,
+		Uid:       item.Uid,
+		Title:     item.Title,
+		IsFolder:  item.IsFolder,
+		FolderUid: item.FolderUid,
+		Deleted:   item.Deleted,
+		DeletedBy: item.DeletedBy,
+		Expires:   item.Deleted.Add(hs.Cfg.DashboardTrashRetention),
+	}
+}
+
+func (hs *HTTPServer) saveDashboardTrashEntityEvent(c *models.ReqContext, dash *models.Dashboard, eventType store.EntityEventType) {
+	if hs.entityEventsService == nil {
+		return
+	}
+
+	entityType := store.EntityTypeDashboard
+	if dash.IsFolder {
+		entityType = store.EntityTypeFolder
+	}
+	if err := hs.entityEventsService.SaveEvent(c.Req.Context(), store.SaveEventCmd{
+		EntityId:  store.CreateDatabaseEntityId(dash.Uid, c.OrgId, entityType),
+		EventType: eventType,
+	}); err != nil {
+		hs.log.Warn("failed to save dashboard entity event", "uid", dash.Uid, "error", err)
+	}
+}
+
+func toDashboardTrashErrorResponse(err error, message string) response.Response {
+	var dashboardErr models.DashboardErr
+	if errors.As(err, &dashboardErr) {
+		return response.Error(dashboardErr.StatusCode, dashboardErr.Error(), err)
+	}
+
+	return response.Error(http.StatusInternalServerError, message, err)
+}
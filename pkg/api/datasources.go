@@ -16,7 +16,9 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	datasourcehealth "github.com/grafana/grafana/pkg/services/datasources/health"
 	"github.com/grafana/grafana/pkg/services/datasources/permissions"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/proxyutil"
@@ -135,6 +137,7 @@ func (hs *HTTPServer) DeleteDataSourceById(c *models.ReqContext) response.Respon
 	}
 
 	hs.Live.HandleDatasourceDelete(c.OrgId, ds.Uid)
+	hs.logAuditEvent(c, audit.NewEvent(c, audit.ActionDatasourceDelete, audit.KindDatasource, ds.Uid).WithChange(datasourceAuditSummary(ds), nil))
 
 	return response.Success("Data source deleted")
 }
@@ -158,6 +161,70 @@ func (hs *HTTPServer) GetDataSourceByUID(c *models.ReqContext) response.Response
 	return response.JSON(http.StatusOK, &dto)
 }
 
+// GET /api/datasources/uid/:uid/usage
+func (hs *HTTPServer) GetDataSourceUsageByUID(c *models.ReqContext) response.Response {
+	ds, err := hs.getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.OrgId)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to query datasource", err)
+	}
+
+	usage, err := hs.dataSourceUsageService.Usage(c.Req.Context(), c.OrgId, ds.Uid)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source usage", err)
+	}
+	return response.JSON(http.StatusOK, usage)
+}
+
+// GET /api/datasources/health
+func (hs *HTTPServer) GetDataSourcesHealth(c *models.ReqContext) response.Response {
+	health, err := hs.dataSourceHealthService.Health(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data sources health", err)
+	}
+
+	// only the data sources the user can query are listed, as by GET /api/datasources
+	query := models.GetDataSourcesQuery{OrgId: c.OrgId, DataSourceLimit: hs.Cfg.DataSourceLimit}
+	if err := hs.DataSourcesService.GetDataSources(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to query datasources", err)
+	}
+	filtered, err := hs.filterDatasourcesByQueryPermission(c.Req.Context(), c.SignedInUser, query.Result)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to query datasources", err)
+	}
+	allowed := make(map[string]bool, len(filtered))
+	for _, ds := range filtered {
+		allowed[ds.Uid] = true
+	}
+
+	result := make([]*datasourcehealth.DataSourceHealth, 0, len(health))
+	for _, h := range health {
+		if allowed[h.UID] {
+			result = append(result, h)
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// GET /api/datasources/uid/:uid/health/history
+func (hs *HTTPServer) GetDataSourceHealthHistoryByUID(c *models.ReqContext) response.Response {
+	ds, err := hs.getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.OrgId)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to query datasource", err)
+	}
+
+	history, err := hs.dataSourceHealthService.History(c.Req.Context(), c.OrgId, ds.Uid)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source health history", err)
+	}
+	return response.JSON(http.StatusOK, history)
+}
+
 // DELETE /api/datasources/uid/:uid
 func (hs *HTTPServer) DeleteDataSourceByUID(c *models.ReqContext) response.Response {
 	uid := web.Params(c.Req)[":uid"]
@@ -189,6 +256,7 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *models.ReqContext) response.Respo
 	}
 
 	hs.Live.HandleDatasourceDelete(c.OrgId, ds.Uid)
+	hs.logAuditEvent(c, audit.NewEvent(c, audit.ActionDatasourceDelete, audit.KindDatasource, ds.Uid).WithChange(datasourceAuditSummary(ds), nil))
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "Data source deleted",
@@ -226,6 +294,7 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *models.ReqContext) response.Resp
 	}
 
 	hs.Live.HandleDatasourceDelete(c.OrgId, getCmd.Result.Uid)
+	hs.logAuditEvent(c, audit.NewEvent(c, audit.ActionDatasourceDelete, audit.KindDatasource, getCmd.Result.Uid).WithChange(datasourceAuditSummary(getCmd.Result), nil))
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "Data source deleted",
@@ -269,6 +338,8 @@ func (hs *HTTPServer) AddDataSource(c *models.ReqContext) response.Response {
 		return response.Error(500, "Failed to add datasource", err)
 	}
 
+	hs.logAuditEvent(c, audit.NewEvent(c, audit.ActionDatasourceCreate, audit.KindDatasource, cmd.Result.Uid).WithChange(nil, datasourceAuditSummary(cmd.Result)))
+
 	ds := hs.convertModelToDtos(c.Req.Context(), cmd.Result)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message":    "Datasource added",
@@ -359,6 +430,7 @@ func (hs *HTTPServer) updateDataSourceByID(c *models.ReqContext, ds *models.Data
 	datasourceDTO := hs.convertModelToDtos(c.Req.Context(), query.Result)
 
 	hs.Live.HandleDatasourceUpdate(c.OrgId, datasourceDTO.UID)
+	hs.logAuditEvent(c, audit.NewEvent(c, audit.ActionDatasourceUpdate, audit.KindDatasource, ds.Uid).WithChange(datasourceAuditSummary(ds), datasourceAuditSummary(query.Result)))
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message":    "Datasource updated",
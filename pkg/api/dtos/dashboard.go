@@ -73,6 +73,15 @@ type CalculateDiffTarget struct {
 	UnsavedDashboard *simplejson.Json `json:"unsavedDashboard"`
 }
 
+type MergeDashboardCommand struct {
+	BaseVersion int              `json:"baseVersion" binding:"Required"`
+	Dashboard   *simplejson.Json `json:"dashboard" binding:"Required"`
+}
+
+type TagDashboardVersionCommand struct {
+	Tag string `json:"tag" binding:"Required"`
+}
+
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
@@ -17,6 +17,9 @@ type Folder struct {
 	UpdatedBy string    `json:"updatedBy"`
 	Updated   time.Time `json:"updated"`
 	Version   int       `json:"version"`
+	ParentUid string    `json:"parentUid,omitempty"`
+	// Parents are the parent folders of a nested folder, starting with its root folder.
+	Parents []FolderSearchHit `json:"parents,omitempty"`
 }
 
 type FolderSearchHit struct {
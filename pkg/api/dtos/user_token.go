@@ -14,3 +14,25 @@ type UserToken struct {
 	CreatedAt              time.Time `json:"createdAt"`
 	SeenAt                 time.Time `json:"seenAt"`
 }
+
+type UserSession struct {
+	UserToken
+	UserId int64  `json:"userId"`
+	Login  string `json:"login"`
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+}
+
+type SearchUserSessionsResult struct {
+	TotalCount int64          `json:"totalCount"`
+	Sessions   []*UserSession `json:"sessions"`
+	Page       int            `json:"page"`
+	PerPage    int            `json:"perPage"`
+}
+
+type RevokeUserSessionsForm struct {
+	UserId int64 `json:"userId"`
+	OrgId  int64 `json:"orgId"`
+	// OlderThan is a duration such as 7d, sessions created before it are revoked
+	OlderThan string `json:"olderThan"`
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
@@ -114,14 +115,17 @@ func (hs *HTTPServer) UpdateFolderPermissions(c *models.ReqContext) response.Res
 		return response.Error(403, "Cannot remove own admin permission for a folder", nil)
 	}
 
+	old, err := g.GetAcl()
+	if err != nil {
+		return response.Error(500, "Error while checking dashboard permissions", err)
+	}
+	auditEvent := audit.NewEvent(c, audit.ActionFolderPermissionsUpdate, audit.KindFolder, folder.Uid).WithChange(aclAuditSummary(old), apiCmd.Items)
+
 	if !hs.AccessControl.IsDisabled() {
-		old, err := g.GetAcl()
-		if err != nil {
-			return response.Error(500, "Error while checking dashboard permissions", err)
-		}
 		if err := hs.updateDashboardAccessControl(c.Req.Context(), c.OrgId, folder.Uid, true, items, old); err != nil {
 			return response.Error(500, "Failed to create permission", err)
 		}
+		hs.logAuditEvent(c, auditEvent)
 		return response.Success("Dashboard permissions updated")
 	}
 
@@ -140,6 +144,7 @@ func (hs *HTTPServer) UpdateFolderPermissions(c *models.ReqContext) response.Res
 		return response.Error(500, "Failed to create permission", err)
 	}
 
+	hs.logAuditEvent(c, auditEvent)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "Folder permissions updated",
 		"id":      folder.Id,
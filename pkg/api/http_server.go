@@ -37,6 +37,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/plugincontext"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/comments"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
@@ -45,7 +46,10 @@ import (
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	datasourcehealth "github.com/grafana/grafana/pkg/services/datasources/health"
 	"github.com/grafana/grafana/pkg/services/datasources/permissions"
+	datasourcereferences "github.com/grafana/grafana/pkg/services/datasources/references"
+	datasourceusage "github.com/grafana/grafana/pkg/services/datasources/usage"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/export"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -165,6 +169,10 @@ type HTTPServer struct {
 	CoremodelRegistry            *registry.Generic
 	CoremodelStaticRegistry      *registry.Static
 	kvStore                      kvstore.KVStore
+	auditService                 audit.Service
+	dataSourceUsageService       *datasourceusage.Service
+	dataSourceReferencesService  *datasourcereferences.Service
+	dataSourceHealthService      *datasourcehealth.Service
 }
 
 type ServerOptions struct {
@@ -199,7 +207,8 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	teamsPermissionsService accesscontrol.TeamPermissionsService, folderPermissionsService accesscontrol.FolderPermissionsService,
 	dashboardPermissionsService accesscontrol.DashboardPermissionsService, dashboardVersionService dashver.Service,
 	starService star.Service, csrfService csrf.Service, coremodelRegistry *registry.Generic, coremodelStaticRegistry *registry.Static,
-	kvStore kvstore.KVStore,
+	kvStore kvstore.KVStore, auditService audit.Service, dataSourceUsageService *datasourceusage.Service,
+	dataSourceReferencesService *datasourcereferences.Service, dataSourceHealthService *datasourcehealth.Service,
 ) (*HTTPServer, error) {
 	web.Env = cfg.Env
 	m := web.New()
@@ -283,6 +292,10 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		CoremodelRegistry:            coremodelRegistry,
 		CoremodelStaticRegistry:      coremodelStaticRegistry,
 		kvStore:                      kvStore,
+		auditService:                 auditService,
+		dataSourceUsageService:       dataSourceUsageService,
+		dataSourceReferencesService:  dataSourceReferencesService,
+		dataSourceHealthService:      dataSourceHealthService,
 	}
 	if hs.Listener != nil {
 		hs.log.Debug("Using provided listener")
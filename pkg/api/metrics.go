@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/services/datasources/limiter"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/util"
@@ -32,6 +33,14 @@ func (hs *HTTPServer) handleQueryMetricsError(err error) *response.NormalRespons
 		return response.Error(http.StatusNotFound, "Plugin not found", err)
 	}
 
+	if errors.Is(err, limiter.ErrLimitReached) {
+		return response.Error(http.StatusTooManyRequests, util.Capitalize(err.Error()), err)
+	}
+
+	if errors.Is(err, backendplugin.ErrPluginUnhealthy) {
+		return response.Error(http.StatusServiceUnavailable, "Plugin unhealthy", err)
+	}
+
 	return response.Error(http.StatusInternalServerError, "Query data error", err)
 }
 
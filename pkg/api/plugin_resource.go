@@ -223,6 +223,11 @@ func handleCallResourceError(err error, reqCtx *models.ReqContext) {
 		return
 	}
 
+	if errors.Is(err, backendplugin.ErrPluginUnhealthy) {
+		reqCtx.JsonApiErr(503, "Plugin unhealthy", err)
+		return
+	}
+
 	if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
 		reqCtx.JsonApiErr(404, "Not found", err)
 		return
@@ -360,32 +360,71 @@ func (hs *HTTPServer) InstallPlugin(c *models.ReqContext) response.Response {
 
 	err := hs.pluginManager.Add(c.Req.Context(), pluginID, dto.Version)
 	if err != nil {
-		var dupeErr plugins.DuplicateError
-		if errors.As(err, &dupeErr) {
-			return response.Error(http.StatusConflict, "Plugin already installed", err)
-		}
-		var versionUnsupportedErr installer.ErrVersionUnsupported
-		if errors.As(err, &versionUnsupportedErr) {
-			return response.Error(http.StatusConflict, "Plugin version not supported", err)
-		}
-		var versionNotFoundErr installer.ErrVersionNotFound
-		if errors.As(err, &versionNotFoundErr) {
-			return response.Error(http.StatusNotFound, "Plugin version not found", err)
-		}
-		var clientError installer.Response4xxError
-		if errors.As(err, &clientError) {
-			return response.Error(clientError.StatusCode, clientError.Message, err)
-		}
-		if errors.Is(err, plugins.ErrInstallCorePlugin) {
-			return response.Error(http.StatusForbidden, "Cannot install or change a Core plugin", err)
-		}
+		return pluginInstallErrorResponse(err)
+	}
+
+	return response.JSON(http.StatusOK, []byte{})
+}
 
-		return response.Error(http.StatusInternalServerError, "Failed to install plugin", err)
+// maxPluginArchiveSize is the maximum size of the plugin archives uploaded to InstallPluginArchive
+const maxPluginArchiveSize = 512 << 20 // 512MB
+
+// InstallPluginArchive installs a plugin from the zip archive in the request body, the SHA256 checksum
+// of the archive is verified when provided in the sha256 query parameter.
+func (hs *HTTPServer) InstallPluginArchive(c *models.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	if err := installer.ValidatePluginID(pluginID); err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid plugin ID", err)
+	}
+
+	if c.Req.ContentLength > maxPluginArchiveSize {
+		return response.Error(http.StatusRequestEntityTooLarge, "Plugin archive is too large", nil)
+	}
+	archive := http.MaxBytesReader(c.Resp, c.Req.Body, maxPluginArchiveSize)
+
+	err := hs.pluginManager.AddFromArchive(c.Req.Context(), pluginID, archive, c.Query("sha256"))
+	if err != nil {
+		return pluginInstallErrorResponse(err)
 	}
 
 	return response.JSON(http.StatusOK, []byte{})
 }
 
+func pluginInstallErrorResponse(err error) response.Response {
+	var dupeErr plugins.DuplicateError
+	if errors.As(err, &dupeErr) {
+		return response.Error(http.StatusConflict, "Plugin already installed", err)
+	}
+	var versionUnsupportedErr installer.ErrVersionUnsupported
+	if errors.As(err, &versionUnsupportedErr) {
+		return response.Error(http.StatusConflict, "Plugin version not supported", err)
+	}
+	var versionNotFoundErr installer.ErrVersionNotFound
+	if errors.As(err, &versionNotFoundErr) {
+		return response.Error(http.StatusNotFound, "Plugin version not found", err)
+	}
+	var clientError installer.Response4xxError
+	if errors.As(err, &clientError) {
+		return response.Error(clientError.StatusCode, clientError.Message, err)
+	}
+	var checksumErr installer.ErrChecksumMismatch
+	if errors.As(err, &checksumErr) {
+		return response.Error(http.StatusBadRequest, "Plugin archive checksum mismatch", err)
+	}
+	var mismatchErr installer.ErrPluginIDMismatch
+	if errors.As(err, &mismatchErr) {
+		return response.Error(http.StatusBadRequest, "Plugin archive contains another plugin", err)
+	}
+	if errors.Is(err, installer.ErrInvalidPluginID) {
+		return response.Error(http.StatusBadRequest, "Invalid plugin ID", err)
+	}
+	if errors.Is(err, plugins.ErrInstallCorePlugin) {
+		return response.Error(http.StatusForbidden, "Cannot install or change a Core plugin", err)
+	}
+
+	return response.Error(http.StatusInternalServerError, "Failed to install plugin", err)
+}
+
 func (hs *HTTPServer) UninstallPlugin(c *models.ReqContext) response.Response {
 	pluginID := web.Params(c.Req)[":pluginId"]
 
@@ -406,6 +445,20 @@ func (hs *HTTPServer) UninstallPlugin(c *models.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, []byte{})
 }
 
+// ReloadPlugins rescans the plugin directories and loads the added, changed and removed plugins without restarting the server.
+func (hs *HTTPServer) ReloadPlugins(c *models.ReqContext) response.Response {
+	result, err := hs.pluginManager.Reload(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reload plugins", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// GetPluginsHealth returns the health of the backend plugins supervised by the plugin manager.
+func (hs *HTTPServer) GetPluginsHealth(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.pluginManager.HealthStatuses(c.Req.Context()))
+}
+
 func translatePluginRequestErrorToAPIError(err error) response.Response {
 	if errors.Is(err, backendplugin.ErrPluginNotRegistered) {
 		return response.Error(404, "Plugin not found", err)
@@ -423,6 +476,10 @@ func translatePluginRequestErrorToAPIError(err error) response.Response {
 		return response.Error(503, "Plugin unavailable", err)
 	}
 
+	if errors.Is(err, backendplugin.ErrPluginUnhealthy) {
+		return response.Error(503, "Plugin unhealthy", err)
+	}
+
 	return response.Error(500, "Plugin request failed", err)
 }
 
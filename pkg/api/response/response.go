@@ -130,6 +130,11 @@ func (r StreamingResponse) Body() []byte {
 	return nil
 }
 
+func (r StreamingResponse) SetHeader(key, value string) StreamingResponse {
+	r.header.Set(key, value)
+	return r
+}
+
 // WriteTo writes the response to the provided context.
 // Required to implement api.Response.
 func (r StreamingResponse) WriteTo(ctx *models.ReqContext) {
@@ -67,6 +67,8 @@ func (hs *HTTPServer) Search(c *models.ReqContext) response.Response {
 		FolderIds:     folderIDs,
 		Permission:    permission,
 		Sort:          sort,
+
+		IncludeSubfolders: c.QueryBool("includeSubfolders"),
 	}
 
 	err := hs.SearchService.SearchHandler(c.Req.Context(), &searchQuery)
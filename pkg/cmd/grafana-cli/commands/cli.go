@@ -44,6 +44,12 @@ func RunCLI(version string) int {
 				Value:   "",
 				EnvVars: []string{"GF_PLUGIN_URL"},
 			},
+			&cli.StringFlag{
+				Name:    "repositoryIndex",
+				Usage:   "URL of the index file or path to the directory of a private plugin repository to install plugins and their dependencies from",
+				Value:   "",
+				EnvVars: []string{"GF_PLUGIN_REPOSITORY_INDEX"},
+			},
 			&cli.BoolFlag{
 				Name:  "insecure",
 				Usage: "Skip TLS verification (insecure)",
@@ -169,6 +169,11 @@ var adminCommands = []*cli.Command{
 				Usage:  "Migrates passwords from unsecured fields to secure_json_data field. Return ok unless there is an error. Safe to execute multiple times.",
 				Action: runDbCommand(datamigrations.EncryptDatasourcePasswords),
 			},
+			{
+				Name:   "move-snapshots-to-storage",
+				Usage:  "Moves the dashboards of the snapshots stored in the database to the storage configured with the [snapshots] storage_url setting. Safe to execute multiple times.",
+				Action: runRunnerCommand(datamigrations.MoveSnapshotsToStorage),
+			},
 		},
 	},
 	{
@@ -0,0 +1,40 @@
+package datamigrations
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/runner"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	dashsnapstore "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
+	dashsnapsvc "github.com/grafana/grafana/pkg/services/dashboardsnapshots/service"
+)
+
+// MoveSnapshotsToStorage moves the dashboards of the snapshots stored in the database
+// to the storage configured with the [snapshots] storage_url setting.
+func MoveSnapshotsToStorage(_ utils.CommandLine, runner runner.Runner) error {
+	if runner.Cfg.SnapshotStorageURL == "" {
+		return errors.New("the snapshot storage is not configured, set storage_url in the [snapshots] section")
+	}
+
+	snapshotsService, err := dashsnapsvc.ProvideService(runner.Cfg, dashsnapstore.ProvideStore(runner.SQLStore), runner.SecretsService)
+	if err != nil {
+		return err
+	}
+
+	cmd := dashboardsnapshots.MoveSnapshotsToStorageCommand{}
+	if err := snapshotsService.MoveSnapshotsToStorage(context.Background(), &cmd); err != nil {
+		return err
+	}
+
+	logger.Info("\n")
+	logger.Infof("%s Moved %d dashboard snapshots to storage\n", color.GreenString("✔"), cmd.Moved)
+	if cmd.Failed > 0 {
+		logger.Warnf("Failed to move %d dashboard snapshots, check the logs for details and run the command again\n", cmd.Failed)
+	}
+	return nil
+}
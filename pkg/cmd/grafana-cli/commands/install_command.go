@@ -51,12 +51,18 @@ func (cmd Command) installCommand(c utils.CommandLine) error {
 	return InstallPlugin(pluginID, version, c)
 }
 
-// InstallPlugin downloads the plugin code as a zip file from the Grafana.com API
-// and then extracts the zip into the plugins directory.
+// InstallPlugin downloads the plugin code as a zip file from the Grafana.com API, or from the private plugin
+// repository when one is provided, and then extracts the zip into the plugins directory.
 func InstallPlugin(pluginID, version string, c utils.CommandLine) error {
 	skipTLSVerify := c.Bool("insecure")
 
 	i := installer.New(skipTLSVerify, services.GrafanaVersion, services.Logger)
+	if repositoryIndex := c.PluginRepositoryIndex(); repositoryIndex != "" {
+		if c.PluginURL() != "" {
+			return i.InstallArchive(context.Background(), pluginID, c.PluginURL(), "", c.PluginDirectory(), repositoryIndex)
+		}
+		return i.InstallFromRepository(context.Background(), pluginID, version, c.PluginDirectory(), repositoryIndex)
+	}
 	return i.Install(context.Background(), pluginID, version, c.PluginDirectory(), c.PluginURL(), c.PluginRepoURL())
 }
 
@@ -22,6 +22,7 @@ type CommandLine interface {
 	PluginDirectory() string
 	PluginRepoURL() string
 	PluginURL() string
+	PluginRepositoryIndex() string
 }
 
 type ApiClient interface {
@@ -61,3 +62,7 @@ func (c *ContextCommandLine) PluginRepoURL() string {
 func (c *ContextCommandLine) PluginURL() string {
 	return c.String("pluginUrl")
 }
+
+func (c *ContextCommandLine) PluginRepositoryIndex() string {
+	return c.String("repositoryIndex")
+}
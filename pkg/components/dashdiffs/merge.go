@@ -0,0 +1,370 @@
+package dashdiffs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// ErrInvalidMergeTarget occurs when one of the merged dashboards is not a JSON object.
+var ErrInvalidMergeTarget = errors.New("dashdiff: merged dashboard is not an object")
+
+// mergeOverrideKeys are always taken from the saved dashboard, so the merged dashboard can be saved over it.
+var mergeOverrideKeys = map[string]bool{"id": true, "uid": true, "version": true}
+
+// MergeConflict is a change made in both edits of the dashboard that cannot be merged automatically.
+// A nil value means the property or panel does not exist in that version.
+type MergeConflict struct {
+	// Path is the top level property of the dashboard, panels[id=<panel id>] for a panel, or panels for the
+	// panels without id, which are merged together.
+	Path    string      `json:"path"`
+	PanelID *int64      `json:"panelId,omitempty"`
+	Base    interface{} `json:"base"`
+	Ours    interface{} `json:"ours"`
+	Theirs  interface{} `json:"theirs"`
+}
+
+// MergeResult holds the merged dashboard and the conflicting changes.
+// Conflicting properties and panels keep the value of the saved dashboard.
+type MergeResult struct {
+	Dashboard *simplejson.Json `json:"dashboard"`
+	Conflicts []MergeConflict  `json:"conflicts"`
+}
+
+// Merge computes the three-way merge of two edits of a dashboard with their common base version.
+// Ours is the dashboard being saved and theirs is the dashboard saved in the meantime. Changes are
+// merged per top level property, and per panel for the panels, matched by their id. The panels without
+// id, or which are not objects, are merged together as a list.
+func Merge(base, ours, theirs *simplejson.Json) (*MergeResult, error) {
+	baseObj, err := toMergeObject(base)
+	if err != nil {
+		return nil, err
+	}
+	oursObj, err := toMergeObject(ours)
+	if err != nil {
+		return nil, err
+	}
+	theirsObj, err := toMergeObject(theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]interface{})
+	conflicts := make([]MergeConflict, 0)
+
+	for _, key := range unionKeys(baseObj, oursObj, theirsObj) {
+		if key == "panels" {
+			continue
+		}
+		if mergeOverrideKeys[key] {
+			if v, ok := theirsObj[key]; ok {
+				merged[key] = v
+			}
+			continue
+		}
+
+		value, exists, conflict := mergeValue(lookupValue(baseObj, key), lookupValue(oursObj, key), lookupValue(theirsObj, key))
+		if conflict {
+			conflicts = append(conflicts, MergeConflict{
+				Path:   key,
+				Base:   baseObj[key],
+				Ours:   oursObj[key],
+				Theirs: theirsObj[key],
+			})
+		}
+		if exists {
+			merged[key] = value
+		}
+	}
+
+	panels, panelConflicts := mergePanels(toPanelList(baseObj["panels"]), toPanelList(oursObj["panels"]), toPanelList(theirsObj["panels"]))
+	if panels != nil {
+		merged["panels"] = panels
+	}
+	conflicts = append(conflicts, panelConflicts...)
+
+	return &MergeResult{Dashboard: simplejson.NewFromAny(merged), Conflicts: conflicts}, nil
+}
+
+type optionalValue struct {
+	value  interface{}
+	exists bool
+}
+
+func lookupValue(obj map[string]interface{}, key string) optionalValue {
+	v, ok := obj[key]
+	return optionalValue{value: v, exists: ok}
+}
+
+// mergeValue returns the merged value and whether it exists. When both edits changed the
+// value differently, the value of theirs is returned along with a conflict.
+func mergeValue(base, ours, theirs optionalValue) (interface{}, bool, bool) {
+	switch {
+	case sameValue(ours, theirs):
+		return theirs.value, theirs.exists, false
+	case sameValue(base, ours):
+		return theirs.value, theirs.exists, false
+	case sameValue(base, theirs):
+		return ours.value, ours.exists, false
+	}
+	return theirs.value, theirs.exists, true
+}
+
+// panelEntry is a panel of a dashboard, panels without id are kept as they are in raw.
+type panelEntry struct {
+	id         int64
+	panel      map[string]interface{}
+	identified bool
+	raw        interface{}
+}
+
+// mergePanels merges the panels matched by id. The order of the saved dashboard is kept,
+// panels added in ours are appended and get a new id when it is already used in theirs.
+// The panels without id keep their position in the saved dashboard, unless only ours changed them,
+// in which case the panels without id of ours are appended.
+func mergePanels(base, ours, theirs []panelEntry) ([]interface{}, []MergeConflict) {
+	if base == nil && ours == nil && theirs == nil {
+		return nil, nil
+	}
+
+	unidentifiedConflict, keepOursUnidentified := mergeUnidentifiedPanels(base, ours, theirs)
+	allOurs, allTheirs := ours, theirs
+	base, ours, theirs = identifiedPanels(base), identifiedPanels(ours), identifiedPanels(theirs)
+
+	baseByID := indexPanels(base)
+	oursByID := indexPanels(ours)
+	theirsByID := indexPanels(theirs)
+
+	var maxID int64
+	for _, list := range [][]panelEntry{base, ours, theirs} {
+		for _, p := range list {
+			if p.id > maxID {
+				maxID = p.id
+			}
+		}
+	}
+
+	merged := make([]interface{}, 0, len(theirs))
+	conflicts := make([]MergeConflict, 0)
+	if unidentifiedConflict != nil {
+		conflicts = append(conflicts, *unidentifiedConflict)
+	}
+	done := make(map[int64]bool)
+
+	mergePanel := func(id int64) {
+		done[id] = true
+		basePanel := panelValue(baseByID, id)
+		oursPanel := panelValue(oursByID, id)
+		theirsPanel := panelValue(theirsByID, id)
+
+		// a panel added in both edits is only a conflict when the edits added the same panel id,
+		// otherwise the panel added in ours gets a new id
+		if !basePanel.exists && oursPanel.exists && theirsPanel.exists && !sameValue(oursPanel, theirsPanel) {
+			merged = append(merged, theirsPanel.value)
+			maxID++
+			renumbered := copyPanel(oursPanel.value.(map[string]interface{}))
+			renumbered["id"] = maxID
+			merged = append(merged, renumbered)
+			return
+		}
+
+		value, exists, conflict := mergeValue(basePanel, oursPanel, theirsPanel)
+		if conflict {
+			panelID := id
+			conflicts = append(conflicts, MergeConflict{
+				Path:    fmt.Sprintf("panels[id=%d]", id),
+				PanelID: &panelID,
+				Base:    basePanel.value,
+				Ours:    oursPanel.value,
+				Theirs:  theirsPanel.value,
+			})
+		}
+		if exists {
+			merged = append(merged, value)
+		}
+	}
+
+	for _, p := range allTheirs {
+		if !p.identified {
+			if !keepOursUnidentified {
+				merged = append(merged, p.raw)
+			}
+			continue
+		}
+		if !done[p.id] {
+			mergePanel(p.id)
+		}
+	}
+	for _, p := range ours {
+		if !done[p.id] {
+			mergePanel(p.id)
+		}
+	}
+	// panels deleted in theirs and changed in ours
+	for _, p := range base {
+		if !done[p.id] {
+			mergePanel(p.id)
+		}
+	}
+	if keepOursUnidentified {
+		for _, p := range allOurs {
+			if !p.identified {
+				merged = append(merged, p.raw)
+			}
+		}
+	}
+
+	return merged, conflicts
+}
+
+// mergeUnidentifiedPanels merges the panels without id of the dashboards as a list. It returns the conflict
+// when both edits changed them differently, and true when the panels without id of ours are kept rather
+// than the ones of theirs.
+func mergeUnidentifiedPanels(base, ours, theirs []panelEntry) (*MergeConflict, bool) {
+	baseList := unidentifiedPanels(base)
+	oursList := unidentifiedPanels(ours)
+	theirsList := unidentifiedPanels(theirs)
+
+	baseValue := optionalValue{value: baseList, exists: true}
+	oursValue := optionalValue{value: oursList, exists: true}
+	theirsValue := optionalValue{value: theirsList, exists: true}
+	_, _, conflict := mergeValue(baseValue, oursValue, theirsValue)
+	if conflict {
+		return &MergeConflict{Path: "panels", Base: baseList, Ours: oursList, Theirs: theirsList}, false
+	}
+	return nil, !sameValue(oursValue, theirsValue) && sameValue(baseValue, theirsValue)
+}
+
+func unidentifiedPanels(panels []panelEntry) []interface{} {
+	list := make([]interface{}, 0)
+	for _, p := range panels {
+		if !p.identified {
+			list = append(list, p.raw)
+		}
+	}
+	return list
+}
+
+func identifiedPanels(panels []panelEntry) []panelEntry {
+	if panels == nil {
+		return nil
+	}
+	list := make([]panelEntry, 0, len(panels))
+	for _, p := range panels {
+		if p.identified {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+func indexPanels(panels []panelEntry) map[int64]map[string]interface{} {
+	byID := make(map[int64]map[string]interface{}, len(panels))
+	for _, p := range panels {
+		byID[p.id] = p.panel
+	}
+	return byID
+}
+
+func panelValue(byID map[int64]map[string]interface{}, id int64) optionalValue {
+	p, ok := byID[id]
+	if !ok {
+		return optionalValue{}
+	}
+	return optionalValue{value: p, exists: true}
+}
+
+func copyPanel(panel map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(panel))
+	for k, v := range panel {
+		c[k] = v
+	}
+	return c
+}
+
+func toPanelList(v interface{}) []panelEntry {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	panels := make([]panelEntry, 0, len(list))
+	for _, item := range list {
+		panel, ok := item.(map[string]interface{})
+		if !ok {
+			panels = append(panels, panelEntry{raw: item})
+			continue
+		}
+		id, ok := panelID(panel["id"])
+		if !ok {
+			panels = append(panels, panelEntry{raw: item})
+			continue
+		}
+		panels = append(panels, panelEntry{id: id, panel: panel, identified: true, raw: item})
+	}
+	return panels
+}
+
+func panelID(v interface{}) (int64, bool) {
+	switch id := v.(type) {
+	case json.Number:
+		n, err := id.Int64()
+		return n, err == nil
+	case float64:
+		return int64(id), true
+	case int:
+		return int64(id), true
+	case int64:
+		return id, true
+	}
+	return 0, false
+}
+
+func toMergeObject(dash *simplejson.Json) (map[string]interface{}, error) {
+	if dash == nil {
+		return nil, ErrInvalidMergeTarget
+	}
+	obj, err := dash.Map()
+	if err != nil {
+		return nil, ErrInvalidMergeTarget
+	}
+	return obj, nil
+}
+
+func unionKeys(objs ...map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, obj := range objs {
+		for k := range obj {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sameValue compares the JSON encoding of two values, object keys are encoded in sorted order.
+func sameValue(a, b optionalValue) bool {
+	if a.exists != b.exists {
+		return false
+	}
+	if !a.exists {
+		return true
+	}
+	aBytes, err := json.Marshal(a.value)
+	if err != nil {
+		return false
+	}
+	bBytes, err := json.Marshal(b.value)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aBytes, bBytes)
+}
@@ -0,0 +1,176 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestMerge(t *testing.T) {
+	const baseJSON = `{
+		"uid": "abc",
+		"version": 1,
+		"title": "Base",
+		"tags": ["a"],
+		"refresh": "5s",
+		"panels": [
+			{"id": 1, "type": "graph", "title": "CPU"},
+			{"id": 2, "type": "graph", "title": "Memory"},
+			{"id": 3, "type": "stat", "title": "Disk"}
+		]
+	}`
+
+	parse := func(t *testing.T, s string) *simplejson.Json {
+		t.Helper()
+		j, err := simplejson.NewJson([]byte(s))
+		require.NoError(t, err)
+		return j
+	}
+
+	panelTitles := func(res *MergeResult) []string {
+		titles := make([]string, 0)
+		for i := range res.Dashboard.Get("panels").MustArray() {
+			titles = append(titles, res.Dashboard.Get("panels").GetIndex(i).Get("title").MustString())
+		}
+		return titles
+	}
+
+	t.Run("should merge changes to different properties and panels", func(t *testing.T) {
+		ours := parse(t, `{
+			"uid": "abc",
+			"version": 1,
+			"title": "Ours",
+			"tags": ["a"],
+			"refresh": "5s",
+			"panels": [
+				{"id": 1, "type": "graph", "title": "CPU usage"},
+				{"id": 2, "type": "graph", "title": "Memory"},
+				{"id": 3, "type": "stat", "title": "Disk"}
+			]
+		}`)
+		theirs := parse(t, `{
+			"uid": "abc",
+			"version": 2,
+			"title": "Base",
+			"tags": ["a", "b"],
+			"panels": [
+				{"id": 1, "type": "graph", "title": "CPU"},
+				{"id": 2, "type": "timeseries", "title": "Memory"}
+			]
+		}`)
+
+		res, err := Merge(parse(t, baseJSON), ours, theirs)
+		require.NoError(t, err)
+		require.Empty(t, res.Conflicts)
+
+		assert.Equal(t, 2, res.Dashboard.Get("version").MustInt())
+		assert.Equal(t, "Ours", res.Dashboard.Get("title").MustString())
+		assert.Equal(t, []string{"a", "b"}, res.Dashboard.Get("tags").MustStringArray())
+		_, ok := res.Dashboard.CheckGet("refresh")
+		assert.False(t, ok)
+		assert.Equal(t, []string{"CPU usage", "Memory"}, panelTitles(res))
+		assert.Equal(t, "timeseries", res.Dashboard.Get("panels").GetIndex(1).Get("type").MustString())
+	})
+
+	t.Run("should return conflicts for changes to the same property or panel", func(t *testing.T) {
+		ours := parse(t, `{
+			"uid": "abc",
+			"version": 1,
+			"title": "Ours",
+			"panels": [
+				{"id": 1, "type": "graph", "title": "CPU ours"},
+				{"id": 2, "type": "graph", "title": "Memory"},
+				{"id": 3, "type": "stat", "title": "Disk ours"}
+			]
+		}`)
+		theirs := parse(t, `{
+			"uid": "abc",
+			"version": 2,
+			"title": "Theirs",
+			"panels": [
+				{"id": 1, "type": "graph", "title": "CPU theirs"},
+				{"id": 2, "type": "graph", "title": "Memory"}
+			]
+		}`)
+
+		res, err := Merge(parse(t, baseJSON), ours, theirs)
+		require.NoError(t, err)
+		require.Len(t, res.Conflicts, 3)
+
+		assert.Equal(t, "title", res.Conflicts[0].Path)
+		assert.Equal(t, "Ours", res.Conflicts[0].Ours)
+		assert.Equal(t, "Theirs", res.Conflicts[0].Theirs)
+
+		assert.Equal(t, "panels[id=1]", res.Conflicts[1].Path)
+		require.NotNil(t, res.Conflicts[1].PanelID)
+		assert.EqualValues(t, 1, *res.Conflicts[1].PanelID)
+
+		// deleted in theirs and changed in ours
+		assert.Equal(t, "panels[id=3]", res.Conflicts[2].Path)
+		assert.Nil(t, res.Conflicts[2].Theirs)
+
+		// conflicting changes keep the saved dashboard
+		assert.Equal(t, "Theirs", res.Dashboard.Get("title").MustString())
+		assert.Equal(t, []string{"CPU theirs", "Memory"}, panelTitles(res))
+	})
+
+	t.Run("should give a new id to a panel added with an id used by the saved dashboard", func(t *testing.T) {
+		ours := parse(t, `{
+			"panels": [
+				{"id": 1, "type": "graph", "title": "CPU"},
+				{"id": 2, "type": "text", "title": "Ours"}
+			]
+		}`)
+		theirs := parse(t, `{
+			"panels": [
+				{"id": 1, "type": "graph", "title": "CPU"},
+				{"id": 2, "type": "text", "title": "Theirs"}
+			]
+		}`)
+
+		res, err := Merge(parse(t, `{"panels": [{"id": 1, "type": "graph", "title": "CPU"}]}`), ours, theirs)
+		require.NoError(t, err)
+		require.Empty(t, res.Conflicts)
+		assert.Equal(t, []string{"CPU", "Theirs", "Ours"}, panelTitles(res))
+		assert.Equal(t, 3, res.Dashboard.Get("panels").GetIndex(2).Get("id").MustInt())
+	})
+
+	t.Run("should keep the panels without id", func(t *testing.T) {
+		base := parse(t, `{"panels": [{"type": "text", "title": "Notes"}, {"id": 1, "type": "graph", "title": "CPU"}]}`)
+		ours := parse(t, `{"panels": [{"type": "text", "title": "Notes"}, {"id": 1, "type": "graph", "title": "CPU usage"}]}`)
+		theirs := parse(t, `{"panels": [{"type": "text", "title": "Notes"}, {"id": 1, "type": "graph", "title": "CPU"}, {"id": 2, "type": "stat", "title": "Disk"}]}`)
+
+		res, err := Merge(base, ours, theirs)
+		require.NoError(t, err)
+		require.Empty(t, res.Conflicts)
+		assert.Equal(t, []string{"Notes", "CPU usage", "Disk"}, panelTitles(res))
+
+		// only ours changed the panels without id
+		ours = parse(t, `{"panels": [{"type": "text", "title": "Ours"}, {"id": 1, "type": "graph", "title": "CPU"}]}`)
+		res, err = Merge(base, ours, theirs)
+		require.NoError(t, err)
+		require.Empty(t, res.Conflicts)
+		assert.Equal(t, []string{"CPU", "Disk", "Ours"}, panelTitles(res))
+	})
+
+	t.Run("should return a conflict for panels without id changed in both edits", func(t *testing.T) {
+		base := parse(t, `{"panels": [{"type": "text", "title": "Notes"}]}`)
+		ours := parse(t, `{"panels": [{"type": "text", "title": "Ours"}]}`)
+		theirs := parse(t, `{"panels": [{"type": "text", "title": "Theirs"}, {"id": 1, "type": "graph", "title": "CPU"}]}`)
+
+		res, err := Merge(base, ours, theirs)
+		require.NoError(t, err)
+		require.Len(t, res.Conflicts, 1)
+		assert.Equal(t, "panels", res.Conflicts[0].Path)
+		assert.Nil(t, res.Conflicts[0].PanelID)
+		assert.Equal(t, []string{"Theirs", "CPU"}, panelTitles(res))
+	})
+
+	t.Run("should fail when a dashboard is not an object", func(t *testing.T) {
+		_, err := Merge(parse(t, baseJSON), parse(t, `[]`), parse(t, baseJSON))
+		require.ErrorIs(t, err, ErrInvalidMergeTarget)
+	})
+}
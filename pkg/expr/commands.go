@@ -286,6 +286,10 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeThreshold is the CMDType for checking if a threshold has been crossed.
+	TypeThreshold
+	// TypeFilter is the CMDType for filtering and relabeling by labels.
+	TypeFilter
 )
 
 func (gt CommandType) String() string {
@@ -298,6 +302,10 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
+	case TypeFilter:
+		return "filter"
 	default:
 		return "unknown"
 	}
@@ -314,6 +322,10 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "threshold":
+		return TypeThreshold, nil
+	case "filter":
+		return TypeFilter, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
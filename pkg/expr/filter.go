@@ -0,0 +1,212 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// FilterMode is whether a FilterCommand keeps or drops the items matching its matchers.
+type FilterMode string
+
+const (
+	FilterModeKeep FilterMode = "keep"
+	FilterModeDrop FilterMode = "drop"
+)
+
+// LabelMatcher matches a label against a value, with the same operators as Prometheus
+// label matchers: "=", "!=", "=~" and "!~". Regular expressions are fully anchored.
+type LabelMatcher struct {
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// FilterCommand is an expression command that keeps or drops the numbers or series of a variable
+// based on their labels, and optionally renames labels so the results can be joined with other
+// variables in a math expression. A label cannot be renamed to a label the item already has.
+type FilterCommand struct {
+	VarToFilter  string
+	Mode         FilterMode
+	Matchers     []*labels.Matcher
+	RenameLabels map[string]string
+	refID        string
+}
+
+// NewFilterCommand creates a new FilterCommand.
+func NewFilterCommand(refID, varToFilter string, mode FilterMode, matchers []LabelMatcher, renameLabels map[string]string) (*FilterCommand, error) {
+	switch mode {
+	case "":
+		mode = FilterModeKeep
+	case FilterModeKeep, FilterModeDrop:
+	default:
+		return nil, fmt.Errorf("filter mode %q is not supported. Supported only: [keep,drop]", mode)
+	}
+
+	cmd := &FilterCommand{
+		VarToFilter:  varToFilter,
+		Mode:         mode,
+		Matchers:     make([]*labels.Matcher, 0, len(matchers)),
+		RenameLabels: renameLabels,
+		refID:        refID,
+	}
+
+	for _, m := range matchers {
+		matchType, err := parseMatchType(m.Type)
+		if err != nil {
+			return nil, err
+		}
+		matcher, err := labels.NewMatcher(matchType, m.Label, m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher for label %q: %w", m.Label, err)
+		}
+		cmd.Matchers = append(cmd.Matchers, matcher)
+	}
+
+	targets := make(map[string]string, len(renameLabels))
+	for from, to := range renameLabels {
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("label names to rename must not be empty")
+		}
+		if other, ok := targets[to]; ok {
+			if other > from {
+				other, from = from, other
+			}
+			return nil, fmt.Errorf("labels %q and %q cannot both be renamed to %q", other, from, to)
+		}
+		targets[to] = from
+	}
+
+	return cmd, nil
+}
+
+func parseMatchType(s string) (labels.MatchType, error) {
+	switch s {
+	case "=", "":
+		return labels.MatchEqual, nil
+	case "!=":
+		return labels.MatchNotEqual, nil
+	case "=~":
+		return labels.MatchRegexp, nil
+	case "!~":
+		return labels.MatchNotRegexp, nil
+	default:
+		return 0, fmt.Errorf("matcher type %q is not supported. Supported only: [=,!=,=~,!~]", s)
+	}
+}
+
+// UnmarshalFilterCommand creates a FilterCommand from Grafana's frontend query.
+func UnmarshalFilterCommand(rn *rawNode) (*FilterCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to filter for refId %v", rn.RefID)
+	}
+	varToFilter, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected filter variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	varToFilter = strings.TrimPrefix(varToFilter, "$")
+
+	var mode FilterMode
+	if rawMode, ok := rn.Query["mode"]; ok {
+		m, ok := rawMode.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected filter mode to be a string, got %T for refId %v", rawMode, rn.RefID)
+		}
+		mode = FilterMode(m)
+	}
+
+	var matchers []LabelMatcher
+	if err := unmarshalQueryField(rn, "matchers", &matchers); err != nil {
+		return nil, err
+	}
+
+	var renameLabels map[string]string
+	if err := unmarshalQueryField(rn, "renameLabels", &renameLabels); err != nil {
+		return nil, err
+	}
+
+	if len(matchers) == 0 && len(renameLabels) == 0 {
+		return nil, fmt.Errorf("filter expression requires matchers or labels to rename for refId %v", rn.RefID)
+	}
+
+	cmd, err := NewFilterCommand(rn.RefID, varToFilter, mode, matchers, renameLabels)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter command for refId %v: %w", rn.RefID, err)
+	}
+	return cmd, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *FilterCommand) NeedsVars() []string {
+	return []string{fc.VarToFilter}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (fc *FilterCommand) Execute(_ context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[fc.VarToFilter].Values {
+		if fc.matches(val.GetLabels()) != (fc.Mode == FilterModeKeep) {
+			continue
+		}
+
+		lbls, err := fc.rename(val.GetLabels())
+		if err != nil {
+			return newRes, err
+		}
+		switch v := val.(type) {
+		case mathexp.Number:
+			n := mathexp.NewNumber(fc.refID, lbls)
+			n.SetValue(v.GetFloat64Value())
+			newRes.Values = append(newRes.Values, n)
+		case mathexp.Series:
+			s := mathexp.NewSeries(fc.refID, lbls, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				s.SetPoint(i, t, f)
+			}
+			newRes.Values = append(newRes.Values, s)
+		default:
+			return newRes, fmt.Errorf("can only filter type number or series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// matches returns true if the labels fulfill all matchers. Missing labels have an empty value.
+func (fc *FilterCommand) matches(lbls data.Labels) bool {
+	for _, m := range fc.Matchers {
+		if !m.Matches(lbls[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// rename returns a copy of the labels with the labels in RenameLabels renamed. It fails when a label is renamed
+// to a label which is kept, as one of the values would be lost.
+func (fc *FilterCommand) rename(lbls data.Labels) (data.Labels, error) {
+	if lbls == nil && len(fc.RenameLabels) == 0 {
+		return nil, nil
+	}
+	renamed := make(data.Labels, len(lbls))
+	for k, v := range lbls {
+		if to, ok := fc.RenameLabels[k]; ok {
+			if _, exists := lbls[to]; exists {
+				if _, renamedAway := fc.RenameLabels[to]; !renamedAway {
+					return nil, fmt.Errorf("cannot rename label %q to %q, the label already exists in {%s}", k, to, lbls)
+				}
+			}
+			k = to
+		}
+		renamed[k] = v
+	}
+	return renamed, nil
+}
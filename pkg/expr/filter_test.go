@@ -0,0 +1,123 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalFilterCommand(t *testing.T) {
+	t.Run("should parse matchers and renamed labels", func(t *testing.T) {
+		cmd, err := UnmarshalFilterCommand(&rawNode{RefID: "B", Query: map[string]interface{}{
+			"expression": "$A",
+			"mode":       "drop",
+			"matchers": []interface{}{
+				map[string]interface{}{"label": "host", "type": "!~", "value": "web.*"},
+			},
+			"renameLabels": map[string]interface{}{"instance": "host"},
+		}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		require.Equal(t, FilterModeDrop, cmd.Mode)
+		require.Len(t, cmd.Matchers, 1)
+		require.Equal(t, map[string]string{"instance": "host"}, cmd.RenameLabels)
+	})
+
+	t.Run("should fail without matchers or labels to rename", func(t *testing.T) {
+		_, err := UnmarshalFilterCommand(&rawNode{RefID: "B", Query: map[string]interface{}{"expression": "$A"}})
+		require.Error(t, err)
+	})
+
+	t.Run("should fail on invalid regular expression", func(t *testing.T) {
+		_, err := UnmarshalFilterCommand(&rawNode{RefID: "B", Query: map[string]interface{}{
+			"expression": "$A",
+			"matchers": []interface{}{
+				map[string]interface{}{"label": "host", "type": "=~", "value": "web("},
+			},
+		}})
+		require.Error(t, err)
+	})
+
+	t.Run("should fail on labels renamed to the same label", func(t *testing.T) {
+		_, err := UnmarshalFilterCommand(&rawNode{RefID: "B", Query: map[string]interface{}{
+			"expression":   "$A",
+			"renameLabels": map[string]interface{}{"instance": "host", "node": "host"},
+		}})
+		require.EqualError(t, err, `invalid filter command for refId B: labels "instance" and "node" cannot both be renamed to "host"`)
+	})
+
+	t.Run("should fail on unknown mode", func(t *testing.T) {
+		_, err := UnmarshalFilterCommand(&rawNode{RefID: "B", Query: map[string]interface{}{
+			"expression":   "$A",
+			"mode":         "join",
+			"renameLabels": map[string]interface{}{"instance": "host"},
+		}})
+		require.Error(t, err)
+	})
+}
+
+func TestFilterExecute(t *testing.T) {
+	number := func(lbls data.Labels, f float64) mathexp.Number {
+		n := mathexp.NewNumber("A", lbls)
+		n.SetValue(ptr.Float64(f))
+		return n
+	}
+	series := mathexp.NewSeries("A", data.Labels{"host": "web02", "instance": "web02:9100"}, 1)
+	series.SetPoint(0, time.Unix(1, 0), ptr.Float64(3))
+
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{
+		number(data.Labels{"host": "web01", "instance": "web01:9100"}, 1),
+		number(data.Labels{"host": "db01"}, 2),
+		series,
+	}}}
+
+	t.Run("should keep matching items and rename labels", func(t *testing.T) {
+		cmd, err := NewFilterCommand("B", "A", FilterModeKeep, []LabelMatcher{{Label: "host", Type: "=~", Value: "web.*"}}, map[string]string{"instance": "target"})
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		require.Equal(t, data.Labels{"host": "web01", "target": "web01:9100"}, res.Values[0].GetLabels())
+		require.Equal(t, data.Labels{"host": "web02", "target": "web02:9100"}, res.Values[1].GetLabels())
+		require.Equal(t, ptr.Float64(3), res.Values[1].(mathexp.Series).GetValue(0))
+
+		// the input is left untouched
+		require.Equal(t, data.Labels{"host": "web02", "instance": "web02:9100"}, series.GetLabels())
+	})
+
+	t.Run("should drop matching items", func(t *testing.T) {
+		cmd, err := NewFilterCommand("B", "A", FilterModeDrop, []LabelMatcher{{Label: "instance", Type: "!=", Value: ""}}, nil)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, data.Labels{"host": "db01"}, res.Values[0].GetLabels())
+		require.Equal(t, ptr.Float64(2), res.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("should fail to rename a label to an existing label", func(t *testing.T) {
+		cmd, err := NewFilterCommand("B", "A", FilterModeKeep, nil, map[string]string{"instance": "host"})
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(context.Background(), vars)
+		require.EqualError(t, err, `cannot rename label "instance" to "host", the label already exists in {host=web01, instance=web01:9100}`)
+	})
+
+	t.Run("should swap labels renamed to each other", func(t *testing.T) {
+		cmd, err := NewFilterCommand("B", "A", FilterModeKeep, []LabelMatcher{{Label: "host", Value: "web01"}}, map[string]string{"instance": "host", "host": "instance"})
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, data.Labels{"host": "web01:9100", "instance": "web01"}, res.Values[0].GetLabels())
+	})
+}
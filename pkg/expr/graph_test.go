@@ -225,6 +225,38 @@ func TestServicebuildPipeLine(t *testing.T) {
 			},
 			expectedOrder: []string{"B", "A"},
 		},
+		{
+			name: "threshold and filter commands",
+			req: &Request{
+				Queries: []Query{
+					{
+						RefID:      "A",
+						DataSource: DataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "$B",
+							"type": "threshold",
+							"conditions": [{"evaluator": {"type": "gt", "params": [5]}}]
+						}`),
+					},
+					{
+						RefID:      "B",
+						DataSource: DataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "$C",
+							"type": "filter",
+							"matchers": [{"label": "host", "type": "=~", "value": "web.*"}]
+						}`),
+					},
+					{
+						RefID: "C",
+						DataSource: &models.DataSource{
+							Uid: "Fake",
+						},
+					},
+				},
+			},
+			expectedOrder: []string{"C", "B", "A"},
+		},
 	}
 	s := Service{}
 	for _, tt := range tests {
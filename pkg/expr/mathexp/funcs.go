@@ -1,8 +1,11 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
 
@@ -68,6 +71,79 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"exp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             exp,
+	},
+	"sqrt": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             sqrt,
+	},
+	"pow": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             pow,
+	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"increase": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      increase,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkDurationArg(1),
+	},
+	"moving_sum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingSum,
+		Check:  checkDurationArg(1),
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumSum,
+	},
+	"time_shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      timeShift,
+		Check:  checkDurationArg(1),
+	},
+	"timestamp": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      timestamp,
+	},
+	"hour": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      hour,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -303,3 +379,104 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// exp returns e**x for each result in NumberSet, SeriesSet, or Scalar
+func exp(e *State, varSet Results) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, math.Exp)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// sqrt returns the square root value for each result in NumberSet, SeriesSet, or Scalar
+func sqrt(e *State, varSet Results) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, math.Sqrt)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// pow returns each result in NumberSet, SeriesSet, or Scalar raised to the power of the scalar exponent.
+func pow(e *State, varSet Results, exponent Results) (Results, error) {
+	y, err := scalarArg("pow", exponent)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatWithScalar(e, varSet, y, math.Pow)
+}
+
+// clampMin returns the value for each result in NumberSet, SeriesSet, or Scalar,
+// replaced by the scalar minimum where it is lower than it.
+func clampMin(e *State, varSet Results, minimum Results) (Results, error) {
+	m, err := scalarArg("clamp_min", minimum)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatWithScalar(e, varSet, m, math.Max)
+}
+
+// clampMax returns the value for each result in NumberSet, SeriesSet, or Scalar,
+// replaced by the scalar maximum where it is greater than it.
+func clampMax(e *State, varSet Results, maximum Results) (Results, error) {
+	m, err := scalarArg("clamp_max", maximum)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatWithScalar(e, varSet, m, math.Min)
+}
+
+// perFloatWithScalar is like perFloat for functions that take an additional scalar argument.
+// If the scalar is null, NaN is returned for each value.
+func perFloatWithScalar(e *State, varSet Results, scalar *float64, floatF func(x, y float64) float64) (Results, error) {
+	y := math.NaN()
+	if scalar != nil {
+		y = *scalar
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(x float64) float64 {
+			return floatF(x, y)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// scalarArg returns the value of a function argument that must evaluate to a single scalar.
+func scalarArg(funcName string, arg Results) (*float64, error) {
+	if len(arg.Values) != 1 {
+		return nil, fmt.Errorf("%s: expected a single scalar argument, got %d values", funcName, len(arg.Values))
+	}
+	s, ok := arg.Values[0].(Scalar)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a scalar argument, got %v", funcName, arg.Values[0].Type())
+	}
+	return s.GetFloat64Value(), nil
+}
+
+// checkDurationArg returns a parse time check that the string argument at position idx is a valid duration.
+func checkDurationArg(idx int) func(*parse.Tree, *parse.FuncNode) error {
+	return func(t *parse.Tree, f *parse.FuncNode) error {
+		arg, ok := f.Args[idx].(*parse.StringNode)
+		if !ok {
+			return fmt.Errorf("parse: expected a duration string for argument %v of %s", idx, f.Name)
+		}
+		if _, err := gtime.ParseDuration(arg.Text); err != nil {
+			return fmt.Errorf("parse: invalid duration %q for argument %v of %s: %w", arg.Text, idx, f.Name, err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,218 @@
+package mathexp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// delta returns the difference between each point and the previous point of each series in the SeriesSet.
+// The first point, and points where either value is null, are null.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, "delta", func(s Series) (Series, error) {
+		return perConsecutivePoints(e, s, func(prev, cur float64, _ time.Duration) *float64 {
+			d := cur - prev
+			return &d
+		}), nil
+	})
+}
+
+// increase is like delta, but treats a decrease in value as a counter reset,
+// in which case the increase is the value of the point itself.
+func increase(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, "increase", func(s Series) (Series, error) {
+		return perConsecutivePoints(e, s, func(prev, cur float64, _ time.Duration) *float64 {
+			inc := counterIncrease(prev, cur)
+			return &inc
+		}), nil
+	})
+}
+
+// rate returns the per-second increase between each point and the previous point of each series in the SeriesSet,
+// handling counter resets the same way as increase.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, "rate", func(s Series) (Series, error) {
+		return perConsecutivePoints(e, s, func(prev, cur float64, elapsed time.Duration) *float64 {
+			if elapsed <= 0 {
+				return nil
+			}
+			r := counterIncrease(prev, cur) / elapsed.Seconds()
+			return &r
+		}), nil
+	})
+}
+
+func counterIncrease(prev, cur float64) float64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// movingAvg returns the average of the non-null values in the window ending at each point of each series in the SeriesSet.
+func movingAvg(e *State, varSet Results, rawWindow string) (Results, error) {
+	return perWindow(e, varSet, "moving_avg", rawWindow, func(sum float64, count int) float64 {
+		return sum / float64(count)
+	})
+}
+
+// movingSum returns the sum of the non-null values in the window ending at each point of each series in the SeriesSet.
+func movingSum(e *State, varSet Results, rawWindow string) (Results, error) {
+	return perWindow(e, varSet, "moving_sum", rawWindow, func(sum float64, _ int) float64 {
+		return sum
+	})
+}
+
+// cumSum returns the running total of the non-null values of each series in the SeriesSet.
+// Null points stay null.
+func cumSum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, "cumsum", func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		sum := float64(0)
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			sum += *f
+			nF := sum
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries, nil
+	})
+}
+
+// timeShift moves each point of each series in the SeriesSet forward in time by the given duration,
+// so that "1h" compares the values of an hour ago with the current ones. Negative durations move points back.
+func timeShift(e *State, varSet Results, rawOffset string) (Results, error) {
+	offset, err := gtime.ParseDuration(rawOffset)
+	if err != nil {
+		return Results{}, fmt.Errorf("time_shift: invalid duration %q: %w", rawOffset, err)
+	}
+	return perSeries(e, varSet, "time_shift", func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(offset), f)
+		}
+		return newSeries, nil
+	})
+}
+
+// timestamp returns the time of each point of each series in the SeriesSet as seconds since the Unix epoch.
+// Null points stay null.
+func timestamp(e *State, varSet Results) (Results, error) {
+	return perPointTime(e, varSet, "timestamp", func(t time.Time) float64 {
+		return float64(t.UnixNano()) / float64(time.Second)
+	})
+}
+
+// hour returns the hour of the day in UTC, from 0 to 23, of each point of each series in the SeriesSet.
+// Null points stay null.
+func hour(e *State, varSet Results) (Results, error) {
+	return perPointTime(e, varSet, "hour", func(t time.Time) float64 {
+		return float64(t.UTC().Hour())
+	})
+}
+
+// perSeries passes each series in varSet to seriesF, sorted from oldest to newest.
+// The series in varSet are not modified. An error is returned if varSet holds anything but series.
+func perSeries(e *State, varSet Results, funcName string, seriesF func(s Series) (Series, error)) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		s, ok := res.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("%s: expected a series, got %v", funcName, res.Type())
+		}
+		sorted := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			sorted.SetPoint(i, t, f)
+		}
+		sorted.SortByTime(false)
+
+		newSeries, err := seriesF(sorted)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// perConsecutivePoints calls pointF with the values of each point and its previous point, and the time elapsed between them.
+// The first point, and points where either value is null, are null.
+func perConsecutivePoints(e *State, s Series, pointF func(prev, cur float64, elapsed time.Duration) *float64) Series {
+	newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if i == 0 || f == nil {
+			newSeries.SetPoint(i, t, nil)
+			continue
+		}
+		prevT, prevF := s.GetPoint(i - 1)
+		if prevF == nil {
+			newSeries.SetPoint(i, t, nil)
+			continue
+		}
+		newSeries.SetPoint(i, t, pointF(*prevF, *f, t.Sub(prevT)))
+	}
+	return newSeries
+}
+
+// perWindow calls windowF with the sum and count of the non-null values of the points in the window ending at each point,
+// including the point itself. Points whose window has no non-null values are null.
+func perWindow(e *State, varSet Results, funcName, rawWindow string, windowF func(sum float64, count int) float64) (Results, error) {
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return Results{}, fmt.Errorf("%s: invalid window %q: %w", funcName, rawWindow, err)
+	}
+	if window <= 0 {
+		return Results{}, fmt.Errorf("%s: window must be positive, got %q", funcName, rawWindow)
+	}
+
+	return perSeries(e, varSet, funcName, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		start := 0
+		for i := 0; i < s.Len(); i++ {
+			t := s.GetTime(i)
+			for !s.GetTime(start).After(t.Add(-window)) {
+				start++
+			}
+
+			sum, count := float64(0), 0
+			for j := start; j <= i; j++ {
+				if f := s.GetValue(j); f != nil {
+					sum += *f
+					count++
+				}
+			}
+			if count == 0 {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			nF := windowF(sum, count)
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries, nil
+	})
+}
+
+// perPointTime sets the value of each non-null point of each series in varSet to timeF of the point's time.
+func perPointTime(e *State, varSet Results, funcName string, timeF func(t time.Time) float64) (Results, error) {
+	return perSeries(e, varSet, funcName, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			nF := timeF(t)
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries, nil
+	})
+}
@@ -0,0 +1,218 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesFuncs(t *testing.T) {
+	counter := Vars{
+		"A": Results{
+			[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(20, 0), float64Pointer(25)},
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(30, 0), nil},
+					tp{time.Unix(40, 0), float64Pointer(30)}),
+			},
+		},
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "delta sorts the series and leaves nulls",
+			expr:      "delta($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), nil},
+						tp{time.Unix(10, 0), float64Pointer(10)},
+						tp{time.Unix(20, 0), float64Pointer(5)},
+						tp{time.Unix(30, 0), nil},
+						tp{time.Unix(40, 0), nil}),
+				},
+			},
+		},
+		{
+			name: "increase handles counter resets",
+			expr: "increase($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil,
+							tp{time.Unix(0, 0), float64Pointer(10)},
+							tp{time.Unix(10, 0), float64Pointer(15)},
+							tp{time.Unix(20, 0), float64Pointer(4)}),
+					},
+				},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), nil},
+						tp{time.Unix(10, 0), float64Pointer(5)},
+						tp{time.Unix(20, 0), float64Pointer(4)}),
+				},
+			},
+		},
+		{
+			name:      "rate returns the per-second increase",
+			expr:      "rate($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), nil},
+						tp{time.Unix(10, 0), float64Pointer(1)},
+						tp{time.Unix(20, 0), float64Pointer(0.5)},
+						tp{time.Unix(30, 0), nil},
+						tp{time.Unix(40, 0), nil}),
+				},
+			},
+		},
+		{
+			name:      "moving_avg skips nulls in the window",
+			expr:      `moving_avg($A, "20s")`,
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), float64Pointer(10)},
+						tp{time.Unix(10, 0), float64Pointer(15)},
+						tp{time.Unix(20, 0), float64Pointer(22.5)},
+						tp{time.Unix(30, 0), float64Pointer(25)},
+						tp{time.Unix(40, 0), float64Pointer(30)}),
+				},
+			},
+		},
+		{
+			name:      "moving_sum",
+			expr:      `moving_sum($A, "15s")`,
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), float64Pointer(10)},
+						tp{time.Unix(10, 0), float64Pointer(30)},
+						tp{time.Unix(20, 0), float64Pointer(45)},
+						tp{time.Unix(30, 0), float64Pointer(25)},
+						tp{time.Unix(40, 0), float64Pointer(30)}),
+				},
+			},
+		},
+		{
+			name:      "cumsum",
+			expr:      "cumsum($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), float64Pointer(10)},
+						tp{time.Unix(10, 0), float64Pointer(30)},
+						tp{time.Unix(20, 0), float64Pointer(55)},
+						tp{time.Unix(30, 0), nil},
+						tp{time.Unix(40, 0), float64Pointer(85)}),
+				},
+			},
+		},
+		{
+			name:      "time_shift combined with binary operation",
+			expr:      `$A - time_shift($A, "10s")`,
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(20, 0), float64Pointer(5)},
+						tp{time.Unix(10, 0), float64Pointer(10)},
+						tp{time.Unix(30, 0), nil},
+						tp{time.Unix(40, 0), nil}),
+				},
+			},
+		},
+		{
+			name: "timestamp and hour",
+			expr: "timestamp($A) + hour($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil,
+							tp{time.Unix(7200, 0), float64Pointer(1)},
+							tp{time.Unix(10800, 0), nil}),
+					},
+				},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(7200, 0), float64Pointer(7202)},
+						tp{time.Unix(10800, 0), nil}),
+				},
+			},
+		},
+		{
+			name:     "invalid window - should error",
+			expr:     `moving_avg($A, "soon")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "window must be a string - should error",
+			expr:     `moving_sum($A, 5)`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "rate on scalar - should error",
+			expr:     `rate(1)`,
+			newErrIs: require.Error,
+		},
+		{
+			name: "rate on number - should error",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				tt.execErrIs(t, err)
+				if tt.results.Values != nil {
+					require.Equal(t, tt.results, res)
+				}
+			}
+		})
+	}
+}
@@ -159,3 +159,106 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestScalarArgFuncs(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expr     string
+		vars     Vars
+		newErrIs require.ErrorAssertionFunc
+		results  Results
+	}{
+		{
+			name: "pow on number",
+			expr: "pow($A, 2)",
+			vars: Vars{
+				"A": Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+			},
+			newErrIs: require.NoError,
+			results:  Results{[]Value{makeNumber("", nil, float64Pointer(9))}},
+		},
+		{
+			name:     "sqrt on scalar",
+			expr:     "sqrt(16)",
+			vars:     Vars{},
+			newErrIs: require.NoError,
+			results:  Results{[]Value{NewScalar("", float64Pointer(4))}},
+		},
+		{
+			name:     "exp on scalar",
+			expr:     "exp(0)",
+			vars:     Vars{},
+			newErrIs: require.NoError,
+			results:  Results{[]Value{NewScalar("", float64Pointer(1))}},
+		},
+		{
+			name: "clamp_min and clamp_max on series",
+			expr: "clamp_max(clamp_min($A, 0), 10)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil,
+							tp{time.Unix(5, 0), float64Pointer(-5)},
+							tp{time.Unix(10, 0), float64Pointer(5)},
+							tp{time.Unix(15, 0), float64Pointer(15)}),
+					},
+				},
+			},
+			newErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(5, 0), float64Pointer(0)},
+						tp{time.Unix(10, 0), float64Pointer(5)},
+						tp{time.Unix(15, 0), float64Pointer(10)}),
+				},
+			},
+		},
+		{
+			name: "clamp_min with negative scalar",
+			expr: "clamp_min($A, -1)",
+			vars: Vars{
+				"A": Results{[]Value{makeNumber("", nil, float64Pointer(-3))}},
+			},
+			newErrIs: require.NoError,
+			results:  Results{[]Value{makeNumber("", nil, float64Pointer(-1))}},
+		},
+		{
+			name:     "pow without exponent - should error",
+			expr:     "pow($A)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "clamp_min with series bound - should error",
+			expr:     "clamp_min($A, $B)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "arguments without comma - should error",
+			expr:     "pow($A 2)",
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				require.NoError(t, err)
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
+
+func TestScalarArgFuncsNullSemantics(t *testing.T) {
+	e, err := New("pow($A, 2)")
+	require.NoError(t, err)
+	res, err := e.Execute("", Vars{
+		"A": Results{[]Value{makeNumber("", nil, nil)}},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Values, 1)
+	require.True(t, math.IsNaN(*res.Values[0].(Number).GetFloat64Value()))
+}
@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -298,6 +299,8 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// VectorMatching is set when the operation has "on", "ignoring", "group_left" or "group_right" modifiers.
+	VectorMatching *VectorMatching
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -306,19 +309,69 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
-	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
+	return fmt.Sprintf("%s %s%s %s", b.Args[0], b.Operator.val, b.VectorMatching, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
-	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
+	return fmt.Sprintf("%s%s(%s, %s)", b.Operator.val, b.VectorMatching, b.Args[0], b.Args[1])
 }
 
 // Check performs parse time checking on the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Check(t *Tree) error {
+	for _, arg := range b.Args {
+		if err := arg.Check(t); err != nil {
+			return err
+		}
+		if b.VectorMatching != nil && arg.Return() == TypeScalar {
+			return fmt.Errorf("parse: vector matching in %s is not allowed with a scalar operand", b)
+		}
+	}
 	return nil
 }
 
+// VectorMatchCardinality is the cardinality of the matching between the items of the two sides of a binary operation.
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne matches each item to at most one item of the other side.
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne matches many items of the left side to one item of the right side (group_left).
+	CardManyToOne
+	// CardOneToMany matches one item of the left side to many items of the right side (group_right).
+	CardOneToMany
+)
+
+// VectorMatching describes how the items of the two sides of a binary operation are matched by their labels,
+// like the vector matching of Prometheus.
+type VectorMatching struct {
+	Card VectorMatchCardinality
+	// On is true if MatchingLabels are the labels to match on, and false if they are the labels to ignore.
+	On             bool
+	MatchingLabels []string
+	// Include are the labels of the "one" side copied to the results of a many-to-one or one-to-many match.
+	Include []string
+}
+
+// String returns the modifiers of the vector matching as they are written in an expression.
+func (m *VectorMatching) String() string {
+	if m == nil {
+		return ""
+	}
+	modifier := "ignoring"
+	if m.On {
+		modifier = "on"
+	}
+	s := fmt.Sprintf(" %s(%s)", modifier, strings.Join(m.MatchingLabels, ", "))
+	switch m.Card {
+	case CardManyToOne:
+		s += fmt.Sprintf(" group_left(%s)", strings.Join(m.Include, ", "))
+	case CardOneToMany:
+		s += fmt.Sprintf(" group_right(%s)", strings.Join(m.Include, ", "))
+	}
+	return s
+}
+
 // Return returns the result type of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Return() ReturnType {
 	t0 := b.Args[0].Return()
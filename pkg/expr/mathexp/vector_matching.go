@@ -0,0 +1,107 @@
+package mathexp
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// vectorMatchingUnion creates Union objects like union, but matches the items of aResults and bResults
+// by the labels selected with the "on" or "ignoring" modifiers of a binary operation, the same way
+// Prometheus matches vectors. Items without a match on the other side are dropped.
+//
+// For a one-to-one match the labels of the Union are the matching labels ("on") or the labels of A
+// without the ignored labels ("ignoring"). For group_left the labels are those of the "many" side A,
+// plus the included labels copied from the "one" side B; group_right is the mirror of group_left.
+func vectorMatchingUnion(aResults, bResults Results, matching *parse.VectorMatching) ([]*Union, error) {
+	unions := []*Union{}
+
+	many, one := aResults, bResults
+	if matching.Card == parse.CardOneToMany {
+		many, one = bResults, aResults
+	}
+
+	oneBySignature := make(map[string]Value, len(one.Values))
+	for _, v := range one.Values {
+		sig := matchingSignature(v.GetLabels(), matching).String()
+		if _, ok := oneBySignature[sig]; ok {
+			side := "right"
+			if matching.Card == parse.CardOneToMany {
+				side = "left"
+			}
+			return nil, fmt.Errorf("found duplicate items for the match group {%s} on the %s hand-side of the operation, many-to-many matching is not allowed", sig, side)
+		}
+		oneBySignature[sig] = v
+	}
+
+	seenSignatures := make(map[string]struct{}, len(many.Values))
+	seenLabels := make(map[string]struct{}, len(many.Values))
+	for _, m := range many.Values {
+		sig := matchingSignature(m.GetLabels(), matching).String()
+		o, ok := oneBySignature[sig]
+		if !ok {
+			continue
+		}
+		if matching.Card == parse.CardOneToOne {
+			if _, ok := seenSignatures[sig]; ok {
+				return nil, fmt.Errorf("found duplicate items for the match group {%s} on the left hand-side of the operation, use group_left or group_right for many-to-one matching", sig)
+			}
+			seenSignatures[sig] = struct{}{}
+		}
+
+		labels := matchingResultLabels(m.GetLabels(), o.GetLabels(), matching)
+		key := labels.String()
+		if _, ok := seenLabels[key]; ok {
+			return nil, fmt.Errorf("multiple matches for labels {%s}, the grouping labels must ensure unique matches", key)
+		}
+		seenLabels[key] = struct{}{}
+
+		u := &Union{Labels: labels, A: m, B: o}
+		if matching.Card == parse.CardOneToMany {
+			u.A, u.B = o, m
+		}
+		unions = append(unions, u)
+	}
+	return unions, nil
+}
+
+// matchingSignature returns the labels used to match an item with the items of the other side of an operation.
+func matchingSignature(labels data.Labels, matching *parse.VectorMatching) data.Labels {
+	sig := data.Labels{}
+	if matching.On {
+		for _, name := range matching.MatchingLabels {
+			if v, ok := labels[name]; ok {
+				sig[name] = v
+			}
+		}
+		return sig
+	}
+	for k, v := range labels {
+		sig[k] = v
+	}
+	for _, name := range matching.MatchingLabels {
+		delete(sig, name)
+	}
+	return sig
+}
+
+// matchingResultLabels returns the labels of the result of an operation between the matched items many and one.
+func matchingResultLabels(many, one data.Labels, matching *parse.VectorMatching) data.Labels {
+	if matching.Card == parse.CardOneToOne {
+		return matchingSignature(many, matching)
+	}
+	labels := data.Labels{}
+	for k, v := range many {
+		labels[k] = v
+	}
+	for _, name := range matching.Include {
+		if v, ok := one[name]; ok {
+			labels[name] = v
+		} else {
+			delete(labels, name)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,140 @@
+package mathexp
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+)
+
+func TestVectorMatching(t *testing.T) {
+	errors := Results{
+		Values: Values{
+			makeNumber("", data.Labels{"job": "api", "code": "500", "datasource": "prom"}, ptr.Float64(5)),
+			makeNumber("", data.Labels{"job": "api", "code": "503", "datasource": "prom"}, ptr.Float64(2)),
+			makeNumber("", data.Labels{"job": "web", "code": "500", "datasource": "prom"}, ptr.Float64(1)),
+		},
+	}
+	requests := Results{
+		Values: Values{
+			makeNumber("", data.Labels{"job": "api", "datasource": "loki", "team": "a"}, ptr.Float64(100)),
+			makeNumber("", data.Labels{"job": "web", "datasource": "loki", "team": "b"}, ptr.Float64(50)),
+			makeNumber("", data.Labels{"job": "db", "datasource": "loki", "team": "c"}, ptr.Float64(10)),
+		},
+	}
+	vars := Vars{"E": errors, "R": requests}
+
+	var tests = []struct {
+		name     string
+		expr     string
+		newErrIs require.ErrorAssertionFunc
+		execErr  string
+		results  Results
+	}{
+		{
+			name:     "one-to-one on label",
+			expr:     "$R / on(job) $R",
+			newErrIs: require.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"job": "api"}, ptr.Float64(1)),
+					makeNumber("", data.Labels{"job": "web"}, ptr.Float64(1)),
+					makeNumber("", data.Labels{"job": "db"}, ptr.Float64(1)),
+				},
+			},
+		},
+		{
+			name:     "one-to-one ignoring labels",
+			expr:     "$R - ignoring(datasource, team) $R",
+			newErrIs: require.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"job": "api"}, ptr.Float64(0)),
+					makeNumber("", data.Labels{"job": "web"}, ptr.Float64(0)),
+					makeNumber("", data.Labels{"job": "db"}, ptr.Float64(0)),
+				},
+			},
+		},
+		{
+			name:     "many-to-one with group_left includes labels of the one side",
+			expr:     "$E / on(job) group_left(team) $R",
+			newErrIs: require.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"job": "api", "code": "500", "datasource": "prom", "team": "a"}, ptr.Float64(0.05)),
+					makeNumber("", data.Labels{"job": "api", "code": "503", "datasource": "prom", "team": "a"}, ptr.Float64(0.02)),
+					makeNumber("", data.Labels{"job": "web", "code": "500", "datasource": "prom", "team": "b"}, ptr.Float64(0.02)),
+				},
+			},
+		},
+		{
+			name:     "one-to-many with group_right keeps the order of the many side",
+			expr:     "$R * on(job) group_right $E",
+			newErrIs: require.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"job": "api", "code": "500", "datasource": "prom"}, ptr.Float64(500)),
+					makeNumber("", data.Labels{"job": "api", "code": "503", "datasource": "prom"}, ptr.Float64(200)),
+					makeNumber("", data.Labels{"job": "web", "code": "500", "datasource": "prom"}, ptr.Float64(50)),
+				},
+			},
+		},
+		{
+			name:     "quoted label names",
+			expr:     `$R / on("job") $R`,
+			newErrIs: require.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"job": "api"}, ptr.Float64(1)),
+					makeNumber("", data.Labels{"job": "web"}, ptr.Float64(1)),
+					makeNumber("", data.Labels{"job": "db"}, ptr.Float64(1)),
+				},
+			},
+		},
+		{
+			name:     "one-to-one with duplicates on the left side - should error",
+			expr:     "$E / on(job) $R",
+			newErrIs: require.NoError,
+			execErr:  "use group_left or group_right",
+		},
+		{
+			name:     "many-to-one with duplicates on the one side - should error",
+			expr:     "$R / on(job) group_left $E",
+			newErrIs: require.NoError,
+			execErr:  "many-to-many matching is not allowed",
+		},
+		{
+			name:     "group_left without unique results - should error",
+			expr:     "$E / on(job) group_left(code) $R",
+			newErrIs: require.NoError,
+			execErr:  "the grouping labels must ensure unique matches",
+		},
+		{
+			name:     "vector matching with a scalar - should error",
+			expr:     "$E / on(job) 2",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "group_left without on or ignoring - should error",
+			expr:     "$E / group_left $R",
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e == nil {
+				return
+			}
+			res, err := e.Execute("", vars)
+			if tt.execErr != "" {
+				require.ErrorContains(t, err, tt.execErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.results, res)
+		})
+	}
+}
@@ -61,6 +61,22 @@ func (rn *rawNode) GetCommandType() (c CommandType, err error) {
 	return ParseCommandType(typeString)
 }
 
+// unmarshalQueryField decodes the optional field of the query into v.
+func unmarshalQueryField(rn *rawNode, field string, v interface{}) error {
+	raw, ok := rn.Query[field]
+	if !ok || raw == nil {
+		return nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to parse %s for refId %v: %w", field, rn.RefID, err)
+	}
+	return nil
+}
+
 // String returns a string representation of the node. In particular for
 // %v formatting in error messages.
 func (b *baseNode) String() string {
@@ -119,6 +135,10 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = UnmarshalResampleCommand(rn)
 	case TypeClassicConditions:
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
+	case TypeThreshold:
+		node.Command, err = UnmarshalThresholdCommand(rn)
+	case TypeFilter:
+		node.Command, err = UnmarshalFilterCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in '%v' not implemented", commandType, rn.RefID)
 	}
@@ -0,0 +1,185 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// ThresholdType is the comparison performed by a threshold evaluator.
+type ThresholdType string
+
+const (
+	// ThresholdIsAbove is true if the value is greater than the first parameter.
+	ThresholdIsAbove ThresholdType = "gt"
+	// ThresholdIsBelow is true if the value is less than the first parameter.
+	ThresholdIsBelow ThresholdType = "lt"
+	// ThresholdIsWithinRange is true if the value is between the two parameters, exclusive.
+	ThresholdIsWithinRange ThresholdType = "within_range"
+	// ThresholdIsOutsideRange is true if the value is less than the first or greater than the second parameter.
+	ThresholdIsOutsideRange ThresholdType = "outside_range"
+)
+
+// ThresholdEvaluator compares a value against one or two parameters.
+type ThresholdEvaluator struct {
+	Type   ThresholdType `json:"type"`
+	Params []float64     `json:"params"`
+}
+
+func (te ThresholdEvaluator) validate() error {
+	switch te.Type {
+	case ThresholdIsAbove, ThresholdIsBelow:
+		if len(te.Params) != 1 {
+			return fmt.Errorf("threshold type %q requires exactly one parameter, got %d", te.Type, len(te.Params))
+		}
+	case ThresholdIsWithinRange, ThresholdIsOutsideRange:
+		if len(te.Params) != 2 {
+			return fmt.Errorf("threshold type %q requires exactly two parameters, got %d", te.Type, len(te.Params))
+		}
+		if te.Params[0] > te.Params[1] {
+			return fmt.Errorf("threshold type %q requires the first parameter to be less than the second", te.Type)
+		}
+	default:
+		return fmt.Errorf("threshold type %q is not supported. Supported only: [gt,lt,within_range,outside_range]", te.Type)
+	}
+	return nil
+}
+
+// Eval returns true if f fulfills the evaluator. It is false for NaN.
+func (te ThresholdEvaluator) Eval(f float64) bool {
+	switch te.Type {
+	case ThresholdIsAbove:
+		return f > te.Params[0]
+	case ThresholdIsBelow:
+		return f < te.Params[0]
+	case ThresholdIsWithinRange:
+		return f > te.Params[0] && f < te.Params[1]
+	case ThresholdIsOutsideRange:
+		return f < te.Params[0] || f > te.Params[1]
+	}
+	return false
+}
+
+// ThresholdCommand is an expression command that returns 1 for each value that crosses a threshold, else 0.
+// If an UnloadEvaluator is set, the points of a series that cross the threshold keep returning 1
+// until a point fulfills the UnloadEvaluator, so values close to the threshold do not flap. Numbers and
+// scalars have no previous value, so an UnloadEvaluator can only be applied to series.
+type ThresholdCommand struct {
+	ReferenceVar    string
+	Evaluator       ThresholdEvaluator
+	UnloadEvaluator *ThresholdEvaluator
+	refID           string
+}
+
+// NewThresholdCommand creates a new ThresholdCommand.
+func NewThresholdCommand(refID, referenceVar string, evaluator ThresholdEvaluator, unloadEvaluator *ThresholdEvaluator) (*ThresholdCommand, error) {
+	if err := evaluator.validate(); err != nil {
+		return nil, err
+	}
+	if unloadEvaluator != nil {
+		if err := unloadEvaluator.validate(); err != nil {
+			return nil, fmt.Errorf("invalid unload evaluator: %w", err)
+		}
+	}
+	return &ThresholdCommand{
+		ReferenceVar:    referenceVar,
+		Evaluator:       evaluator,
+		UnloadEvaluator: unloadEvaluator,
+		refID:           refID,
+	}, nil
+}
+
+type thresholdCondition struct {
+	Evaluator       ThresholdEvaluator  `json:"evaluator"`
+	UnloadEvaluator *ThresholdEvaluator `json:"unloadEvaluator"`
+}
+
+// UnmarshalThresholdCommand creates a ThresholdCommand from Grafana's frontend query.
+func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to apply the threshold to for refId %v", rn.RefID)
+	}
+	referenceVar, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	referenceVar = strings.TrimPrefix(referenceVar, "$")
+
+	var conditions []thresholdCondition
+	if err := unmarshalQueryField(rn, "conditions", &conditions); err != nil {
+		return nil, err
+	}
+	if len(conditions) != 1 {
+		return nil, fmt.Errorf("threshold expression requires exactly one condition, got %d for refId %v", len(conditions), rn.RefID)
+	}
+
+	cmd, err := NewThresholdCommand(rn.RefID, referenceVar, conditions[0].Evaluator, conditions[0].UnloadEvaluator)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold command for refId %v: %w", rn.RefID, err)
+	}
+	return cmd, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (tc *ThresholdCommand) NeedsVars() []string {
+	return []string{tc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute. Null values stay null.
+func (tc *ThresholdCommand) Execute(_ context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[tc.ReferenceVar].Values {
+		if _, ok := val.(mathexp.Series); !ok && tc.UnloadEvaluator != nil {
+			return newRes, fmt.Errorf("an unload evaluator can only be applied to type series, got type %v", val.Type())
+		}
+		switch v := val.(type) {
+		case mathexp.Number:
+			n := mathexp.NewNumber(tc.refID, v.GetLabels())
+			n.SetValue(tc.eval(v.GetFloat64Value(), false))
+			newRes.Values = append(newRes.Values, n)
+		case mathexp.Series:
+			s := mathexp.NewSeries(tc.refID, v.GetLabels(), v.Len())
+			firing := false
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				res := tc.eval(f, firing)
+				if res != nil {
+					firing = *res == 1
+				}
+				s.SetPoint(i, t, res)
+			}
+			newRes.Values = append(newRes.Values, s)
+		case mathexp.Scalar:
+			newRes.Values = append(newRes.Values, mathexp.NewScalar(tc.refID, tc.eval(v.GetFloat64Value(), false)))
+		default:
+			return newRes, fmt.Errorf("can only apply a threshold to type number or series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// eval returns 1 if f crosses the threshold, else 0. If firing is true and there is an unload evaluator,
+// 1 is returned until f fulfills the unload evaluator.
+func (tc *ThresholdCommand) eval(f *float64, firing bool) *float64 {
+	if f == nil {
+		return nil
+	}
+	var result bool
+	if firing && tc.UnloadEvaluator != nil {
+		// like every comparison, NaN does not keep the threshold crossed
+		result = !math.IsNaN(*f) && !tc.UnloadEvaluator.Eval(*f)
+	} else {
+		result = tc.Evaluator.Eval(*f)
+	}
+	r := float64(0)
+	if result {
+		r = 1
+	}
+	return &r
+}
@@ -0,0 +1,150 @@
+package expr
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalThresholdCommand(t *testing.T) {
+	var tests = []struct {
+		name          string
+		query         map[string]interface{}
+		expectedError string
+	}{
+		{
+			name: "valid within range",
+			query: map[string]interface{}{
+				"expression": "$A",
+				"conditions": []interface{}{
+					map[string]interface{}{"evaluator": map[string]interface{}{"type": "within_range", "params": []interface{}{1.0, 5.0}}},
+				},
+			},
+		},
+		{
+			name: "valid with unload evaluator",
+			query: map[string]interface{}{
+				"expression": "$A",
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"evaluator":       map[string]interface{}{"type": "gt", "params": []interface{}{5.0}},
+						"unloadEvaluator": map[string]interface{}{"type": "lt", "params": []interface{}{3.0}},
+					},
+				},
+			},
+		},
+		{
+			name:          "missing conditions",
+			query:         map[string]interface{}{"expression": "$A"},
+			expectedError: "requires exactly one condition",
+		},
+		{
+			name: "unknown type",
+			query: map[string]interface{}{
+				"expression": "$A",
+				"conditions": []interface{}{
+					map[string]interface{}{"evaluator": map[string]interface{}{"type": "eq", "params": []interface{}{1.0}}},
+				},
+			},
+			expectedError: "is not supported",
+		},
+		{
+			name: "wrong number of params",
+			query: map[string]interface{}{
+				"expression": "$A",
+				"conditions": []interface{}{
+					map[string]interface{}{"evaluator": map[string]interface{}{"type": "outside_range", "params": []interface{}{1.0}}},
+				},
+			},
+			expectedError: "requires exactly two parameters",
+		},
+		{
+			name: "invalid unload evaluator",
+			query: map[string]interface{}{
+				"expression": "$A",
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"evaluator":       map[string]interface{}{"type": "gt", "params": []interface{}{5.0}},
+						"unloadEvaluator": map[string]interface{}{"type": "lt"},
+					},
+				},
+			},
+			expectedError: "invalid unload evaluator",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", Query: test.query})
+			if test.expectedError != "" {
+				require.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestThresholdExecute(t *testing.T) {
+	number := func(f *float64) mathexp.Number {
+		n := mathexp.NewNumber("", data.Labels{"host": "a"})
+		n.SetValue(f)
+		return n
+	}
+
+	t.Run("should evaluate numbers", func(t *testing.T) {
+		cmd, err := NewThresholdCommand("B", "A", ThresholdEvaluator{Type: ThresholdIsOutsideRange, Params: []float64{1, 5}}, nil)
+		require.NoError(t, err)
+
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{
+			number(ptr.Float64(0)), number(ptr.Float64(3)), number(ptr.Float64(6)), number(nil), number(ptr.Float64(math.NaN())),
+		}}}
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+
+		expected := []*float64{ptr.Float64(1), ptr.Float64(0), ptr.Float64(1), nil, ptr.Float64(0)}
+		require.Len(t, res.Values, len(expected))
+		for i, v := range res.Values {
+			require.Equal(t, expected[i], v.(mathexp.Number).GetFloat64Value())
+			require.Equal(t, data.Labels{"host": "a"}, v.GetLabels())
+		}
+	})
+
+	t.Run("should apply hysteresis to series", func(t *testing.T) {
+		unload := ThresholdEvaluator{Type: ThresholdIsBelow, Params: []float64{3}}
+		cmd, err := NewThresholdCommand("B", "A", ThresholdEvaluator{Type: ThresholdIsAbove, Params: []float64{5}}, &unload)
+		require.NoError(t, err)
+
+		values := []*float64{ptr.Float64(4), ptr.Float64(6), ptr.Float64(4), nil, ptr.Float64(2), ptr.Float64(4)}
+		series := mathexp.NewSeries("A", nil, len(values))
+		for i, v := range values {
+			series.SetPoint(i, time.Unix(int64(i), 0), v)
+		}
+
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series}}})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+
+		result := res.Values[0].(mathexp.Series)
+		expected := []*float64{ptr.Float64(0), ptr.Float64(1), ptr.Float64(1), nil, ptr.Float64(0), ptr.Float64(0)}
+		for i, e := range expected {
+			require.Equal(t, e, result.GetValue(i), "point %d", i)
+		}
+	})
+
+	t.Run("should fail to apply hysteresis to numbers", func(t *testing.T) {
+		unload := ThresholdEvaluator{Type: ThresholdIsBelow, Params: []float64{3}}
+		cmd, err := NewThresholdCommand("B", "A", ThresholdEvaluator{Type: ThresholdIsAbove, Params: []float64{5}}, &unload)
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(context.Background(), mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{number(ptr.Float64(6))}}})
+		require.EqualError(t, err, "an unload evaluator can only be applied to type series, got type numberSet")
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -158,6 +159,9 @@ type ListOptions struct {
 
 type FileStorage interface {
 	Get(ctx context.Context, path string) (*File, error)
+	// Read opens the contents of the file for reading without loading them in memory.
+	// The returned reader is nil if the file does not exist, and must be closed by the caller otherwise.
+	Read(ctx context.Context, path string) (io.ReadCloser, error)
 	Delete(ctx context.Context, path string) error
 	Upsert(ctx context.Context, command *UpsertFileCommand) error
 
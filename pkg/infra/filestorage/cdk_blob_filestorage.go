@@ -5,13 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"gocloud.dev/blob"
+	"gocloud.dev/blob/fileblob"
+	"gocloud.dev/blob/gcsblob"
 	"gocloud.dev/gcerrors"
 
-	_ "gocloud.dev/blob/fileblob"
 	_ "gocloud.dev/blob/memblob"
 )
 
@@ -31,6 +33,24 @@ func NewCdkBlobStorage(log log.Logger, bucket *blob.Bucket, rootFolder string, f
 	}, filter, rootFolder)
 }
 
+// OpenBucket opens the bucket at the given URL, either a directory on disk (file:///path),
+// a Google Cloud Storage bucket (gs://bucket) or an S3 or S3-compatible bucket (s3://bucket).
+func OpenBucket(ctx context.Context, bucketURL string) (*blob.Bucket, error) {
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case fileblob.Scheme, gcsblob.Scheme:
+		return blob.DefaultURLMux().OpenBucketURL(ctx, u)
+	case s3Scheme:
+		return (&s3URLOpener{}).OpenBucketURL(ctx, u)
+	default:
+		return nil, fmt.Errorf("unsupported bucket URL scheme %q", u.Scheme)
+	}
+}
+
 func (c cdkBlobStorage) Get(ctx context.Context, filePath string) (*File, error) {
 	contents, err := c.bucket.ReadAll(ctx, strings.ToLower(filePath))
 	if err != nil {
@@ -71,6 +91,18 @@ func (c cdkBlobStorage) Get(ctx context.Context, filePath string) (*File, error)
 	}, nil
 }
 
+func (c cdkBlobStorage) Read(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	reader, err := c.bucket.NewReader(ctx, strings.ToLower(filePath), nil)
+	if err != nil {
+		if gcerrors.Code(err) == gcerrors.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return reader, nil
+}
+
 func (c cdkBlobStorage) Delete(ctx context.Context, filePath string) error {
 	exists, err := c.bucket.Exists(ctx, strings.ToLower(filePath))
 	if err != nil {
@@ -1,6 +1,7 @@
 package filestorage
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
 	"encoding/hex"
@@ -10,6 +11,7 @@ import (
 	// nolint:gosec
 	"crypto/sha1"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -130,6 +132,16 @@ func (s dbFileStorage) Get(ctx context.Context, filePath string) (*File, error)
 	return result, err
 }
 
+func (s dbFileStorage) Read(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	file, err := s.Get(ctx, filePath)
+	if err != nil || file == nil {
+		return nil, err
+	}
+
+	// the contents are stored in a single column, so they are already loaded in memory
+	return io.NopCloser(bytes.NewReader(file.Contents)), nil
+}
+
 func (s dbFileStorage) Delete(ctx context.Context, filePath string) error {
 	pathHash, err := createPathHash(filePath)
 	if err != nil {
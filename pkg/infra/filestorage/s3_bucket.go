@@ -0,0 +1,360 @@
+package filestorage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/driver"
+	"gocloud.dev/gcerrors"
+)
+
+// s3Scheme is the scheme of the URLs of the S3 and S3-compatible buckets, such as
+// s3://bucket?region=us-east-1 or s3://bucket?endpoint=minio:9000&s3ForcePathStyle=true&disableSSL=true.
+//
+// The buckets are opened with the AWS SDK used by the rest of Grafana rather than gocloud.dev/blob/s3blob,
+// which depends on both versions of the SDK.
+const s3Scheme = "s3"
+
+const s3DefaultPageSize = 1000
+
+type s3URLOpener struct{}
+
+// OpenBucketURL opens the bucket named after the host of the URL. The region, endpoint, disableSSL,
+// s3ForcePathStyle and profile query parameters override the configuration found in the environment.
+func (o *s3URLOpener) OpenBucketURL(_ context.Context, u *url.URL) (*blob.Bucket, error) {
+	opts := session.Options{SharedConfigState: session.SharedConfigEnable}
+	for param, values := range u.Query() {
+		value := values[0]
+		switch param {
+		case "region":
+			opts.Config.Region = aws.String(value)
+		case "endpoint":
+			opts.Config.Endpoint = aws.String(value)
+		case "disableSSL", "s3ForcePathStyle":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for query parameter %q: %w", param, err)
+			}
+			if param == "disableSSL" {
+				opts.Config.DisableSSL = aws.Bool(b)
+			} else {
+				opts.Config.S3ForcePathStyle = aws.Bool(b)
+			}
+		case "profile":
+			opts.Profile = value
+		default:
+			return nil, fmt.Errorf("unknown query parameter %q", param)
+		}
+	}
+
+	sess, err := session.NewSessionWithOptions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	return blob.NewBucket(&s3Bucket{name: u.Host, client: s3.New(sess)}), nil
+}
+
+// s3Bucket implements the gocloud bucket driver for S3.
+type s3Bucket struct {
+	name   string
+	client *s3.S3
+}
+
+func (b *s3Bucket) ErrorCode(err error) gcerrors.ErrorCode {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return gcerrors.Unknown
+	}
+	switch awsErr.Code() {
+	case s3.ErrCodeNoSuchBucket, s3.ErrCodeNoSuchKey, "NotFound":
+		return gcerrors.NotFound
+	default:
+		return gcerrors.Unknown
+	}
+}
+
+func (b *s3Bucket) As(i interface{}) bool {
+	p, ok := i.(**s3.S3)
+	if !ok {
+		return false
+	}
+	*p = b.client
+	return true
+}
+
+func (b *s3Bucket) ErrorAs(err error, i interface{}) bool {
+	p, ok := i.(*awserr.Error)
+	if !ok {
+		return false
+	}
+	return errors.As(err, p)
+}
+
+func (b *s3Bucket) Attributes(ctx context.Context, key string) (*driver.Attributes, error) {
+	resp, err := b.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]string, len(resp.Metadata))
+	for k, v := range resp.Metadata {
+		metadata[unescapeMetadata(strings.ToLower(k))] = unescapeMetadata(aws.StringValue(v))
+	}
+	return &driver.Attributes{
+		CacheControl:       aws.StringValue(resp.CacheControl),
+		ContentDisposition: aws.StringValue(resp.ContentDisposition),
+		ContentEncoding:    aws.StringValue(resp.ContentEncoding),
+		ContentLanguage:    aws.StringValue(resp.ContentLanguage),
+		ContentType:        aws.StringValue(resp.ContentType),
+		Metadata:           metadata,
+		ModTime:            aws.TimeValue(resp.LastModified),
+		Size:               aws.Int64Value(resp.ContentLength),
+		ETag:               aws.StringValue(resp.ETag),
+		AsFunc: func(i interface{}) bool {
+			p, ok := i.(*s3.HeadObjectOutput)
+			if !ok {
+				return false
+			}
+			*p = *resp
+			return true
+		},
+	}, nil
+}
+
+func (b *s3Bucket) ListPaged(ctx context.Context, opts *driver.ListOptions) (*driver.ListPage, error) {
+	pageSize := opts.PageSize
+	if pageSize == 0 {
+		pageSize = s3DefaultPageSize
+	}
+	in := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(b.name),
+		MaxKeys: aws.Int64(int64(pageSize)),
+	}
+	if len(opts.PageToken) > 0 {
+		in.ContinuationToken = aws.String(string(opts.PageToken))
+	}
+	if opts.Prefix != "" {
+		in.Prefix = aws.String(opts.Prefix)
+	}
+	if opts.Delimiter != "" {
+		in.Delimiter = aws.String(opts.Delimiter)
+	}
+	resp, err := b.client.ListObjectsV2WithContext(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &driver.ListPage{}
+	if resp.NextContinuationToken != nil {
+		page.NextPageToken = []byte(*resp.NextContinuationToken)
+	}
+	for _, obj := range resp.Contents {
+		page.Objects = append(page.Objects, &driver.ListObject{
+			Key:     aws.StringValue(obj.Key),
+			ModTime: aws.TimeValue(obj.LastModified),
+			Size:    aws.Int64Value(obj.Size),
+		})
+	}
+	for _, prefix := range resp.CommonPrefixes {
+		page.Objects = append(page.Objects, &driver.ListObject{
+			Key:   aws.StringValue(prefix.Prefix),
+			IsDir: true,
+		})
+	}
+	// S3 lists the objects and the "directories" separately
+	sort.Slice(page.Objects, func(i, j int) bool {
+		return page.Objects[i].Key < page.Objects[j].Key
+	})
+	return page, nil
+}
+
+func (b *s3Bucket) NewRangeReader(ctx context.Context, key string, offset, length int64, _ *driver.ReaderOptions) (driver.Reader, error) {
+	in := &s3.GetObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(key),
+	}
+	switch {
+	case length == 0:
+		// S3 does not support empty ranges, the byte read is dropped below
+		in.Range = aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset))
+	case length > 0:
+		in.Range = aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	case offset > 0:
+		in.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := b.client.GetObjectWithContext(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	body := resp.Body
+	if length == 0 {
+		_ = body.Close()
+		body = http.NoBody
+	}
+	return &s3Reader{
+		body: body,
+		attrs: driver.ReaderAttributes{
+			ContentType: aws.StringValue(resp.ContentType),
+			ModTime:     aws.TimeValue(resp.LastModified),
+			Size:        s3ObjectSize(aws.Int64Value(resp.ContentLength), aws.StringValue(resp.ContentRange)),
+		},
+	}, nil
+}
+
+// s3ObjectSize returns the size of the whole object, which is only found in the content range of partial reads.
+func s3ObjectSize(contentLength int64, contentRange string) int64 {
+	// e.g. bytes 10-14/27
+	if i := strings.LastIndex(contentRange, "/"); i >= 0 {
+		if size, err := strconv.ParseInt(contentRange[i+1:], 10, 64); err == nil {
+			return size
+		}
+	}
+	return contentLength
+}
+
+func (b *s3Bucket) NewTypedWriter(ctx context.Context, key string, contentType string, opts *driver.WriterOptions) (driver.Writer, error) {
+	uploader := s3manager.NewUploaderWithClient(b.client, func(u *s3manager.Uploader) {
+		if opts.BufferSize != 0 {
+			u.PartSize = int64(opts.BufferSize)
+		}
+	})
+
+	metadata := make(map[string]*string, len(opts.Metadata))
+	for k, v := range opts.Metadata {
+		// the metadata is sent in HTTP headers
+		metadata[url.PathEscape(k)] = aws.String(url.PathEscape(v))
+	}
+	in := &s3manager.UploadInput{
+		Bucket:      aws.String(b.name),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		Metadata:    metadata,
+	}
+	if opts.CacheControl != "" {
+		in.CacheControl = aws.String(opts.CacheControl)
+	}
+	if opts.ContentDisposition != "" {
+		in.ContentDisposition = aws.String(opts.ContentDisposition)
+	}
+	if opts.ContentEncoding != "" {
+		in.ContentEncoding = aws.String(opts.ContentEncoding)
+	}
+	if opts.ContentLanguage != "" {
+		in.ContentLanguage = aws.String(opts.ContentLanguage)
+	}
+	if len(opts.ContentMD5) > 0 {
+		in.ContentMD5 = aws.String(base64.StdEncoding.EncodeToString(opts.ContentMD5))
+	}
+
+	pr, pw := io.Pipe()
+	w := &s3Writer{pw: pw, done: make(chan struct{})}
+	in.Body = pr
+	go func() {
+		defer close(w.done)
+		if _, err := uploader.UploadWithContext(ctx, in); err != nil {
+			w.err = err
+			_ = pr.CloseWithError(err)
+		}
+	}()
+	return w, nil
+}
+
+func (b *s3Bucket) Copy(ctx context.Context, dstKey, srcKey string, _ *driver.CopyOptions) error {
+	_, err := b.client.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(b.name),
+		CopySource: aws.String(b.name + "/" + srcKey),
+		Key:        aws.String(dstKey),
+	})
+	return err
+}
+
+func (b *s3Bucket) Delete(ctx context.Context, key string) error {
+	// S3 does not fail when deleting missing objects
+	if _, err := b.Attributes(ctx, key); err != nil {
+		return err
+	}
+	_, err := b.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (b *s3Bucket) SignedURL(_ context.Context, _ string, _ *driver.SignedURLOptions) (string, error) {
+	return "", errors.New("signed URLs are not supported by the S3 file storage")
+}
+
+func (b *s3Bucket) Close() error {
+	return nil
+}
+
+func unescapeMetadata(s string) string {
+	if unescaped, err := url.PathUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
+
+type s3Reader struct {
+	body  io.ReadCloser
+	attrs driver.ReaderAttributes
+}
+
+func (r *s3Reader) Read(p []byte) (int, error) {
+	return r.body.Read(p)
+}
+
+func (r *s3Reader) Close() error {
+	return r.body.Close()
+}
+
+func (r *s3Reader) As(interface{}) bool {
+	return false
+}
+
+func (r *s3Reader) Attributes() *driver.ReaderAttributes {
+	return &r.attrs
+}
+
+// s3Writer streams the written data to the upload running in the background.
+type s3Writer struct {
+	pw   *io.PipeWriter
+	done chan struct{}
+	// err is set before done is closed
+	err error
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	select {
+	case <-w.done:
+		return 0, w.err
+	default:
+	}
+	return w.pw.Write(p)
+}
+
+func (w *s3Writer) Close() error {
+	if err := w.pw.Close(); err != nil {
+		return err
+	}
+	<-w.done
+	return w.err
+}
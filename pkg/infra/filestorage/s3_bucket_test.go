@@ -0,0 +1,122 @@
+package filestorage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 serves the objects of a single bucket with the subset of the S3 API used by the file storage.
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	metadata map[string]http.Header
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	contents, exists := s.objects[key]
+	if !exists && r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusNotFound)
+		if r.Method != http.MethodHead {
+			_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		s.objects[key] = body
+		s.metadata[key] = http.Header{}
+		for name, values := range r.Header {
+			if strings.HasPrefix(strings.ToLower(name), "x-amz-meta-") {
+				s.metadata[key][name] = values
+			}
+		}
+	case http.MethodHead, http.MethodGet:
+		for name, values := range s.metadata[key] {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.Itoa(len(contents)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(contents)
+		}
+	case http.MethodDelete:
+		delete(s.objects, key)
+		delete(s.metadata, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3Bucket(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret-key")
+
+	server := httptest.NewServer(&fakeS3{objects: map[string][]byte{}, metadata: map[string]http.Header{}})
+	t.Cleanup(server.Close)
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+
+	ctx := context.Background()
+	bucket, err := OpenBucket(ctx, "s3://bucket?region=us-east-1&endpoint="+endpoint+"&s3ForcePathStyle=true&disableSSL=true")
+	require.NoError(t, err)
+	fs := NewCdkBlobStorage(log.New("test"), bucket, "", NewAllowAllPathFilter())
+	t.Cleanup(func() { _ = fs.close() })
+
+	file, err := fs.Get(ctx, "/folder/file.json")
+	require.NoError(t, err)
+	require.Nil(t, file)
+
+	err = fs.Upsert(ctx, &UpsertFileCommand{
+		Path:       "/folder/File.json",
+		Contents:   []byte(`{"title":"dashboard"}`),
+		Properties: map[string]string{"description": "ünïcode"},
+	})
+	require.NoError(t, err)
+
+	file, err = fs.Get(ctx, "/folder/File.json")
+	require.NoError(t, err)
+	require.NotNil(t, file)
+	require.Equal(t, []byte(`{"title":"dashboard"}`), file.Contents)
+	require.Equal(t, "/folder/File.json", file.FullPath)
+	require.Equal(t, map[string]string{"description": "ünïcode"}, file.Properties)
+
+	reader, err := fs.Read(ctx, "/folder/File.json")
+	require.NoError(t, err)
+	require.NotNil(t, reader)
+	contents, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	require.Equal(t, []byte(`{"title":"dashboard"}`), contents)
+
+	require.NoError(t, fs.Delete(ctx, "/folder/File.json"))
+	reader, err = fs.Read(ctx, "/folder/File.json")
+	require.NoError(t, err)
+	require.Nil(t, reader)
+}
+
+func TestOpenBucket(t *testing.T) {
+	_, err := OpenBucket(context.Background(), "azblob://container")
+	require.EqualError(t, err, `unsupported bucket URL scheme "azblob"`)
+
+	_, err = OpenBucket(context.Background(), "s3://bucket?endpoint=localhost&unknown=true")
+	require.EqualError(t, err, `unknown query parameter "unknown"`)
+
+	bucket, err := OpenBucket(context.Background(), "file://"+t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, bucket.Close())
+}
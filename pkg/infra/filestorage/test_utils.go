@@ -3,6 +3,7 @@ package filestorage
 import (
 	"context"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
@@ -279,6 +280,18 @@ func handleQuery(t *testing.T, ctx context.Context, query interface{}, queryName
 		} else {
 			require.Nil(t, file, "%s %s", queryName, inputPath)
 		}
+
+		reader, err := fs.Read(ctx, inputPath)
+		require.NoError(t, err, "%s: should be able to read file %s", queryName, inputPath)
+		if file == nil {
+			require.Nil(t, reader, "%s %s", queryName, inputPath)
+		} else {
+			require.NotNil(t, reader, "%s %s", queryName, inputPath)
+			contents, err := io.ReadAll(reader)
+			require.NoError(t, err, "%s: should be able to read file %s", queryName, inputPath)
+			require.NoError(t, reader.Close())
+			require.Equal(t, file.Contents, contents, "%s %s", queryName, inputPath)
+		}
 	case queryListFiles:
 		inputPath := q.input.path
 		resp, err := fs.List(ctx, inputPath, q.input.paging, q.input.options)
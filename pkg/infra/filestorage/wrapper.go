@@ -3,6 +3,7 @@ package filestorage
 import (
 	"context"
 	"fmt"
+	"io"
 	"mime"
 	"path/filepath"
 	"strings"
@@ -135,6 +136,23 @@ func (b wrapper) Get(ctx context.Context, path string) (*File, error) {
 	return file, err
 }
 
+func (b wrapper) Read(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := b.validatePath(path); err != nil {
+		return nil, err
+	}
+
+	rootedPath := b.addRoot(path)
+	if !b.filter.IsAllowed(rootedPath) {
+		return nil, nil
+	}
+
+	if b.rootFolder == rootedPath {
+		return nil, nil
+	}
+
+	return b.wrapped.Read(ctx, rootedPath)
+}
+
 func (b wrapper) Delete(ctx context.Context, path string) error {
 	if err := b.validatePath(path); err != nil {
 		return err
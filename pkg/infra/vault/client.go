@@ -0,0 +1,237 @@
+// Package vault is a client of the HashiCorp Vault HTTP API reading the secrets of the KV version 2 secrets engine.
+// The client authenticates with a token, or logs in with AppRole and logs in again when its token expires.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AuthMethodToken   = "token"
+	AuthMethodAppRole = "approle"
+)
+
+// ErrSecretNotFound is returned when a secret or a field of a secret doesn't exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Config configures the access to Vault.
+type Config struct {
+	URL string
+	// Namespace is the Vault Enterprise namespace, empty for the root namespace
+	Namespace  string
+	AuthMethod string
+	// Token is the token of the token auth method
+	Token string
+	// AppRoleMount is the path the AppRole auth method is mounted at, approle by default
+	AppRoleMount    string
+	AppRoleRoleID   string
+	AppRoleSecretID string
+	Timeout         time.Duration
+}
+
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu sync.Mutex
+	// token is the token of the client, and expires when the token got by logging in must be renewed
+	token   string
+	expires time.Time
+}
+
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("the url of Vault is not set")
+	}
+	if _, err := url.Parse(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid url of Vault: %w", err)
+	}
+
+	c := &Client{cfg: cfg, httpClient: &http.Client{Timeout: cfg.Timeout}}
+	switch cfg.AuthMethod {
+	case AuthMethodToken:
+		if cfg.Token == "" {
+			return nil, errors.New("the token auth method requires a token")
+		}
+		c.token = cfg.Token
+	case AuthMethodAppRole:
+		if cfg.AppRoleRoleID == "" || cfg.AppRoleSecretID == "" {
+			return nil, errors.New("the approle auth method requires a role id and a secret id")
+		}
+		if c.cfg.AppRoleMount == "" {
+			c.cfg.AppRoleMount = "approle"
+		}
+	default:
+		return nil, fmt.Errorf("unsupported auth method %q, supported methods are token and approle", cfg.AuthMethod)
+	}
+	return c, nil
+}
+
+// ReadKV returns the data of the latest version of a secret of a KV version 2 secrets engine mounted at mount.
+func (c *Client) ReadKV(ctx context.Context, mount, path string) (map[string]interface{}, error) {
+	endpoint := fmt.Sprintf("/v1/%s/data/%s", strings.Trim(mount, "/"), strings.Trim(path, "/"))
+
+	var resp struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	err := c.request(ctx, http.MethodGet, endpoint, nil, &resp)
+	// a token got by logging in may be revoked before it expires, in which case the client logs in again
+	if responseStatus(err) == http.StatusForbidden && c.cfg.AuthMethod == AuthMethodAppRole {
+		c.mu.Lock()
+		c.token = ""
+		c.mu.Unlock()
+		err = c.request(ctx, http.MethodGet, endpoint, nil, &resp)
+	}
+	if responseStatus(err) == http.StatusNotFound {
+		return nil, ErrSecretNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// deleted and destroyed versions have no data
+	if resp.Data.Data == nil {
+		return nil, ErrSecretNotFound
+	}
+	return resp.Data.Data, nil
+}
+
+// ReadKVField returns a field of the latest version of a secret of a KV version 2 secrets engine as a string.
+func (c *Client) ReadKVField(ctx context.Context, mount, path, field string) (string, error) {
+	data, err := c.ReadKV(ctx, mount, path)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("%w: field %q of secret %q", ErrSecretNotFound, field, path)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (c *Client) request(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+	token, err := c.clientToken(ctx)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, method, endpoint, token, body, result)
+}
+
+// clientToken returns the token of the client, logging in with AppRole when the client has no valid token.
+func (c *Client) clientToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cfg.AuthMethod == AuthMethodToken {
+		return c.token, nil
+	}
+	if c.token != "" && (c.expires.IsZero() || time.Now().Before(c.expires)) {
+		return c.token, nil
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	login := map[string]string{"role_id": c.cfg.AppRoleRoleID, "secret_id": c.cfg.AppRoleSecretID}
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/v1/auth/%s/login", strings.Trim(c.cfg.AppRoleMount, "/")), "", login, &resp); err != nil {
+		return "", fmt.Errorf("failed to log in to Vault with AppRole: %w", err)
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", errors.New("failed to log in to Vault with AppRole: no token returned")
+	}
+
+	c.token = resp.Auth.ClientToken
+	c.expires = time.Time{}
+	if resp.Auth.LeaseDuration > 0 {
+		// log in again a bit before the token expires
+		lease := time.Duration(resp.Auth.LeaseDuration) * time.Second
+		c.expires = time.Now().Add(lease - lease/10)
+	}
+	return c.token, nil
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint, token string, body interface{}, result interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.cfg.URL, "/")+endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if c.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.cfg.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&vaultErr)
+		return &responseError{status: resp.StatusCode, errors: vaultErr.Errors}
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// responseError is the error of a request Vault didn't serve.
+type responseError struct {
+	status int
+	errors []string
+}
+
+func (e *responseError) Error() string {
+	if len(e.errors) == 0 {
+		return fmt.Sprintf("Vault responded with status %d", e.status)
+	}
+	return fmt.Sprintf("Vault responded with status %d: %s", e.status, strings.Join(e.errors, ", "))
+}
+
+// responseStatus returns the status of the response when the request itself wasn't served by Vault, and 0 otherwise.
+// The errors of the AppRole login are wrapped so that their status is not taken for the one of the request.
+func responseStatus(err error) int {
+	// nolint:errorlint
+	if respErr, ok := err.(*responseError); ok {
+		return respErr.status
+	}
+	return 0
+}
@@ -0,0 +1,138 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault serves the secrets of a KV version 2 secrets engine mounted at secret to the clients with a valid token.
+type fakeVault struct {
+	secrets map[string]map[string]interface{}
+	token   string
+	logins  int32
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/v1/auth/approle/login" {
+		var login map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&login); err != nil || login["role_id"] != "role" || login["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+			return
+		}
+		n := atomic.AddInt32(&v.logins, 1)
+		v.token = "approle-token-" + strconv.Itoa(int(n))
+		_, _ = w.Write([]byte(`{"auth":{"client_token":"` + v.token + `","lease_duration":3600}}`))
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/v1/auth/") {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":["no handler for route"]}`))
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != v.token {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	data, ok := v.secrets[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":[]}`))
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": data}})
+}
+
+func TestClient(t *testing.T) {
+	vault := &fakeVault{
+		secrets: map[string]map[string]interface{}{
+			"/v1/secret/data/grafana/loki": {"password": "s3cr3t", "port": 3100},
+		},
+		token: "root",
+	}
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+
+	t.Run("The fields of a secret should be read with a token", func(t *testing.T) {
+		c, err := NewClient(Config{URL: server.URL, AuthMethod: AuthMethodToken, Token: "root", Timeout: time.Second})
+		require.NoError(t, err)
+
+		password, err := c.ReadKVField(ctx, "secret", "grafana/loki", "password")
+		require.NoError(t, err)
+		require.Equal(t, "s3cr3t", password)
+		port, err := c.ReadKVField(ctx, "secret", "grafana/loki", "port")
+		require.NoError(t, err)
+		require.Equal(t, "3100", port)
+
+		_, err = c.ReadKVField(ctx, "secret", "grafana/loki", "user")
+		require.ErrorIs(t, err, ErrSecretNotFound)
+		_, err = c.ReadKVField(ctx, "secret", "grafana/missing", "password")
+		require.ErrorIs(t, err, ErrSecretNotFound)
+	})
+
+	t.Run("An invalid token should be reported", func(t *testing.T) {
+		c, err := NewClient(Config{URL: server.URL, AuthMethod: AuthMethodToken, Token: "invalid", Timeout: time.Second})
+		require.NoError(t, err)
+
+		_, err = c.ReadKVField(ctx, "secret", "grafana/loki", "password")
+		require.EqualError(t, err, "Vault responded with status 403: permission denied")
+	})
+
+	t.Run("A client authenticated with AppRole should log in again when its token is revoked", func(t *testing.T) {
+		c, err := NewClient(Config{URL: server.URL, AuthMethod: AuthMethodAppRole, AppRoleRoleID: "role", AppRoleSecretID: "secret", Timeout: time.Second})
+		require.NoError(t, err)
+
+		password, err := c.ReadKVField(ctx, "secret", "grafana/loki", "password")
+		require.NoError(t, err)
+		require.Equal(t, "s3cr3t", password)
+		_, err = c.ReadKVField(ctx, "secret", "grafana/loki", "password")
+		require.NoError(t, err)
+		require.Equal(t, int32(1), vault.logins)
+
+		vault.token = "revoked"
+		password, err = c.ReadKVField(ctx, "secret", "grafana/loki", "password")
+		require.NoError(t, err)
+		require.Equal(t, "s3cr3t", password)
+		require.Equal(t, int32(2), vault.logins)
+	})
+
+	t.Run("Invalid AppRole credentials should be reported", func(t *testing.T) {
+		c, err := NewClient(Config{URL: server.URL, AuthMethod: AuthMethodAppRole, AppRoleRoleID: "role", AppRoleSecretID: "wrong", Timeout: time.Second})
+		require.NoError(t, err)
+
+		_, err = c.ReadKVField(ctx, "secret", "grafana/loki", "password")
+		require.EqualError(t, err, "failed to log in to Vault with AppRole: Vault responded with status 400: invalid role or secret ID")
+	})
+
+	t.Run("A missing AppRole mount should not be reported as a missing secret", func(t *testing.T) {
+		c, err := NewClient(Config{URL: server.URL, AuthMethod: AuthMethodAppRole, AppRoleMount: "missing", AppRoleRoleID: "role", AppRoleSecretID: "secret", Timeout: time.Second})
+		require.NoError(t, err)
+
+		_, err = c.ReadKVField(ctx, "secret", "grafana/loki", "password")
+		require.NotErrorIs(t, err, ErrSecretNotFound)
+		require.EqualError(t, err, "failed to log in to Vault with AppRole: Vault responded with status 404: no handler for route")
+	})
+
+	t.Run("Incomplete configurations should be rejected", func(t *testing.T) {
+		_, err := NewClient(Config{AuthMethod: AuthMethodToken, Token: "root"})
+		require.Error(t, err)
+		_, err = NewClient(Config{URL: server.URL, AuthMethod: AuthMethodToken})
+		require.Error(t, err)
+		_, err = NewClient(Config{URL: server.URL, AuthMethod: AuthMethodAppRole, AppRoleRoleID: "role"})
+		require.Error(t, err)
+		_, err = NewClient(Config{URL: server.URL, AuthMethod: "kubernetes"})
+		require.Error(t, err)
+	})
+}
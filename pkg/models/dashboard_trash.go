@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+)
+
+var (
+	ErrDashboardTrashItemNotFound = DashboardErr{
+		Reason:     "Trash item not found",
+		StatusCode: 404,
+		Status:     "not-found",
+	}
+	ErrDashboardTrashDisabled = DashboardErr{
+		Reason:     "Trash is disabled",
+		StatusCode: 400,
+	}
+)
+
+// DashboardTrash is a dashboard or folder that has been deleted and can be restored until it expires.
+// Data holds everything needed to restore it: the dashboard with its versions and permissions and,
+// for folders, the dashboards, subfolders and alert rules it contained.
+type DashboardTrash struct {
+	Id        int64
+	OrgId     int64
+	Uid       string
+	Title     string
+	IsFolder  bool
+	FolderUid string
+	Data      string
+	Deleted   time.Time
+	DeletedBy int64
+}
+
+type DashboardTrashDTO struct {
+	Id        int64     `json:"id"`
+	Uid       string    `json:"uid"`
+	Title     string    `json:"title"`
+	IsFolder  bool      `json:"isFolder"`
+	FolderUid string    `json:"folderUid"`
+	Deleted   time.Time `json:"deleted"`
+	DeletedBy int64     `json:"deletedBy"`
+	Expires   time.Time `json:"expires"`
+}
+
+//
+// QUERIES
+//
+
+type GetDashboardTrashQuery struct {
+	OrgId int64
+	// DeletedBy limits the result to the items deleted by a user, when set
+	DeletedBy int64
+
+	Result []*DashboardTrash
+}
+
+type GetDashboardTrashItemQuery struct {
+	Id    int64
+	OrgId int64
+
+	Result *DashboardTrash
+}
+
+//
+// COMMANDS
+//
+
+type RestoreDashboardFromTrashCommand struct {
+	Id    int64
+	OrgId int64
+
+	// Result holds the restored dashboard or folder followed by anything it contained
+	Result []*Dashboard
+}
+
+type DeleteDashboardFromTrashCommand struct {
+	Id    int64
+	OrgId int64
+}
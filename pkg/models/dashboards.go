@@ -63,6 +63,14 @@ var (
 		Reason:     "A Dashboard Folder cannot be added to another folder",
 		StatusCode: 400,
 	}
+	ErrDashboardFolderMaxDepthExceeded = DashboardErr{
+		Reason:     fmt.Sprintf("Folders cannot be nested more than %d levels deep", MaxNestedFolderDepth),
+		StatusCode: 400,
+	}
+	ErrDashboardFolderCircularReference = DashboardErr{
+		Reason:     "A folder cannot be moved into itself or one of its subfolders",
+		StatusCode: 400,
+	}
 	ErrDashboardsWithSameSlugExists = DashboardErr{
 		Reason:     "Multiple dashboards with the same slug exists",
 		StatusCode: 412,
@@ -407,12 +415,29 @@ type DeleteDashboardCommand struct {
 	Id                     int64
 	OrgId                  int64
 	ForceDeleteFolderRules bool
+	// MoveToTrash keeps a copy of the dashboard in the trash so it can be restored
+	MoveToTrash bool
+	DeletedBy   int64
 }
 
 type DeleteOrphanedProvisionedDashboardsCommand struct {
 	ReaderNames []string
 }
 
+// MigrateDashboardsSchemaCommand upgrades the stored dashboards to the latest schema version.
+type MigrateDashboardsSchemaCommand struct {
+	// OrgId limits the migration to an organization, all organizations are migrated when not set
+	OrgId int64 `json:"orgId"`
+
+	Result *DashboardsSchemaMigration `json:"-"`
+}
+
+type DashboardsSchemaMigration struct {
+	Scanned  int64 `json:"scanned"`
+	Migrated int64 `json:"migrated"`
+	Failed   int64 `json:"failed"`
+}
+
 //
 // QUERIES
 //
@@ -19,6 +19,26 @@ var (
 		Reason:     "No Uid for public dashboard specified",
 		StatusCode: 400,
 	}
+	ErrPublicDashboardInvalidMaxTimeRange = DashboardErr{
+		Reason:     "Invalid maximum time range for public dashboard",
+		StatusCode: 400,
+	}
+	ErrPublicDashboardInvalidTimeRange = DashboardErr{
+		Reason:     "Invalid time range",
+		StatusCode: 400,
+	}
+	ErrPublicDashboardTimeRangeTooLarge = DashboardErr{
+		Reason:     "Time range is larger than allowed for public dashboard",
+		StatusCode: 400,
+	}
+	ErrPublicDashboardInvalidVariable = DashboardErr{
+		Reason:     "Invalid template variable value",
+		StatusCode: 400,
+	}
+	ErrPublicDashboardUnsupportedVariable = DashboardErr{
+		Reason:     "Public dashboards only support constant and custom template variables",
+		StatusCode: 400,
+	}
 )
 
 type PublicDashboardConfig struct {
@@ -31,12 +51,36 @@ type PublicDashboard struct {
 	DashboardUid string `json:"dashboardUid" xorm:"dashboard_uid"`
 	OrgId        int64  `json:"orgId" xorm:"org_id"`
 	TimeSettings string `json:"timeSettings" xorm:"time_settings"`
+
+	// TimeSelectionEnabled lets viewers choose the time range, up to MaxTimeRange when it is set
+	TimeSelectionEnabled bool   `json:"timeSelectionEnabled" xorm:"time_selection_enabled"`
+	MaxTimeRange         string `json:"maxTimeRange" xorm:"max_time_range"`
+	AnnotationsEnabled   bool   `json:"annotationsEnabled" xorm:"annotations_enabled"`
 }
 
 func (pd PublicDashboard) TableName() string {
 	return "dashboard_public_config"
 }
 
+// PublicDashboardQueryDTO holds what a viewer of a public dashboard can choose. The queries themselves
+// always come from the saved dashboard.
+type PublicDashboardQueryDTO struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Variables are the values chosen for custom template variables, by variable name
+	Variables map[string]string `json:"variables"`
+}
+
+// PublicDashboardAnnotation is an annotation shown on a public dashboard.
+type PublicDashboardAnnotation struct {
+	PanelId int64    `json:"panelId"`
+	Time    int64    `json:"time"`
+	TimeEnd int64    `json:"timeEnd"`
+	Text    string   `json:"text"`
+	Tags    []string `json:"tags"`
+	Color   string   `json:"color"`
+}
+
 //
 // COMMANDS
 //
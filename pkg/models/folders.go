@@ -19,6 +19,13 @@ var (
 	ErrFolderContainsAlertRules      = errors.New("folder contains alert rules")
 )
 
+// MaxNestedFolderDepth is the maximum number of folders in the path from a root folder
+// to a nested folder, both included.
+const MaxNestedFolderDepth = 4
+
+// FolderPathSeparator separates the folder titles in the path of a nested folder.
+const FolderPathSeparator = "/"
+
 type Folder struct {
 	Id      int64
 	Uid     string
@@ -26,6 +33,10 @@ type Folder struct {
 	Url     string
 	Version int
 
+	// ParentId and ParentUid identify the parent folder of a nested folder. They are empty for root folders.
+	ParentId  int64
+	ParentUid string
+
 	Created time.Time
 	Updated time.Time
 
@@ -49,6 +60,7 @@ func DashboardToFolder(dash *Dashboard) *Folder {
 		Id:        dash.Id,
 		Uid:       dash.Uid,
 		Title:     dash.Title,
+		ParentId:  dash.FolderId,
 		HasAcl:    dash.HasAcl,
 		Url:       dash.GetUrl(),
 		Version:   dash.Version,
@@ -85,8 +97,9 @@ func (cmd *UpdateFolderCommand) UpdateDashboardModel(dashFolder *Dashboard, orgI
 //
 
 type CreateFolderCommand struct {
-	Uid   string `json:"uid"`
-	Title string `json:"title"`
+	Uid       string `json:"uid"`
+	Title     string `json:"title"`
+	ParentUid string `json:"parentUid"`
 
 	Result *Folder `json:"-"`
 }
@@ -100,6 +113,13 @@ type UpdateFolderCommand struct {
 	Result *Folder `json:"-"`
 }
 
+// MoveFolderCommand moves a folder into another folder, or to the root if ParentUid is empty.
+type MoveFolderCommand struct {
+	ParentUid string `json:"parentUid"`
+
+	Result *Folder `json:"-"`
+}
+
 //
 // QUERIES
 //
@@ -33,6 +33,8 @@ type FindPersistedDashboardsQuery struct {
 	Page          int64
 	Permission    PermissionType
 	Sort          SortOption
+	// IncludeSubfolders also matches the dashboards and folders in the subfolders of FolderIds.
+	IncludeSubfolders bool
 
 	Filters []interface{}
 
@@ -10,7 +10,8 @@ import (
 
 // Typed errors
 var (
-	ErrUserTokenNotFound = errors.New("user token not found")
+	ErrUserTokenNotFound           = errors.New("user token not found")
+	ErrRevokeSessionsFilterMissing = errors.New("at least one filter is required to revoke sessions")
 )
 
 // CreateTokenErr represents a token creation error; used in Enterprise
@@ -63,6 +64,49 @@ type RevokeAuthTokenCmd struct {
 	AuthTokenId int64 `json:"authTokenId"`
 }
 
+// UserSession is an active user token together with the user it belongs to
+type UserSession struct {
+	UserToken
+	Login string
+	Email string
+	Name  string
+}
+
+// SearchUserSessionsQuery lists active sessions across all users
+type SearchUserSessionsQuery struct {
+	UserId int64
+	OrgId  int64
+	// Query matches login, email, name, client IP or user agent
+	Query string
+	// SeenBefore and SeenAfter are unix timestamps, zero means no bound
+	SeenBefore int64
+	SeenAfter  int64
+	Page       int
+	Limit      int
+
+	Result SearchUserSessionsQueryResult
+}
+
+type SearchUserSessionsQueryResult struct {
+	TotalCount int64
+	Sessions   []*UserSession
+	Page       int
+	PerPage    int
+}
+
+// RevokeUserSessionsCmd revokes all sessions matching the given filters. At least
+// one filter must be set.
+type RevokeUserSessionsCmd struct {
+	UserId int64
+	OrgId  int64
+	// CreatedBefore is a unix timestamp, sessions created before it are revoked
+	CreatedBefore int64
+	// ExcludeTokenId keeps the session with this id, usually the caller's own
+	ExcludeTokenId int64
+
+	Result int64
+}
+
 // UserTokenService are used for generating and validating user tokens
 type UserTokenService interface {
 	CreateToken(ctx context.Context, user *User, clientIP net.IP, userAgent string) (*UserToken, error)
@@ -74,6 +118,8 @@ type UserTokenService interface {
 	GetUserToken(ctx context.Context, userId, userTokenId int64) (*UserToken, error)
 	GetUserTokens(ctx context.Context, userId int64) ([]*UserToken, error)
 	GetUserRevokedTokens(ctx context.Context, userId int64) ([]*UserToken, error)
+	SearchUserSessions(ctx context.Context, query *SearchUserSessionsQuery) error
+	RevokeUserSessions(ctx context.Context, cmd *RevokeUserSessionsCmd) error
 }
 
 type UserTokenBackgroundService interface {
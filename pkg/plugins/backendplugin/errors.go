@@ -9,6 +9,8 @@ var (
 	ErrHealthCheckFailed = errors.New("health check failed")
 	// ErrPluginUnavailable error returned when plugin is unavailable.
 	ErrPluginUnavailable = errors.New("plugin unavailable")
+	// ErrPluginUnhealthy error returned when requests to a plugin are rejected because the plugin is unhealthy.
+	ErrPluginUnhealthy = errors.New("plugin unhealthy")
 	// ErrMethodNotImplemented error returned when plugin method not implemented.
 	ErrMethodNotImplemented = errors.New("method not implemented")
 )
@@ -21,6 +21,30 @@ var (
 		Help:      "Plugin request duration",
 		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 25, 50, 100},
 	}, []string{"plugin_id", "endpoint"})
+
+	pluginHealthState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "plugin_health_state",
+		Help:      "The health state of backend plugins, 1 for the current state of each plugin",
+	}, []string{"plugin_id", "state"})
+
+	pluginCircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "plugin_circuit_breaker_state",
+		Help:      "The state of the circuit breaker of backend plugins, 1 for the current state of each plugin",
+	}, []string{"plugin_id", "state"})
+
+	pluginRestartCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "plugin_restarts_total",
+		Help:      "The total amount of restarts of backend plugin processes",
+	}, []string{"plugin_id"})
+
+	pluginRejectedRequestCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "plugin_rejected_requests_total",
+		Help:      "The total amount of plugin requests rejected because the plugin is unhealthy",
+	}, []string{"plugin_id", "endpoint"})
 )
 
 // instrumentPluginRequest instruments success rate and latency of `fn`
@@ -60,3 +84,34 @@ func InstrumentCallResourceRequest(pluginID string, fn func() error) error {
 func InstrumentQueryDataRequest(pluginID string, fn func() error) error {
 	return instrumentPluginRequest(pluginID, "queryData", fn)
 }
+
+// SetPluginHealthState records the health state of a plugin in place of its previous state.
+// An empty current state removes the health state of the plugin.
+func SetPluginHealthState(pluginID, previous, current string) {
+	setStateGauge(pluginHealthState, pluginID, previous, current)
+}
+
+// SetPluginCircuitBreakerState records the circuit breaker state of a plugin in place of its previous state.
+// An empty current state removes the circuit breaker state of the plugin.
+func SetPluginCircuitBreakerState(pluginID, previous, current string) {
+	setStateGauge(pluginCircuitBreakerState, pluginID, previous, current)
+}
+
+// IncPluginRestarts counts a restart of a plugin process.
+func IncPluginRestarts(pluginID string) {
+	pluginRestartCounter.WithLabelValues(pluginID).Inc()
+}
+
+// IncPluginRejectedRequests counts a request rejected because the plugin is unhealthy.
+func IncPluginRejectedRequests(pluginID, endpoint string) {
+	pluginRejectedRequestCounter.WithLabelValues(pluginID, endpoint).Inc()
+}
+
+func setStateGauge(gauge *prometheus.GaugeVec, pluginID, previous, current string) {
+	if previous != "" && previous != current {
+		gauge.DeleteLabelValues(pluginID, previous)
+	}
+	if current != "" {
+		gauge.WithLabelValues(pluginID, current).Set(1)
+	}
+}
@@ -1,6 +1,8 @@
 package plugins
 
 import (
+	"time"
+
 	"github.com/grafana/grafana-azure-sdk-go/azsettings"
 
 	"github.com/grafana/grafana/pkg/setting"
@@ -14,6 +16,25 @@ type Cfg struct {
 	PluginSettings       setting.PluginSettings
 	PluginsAllowUnsigned []string
 
+	// PrivateRepository is the URL of the index or the local directory of a private plugin repository
+	// plugins are installed from instead of grafana.com
+	PrivateRepository string
+
+	// HotReloadEnabled enables watching the external plugin directories for added, changed or removed plugins
+	HotReloadEnabled  bool
+	HotReloadInterval time.Duration
+
+	// HealthCheckInterval is how often the health of the backend plugins is checked, health checks are disabled when 0
+	HealthCheckInterval time.Duration
+	// HealthCheckFailureThreshold is the number of consecutive failed requests or health checks opening the circuit
+	// breaker of a backend plugin, the circuit breaker is disabled when 0
+	HealthCheckFailureThreshold int
+	// CircuitBreakerOpenDuration is how long the requests to an unhealthy backend plugin are rejected before a
+	// trial request is let through
+	CircuitBreakerOpenDuration time.Duration
+	// RestartMaxBackoff is the maximum delay before restarting a backend plugin process which keeps exiting
+	RestartMaxBackoff time.Duration
+
 	EnterpriseLicensePath string
 
 	// AWS Plugin Auth
@@ -38,6 +59,13 @@ func FromGrafanaCfg(grafanaCfg *setting.Cfg) *Cfg {
 
 	cfg.PluginSettings = grafanaCfg.PluginSettings
 	cfg.PluginsAllowUnsigned = grafanaCfg.PluginsAllowUnsigned
+	cfg.PrivateRepository = grafanaCfg.PluginsPrivateRepository
+	cfg.HotReloadEnabled = grafanaCfg.PluginsHotReloadEnabled
+	cfg.HotReloadInterval = grafanaCfg.PluginsHotReloadInterval
+	cfg.HealthCheckInterval = grafanaCfg.PluginsHealthCheckInterval
+	cfg.HealthCheckFailureThreshold = grafanaCfg.PluginsHealthCheckFailureThreshold
+	cfg.CircuitBreakerOpenDuration = grafanaCfg.PluginsCircuitBreakerOpenDuration
+	cfg.RestartMaxBackoff = grafanaCfg.PluginsRestartMaxBackoff
 	cfg.EnterpriseLicensePath = grafanaCfg.EnterpriseLicensePath
 
 	// AWS
@@ -3,6 +3,7 @@ package plugins
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
@@ -20,8 +21,69 @@ type Store interface {
 type Manager interface {
 	// Add adds a plugin to the store.
 	Add(ctx context.Context, pluginID, version string) error
+	// AddFromArchive adds a plugin to the store from a zip archive, verifying the SHA256 checksum of the
+	// archive when provided.
+	AddFromArchive(ctx context.Context, pluginID string, archive io.Reader, checksum string) error
 	// Remove removes a plugin from the store.
 	Remove(ctx context.Context, pluginID string) error
+	// Reload rescans the external plugin directories, loading the added plugins, reloading the changed
+	// plugins and unloading the removed plugins without restarting the server.
+	Reload(ctx context.Context) (ReloadResult, error)
+	// HealthStatuses returns the health of the supervised backend plugins.
+	HealthStatuses(ctx context.Context) []HealthStatus
+}
+
+// ReloadResult lists the IDs of the plugins affected by a reload.
+type ReloadResult struct {
+	Added   []string `json:"added"`
+	Updated []string `json:"updated"`
+	Removed []string `json:"removed"`
+	// Failed lists the changed plugins which could not be loaded again, their previous version keeps running.
+	Failed []ReloadError `json:"failed"`
+}
+
+// ReloadError is the error of a changed plugin which could not be loaded again.
+type ReloadError struct {
+	PluginID string `json:"pluginId"`
+	Error    string `json:"error"`
+}
+
+// HealthState is the health of a backend plugin as observed by the plugin manager.
+type HealthState string
+
+const (
+	// HealthStateHealthy is the state of a plugin which responds to its requests.
+	HealthStateHealthy HealthState = "healthy"
+	// HealthStateUnhealthy is the state of a plugin whose requests or health checks time out or fail to reach it.
+	HealthStateUnhealthy HealthState = "unhealthy"
+	// HealthStateRestarting is the state of a plugin whose process exited and is about to be restarted.
+	HealthStateRestarting HealthState = "restarting"
+	// HealthStateCrashLoop is the state of a plugin whose process keeps exiting shortly after being restarted.
+	HealthStateCrashLoop HealthState = "crashloop"
+)
+
+// CircuitState is the state of the circuit breaker of a backend plugin.
+type CircuitState string
+
+const (
+	// CircuitClosed lets the requests through to the plugin.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects the requests to the plugin.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single trial request through to the plugin, which closes the circuit when it succeeds.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// HealthStatus is the health of a supervised backend plugin.
+type HealthStatus struct {
+	PluginID            string       `json:"pluginId"`
+	State               HealthState  `json:"state"`
+	Circuit             CircuitState `json:"circuit"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	Restarts            int          `json:"restarts"`
+	LastError           string       `json:"lastError,omitempty"`
+	LastHealthCheck     *time.Time   `json:"lastHealthCheck,omitempty"`
+	NextRestart         *time.Time   `json:"nextRestart,omitempty"`
 }
 
 type UpdateInfo struct {
@@ -7,21 +7,29 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/instrumentation"
 )
 
 func (m *PluginManager) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	plugin, exists := m.plugin(ctx, req.PluginContext.PluginID)
+	plugin, release, exists := m.acquire(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return nil, backendplugin.ErrPluginNotRegistered
 	}
+	defer release()
+
+	done, err := m.admit(req.PluginContext.PluginID, "queryData")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp *backend.QueryDataResponse
-	err := instrumentation.InstrumentQueryDataRequest(req.PluginContext.PluginID, func() (innerErr error) {
+	err = instrumentation.InstrumentQueryDataRequest(req.PluginContext.PluginID, func() (innerErr error) {
 		resp, innerErr = plugin.QueryData(ctx, req)
 		return
 	})
+	done(err)
 
 	if err != nil {
 		if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
@@ -48,16 +56,24 @@ func (m *PluginManager) QueryData(ctx context.Context, req *backend.QueryDataReq
 }
 
 func (m *PluginManager) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
-	p, exists := m.plugin(ctx, req.PluginContext.PluginID)
+	p, release, exists := m.acquire(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return backendplugin.ErrPluginNotRegistered
 	}
-	err := instrumentation.InstrumentCallResourceRequest(p.PluginID(), func() error {
+	defer release()
+
+	done, err := m.admit(req.PluginContext.PluginID, "callResource")
+	if err != nil {
+		return err
+	}
+
+	err = instrumentation.InstrumentCallResourceRequest(p.PluginID(), func() error {
 		if err := p.CallResource(ctx, req, sender); err != nil {
 			return err
 		}
 		return nil
 	})
+	done(err)
 
 	if err != nil {
 		return err
@@ -67,16 +83,23 @@ func (m *PluginManager) CallResource(ctx context.Context, req *backend.CallResou
 }
 
 func (m *PluginManager) CollectMetrics(ctx context.Context, req *backend.CollectMetricsRequest) (*backend.CollectMetricsResult, error) {
-	p, exists := m.plugin(ctx, req.PluginContext.PluginID)
+	p, release, exists := m.acquire(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return nil, backendplugin.ErrPluginNotRegistered
 	}
+	defer release()
+
+	done, err := m.admit(req.PluginContext.PluginID, "collectMetrics")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp *backend.CollectMetricsResult
-	err := instrumentation.InstrumentCollectMetrics(p.PluginID(), func() (innerErr error) {
+	err = instrumentation.InstrumentCollectMetrics(p.PluginID(), func() (innerErr error) {
 		resp, innerErr = p.CollectMetrics(ctx, req)
 		return
 	})
+	done(err)
 	if err != nil {
 		return nil, err
 	}
@@ -85,16 +108,23 @@ func (m *PluginManager) CollectMetrics(ctx context.Context, req *backend.Collect
 }
 
 func (m *PluginManager) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
-	p, exists := m.plugin(ctx, req.PluginContext.PluginID)
+	p, release, exists := m.acquire(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return nil, backendplugin.ErrPluginNotRegistered
 	}
+	defer release()
+
+	done, err := m.admit(req.PluginContext.PluginID, "checkHealth")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp *backend.CheckHealthResult
-	err := instrumentation.InstrumentCheckHealthRequest(p.PluginID(), func() (innerErr error) {
+	err = instrumentation.InstrumentCheckHealthRequest(p.PluginID(), func() (innerErr error) {
 		resp, innerErr = p.CheckHealth(ctx, req)
 		return
 	})
+	done(err)
 
 	if err != nil {
 		if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
@@ -112,24 +142,42 @@ func (m *PluginManager) CheckHealth(ctx context.Context, req *backend.CheckHealt
 }
 
 func (m *PluginManager) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
-	plugin, exists := m.plugin(ctx, req.PluginContext.PluginID)
+	plugin, release, exists := m.acquire(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return nil, backendplugin.ErrPluginNotRegistered
 	}
+	defer release()
+
+	done, err := m.admit(req.PluginContext.PluginID, "subscribeStream")
+	if err != nil {
+		return nil, err
+	}
 
-	return plugin.SubscribeStream(ctx, req)
+	resp, err := plugin.SubscribeStream(ctx, req)
+	done(err)
+	return resp, err
 }
 
 func (m *PluginManager) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
-	plugin, exists := m.plugin(ctx, req.PluginContext.PluginID)
+	plugin, release, exists := m.acquire(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return nil, backendplugin.ErrPluginNotRegistered
 	}
+	defer release()
+
+	done, err := m.admit(req.PluginContext.PluginID, "publishStream")
+	if err != nil {
+		return nil, err
+	}
 
-	return plugin.PublishStream(ctx, req)
+	resp, err := plugin.PublishStream(ctx, req)
+	done(err)
+	return resp, err
 }
 
 func (m *PluginManager) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	// streams are long-lived, unloading the plugin does not wait for them to complete and they are not
+	// rejected by the circuit breaker since the subscription to the stream already is
 	plugin, exists := m.plugin(ctx, req.PluginContext.PluginID)
 	if !exists {
 		return backendplugin.ErrPluginNotRegistered
@@ -137,3 +185,17 @@ func (m *PluginManager) RunStream(ctx context.Context, req *backend.RunStreamReq
 
 	return plugin.RunStream(ctx, req, sender)
 }
+
+// acquire finds a plugin that is not decommissioned and tracks the request made to it until release is called,
+// so that unloading the plugin waits for the request to complete before stopping the plugin process.
+func (m *PluginManager) acquire(ctx context.Context, pluginID string) (p *plugins.Plugin, release func(), exists bool) {
+	m.pluginsMu.RLock()
+	defer m.pluginsMu.RUnlock()
+
+	p, exists = m.plugin(ctx, pluginID)
+	if !exists {
+		return nil, nil, false
+	}
+
+	return p, m.requests.start(p), true
+}
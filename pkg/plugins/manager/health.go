@@ -0,0 +1,413 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/instrumentation"
+)
+
+const (
+	// crashLoopThreshold is the number of consecutive crashes after which a plugin is crash looping
+	crashLoopThreshold = 5
+	// maxHealthCheckTimeout is the maximum time a health check waits for the plugin to respond
+	maxHealthCheckTimeout = 10 * time.Second
+	// defaultRestartMaxBackoff is used when no maximum restart backoff is configured
+	defaultRestartMaxBackoff = 5 * time.Minute
+)
+
+var (
+	// superviseInterval is how often the process of a supervised plugin is checked
+	superviseInterval = time.Second
+	// stableRunDuration is how long a restarted plugin process must keep running for its crashes to be forgotten
+	stableRunDuration = time.Minute
+)
+
+// HealthStatuses returns the health of the supervised backend plugins, sorted by plugin ID.
+func (m *PluginManager) HealthStatuses(_ context.Context) []plugins.HealthStatus {
+	m.healthMu.RLock()
+	defer m.healthMu.RUnlock()
+
+	res := make([]plugins.HealthStatus, 0, len(m.health))
+	for _, h := range m.health {
+		res = append(res, h.status())
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].PluginID < res[j].PluginID
+	})
+	return res
+}
+
+// supervise restarts the plugin process with an exponential backoff when it exits and checks the health of the
+// plugin while its process is running, until the plugin is decommissioned or ctx is done.
+func (m *PluginManager) supervise(ctx context.Context, p *plugins.Plugin, h *pluginHealth) {
+	ticker := time.NewTicker(superviseInterval)
+	defer ticker.Stop()
+
+	var nextHealthCheck time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if p.IsDecommissioned() {
+				p.Logger().Debug("Plugin decommissioned")
+				return
+			}
+
+			if p.Exited() {
+				m.restart(ctx, p, h, now)
+				continue
+			}
+			h.running(now)
+
+			if m.cfg.HealthCheckInterval > 0 && !now.Before(nextHealthCheck) {
+				nextHealthCheck = now.Add(m.cfg.HealthCheckInterval)
+				m.checkHealth(ctx, p, h)
+			}
+		}
+	}
+}
+
+// restart restarts an exited plugin process once its restart backoff has elapsed.
+func (m *PluginManager) restart(ctx context.Context, p *plugins.Plugin, h *pluginHealth, now time.Time) {
+	maxBackoff := m.cfg.RestartMaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRestartMaxBackoff
+	}
+
+	if !h.exited(now, maxBackoff) {
+		return
+	}
+
+	p.Logger().Debug("Restarting plugin")
+	if err := p.Start(ctx); err != nil {
+		p.Logger().Error("Failed to restart plugin", "error", err)
+		h.restartFailed(now, maxBackoff, err)
+		return
+	}
+	h.restarted(now)
+	p.Logger().Debug("Plugin restarted")
+}
+
+// checkHealth probes the plugin with a health check. The health check only tells whether the plugin responds,
+// since it is not made on behalf of a data source the health reported by the plugin is not considered.
+func (m *PluginManager) checkHealth(ctx context.Context, p *plugins.Plugin, h *pluginHealth) {
+	timeout := m.cfg.HealthCheckInterval
+	if timeout > maxHealthCheckTimeout {
+		timeout = maxHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	_, err := p.CheckHealth(ctx, &backend.CheckHealthRequest{
+		PluginContext: backend.PluginContext{PluginID: p.ID},
+	})
+	h.healthChecked(time.Now(), err)
+}
+
+// trackHealth starts tracking the health of a started plugin, replacing the health of a previous version.
+func (m *PluginManager) trackHealth(p *plugins.Plugin) *pluginHealth {
+	h := newPluginHealth(p.ID, m.cfg.HealthCheckFailureThreshold, m.cfg.CircuitBreakerOpenDuration)
+
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	if previous, exists := m.health[p.ID]; exists {
+		previous.untrack()
+	}
+	m.health[p.ID] = h
+	return h
+}
+
+// untrackHealth stops tracking the health of an unregistered plugin.
+func (m *PluginManager) untrackHealth(pluginID string) {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	if h, exists := m.health[pluginID]; exists {
+		h.untrack()
+		delete(m.health, pluginID)
+	}
+}
+
+// admit checks whether the circuit breaker of a plugin lets a request through. The returned function must be
+// called with the result of the request.
+func (m *PluginManager) admit(pluginID, endpoint string) (func(error), error) {
+	m.healthMu.RLock()
+	h, exists := m.health[pluginID]
+	m.healthMu.RUnlock()
+	if !exists {
+		return func(error) {}, nil
+	}
+
+	if err := h.allow(time.Now()); err != nil {
+		instrumentation.IncPluginRejectedRequests(pluginID, endpoint)
+		return nil, err
+	}
+	return h.observe, nil
+}
+
+// pluginHealth tracks the health and the circuit breaker of a backend plugin.
+type pluginHealth struct {
+	pluginID         string
+	failureThreshold int
+	openDuration     time.Duration
+
+	mu                  sync.Mutex
+	state               plugins.HealthState
+	circuit             plugins.CircuitState
+	consecutiveFailures int
+	crashes             int
+	restarts            int
+	lastError           string
+	lastHealthCheck     time.Time
+	nextRestart         time.Time
+	runningSince        time.Time
+	openUntil           time.Time
+	trial               bool
+}
+
+func newPluginHealth(pluginID string, failureThreshold int, openDuration time.Duration) *pluginHealth {
+	h := &pluginHealth{
+		pluginID:         pluginID,
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+	}
+	h.setState(plugins.HealthStateHealthy)
+	h.setCircuit(plugins.CircuitClosed)
+	return h
+}
+
+// allow lets a request through unless the circuit is open. An open circuit lets a trial request through once
+// it has been open for the open duration, as long as the plugin process is running.
+func (h *pluginHealth) allow(now time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch h.circuit {
+	case plugins.CircuitOpen:
+		if h.nextRestart.IsZero() && !now.Before(h.openUntil) {
+			h.setCircuit(plugins.CircuitHalfOpen)
+			h.trial = true
+			return nil
+		}
+	case plugins.CircuitHalfOpen:
+		if !h.trial {
+			h.trial = true
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s is %s, its requests are rejected until it recovers: %s",
+		backendplugin.ErrPluginUnhealthy, h.pluginID, h.state, h.lastError)
+}
+
+// observe records the result of a request to the plugin. Requests which time out or cannot reach the plugin
+// are failures, the other results show that the plugin responds.
+func (h *pluginHealth) observe(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		// the request was abandoned by its caller before the plugin responded
+		h.trial = false
+	case isPluginFailure(err):
+		h.failed(time.Now(), err)
+	default:
+		h.succeeded()
+	}
+}
+
+// healthChecked records the result of a health check, which is also a trial when the circuit is open.
+func (h *pluginHealth) healthChecked(now time.Time, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastHealthCheck = now
+	switch {
+	case errors.Is(err, context.Canceled):
+	case isPluginFailure(err):
+		h.failed(now, err)
+	default:
+		h.succeeded()
+	}
+}
+
+// exited records that the plugin process exited and tells whether the process is due to be restarted.
+func (h *pluginHealth) exited(now time.Time, maxBackoff time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.nextRestart.IsZero() {
+		h.crashed(now, maxBackoff, "plugin process exited")
+	}
+	return !now.Before(h.nextRestart)
+}
+
+// restartFailed records a failed restart of the plugin process, which is retried after a longer backoff.
+func (h *pluginHealth) restartFailed(now time.Time, maxBackoff time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.crashed(now, maxBackoff, err.Error())
+}
+
+// restarted records a restart of the plugin process, the next request is a trial of the restarted process.
+func (h *pluginHealth) restarted(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.restarts++
+	instrumentation.IncPluginRestarts(h.pluginID)
+
+	h.nextRestart = time.Time{}
+	h.runningSince = now
+	h.trial = false
+	h.setCircuit(plugins.CircuitHalfOpen)
+	if h.state == plugins.HealthStateRestarting {
+		h.setState(plugins.HealthStateHealthy)
+	}
+}
+
+// running records that the plugin process is running, its crashes are forgotten once it has kept running for
+// stableRunDuration.
+func (h *pluginHealth) running(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.crashes == 0 || now.Sub(h.runningSince) < stableRunDuration {
+		return
+	}
+	h.crashes = 0
+	if h.state == plugins.HealthStateCrashLoop {
+		h.setState(plugins.HealthStateHealthy)
+	}
+}
+
+func (h *pluginHealth) crashed(now time.Time, maxBackoff time.Duration, reason string) {
+	h.crashes++
+	h.lastError = reason
+	h.nextRestart = now.Add(restartBackoff(h.crashes, maxBackoff))
+	h.trial = false
+	h.setCircuit(plugins.CircuitOpen)
+	if h.crashes >= crashLoopThreshold {
+		h.setState(plugins.HealthStateCrashLoop)
+	} else {
+		h.setState(plugins.HealthStateRestarting)
+	}
+}
+
+func (h *pluginHealth) failed(now time.Time, err error) {
+	h.consecutiveFailures++
+	h.lastError = err.Error()
+	if h.state == plugins.HealthStateHealthy {
+		h.setState(plugins.HealthStateUnhealthy)
+	}
+
+	switch {
+	case h.circuit == plugins.CircuitHalfOpen,
+		h.circuit == plugins.CircuitOpen && h.nextRestart.IsZero(),
+		h.circuit == plugins.CircuitClosed && h.failureThreshold > 0 && h.consecutiveFailures >= h.failureThreshold:
+		h.trial = false
+		h.openUntil = now.Add(h.openDuration)
+		h.setCircuit(plugins.CircuitOpen)
+	}
+}
+
+func (h *pluginHealth) succeeded() {
+	h.consecutiveFailures = 0
+	h.lastError = ""
+	h.trial = false
+	if h.state == plugins.HealthStateUnhealthy {
+		h.setState(plugins.HealthStateHealthy)
+	}
+	if h.nextRestart.IsZero() {
+		h.setCircuit(plugins.CircuitClosed)
+	}
+}
+
+func (h *pluginHealth) setState(state plugins.HealthState) {
+	instrumentation.SetPluginHealthState(h.pluginID, string(h.state), string(state))
+	h.state = state
+}
+
+func (h *pluginHealth) setCircuit(circuit plugins.CircuitState) {
+	instrumentation.SetPluginCircuitBreakerState(h.pluginID, string(h.circuit), string(circuit))
+	h.circuit = circuit
+}
+
+// untrack removes the health metrics of the plugin.
+func (h *pluginHealth) untrack() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	instrumentation.SetPluginHealthState(h.pluginID, string(h.state), "")
+	instrumentation.SetPluginCircuitBreakerState(h.pluginID, string(h.circuit), "")
+}
+
+func (h *pluginHealth) status() plugins.HealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := plugins.HealthStatus{
+		PluginID:            h.pluginID,
+		State:               h.state,
+		Circuit:             h.circuit,
+		ConsecutiveFailures: h.consecutiveFailures,
+		Restarts:            h.restarts,
+		LastError:           h.lastError,
+	}
+	if !h.lastHealthCheck.IsZero() {
+		lastHealthCheck := h.lastHealthCheck
+		s.LastHealthCheck = &lastHealthCheck
+	}
+	if !h.nextRestart.IsZero() {
+		nextRestart := h.nextRestart
+		s.NextRestart = &nextRestart
+	}
+	return s
+}
+
+// restartBackoff returns the delay before restarting a plugin process after its consecutive crashes. The first
+// crash is restarted right away, the delay then doubles from a second up to maxBackoff.
+func restartBackoff(crashes int, maxBackoff time.Duration) time.Duration {
+	if crashes <= 1 {
+		return 0
+	}
+
+	backoff := time.Second
+	for i := 2; i < crashes && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+// isPluginFailure tells whether a request error shows that the plugin is unavailable or not responding.
+func isPluginFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, backendplugin.ErrPluginUnavailable) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/manager/registry"
+)
+
+func TestPluginManager_circuitBreaker(t *testing.T) {
+	setup := func(t *testing.T) (*PluginManager, *fakePluginClient) {
+		t.Helper()
+
+		pm := New(&plugins.Cfg{
+			HealthCheckFailureThreshold: 2,
+			CircuitBreakerOpenDuration:  50 * time.Millisecond,
+		}, registry.NewInMemory(), nil, &fakeLoader{})
+		p, pc := createPlugin(t, testPluginID, "1.0.0", plugins.External, true, true)
+		require.NoError(t, pm.registerAndStart(context.Background(), p))
+		t.Cleanup(func() {
+			require.NoError(t, pm.unregisterAndStop(context.Background(), p))
+		})
+		return pm, pc
+	}
+
+	query := func(pm *PluginManager) error {
+		_, err := pm.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{PluginID: testPluginID},
+		})
+		return err
+	}
+
+	t.Run("Requests fail fast once the plugin keeps timing out", func(t *testing.T) {
+		pm, pc := setup(t)
+		calls := 0
+		pc.QueryDataHandlerFunc = func(_ context.Context, _ *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			calls++
+			return nil, status.Error(codes.DeadlineExceeded, "context deadline exceeded")
+		}
+
+		require.Error(t, query(pm))
+		require.Equal(t, plugins.CircuitClosed, pm.HealthStatuses(context.Background())[0].Circuit)
+		require.Error(t, query(pm))
+
+		health := pm.HealthStatuses(context.Background())
+		require.Len(t, health, 1)
+		require.Equal(t, plugins.HealthStateUnhealthy, health[0].State)
+		require.Equal(t, plugins.CircuitOpen, health[0].Circuit)
+		require.Equal(t, 2, health[0].ConsecutiveFailures)
+
+		err := query(pm)
+		require.ErrorIs(t, err, backendplugin.ErrPluginUnhealthy)
+		require.Contains(t, err.Error(), "deadline exceeded")
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Errors returned by a responding plugin do not open the circuit", func(t *testing.T) {
+		pm, pc := setup(t)
+		pc.QueryDataHandlerFunc = func(_ context.Context, _ *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			return nil, status.Error(codes.InvalidArgument, "invalid query")
+		}
+
+		for i := 0; i < 3; i++ {
+			require.Error(t, query(pm))
+		}
+		health := pm.HealthStatuses(context.Background())
+		require.Equal(t, plugins.HealthStateHealthy, health[0].State)
+		require.Equal(t, plugins.CircuitClosed, health[0].Circuit)
+	})
+
+	t.Run("A successful trial request closes the circuit", func(t *testing.T) {
+		pm, pc := setup(t)
+		failing := true
+		pc.QueryDataHandlerFunc = func(_ context.Context, _ *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			if failing {
+				return nil, backendplugin.ErrPluginUnavailable
+			}
+			return &backend.QueryDataResponse{}, nil
+		}
+
+		require.Error(t, query(pm))
+		require.Error(t, query(pm))
+		require.ErrorIs(t, query(pm), backendplugin.ErrPluginUnhealthy)
+
+		time.Sleep(60 * time.Millisecond)
+		failing = false
+		require.NoError(t, query(pm))
+
+		health := pm.HealthStatuses(context.Background())
+		require.Equal(t, plugins.HealthStateHealthy, health[0].State)
+		require.Equal(t, plugins.CircuitClosed, health[0].Circuit)
+		require.Zero(t, health[0].ConsecutiveFailures)
+	})
+
+	t.Run("A failed trial request opens the circuit again", func(t *testing.T) {
+		pm, pc := setup(t)
+		pc.QueryDataHandlerFunc = func(_ context.Context, _ *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			return nil, context.DeadlineExceeded
+		}
+
+		require.Error(t, query(pm))
+		require.Error(t, query(pm))
+
+		time.Sleep(60 * time.Millisecond)
+		require.ErrorIs(t, query(pm), context.DeadlineExceeded)
+		require.ErrorIs(t, query(pm), backendplugin.ErrPluginUnhealthy)
+		require.Equal(t, plugins.CircuitOpen, pm.HealthStatuses(context.Background())[0].Circuit)
+	})
+
+	t.Run("Unloaded plugins are not supervised anymore", func(t *testing.T) {
+		pm := New(&plugins.Cfg{}, registry.NewInMemory(), nil, &fakeLoader{})
+		p, _ := createPlugin(t, testPluginID, "1.0.0", plugins.External, true, true)
+		require.NoError(t, pm.registerAndStart(context.Background(), p))
+		require.Len(t, pm.HealthStatuses(context.Background()), 1)
+
+		require.NoError(t, pm.unregisterAndStop(context.Background(), p))
+		require.Empty(t, pm.HealthStatuses(context.Background()))
+	})
+}
+
+func TestPluginManager_healthChecks(t *testing.T) {
+	pm := New(&plugins.Cfg{
+		HealthCheckInterval:         10 * time.Millisecond,
+		HealthCheckFailureThreshold: 1,
+		CircuitBreakerOpenDuration:  time.Hour,
+	}, registry.NewInMemory(), nil, &fakeLoader{})
+	p, pc := createPlugin(t, testPluginID, "1.0.0", plugins.External, true, true)
+
+	responding := make(chan bool, 1)
+	responding <- false
+	pc.CheckHealthHandlerFunc = func(_ context.Context, _ *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+		r := <-responding
+		responding <- r
+		if !r {
+			return nil, backendplugin.ErrPluginUnavailable
+		}
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: "no data source"}, nil
+	}
+
+	require.NoError(t, pm.registerAndStart(context.Background(), p))
+	t.Cleanup(func() {
+		require.NoError(t, pm.unregisterAndStop(context.Background(), p))
+	})
+
+	require.Eventually(t, func() bool {
+		return pm.HealthStatuses(context.Background())[0].Circuit == plugins.CircuitOpen
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// a responding plugin closes the circuit even though the health it reports is not ok
+	<-responding
+	responding <- true
+	require.Eventually(t, func() bool {
+		return pm.HealthStatuses(context.Background())[0].Circuit == plugins.CircuitClosed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	health := pm.HealthStatuses(context.Background())[0]
+	require.Equal(t, plugins.HealthStateHealthy, health.State)
+	require.NotNil(t, health.LastHealthCheck)
+}
+
+func TestPluginHealth_restarts(t *testing.T) {
+	now := time.Now()
+	maxBackoff := 8 * time.Second
+	h := newPluginHealth(testPluginID, 3, time.Second)
+	t.Cleanup(h.untrack)
+
+	t.Run("The first crash is restarted right away", func(t *testing.T) {
+		require.True(t, h.exited(now, maxBackoff))
+		require.Equal(t, plugins.HealthStateRestarting, h.status().State)
+		require.Equal(t, plugins.CircuitOpen, h.status().Circuit)
+		require.ErrorIs(t, h.allow(now), backendplugin.ErrPluginUnhealthy)
+
+		h.restarted(now)
+		require.Equal(t, plugins.HealthStateHealthy, h.status().State)
+		require.Equal(t, plugins.CircuitHalfOpen, h.status().Circuit)
+		require.Equal(t, 1, h.status().Restarts)
+	})
+
+	t.Run("Consecutive crashes are restarted after a growing backoff", func(t *testing.T) {
+		for _, backoff := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+			require.False(t, h.exited(now, maxBackoff))
+			require.Equal(t, now.Add(backoff), *h.status().NextRestart)
+
+			now = now.Add(backoff)
+			require.True(t, h.exited(now, maxBackoff))
+			h.restarted(now)
+		}
+	})
+
+	t.Run("A plugin crashing repeatedly is crash looping", func(t *testing.T) {
+		require.False(t, h.exited(now, maxBackoff))
+		require.Equal(t, plugins.HealthStateCrashLoop, h.status().State)
+		require.Equal(t, now.Add(maxBackoff), *h.status().NextRestart)
+
+		h.restartFailed(now.Add(maxBackoff), maxBackoff, backendplugin.ErrPluginUnavailable)
+		require.Equal(t, now.Add(2*maxBackoff), *h.status().NextRestart)
+
+		now = now.Add(2 * maxBackoff)
+		require.True(t, h.exited(now, maxBackoff))
+		h.restarted(now)
+		require.Equal(t, plugins.HealthStateCrashLoop, h.status().State)
+	})
+
+	t.Run("A plugin which keeps running is not crash looping anymore", func(t *testing.T) {
+		h.running(now.Add(stableRunDuration / 2))
+		require.Equal(t, plugins.HealthStateCrashLoop, h.status().State)
+
+		h.running(now.Add(stableRunDuration))
+		require.Equal(t, plugins.HealthStateHealthy, h.status().State)
+
+		require.True(t, h.exited(now.Add(stableRunDuration), maxBackoff))
+		require.Equal(t, plugins.HealthStateRestarting, h.status().State)
+	})
+}
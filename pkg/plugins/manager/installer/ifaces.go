@@ -10,6 +10,12 @@ import (
 type Service interface {
 	// Install downloads the requested plugin in the provided file system location.
 	Install(ctx context.Context, pluginID, version, pluginsDir, pluginZipURL, pluginRepoURL string) error
+	// InstallFromRepository downloads the requested plugin and its dependencies from the index of a private
+	// plugin repository in the provided file system location. The version may be a version constraint.
+	InstallFromRepository(ctx context.Context, pluginID, version, pluginsDir, repositoryURL string) error
+	// InstallArchive extracts the plugin archive at the provided local path or URL in the provided file system
+	// location and installs the missing dependencies of the plugin from the private plugin repository, if any.
+	InstallArchive(ctx context.Context, pluginID, archiveURL, checksum, pluginsDir, repositoryURL string) error
 	// Uninstall removes the requested plugin from the provided file system location.
 	Uninstall(ctx context.Context, pluginDir string) error
 	// GetUpdateInfo provides update information for the requested plugin.
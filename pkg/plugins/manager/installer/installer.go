@@ -41,8 +41,22 @@ const (
 
 var (
 	reGitBuild = regexp.MustCompile("^[a-zA-Z0-9_.-]*/")
+	// rePluginID matches the plugin IDs which can be used as the name of the directory of a plugin
+	rePluginID = regexp.MustCompile("^[a-zA-Z0-9][a-zA-Z0-9._-]*$")
 )
 
+// ErrInvalidPluginID is returned when a plugin ID cannot be used as the name of the directory of a plugin.
+var ErrInvalidPluginID = errors.New("invalid plugin ID")
+
+// ValidatePluginID returns ErrInvalidPluginID when a plugin ID cannot be used as the name of the directory of a
+// plugin, such as IDs with path elements.
+func ValidatePluginID(pluginID string) error {
+	if !rePluginID.MatchString(pluginID) {
+		return fmt.Errorf("%w: %q", ErrInvalidPluginID, pluginID)
+	}
+	return nil
+}
+
 type Response4xxError struct {
 	Message    string
 	StatusCode int
@@ -79,6 +93,23 @@ func (e ErrVersionNotFound) Error() string {
 	return fmt.Sprintf("%s v%s either does not exist or is not supported on your system (%s)", e.PluginID, e.RequestedVersion, e.SystemInfo)
 }
 
+type ErrChecksumMismatch struct {
+	PluginID string
+}
+
+func (e ErrChecksumMismatch) Error() string {
+	return fmt.Sprintf("expected SHA256 checksum does not match the downloaded archive of %s", e.PluginID)
+}
+
+type ErrPluginIDMismatch struct {
+	PluginID        string
+	ArchivePluginID string
+}
+
+func (e ErrPluginIDMismatch) Error() string {
+	return fmt.Sprintf("the archive of %s contains the plugin %s", e.PluginID, e.ArchivePluginID)
+}
+
 func New(skipTLSVerify bool, grafanaVersion string, logger Logger) Service {
 	return &Installer{
 		httpClient:          makeHttpClient(skipTLSVerify, 10*time.Second),
@@ -122,6 +153,32 @@ func (i *Installer) Install(ctx context.Context, pluginID, version, pluginsDir,
 		}
 	}
 
+	if err := i.downloadAndExtract(pluginID, pluginZipURL, checksum, pluginsDir); err != nil {
+		return err
+	}
+
+	res, _ := toPluginDTO(pluginsDir, pluginID)
+
+	i.log.Successf("Downloaded %s v%s zip successfully", res.ID, res.Info.Version)
+
+	// download dependency plugins
+	for _, dep := range res.Dependencies.Plugins {
+		i.log.Infof("Fetching %s dependencies...", res.ID)
+		if err := i.Install(ctx, dep.ID, normalizeVersion(dep.Version), pluginsDir, "", pluginRepoURL); err != nil {
+			return fmt.Errorf("failed to install plugin %s: %w", dep.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// downloadAndExtract downloads the plugin archive from the specified local path or URL, verifying its checksum
+// when provided, and extracts it into the provided plugins directory. An installed version of the plugin is only
+// replaced once the archive has been downloaded and extracted successfully.
+func (i *Installer) downloadAndExtract(pluginID, pluginZipURL, checksum, pluginsDir string) error {
+	if err := ValidatePluginID(pluginID); err != nil {
+		return err
+	}
 	i.log.Debugf("Installing plugin\nfrom: %s\ninto: %s", pluginZipURL, pluginsDir)
 
 	// Create temp file for downloading zip file
@@ -147,24 +204,61 @@ func (i *Installer) Install(ctx context.Context, pluginID, version, pluginsDir,
 		return fmt.Errorf("%v: %w", "failed to close tmp file", err)
 	}
 
-	err = i.extractFiles(tmpFile.Name(), pluginID, pluginsDir)
+	// the staging directory is in the plugins directory so that the extracted plugin can be moved into place
+	// We can ignore gosec G301 here since it makes sense to give all users read access
+	// nolint:gosec
+	if err := os.MkdirAll(pluginsDir, 0755); err != nil {
+		return fmt.Errorf("%v: %w", "failed to create plugins directory", err)
+	}
+	stagingDir, err := os.MkdirTemp(pluginsDir, ".staging-"+pluginID+"-")
+	if err != nil {
+		return fmt.Errorf("%v: %w", "failed to create staging directory", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(stagingDir); err != nil {
+			i.log.Warn("Failed to remove staging directory", "dir", stagingDir, "err", err)
+		}
+	}()
+
+	err = i.extractFiles(tmpFile.Name(), pluginID, stagingDir)
 	if err != nil {
 		return fmt.Errorf("%v: %w", "failed to extract plugin archive", err)
 	}
 
-	res, _ := toPluginDTO(pluginsDir, pluginID)
+	// the archive must contain the requested plugin in a single top level directory
+	extracted, err := toPluginDTO(stagingDir, pluginID)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "invalid plugin archive", err)
+	}
+	if extracted.ID != pluginID {
+		return ErrPluginIDMismatch{PluginID: pluginID, ArchivePluginID: extracted.ID}
+	}
 
-	i.log.Successf("Downloaded %s v%s zip successfully", res.ID, res.Info.Version)
+	return i.replaceInstallDir(filepath.Join(stagingDir, pluginID), filepath.Join(pluginsDir, pluginID), stagingDir)
+}
 
-	// download dependency plugins
-	for _, dep := range res.Dependencies.Plugins {
-		i.log.Infof("Fetching %s dependencies...", res.ID)
-		if err := i.Install(ctx, dep.ID, normalizeVersion(dep.Version), pluginsDir, "", pluginRepoURL); err != nil {
-			return fmt.Errorf("failed to install plugin %s: %w", dep.ID, err)
+// replaceInstallDir moves an extracted plugin into its install directory. An existing installation is moved aside
+// into the staging directory first, and moved back when the extracted plugin cannot be moved into place.
+func (i *Installer) replaceInstallDir(extractedDir, installDir, stagingDir string) error {
+	previousDir := filepath.Join(stagingDir, ".previous")
+	_, err := os.Stat(installDir)
+	exists := err == nil
+	if exists {
+		i.log.Debugf("Replacing existing installation of plugin %s", installDir)
+		if err := os.Rename(installDir, previousDir); err != nil {
+			return fmt.Errorf("%v: %w", "failed to move existing installation of plugin", err)
 		}
 	}
 
-	return err
+	if err := os.Rename(extractedDir, installDir); err != nil {
+		if exists {
+			if restoreErr := os.Rename(previousDir, installDir); restoreErr != nil {
+				i.log.Error("Failed to restore existing installation of plugin", "dir", installDir, "err", restoreErr)
+			}
+		}
+		return fmt.Errorf("%v: %w", "failed to move extracted plugin into place", err)
+	}
+	return nil
 }
 
 // Uninstall removes the specified plugin from the provided plugin directory.
@@ -200,10 +294,14 @@ func (i *Installer) DownloadFile(pluginID string, tmpFile *os.File, url string,
 				i.log.Warn("Failed to close file", "err", err)
 			}
 		}()
-		_, err = io.Copy(tmpFile, f)
+		h := sha256.New()
+		_, err = io.Copy(tmpFile, io.TeeReader(f, h))
 		if err != nil {
 			return fmt.Errorf("%v: %w", "Failed to copy plugin archive", err)
 		}
+		if len(checksum) > 0 && !strings.EqualFold(checksum, fmt.Sprintf("%x", h.Sum(nil))) {
+			return ErrChecksumMismatch{PluginID: pluginID}
+		}
 		return nil
 	}
 
@@ -255,8 +353,8 @@ func (i *Installer) DownloadFile(pluginID string, tmpFile *os.File, url string,
 	if err := w.Flush(); err != nil {
 		return fmt.Errorf("failed to write to %q: %w", tmpFile.Name(), err)
 	}
-	if len(checksum) > 0 && checksum != fmt.Sprintf("%x", h.Sum(nil)) {
-		return fmt.Errorf("expected SHA256 checksum does not match the downloaded archive - please contact security@grafana.com")
+	if len(checksum) > 0 && !strings.EqualFold(checksum, fmt.Sprintf("%x", h.Sum(nil))) {
+		return ErrChecksumMismatch{PluginID: pluginID}
 	}
 	return nil
 }
@@ -36,10 +36,17 @@ type Version struct {
 	URL     string              `json:"url"`
 	Version string              `json:"version"`
 	Arch    map[string]ArchMeta `json:"arch"`
+
+	// The fields below are set in the index of private plugin repositories
+	SHA256            string             `json:"sha256,omitempty"`
+	GrafanaDependency string             `json:"grafanaDependency,omitempty"`
+	Dependencies      []PluginDependency `json:"dependencies,omitempty"`
 }
 
 type ArchMeta struct {
 	SHA256 string `json:"sha256"`
+	// URL of the archive for the architecture, set in the index of private plugin repositories
+	URL string `json:"url,omitempty"`
 }
 
 type PluginRepo struct {
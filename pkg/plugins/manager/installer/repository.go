@@ -0,0 +1,333 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// RepositoryIndexFile is the name of the index file of a private plugin repository stored in a local directory.
+const RepositoryIndexFile = "index.json"
+
+// repositoryIndex is the index of a private plugin repository, along with the location the relative
+// archive URLs of the index are resolved against.
+type repositoryIndex struct {
+	PluginRepo
+	location string
+}
+
+// resolvedVersion is a plugin version selected to be installed from a private plugin repository.
+type resolvedVersion struct {
+	pluginID   string
+	version    string
+	archiveURL string
+	checksum   string
+}
+
+// InstallFromRepository downloads the requested plugin and its dependencies from the index of a private
+// plugin repository, served over HTTP or stored in a local directory. The version of each plugin is the latest
+// version satisfying the version constraints on the plugin and supporting the Grafana version and the system.
+// Dependencies which are already installed with a version satisfying their constraints are not installed again.
+func (i *Installer) InstallFromRepository(ctx context.Context, pluginID, version, pluginsDir, repositoryURL string) error {
+	index, err := i.getRepositoryIndex(repositoryURL)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := i.resolve(index, pluginID, version, pluginsDir)
+	if err != nil {
+		return err
+	}
+
+	// dependencies are installed before the plugins depending on them
+	for idx := len(resolved) - 1; idx >= 0; idx-- {
+		r := resolved[idx]
+		if err := i.downloadAndExtract(r.pluginID, r.archiveURL, r.checksum, pluginsDir); err != nil {
+			return fmt.Errorf("failed to install plugin %s: %w", r.pluginID, err)
+		}
+		i.log.Successf("Downloaded %s v%s zip successfully", r.pluginID, r.version)
+	}
+
+	return nil
+}
+
+// InstallArchive extracts the plugin archive at the provided local path or URL, verifying its checksum
+// when provided. The missing dependencies of the plugin are installed from the private plugin repository
+// when repositoryURL is set.
+func (i *Installer) InstallArchive(ctx context.Context, pluginID, archiveURL, checksum, pluginsDir, repositoryURL string) error {
+	if err := i.downloadAndExtract(pluginID, archiveURL, checksum, pluginsDir); err != nil {
+		return err
+	}
+
+	res, err := toPluginDTO(pluginsDir, pluginID)
+	if err != nil {
+		return err
+	}
+	i.log.Successf("Extracted %s v%s zip successfully", res.ID, res.Info.Version)
+
+	for _, dep := range res.Dependencies.Plugins {
+		if installedVersionSatisfies(pluginsDir, dep.ID, []string{dep.Version}) {
+			continue
+		}
+		if repositoryURL == "" {
+			i.log.Warnf("Plugin %s depends on %s %s which is not installed", res.ID, dep.ID, dep.Version)
+			continue
+		}
+
+		i.log.Infof("Fetching %s dependencies...", res.ID)
+		if err := i.InstallFromRepository(ctx, dep.ID, dep.Version, pluginsDir, repositoryURL); err != nil {
+			return fmt.Errorf("failed to install plugin %s: %w", dep.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (i *Installer) getRepositoryIndex(repositoryURL string) (*repositoryIndex, error) {
+	var body []byte
+	location := repositoryURL
+	if isHTTPURL(repositoryURL) {
+		i.log.Debugf("Fetching plugin repository index %s", repositoryURL)
+		var err error
+		if body, err = i.sendRequestGetBytes(repositoryURL); err != nil {
+			return nil, err
+		}
+	} else {
+		info, err := os.Stat(repositoryURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read plugin repository: %w", err)
+		}
+		if info.IsDir() {
+			location = filepath.Join(repositoryURL, RepositoryIndexFile)
+		}
+
+		i.log.Debugf("Reading plugin repository index %s", location)
+		// We can ignore the gosec G304 warning since the plugin repository is configured by the server admin.
+		// nolint:gosec
+		if body, err = os.ReadFile(location); err != nil {
+			return nil, fmt.Errorf("failed to read plugin repository index: %w", err)
+		}
+	}
+
+	index := &repositoryIndex{location: location}
+	if err := json.Unmarshal(body, &index.PluginRepo); err != nil {
+		return nil, fmt.Errorf("failed to parse plugin repository index: %w", err)
+	}
+	return index, nil
+}
+
+// resolve selects the versions of a plugin and of its transitive dependencies to install, the plugin is first.
+func (i *Installer) resolve(index *repositoryIndex, pluginID, version, pluginsDir string) ([]resolvedVersion, error) {
+	constraints := map[string][]string{pluginID: {version}}
+	selected := make(map[string]resolvedVersion)
+	var order []string
+
+	// a plugin is selected again with the new constraints on it, which only lowers its version
+	queue := []string{pluginID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if id != pluginID && installedVersionSatisfies(pluginsDir, id, constraints[id]) {
+			delete(selected, id)
+			continue
+		}
+
+		v, err := i.selectRepositoryVersion(index, id, constraints[id])
+		if err != nil {
+			return nil, err
+		}
+		if previous, exists := selected[id]; exists && previous.version == v.Version {
+			continue
+		}
+
+		archiveURL, checksum := archiveForSystem(v)
+		if archiveURL == "" {
+			return nil, fmt.Errorf("plugin %s v%s has no archive in the plugin repository index", id, v.Version)
+		}
+		if checksum == "" {
+			return nil, fmt.Errorf("plugin %s v%s has no SHA256 checksum in the plugin repository index", id, v.Version)
+		}
+		archiveURL, err = index.resolveURL(archiveURL)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := selected[id]; !exists {
+			order = append(order, id)
+		}
+		selected[id] = resolvedVersion{pluginID: id, version: v.Version, archiveURL: archiveURL, checksum: checksum}
+
+		for _, dep := range v.Dependencies {
+			constraints[dep.ID] = append(constraints[dep.ID], dep.Version)
+			queue = append(queue, dep.ID)
+		}
+	}
+
+	resolved := make([]resolvedVersion, 0, len(order))
+	for _, id := range order {
+		if r, exists := selected[id]; exists {
+			resolved = append(resolved, r)
+		}
+	}
+	return resolved, nil
+}
+
+// selectRepositoryVersion returns the latest version of a plugin of the repository satisfying the constraints
+// and supporting the Grafana version and the system.
+func (i *Installer) selectRepositoryVersion(index *repositoryIndex, pluginID string, constraints []string) (*Version, error) {
+	requested := strings.Join(nonEmpty(constraints), ", ")
+
+	var plugin *Plugin
+	for idx := range index.Plugins {
+		if index.Plugins[idx].ID == pluginID {
+			plugin = &index.Plugins[idx]
+			break
+		}
+	}
+	if plugin == nil {
+		return nil, ErrVersionNotFound{PluginID: pluginID, RequestedVersion: requested, SystemInfo: i.fullSystemInfoString()}
+	}
+
+	versions := make([]*semver.Version, 0, len(plugin.Versions))
+	byVersion := make(map[*semver.Version]*Version)
+	for idx := range plugin.Versions {
+		v, err := semver.NewVersion(plugin.Versions[idx].Version)
+		if err != nil {
+			i.log.Debugf("Ignoring invalid version %q of plugin %s", plugin.Versions[idx].Version, pluginID)
+			continue
+		}
+		versions = append(versions, v)
+		byVersion[v] = &plugin.Versions[idx]
+	}
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+
+	matched := false
+	for _, v := range versions {
+		satisfies, err := satisfiesAll(v, constraints)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint on plugin %s: %w", pluginID, err)
+		}
+		if !satisfies {
+			continue
+		}
+
+		matched = true
+		candidate := byVersion[v]
+		if supportsCurrentArch(candidate) && i.supportsGrafanaVersion(candidate.GrafanaDependency) {
+			return candidate, nil
+		}
+	}
+
+	if matched {
+		return nil, ErrVersionUnsupported{PluginID: pluginID, RequestedVersion: requested, SystemInfo: i.fullSystemInfoString()}
+	}
+	return nil, ErrVersionNotFound{PluginID: pluginID, RequestedVersion: requested, SystemInfo: i.fullSystemInfoString()}
+}
+
+// supportsGrafanaVersion checks the Grafana version against the Grafana dependency of a plugin version,
+// pre-release versions of Grafana are checked as their release version.
+func (i *Installer) supportsGrafanaVersion(grafanaDependency string) bool {
+	if grafanaDependency == "" {
+		return true
+	}
+
+	constraint, err := semver.NewConstraint(grafanaDependency)
+	if err != nil {
+		i.log.Debugf("Ignoring plugin version with invalid Grafana dependency %q", grafanaDependency)
+		return false
+	}
+
+	grafanaVersion, err := semver.NewVersion(i.grafanaVersion)
+	if err != nil {
+		// development builds do not have a semantic version
+		return true
+	}
+	release, err := semver.NewVersion(fmt.Sprintf("%d.%d.%d", grafanaVersion.Major(), grafanaVersion.Minor(), grafanaVersion.Patch()))
+	if err != nil {
+		return true
+	}
+	return constraint.Check(release)
+}
+
+// resolveURL resolves an archive URL of the index relative to the location of the index.
+func (index *repositoryIndex) resolveURL(archiveURL string) (string, error) {
+	if isHTTPURL(archiveURL) || filepath.IsAbs(archiveURL) {
+		return archiveURL, nil
+	}
+
+	if isHTTPURL(index.location) {
+		base, err := url.Parse(index.location)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(archiveURL)
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+
+	return filepath.Join(filepath.Dir(index.location), filepath.FromSlash(archiveURL)), nil
+}
+
+// archiveForSystem returns the URL and checksum of the archive of a plugin version for the system, the archive
+// of the version is used when it has no archive for the system.
+func archiveForSystem(v *Version) (string, string) {
+	for _, arch := range []string{osAndArchString(), "any"} {
+		if meta, exists := v.Arch[arch]; exists && meta.URL != "" {
+			return meta.URL, meta.SHA256
+		}
+	}
+	return v.URL, v.SHA256
+}
+
+// installedVersionSatisfies checks whether a plugin is installed in the plugins directory with a version
+// satisfying the constraints.
+func installedVersionSatisfies(pluginsDir, pluginID string, constraints []string) bool {
+	installed, err := toPluginDTO(pluginsDir, pluginID)
+	if err != nil {
+		return false
+	}
+
+	v, err := semver.NewVersion(installed.Info.Version)
+	if err != nil {
+		return false
+	}
+	satisfies, err := satisfiesAll(v, constraints)
+	return err == nil && satisfies
+}
+
+func satisfiesAll(v *semver.Version, constraints []string) (bool, error) {
+	for _, c := range nonEmpty(constraints) {
+		constraint, err := semver.NewConstraint(c)
+		if err != nil {
+			return false, err
+		}
+		if !constraint.Check(v) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func nonEmpty(values []string) []string {
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func isHTTPURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
@@ -0,0 +1,234 @@
+package installer
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallFromRepository(t *testing.T) {
+	setup := func(t *testing.T) (*Installer, string, string) {
+		t.Helper()
+
+		repoDir := t.TempDir()
+		writeRepositoryIndex(t, repoDir, []Plugin{
+			{ID: "test-app", Versions: []Version{
+				repositoryVersion(t, repoDir, "test-app", "2.0.0", ">=100.0.0", PluginDependency{ID: "test-datasource", Version: ">=2.0.0"}),
+				repositoryVersion(t, repoDir, "test-app", "1.1.0", ">=8.0.0", PluginDependency{ID: "test-datasource", Version: ">=1.0.0, <2.0.0"}),
+			}},
+			{ID: "test-datasource", Versions: []Version{
+				repositoryVersion(t, repoDir, "test-datasource", "1.0.0", ""),
+				repositoryVersion(t, repoDir, "test-datasource", "2.0.0", ""),
+				repositoryVersion(t, repoDir, "test-datasource", "1.5.0", ""),
+			}},
+		})
+
+		i := &Installer{log: &fakeLogger{}, grafanaVersion: "9.1.0-pre"}
+		return i, repoDir, t.TempDir()
+	}
+
+	t.Run("Installs the latest supported versions of the plugin and its dependencies", func(t *testing.T) {
+		i, repoDir, pluginsDir := setup(t)
+
+		err := i.InstallFromRepository(context.Background(), "test-app", "", pluginsDir, repoDir)
+		require.NoError(t, err)
+		requireInstalledVersion(t, pluginsDir, "test-app", "1.1.0")
+		requireInstalledVersion(t, pluginsDir, "test-datasource", "1.5.0")
+	})
+
+	t.Run("Dependencies installed with a satisfying version are kept", func(t *testing.T) {
+		i, repoDir, pluginsDir := setup(t)
+		writePluginJSON(t, filepath.Join(pluginsDir, "test-datasource"), "test-datasource", "1.2.0")
+
+		err := i.InstallFromRepository(context.Background(), "test-app", "", pluginsDir, filepath.Join(repoDir, RepositoryIndexFile))
+		require.NoError(t, err)
+		requireInstalledVersion(t, pluginsDir, "test-app", "1.1.0")
+		requireInstalledVersion(t, pluginsDir, "test-datasource", "1.2.0")
+	})
+
+	t.Run("Versions not supported by the Grafana version are not installed", func(t *testing.T) {
+		i, repoDir, pluginsDir := setup(t)
+
+		err := i.InstallFromRepository(context.Background(), "test-app", "2.0.0", pluginsDir, repoDir)
+		var unsupportedErr ErrVersionUnsupported
+		require.ErrorAs(t, err, &unsupportedErr)
+	})
+
+	t.Run("Unknown plugins or versions are not found", func(t *testing.T) {
+		i, repoDir, pluginsDir := setup(t)
+
+		var notFoundErr ErrVersionNotFound
+		err := i.InstallFromRepository(context.Background(), "test-panel", "", pluginsDir, repoDir)
+		require.ErrorAs(t, err, &notFoundErr)
+		err = i.InstallFromRepository(context.Background(), "test-app", "^3.0.0", pluginsDir, repoDir)
+		require.ErrorAs(t, err, &notFoundErr)
+	})
+
+	t.Run("Archives not matching their checksum are not installed", func(t *testing.T) {
+		i, repoDir, pluginsDir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, "test-datasource-1.5.0.zip"), []byte("tampered"), 0600))
+
+		err := i.InstallFromRepository(context.Background(), "test-app", "", pluginsDir, repoDir)
+		var checksumErr ErrChecksumMismatch
+		require.ErrorAs(t, err, &checksumErr)
+		_, err = os.Stat(filepath.Join(pluginsDir, "test-app"))
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Repositories served over HTTP resolve archive URLs relative to the index", func(t *testing.T) {
+		i, repoDir, pluginsDir := setup(t)
+		server := httptest.NewServer(http.StripPrefix("/plugins/", http.FileServer(http.Dir(repoDir))))
+		t.Cleanup(server.Close)
+		i.httpClient = http.Client{}
+		i.httpClientNoTimeout = http.Client{}
+
+		err := i.InstallFromRepository(context.Background(), "test-app", "1.x", pluginsDir, server.URL+"/plugins/"+RepositoryIndexFile)
+		require.NoError(t, err)
+		requireInstalledVersion(t, pluginsDir, "test-app", "1.1.0")
+		requireInstalledVersion(t, pluginsDir, "test-datasource", "1.5.0")
+	})
+}
+
+func TestInstallArchive(t *testing.T) {
+	repoDir := t.TempDir()
+	writeRepositoryIndex(t, repoDir, []Plugin{
+		{ID: "test-datasource", Versions: []Version{repositoryVersion(t, repoDir, "test-datasource", "1.5.0", "")}},
+	})
+	archiveDir := t.TempDir()
+	archive, checksum := writePluginArchive(t, archiveDir, "test-app", "1.0.0", PluginDependency{ID: "test-datasource", Version: "^1.0.0"})
+
+	i := &Installer{log: &fakeLogger{}, grafanaVersion: "9.1.0"}
+
+	t.Run("Installs the archive and its missing dependencies", func(t *testing.T) {
+		pluginsDir := t.TempDir()
+
+		err := i.InstallArchive(context.Background(), "test-app", archive, checksum, pluginsDir, repoDir)
+		require.NoError(t, err)
+		requireInstalledVersion(t, pluginsDir, "test-app", "1.0.0")
+		requireInstalledVersion(t, pluginsDir, "test-datasource", "1.5.0")
+	})
+
+	t.Run("Archives not matching the checksum are not installed", func(t *testing.T) {
+		pluginsDir := t.TempDir()
+
+		err := i.InstallArchive(context.Background(), "test-app", archive, fmt.Sprintf("%x", sha256.Sum256([]byte("other"))), pluginsDir, repoDir)
+		var checksumErr ErrChecksumMismatch
+		require.ErrorAs(t, err, &checksumErr)
+	})
+
+	t.Run("The installed version is kept when the archive cannot be installed", func(t *testing.T) {
+		pluginsDir := t.TempDir()
+		require.NoError(t, i.InstallArchive(context.Background(), "test-app", archive, checksum, pluginsDir, repoDir))
+
+		updated, updatedChecksum := writePluginArchive(t, t.TempDir(), "test-app", "2.0.0")
+		err := i.InstallArchive(context.Background(), "test-app", updated, checksum, pluginsDir, repoDir)
+		var checksumErr ErrChecksumMismatch
+		require.ErrorAs(t, err, &checksumErr)
+		requireInstalledVersion(t, pluginsDir, "test-app", "1.0.0")
+
+		require.NoError(t, i.InstallArchive(context.Background(), "test-app", updated, updatedChecksum, pluginsDir, repoDir))
+		requireInstalledVersion(t, pluginsDir, "test-app", "2.0.0")
+
+		// the staging directories are removed
+		entries, err := os.ReadDir(pluginsDir)
+		require.NoError(t, err)
+		for _, e := range entries {
+			require.False(t, strings.HasPrefix(e.Name(), ".staging-"), e.Name())
+		}
+	})
+
+	t.Run("Archives of other plugins are not installed", func(t *testing.T) {
+		pluginsDir := t.TempDir()
+		require.NoError(t, i.InstallArchive(context.Background(), "test-app", archive, checksum, pluginsDir, repoDir))
+
+		other, otherChecksum := writePluginArchive(t, t.TempDir(), "other-app", "2.0.0")
+		err := i.InstallArchive(context.Background(), "test-app", other, otherChecksum, pluginsDir, repoDir)
+		var mismatchErr ErrPluginIDMismatch
+		require.ErrorAs(t, err, &mismatchErr)
+		require.Equal(t, "other-app", mismatchErr.ArchivePluginID)
+		requireInstalledVersion(t, pluginsDir, "test-app", "1.0.0")
+	})
+
+	t.Run("Plugin IDs with path elements are rejected", func(t *testing.T) {
+		pluginsDir := filepath.Join(t.TempDir(), "plugins")
+		writePluginJSON(t, filepath.Join(pluginsDir, "test-app"), "test-app", "1.0.0")
+
+		for _, pluginID := range []string{".", "..", "../test-app", "test/app", ""} {
+			err := i.InstallArchive(context.Background(), pluginID, archive, checksum, pluginsDir, repoDir)
+			require.ErrorIs(t, err, ErrInvalidPluginID, pluginID)
+		}
+		requireInstalledVersion(t, pluginsDir, "test-app", "1.0.0")
+	})
+}
+
+func repositoryVersion(t *testing.T, repoDir, pluginID, version, grafanaDependency string, deps ...PluginDependency) Version {
+	t.Helper()
+
+	archive, checksum := writePluginArchive(t, repoDir, pluginID, version, deps...)
+	return Version{
+		Version:           version,
+		URL:               filepath.Base(archive),
+		SHA256:            checksum,
+		GrafanaDependency: grafanaDependency,
+		Dependencies:      deps,
+	}
+}
+
+func writeRepositoryIndex(t *testing.T, repoDir string, plugins []Plugin) {
+	t.Helper()
+
+	data, err := json.Marshal(PluginRepo{Plugins: plugins})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, RepositoryIndexFile), data, 0600))
+}
+
+// writePluginArchive writes a plugin archive in dir and returns its path and SHA256 checksum.
+func writePluginArchive(t *testing.T, dir, pluginID, version string, deps ...PluginDependency) (string, string) {
+	t.Helper()
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.zip", pluginID, version))
+	f, err := os.Create(path)
+	require.NoError(t, err)
+
+	w := zip.NewWriter(f)
+	pluginJSON, err := w.Create(pluginID + "/plugin.json")
+	require.NoError(t, err)
+	require.NoError(t, json.NewEncoder(pluginJSON).Encode(InstalledPlugin{
+		ID:           pluginID,
+		Info:         PluginInfo{Version: version},
+		Dependencies: Dependencies{Plugins: deps},
+	}))
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return path, fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+func writePluginJSON(t *testing.T, pluginDir, pluginID, version string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(pluginDir, 0750))
+	data, err := json.Marshal(InstalledPlugin{ID: pluginID, Info: PluginInfo{Version: version}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "plugin.json"), data, 0600))
+}
+
+func requireInstalledVersion(t *testing.T, pluginsDir, pluginID, version string) {
+	t.Helper()
+
+	installed, err := toPluginDTO(pluginsDir, pluginID)
+	require.NoError(t, err)
+	require.Equal(t, version, installed.Info.Version)
+}
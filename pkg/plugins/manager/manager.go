@@ -2,10 +2,8 @@ package manager
 
 import (
 	"context"
-	"errors"
 	"path/filepath"
 	"sync"
-	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -34,6 +32,16 @@ type PluginManager struct {
 	pluginsMu       sync.RWMutex
 	pluginSources   []PluginSource
 	log             log.Logger
+
+	// reloadMu serializes the changes to the loaded external plugins
+	reloadMu sync.Mutex
+	// fingerprints of the directories of the loaded external plugins, by plugin ID
+	fingerprints map[string]string
+	requests     *requestTracker
+
+	healthMu sync.RWMutex
+	// health of the supervised backend plugins, by plugin ID
+	health map[string]*pluginHealth
 }
 
 type PluginSource struct {
@@ -61,6 +69,9 @@ func New(cfg *plugins.Cfg, pluginRegistry registry.Service, pluginSources []Plug
 		pluginRegistry:  pluginRegistry,
 		log:             log.New("plugin.manager"),
 		pluginInstaller: installer.New(false, cfg.BuildVersion, newInstallerLogger("plugin.installer", true)),
+		fingerprints:    make(map[string]string),
+		requests:        newRequestTracker(),
+		health:          make(map[string]*pluginHealth),
 	}
 }
 
@@ -76,6 +87,10 @@ func (m *PluginManager) Init() error {
 }
 
 func (m *PluginManager) Run(ctx context.Context) error {
+	if m.cfg.HotReloadEnabled {
+		m.watch(ctx, m.cfg.HotReloadInterval)
+	}
+
 	<-ctx.Done()
 	m.shutdown(ctx)
 	return ctx.Err()
@@ -103,6 +118,7 @@ func (m *PluginManager) loadPlugins(ctx context.Context, class plugins.Class, pa
 		if err := m.registerAndStart(context.Background(), p); err != nil {
 			m.log.Error("Could not start plugin", "pluginId", p.ID, "err", err)
 		}
+		m.recordFingerprint(p)
 	}
 
 	return nil
@@ -152,7 +168,15 @@ func (m *PluginManager) registerAndStart(ctx context.Context, p *plugins.Plugin)
 }
 
 func (m *PluginManager) unregisterAndStop(ctx context.Context, p *plugins.Plugin) error {
-	m.log.Debug("Stopping plugin process", "pluginId", p.ID)
+	if err := m.unregister(ctx, p); err != nil {
+		return err
+	}
+
+	return m.drainAndStop(ctx, p)
+}
+
+// unregister removes a plugin from the registry, new requests are not routed to the plugin anymore.
+func (m *PluginManager) unregister(ctx context.Context, p *plugins.Plugin) error {
 	m.pluginsMu.Lock()
 	defer m.pluginsMu.Unlock()
 
@@ -160,18 +184,23 @@ func (m *PluginManager) unregisterAndStop(ctx context.Context, p *plugins.Plugin
 		return err
 	}
 
-	if err := p.Stop(ctx); err != nil {
-		return err
-	}
-
 	if err := m.pluginRegistry.Remove(ctx, p.ID); err != nil {
 		return err
 	}
+	m.untrackHealth(p.ID)
 
 	m.log.Debug("Plugin unregistered", "pluginId", p.ID)
 	return nil
 }
 
+// drainAndStop waits for the in-flight requests of an unregistered plugin to complete and stops its backend plugin process.
+func (m *PluginManager) drainAndStop(ctx context.Context, p *plugins.Plugin) error {
+	m.requests.wait(ctx, p, drainTimeout)
+
+	m.log.Debug("Stopping plugin process", "pluginId", p.ID)
+	return p.Stop(ctx)
+}
+
 // start starts a backend plugin process
 func (m *PluginManager) start(ctx context.Context, p *plugins.Plugin) error {
 	if !p.IsManaged() || !p.Backend || p.SignatureError != nil {
@@ -186,57 +215,15 @@ func (m *PluginManager) start(ctx context.Context, p *plugins.Plugin) error {
 		return nil
 	}
 
-	if err := startPluginAndRestartKilledProcesses(ctx, p); err != nil {
-		return err
-	}
-
-	p.Logger().Debug("Successfully started backend plugin process")
-
-	return nil
-}
-
-func startPluginAndRestartKilledProcesses(ctx context.Context, p *plugins.Plugin) error {
 	if err := p.Start(ctx); err != nil {
 		return err
 	}
 
-	go func(ctx context.Context, p *plugins.Plugin) {
-		if err := restartKilledProcess(ctx, p); err != nil {
-			p.Logger().Error("Attempt to restart killed plugin process failed", "error", err)
-		}
-	}(ctx, p)
-
-	return nil
-}
-
-func restartKilledProcess(ctx context.Context, p *plugins.Plugin) error {
-	ticker := time.NewTicker(time.Second * 1)
+	go m.supervise(ctx, p, m.trackHealth(p))
 
-	for {
-		select {
-		case <-ctx.Done():
-			if err := ctx.Err(); err != nil && !errors.Is(err, context.Canceled) {
-				return err
-			}
-			return nil
-		case <-ticker.C:
-			if p.IsDecommissioned() {
-				p.Logger().Debug("Plugin decommissioned")
-				return nil
-			}
-
-			if !p.Exited() {
-				continue
-			}
+	p.Logger().Debug("Successfully started backend plugin process")
 
-			p.Logger().Debug("Restarting plugin")
-			if err := p.Start(ctx); err != nil {
-				p.Logger().Error("Failed to restart plugin", "error", err)
-				continue
-			}
-			p.Logger().Debug("Plugin restarted")
-		}
-	}
+	return nil
 }
 
 // shutdown stops all backend plugin processes
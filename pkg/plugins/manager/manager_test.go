@@ -2,6 +2,7 @@ package manager
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"sync"
 	"testing"
@@ -188,6 +189,21 @@ func TestPluginManager_Installer(t *testing.T) {
 			}, err)
 		})
 
+		t.Run("Failed updates keep the installed version", func(t *testing.T) {
+			failing := &fakePluginInstaller{installErr: errors.New("download failed")}
+			pm.pluginInstaller = failing
+			defer func() { pm.pluginInstaller = i }()
+
+			err := pm.Add(context.Background(), testPluginID, "1.1.0")
+			require.Error(t, err)
+
+			assert.Equal(t, 1, failing.installCount)
+			assert.Equal(t, 0, failing.uninstallCount)
+			assert.Equal(t, 0, pc.stopCount)
+			_, exists := pm.Plugin(context.Background(), testPluginID)
+			assert.True(t, exists)
+		})
+
 		t.Run("Update", func(t *testing.T) {
 			p, pc := createPlugin(t, testPluginID, "1.2.0", plugins.External, true, true)
 
@@ -603,11 +619,22 @@ func verifyNoPluginErrors(t *testing.T, pm *PluginManager) {
 type fakePluginInstaller struct {
 	installCount   int
 	uninstallCount int
+	installErr     error
 }
 
 func (f *fakePluginInstaller) Install(_ context.Context, _, _, _, _, _ string) error {
 	f.installCount++
-	return nil
+	return f.installErr
+}
+
+func (f *fakePluginInstaller) InstallFromRepository(_ context.Context, _, _, _, _ string) error {
+	f.installCount++
+	return f.installErr
+}
+
+func (f *fakePluginInstaller) InstallArchive(_ context.Context, _, _, _, _, _ string) error {
+	f.installCount++
+	return f.installErr
 }
 
 func (f *fakePluginInstaller) Uninstall(_ context.Context, _ string) error {
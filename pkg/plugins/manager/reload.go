@@ -0,0 +1,300 @@
+package manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// drainTimeout is how long unloading a plugin waits for its in-flight requests to complete
+var drainTimeout = 30 * time.Second
+
+// Reload rescans the external plugin sources. Added plugins are loaded, plugins whose directory changed are
+// loaded again and replace the running version, and removed plugins are unloaded. The plugin process of a
+// replaced or removed plugin is stopped once its in-flight requests have completed. A changed plugin which
+// cannot be loaded again is reported as failed and its running version is kept.
+func (m *PluginManager) Reload(ctx context.Context) (plugins.ReloadResult, error) {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	result := plugins.ReloadResult{Added: []string{}, Updated: []string{}, Removed: []string{}, Failed: []plugins.ReloadError{}}
+
+	// plugins which are unchanged are ignored by the loader, the others are loaded again
+	ignore := make(map[string]struct{})
+	var stale []*plugins.Plugin
+	for _, p := range m.pluginRegistry.Plugins(ctx) {
+		if !p.IsExternalPlugin() {
+			ignore[p.ID] = struct{}{}
+			continue
+		}
+
+		root := rootPlugin(p)
+		fingerprint, err := pluginFingerprint(root.PluginDir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return result, err
+		}
+		if err == nil && fingerprint == m.fingerprints[root.ID] {
+			ignore[p.ID] = struct{}{}
+			continue
+		}
+		stale = append(stale, p)
+	}
+
+	loadedPlugins, err := m.pluginLoader.Load(ctx, plugins.External, m.externalPluginPaths(), ignore)
+	if err != nil {
+		m.log.Error("Could not reload plugins", "err", err)
+		return result, err
+	}
+
+	loadedByID := make(map[string]*plugins.Plugin, len(loadedPlugins))
+	for _, p := range loadedPlugins {
+		loadedByID[p.ID] = p
+	}
+
+	for _, old := range stale {
+		if _, loaded := loadedByID[old.ID]; !loaded {
+			// the plugin was changed rather than removed when its directory is still there
+			if _, err := os.Stat(old.PluginDir); err == nil {
+				reloadErr := m.loadError(old.ID)
+				m.log.Error("Could not reload plugin, keeping the running version", "pluginId", old.ID,
+					"version", old.Info.Version, "err", reloadErr)
+				result.Failed = append(result.Failed, plugins.ReloadError{PluginID: old.ID, Error: reloadErr})
+				continue
+			}
+		}
+
+		if err := m.unregister(ctx, old); err != nil {
+			return result, err
+		}
+		delete(m.fingerprints, old.ID)
+
+		if p, exists := loadedByID[old.ID]; exists {
+			delete(loadedByID, old.ID)
+			if err := m.registerAndStart(context.Background(), p); err != nil {
+				m.log.Error("Could not start plugin", "pluginId", p.ID, "err", err)
+			}
+			m.recordFingerprint(p)
+			result.Updated = append(result.Updated, p.ID)
+			m.log.Info("Plugin reloaded", "pluginId", p.ID, "version", p.Info.Version, "previousVersion", old.Info.Version)
+		} else {
+			result.Removed = append(result.Removed, old.ID)
+			m.log.Info("Plugin unloaded", "pluginId", old.ID)
+		}
+
+		// the previous version keeps serving its in-flight requests while the new version serves the new requests
+		go func(old *plugins.Plugin) {
+			if err := m.drainAndStop(context.Background(), old); err != nil {
+				old.Logger().Error("Failed to stop plugin", "error", err)
+			}
+		}(old)
+	}
+
+	for _, p := range loadedPlugins {
+		if _, added := loadedByID[p.ID]; !added {
+			continue
+		}
+		if err := m.registerAndStart(context.Background(), p); err != nil {
+			m.log.Error("Could not start plugin", "pluginId", p.ID, "err", err)
+		}
+		m.recordFingerprint(p)
+		result.Added = append(result.Added, p.ID)
+	}
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Updated)
+	sort.Strings(result.Removed)
+	sort.Slice(result.Failed, func(i, j int) bool {
+		return result.Failed[i].PluginID < result.Failed[j].PluginID
+	})
+	return result, nil
+}
+
+// loadError returns why a plugin could not be loaded, the loader only keeps the signature errors of the plugins.
+func (m *PluginManager) loadError(pluginID string) string {
+	if errResolver, ok := m.pluginLoader.(plugins.ErrorResolver); ok {
+		for _, err := range errResolver.PluginErrors() {
+			if err.PluginID == pluginID {
+				return fmt.Sprintf("plugin could not be loaded: %s", err.ErrorCode)
+			}
+		}
+	}
+	return "plugin could not be loaded, see the server logs for details"
+}
+
+// watch reloads the plugins whenever the content of the external plugin directories changes, until ctx is done.
+func (m *PluginManager) watch(ctx context.Context, interval time.Duration) {
+	m.log.Info("Watching plugin directories for changes", "paths", m.externalPluginPaths(), "interval", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := m.sourcesFingerprint()
+		for {
+			select {
+			case <-ticker.C:
+				current := m.sourcesFingerprint()
+				if current == last {
+					continue
+				}
+				last = current
+
+				result, err := m.Reload(ctx)
+				if err != nil {
+					m.log.Error("Failed to reload plugins", "err", err)
+					continue
+				}
+				m.log.Info("Plugins reloaded", "added", result.Added, "updated", result.Updated, "removed", result.Removed,
+					"failed", len(result.Failed))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (m *PluginManager) externalPluginPaths() []string {
+	var paths []string
+	for _, ps := range m.pluginSources {
+		if ps.Class != plugins.External {
+			continue
+		}
+		for _, p := range ps.Paths {
+			if p != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
+
+// sourcesFingerprint returns a fingerprint of the content of the external plugin directories.
+func (m *PluginManager) sourcesFingerprint() string {
+	h := sha256.New()
+	for _, path := range m.externalPluginPaths() {
+		fingerprint, err := pluginFingerprint(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			m.log.Warn("Could not read plugin directory", "path", path, "err", err)
+		}
+		_, _ = fmt.Fprintf(h, "%s=%s\n", path, fingerprint)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordFingerprint records the fingerprint of the directory of a loaded external plugin, so that
+// reloading the plugins can tell whether it changed.
+func (m *PluginManager) recordFingerprint(p *plugins.Plugin) {
+	if !p.IsExternalPlugin() || p.Parent != nil {
+		return
+	}
+
+	fingerprint, err := pluginFingerprint(p.PluginDir)
+	if err != nil {
+		m.log.Warn("Could not read plugin directory", "pluginId", p.ID, "path", p.PluginDir, "err", err)
+		return
+	}
+	m.fingerprints[p.ID] = fingerprint
+}
+
+// rootPlugin returns the top-level plugin of a nested plugin, nested plugins are reloaded along with it.
+func rootPlugin(p *plugins.Plugin) *plugins.Plugin {
+	for p.Parent != nil {
+		p = p.Parent
+	}
+	return p
+}
+
+// pluginFingerprint hashes the paths, sizes and modification times of the files in a directory.
+func pluginFingerprint(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			// follow symbolic links, like the plugin finder does
+			if info, err = os.Stat(path); err != nil {
+				return err
+			}
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(h, "%s:%d:%d\n", rel, info.Size(), info.ModTime().UnixNano())
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// requestTracker counts the in-flight requests of each plugin.
+type requestTracker struct {
+	mu       sync.Mutex
+	requests map[*plugins.Plugin]*sync.WaitGroup
+}
+
+func newRequestTracker() *requestTracker {
+	return &requestTracker{
+		requests: make(map[*plugins.Plugin]*sync.WaitGroup),
+	}
+}
+
+// start tracks a request to a plugin, the returned function must be called when the request completes.
+func (t *requestTracker) start(p *plugins.Plugin) func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	wg, exists := t.requests[p]
+	if !exists {
+		wg = &sync.WaitGroup{}
+		t.requests[p] = wg
+	}
+	wg.Add(1)
+	return wg.Done
+}
+
+// wait waits for the in-flight requests of an unregistered plugin to complete, for at most timeout.
+func (t *requestTracker) wait(ctx context.Context, p *plugins.Plugin, timeout time.Duration) {
+	t.mu.Lock()
+	wg, exists := t.requests[p]
+	delete(t.requests, p)
+	t.mu.Unlock()
+
+	if !exists {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		p.Logger().Warn("Stopping plugin with in-flight requests", "timeout", timeout)
+	case <-ctx.Done():
+	}
+}
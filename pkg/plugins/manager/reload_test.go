@@ -0,0 +1,206 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager/registry"
+)
+
+func TestPluginManager_Reload(t *testing.T) {
+	setup := func(t *testing.T) (*PluginManager, *reloadLoader, string) {
+		t.Helper()
+
+		pluginsDir := t.TempDir()
+		loader := &reloadLoader{}
+		pm := New(&plugins.Cfg{}, registry.NewInMemory(), []PluginSource{
+			{Class: plugins.External, Paths: []string{pluginsDir}},
+		}, loader)
+		return pm, loader, pluginsDir
+	}
+
+	writePlugin := func(t *testing.T, dir, version string) {
+		t.Helper()
+
+		require.NoError(t, os.MkdirAll(dir, 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(`{"id":"`+testPluginID+`","version":"`+version+`"}`), 0600))
+	}
+
+	newPlugin := func(t *testing.T, dir, version string) (*plugins.Plugin, *fakePluginClient) {
+		t.Helper()
+
+		return createPlugin(t, testPluginID, version, plugins.External, true, true, func(p *plugins.Plugin) {
+			p.PluginDir = dir
+		})
+	}
+
+	t.Run("Added plugins are loaded", func(t *testing.T) {
+		pm, loader, pluginsDir := setup(t)
+		dir := filepath.Join(pluginsDir, testPluginID)
+		writePlugin(t, dir, "1.0.0")
+		p, pc := newPlugin(t, dir, "1.0.0")
+		loader.plugins = []*plugins.Plugin{p}
+
+		result, err := pm.Reload(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{testPluginID}, result.Added)
+		require.Empty(t, result.Updated)
+		require.Empty(t, result.Removed)
+		require.Equal(t, 1, pc.startCount)
+		require.Equal(t, []string{pluginsDir}, loader.loadedPaths)
+
+		_, exists := pm.Plugin(context.Background(), testPluginID)
+		require.True(t, exists)
+	})
+
+	t.Run("Unchanged plugins are not reloaded", func(t *testing.T) {
+		pm, loader, pluginsDir := setup(t)
+		dir := filepath.Join(pluginsDir, testPluginID)
+		writePlugin(t, dir, "1.0.0")
+		p, pc := newPlugin(t, dir, "1.0.0")
+		loader.plugins = []*plugins.Plugin{p}
+		require.NoError(t, pm.loadPlugins(context.Background(), plugins.External, pluginsDir))
+
+		result, err := pm.Reload(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, result.Added)
+		require.Empty(t, result.Updated)
+		require.Empty(t, result.Removed)
+		require.Contains(t, loader.ignored, testPluginID)
+		require.Equal(t, 1, pc.startCount)
+		require.False(t, pc.IsDecommissioned())
+	})
+
+	t.Run("Changed plugins replace the running version once its requests complete", func(t *testing.T) {
+		pm, loader, pluginsDir := setup(t)
+		dir := filepath.Join(pluginsDir, testPluginID)
+		writePlugin(t, dir, "1.0.0")
+		oldPlugin, oldClient := newPlugin(t, dir, "1.0.0")
+		loader.plugins = []*plugins.Plugin{oldPlugin}
+		require.NoError(t, pm.loadPlugins(context.Background(), plugins.External, pluginsDir))
+
+		_, release, exists := pm.acquire(context.Background(), testPluginID)
+		require.True(t, exists)
+
+		writePlugin(t, dir, "2.0.10")
+		newPlugin, newClient := newPlugin(t, dir, "2.0.10")
+		loader.plugins = []*plugins.Plugin{newPlugin}
+
+		result, err := pm.Reload(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{testPluginID}, result.Updated)
+		require.Equal(t, 1, newClient.startCount)
+		require.True(t, oldClient.IsDecommissioned())
+
+		p, exists := pm.Plugin(context.Background(), testPluginID)
+		require.True(t, exists)
+		require.Equal(t, "2.0.10", p.Info.Version)
+
+		// the previous version is stopped once the in-flight request completes
+		require.Eventually(t, func() bool {
+			pm.requests.mu.Lock()
+			defer pm.requests.mu.Unlock()
+			_, tracked := pm.requests.requests[oldPlugin]
+			return !tracked
+		}, time.Second, 10*time.Millisecond, "the previous version should be waiting for its requests")
+		require.False(t, oldClient.Exited())
+		release()
+		require.Eventually(t, oldClient.Exited, time.Second, 10*time.Millisecond)
+		require.False(t, newClient.Exited())
+	})
+
+	t.Run("Changed plugins which cannot be loaded keep the running version", func(t *testing.T) {
+		pm, loader, pluginsDir := setup(t)
+		dir := filepath.Join(pluginsDir, testPluginID)
+		writePlugin(t, dir, "1.0.0")
+		p, pc := newPlugin(t, dir, "1.0.0")
+		loader.plugins = []*plugins.Plugin{p}
+		require.NoError(t, pm.loadPlugins(context.Background(), plugins.External, pluginsDir))
+
+		writePlugin(t, dir, "2.0.0")
+		loader.plugins = nil
+		loader.errs = []*plugins.Error{{PluginID: testPluginID, ErrorCode: "signatureModified"}}
+
+		result, err := pm.Reload(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, result.Updated)
+		require.Empty(t, result.Removed)
+		require.Equal(t, []plugins.ReloadError{{PluginID: testPluginID, Error: "plugin could not be loaded: signatureModified"}}, result.Failed)
+		require.False(t, pc.IsDecommissioned())
+
+		running, exists := pm.Plugin(context.Background(), testPluginID)
+		require.True(t, exists)
+		require.Equal(t, "1.0.0", running.Info.Version)
+	})
+
+	t.Run("Removed plugins are unloaded", func(t *testing.T) {
+		pm, loader, pluginsDir := setup(t)
+		dir := filepath.Join(pluginsDir, testPluginID)
+		writePlugin(t, dir, "1.0.0")
+		p, pc := newPlugin(t, dir, "1.0.0")
+		loader.plugins = []*plugins.Plugin{p}
+		require.NoError(t, pm.loadPlugins(context.Background(), plugins.External, pluginsDir))
+
+		require.NoError(t, os.RemoveAll(dir))
+		loader.plugins = nil
+
+		result, err := pm.Reload(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{testPluginID}, result.Removed)
+		require.Eventually(t, pc.Exited, time.Second, 10*time.Millisecond)
+
+		_, exists := pm.Plugin(context.Background(), testPluginID)
+		require.False(t, exists)
+	})
+}
+
+func TestPluginFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(`{}`), 0600))
+
+	before, err := pluginFingerprint(dir)
+	require.NoError(t, err)
+	unchanged, err := pluginFingerprint(dir)
+	require.NoError(t, err)
+	require.Equal(t, before, unchanged)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "module.js"), []byte(`define([])`), 0600))
+	after, err := pluginFingerprint(dir)
+	require.NoError(t, err)
+	require.NotEqual(t, before, after)
+
+	_, err = pluginFingerprint(filepath.Join(dir, "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// reloadLoader returns its plugins except for the ignored ones, like the plugin loader does.
+type reloadLoader struct {
+	plugins []*plugins.Plugin
+	errs    []*plugins.Error
+
+	loadedPaths []string
+	ignored     map[string]struct{}
+}
+
+func (l *reloadLoader) PluginErrors() []*plugins.Error {
+	return l.errs
+}
+
+func (l *reloadLoader) Load(_ context.Context, _ plugins.Class, paths []string, ignore map[string]struct{}) ([]*plugins.Plugin, error) {
+	l.loadedPaths = paths
+	l.ignored = ignore
+
+	var res []*plugins.Plugin
+	for _, p := range l.plugins {
+		if _, ignored := ignore[p.ID]; !ignored {
+			res = append(res, p)
+		}
+	}
+	return res, nil
+}
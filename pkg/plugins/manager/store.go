@@ -2,10 +2,14 @@ package manager
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager/installer"
 )
 
 func (m *PluginManager) Plugin(ctx context.Context, pluginID string) (plugins.PluginDTO, bool) {
@@ -69,11 +73,15 @@ func (m *PluginManager) registeredPlugins(ctx context.Context) map[string]struct
 }
 
 func (m *PluginManager) Add(ctx context.Context, pluginID, version string) error {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
 	var pluginZipURL string
 
-	if plugin, exists := m.plugin(ctx, pluginID); exists {
-		if !plugin.IsExternalPlugin() {
-			return plugins.ErrInstallCorePlugin
+	plugin, exists := m.plugin(ctx, pluginID)
+	if exists {
+		if err := m.checkReplaceable(plugin); err != nil {
+			return err
 		}
 
 		if plugin.Info.Version == version {
@@ -84,34 +92,129 @@ func (m *PluginManager) Add(ctx context.Context, pluginID, version string) error
 		}
 
 		// get plugin update information to confirm if upgrading is possible
-		updateInfo, err := m.pluginInstaller.GetUpdateInfo(ctx, pluginID, version, grafanaComURL)
-		if err != nil {
-			return err
+		if m.cfg.PrivateRepository == "" {
+			updateInfo, err := m.pluginInstaller.GetUpdateInfo(ctx, pluginID, version, grafanaComURL)
+			if err != nil {
+				return err
+			}
+
+			pluginZipURL = updateInfo.PluginZipURL
 		}
+	}
 
-		pluginZipURL = updateInfo.PluginZipURL
+	// the installer only replaces the files of the existing installation once the new version is extracted
+	var err error
+	if m.cfg.PrivateRepository != "" {
+		err = m.pluginInstaller.InstallFromRepository(ctx, pluginID, version, m.cfg.PluginsPath, m.cfg.PrivateRepository)
+	} else {
+		err = m.pluginInstaller.Install(ctx, pluginID, version, m.cfg.PluginsPath, pluginZipURL, grafanaComURL)
+	}
+	if err != nil {
+		return err
+	}
 
-		// remove existing installation of plugin
-		err = m.Remove(ctx, plugin.ID)
-		if err != nil {
+	if exists {
+		if err := m.replace(ctx, plugin); err != nil {
 			return err
 		}
 	}
 
-	err := m.pluginInstaller.Install(ctx, pluginID, version, m.cfg.PluginsPath, pluginZipURL, grafanaComURL)
+	err = m.loadPlugins(context.Background(), plugins.External, m.cfg.PluginsPath)
 	if err != nil {
 		return err
 	}
 
-	err = m.loadPlugins(context.Background(), plugins.External, m.cfg.PluginsPath)
+	return nil
+}
+
+func (m *PluginManager) AddFromArchive(ctx context.Context, pluginID string, archive io.Reader, checksum string) error {
+	if err := installer.ValidatePluginID(pluginID); err != nil {
+		return err
+	}
+
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	tmpFile, err := os.CreateTemp("", "*.zip")
+	if err != nil {
+		return fmt.Errorf("%v: %w", "failed to create temporary file", err)
+	}
+	defer func() {
+		if err := os.Remove(tmpFile.Name()); err != nil {
+			m.log.Warn("Failed to remove temporary file", "file", tmpFile.Name(), "err", err)
+		}
+	}()
+	if _, err := io.Copy(tmpFile, archive); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("%v: %w", "failed to write plugin archive", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("%v: %w", "failed to close tmp file", err)
+	}
+
+	plugin, exists := m.plugin(ctx, pluginID)
+	if exists {
+		if err := m.checkReplaceable(plugin); err != nil {
+			return err
+		}
+	}
+
+	// the checksum is verified and the archive extracted before the existing installation is replaced
+	err = m.pluginInstaller.InstallArchive(ctx, pluginID, tmpFile.Name(), checksum, m.cfg.PluginsPath, m.cfg.PrivateRepository)
 	if err != nil {
 		return err
 	}
 
+	if exists {
+		if err := m.replace(ctx, plugin); err != nil {
+			return err
+		}
+	}
+
+	return m.loadPlugins(context.Background(), plugins.External, m.cfg.PluginsPath)
+}
+
+// checkReplaceable returns an error when an installed plugin cannot be replaced by another version.
+func (m *PluginManager) checkReplaceable(plugin *plugins.Plugin) error {
+	if !plugin.IsExternalPlugin() {
+		return plugins.ErrInstallCorePlugin
+	}
+	if !m.inPluginsPath(plugin) {
+		return plugins.ErrUninstallOutsideOfPluginDir
+	}
 	return nil
 }
 
+// replace unloads a plugin replaced by a newly installed version, removing its files unless the new version
+// was installed in the same directory.
+func (m *PluginManager) replace(ctx context.Context, plugin *plugins.Plugin) error {
+	if err := m.unregisterAndStop(ctx, plugin); err != nil {
+		return err
+	}
+	delete(m.fingerprints, plugin.ID)
+
+	installDir, err := filepath.Abs(filepath.Join(m.cfg.PluginsPath, plugin.ID))
+	if err != nil {
+		return err
+	}
+	pluginDir, err := filepath.Abs(plugin.PluginDir)
+	if err != nil {
+		return err
+	}
+	if path, err := filepath.Rel(installDir, pluginDir); err == nil && path != ".." && !strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	return m.pluginInstaller.Uninstall(ctx, plugin.PluginDir)
+}
+
 func (m *PluginManager) Remove(ctx context.Context, pluginID string) error {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	return m.remove(ctx, pluginID)
+}
+
+func (m *PluginManager) remove(ctx context.Context, pluginID string) error {
 	plugin, exists := m.plugin(ctx, pluginID)
 	if !exists {
 		return plugins.ErrPluginNotInstalled
@@ -122,14 +225,20 @@ func (m *PluginManager) Remove(ctx context.Context, pluginID string) error {
 	}
 
 	// extra security check to ensure we only remove plugins that are located in the configured plugins directory
-	path, err := filepath.Rel(m.cfg.PluginsPath, plugin.PluginDir)
-	if err != nil || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+	if !m.inPluginsPath(plugin) {
 		return plugins.ErrUninstallOutsideOfPluginDir
 	}
 
 	if err := m.unregisterAndStop(ctx, plugin); err != nil {
 		return err
 	}
+	delete(m.fingerprints, plugin.ID)
 
 	return m.pluginInstaller.Uninstall(ctx, plugin.PluginDir)
 }
+
+// inPluginsPath returns true when a plugin is located in the configured plugins directory.
+func (m *PluginManager) inPluginsPath(plugin *plugins.Plugin) bool {
+	path, err := filepath.Rel(m.cfg.PluginsPath, plugin.PluginDir)
+	return err == nil && !strings.HasPrefix(path, ".."+string(filepath.Separator))
+}
@@ -18,6 +18,11 @@ var sqlIDAcceptList = map[string]struct{}{
 	"\"user\".\"id\"":  {}, // For Postgres
 	"`user`.`id`":      {}, // For MySQL and SQLite
 	"dashboard.uid":    {},
+	// aliases of the levels of the folder tree in searchstore.NestedFolderSelect
+	"f0.uid": {},
+	"f1.uid": {},
+	"f2.uid": {},
+	"f3.uid": {},
 }
 
 var (
@@ -215,6 +215,7 @@ func (n *notificationService) renderAndUploadImage(evalCtx *EvalContext, timeout
 		Width:           1000,
 		Height:          500,
 		ConcurrentLimit: setting.AlertingRenderLimit,
+		Priority:        rendering.PriorityAlerting,
 		Theme:           models.ThemeDark,
 	}
 
@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+)
+
+// Service records administrative and data-changing actions.
+type Service interface {
+	// Log records an event. Failing to record an event never fails the action that
+	// triggered it, errors are logged instead.
+	Log(ctx context.Context, event *Event)
+	Search(ctx context.Context, query *SearchEventsQuery) (*SearchEventsResult, error)
+	DeleteExpired(ctx context.Context, cmd *DeleteExpiredEventsCommand) error
+}
@@ -0,0 +1,39 @@
+package auditimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/admin/audit", func(entities routing.RouteRegister) {
+		entities.Get("/events", middleware.ReqGrafanaAdmin, routing.Wrap(s.searchHandler))
+	})
+}
+
+// searchHandler handles GET /api/admin/audit/events
+func (s *Service) searchHandler(c *models.ReqContext) response.Response {
+	query := audit.SearchEventsQuery{
+		OrgID:        c.QueryInt64("orgId"),
+		ActorID:      c.QueryInt64("actorId"),
+		Action:       c.Query("action"),
+		ResourceKind: c.Query("resourceKind"),
+		ResourceUID:  c.Query("resourceUid"),
+		From:         c.QueryInt64("from"),
+		To:           c.QueryInt64("to"),
+		Page:         c.QueryInt("page"),
+		Limit:        c.QueryInt("perpage"),
+	}
+
+	result, err := s.Search(c.Req.Context(), &query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search audit events", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
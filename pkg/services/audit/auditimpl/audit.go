@@ -0,0 +1,117 @@
+package auditimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// eventBufferSize is the number of events waiting to be exported to the sinks
+// before new events are dropped.
+const eventBufferSize = 1000
+
+type Service struct {
+	store         store
+	cfg           setting.AuditSettings
+	routeRegister routing.RouteRegister
+	sinks         []sink
+	events        chan *audit.Event
+	log           log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister) (*Service, error) {
+	s := &Service{
+		store:         &sqlStore{db: db},
+		cfg:           cfg.Audit,
+		routeRegister: routeRegister,
+		log:           log.New("audit"),
+	}
+
+	if !s.cfg.Enabled {
+		return s, nil
+	}
+
+	sinks, err := newSinks(s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.sinks = sinks
+	s.events = make(chan *audit.Event, eventBufferSize)
+
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *Service) Log(ctx context.Context, event *audit.Event) {
+	if !s.cfg.Enabled || event == nil {
+		return
+	}
+
+	if event.Created == 0 {
+		event.Created = time.Now().Unix()
+	}
+
+	if err := s.store.Insert(ctx, event); err != nil {
+		s.log.Error("Failed to store audit event", "action", event.Action, "resourceUid", event.ResourceUID, "error", err)
+	}
+
+	if len(s.sinks) == 0 {
+		return
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		s.log.Warn("Audit event buffer is full, event not exported", "action", event.Action, "resourceUid", event.ResourceUID)
+	}
+}
+
+func (s *Service) Search(ctx context.Context, query *audit.SearchEventsQuery) (*audit.SearchEventsResult, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) DeleteExpired(ctx context.Context, cmd *audit.DeleteExpiredEventsCommand) error {
+	if s.cfg.Retention <= 0 {
+		return nil
+	}
+
+	deleted, err := s.store.DeleteOlderThan(ctx, time.Now().Add(-s.cfg.Retention).Unix())
+	if err != nil {
+		return err
+	}
+	cmd.DeletedRows = deleted
+	return nil
+}
+
+// IsDisabled returns true when there are no sinks to export events to.
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled || len(s.sinks) == 0
+}
+
+// Run exports the recorded events to the configured sinks.
+func (s *Service) Run(ctx context.Context) error {
+	for {
+		select {
+		case event := <-s.events:
+			for _, sink := range s.sinks {
+				if err := sink.Write(ctx, event); err != nil {
+					s.log.Error("Failed to export audit event", "action", event.Action, "resourceUid", event.ResourceUID, "error", err)
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
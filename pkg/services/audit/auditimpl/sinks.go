@@ -0,0 +1,158 @@
+package auditimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	sinkFile = "file"
+	sinkLoki = "loki"
+)
+
+// sink exports audit events to a destination other than the database.
+type sink interface {
+	Write(ctx context.Context, event *audit.Event) error
+}
+
+func newSinks(cfg setting.AuditSettings) ([]sink, error) {
+	sinks := make([]sink, 0, len(cfg.Sinks))
+	for _, name := range cfg.Sinks {
+		switch name {
+		case sinkFile:
+			if cfg.FilePath == "" {
+				return nil, fmt.Errorf("audit file sink requires a file path")
+			}
+			sinks = append(sinks, &fileSink{path: cfg.FilePath})
+		case sinkLoki:
+			if cfg.LokiURL == "" {
+				return nil, fmt.Errorf("audit loki sink requires a loki url")
+			}
+			sinks = append(sinks, &lokiSink{
+				url:      strings.TrimSuffix(cfg.LokiURL, "/") + "/loki/api/v1/push",
+				tenantID: cfg.LokiTenantID,
+				user:     cfg.LokiUser,
+				password: cfg.LokiPassword,
+				client:   &http.Client{Timeout: 10 * time.Second},
+			})
+		default:
+			return nil, fmt.Errorf("unknown audit sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// fileSink appends events to a file, one JSON document per line.
+type fileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (s *fileSink) Write(_ context.Context, event *audit.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0750); err != nil {
+		return err
+	}
+
+	// We can ignore the gosec G304 warning on this one because `path` comes
+	// from the server configuration.
+	// nolint:gosec
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// lokiSink pushes events to a Loki instance as log lines labeled by action.
+type lokiSink struct {
+	url      string
+	tenantID string
+	user     string
+	password string
+	client   *http.Client
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (s *lokiSink) Write(ctx context.Context, event *audit.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(lokiPushRequest{
+		Streams: []lokiStream{
+			{
+				Stream: map[string]string{
+					"source":        "grafana",
+					"component":     "audit",
+					"action":        event.Action,
+					"resource_kind": event.ResourceKind,
+				},
+				Values: [][2]string{
+					{strconv.FormatInt(time.Unix(event.Created, 0).UnixNano(), 10), string(line)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", s.tenantID)
+	}
+	if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("loki push failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
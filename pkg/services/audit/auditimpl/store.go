@@ -0,0 +1,117 @@
+package auditimpl
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+type store interface {
+	Insert(ctx context.Context, event *audit.Event) error
+	Search(ctx context.Context, query *audit.SearchEventsQuery) (*audit.SearchEventsResult, error)
+	DeleteOlderThan(ctx context.Context, olderThan int64) (int64, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Insert(ctx context.Context, event *audit.Event) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(event)
+		return err
+	})
+}
+
+func (s *sqlStore) Search(ctx context.Context, query *audit.SearchEventsQuery) (*audit.SearchEventsResult, error) {
+	result := &audit.SearchEventsResult{
+		Events:  make([]*audit.Event, 0),
+		Page:    query.Page,
+		PerPage: query.Limit,
+	}
+
+	whereConditions := make([]string, 0)
+	whereParams := make([]interface{}, 0)
+
+	if query.OrgID > 0 {
+		whereConditions = append(whereConditions, "org_id = ?")
+		whereParams = append(whereParams, query.OrgID)
+	}
+
+	if query.ActorID > 0 {
+		whereConditions = append(whereConditions, "actor_id = ?")
+		whereParams = append(whereParams, query.ActorID)
+	}
+
+	if query.Action != "" {
+		whereConditions = append(whereConditions, "action = ?")
+		whereParams = append(whereParams, query.Action)
+	}
+
+	if query.ResourceKind != "" {
+		whereConditions = append(whereConditions, "resource_kind = ?")
+		whereParams = append(whereParams, query.ResourceKind)
+	}
+
+	if query.ResourceUID != "" {
+		whereConditions = append(whereConditions, "resource_uid = ?")
+		whereParams = append(whereParams, query.ResourceUID)
+	}
+
+	if query.From > 0 {
+		whereConditions = append(whereConditions, "created >= ?")
+		whereParams = append(whereParams, query.From)
+	}
+
+	if query.To > 0 {
+		whereConditions = append(whereConditions, "created <= ?")
+		whereParams = append(whereParams, query.To)
+	}
+
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		where := strings.Join(whereConditions, " AND ")
+
+		findSess := sess.Table("audit_event")
+		if where != "" {
+			findSess.Where(where, whereParams...)
+		}
+		if query.Limit > 0 {
+			findSess.Limit(query.Limit, query.Limit*(query.Page-1))
+		}
+		if err := findSess.Desc("created", "id").Find(&result.Events); err != nil {
+			return err
+		}
+
+		countSess := sess.Table("audit_event")
+		if where != "" {
+			countSess.Where(where, whereParams...)
+		}
+		count, err := countSess.Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *sqlStore) DeleteOlderThan(ctx context.Context, olderThan int64) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM audit_event WHERE created < ?", olderThan)
+		if err != nil {
+			return err
+		}
+
+		affected, err = res.RowsAffected()
+		return err
+	})
+
+	return affected, err
+}
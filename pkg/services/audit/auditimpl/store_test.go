@@ -0,0 +1,73 @@
+package auditimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationAuditEventsDataAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ss := sqlstore.InitTestDB(t)
+	auditStore := sqlStore{db: ss}
+	now := time.Now().Unix()
+
+	events := []*audit.Event{
+		{OrgID: 1, ActorID: 10, ActorLogin: "admin", Action: audit.ActionDashboardCreate, ResourceKind: audit.KindDashboard, ResourceUID: "dash-1", After: `{"title":"A"}`, Created: now - 100},
+		{OrgID: 1, ActorID: 10, ActorLogin: "admin", Action: audit.ActionDashboardUpdate, ResourceKind: audit.KindDashboard, ResourceUID: "dash-1", Created: now - 50},
+		{OrgID: 1, ActorID: 11, ActorLogin: "editor", Action: audit.ActionDatasourceDelete, ResourceKind: audit.KindDatasource, ResourceUID: "ds-1", Created: now - 10},
+		{OrgID: 2, ActorID: 10, ActorLogin: "admin", Action: audit.ActionDashboardDelete, ResourceKind: audit.KindDashboard, ResourceUID: "dash-2", Created: now - 200*24*60*60},
+	}
+	for _, event := range events {
+		require.NoError(t, auditStore.Insert(context.Background(), event))
+		require.NotZero(t, event.ID)
+	}
+
+	t.Run("Search should return events newest first", func(t *testing.T) {
+		result, err := auditStore.Search(context.Background(), &audit.SearchEventsQuery{OrgID: 1, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.Events, 3)
+		require.Equal(t, "ds-1", result.Events[0].ResourceUID)
+		require.Equal(t, `{"title":"A"}`, result.Events[2].After)
+	})
+
+	t.Run("Search should filter by resource", func(t *testing.T) {
+		result, err := auditStore.Search(context.Background(), &audit.SearchEventsQuery{ResourceKind: audit.KindDashboard, ResourceUID: "dash-1", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), result.TotalCount)
+	})
+
+	t.Run("Search should filter by actor and time range", func(t *testing.T) {
+		result, err := auditStore.Search(context.Background(), &audit.SearchEventsQuery{ActorID: 10, From: now - 60, To: now, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalCount)
+		require.Equal(t, audit.ActionDashboardUpdate, result.Events[0].Action)
+	})
+
+	t.Run("Search should paginate", func(t *testing.T) {
+		result, err := auditStore.Search(context.Background(), &audit.SearchEventsQuery{Page: 2, Limit: 3})
+		require.NoError(t, err)
+		require.Equal(t, int64(4), result.TotalCount)
+		require.Len(t, result.Events, 1)
+		require.Equal(t, "dash-2", result.Events[0].ResourceUID)
+	})
+
+	t.Run("DeleteOlderThan should remove expired events", func(t *testing.T) {
+		deleted, err := auditStore.DeleteOlderThan(context.Background(), now-90*24*60*60)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		result, err := auditStore.Search(context.Background(), &audit.SearchEventsQuery{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.TotalCount)
+	})
+}
@@ -0,0 +1,29 @@
+package audittest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/audit"
+)
+
+type FakeAuditService struct {
+	Events         []*audit.Event
+	ExpectedResult *audit.SearchEventsResult
+	ExpectedError  error
+}
+
+func NewFakeAuditService() *FakeAuditService {
+	return &FakeAuditService{}
+}
+
+func (f *FakeAuditService) Log(ctx context.Context, event *audit.Event) {
+	f.Events = append(f.Events, event)
+}
+
+func (f *FakeAuditService) Search(ctx context.Context, query *audit.SearchEventsQuery) (*audit.SearchEventsResult, error) {
+	return f.ExpectedResult, f.ExpectedError
+}
+
+func (f *FakeAuditService) DeleteExpired(ctx context.Context, cmd *audit.DeleteExpiredEventsCommand) error {
+	return f.ExpectedError
+}
@@ -0,0 +1,236 @@
+package audit
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// maxStateLength is the maximum length of the stored before and after summaries
+const maxStateLength = 4096
+
+// truncatedKey is set to true in the summaries of the values too large to be stored
+const truncatedKey = "truncated"
+
+// Actions recorded in the audit log
+const (
+	ActionDashboardCreate            = "dashboards:create"
+	ActionDashboardUpdate            = "dashboards:write"
+	ActionDashboardDelete            = "dashboards:delete"
+	ActionDashboardRestore           = "dashboards:restore"
+	ActionDashboardPermissionsUpdate = "dashboards.permissions:write"
+	ActionFolderPermissionsUpdate    = "folders.permissions:write"
+	ActionDatasourceCreate           = "datasources:create"
+	ActionDatasourceUpdate           = "datasources:write"
+	ActionDatasourceDelete           = "datasources:delete"
+	ActionAlertRuleGroupUpdate       = "alert.rules:write"
+	ActionAlertRuleDelete            = "alert.rules:delete"
+	ActionSessionRevoke              = "users.authtoken:delete"
+	ActionSessionsBulkRevoke         = "sessions:delete"
+)
+
+// Kinds of resources recorded in the audit log
+const (
+	KindDashboard      = "dashboard"
+	KindFolder         = "folder"
+	KindDatasource     = "datasource"
+	KindAlertRule      = "alert-rule"
+	KindAlertRuleGroup = "alert-rule-group"
+	KindUser           = "user"
+)
+
+// Event is a single recorded action
+type Event struct {
+	ID           int64  `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID        int64  `xorm:"org_id" json:"orgId"`
+	ActorID      int64  `xorm:"actor_id" json:"actorId"`
+	ActorLogin   string `xorm:"actor_login" json:"actorLogin"`
+	Action       string `xorm:"action" json:"action"`
+	ResourceKind string `xorm:"resource_kind" json:"resourceKind"`
+	ResourceUID  string `xorm:"resource_uid" json:"resourceUid"`
+	Before       string `xorm:"before_state" json:"before,omitempty"`
+	After        string `xorm:"after_state" json:"after,omitempty"`
+	IP           string `xorm:"ip" json:"ip"`
+	Created      int64  `xorm:"'created'" json:"created"`
+}
+
+func (e Event) TableName() string {
+	return "audit_event"
+}
+
+// NewEvent creates an event for an action performed by the user of the request.
+func NewEvent(c *models.ReqContext, action, resourceKind, resourceUID string) *Event {
+	event := &Event{
+		Action:       action,
+		ResourceKind: resourceKind,
+		ResourceUID:  resourceUID,
+		Created:      time.Now().Unix(),
+	}
+
+	if c == nil {
+		return event
+	}
+
+	if c.SignedInUser != nil {
+		event.OrgID = c.OrgId
+		event.ActorID = c.UserId
+		event.ActorLogin = c.Login
+	}
+	if c.Context != nil && c.Req != nil {
+		event.IP = c.RemoteAddr()
+	}
+
+	return event
+}
+
+// WithChange sets the before and after summaries of the event, serialized as JSON
+// and truncated to a sensible size. Nil values are left empty. The summaries of JSON
+// values too large to be stored remain valid JSON, see truncateJSON.
+func (e *Event) WithChange(before, after interface{}) *Event {
+	e.Before = summarize(before)
+	e.After = summarize(after)
+	return e
+}
+
+func summarize(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+
+	var summary string
+	switch value := v.(type) {
+	case string:
+		summary = value
+	case []byte:
+		summary = string(value)
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return ""
+		}
+		summary = string(b)
+	}
+
+	if len(summary) <= maxStateLength {
+		return summary
+	}
+	if json.Valid([]byte(summary)) {
+		var value interface{}
+		if err := json.Unmarshal([]byte(summary), &value); err == nil {
+			return truncateJSON(value)
+		}
+	}
+
+	// text is cut on a rune boundary
+	n := maxStateLength
+	for n > 0 && !utf8.RuneStart(summary[n]) {
+		n--
+	}
+	return summary[:n]
+}
+
+// truncateJSON returns the summary of a JSON value too large to be stored, with "truncated" set to true.
+// The largest fields of the objects are dropped first, so that the identifying fields are kept, then the
+// trailing items of arrays. Arrays are summarized as {"truncated":true,"count":<length>,"items":[...]}.
+func truncateJSON(value interface{}) string {
+	var summary map[string]interface{}
+	var objects []map[string]interface{}
+	var items []interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		summary = copyObject(v)
+		objects = append(objects, summary)
+	case []interface{}:
+		items = make([]interface{}, len(v))
+		for i, item := range v {
+			if obj, ok := item.(map[string]interface{}); ok {
+				obj = copyObject(obj)
+				objects = append(objects, obj)
+				item = obj
+			}
+			items[i] = item
+		}
+		summary = map[string]interface{}{"count": len(v), "items": items}
+	default:
+		return `{"truncated":true}`
+	}
+	summary[truncatedKey] = true
+
+	type field struct {
+		obj  map[string]interface{}
+		key  string
+		size int
+	}
+	var fields []field
+	for i, obj := range objects {
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			// the summary of an object keeps its truncated field
+			if i == 0 && items == nil && k == truncatedKey {
+				continue
+			}
+			b, _ := json.Marshal(obj[k])
+			// "key":value,
+			fields = append(fields, field{obj: obj, key: k, size: len(k) + len(b) + 4})
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].size > fields[j].size })
+
+	b, _ := json.Marshal(summary)
+	for i, size := 0, len(b); len(b) > maxStateLength && i < len(fields); {
+		for ; i < len(fields) && size > maxStateLength; i++ {
+			delete(fields[i].obj, fields[i].key)
+			size -= fields[i].size
+		}
+		b, _ = json.Marshal(summary)
+		size = len(b)
+	}
+	for len(b) > maxStateLength && len(items) > 0 {
+		items = items[:len(items)-1]
+		summary["items"] = items
+		b, _ = json.Marshal(summary)
+	}
+	if len(b) > maxStateLength {
+		return `{"truncated":true}`
+	}
+	return string(b)
+}
+
+func copyObject(obj map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		c[k] = v
+	}
+	return c
+}
+
+type SearchEventsQuery struct {
+	OrgID        int64
+	ActorID      int64
+	Action       string
+	ResourceKind string
+	ResourceUID  string
+	// From and To are unix timestamps, zero means no bound
+	From  int64
+	To    int64
+	Page  int
+	Limit int
+}
+
+type SearchEventsResult struct {
+	TotalCount int64    `json:"totalCount"`
+	Events     []*Event `json:"events"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"perPage"`
+}
+
+type DeleteExpiredEventsCommand struct {
+	DeletedRows int64
+}
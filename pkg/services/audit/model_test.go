@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventWithChange(t *testing.T) {
+	t.Run("Should serialize values as JSON", func(t *testing.T) {
+		event := (&Event{}).WithChange(map[string]interface{}{"title": "old"}, "new")
+		require.Equal(t, `{"title":"old"}`, event.Before)
+		require.Equal(t, "new", event.After)
+	})
+
+	t.Run("Should leave nil values empty", func(t *testing.T) {
+		event := (&Event{}).WithChange(nil, []byte("after"))
+		require.Empty(t, event.Before)
+		require.Equal(t, "after", event.After)
+	})
+
+	t.Run("Should truncate large values", func(t *testing.T) {
+		event := (&Event{}).WithChange(strings.Repeat("a", maxStateLength+10), nil)
+		require.Len(t, event.Before, maxStateLength)
+	})
+
+	t.Run("Should truncate text on a rune boundary", func(t *testing.T) {
+		event := (&Event{}).WithChange("a"+strings.Repeat("é", maxStateLength), nil)
+		require.Len(t, event.Before, maxStateLength-1)
+		require.True(t, utf8.ValidString(event.Before))
+	})
+
+	t.Run("Should drop the largest fields of large objects", func(t *testing.T) {
+		event := (&Event{}).WithChange(map[string]interface{}{
+			"uid":   "abc",
+			"title": "Dashboard",
+			"data":  strings.Repeat("a", maxStateLength),
+		}, nil)
+		require.JSONEq(t, `{"uid":"abc","title":"Dashboard","truncated":true}`, event.Before)
+	})
+
+	t.Run("Should keep the items of large arrays without their largest fields", func(t *testing.T) {
+		rules := make([]map[string]interface{}, 0, 3)
+		for _, uid := range []string{"a", "b", "c"} {
+			rules = append(rules, map[string]interface{}{"uid": uid, "data": strings.Repeat("x", maxStateLength)})
+		}
+		event := (&Event{}).WithChange(nil, rules)
+		require.LessOrEqual(t, len(event.After), maxStateLength)
+		require.JSONEq(t, `{"truncated":true,"count":3,"items":[{"uid":"a"},{"uid":"b"},{"uid":"c"}]}`, event.After)
+	})
+
+	t.Run("Should drop the trailing items of arrays too large without their fields", func(t *testing.T) {
+		items := make([]string, maxStateLength)
+		for i := range items {
+			items[i] = "item"
+		}
+		event := (&Event{}).WithChange(items, nil)
+		require.LessOrEqual(t, len(event.Before), maxStateLength)
+
+		var summary struct {
+			Truncated bool     `json:"truncated"`
+			Count     int      `json:"count"`
+			Items     []string `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(event.Before), &summary))
+		require.True(t, summary.Truncated)
+		require.Equal(t, maxStateLength, summary.Count)
+		require.NotEmpty(t, summary.Items)
+		require.Less(t, len(summary.Items), maxStateLength)
+	})
+}
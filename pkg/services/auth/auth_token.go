@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"net"
 	"strings"
 	"time"
@@ -85,9 +86,20 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *models.Use
 		AuthTokenSeen: false,
 	}
 
-	err = s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		_, err = dbSession.Insert(&userAuthToken)
-		return err
+	// the token isn't created when the sessions exceeding the max concurrent sessions can't be revoked
+	err = s.SQLStore.InTransaction(ctx, func(ctx context.Context) error {
+		err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+			_, err := dbSession.Insert(&userAuthToken)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := s.enforceMaxConcurrentSessions(ctx, user.Id); err != nil {
+			return fmt.Errorf("failed to enforce max concurrent sessions: %w", err)
+		}
+		return nil
 	})
 
 	if err != nil {
@@ -127,8 +139,9 @@ func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken st
 
 	if model.RevokedAt > 0 {
 		return nil, &models.TokenRevokedError{
-			UserID:  model.UserId,
-			TokenID: model.Id,
+			UserID:                model.UserId,
+			TokenID:               model.Id,
+			MaxConcurrentSessions: int64(s.Cfg.MaxConcurrentSessions),
 		}
 	}
 
@@ -440,6 +453,199 @@ func (s *UserAuthTokenService) GetUserRevokedTokens(ctx context.Context, userId
 	return result, err
 }
 
+// SearchUserSessions returns the active sessions of all users matching the query,
+// most recently active first.
+func (s *UserAuthTokenService) SearchUserSessions(ctx context.Context, query *models.SearchUserSessionsQuery) error {
+	return s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		query.Result = models.SearchUserSessionsQueryResult{
+			Sessions: make([]*models.UserSession, 0),
+		}
+
+		whereConditions := []string{"t.created_at > ?", "t.rotated_at > ?", "t.revoked_at = 0"}
+		whereParams := []interface{}{s.createdAfterParam(), s.rotatedAfterParam()}
+
+		if query.UserId > 0 {
+			whereConditions = append(whereConditions, "t.user_id = ?")
+			whereParams = append(whereParams, query.UserId)
+		}
+
+		if query.OrgId > 0 {
+			whereConditions = append(whereConditions, "t.user_id IN (SELECT user_id FROM org_user WHERE org_id = ?)")
+			whereParams = append(whereParams, query.OrgId)
+		}
+
+		// seen_at is reset on rotation, so the last activity is the latest of the two
+		if query.SeenAfter > 0 {
+			whereConditions = append(whereConditions, "(t.seen_at > ? OR t.rotated_at > ?)")
+			whereParams = append(whereParams, query.SeenAfter, query.SeenAfter)
+		}
+
+		if query.SeenBefore > 0 {
+			whereConditions = append(whereConditions, "t.seen_at < ? AND t.rotated_at < ?")
+			whereParams = append(whereParams, query.SeenBefore, query.SeenBefore)
+		}
+
+		if query.Query != "" {
+			like := s.SQLStore.Dialect.LikeStr() + " ? ESCAPE '" + likeEscape + "'"
+			queryWithWildcards := "%" + escapeLike(query.Query) + "%"
+			whereConditions = append(whereConditions, "(u.login "+like+" OR u.email "+like+" OR u.name "+like+" OR t.client_ip "+like+" OR t.user_agent "+like+")")
+			whereParams = append(whereParams, queryWithWildcards, queryWithWildcards, queryWithWildcards, queryWithWildcards, queryWithWildcards)
+		}
+
+		userTable := s.SQLStore.Dialect.Quote("user")
+		where := strings.Join(whereConditions, " AND ")
+
+		sess := dbSession.Table("user_auth_token").Alias("t").
+			Join("INNER", userTable+" AS u", "u.id = t.user_id").
+			Where(where, whereParams...).
+			Select("t.*").
+			Desc("t.rotated_at", "t.id")
+		if query.Limit > 0 {
+			offset := query.Limit * (query.Page - 1)
+			sess.Limit(query.Limit, offset)
+		}
+
+		var tokens []*userAuthToken
+		if err := sess.Find(&tokens); err != nil {
+			return err
+		}
+
+		count, err := dbSession.Table("user_auth_token").Alias("t").
+			Join("INNER", userTable+" AS u", "u.id = t.user_id").
+			Where(where, whereParams...).
+			Count()
+		if err != nil {
+			return err
+		}
+		query.Result.TotalCount = count
+
+		if len(tokens) == 0 {
+			return nil
+		}
+
+		userIds := make([]int64, 0, len(tokens))
+		for _, token := range tokens {
+			userIds = append(userIds, token.UserId)
+		}
+
+		var users []*models.User
+		if err := dbSession.Table("user").In("id", userIds).Cols("id", "login", "email", "name").Find(&users); err != nil {
+			return err
+		}
+
+		usersById := make(map[int64]*models.User, len(users))
+		for _, user := range users {
+			usersById[user.Id] = user
+		}
+
+		for _, token := range tokens {
+			session := &models.UserSession{}
+			if err := token.toUserToken(&session.UserToken); err != nil {
+				return err
+			}
+			if user, ok := usersById[token.UserId]; ok {
+				session.Login = user.Login
+				session.Email = user.Email
+				session.Name = user.Name
+			}
+			query.Result.Sessions = append(query.Result.Sessions, session)
+		}
+
+		return nil
+	})
+}
+
+// RevokeUserSessions deletes every session matching the command filters and sets
+// the number of revoked sessions as the command result.
+func (s *UserAuthTokenService) RevokeUserSessions(ctx context.Context, cmd *models.RevokeUserSessionsCmd) error {
+	if cmd.UserId == 0 && cmd.OrgId == 0 && cmd.CreatedBefore == 0 {
+		return models.ErrRevokeSessionsFilterMissing
+	}
+
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		whereConditions := make([]string, 0)
+		whereParams := make([]interface{}, 0)
+
+		if cmd.UserId > 0 {
+			whereConditions = append(whereConditions, "user_id = ?")
+			whereParams = append(whereParams, cmd.UserId)
+		}
+
+		if cmd.OrgId > 0 {
+			whereConditions = append(whereConditions, "user_id IN (SELECT user_id FROM org_user WHERE org_id = ?)")
+			whereParams = append(whereParams, cmd.OrgId)
+		}
+
+		if cmd.CreatedBefore > 0 {
+			whereConditions = append(whereConditions, "created_at < ?")
+			whereParams = append(whereParams, cmd.CreatedBefore)
+		}
+
+		if cmd.ExcludeTokenId > 0 {
+			whereConditions = append(whereConditions, "id <> ?")
+			whereParams = append(whereParams, cmd.ExcludeTokenId)
+		}
+
+		params := []interface{}{"DELETE FROM user_auth_token WHERE " + strings.Join(whereConditions, " AND ")}
+		params = append(params, whereParams...)
+
+		res, err := dbSession.Exec(params...)
+		if err != nil {
+			return err
+		}
+
+		cmd.Result, err = res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		s.log.Debug("user sessions revoked", "userId", cmd.UserId, "orgId", cmd.OrgId, "createdBefore", cmd.CreatedBefore, "count", cmd.Result)
+
+		return nil
+	})
+}
+
+// enforceMaxConcurrentSessions soft revokes the least recently rotated sessions of
+// a user exceeding the configured maximum number of concurrent sessions.
+func (s *UserAuthTokenService) enforceMaxConcurrentSessions(ctx context.Context, userId int64) error {
+	maxSessions := s.Cfg.MaxConcurrentSessions
+	if maxSessions <= 0 {
+		return nil
+	}
+
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		var tokens []*userAuthToken
+		err := dbSession.Where("user_id = ? AND created_at > ? AND rotated_at > ? AND revoked_at = 0",
+			userId,
+			s.createdAfterParam(),
+			s.rotatedAfterParam()).
+			Desc("rotated_at", "id").
+			Find(&tokens)
+		if err != nil {
+			return err
+		}
+
+		if len(tokens) <= maxSessions {
+			return nil
+		}
+
+		tokenIds := make([]int64, 0, len(tokens)-maxSessions)
+		for _, token := range tokens[maxSessions:] {
+			tokenIds = append(tokenIds, token.Id)
+		}
+
+		affected, err := dbSession.Table("user_auth_token").In("id", tokenIds).
+			Update(map[string]interface{}{"revoked_at": getTime().Unix()})
+		if err != nil {
+			return err
+		}
+
+		s.log.Debug("revoked sessions exceeding max concurrent sessions", "userId", userId, "max", maxSessions, "count", affected)
+
+		return nil
+	})
+}
+
 func (s *UserAuthTokenService) createdAfterParam() int64 {
 	return getTime().Add(-s.Cfg.LoginMaxLifetime).Unix()
 }
@@ -452,3 +658,14 @@ func hashToken(token string) string {
 	hashBytes := sha256.Sum256([]byte(token + setting.SecretKey))
 	return hex.EncodeToString(hashBytes[:])
 }
+
+// likeEscape is the escape character of the wildcards of the search text of LIKE conditions. It isn't a backslash,
+// as MySQL and Postgres don't read backslashes the same way in string literals.
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// escapeLike escapes the wildcards of a search text, so that it matches literally in LIKE conditions.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -528,6 +528,119 @@ func TestUserAuthToken(t *testing.T) {
 	})
 }
 
+func TestUserAuthTokenSessions(t *testing.T) {
+	ctx := createTestContext(t)
+
+	now := time.Date(2018, 12, 13, 13, 45, 0, 0, time.UTC)
+	getTime = func() time.Time { return now }
+	defer func() { getTime = time.Now }()
+
+	alice, err := ctx.sqlstore.CreateUser(context.Background(), models.CreateUserCommand{Login: "alice", Email: "alice@example.com"})
+	require.NoError(t, err)
+	bob, err := ctx.sqlstore.CreateUser(context.Background(), models.CreateUserCommand{Login: "bob", Email: "bob@example.com"})
+	require.NoError(t, err)
+
+	createToken := func(t *testing.T, user *models.User, ip string) *models.UserToken {
+		t.Helper()
+		token, err := ctx.tokenService.CreateToken(context.Background(), user, net.ParseIP(ip), "some user agent")
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("Can search sessions across users", func(t *testing.T) {
+		createToken(t, alice, "192.168.10.11")
+		createToken(t, alice, "192.168.10.12")
+		createToken(t, bob, "10.0.0.1")
+
+		query := &models.SearchUserSessionsQuery{Page: 1, Limit: 10}
+		require.NoError(t, ctx.tokenService.SearchUserSessions(context.Background(), query))
+		require.Equal(t, int64(3), query.Result.TotalCount)
+		require.Len(t, query.Result.Sessions, 3)
+
+		query = &models.SearchUserSessionsQuery{OrgId: bob.OrgId, Page: 1, Limit: 10}
+		require.NoError(t, ctx.tokenService.SearchUserSessions(context.Background(), query))
+		require.Equal(t, int64(1), query.Result.TotalCount)
+		require.Equal(t, "bob", query.Result.Sessions[0].Login)
+		require.Equal(t, "10.0.0.1", query.Result.Sessions[0].ClientIp)
+
+		query = &models.SearchUserSessionsQuery{Query: "alice", Page: 1, Limit: 1}
+		require.NoError(t, ctx.tokenService.SearchUserSessions(context.Background(), query))
+		require.Equal(t, int64(2), query.Result.TotalCount)
+		require.Len(t, query.Result.Sessions, 1)
+		require.Equal(t, alice.Id, query.Result.Sessions[0].UserId)
+
+		// wildcards in the search text are matched literally
+		for _, wildcard := range []string{"%", "_", "192.168.10.1_"} {
+			query = &models.SearchUserSessionsQuery{Query: wildcard, Page: 1, Limit: 10}
+			require.NoError(t, ctx.tokenService.SearchUserSessions(context.Background(), query))
+			require.Equal(t, int64(0), query.Result.TotalCount, wildcard)
+		}
+	})
+
+	t.Run("Revoking sessions requires a filter", func(t *testing.T) {
+		err := ctx.tokenService.RevokeUserSessions(context.Background(), &models.RevokeUserSessionsCmd{})
+		require.ErrorIs(t, err, models.ErrRevokeSessionsFilterMissing)
+	})
+
+	t.Run("Can revoke sessions by user and keep excluded session", func(t *testing.T) {
+		tokens, err := ctx.tokenService.GetUserTokens(context.Background(), alice.Id)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+
+		cmd := &models.RevokeUserSessionsCmd{UserId: alice.Id, ExcludeTokenId: tokens[0].Id}
+		require.NoError(t, ctx.tokenService.RevokeUserSessions(context.Background(), cmd))
+		require.Equal(t, int64(1), cmd.Result)
+
+		tokens, err = ctx.tokenService.GetUserTokens(context.Background(), alice.Id)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+	})
+
+	t.Run("Can revoke sessions by age", func(t *testing.T) {
+		getTime = func() time.Time { return now.Add(time.Hour) }
+		newToken := createToken(t, bob, "10.0.0.2")
+
+		cmd := &models.RevokeUserSessionsCmd{CreatedBefore: now.Add(time.Minute).Unix()}
+		require.NoError(t, ctx.tokenService.RevokeUserSessions(context.Background(), cmd))
+		require.Equal(t, int64(2), cmd.Result)
+
+		tokens, err := ctx.tokenService.GetUserTokens(context.Background(), bob.Id)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		require.Equal(t, newToken.Id, tokens[0].Id)
+	})
+
+	t.Run("Enforces max concurrent sessions", func(t *testing.T) {
+		ctx.tokenService.Cfg.MaxConcurrentSessions = 2
+		defer func() { ctx.tokenService.Cfg.MaxConcurrentSessions = 0 }()
+
+		getTime = func() time.Time { return now.Add(2 * time.Hour) }
+		oldest := createToken(t, bob, "10.0.0.3")
+		getTime = func() time.Time { return now.Add(3 * time.Hour) }
+		createToken(t, bob, "10.0.0.4")
+
+		tokens, err := ctx.tokenService.GetUserTokens(context.Background(), bob.Id)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+
+		_, err = ctx.tokenService.LookupToken(context.Background(), oldest.UnhashedToken)
+		require.NoError(t, err)
+
+		revoked, err := ctx.tokenService.GetUserRevokedTokens(context.Background(), bob.Id)
+		require.NoError(t, err)
+		require.Len(t, revoked, 1)
+		require.Equal(t, "10.0.0.2", revoked[0].ClientIp)
+
+		getTime = func() time.Time { return now.Add(4 * time.Hour) }
+		createToken(t, bob, "10.0.0.5")
+
+		_, err = ctx.tokenService.LookupToken(context.Background(), oldest.UnhashedToken)
+		var revokedErr *models.TokenRevokedError
+		require.ErrorAs(t, err, &revokedErr)
+		require.Equal(t, int64(2), revokedErr.MaxConcurrentSessions)
+	})
+}
+
 func createTestContext(t *testing.T) *testContext {
 	t.Helper()
 	maxInactiveDurationVal, _ := time.ParseDuration("168h")
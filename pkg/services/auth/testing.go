@@ -18,6 +18,8 @@ type FakeUserAuthTokenService struct {
 	GetUserTokensProvider        func(ctx context.Context, userId int64) ([]*models.UserToken, error)
 	GetUserRevokedTokensProvider func(ctx context.Context, userId int64) ([]*models.UserToken, error)
 	BatchRevokedTokenProvider    func(ctx context.Context, userIds []int64) error
+	SearchUserSessionsProvider   func(ctx context.Context, query *models.SearchUserSessionsQuery) error
+	RevokeUserSessionsProvider   func(ctx context.Context, cmd *models.RevokeUserSessionsCmd) error
 }
 
 func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
@@ -55,6 +57,12 @@ func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
 		GetUserTokensProvider: func(ctx context.Context, userId int64) ([]*models.UserToken, error) {
 			return nil, nil
 		},
+		SearchUserSessionsProvider: func(ctx context.Context, query *models.SearchUserSessionsQuery) error {
+			return nil
+		},
+		RevokeUserSessionsProvider: func(ctx context.Context, cmd *models.RevokeUserSessionsCmd) error {
+			return nil
+		},
 	}
 }
 
@@ -104,3 +112,11 @@ func (s *FakeUserAuthTokenService) GetUserRevokedTokens(ctx context.Context, use
 func (s *FakeUserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, userIds []int64) error {
 	return s.BatchRevokedTokenProvider(ctx, userIds)
 }
+
+func (s *FakeUserAuthTokenService) SearchUserSessions(ctx context.Context, query *models.SearchUserSessionsQuery) error {
+	return s.SearchUserSessionsProvider(context.Background(), query)
+}
+
+func (s *FakeUserAuthTokenService) RevokeUserSessions(ctx context.Context, cmd *models.RevokeUserSessionsCmd) error {
+	return s.RevokeUserSessionsProvider(context.Background(), cmd)
+}
@@ -8,6 +8,11 @@ import (
 	"path"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
@@ -21,9 +26,16 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+var deletedDashboardVersions = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "grafana",
+	Name:      "cleanup_dashboard_versions_deleted_total",
+	Help:      "Number of dashboard versions deleted by the retention policies, by policy",
+}, []string{"reason"})
+
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, store sqlstore.Store, queryHistoryService queryhistory.Service,
-	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service) *CleanUpService {
+	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, auditService audit.Service,
+	dashboardService dashboards.DashboardService) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:                      cfg,
 		ServerLockService:        serverLockService,
@@ -33,6 +45,8 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 		log:                      log.New("cleanup"),
 		dashboardVersionService:  dashboardVersionService,
 		dashboardSnapshotService: dashSnapSvc,
+		auditService:             auditService,
+		dashboardService:         dashboardService,
 	}
 	return s
 }
@@ -46,6 +60,8 @@ type CleanUpService struct {
 	QueryHistoryService      queryhistory.Service
 	dashboardVersionService  dashver.Service
 	dashboardSnapshotService dashboardsnapshots.Service
+	auditService             audit.Service
+	dashboardService         dashboards.DashboardService
 }
 
 func (srv *CleanUpService) Run(ctx context.Context) error {
@@ -65,6 +81,8 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.expireOldUserInvites(ctx)
 			srv.deleteStaleShortURLs(ctx)
 			srv.deleteStaleQueryHistory(ctx)
+			srv.deleteExpiredAuditEvents(ctx)
+			srv.deleteExpiredDashboardTrash(ctx)
 			err := srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts",
 				time.Minute*10, func(context.Context) {
 					srv.deleteOldLoginAttempts(ctx)
@@ -152,7 +170,10 @@ func (srv *CleanUpService) deleteExpiredDashboardVersions(ctx context.Context) {
 	if err := srv.dashboardVersionService.DeleteExpired(ctx, &cmd); err != nil {
 		srv.log.Error("Failed to delete expired dashboard versions", "error", err.Error())
 	} else {
-		srv.log.Debug("Deleted old/expired dashboard versions", "rows affected", cmd.DeletedRows)
+		srv.log.Debug("Deleted old/expired dashboard versions", "rows affected", cmd.DeletedRows, "by reason", cmd.DeletedRowsByReason)
+	}
+	for reason, rows := range cmd.DeletedRowsByReason {
+		deletedDashboardVersions.WithLabelValues(reason).Add(float64(rows))
 	}
 }
 
@@ -224,3 +245,20 @@ func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
 		srv.log.Debug("Enforced row limit for query_history_star", "rows affected", rowsCount)
 	}
 }
+
+func (srv *CleanUpService) deleteExpiredAuditEvents(ctx context.Context) {
+	cmd := audit.DeleteExpiredEventsCommand{}
+	if err := srv.auditService.DeleteExpired(ctx, &cmd); err != nil {
+		srv.log.Error("Problem deleting expired audit events", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired audit events", "rows affected", cmd.DeletedRows)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredDashboardTrash(ctx context.Context) {
+	if rowsAffected, err := srv.dashboardService.DeleteExpiredDashboardTrash(ctx); err != nil {
+		srv.log.Error("Problem deleting expired dashboards from trash", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired dashboards from trash", "rows affected", rowsAffected)
+	}
+}
@@ -4,10 +4,15 @@ type EventType string
 
 const (
 	EventCommentCreated EventType = "commentCreated"
+	EventCommentUpdated EventType = "commentUpdated"
+	EventCommentDeleted EventType = "commentDeleted"
 )
 
 // Event represents comment event structure.
 type Event struct {
 	Event          EventType   `json:"event"`
 	CommentCreated *CommentDto `json:"commentCreated"`
+	CommentUpdated *CommentDto `json:"commentUpdated,omitempty"`
+	// CommentDeleted is the deleted comment, deleting a comment starting a thread deletes its replies too.
+	CommentDeleted *CommentDto `json:"commentDeleted,omitempty"`
 }
@@ -67,8 +67,10 @@ func (s *Settings) Scan(value interface{}) error {
 type Comment struct {
 	Id      int64
 	GroupId int64
-	UserId  int64
-	Content string
+	// ParentId is the id of the comment replied to, 0 for comments starting a thread.
+	ParentId int64
+	UserId   int64
+	Content  string
 
 	Created int64
 	Updated int64
@@ -83,20 +85,24 @@ type CommentUser struct {
 }
 
 type CommentDto struct {
-	Id      int64        `json:"id"`
-	UserId  int64        `json:"userId"`
-	Content string       `json:"content"`
-	Created int64        `json:"created"`
-	User    *CommentUser `json:"user,omitempty"`
+	Id       int64        `json:"id"`
+	ParentId int64        `json:"parentId"`
+	UserId   int64        `json:"userId"`
+	Content  string       `json:"content"`
+	Created  int64        `json:"created"`
+	Updated  int64        `json:"updated"`
+	User     *CommentUser `json:"user,omitempty"`
 }
 
 func (i Comment) ToDTO(user *CommentUser) *CommentDto {
 	return &CommentDto{
-		Id:      i.Id,
-		UserId:  i.UserId,
-		Content: i.Content,
-		Created: i.Created,
-		User:    user,
+		Id:       i.Id,
+		ParentId: i.ParentId,
+		UserId:   i.UserId,
+		Content:  i.Content,
+		Created:  i.Created,
+		Updated:  i.Updated,
+		User:     user,
 	}
 }
 
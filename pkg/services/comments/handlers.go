@@ -2,9 +2,7 @@ package comments
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"sort"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -81,20 +79,26 @@ type GetCmd struct {
 type CreateCmd struct {
 	ObjectType string `json:"objectType"`
 	ObjectID   string `json:"objectId"`
-	Content    string `json:"content"`
+	// ParentID is the id of the comment replied to, replies belong to the thread of the comment.
+	ParentID int64  `json:"parentId"`
+	Content  string `json:"content"`
 }
 
-var ErrPermissionDenied = errors.New("permission denied")
+type UpdateCmd struct {
+	ID      int64  `json:"id"`
+	Content string `json:"content"`
+}
 
-func (s *Service) Create(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, cmd CreateCmd) (*commentmodel.CommentDto, error) {
-	ok, err := s.permissions.CheckWritePermissions(ctx, orgID, signedInUser, cmd.ObjectType, cmd.ObjectID)
-	if err != nil {
-		return nil, err
-	}
-	if !ok {
-		return nil, ErrPermissionDenied
-	}
+type DeleteCmd struct {
+	ID int64 `json:"id"`
+}
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrCommentNotFound  = errors.New("comment not found")
+)
 
+func signedInUserToCommentUserMap(signedInUser *models.SignedInUser) map[int64]*commentmodel.CommentUser {
 	userMap := make(map[int64]*commentmodel.CommentUser, 1)
 	if signedInUser.UserId > 0 {
 		userMap[signedInUser.UserId] = &commentmodel.CommentUser{
@@ -105,21 +109,96 @@ func (s *Service) Create(ctx context.Context, orgID int64, signedInUser *models.
 			AvatarUrl: dtos.GetGravatarUrl(signedInUser.Email),
 		}
 	}
+	return userMap
+}
 
-	m, err := s.storage.Create(ctx, orgID, cmd.ObjectType, cmd.ObjectID, signedInUser.UserId, cmd.Content)
+func (s *Service) publish(orgID int64, group *commentmodel.CommentGroup, e commentmodel.Event) {
+	if err := s.live.GrafanaScope.Comments.PublishEvent(orgID, group.ObjectType, group.ObjectId, e); err != nil {
+		s.log.Warn("Failed to publish comment event", "event", e.Event, "error", err)
+	}
+}
+
+func (s *Service) Create(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, cmd CreateCmd) (*commentmodel.CommentDto, error) {
+	ok, err := s.permissions.CheckWritePermissions(ctx, orgID, signedInUser, cmd.ObjectType, cmd.ObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+
+	m, err := s.storage.Create(ctx, orgID, cmd.ObjectType, cmd.ObjectID, signedInUser.UserId, cmd.ParentID, cmd.Content)
 	if err != nil {
 		return nil, err
 	}
-	mDto := commentToDto(m, userMap)
-	e := commentmodel.Event{
+	mDto := commentToDto(m, signedInUserToCommentUserMap(signedInUser))
+	group := &commentmodel.CommentGroup{ObjectType: cmd.ObjectType, ObjectId: cmd.ObjectID}
+	s.publish(orgID, group, commentmodel.Event{
 		Event:          commentmodel.EventCommentCreated,
 		CommentCreated: mDto,
+	})
+	s.notifyMentions(ctx, orgID, signedInUser, group, m, "")
+	return mDto, nil
+}
+
+// Update edits the content of a comment, only the author of a comment can edit it.
+func (s *Service) Update(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, cmd UpdateCmd) (*commentmodel.CommentDto, error) {
+	comment, group, err := s.storage.GetByID(ctx, orgID, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserId != signedInUser.UserId {
+		return nil, ErrPermissionDenied
+	}
+	ok, err := s.permissions.CheckWritePermissions(ctx, orgID, signedInUser, group.ObjectType, group.ObjectId)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPermissionDenied
+	}
+
+	m, err := s.storage.Update(ctx, orgID, cmd.ID, cmd.Content)
+	if err != nil {
+		return nil, err
 	}
-	eventJSON, _ := json.Marshal(e)
-	_ = s.live.Publish(orgID, fmt.Sprintf("grafana/comment/%s/%s", cmd.ObjectType, cmd.ObjectID), eventJSON)
+	mDto := commentToDto(m, signedInUserToCommentUserMap(signedInUser))
+	s.publish(orgID, group, commentmodel.Event{
+		Event:          commentmodel.EventCommentUpdated,
+		CommentUpdated: mDto,
+	})
+	// only the users mentioned by the edit are notified
+	s.notifyMentions(ctx, orgID, signedInUser, group, m, comment.Content)
 	return mDto, nil
 }
 
+// Delete deletes a comment with its replies, the author of a comment and org admins can delete it.
+func (s *Service) Delete(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, cmd DeleteCmd) error {
+	comment, group, err := s.storage.GetByID(ctx, orgID, cmd.ID)
+	if err != nil {
+		return err
+	}
+	if comment.UserId != signedInUser.UserId && !signedInUser.HasRole(models.ROLE_ADMIN) {
+		return ErrPermissionDenied
+	}
+	ok, err := s.permissions.CheckWritePermissions(ctx, orgID, signedInUser, group.ObjectType, group.ObjectId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPermissionDenied
+	}
+
+	if err := s.storage.Delete(ctx, orgID, cmd.ID); err != nil {
+		return err
+	}
+	s.publish(orgID, group, commentmodel.Event{
+		Event:          commentmodel.EventCommentDeleted,
+		CommentDeleted: commentToDto(comment, signedInUserToCommentUserMap(signedInUser)),
+	})
+	return nil
+}
+
 func (s *Service) Get(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, cmd GetCmd) ([]*commentmodel.CommentDto, error) {
 	ok, err := s.permissions.CheckReadPermissions(ctx, orgID, signedInUser, cmd.ObjectType, cmd.ObjectID)
 	if err != nil {
@@ -0,0 +1,131 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
+)
+
+const tmplCommentMention = "comment_mention"
+
+// mentionRegexp matches `@login` mentions, logins may contain dots, dashes and @ as e-mails used as logins do.
+var mentionRegexp = regexp.MustCompile(`(?:^|[^\w@.])@(\w[\w.@+-]*)`)
+
+// parseMentions returns the logins mentioned in the content of a comment, without duplicates.
+func parseMentions(content string) []string {
+	seen := make(map[string]struct{})
+	logins := make([]string, 0)
+	for _, match := range mentionRegexp.FindAllStringSubmatch(content, -1) {
+		// a mention ending a sentence
+		login := strings.TrimRight(match[1], ".")
+		if _, ok := seen[login]; ok {
+			continue
+		}
+		seen[login] = struct{}{}
+		logins = append(logins, login)
+	}
+	return logins
+}
+
+// resolveMentions returns the users of the org mentioned in the content of a comment, skipping the logins
+// already mentioned in the previous content.
+func (s *Service) resolveMentions(ctx context.Context, orgID int64, content string, previousContent string) ([]*models.SignedInUser, error) {
+	previous := make(map[string]struct{})
+	for _, login := range parseMentions(previousContent) {
+		previous[login] = struct{}{}
+	}
+
+	users := make([]*models.SignedInUser, 0)
+	for _, login := range parseMentions(content) {
+		if _, ok := previous[login]; ok {
+			continue
+		}
+		query := models.GetSignedInUserQuery{Login: login, OrgId: orgID}
+		if err := s.sqlStore.GetSignedInUser(ctx, &query); err != nil {
+			if errors.Is(err, models.ErrUserNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		// users outside of the org get an org id of -1
+		if query.Result.OrgId != orgID || query.Result.IsDisabled {
+			continue
+		}
+		users = append(users, query.Result)
+	}
+	return users, nil
+}
+
+// notifyMentions emails the users mentioned in a comment who can read it. Failures are only logged
+// since the comment is saved already.
+func (s *Service) notifyMentions(ctx context.Context, orgID int64, author *models.SignedInUser, group *commentmodel.CommentGroup, comment *commentmodel.Comment, previousContent string) {
+	users, err := s.resolveMentions(ctx, orgID, comment.Content, previousContent)
+	if err != nil {
+		s.log.Error("Failed to resolve comment mentions", "commentId", comment.Id, "error", err)
+		return
+	}
+
+	var link string
+	for _, user := range users {
+		if user.UserId == author.UserId || user.Email == "" {
+			continue
+		}
+		ok, err := s.permissions.CheckReadPermissions(ctx, orgID, user, group.ObjectType, group.ObjectId)
+		if err != nil || !ok {
+			continue
+		}
+
+		if link == "" {
+			link = s.objectURL(ctx, orgID, author, group)
+		}
+		err = s.notifications.SendEmailCommandHandler(ctx, &models.SendEmailCommand{
+			To:       []string{user.Email},
+			Template: tmplCommentMention,
+			Data: map[string]interface{}{
+				"Name":        user.NameOrFallback(),
+				"MentionedBy": author.NameOrFallback(),
+				"Content":     comment.Content,
+				"Link":        link,
+			},
+		})
+		if errors.Is(err, models.ErrSmtpNotEnabled) {
+			s.log.Debug("Not sending comment mention emails, SMTP is not enabled")
+			return
+		}
+		if err != nil {
+			s.log.Error("Failed to send comment mention email", "commentId", comment.Id, "userId", user.UserId, "error", err)
+		}
+	}
+}
+
+// objectURL returns the URL of the dashboard a comment is about, or the URL of Grafana when unknown.
+func (s *Service) objectURL(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, group *commentmodel.CommentGroup) string {
+	query := models.GetDashboardQuery{OrgId: orgID}
+	switch group.ObjectType {
+	case commentmodel.ObjectTypeDashboard:
+		query.Uid = group.ObjectId
+	case commentmodel.ObjectTypeAnnotation:
+		annotationID, err := strconv.ParseInt(group.ObjectId, 10, 64)
+		if err != nil {
+			return s.cfg.AppURL
+		}
+		items, err := annotations.GetRepository().Find(ctx, &annotations.ItemQuery{AnnotationId: annotationID, OrgId: orgID, SignedInUser: signedInUser})
+		if err != nil || len(items) != 1 || items[0].DashboardId == 0 {
+			return s.cfg.AppURL
+		}
+		query.Id = items[0].DashboardId
+	default:
+		return s.cfg.AppURL
+	}
+
+	if err := s.dashboardService.GetDashboard(ctx, &query); err != nil {
+		return s.cfg.AppURL
+	}
+	return models.GetFullDashboardUrl(query.Result.Uid, query.Result.Slug)
+}
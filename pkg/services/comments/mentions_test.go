@@ -0,0 +1,40 @@
+package comments
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestParseMentions(t *testing.T) {
+	require.Equal(t, []string{"alice", "bob.smith", "carol@example.com"},
+		parseMentions("@alice can you check with @bob.smith and (@carol@example.com)? Thanks @alice."))
+	require.Empty(t, parseMentions("mail alice@example.com or bob@@example.com"))
+	require.Empty(t, parseMentions(""))
+}
+
+func TestResolveMentions(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	s := &Service{sqlStore: sqlStore}
+	ctx := context.Background()
+
+	alice, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "alice", Email: "alice@example.com"})
+	require.NoError(t, err)
+	_, err = sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "bob", Email: "bob@example.com", OrgName: "other org"})
+	require.NoError(t, err)
+	_, err = sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "carol", Email: "carol@example.com", OrgId: alice.OrgId, IsDisabled: true})
+	require.NoError(t, err)
+
+	users, err := s.resolveMentions(ctx, alice.OrgId, "@alice @bob @carol @unknown", "")
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, alice.Id, users[0].UserId)
+
+	users, err = s.resolveMentions(ctx, alice.OrgId, "@alice, any news?", "@alice")
+	require.NoError(t, err)
+	require.Len(t, users, 0)
+}
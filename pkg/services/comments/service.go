@@ -3,26 +3,31 @@ package comments
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 type Service struct {
-	cfg         *setting.Cfg
-	live        *live.GrafanaLive
-	sqlStore    *sqlstore.SQLStore
-	storage     Storage
-	permissions *commentmodel.PermissionChecker
+	cfg              *setting.Cfg
+	live             *live.GrafanaLive
+	sqlStore         *sqlstore.SQLStore
+	storage          Storage
+	permissions      *commentmodel.PermissionChecker
+	notifications    notifications.Service
+	dashboardService dashboards.DashboardService
+	log              log.Logger
 }
 
 func ProvideService(cfg *setting.Cfg, store *sqlstore.SQLStore, live *live.GrafanaLive,
 	features featuremgmt.FeatureToggles, accessControl accesscontrol.AccessControl,
-	dashboardService dashboards.DashboardService) *Service {
+	dashboardService dashboards.DashboardService, notificationService notifications.Service) *Service {
 	s := &Service{
 		cfg:      cfg,
 		live:     live,
@@ -30,7 +35,10 @@ func ProvideService(cfg *setting.Cfg, store *sqlstore.SQLStore, live *live.Grafa
 		storage: &sqlStorage{
 			sql: store,
 		},
-		permissions: commentmodel.NewPermissionChecker(store, features, accessControl, dashboardService),
+		permissions:      commentmodel.NewPermissionChecker(store, features, accessControl, dashboardService),
+		notifications:    notificationService,
+		dashboardService: dashboardService,
+		log:              log.New("comments"),
 	}
 	return s
 }
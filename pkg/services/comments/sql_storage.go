@@ -21,7 +21,7 @@ func checkObjectID(objectID string) bool {
 	return objectID != ""
 }
 
-func (s *sqlStorage) Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, content string) (*commentmodel.Comment, error) {
+func (s *sqlStorage) Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, parentID int64, content string) (*commentmodel.Comment, error) {
 	if !checkObjectType(objectType) {
 		return nil, errUnknownObjectType
 	}
@@ -60,12 +60,27 @@ func (s *sqlStorage) Create(ctx context.Context, orgID int64, objectType string,
 			}
 			groupID = group.Id
 		}
+		if parentID > 0 {
+			var parent commentmodel.Comment
+			has, err := dbSession.NoAutoCondition().Where("id=? AND group_id=?", parentID, groupID).Get(&parent)
+			if err != nil {
+				return err
+			}
+			if !has {
+				return ErrCommentNotFound
+			}
+			// Threads are one level deep, replies to a reply belong to the thread of the reply.
+			if parent.ParentId > 0 {
+				parentID = parent.ParentId
+			}
+		}
 		message := commentmodel.Comment{
-			GroupId: groupID,
-			UserId:  userID,
-			Content: content,
-			Created: nowUnix,
-			Updated: nowUnix,
+			GroupId:  groupID,
+			ParentId: parentID,
+			UserId:   userID,
+			Content:  content,
+			Created:  nowUnix,
+			Updated:  nowUnix,
 		}
 		_, err = dbSession.Insert(&message)
 		if err != nil {
@@ -115,3 +130,67 @@ func (s *sqlStorage) Get(ctx context.Context, orgID int64, objectType string, ob
 		return clause.OrderBy("id desc").Limit(limit).Find(&result)
 	})
 }
+
+func getComment(dbSession *sqlstore.DBSession, orgID int64, id int64) (*commentmodel.Comment, *commentmodel.CommentGroup, error) {
+	var comment commentmodel.Comment
+	has, err := dbSession.NoAutoCondition().Where("id=?", id).Get(&comment)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !has {
+		return nil, nil, ErrCommentNotFound
+	}
+	var group commentmodel.CommentGroup
+	has, err = dbSession.NoAutoCondition().Where("id=? AND org_id=?", comment.GroupId, orgID).Get(&group)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !has {
+		return nil, nil, ErrCommentNotFound
+	}
+	return &comment, &group, nil
+}
+
+func (s *sqlStorage) GetByID(ctx context.Context, orgID int64, id int64) (*commentmodel.Comment, *commentmodel.CommentGroup, error) {
+	var comment *commentmodel.Comment
+	var group *commentmodel.CommentGroup
+	err := s.sql.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		var err error
+		comment, group, err = getComment(dbSession, orgID, id)
+		return err
+	})
+	return comment, group, err
+}
+
+func (s *sqlStorage) Update(ctx context.Context, orgID int64, id int64, content string) (*commentmodel.Comment, error) {
+	if content == "" {
+		return nil, errEmptyContent
+	}
+
+	var result *commentmodel.Comment
+
+	return result, s.sql.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		comment, _, err := getComment(dbSession, orgID, id)
+		if err != nil {
+			return err
+		}
+		comment.Content = content
+		comment.Updated = time.Now().Unix()
+		if _, err := dbSession.ID(comment.Id).Cols("content", "updated").Update(comment); err != nil {
+			return err
+		}
+		result = comment
+		return nil
+	})
+}
+
+func (s *sqlStorage) Delete(ctx context.Context, orgID int64, id int64) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		comment, _, err := getComment(dbSession, orgID, id)
+		if err != nil {
+			return err
+		}
+		_, err = dbSession.Exec("DELETE FROM comment WHERE id=? OR parent_id=?", comment.Id, comment.Id)
+		return err
+	})
+}
@@ -29,7 +29,7 @@ func TestSqlStorage(t *testing.T) {
 	numComments := 10
 
 	for i := 0; i < numComments; i++ {
-		comment, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 1, "test"+strconv.Itoa(i))
+		comment, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 1, 0, "test"+strconv.Itoa(i))
 		require.NoError(t, err)
 		require.NotNil(t, comment)
 		require.True(t, comment.Id > 0)
@@ -74,3 +74,50 @@ func TestSqlStorage(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, items, 0)
 }
+
+func TestSqlStorageThreads(t *testing.T) {
+	s := createSqlStorage(t)
+	ctx := context.Background()
+
+	parent, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 1, 0, "parent")
+	require.NoError(t, err)
+	reply, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 2, parent.Id, "reply")
+	require.NoError(t, err)
+	require.Equal(t, parent.Id, reply.ParentId)
+
+	// Replies to a reply belong to the thread.
+	replyToReply, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 1, reply.Id, "reply to reply")
+	require.NoError(t, err)
+	require.Equal(t, parent.Id, replyToReply.ParentId)
+
+	// Parent of another object.
+	_, err = s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "3", 1, parent.Id, "reply")
+	require.ErrorIs(t, err, ErrCommentNotFound)
+
+	updated, err := s.Update(ctx, 1, reply.Id, "edited reply")
+	require.NoError(t, err)
+	require.Equal(t, "edited reply", updated.Content)
+	require.Equal(t, parent.Id, updated.ParentId)
+
+	comment, group, err := s.GetByID(ctx, 1, reply.Id)
+	require.NoError(t, err)
+	require.Equal(t, "edited reply", comment.Content)
+	require.Equal(t, "2", group.ObjectId)
+
+	// Comment of another org.
+	_, _, err = s.GetByID(ctx, 2, reply.Id)
+	require.ErrorIs(t, err, ErrCommentNotFound)
+	_, err = s.Update(ctx, 2, reply.Id, "edited reply")
+	require.ErrorIs(t, err, ErrCommentNotFound)
+	require.ErrorIs(t, s.Delete(ctx, 2, reply.Id), ErrCommentNotFound)
+
+	other, err := s.Create(ctx, 1, commentmodel.ObjectTypeOrg, "2", 1, 0, "other")
+	require.NoError(t, err)
+
+	// Deleting a thread deletes its replies.
+	require.NoError(t, s.Delete(ctx, 1, parent.Id))
+	items, err := s.Get(ctx, 1, commentmodel.ObjectTypeOrg, "2", GetFilter{})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, other.Id, items[0].Id)
+}
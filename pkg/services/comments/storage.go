@@ -20,5 +20,11 @@ var (
 
 type Storage interface {
 	Get(ctx context.Context, orgID int64, objectType string, objectID string, filter GetFilter) ([]*commentmodel.Comment, error)
-	Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, content string) (*commentmodel.Comment, error)
+	// GetByID returns a comment with the group it belongs to.
+	GetByID(ctx context.Context, orgID int64, id int64) (*commentmodel.Comment, *commentmodel.CommentGroup, error)
+	// Create adds a comment, replying to the comment with parentID when not 0.
+	Create(ctx context.Context, orgID int64, objectType string, objectID string, userID int64, parentID int64, content string) (*commentmodel.Comment, error)
+	Update(ctx context.Context, orgID int64, id int64, content string) (*commentmodel.Comment, error)
+	// Delete deletes a comment with its replies.
+	Delete(ctx context.Context, orgID int64, id int64) error
 }
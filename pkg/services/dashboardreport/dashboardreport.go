@@ -0,0 +1,18 @@
+package dashboardreport
+
+import (
+	"context"
+)
+
+// Service manages dashboard reports, rendered and emailed to their recipients on a schedule.
+type Service interface {
+	Create(ctx context.Context, cmd *SaveReportCommand) (*Report, error)
+	Update(ctx context.Context, cmd *UpdateReportCommand) (*Report, error)
+	Delete(ctx context.Context, orgID int64, uid string) error
+	Get(ctx context.Context, orgID int64, uid string) (*Report, error)
+	Search(ctx context.Context, query *SearchReportsQuery) (*SearchReportsResult, error)
+	// Send queues a report to be rendered and emailed right away, outside of its schedule.
+	// The delivery is recorded in the history of the report once sent.
+	Send(ctx context.Context, orgID int64, uid string) error
+	GetDeliveries(ctx context.Context, query *GetDeliveriesQuery) ([]*Delivery, error)
+}
@@ -0,0 +1,217 @@
+package dashboardreport
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	ErrReportNotFound    = errors.New("dashboard report not found")
+	ErrMissingName       = errors.New("dashboard report name is missing")
+	ErrMissingDashboard  = errors.New("dashboard report dashboard is missing")
+	ErrInvalidFormat     = errors.New("dashboard report format must be pdf or png")
+	ErrInvalidSchedule   = errors.New("dashboard report schedule is not a valid cron expression")
+	ErrInvalidTimezone   = errors.New("dashboard report timezone is not valid")
+	ErrMissingRecipients = errors.New("dashboard report has no recipients")
+	ErrInvalidRecipient  = errors.New("dashboard report recipient is not a valid email address")
+	ErrSendQueueFull     = errors.New("too many dashboard reports are waiting to be sent")
+)
+
+type Format string
+
+const (
+	FormatPDF Format = "pdf"
+	FormatPNG Format = "png"
+)
+
+// Delivery statuses
+const (
+	DeliveryStatusSent   = "sent"
+	DeliveryStatusFailed = "failed"
+)
+
+// Recipients are the email addresses a report is sent to, stored comma-separated.
+type Recipients []string
+
+func (r *Recipients) FromDB(data []byte) error {
+	*r = Recipients{}
+	for _, address := range strings.Split(string(data), ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			*r = append(*r, address)
+		}
+	}
+	return nil
+}
+
+func (r Recipients) ToDB() ([]byte, error) {
+	return []byte(strings.Join(r, ",")), nil
+}
+
+// Report is a dashboard rendered and emailed on a schedule
+type Report struct {
+	ID    int64  `xorm:"pk autoincr 'id'" json:"id"`
+	UID   string `xorm:"uid" json:"uid"`
+	OrgID int64  `xorm:"org_id" json:"orgId"`
+	// UserID is the owner of the report, the dashboard is rendered with the permissions of the owner
+	UserID       int64  `xorm:"user_id" json:"userId"`
+	Name         string `xorm:"name" json:"name"`
+	DashboardUID string `xorm:"dashboard_uid" json:"dashboardUid"`
+	// Variables are the values of the template variables of the dashboard, by variable name
+	Variables *simplejson.Json `xorm:"variables" json:"variables"`
+	TimeFrom  string           `xorm:"time_from" json:"timeFrom"`
+	TimeTo    string           `xorm:"time_to" json:"timeTo"`
+	// Schedule is a cron expression evaluated in the timezone of the report
+	Schedule   string     `xorm:"schedule" json:"schedule"`
+	Timezone   string     `xorm:"timezone" json:"timezone"`
+	Format     Format     `xorm:"format" json:"format"`
+	Recipients Recipients `xorm:"recipients" json:"recipients"`
+	Enabled    bool       `xorm:"enabled" json:"enabled"`
+	// NextRun is the unix timestamp of the next scheduled delivery, zero when the report is disabled
+	NextRun int64 `xorm:"next_run" json:"nextRun"`
+	Created int64 `xorm:"created" json:"created"`
+	Updated int64 `xorm:"updated" json:"updated"`
+}
+
+func (r Report) TableName() string {
+	return "dashboard_report"
+}
+
+// NextRunAfter returns the next time the report is due after t, following its schedule and timezone.
+func (r *Report) NextRunAfter(t time.Time) (time.Time, error) {
+	schedule, err := parseSchedule(r.Schedule, r.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(t), nil
+}
+
+func parseSchedule(schedule string, timezone string) (cron.Schedule, error) {
+	location := time.UTC
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return nil, ErrInvalidTimezone
+		}
+	}
+
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchedule, err)
+	}
+	if specSchedule, ok := parsed.(*cron.SpecSchedule); ok {
+		specSchedule.Location = location
+	}
+	return parsed, nil
+}
+
+// Delivery is a recorded attempt at sending a report
+type Delivery struct {
+	ID       int64 `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID    int64 `xorm:"org_id" json:"orgId"`
+	ReportID int64 `xorm:"report_id" json:"reportId"`
+	// Manual is true for deliveries requested through the API instead of the schedule
+	Manual     bool       `xorm:"manual" json:"manual"`
+	Status     string     `xorm:"status" json:"status"`
+	Error      string     `xorm:"error" json:"error,omitempty"`
+	Recipients Recipients `xorm:"recipients" json:"recipients"`
+	Started    int64      `xorm:"started" json:"started"`
+	Finished   int64      `xorm:"finished" json:"finished"`
+}
+
+func (d Delivery) TableName() string {
+	return "dashboard_report_delivery"
+}
+
+type SaveReportCommand struct {
+	Name         string           `json:"name"`
+	DashboardUID string           `json:"dashboardUid"`
+	Variables    *simplejson.Json `json:"variables"`
+	TimeFrom     string           `json:"timeFrom"`
+	TimeTo       string           `json:"timeTo"`
+	Schedule     string           `json:"schedule"`
+	Timezone     string           `json:"timezone"`
+	Format       Format           `json:"format"`
+	Recipients   Recipients       `json:"recipients"`
+	Enabled      bool             `json:"enabled"`
+
+	OrgID  int64 `json:"-"`
+	UserID int64 `json:"-"`
+}
+
+// Validate checks the command and sets the defaults of the optional fields.
+func (cmd *SaveReportCommand) Validate() error {
+	if strings.TrimSpace(cmd.Name) == "" {
+		return ErrMissingName
+	}
+	if cmd.DashboardUID == "" {
+		return ErrMissingDashboard
+	}
+
+	switch cmd.Format {
+	case "":
+		cmd.Format = FormatPDF
+	case FormatPDF, FormatPNG:
+	default:
+		return ErrInvalidFormat
+	}
+
+	if _, err := parseSchedule(cmd.Schedule, cmd.Timezone); err != nil {
+		return err
+	}
+
+	if len(cmd.Recipients) == 0 {
+		return ErrMissingRecipients
+	}
+	for i, address := range cmd.Recipients {
+		address = strings.TrimSpace(address)
+		if !util.IsEmail(address) || strings.Contains(address, ",") {
+			return fmt.Errorf("%w: %q", ErrInvalidRecipient, address)
+		}
+		cmd.Recipients[i] = address
+	}
+
+	if cmd.TimeFrom == "" {
+		cmd.TimeFrom = "now-7d"
+	}
+	if cmd.TimeTo == "" {
+		cmd.TimeTo = "now"
+	}
+	if cmd.Variables == nil {
+		cmd.Variables = simplejson.New()
+	}
+	return nil
+}
+
+type UpdateReportCommand struct {
+	SaveReportCommand
+	UID string `json:"-"`
+}
+
+type SearchReportsQuery struct {
+	OrgID        int64
+	DashboardUID string
+	// DashboardUIDs restricts the reports to the reports of these dashboards when not nil
+	DashboardUIDs []string
+	Page          int
+	Limit         int
+}
+
+type SearchReportsResult struct {
+	TotalCount int64     `json:"totalCount"`
+	Reports    []*Report `json:"reports"`
+	Page       int       `json:"page"`
+	PerPage    int       `json:"perPage"`
+}
+
+type GetDeliveriesQuery struct {
+	OrgID     int64
+	ReportUID string
+	Limit     int
+}
@@ -0,0 +1,85 @@
+package dashboardreport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveReportCommandValidate(t *testing.T) {
+	valid := func() SaveReportCommand {
+		return SaveReportCommand{
+			Name:         "Weekly",
+			DashboardUID: "dash-1",
+			Schedule:     "0 8 * * 1",
+			Recipients:   Recipients{" ops@example.com "},
+		}
+	}
+
+	t.Run("should set the defaults", func(t *testing.T) {
+		cmd := valid()
+		require.NoError(t, cmd.Validate())
+		require.Equal(t, FormatPDF, cmd.Format)
+		require.Equal(t, "now-7d", cmd.TimeFrom)
+		require.Equal(t, "now", cmd.TimeTo)
+		require.NotNil(t, cmd.Variables)
+		require.Equal(t, Recipients{"ops@example.com"}, cmd.Recipients)
+	})
+
+	tests := []struct {
+		name   string
+		modify func(cmd *SaveReportCommand)
+		err    error
+	}{
+		{name: "missing name", modify: func(cmd *SaveReportCommand) { cmd.Name = " " }, err: ErrMissingName},
+		{name: "missing dashboard", modify: func(cmd *SaveReportCommand) { cmd.DashboardUID = "" }, err: ErrMissingDashboard},
+		{name: "invalid format", modify: func(cmd *SaveReportCommand) { cmd.Format = "csv" }, err: ErrInvalidFormat},
+		{name: "invalid schedule", modify: func(cmd *SaveReportCommand) { cmd.Schedule = "every monday" }, err: ErrInvalidSchedule},
+		{name: "invalid timezone", modify: func(cmd *SaveReportCommand) { cmd.Timezone = "Mars/Olympus" }, err: ErrInvalidTimezone},
+		{name: "missing recipients", modify: func(cmd *SaveReportCommand) { cmd.Recipients = nil }, err: ErrMissingRecipients},
+		{name: "invalid recipient", modify: func(cmd *SaveReportCommand) { cmd.Recipients = Recipients{"ops"} }, err: ErrInvalidRecipient},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := valid()
+			tc.modify(&cmd)
+			require.ErrorIs(t, cmd.Validate(), tc.err)
+		})
+	}
+}
+
+func TestReportNextRunAfter(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should default to UTC", func(t *testing.T) {
+		report := Report{Schedule: "0 8 * * *"}
+		next, err := report.NextRunAfter(now)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2022, 6, 2, 8, 0, 0, 0, time.UTC).Unix(), next.Unix())
+	})
+
+	t.Run("should follow the timezone of the report", func(t *testing.T) {
+		report := Report{Schedule: "0 8 * * *", Timezone: "Europe/Stockholm"}
+		next, err := report.NextRunAfter(now)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2022, 6, 2, 6, 0, 0, 0, time.UTC).Unix(), next.Unix())
+	})
+
+	t.Run("should fail with an invalid schedule", func(t *testing.T) {
+		report := Report{Schedule: "* *"}
+		_, err := report.NextRunAfter(now)
+		require.ErrorIs(t, err, ErrInvalidSchedule)
+	})
+}
+
+func TestRecipients(t *testing.T) {
+	recipients := Recipients{"a@example.com", "b@example.com"}
+	data, err := recipients.ToDB()
+	require.NoError(t, err)
+	require.Equal(t, "a@example.com,b@example.com", string(data))
+
+	var read Recipients
+	require.NoError(t, read.FromDB([]byte(" a@example.com, ,b@example.com")))
+	require.Equal(t, recipients, read)
+}
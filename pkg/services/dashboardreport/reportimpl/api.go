@@ -0,0 +1,251 @@
+package reportimpl
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardreport"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/dashboard-reports", func(entities routing.RouteRegister) {
+		entities.Get("/", middleware.ReqEditorRole, routing.Wrap(s.searchHandler))
+		entities.Post("/", middleware.ReqEditorRole, routing.Wrap(s.createHandler))
+		entities.Get("/:uid", middleware.ReqEditorRole, routing.Wrap(s.getHandler))
+		entities.Put("/:uid", middleware.ReqEditorRole, routing.Wrap(s.updateHandler))
+		entities.Delete("/:uid", middleware.ReqEditorRole, routing.Wrap(s.deleteHandler))
+		entities.Post("/:uid/send", middleware.ReqEditorRole, routing.Wrap(s.sendHandler))
+		entities.Get("/:uid/deliveries", middleware.ReqEditorRole, routing.Wrap(s.getDeliveriesHandler))
+	})
+}
+
+// searchHandler handles GET /api/dashboard-reports
+func (s *Service) searchHandler(c *models.ReqContext) response.Response {
+	query := &dashboardreport.SearchReportsQuery{
+		OrgID:        c.OrgId,
+		DashboardUID: c.Query("dashboardUid"),
+		Page:         c.QueryInt("page"),
+		Limit:        c.QueryInt("perpage"),
+	}
+
+	// only the reports of the dashboards the user can view are returned
+	dashboardUIDs := []string{query.DashboardUID}
+	if query.DashboardUID == "" {
+		var err error
+		if dashboardUIDs, err = s.store.GetDashboardUIDs(c.Req.Context(), c.OrgId); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to search dashboard reports", err)
+		}
+	}
+	var err error
+	if query.DashboardUIDs, err = s.viewableDashboardUIDs(c, dashboardUIDs); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check dashboard permissions", err)
+	}
+
+	result, err := s.Search(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search dashboard reports", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// createHandler handles POST /api/dashboard-reports
+func (s *Service) createHandler(c *models.ReqContext) response.Response {
+	cmd := dashboardreport.SaveReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.OrgId
+	cmd.UserID = c.UserId
+
+	if resp := s.checkCanViewDashboard(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+
+	report, err := s.Create(c.Req.Context(), &cmd)
+	if err != nil {
+		return toErrorResponse(err, "Failed to create dashboard report")
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// getHandler handles GET /api/dashboard-reports/:uid
+func (s *Service) getHandler(c *models.ReqContext) response.Response {
+	report, resp := s.getViewableReport(c)
+	if resp != nil {
+		return resp
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// updateHandler handles PUT /api/dashboard-reports/:uid
+func (s *Service) updateHandler(c *models.ReqContext) response.Response {
+	cmd := dashboardreport.UpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.OrgId
+	cmd.UserID = c.UserId
+
+	if _, resp := s.getViewableReport(c); resp != nil {
+		return resp
+	}
+	if resp := s.checkCanViewDashboard(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+
+	report, err := s.Update(c.Req.Context(), &cmd)
+	if err != nil {
+		return toErrorResponse(err, "Failed to update dashboard report")
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// deleteHandler handles DELETE /api/dashboard-reports/:uid
+func (s *Service) deleteHandler(c *models.ReqContext) response.Response {
+	report, resp := s.getViewableReport(c)
+	if resp != nil {
+		return resp
+	}
+	if err := s.Delete(c.Req.Context(), c.OrgId, report.UID); err != nil {
+		return toErrorResponse(err, "Failed to delete dashboard report")
+	}
+	return response.Success("Dashboard report deleted")
+}
+
+// sendHandler handles POST /api/dashboard-reports/:uid/send
+func (s *Service) sendHandler(c *models.ReqContext) response.Response {
+	report, resp := s.getViewableReport(c)
+	if resp != nil {
+		return resp
+	}
+	if err := s.queueSend(report); err != nil {
+		return toErrorResponse(err, "Failed to send dashboard report")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "Dashboard report queued for sending"})
+}
+
+// getDeliveriesHandler handles GET /api/dashboard-reports/:uid/deliveries
+func (s *Service) getDeliveriesHandler(c *models.ReqContext) response.Response {
+	report, resp := s.getViewableReport(c)
+	if resp != nil {
+		return resp
+	}
+	deliveries, err := s.GetDeliveries(c.Req.Context(), &dashboardreport.GetDeliveriesQuery{
+		OrgID:     c.OrgId,
+		ReportUID: report.UID,
+		Limit:     c.QueryInt("limit"),
+	})
+	if err != nil {
+		return toErrorResponse(err, "Failed to get dashboard report deliveries")
+	}
+	return response.JSON(http.StatusOK, deliveries)
+}
+
+// getViewableReport returns the report of the request, or an error response when the dashboard of the report
+// cannot be viewed by the signed in user.
+func (s *Service) getViewableReport(c *models.ReqContext) (*dashboardreport.Report, response.Response) {
+	report, err := s.Get(c.Req.Context(), c.OrgId, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return nil, toErrorResponse(err, "Failed to get dashboard report")
+	}
+
+	canView, err := s.canViewDashboard(c, report.DashboardUID)
+	if err != nil {
+		return nil, response.Error(http.StatusInternalServerError, "Failed to check dashboard permissions", err)
+	}
+	if !canView {
+		// the reports of dashboards the user cannot view are hidden
+		return nil, response.Error(http.StatusNotFound, dashboardreport.ErrReportNotFound.Error(), nil)
+	}
+	return report, nil
+}
+
+// checkCanViewDashboard returns an error response when the dashboard of a report cannot be viewed by the signed in user.
+func (s *Service) checkCanViewDashboard(c *models.ReqContext, dashboardUID string) response.Response {
+	if dashboardUID == "" {
+		return response.Error(http.StatusBadRequest, dashboardreport.ErrMissingDashboard.Error(), nil)
+	}
+
+	query := models.GetDashboardQuery{Uid: dashboardUID, OrgId: c.OrgId}
+	if err := s.dashboardService.GetDashboard(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard", err)
+	}
+
+	canView, err := guardian.New(c.Req.Context(), query.Result.Id, c.OrgId, c.SignedInUser).CanView()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check dashboard permissions", err)
+	}
+	if !canView {
+		return response.Error(http.StatusForbidden, "Access denied to the dashboard", nil)
+	}
+	return nil
+}
+
+// viewableDashboardUIDs returns the dashboards the signed in user can view among a list of dashboards, the missing
+// dashboards are left out. The permissions are checked by the dashboard search, in batches of dashboards.
+func (s *Service) viewableDashboardUIDs(c *models.ReqContext, dashboardUIDs []string) ([]string, error) {
+	viewable := make([]string, 0, len(dashboardUIDs))
+	for start := 0; start < len(dashboardUIDs); start += dashboardUIDsBatchSize {
+		end := start + dashboardUIDsBatchSize
+		if end > len(dashboardUIDs) {
+			end = len(dashboardUIDs)
+		}
+
+		hits, err := s.dashboardService.FindDashboards(c.Req.Context(), &models.FindPersistedDashboardsQuery{
+			OrgId:         c.OrgId,
+			SignedInUser:  c.SignedInUser,
+			DashboardUIDs: dashboardUIDs[start:end],
+			Type:          string(models.DashHitDB),
+			Permission:    models.PERMISSION_VIEW,
+			Limit:         int64(end - start),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			viewable = append(viewable, hit.UID)
+		}
+	}
+	return viewable, nil
+}
+
+// canViewDashboard returns true when the signed in user can view a dashboard, false when the dashboard doesn't exist.
+func (s *Service) canViewDashboard(c *models.ReqContext, dashboardUID string) (bool, error) {
+	query := models.GetDashboardQuery{Uid: dashboardUID, OrgId: c.OrgId}
+	if err := s.dashboardService.GetDashboard(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return guardian.New(c.Req.Context(), query.Result.Id, c.OrgId, c.SignedInUser).CanView()
+}
+
+func toErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, dashboardreport.ErrReportNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, dashboardreport.ErrSendQueueFull):
+		return response.Error(http.StatusServiceUnavailable, err.Error(), err)
+	case errors.Is(err, dashboardreport.ErrMissingName),
+		errors.Is(err, dashboardreport.ErrMissingDashboard),
+		errors.Is(err, dashboardreport.ErrInvalidFormat),
+		errors.Is(err, dashboardreport.ErrInvalidSchedule),
+		errors.Is(err, dashboardreport.ErrInvalidTimezone),
+		errors.Is(err, dashboardreport.ErrMissingRecipients),
+		errors.Is(err, dashboardreport.ErrInvalidRecipient):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
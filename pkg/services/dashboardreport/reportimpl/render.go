@@ -0,0 +1,205 @@
+package reportimpl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardreport"
+	"github.com/grafana/grafana/pkg/services/rendering"
+)
+
+const (
+	dashboardWidth = 1600
+	panelWidth     = 1200
+	panelHeight    = 600
+
+	// A4 landscape page, in millimeters
+	pageWidth    = 297.0
+	pageHeight   = 210.0
+	pageMargin   = 10.0
+	headerHeight = 12.0
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^\w\-. ]+`)
+
+type reportPanel struct {
+	ID    int64
+	Title string
+}
+
+// render renders a report in its format, as an email attachment.
+func (s *Service) render(ctx context.Context, report *dashboardreport.Report, owner *models.SignedInUser, dash *models.Dashboard) (*models.SendEmailAttachFile, error) {
+	var content []byte
+	var err error
+	switch report.Format {
+	case dashboardreport.FormatPNG:
+		content, err = s.renderImage(ctx, report, owner, dashboardPath(report, dash)+"&kiosk", dashboardWidth, -1)
+	case dashboardreport.FormatPDF:
+		content, err = s.renderPDF(ctx, report, owner, dash)
+	default:
+		err = errUnknownFormatType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SendEmailAttachFile{
+		Name:    attachmentName(report),
+		Content: content,
+	}, nil
+}
+
+// renderPDF renders each panel of the dashboard on a page of the PDF.
+func (s *Service) renderPDF(ctx context.Context, report *dashboardreport.Report, owner *models.SignedInUser, dash *models.Dashboard) ([]byte, error) {
+	panels := dashboardPanels(dash.Data)
+	if len(panels) == 0 {
+		return nil, errNoPanelsToRender
+	}
+
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(false, pageMargin)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for _, panel := range panels {
+		image, err := s.renderImage(ctx, report, owner, panelPath(report, dash, panel.ID), panelWidth, panelHeight)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render panel %d: %w", panel.ID, err)
+		}
+
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 7, tr(panel.Title), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(0, 5, tr(fmt.Sprintf("%s, %s to %s", dash.Title, report.TimeFrom, report.TimeTo)), "", 1, "L", false, 0, "")
+
+		name := "panel-" + strconv.FormatInt(panel.ID, 10)
+		info := pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(image))
+		if err := pdf.Error(); err != nil {
+			return nil, err
+		}
+
+		// fit the image in the page below the header, keeping its aspect ratio
+		maxWidth := pageWidth - 2*pageMargin
+		maxHeight := pageHeight - 2*pageMargin - headerHeight
+		width := maxWidth
+		height := width * info.Height() / info.Width()
+		if height > maxHeight {
+			height = maxHeight
+			width = height * info.Width() / info.Height()
+		}
+		pdf.ImageOptions(name, pageMargin, pageMargin+headerHeight, width, height, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *Service) renderImage(ctx context.Context, report *dashboardreport.Report, owner *models.SignedInUser, renderPath string, width int, height int) ([]byte, error) {
+	result, err := s.renderService.Render(ctx, rendering.Opts{
+		TimeoutOpts: rendering.TimeoutOpts{
+			Timeout: s.cfg.RenderTimeout,
+		},
+		AuthOpts: rendering.AuthOpts{
+			OrgID:   owner.OrgId,
+			UserID:  owner.UserId,
+			OrgRole: owner.OrgRole,
+		},
+		ErrorOpts: rendering.ErrorOpts{
+			ErrorConcurrentLimitReached: true,
+			ErrorRenderUnavailable:      true,
+		},
+		Width:           width,
+		Height:          height,
+		Path:            renderPath,
+		Timezone:        report.Timezone,
+		ConcurrentLimit: s.cfg.ConcurrentRenderLimit,
+		Priority:        rendering.PriorityBackground,
+		Theme:           models.ThemeLight,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := os.Remove(result.FilePath); err != nil {
+			s.log.Warn("Failed to remove rendered report image", "path", result.FilePath, "error", err)
+		}
+	}()
+	return os.ReadFile(result.FilePath)
+}
+
+// dashboardPanels returns the panels of a dashboard in order, including the panels of collapsed rows.
+func dashboardPanels(data *simplejson.Json) []reportPanel {
+	panels := make([]reportPanel, 0)
+	for _, panel := range data.Get("panels").MustArray() {
+		panelJSON := simplejson.NewFromAny(panel)
+		if panelJSON.Get("type").MustString() == "row" {
+			for _, rowPanel := range panelJSON.Get("panels").MustArray() {
+				panels = append(panels, toReportPanel(simplejson.NewFromAny(rowPanel)))
+			}
+			continue
+		}
+		panels = append(panels, toReportPanel(panelJSON))
+	}
+	return panels
+}
+
+func toReportPanel(panel *simplejson.Json) reportPanel {
+	return reportPanel{
+		ID:    panel.Get("id").MustInt64(),
+		Title: panel.Get("title").MustString(),
+	}
+}
+
+// reportQuery returns the query string selecting the time range and variables of the report.
+func reportQuery(report *dashboardreport.Report, dash *models.Dashboard) url.Values {
+	query := url.Values{}
+	query.Set("orgId", strconv.FormatInt(dash.OrgId, 10))
+	query.Set("from", report.TimeFrom)
+	query.Set("to", report.TimeTo)
+	if report.Variables != nil {
+		for name := range report.Variables.MustMap() {
+			value := report.Variables.Get(name)
+			if values, err := value.StringArray(); err == nil {
+				for _, v := range values {
+					query.Add("var-"+name, v)
+				}
+				continue
+			}
+			query.Set("var-"+name, fmt.Sprint(value.Interface()))
+		}
+	}
+	return query
+}
+
+func dashboardPath(report *dashboardreport.Report, dash *models.Dashboard) string {
+	return path.Join("d", dash.Uid, dash.Slug) + "?" + reportQuery(report, dash).Encode()
+}
+
+func panelPath(report *dashboardreport.Report, dash *models.Dashboard, panelID int64) string {
+	query := reportQuery(report, dash)
+	query.Set("panelId", strconv.FormatInt(panelID, 10))
+	return path.Join("d-solo", dash.Uid, dash.Slug) + "?" + query.Encode()
+}
+
+func attachmentName(report *dashboardreport.Report) string {
+	name := unsafeFileNameChars.ReplaceAllString(report.Name, "")
+	if name == "" {
+		name = "report"
+	}
+	return name + "." + string(report.Format)
+}
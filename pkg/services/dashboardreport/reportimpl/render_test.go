@@ -0,0 +1,40 @@
+package reportimpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardreport"
+)
+
+func TestDashboardPanels(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(`{"panels": [
+		{"id": 1, "title": "CPU", "type": "timeseries"},
+		{"id": 2, "title": "Open row", "type": "row", "panels": []},
+		{"id": 3, "title": "Memory", "type": "stat"},
+		{"id": 4, "title": "Collapsed row", "type": "row", "collapsed": true, "panels": [
+			{"id": 5, "title": "Disk", "type": "timeseries"}
+		]}
+	]}`))
+	require.NoError(t, err)
+
+	require.Equal(t, []reportPanel{
+		{ID: 1, Title: "CPU"},
+		{ID: 3, Title: "Memory"},
+		{ID: 5, Title: "Disk"},
+	}, dashboardPanels(data))
+}
+
+func TestRenderPaths(t *testing.T) {
+	variables, err := simplejson.NewJson([]byte(`{"host": ["a", "b"], "env": "prod"}`))
+	require.NoError(t, err)
+	report := &dashboardreport.Report{Name: "Weekly / ops", Format: dashboardreport.FormatPDF, TimeFrom: "now-7d", TimeTo: "now", Variables: variables}
+	dash := &models.Dashboard{Uid: "dash-1", Slug: "ops", OrgId: 3}
+
+	require.Equal(t, "d/dash-1/ops?from=now-7d&orgId=3&to=now&var-env=prod&var-host=a&var-host=b", dashboardPath(report, dash))
+	require.Equal(t, "d-solo/dash-1/ops?from=now-7d&orgId=3&panelId=5&to=now&var-env=prod&var-host=a&var-host=b", panelPath(report, dash, 5))
+	require.Equal(t, "Weekly  ops.pdf", attachmentName(report))
+}
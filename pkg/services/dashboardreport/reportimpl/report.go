@@ -0,0 +1,330 @@
+package reportimpl
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardreport"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// schedulerInterval is how often the due reports are looked for
+	schedulerInterval = time.Minute
+	// defaultDeliveriesLimit is the number of deliveries returned by default
+	defaultDeliveriesLimit = 50
+	// sendQueueSize is the number of reports sent on demand which can wait to be sent
+	sendQueueSize = 100
+)
+
+var (
+	errOwnerNotInOrg     = errors.New("the owner of the report is not a member of the organization anymore")
+	errOwnerCannotView   = errors.New("the owner of the report cannot view the dashboard")
+	errNoPanelsToRender  = errors.New("the dashboard has no panels to render")
+	errUnknownFormatType = errors.New("unknown report format")
+)
+
+type Service struct {
+	store            store
+	sqlStore         sqlstore.Store
+	cfg              setting.DashboardReportsSettings
+	routeRegister    routing.RouteRegister
+	renderService    rendering.Service
+	notifications    notifications.Service
+	lockService      *serverlock.ServerLockService
+	dashboardService dashboards.DashboardService
+	log              log.Logger
+	// sendQueue holds the reports sent on demand, until they are sent by Run
+	sendQueue chan *dashboardreport.Report
+}
+
+func ProvideService(cfg *setting.Cfg, ss *sqlstore.SQLStore, routeRegister routing.RouteRegister,
+	renderService rendering.Service, notificationService notifications.Service,
+	lockService *serverlock.ServerLockService, dashboardService dashboards.DashboardService) *Service {
+	s := &Service{
+		store:            &sqlStore{db: ss},
+		sqlStore:         ss,
+		cfg:              cfg.DashboardReports,
+		routeRegister:    routeRegister,
+		renderService:    renderService,
+		notifications:    notificationService,
+		lockService:      lockService,
+		dashboardService: dashboardService,
+		log:              log.New("dashboard-reports"),
+		sendQueue:        make(chan *dashboardreport.Report, sendQueueSize),
+	}
+
+	if s.cfg.Enabled {
+		s.registerAPIEndpoints()
+	}
+
+	return s
+}
+
+func (s *Service) Create(ctx context.Context, cmd *dashboardreport.SaveReportCommand) (*dashboardreport.Report, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &dashboardreport.Report{
+		UID:     util.GenerateShortUID(),
+		OrgID:   cmd.OrgID,
+		Created: now.Unix(),
+	}
+	if err := applySaveCommand(report, cmd, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Insert(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Update saves the changes to a report, the user updating a report becomes its owner.
+func (s *Service) Update(ctx context.Context, cmd *dashboardreport.UpdateReportCommand) (*dashboardreport.Report, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	report, err := s.store.Get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applySaveCommand(report, &cmd.SaveReportCommand, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Update(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func applySaveCommand(report *dashboardreport.Report, cmd *dashboardreport.SaveReportCommand, now time.Time) error {
+	report.UserID = cmd.UserID
+	report.Name = cmd.Name
+	report.DashboardUID = cmd.DashboardUID
+	report.Variables = cmd.Variables
+	report.TimeFrom = cmd.TimeFrom
+	report.TimeTo = cmd.TimeTo
+	report.Schedule = cmd.Schedule
+	report.Timezone = cmd.Timezone
+	report.Format = cmd.Format
+	report.Recipients = cmd.Recipients
+	report.Enabled = cmd.Enabled
+	report.Updated = now.Unix()
+
+	report.NextRun = 0
+	if report.Enabled {
+		nextRun, err := report.NextRunAfter(now)
+		if err != nil {
+			return err
+		}
+		report.NextRun = nextRun.Unix()
+	}
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.store.Delete(ctx, orgID, uid)
+}
+
+func (s *Service) Get(ctx context.Context, orgID int64, uid string) (*dashboardreport.Report, error) {
+	return s.store.Get(ctx, orgID, uid)
+}
+
+func (s *Service) Search(ctx context.Context, query *dashboardreport.SearchReportsQuery) (*dashboardreport.SearchReportsResult, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) Send(ctx context.Context, orgID int64, uid string) error {
+	report, err := s.store.Get(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	return s.queueSend(report)
+}
+
+// queueSend queues a report to be sent by Run, rendering a report can take longer than an HTTP request.
+func (s *Service) queueSend(report *dashboardreport.Report) error {
+	select {
+	case s.sendQueue <- report:
+		return nil
+	default:
+		return dashboardreport.ErrSendQueueFull
+	}
+}
+
+func (s *Service) GetDeliveries(ctx context.Context, query *dashboardreport.GetDeliveriesQuery) ([]*dashboardreport.Delivery, error) {
+	report, err := s.store.Get(ctx, query.OrgID, query.ReportUID)
+	if err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultDeliveriesLimit
+	}
+	return s.store.GetDeliveries(ctx, report.ID, query.Limit)
+}
+
+// deliver renders and emails a report, recording the delivery in the history of the report.
+// Failing to render or email the report is recorded in the delivery and does not return an error.
+func (s *Service) deliver(ctx context.Context, report *dashboardreport.Report, manual bool) (*dashboardreport.Delivery, error) {
+	delivery := &dashboardreport.Delivery{
+		OrgID:      report.OrgID,
+		ReportID:   report.ID,
+		Manual:     manual,
+		Status:     dashboardreport.DeliveryStatusSent,
+		Recipients: report.Recipients,
+		Started:    time.Now().Unix(),
+	}
+
+	if err := s.send(ctx, report); err != nil {
+		s.log.Error("Failed to send dashboard report", "uid", report.UID, "orgId", report.OrgID, "error", err)
+		delivery.Status = dashboardreport.DeliveryStatusFailed
+		delivery.Error = err.Error()
+	}
+	delivery.Finished = time.Now().Unix()
+
+	if err := s.store.InsertDelivery(ctx, delivery); err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+func (s *Service) send(ctx context.Context, report *dashboardreport.Report) error {
+	ownerQuery := models.GetSignedInUserQuery{UserId: report.UserID, OrgId: report.OrgID}
+	if err := s.sqlStore.GetSignedInUser(ctx, &ownerQuery); err != nil {
+		return err
+	}
+	owner := ownerQuery.Result
+	if owner.OrgId != report.OrgID {
+		return errOwnerNotInOrg
+	}
+
+	dashQuery := models.GetDashboardQuery{Uid: report.DashboardUID, OrgId: report.OrgID}
+	if err := s.dashboardService.GetDashboard(ctx, &dashQuery); err != nil {
+		return err
+	}
+	dash := dashQuery.Result
+	if canView, err := guardian.New(ctx, dash.Id, report.OrgID, owner).CanView(); err != nil || !canView {
+		return errOwnerCannotView
+	}
+
+	attachment, err := s.render(ctx, report, owner, dash)
+	if err != nil {
+		return err
+	}
+
+	return s.notifications.SendEmailCommandHandlerSync(ctx, &models.SendEmailCommandSync{
+		SendEmailCommand: models.SendEmailCommand{
+			To:       report.Recipients,
+			Template: "dashboard_report",
+			Data: map[string]interface{}{
+				"ReportName":     report.Name,
+				"DashboardTitle": dash.Title,
+				"DashboardURL":   setting.ToAbsUrl(dashboardPath(report, dash)),
+				"TimeFrom":       report.TimeFrom,
+				"TimeTo":         report.TimeTo,
+			},
+			AttachedFiles: []*models.SendEmailAttachFile{attachment},
+		},
+	})
+}
+
+// Run sends the reports when they are due. Servers in HA setups take a server lock so that
+// a single server sends each report. The reports sent on demand are sent by the server they are queued on.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	go s.sendQueued(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.lockService.LockAndExecute(ctx, "send dashboard reports", schedulerInterval/2, func(ctx context.Context) {
+				s.sendDueReports(ctx, time.Now())
+			})
+			if err != nil {
+				s.log.Error("Failed to send the due dashboard reports", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// sendQueued sends the reports queued by Send until ctx is done.
+func (s *Service) sendQueued(ctx context.Context) {
+	for {
+		select {
+		case report := <-s.sendQueue:
+			if _, err := s.deliver(ctx, report, true); err != nil {
+				s.log.Error("Failed to record dashboard report delivery", "uid", report.UID, "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// IsDisabled returns true when dashboard reports are disabled.
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+func (s *Service) sendDueReports(ctx context.Context, now time.Time) {
+	reports, err := s.store.GetDue(ctx, now.Unix())
+	if err != nil {
+		s.log.Error("Failed to get the due dashboard reports", "error", err)
+		return
+	}
+
+	for _, report := range reports {
+		// the next run is claimed before sending, a report missing several runs is sent once
+		var nextRun int64
+		if next, err := report.NextRunAfter(now); err != nil {
+			s.log.Error("Failed to schedule dashboard report, it will not run again until updated", "uid", report.UID, "error", err)
+		} else {
+			nextRun = next.Unix()
+		}
+
+		claimed, err := s.store.ClaimRun(ctx, report.ID, report.NextRun, nextRun)
+		if err != nil {
+			s.log.Error("Failed to schedule dashboard report", "uid", report.UID, "error", err)
+			continue
+		}
+		if !claimed || nextRun == 0 {
+			continue
+		}
+
+		if _, err := s.deliver(ctx, report, false); err != nil {
+			s.log.Error("Failed to record dashboard report delivery", "uid", report.UID, "error", err)
+		}
+	}
+
+	if s.cfg.HistoryRetention > 0 {
+		if _, err := s.store.DeleteDeliveriesOlderThan(ctx, now.Add(-s.cfg.HistoryRetention).Unix()); err != nil {
+			s.log.Error("Failed to delete expired dashboard report deliveries", "error", err)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package reportimpl
+
+import (
+	"context"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/services/dashboardreport"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+// dashboardUIDsBatchSize is the number of dashboard uids used at once in a query, SQLite limits the number of parameters
+const dashboardUIDsBatchSize = 500
+
+type store interface {
+	Insert(ctx context.Context, report *dashboardreport.Report) error
+	Update(ctx context.Context, report *dashboardreport.Report) error
+	Delete(ctx context.Context, orgID int64, uid string) error
+	Get(ctx context.Context, orgID int64, uid string) (*dashboardreport.Report, error)
+	Search(ctx context.Context, query *dashboardreport.SearchReportsQuery) (*dashboardreport.SearchReportsResult, error)
+	// GetDashboardUIDs returns the uids of the dashboards having reports.
+	GetDashboardUIDs(ctx context.Context, orgID int64) ([]string, error)
+	// GetDue returns the enabled reports scheduled to run at or before now.
+	GetDue(ctx context.Context, now int64) ([]*dashboardreport.Report, error)
+	// ClaimRun moves the next run of a report from its scheduled time to nextRun, it returns false when
+	// another server claimed the run first.
+	ClaimRun(ctx context.Context, id int64, scheduledRun int64, nextRun int64) (bool, error)
+	InsertDelivery(ctx context.Context, delivery *dashboardreport.Delivery) error
+	GetDeliveries(ctx context.Context, reportID int64, limit int) ([]*dashboardreport.Delivery, error)
+	DeleteDeliveriesOlderThan(ctx context.Context, olderThan int64) (int64, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Insert(ctx context.Context, report *dashboardreport.Report) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(report)
+		return err
+	})
+}
+
+func (s *sqlStore) Update(ctx context.Context, report *dashboardreport.Report) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		affected, err := sess.ID(report.ID).AllCols().Update(report)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return dashboardreport.ErrReportNotFound
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var report dashboardreport.Report
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&report)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return dashboardreport.ErrReportNotFound
+		}
+
+		if _, err := sess.Exec("DELETE FROM dashboard_report_delivery WHERE report_id = ?", report.ID); err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM dashboard_report WHERE id = ?", report.ID)
+		return err
+	})
+}
+
+func (s *sqlStore) Get(ctx context.Context, orgID int64, uid string) (*dashboardreport.Report, error) {
+	var report dashboardreport.Report
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&report)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return dashboardreport.ErrReportNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (s *sqlStore) Search(ctx context.Context, query *dashboardreport.SearchReportsQuery) (*dashboardreport.SearchReportsResult, error) {
+	if query.DashboardUIDs != nil {
+		return s.searchByDashboards(ctx, query)
+	}
+
+	result := &dashboardreport.SearchReportsResult{
+		Reports: make([]*dashboardreport.Report, 0),
+		Page:    query.Page,
+		PerPage: query.Limit,
+	}
+
+	where := "org_id = ?"
+	params := []interface{}{query.OrgID}
+	if query.DashboardUID != "" {
+		where += " AND dashboard_uid = ?"
+		params = append(params, query.DashboardUID)
+	}
+
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		findSess := sess.Table("dashboard_report").Where(where, params...)
+		if query.Limit > 0 {
+			findSess.Limit(query.Limit, query.Limit*(query.Page-1))
+		}
+		if err := findSess.Asc("name", "id").Find(&result.Reports); err != nil {
+			return err
+		}
+
+		count, err := sess.Table("dashboard_report").Where(where, params...).Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+		return nil
+	})
+
+	return result, err
+}
+
+// searchByDashboards searches the reports of a list of dashboards. The dashboards are queried in batches to stay
+// below the limit of SQL parameters, so the reports are sorted and paginated once all of them are read.
+func (s *sqlStore) searchByDashboards(ctx context.Context, query *dashboardreport.SearchReportsQuery) (*dashboardreport.SearchReportsResult, error) {
+	reports := make([]*dashboardreport.Report, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for start := 0; start < len(query.DashboardUIDs); start += dashboardUIDsBatchSize {
+			end := start + dashboardUIDsBatchSize
+			if end > len(query.DashboardUIDs) {
+				end = len(query.DashboardUIDs)
+			}
+
+			findSess := sess.Table("dashboard_report").Where("org_id = ?", query.OrgID).In("dashboard_uid", query.DashboardUIDs[start:end])
+			if query.DashboardUID != "" {
+				findSess.And("dashboard_uid = ?", query.DashboardUID)
+			}
+			if err := findSess.Find(&reports); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Name != reports[j].Name {
+			return reports[i].Name < reports[j].Name
+		}
+		return reports[i].ID < reports[j].ID
+	})
+
+	result := &dashboardreport.SearchReportsResult{
+		Reports:    reports,
+		TotalCount: int64(len(reports)),
+		Page:       query.Page,
+		PerPage:    query.Limit,
+	}
+	if query.Limit > 0 {
+		start := query.Limit * (query.Page - 1)
+		if start < 0 {
+			start = 0
+		}
+		if start > len(reports) {
+			start = len(reports)
+		}
+		end := start + query.Limit
+		if end > len(reports) {
+			end = len(reports)
+		}
+		result.Reports = reports[start:end]
+	}
+	return result, nil
+}
+
+func (s *sqlStore) GetDashboardUIDs(ctx context.Context, orgID int64) ([]string, error) {
+	uids := make([]string, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("dashboard_report").Where("org_id = ?", orgID).Distinct("dashboard_uid").Find(&uids)
+	})
+	return uids, err
+}
+
+func (s *sqlStore) GetDue(ctx context.Context, now int64) ([]*dashboardreport.Report, error) {
+	reports := make([]*dashboardreport.Report, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("enabled = ? AND next_run > 0 AND next_run <= ?", s.db.GetDialect().BooleanStr(true), now).
+			Asc("next_run").Find(&reports)
+	})
+	return reports, err
+}
+
+func (s *sqlStore) ClaimRun(ctx context.Context, id int64, scheduledRun int64, nextRun int64) (bool, error) {
+	var claimed bool
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("UPDATE dashboard_report SET next_run = ? WHERE id = ? AND next_run = ?", nextRun, id, scheduledRun)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		claimed = affected == 1
+		return err
+	})
+	return claimed, err
+}
+
+func (s *sqlStore) InsertDelivery(ctx context.Context, delivery *dashboardreport.Delivery) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(delivery)
+		return err
+	})
+}
+
+func (s *sqlStore) GetDeliveries(ctx context.Context, reportID int64, limit int) ([]*dashboardreport.Delivery, error) {
+	deliveries := make([]*dashboardreport.Delivery, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		findSess := sess.Where("report_id = ?", reportID).Desc("started", "id")
+		if limit > 0 {
+			findSess.Limit(limit)
+		}
+		return findSess.Find(&deliveries)
+	})
+	return deliveries, err
+}
+
+func (s *sqlStore) DeleteDeliveriesOlderThan(ctx context.Context, olderThan int64) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM dashboard_report_delivery WHERE started < ?", olderThan)
+		if err != nil {
+			return err
+		}
+
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,138 @@
+package reportimpl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardreport"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationDashboardReportsDataAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ss := sqlstore.InitTestDB(t)
+	reportStore := sqlStore{db: ss}
+	now := time.Now().Unix()
+
+	reports := []*dashboardreport.Report{
+		{UID: "weekly", OrgID: 1, UserID: 1, Name: "Weekly", DashboardUID: "dash-1", Variables: simplejson.New(), Schedule: "0 8 * * 1",
+			Format: dashboardreport.FormatPDF, Recipients: dashboardreport.Recipients{"a@example.com", "b@example.com"}, Enabled: true, NextRun: now - 60},
+		{UID: "daily", OrgID: 1, UserID: 1, Name: "Daily", DashboardUID: "dash-2", Variables: simplejson.New(), Schedule: "0 8 * * *",
+			Format: dashboardreport.FormatPNG, Recipients: dashboardreport.Recipients{"a@example.com"}, Enabled: true, NextRun: now + 3600},
+		{UID: "paused", OrgID: 1, UserID: 1, Name: "Paused", DashboardUID: "dash-1", Variables: simplejson.New(), Schedule: "0 8 * * *",
+			Format: dashboardreport.FormatPDF, Recipients: dashboardreport.Recipients{"a@example.com"}},
+		{UID: "other-org", OrgID: 2, UserID: 1, Name: "Other org", DashboardUID: "dash-1", Variables: simplejson.New(), Schedule: "0 8 * * *",
+			Format: dashboardreport.FormatPDF, Recipients: dashboardreport.Recipients{"a@example.com"}, Enabled: true, NextRun: now - 120},
+	}
+	for _, report := range reports {
+		require.NoError(t, reportStore.Insert(context.Background(), report))
+		require.NotZero(t, report.ID)
+	}
+
+	t.Run("Get should return the report of the org", func(t *testing.T) {
+		report, err := reportStore.Get(context.Background(), 1, "weekly")
+		require.NoError(t, err)
+		require.Equal(t, dashboardreport.Recipients{"a@example.com", "b@example.com"}, report.Recipients)
+
+		_, err = reportStore.Get(context.Background(), 2, "weekly")
+		require.ErrorIs(t, err, dashboardreport.ErrReportNotFound)
+	})
+
+	t.Run("Search should filter by dashboard and paginate", func(t *testing.T) {
+		result, err := reportStore.Search(context.Background(), &dashboardreport.SearchReportsQuery{OrgID: 1, DashboardUID: "dash-1", Page: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), result.TotalCount)
+		require.Len(t, result.Reports, 1)
+		require.Equal(t, "Paused", result.Reports[0].Name)
+	})
+
+	t.Run("Search should filter by the dashboards the user can view", func(t *testing.T) {
+		uids, err := reportStore.GetDashboardUIDs(context.Background(), 1)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"dash-1", "dash-2"}, uids)
+
+		result, err := reportStore.Search(context.Background(), &dashboardreport.SearchReportsQuery{OrgID: 1, DashboardUIDs: []string{"dash-2"}, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalCount)
+		require.Equal(t, "Daily", result.Reports[0].Name)
+
+		result, err = reportStore.Search(context.Background(), &dashboardreport.SearchReportsQuery{OrgID: 1, DashboardUIDs: []string{}, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Zero(t, result.TotalCount)
+		require.Empty(t, result.Reports)
+	})
+
+	t.Run("Search should sort and paginate the reports of more dashboards than a batch", func(t *testing.T) {
+		uids := []string{"dash-2"}
+		for i := 0; i < 2*dashboardUIDsBatchSize; i++ {
+			uids = append(uids, fmt.Sprintf("missing-%d", i))
+		}
+		uids = append(uids, "dash-1")
+
+		result, err := reportStore.Search(context.Background(), &dashboardreport.SearchReportsQuery{OrgID: 1, DashboardUIDs: uids, Page: 2, Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.Reports, 1)
+		require.Equal(t, "Paused", result.Reports[0].Name)
+
+		result, err = reportStore.Search(context.Background(), &dashboardreport.SearchReportsQuery{OrgID: 1, DashboardUIDs: uids, Page: 4, Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.TotalCount)
+		require.Empty(t, result.Reports)
+	})
+
+	t.Run("GetDue should return the enabled reports due to run", func(t *testing.T) {
+		due, err := reportStore.GetDue(context.Background(), now)
+		require.NoError(t, err)
+		require.Len(t, due, 2)
+		require.Equal(t, "other-org", due[0].UID)
+		require.Equal(t, "weekly", due[1].UID)
+	})
+
+	t.Run("ClaimRun should only claim a run once", func(t *testing.T) {
+		claimed, err := reportStore.ClaimRun(context.Background(), reports[0].ID, now-60, now+600)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = reportStore.ClaimRun(context.Background(), reports[0].ID, now-60, now+600)
+		require.NoError(t, err)
+		require.False(t, claimed)
+	})
+
+	t.Run("Deliveries should be returned newest first and expire", func(t *testing.T) {
+		for _, started := range []int64{now - 100*24*60*60, now - 60, now} {
+			require.NoError(t, reportStore.InsertDelivery(context.Background(), &dashboardreport.Delivery{
+				OrgID: 1, ReportID: reports[0].ID, Status: dashboardreport.DeliveryStatusSent, Started: started, Finished: started,
+			}))
+		}
+
+		deliveries, err := reportStore.GetDeliveries(context.Background(), reports[0].ID, 2)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		require.Equal(t, now, deliveries[0].Started)
+
+		deleted, err := reportStore.DeleteDeliveriesOlderThan(context.Background(), now-90*24*60*60)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+	})
+
+	t.Run("Delete should delete the report and its deliveries", func(t *testing.T) {
+		require.NoError(t, reportStore.Delete(context.Background(), 1, "weekly"))
+		_, err := reportStore.Get(context.Background(), 1, "weekly")
+		require.ErrorIs(t, err, dashboardreport.ErrReportNotFound)
+
+		deliveries, err := reportStore.GetDeliveries(context.Background(), reports[0].ID, 0)
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+
+		require.ErrorIs(t, reportStore.Delete(context.Background(), 1, "weekly"), dashboardreport.ErrReportNotFound)
+	})
+}
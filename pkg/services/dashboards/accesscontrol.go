@@ -2,6 +2,7 @@ package dashboards
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -82,6 +83,40 @@ func NewFolderIDScopeResolver(db Store) (string, ac.ScopeAttributeResolver) {
 	})
 }
 
+// NewFolderUIDScopeResolver provides an ScopeAttributeResolver that is able to convert a scope prefixed with "folders:uid:"
+// into uid based scopes for the folder and all its parent folders, so that permissions on a folder apply to its subfolders.
+func NewFolderUIDScopeResolver(db Store) (string, ac.ScopeAttributeResolver) {
+	prefix := ScopeFoldersProvider.GetResourceScopeUID("")
+	return prefix, ac.ScopeAttributeResolverFunc(func(ctx context.Context, orgID int64, scope string) ([]string, error) {
+		if !strings.HasPrefix(scope, prefix) {
+			return nil, ac.ErrInvalidScope
+		}
+
+		uid, err := ac.ParseScopeUID(scope)
+		if err != nil {
+			return nil, err
+		}
+
+		if uid == "*" || uid == ac.GeneralFolderUID {
+			return []string{scope}, nil
+		}
+
+		folder, err := db.GetFolderByUID(ctx, orgID, uid)
+		if err != nil {
+			if errors.Is(err, models.ErrFolderNotFound) {
+				return []string{scope}, nil
+			}
+			return nil, err
+		}
+
+		parentScopes, err := resolveParentFolderScopes(ctx, db, orgID, folder)
+		if err != nil {
+			return nil, err
+		}
+		return append([]string{scope}, parentScopes...), nil
+	})
+}
+
 // NewDashboardIDScopeResolver provides an ScopeAttributeResolver that is able to convert a scope prefixed with "dashboards:id:"
 // into uid based scopes for both dashboard and folder
 func NewDashboardIDScopeResolver(db Store) (string, ac.ScopeAttributeResolver) {
@@ -129,18 +164,39 @@ func NewDashboardUIDScopeResolver(db Store) (string, ac.ScopeAttributeResolver)
 }
 
 func resolveDashboardScope(ctx context.Context, db Store, orgID int64, dashboard *models.Dashboard) ([]string, error) {
-	var folderUID string
 	if dashboard.FolderId == 0 {
-		folderUID = ac.GeneralFolderUID
-	} else {
-		folder, err := db.GetFolderByID(ctx, orgID, dashboard.FolderId)
-		if err != nil {
-			return nil, err
-		}
-		folderUID = folder.Uid
+		return []string{
+			ScopeDashboardsProvider.GetResourceScopeUID(dashboard.Uid),
+			ScopeFoldersProvider.GetResourceScopeUID(ac.GeneralFolderUID),
+		}, nil
 	}
-	return []string{
+
+	folder, err := db.GetFolderByID(ctx, orgID, dashboard.FolderId)
+	if err != nil {
+		return nil, err
+	}
+	parentScopes, err := resolveParentFolderScopes(ctx, db, orgID, folder)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{
 		ScopeDashboardsProvider.GetResourceScopeUID(dashboard.Uid),
-		ScopeFoldersProvider.GetResourceScopeUID(folderUID),
-	}, nil
+		ScopeFoldersProvider.GetResourceScopeUID(folder.Uid),
+	}, parentScopes...), nil
+}
+
+// resolveParentFolderScopes returns the uid based scopes of the parent folders of a nested folder.
+func resolveParentFolderScopes(ctx context.Context, db Store, orgID int64, folder *models.Folder) ([]string, error) {
+	if folder.ParentId == 0 {
+		return nil, nil
+	}
+	parents, err := db.GetFolderParents(ctx, orgID, folder.Id)
+	if err != nil {
+		return nil, err
+	}
+	scopes := make([]string, 0, len(parents))
+	for _, parent := range parents {
+		scopes = append(scopes, ScopeFoldersProvider.GetResourceScopeUID(parent.Uid))
+	}
+	return scopes, nil
 }
@@ -2,32 +2,46 @@ package dashboards
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards/schemaversion"
 )
 
 //go:generate mockery --name DashboardService --structname FakeDashboardService --inpackage --filename dashboard_service_mock.go
 // DashboardService is a service for operating on dashboards.
 type DashboardService interface {
-	BuildPublicDashboardMetricRequest(ctx context.Context, publicDashboardUid string, panelId int64) (dtos.MetricRequest, error)
+	BuildPublicDashboardMetricRequest(ctx context.Context, publicDashboardUid string, panelId int64, reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error)
 	BuildSaveDashboardCommand(ctx context.Context, dto *SaveDashboardDTO, shouldValidateAlerts bool, validateProvisionedDashboard bool) (*models.SaveDashboardCommand, error)
 	DeleteDashboard(ctx context.Context, dashboardId int64, orgId int64) error
+	DeleteDashboardFromTrash(ctx context.Context, orgID int64, id int64) error
+	DeleteExpiredDashboardTrash(ctx context.Context) (int64, error)
 	FindDashboards(ctx context.Context, query *models.FindPersistedDashboardsQuery) ([]DashboardSearchProjection, error)
 	GetDashboard(ctx context.Context, query *models.GetDashboardQuery) error
 	GetDashboardAclInfoList(ctx context.Context, query *models.GetDashboardAclInfoListQuery) error
 	GetDashboards(ctx context.Context, query *models.GetDashboardsQuery) error
 	GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error
+	GetDashboardTrash(ctx context.Context, query *models.GetDashboardTrashQuery) error
+	GetDashboardTrashItem(ctx context.Context, query *models.GetDashboardTrashItemQuery) error
 	GetDashboardUIDById(ctx context.Context, query *models.GetDashboardRefByIdQuery) error
 	GetPublicDashboard(ctx context.Context, publicDashboardUid string) (*models.Dashboard, error)
+	GetPublicDashboardAnnotations(ctx context.Context, publicDashboardUid string, reqDTO models.PublicDashboardQueryDTO) ([]*models.PublicDashboardAnnotation, error)
 	GetPublicDashboardConfig(ctx context.Context, orgId int64, dashboardUid string) (*models.PublicDashboardConfig, error)
 	HasAdminPermissionInFolders(ctx context.Context, query *models.HasAdminPermissionInFoldersQuery) error
 	HasEditPermissionInFolders(ctx context.Context, query *models.HasEditPermissionInFoldersQuery) error
 	ImportDashboard(ctx context.Context, dto *SaveDashboardDTO) (*models.Dashboard, error)
 	MakeUserAdmin(ctx context.Context, orgID int64, userID, dashboardID int64, setViewAndEditPermissions bool) error
+	// MigrateDashboardSchema upgrades the model of a dashboard to the latest schema version.
+	MigrateDashboardSchema(ctx context.Context, dash *models.Dashboard) error
+	// MigrateDashboardsSchema upgrades the stored dashboards to the latest schema version.
+	MigrateDashboardsSchema(ctx context.Context, cmd *models.MigrateDashboardsSchemaCommand) error
+	RestoreDashboardFromTrash(ctx context.Context, cmd *models.RestoreDashboardFromTrashCommand) error
 	SaveDashboard(ctx context.Context, dto *SaveDashboardDTO, allowUiUpdate bool) (*models.Dashboard, error)
 	SavePublicDashboardConfig(ctx context.Context, dto *SavePublicDashboardConfigDTO) (*models.PublicDashboardConfig, error)
 	SearchDashboards(ctx context.Context, query *models.FindPersistedDashboardsQuery) error
+	// TrashDashboard deletes a dashboard, keeping it in the trash when the trash is enabled.
+	TrashDashboard(ctx context.Context, user *models.SignedInUser, dashboardId int64, orgId int64) error
 	UpdateDashboardACL(ctx context.Context, uid int64, items []*models.DashboardAcl) error
 }
 
@@ -53,6 +67,9 @@ type DashboardProvisioningService interface {
 // Store is a dashboard store.
 type Store interface {
 	DeleteDashboard(ctx context.Context, cmd *models.DeleteDashboardCommand) error
+	DeleteDashboardFromTrash(ctx context.Context, cmd *models.DeleteDashboardFromTrashCommand) error
+	// DeleteExpiredDashboardTrash permanently deletes the items that were moved to the trash before olderThan.
+	DeleteExpiredDashboardTrash(ctx context.Context, olderThan time.Time) (int64, error)
 	DeleteOrphanedProvisionedDashboards(ctx context.Context, cmd *models.DeleteOrphanedProvisionedDashboardsCommand) error
 	FindDashboards(ctx context.Context, query *models.FindPersistedDashboardsQuery) ([]DashboardSearchProjection, error)
 	GetDashboard(ctx context.Context, query *models.GetDashboardQuery) (*models.Dashboard, error)
@@ -62,6 +79,10 @@ type Store interface {
 	// GetDashboardsByPluginID retrieves dashboards identified by plugin.
 	GetDashboardsByPluginID(ctx context.Context, query *models.GetDashboardsByPluginIdQuery) error
 	GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error
+	GetDashboardTrash(ctx context.Context, query *models.GetDashboardTrashQuery) error
+	GetDashboardTrashItem(ctx context.Context, query *models.GetDashboardTrashItemQuery) error
+	// GetDataSourceLookup returns a lookup of the data sources of an organization used by the schema migrations.
+	GetDataSourceLookup(ctx context.Context, orgID int64) (schemaversion.DataSourceLookup, error)
 	GetProvisionedDashboardData(name string) ([]*models.DashboardProvisioning, error)
 	GetProvisionedDataByDashboardID(dashboardID int64) (*models.DashboardProvisioning, error)
 	GetProvisionedDataByDashboardUID(orgID int64, dashboardUID string) (*models.DashboardProvisioning, error)
@@ -69,6 +90,10 @@ type Store interface {
 	GetPublicDashboard(uid string) (*models.PublicDashboard, *models.Dashboard, error)
 	HasAdminPermissionInFolders(ctx context.Context, query *models.HasAdminPermissionInFoldersQuery) error
 	HasEditPermissionInFolders(ctx context.Context, query *models.HasEditPermissionInFoldersQuery) error
+	// MigrateDashboardsSchema upgrades the stored dashboards to the latest schema version.
+	MigrateDashboardsSchema(ctx context.Context, cmd *models.MigrateDashboardsSchemaCommand) error
+	// RestoreDashboardFromTrash restores a dashboard or folder with its versions and permissions.
+	RestoreDashboardFromTrash(ctx context.Context, cmd *models.RestoreDashboardFromTrashCommand) error
 	// SaveAlerts saves dashboard alerts.
 	SaveAlerts(ctx context.Context, dashID int64, alerts []*models.Alert) error
 	SaveDashboard(cmd models.SaveDashboardCommand) (*models.Dashboard, error)
@@ -91,4 +116,8 @@ type FolderStore interface {
 	GetFolderByUID(ctx context.Context, orgID int64, uid string) (*models.Folder, error)
 	// GetFolderByID retrieves a folder by its ID
 	GetFolderByID(ctx context.Context, orgID int64, id int64) (*models.Folder, error)
+	// GetFolderParents retrieves the parent folders of a nested folder, starting with its root folder
+	GetFolderParents(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error)
+	// GetFolderChildren retrieves the folders directly in a folder
+	GetFolderChildren(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error)
 }
@@ -18,20 +18,20 @@ type FakeDashboardService struct {
 	mock.Mock
 }
 
-// BuildPublicDashboardMetricRequest provides a mock function with given fields: ctx, publicDashboardUid, panelId
-func (_m *FakeDashboardService) BuildPublicDashboardMetricRequest(ctx context.Context, publicDashboardUid string, panelId int64) (dtos.MetricRequest, error) {
-	ret := _m.Called(ctx, publicDashboardUid, panelId)
+// BuildPublicDashboardMetricRequest provides a mock function with given fields: ctx, publicDashboardUid, panelId, reqDTO
+func (_m *FakeDashboardService) BuildPublicDashboardMetricRequest(ctx context.Context, publicDashboardUid string, panelId int64, reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
+	ret := _m.Called(ctx, publicDashboardUid, panelId, reqDTO)
 
 	var r0 dtos.MetricRequest
-	if rf, ok := ret.Get(0).(func(context.Context, string, int64) dtos.MetricRequest); ok {
-		r0 = rf(ctx, publicDashboardUid, panelId)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, models.PublicDashboardQueryDTO) dtos.MetricRequest); ok {
+		r0 = rf(ctx, publicDashboardUid, panelId, reqDTO)
 	} else {
 		r0 = ret.Get(0).(dtos.MetricRequest)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
-		r1 = rf(ctx, publicDashboardUid, panelId)
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, models.PublicDashboardQueryDTO) error); ok {
+		r1 = rf(ctx, publicDashboardUid, panelId, reqDTO)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -76,6 +76,41 @@ func (_m *FakeDashboardService) DeleteDashboard(ctx context.Context, dashboardId
 	return r0
 }
 
+// DeleteDashboardFromTrash provides a mock function with given fields: ctx, orgID, id
+func (_m *FakeDashboardService) DeleteDashboardFromTrash(ctx context.Context, orgID int64, id int64) error {
+	ret := _m.Called(ctx, orgID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, orgID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpiredDashboardTrash provides a mock function with given fields: ctx
+func (_m *FakeDashboardService) DeleteExpiredDashboardTrash(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindDashboards provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardService) FindDashboards(ctx context.Context, query *models.FindPersistedDashboardsQuery) ([]DashboardSearchProjection, error) {
 	ret := _m.Called(ctx, query)
@@ -141,6 +176,34 @@ func (_m *FakeDashboardService) GetDashboardTags(ctx context.Context, query *mod
 	return r0
 }
 
+// GetDashboardTrash provides a mock function with given fields: ctx, query
+func (_m *FakeDashboardService) GetDashboardTrash(ctx context.Context, query *models.GetDashboardTrashQuery) error {
+	ret := _m.Called(ctx, query)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetDashboardTrashQuery) error); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDashboardTrashItem provides a mock function with given fields: ctx, query
+func (_m *FakeDashboardService) GetDashboardTrashItem(ctx context.Context, query *models.GetDashboardTrashItemQuery) error {
+	ret := _m.Called(ctx, query)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetDashboardTrashItemQuery) error); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetDashboardUIDById provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardService) GetDashboardUIDById(ctx context.Context, query *models.GetDashboardRefByIdQuery) error {
 	ret := _m.Called(ctx, query)
@@ -192,6 +255,29 @@ func (_m *FakeDashboardService) GetPublicDashboard(ctx context.Context, publicDa
 	return r0, r1
 }
 
+// GetPublicDashboardAnnotations provides a mock function with given fields: ctx, publicDashboardUid, reqDTO
+func (_m *FakeDashboardService) GetPublicDashboardAnnotations(ctx context.Context, publicDashboardUid string, reqDTO models.PublicDashboardQueryDTO) ([]*models.PublicDashboardAnnotation, error) {
+	ret := _m.Called(ctx, publicDashboardUid, reqDTO)
+
+	var r0 []*models.PublicDashboardAnnotation
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.PublicDashboardQueryDTO) []*models.PublicDashboardAnnotation); ok {
+		r0 = rf(ctx, publicDashboardUid, reqDTO)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PublicDashboardAnnotation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.PublicDashboardQueryDTO) error); ok {
+		r1 = rf(ctx, publicDashboardUid, reqDTO)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPublicDashboardConfig provides a mock function with given fields: ctx, orgId, dashboardUid
 func (_m *FakeDashboardService) GetPublicDashboardConfig(ctx context.Context, orgId int64, dashboardUid string) (*models.PublicDashboardConfig, error) {
 	ret := _m.Called(ctx, orgId, dashboardUid)
@@ -280,6 +366,48 @@ func (_m *FakeDashboardService) MakeUserAdmin(ctx context.Context, orgID int64,
 	return r0
 }
 
+// MigrateDashboardSchema provides a mock function with given fields: ctx, dash
+func (_m *FakeDashboardService) MigrateDashboardSchema(ctx context.Context, dash *models.Dashboard) error {
+	ret := _m.Called(ctx, dash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Dashboard) error); ok {
+		r0 = rf(ctx, dash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MigrateDashboardsSchema provides a mock function with given fields: ctx, cmd
+func (_m *FakeDashboardService) MigrateDashboardsSchema(ctx context.Context, cmd *models.MigrateDashboardsSchemaCommand) error {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.MigrateDashboardsSchemaCommand) error); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreDashboardFromTrash provides a mock function with given fields: ctx, cmd
+func (_m *FakeDashboardService) RestoreDashboardFromTrash(ctx context.Context, cmd *models.RestoreDashboardFromTrashCommand) error {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.RestoreDashboardFromTrashCommand) error); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveDashboard provides a mock function with given fields: ctx, dto, allowUiUpdate
 func (_m *FakeDashboardService) SaveDashboard(ctx context.Context, dto *SaveDashboardDTO, allowUiUpdate bool) (*models.Dashboard, error) {
 	ret := _m.Called(ctx, dto, allowUiUpdate)
@@ -340,6 +468,20 @@ func (_m *FakeDashboardService) SearchDashboards(ctx context.Context, query *mod
 	return r0
 }
 
+// TrashDashboard provides a mock function with given fields: ctx, user, dashboardId, orgId
+func (_m *FakeDashboardService) TrashDashboard(ctx context.Context, user *models.SignedInUser, dashboardId int64, orgId int64) error {
+	ret := _m.Called(ctx, user, dashboardId, orgId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SignedInUser, int64, int64) error); ok {
+		r0 = rf(ctx, user, dashboardId, orgId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateDashboardACL provides a mock function with given fields: ctx, uid, items
 func (_m *FakeDashboardService) UpdateDashboardACL(ctx context.Context, uid int64, items []*models.DashboardAcl) error {
 	ret := _m.Called(ctx, uid, items)
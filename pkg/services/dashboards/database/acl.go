@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -10,7 +11,7 @@ import (
 // GetDashboardAclInfoList returns a list of permissions for a dashboard. They can be fetched from three
 // different places.
 // 1) Permissions for the dashboard
-// 2) permissions for its parent folder
+// 2) permissions for its parent folder, and the parents of that folder when folders are nested
 // 3) if no specific permissions have been set for the dashboard or its parent folder then get the default permissions
 func (d *DashboardStore) GetDashboardAclInfoList(ctx context.Context, query *models.GetDashboardAclInfoListQuery) error {
 	outerErr := d.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
@@ -41,8 +42,22 @@ func (d *DashboardStore) GetDashboardAclInfoList(ctx context.Context, query *mod
 			return dbSession.SQL(sql).Find(&query.Result)
 		}
 
+		ancestorIDs, err := getAncestorFolderIDs(dbSession, query.OrgID, query.DashboardID)
+		if err != nil {
+			return err
+		}
+		ancestorFilter := ""
+		params := make([]interface{}, 0, len(ancestorIDs)+2)
+		if len(ancestorIDs) > 0 {
+			ancestorFilter = "da.dashboard_id IN (?" + strings.Repeat(",?", len(ancestorIDs)-1) + ") OR"
+			for _, id := range ancestorIDs {
+				params = append(params, id)
+			}
+		}
+		params = append(params, query.OrgID, query.DashboardID)
+
 		rawSQL := `
-			-- get permissions for the dashboard and its parent folder
+			-- get permissions for the dashboard and its parent folders
 			SELECT
 				da.id,
 				da.org_id,
@@ -61,12 +76,13 @@ func (d *DashboardStore) GetDashboardAclInfoList(ctx context.Context, query *mod
 				d.slug,
 				d.uid,
 				d.is_folder,
-				CASE WHEN (da.dashboard_id = -1 AND d.folder_id > 0) OR da.dashboard_id = d.folder_id THEN ` + d.dialect.BooleanStr(true) + ` ELSE ` + falseStr + ` END AS inherited
+				CASE WHEN (da.dashboard_id = -1 AND d.folder_id > 0) OR (da.dashboard_id > 0 AND da.dashboard_id <> d.id) THEN ` + d.dialect.BooleanStr(true) + ` ELSE ` + falseStr + ` END AS inherited
 			FROM dashboard as d
 				LEFT JOIN dashboard folder on folder.id = d.folder_id
 				LEFT JOIN dashboard_acl AS da ON
 				da.dashboard_id = d.id OR
 				da.dashboard_id = d.folder_id OR
+				` + ancestorFilter + `
 				(
 					-- include default permissions -->
 					da.org_id = -1 AND (
@@ -80,7 +96,7 @@ func (d *DashboardStore) GetDashboardAclInfoList(ctx context.Context, query *mod
 			ORDER BY da.id ASC
 			`
 
-		return dbSession.SQL(rawSQL, query.OrgID, query.DashboardID).Find(&query.Result)
+		return dbSession.SQL(rawSQL, params...).Find(&query.Result)
 	})
 
 	if outerErr != nil {
@@ -94,6 +110,27 @@ func (d *DashboardStore) GetDashboardAclInfoList(ctx context.Context, query *mod
 	return nil
 }
 
+// getAncestorFolderIDs returns the IDs of the folders above the direct parent folder of a dashboard or folder.
+// It is empty unless folders are nested.
+func getAncestorFolderIDs(sess *sqlstore.DBSession, orgID, dashboardID int64) ([]int64, error) {
+	var ids []int64
+	var folderID int64
+	if _, err := sess.Table("dashboard").Where("org_id = ? AND id = ?", orgID, dashboardID).Cols("folder_id").Get(&folderID); err != nil {
+		return nil, err
+	}
+	for folderID > 0 && len(ids) <= models.MaxNestedFolderDepth {
+		var parentID int64
+		if _, err := sess.Table("dashboard").Where("org_id = ? AND id = ?", orgID, folderID).Cols("folder_id").Get(&parentID); err != nil {
+			return nil, err
+		}
+		if parentID > 0 {
+			ids = append(ids, parentID)
+		}
+		folderID = parentID
+	}
+	return ids, nil
+}
+
 // HasEditPermissionInFolders validates that an user have access to a certain folder
 func (d *DashboardStore) HasEditPermissionInFolders(ctx context.Context, query *models.HasEditPermissionInFoldersQuery) error {
 	return d.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
@@ -64,7 +64,7 @@ func (d *DashboardStore) GetFolderByTitle(ctx context.Context, orgID int64, titl
 	}
 
 	// there is a unique constraint on org_id, folder_id, title
-	// there are no nested folders so the parent folder id is always 0
+	// folders are looked up by title at the root level only, nested folders can share titles
 	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Title: title}
 	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Where("folder_id=0").Get(&dashboard)
@@ -82,9 +82,24 @@ func (d *DashboardStore) GetFolderByTitle(ctx context.Context, orgID int64, titl
 }
 
 func (d *DashboardStore) GetFolderByID(ctx context.Context, orgID int64, id int64) (*models.Folder, error) {
-	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Id: id}
-	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Where("folder_id=0").Get(&dashboard)
+	dashboard := models.Dashboard{OrgId: orgID, Id: id}
+	return d.getFolder(ctx, &dashboard)
+}
+
+func (d *DashboardStore) GetFolderByUID(ctx context.Context, orgID int64, uid string) (*models.Folder, error) {
+	if uid == "" {
+		return nil, models.ErrDashboardIdentifierNotSet
+	}
+
+	dashboard := models.Dashboard{OrgId: orgID, Uid: uid}
+	return d.getFolder(ctx, &dashboard)
+}
+
+// getFolder returns the folder matching the non-empty fields of dashboard, at any level of the folder tree.
+func (d *DashboardStore) getFolder(ctx context.Context, dashboard *models.Dashboard) (*models.Folder, error) {
+	var folder *models.Folder
+	err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Get(dashboard)
 		if err != nil {
 			return err
 		}
@@ -93,36 +108,95 @@ func (d *DashboardStore) GetFolderByID(ctx context.Context, orgID int64, id int6
 		}
 		dashboard.SetId(dashboard.Id)
 		dashboard.SetUid(dashboard.Uid)
-		return nil
+		folder = models.DashboardToFolder(dashboard)
+		return setParentUID(sess, folder)
 	})
 	if err != nil {
 		return nil, err
 	}
-	return models.DashboardToFolder(&dashboard), nil
+	return folder, nil
 }
 
-func (d *DashboardStore) GetFolderByUID(ctx context.Context, orgID int64, uid string) (*models.Folder, error) {
-	if uid == "" {
-		return nil, models.ErrDashboardIdentifierNotSet
+// setParentUID sets the UID of the parent folder of a nested folder.
+func setParentUID(sess *sqlstore.DBSession, folder *models.Folder) error {
+	if folder.ParentId == 0 {
+		return nil
 	}
+	var parentUID string
+	has, err := sess.Table("dashboard").Where("id = ?", folder.ParentId).Cols("uid").Get(&parentUID)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return models.ErrFolderNotFound
+	}
+	folder.ParentUid = parentUID
+	return nil
+}
 
-	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Uid: uid}
-	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		has, err := sess.Table(&models.Dashboard{}).Where("is_folder = " + d.sqlStore.Dialect.BooleanStr(true)).Where("folder_id=0").Get(&dashboard)
+// GetFolderParents returns the parent folders of the folder with the given ID, starting with its root folder.
+// The result is empty for root folders.
+func (d *DashboardStore) GetFolderParents(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error) {
+	var parents []*models.Folder
+	err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var folder models.Dashboard
+		has, err := sess.Where("org_id = ? AND id = ? AND is_folder = ?", orgID, id, d.dialect.BooleanStr(true)).Get(&folder)
 		if err != nil {
 			return err
 		}
 		if !has {
 			return models.ErrFolderNotFound
 		}
-		dashboard.SetId(dashboard.Id)
-		dashboard.SetUid(dashboard.Uid)
+
+		// the depth of the tree is limited, so a corrupt tree with a cycle cannot loop forever
+		for parentID := folder.FolderId; parentID > 0 && len(parents) < models.MaxNestedFolderDepth; {
+			var parent models.Dashboard
+			has, err := sess.Where("org_id = ? AND id = ? AND is_folder = ?", orgID, parentID, d.dialect.BooleanStr(true)).Get(&parent)
+			if err != nil {
+				return err
+			}
+			if !has {
+				return models.ErrFolderNotFound
+			}
+			parents = append([]*models.Folder{models.DashboardToFolder(&parent)}, parents...)
+			parentID = parent.FolderId
+		}
+		for _, parent := range parents {
+			if err := setParentUID(sess, parent); err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return models.DashboardToFolder(&dashboard), nil
+	return parents, nil
+}
+
+// GetFolderChildren returns the folders directly in the folder with the given ID, sorted by title.
+// The root folders are returned for the ID 0.
+func (d *DashboardStore) GetFolderChildren(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error) {
+	var children []*models.Folder
+	err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var dashboards []*models.Dashboard
+		if err := sess.Where("org_id = ? AND folder_id = ? AND is_folder = ?", orgID, id, d.dialect.BooleanStr(true)).
+			OrderBy("title ASC").Find(&dashboards); err != nil {
+			return err
+		}
+		for _, dash := range dashboards {
+			folder := models.DashboardToFolder(dash)
+			if err := setParentUID(sess, folder); err != nil {
+				return err
+			}
+			children = append(children, folder)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return children, nil
 }
 
 func (d *DashboardStore) GetProvisionedDataByDashboardID(dashboardID int64) (*models.DashboardProvisioning, error) {
@@ -342,7 +416,7 @@ func getExistingDashboardByIdOrUidForUpdate(sess *sqlstore.DBSession, dash *mode
 		return isParentFolderChanged, models.ErrDashboardTypeMismatch
 	}
 
-	if !dash.IsFolder && dash.FolderId != existing.FolderId {
+	if dash.FolderId != existing.FolderId {
 		isParentFolderChanged = true
 	}
 
@@ -366,8 +440,15 @@ func getExistingDashboardByIdOrUidForUpdate(sess *sqlstore.DBSession, dash *mode
 func getExistingDashboardByTitleAndFolder(sess *sqlstore.DBSession, dash *models.Dashboard, dialect migrator.Dialect, overwrite,
 	isParentFolderChanged bool) (bool, error) {
 	var existing models.Dashboard
-	exists, err := sess.Where("org_id=? AND slug=? AND (is_folder=? OR folder_id=?)", dash.OrgId, dash.Slug,
-		dialect.BooleanStr(true), dash.FolderId).Get(&existing)
+	var exists bool
+	var err error
+	if dash.IsFolder && dash.FolderId > 0 {
+		// nested folders only need a unique name within their parent folder
+		exists, err = sess.Where("org_id=? AND slug=? AND folder_id=?", dash.OrgId, dash.Slug, dash.FolderId).Get(&existing)
+	} else {
+		exists, err = sess.Where("org_id=? AND slug=? AND ((is_folder=? AND folder_id=0) OR folder_id=?)", dash.OrgId, dash.Slug,
+			dialect.BooleanStr(true), dash.FolderId).Get(&existing)
+	}
 	if err != nil {
 		return isParentFolderChanged, fmt.Errorf("SQL query for existing dashboard by org ID or folder ID failed: %w", err)
 	}
@@ -712,6 +793,12 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 		return models.ErrDashboardNotFound
 	}
 
+	if cmd.MoveToTrash {
+		if err := d.moveToTrash(sess, &dashboard, cmd.DeletedBy); err != nil {
+			return err
+		}
+	}
+
 	deletes := []string{
 		"DELETE FROM dashboard_tag WHERE dashboard_id = ? ",
 		"DELETE FROM star WHERE dashboard_id = ? ",
@@ -724,13 +811,27 @@ func (d *DashboardStore) deleteDashboard(cmd *models.DeleteDashboardCommand, ses
 	}
 
 	if dashboard.IsFolder {
+		// delete the subfolders first, with their dashboards and alert rules
+		var subfolderIDs []int64
+		err := sess.Table("dashboard").Where("org_id = ? AND folder_id = ? AND is_folder = ?", dashboard.OrgId, dashboard.Id, d.dialect.BooleanStr(true)).
+			Cols("id").Find(&subfolderIDs)
+		if err != nil {
+			return err
+		}
+		for _, id := range subfolderIDs {
+			subfolderCmd := models.DeleteDashboardCommand{OrgId: cmd.OrgId, Id: id, ForceDeleteFolderRules: cmd.ForceDeleteFolderRules}
+			if err := d.deleteDashboard(&subfolderCmd, sess); err != nil {
+				return err
+			}
+		}
+
 		deletes = append(deletes, "DELETE FROM dashboard WHERE folder_id = ?")
 
 		var dashIds []struct {
 			Id  int64
 			Uid string
 		}
-		err := sess.SQL("SELECT id, uid FROM dashboard WHERE folder_id = ?", dashboard.Id).Find(&dashIds)
+		err = sess.SQL("SELECT id, uid FROM dashboard WHERE folder_id = ?", dashboard.Id).Find(&dashIds)
 		if err != nil {
 			return err
 		}
@@ -900,14 +1001,15 @@ func (d *DashboardStore) FindDashboards(ctx context.Context, query *models.FindP
 			Dialect:         d.dialect,
 			UserId:          query.SignedInUser.UserId,
 			PermissionLevel: query.Permission,
+			NestedFolders:   permissions.NestedFoldersEnabled(d.sqlStore.Cfg),
 		},
 	}
 
 	if !ac.IsDisabled(d.sqlStore.Cfg) {
 		// if access control is enabled, overwrite the filters so far
-		filters = []interface{}{
-			permissions.NewAccessControlDashboardPermissionFilter(query.SignedInUser, query.Permission, query.Type),
-		}
+		acFilter := permissions.NewAccessControlDashboardPermissionFilter(query.SignedInUser, query.Permission, query.Type)
+		acFilter.NestedFolders = permissions.NestedFoldersEnabled(d.sqlStore.Cfg)
+		filters = []interface{}{acFilter}
 	}
 
 	for _, filter := range query.Sort.Filter {
@@ -945,7 +1047,11 @@ func (d *DashboardStore) FindDashboards(ctx context.Context, query *models.FindP
 	}
 
 	if len(query.FolderIds) > 0 {
-		filters = append(filters, searchstore.FolderFilter{IDs: query.FolderIds})
+		if query.IncludeSubfolders && permissions.NestedFoldersEnabled(d.sqlStore.Cfg) {
+			filters = append(filters, searchstore.NestedFolderFilter{IDs: query.FolderIds, Depth: models.MaxNestedFolderDepth})
+		} else {
+			filters = append(filters, searchstore.FolderFilter{IDs: query.FolderIds})
+		}
 	}
 
 	var res []dashboards.DashboardSearchProjection
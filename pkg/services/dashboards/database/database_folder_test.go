@@ -523,6 +523,81 @@ func TestIntegrationDashboardFolderDataAccess(t *testing.T) {
 				require.ErrorIs(t, err, models.ErrFolderNotFound)
 			})
 		})
+
+		t.Run("Nested folders", func(t *testing.T) {
+			var orgId int64 = 1
+			sqlStore := sqlstore.InitTestDB(t)
+			dashboardStore := ProvideDashboardStore(sqlStore)
+			root := insertTestDashboard(t, dashboardStore, "Root", orgId, 0, true)
+			child := insertTestDashboard(t, dashboardStore, "Child", orgId, root.Id, true)
+			grandchild := insertTestDashboard(t, dashboardStore, "Grandchild", orgId, child.Id, true)
+			dash := insertTestDashboard(t, dashboardStore, "Nested dashboard", orgId, grandchild.Id, false)
+
+			t.Run("should return subfolder with parent UID", func(t *testing.T) {
+				f, err := dashboardStore.GetFolderByUID(context.Background(), orgId, grandchild.Uid)
+				require.NoError(t, err)
+				require.Equal(t, child.Id, f.ParentId)
+				require.Equal(t, child.Uid, f.ParentUid)
+			})
+
+			t.Run("should return parents starting with the root folder", func(t *testing.T) {
+				parents, err := dashboardStore.GetFolderParents(context.Background(), orgId, grandchild.Id)
+				require.NoError(t, err)
+				require.Len(t, parents, 2)
+				require.Equal(t, root.Uid, parents[0].Uid)
+				require.Equal(t, child.Uid, parents[1].Uid)
+				require.Equal(t, root.Uid, parents[1].ParentUid)
+
+				parents, err = dashboardStore.GetFolderParents(context.Background(), orgId, root.Id)
+				require.NoError(t, err)
+				require.Empty(t, parents)
+			})
+
+			t.Run("should return children", func(t *testing.T) {
+				children, err := dashboardStore.GetFolderChildren(context.Background(), orgId, root.Id)
+				require.NoError(t, err)
+				require.Len(t, children, 1)
+				require.Equal(t, child.Uid, children[0].Uid)
+			})
+
+			t.Run("should allow the same folder title in different parents", func(t *testing.T) {
+				insertTestDashboard(t, dashboardStore, "Child", orgId, child.Id, true)
+			})
+
+			t.Run("should include permissions of all parent folders", func(t *testing.T) {
+				editorRole := models.ROLE_EDITOR
+				err := updateDashboardAcl(t, dashboardStore, root.Id, models.DashboardAcl{
+					OrgID:       orgId,
+					DashboardID: root.Id,
+					Permission:  models.PERMISSION_EDIT,
+					Role:        &editorRole,
+				})
+				require.NoError(t, err)
+
+				query := models.GetDashboardAclInfoListQuery{DashboardID: dash.Id, OrgID: orgId}
+				err = dashboardStore.GetDashboardAclInfoList(context.Background(), &query)
+				require.NoError(t, err)
+				var found bool
+				for _, acl := range query.Result {
+					if acl.DashboardId == root.Id {
+						found = true
+						require.True(t, acl.Inherited)
+					}
+				}
+				require.True(t, found)
+			})
+
+			t.Run("should delete subfolders and their dashboards with the folder", func(t *testing.T) {
+				err := dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{OrgId: orgId, Id: root.Id})
+				require.NoError(t, err)
+
+				_, err = dashboardStore.GetFolderByID(context.Background(), orgId, grandchild.Id)
+				require.ErrorIs(t, err, models.ErrFolderNotFound)
+				query := models.GetDashboardQuery{OrgId: orgId, Id: dash.Id}
+				_, err = dashboardStore.GetDashboard(context.Background(), &query)
+				require.ErrorIs(t, err, models.ErrDashboardNotFound)
+			})
+		})
 	})
 }
 
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards/schemaversion"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const schemaMigrationBatchSize = 100
+
+type dataSourceRefResult struct {
+	UID       string `xorm:"uid"`
+	Type      string `xorm:"type"`
+	Name      string `xorm:"name"`
+	IsDefault bool   `xorm:"is_default"`
+}
+
+// GetDataSourceLookup returns a lookup of the data sources of an organization by name or uid,
+// used by the schema migrations to replace data source names with references.
+func (d *DashboardStore) GetDataSourceLookup(ctx context.Context, orgID int64) (schemaversion.DataSourceLookup, error) {
+	byUID := make(map[string]*schemaversion.DataSourceRef)
+	byName := make(map[string]*schemaversion.DataSourceRef)
+	var defaultDS *schemaversion.DataSourceRef
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rows := make([]*dataSourceRefResult, 0)
+		if err := sess.Table("data_source").Where("org_id = ?", orgID).Cols("uid", "name", "type", "is_default").Find(&rows); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			ds := &schemaversion.DataSourceRef{UID: row.UID, Type: row.Type}
+			byUID[row.UID] = ds
+			byName[row.Name] = ds
+			if row.IsDefault {
+				defaultDS = ds
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(nameOrUID string) *schemaversion.DataSourceRef {
+		if nameOrUID == "" {
+			return defaultDS
+		}
+		if ds, ok := byName[nameOrUID]; ok {
+			return ds
+		}
+		return byUID[nameOrUID]
+	}, nil
+}
+
+// MigrateDashboardsSchema upgrades the stored dashboards to the latest schema version.
+// The dashboards keep their version, the migration only changes the format of the model.
+func (d *DashboardStore) MigrateDashboardsSchema(ctx context.Context, cmd *models.MigrateDashboardsSchemaCommand) error {
+	result := &models.DashboardsSchemaMigration{}
+	lookups := make(map[int64]schemaversion.DataSourceLookup)
+
+	var lastID int64
+	for {
+		var batch []*models.Dashboard
+		err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			sess.Where("id > ? AND is_folder = ?", lastID, d.dialect.BooleanStr(false))
+			if cmd.OrgId > 0 {
+				sess.And("org_id = ?", cmd.OrgId)
+			}
+			return sess.Cols("id", "org_id", "uid", "data").Asc("id").Limit(schemaMigrationBatchSize).Find(&batch)
+		})
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		lastID = batch[len(batch)-1].Id
+
+		for _, dash := range batch {
+			result.Scanned++
+			if !schemaversion.NeedsMigration(dash.Data) {
+				continue
+			}
+
+			lookup, ok := lookups[dash.OrgId]
+			if !ok {
+				lookup, err = d.GetDataSourceLookup(ctx, dash.OrgId)
+				if err != nil {
+					return err
+				}
+				lookups[dash.OrgId] = lookup
+			}
+
+			if err := schemaversion.Migrate(dash.Data, lookup); err != nil {
+				d.log.Warn("Failed to migrate dashboard schema", "orgId", dash.OrgId, "uid", dash.Uid, "error", err)
+				result.Failed++
+				continue
+			}
+
+			err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+				_, err := sess.ID(dash.Id).Cols("data").Update(&models.Dashboard{Data: dash.Data})
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			result.Migrated++
+		}
+	}
+
+	cmd.Result = result
+	return nil
+}
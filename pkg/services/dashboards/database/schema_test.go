@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards/schemaversion"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationDashboardsSchemaMigration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := sqlstore.InitTestDB(t)
+	dashboardStore := ProvideDashboardStore(sqlStore)
+
+	for _, cmd := range []*models.AddDataSourceCommand{
+		{OrgId: 1, Name: "prom", Uid: "prom-uid", Type: "prometheus", IsDefault: true, Access: models.DS_ACCESS_PROXY},
+		{OrgId: 1, Name: "loki", Uid: "loki-uid", Type: "loki", Access: models.DS_ACCESS_PROXY},
+	} {
+		require.NoError(t, sqlStore.AddDataSource(context.Background(), cmd))
+	}
+
+	saveDashboard := func(t *testing.T, orgID int64, data map[string]interface{}) *models.Dashboard {
+		t.Helper()
+		dash, err := dashboardStore.SaveDashboard(models.SaveDashboardCommand{OrgId: orgID, Dashboard: simplejson.NewFromAny(data)})
+		require.NoError(t, err)
+		return dash
+	}
+
+	oldDash := saveDashboard(t, 1, map[string]interface{}{
+		"title":         "old",
+		"schemaVersion": 27,
+		"panels": []interface{}{
+			map[string]interface{}{"id": 1, "type": "singlestat", "datasource": "loki", "targets": []interface{}{map[string]interface{}{"refId": "A"}}},
+		},
+	})
+	latestDash := saveDashboard(t, 1, map[string]interface{}{"title": "latest", "schemaVersion": schemaversion.LatestVersion})
+	otherOrgDash := saveDashboard(t, 2, map[string]interface{}{"title": "other org", "schemaVersion": 30})
+
+	t.Run("Should look up data sources by name, uid or default", func(t *testing.T) {
+		lookup, err := dashboardStore.GetDataSourceLookup(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, &schemaversion.DataSourceRef{UID: "loki-uid", Type: "loki"}, lookup("loki"))
+		assert.Equal(t, &schemaversion.DataSourceRef{UID: "loki-uid", Type: "loki"}, lookup("loki-uid"))
+		assert.Equal(t, &schemaversion.DataSourceRef{UID: "prom-uid", Type: "prometheus"}, lookup(""))
+		assert.Nil(t, lookup("unknown"))
+	})
+
+	t.Run("Should migrate the dashboards of an organization", func(t *testing.T) {
+		cmd := models.MigrateDashboardsSchemaCommand{OrgId: 1}
+		require.NoError(t, dashboardStore.MigrateDashboardsSchema(context.Background(), &cmd))
+		assert.Equal(t, &models.DashboardsSchemaMigration{Scanned: 2, Migrated: 1}, cmd.Result)
+
+		dash, err := dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{Id: oldDash.Id, OrgId: 1})
+		require.NoError(t, err)
+		assert.Equal(t, oldDash.Version, dash.Version)
+		assert.Equal(t, schemaversion.LatestVersion, dash.Data.Get("schemaVersion").MustInt())
+		panel := dash.Data.Get("panels").GetIndex(0)
+		assert.Equal(t, "stat", panel.Get("type").MustString())
+		assert.Equal(t, "loki-uid", panel.GetPath("datasource", "uid").MustString())
+
+		dash, err = dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{Id: otherOrgDash.Id, OrgId: 2})
+		require.NoError(t, err)
+		assert.Equal(t, 30, dash.Data.Get("schemaVersion").MustInt())
+	})
+
+	t.Run("Should migrate the dashboards of all organizations", func(t *testing.T) {
+		cmd := models.MigrateDashboardsSchemaCommand{}
+		require.NoError(t, dashboardStore.MigrateDashboardsSchema(context.Background(), &cmd))
+		assert.Equal(t, &models.DashboardsSchemaMigration{Scanned: 3, Migrated: 1}, cmd.Result)
+
+		for _, d := range []*models.Dashboard{oldDash, latestDash, otherOrgDash} {
+			dash, err := dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{Id: d.Id, OrgId: d.OrgId})
+			require.NoError(t, err)
+			assert.False(t, schemaversion.NeedsMigration(dash.Data))
+		}
+	})
+}
@@ -0,0 +1,302 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// trashedDashboard is what is kept in the trash for a deleted dashboard or folder.
+type trashedDashboard struct {
+	Dashboard   *models.Dashboard           `json:"dashboard"`
+	Versions    []*dashver.DashboardVersion `json:"versions"`
+	ACL         []*models.DashboardAcl      `json:"acl"`
+	Permissions []*trashedPermission        `json:"permissions"`
+
+	// Children are the dashboards and subfolders of a folder
+	Children   []*trashedDashboard   `json:"children,omitempty"`
+	AlertRules []*ngmodels.AlertRule `json:"alertRules,omitempty"`
+}
+
+// trashedPermission is an access control permission scoped to a trashed dashboard or folder.
+// accesscontrol.Permission does not serialize the role it belongs to.
+type trashedPermission struct {
+	RoleID  int64     `json:"roleId"`
+	Action  string    `json:"action"`
+	Scope   string    `json:"scope"`
+	Updated time.Time `json:"updated"`
+	Created time.Time `json:"created"`
+}
+
+func (d *DashboardStore) moveToTrash(sess *sqlstore.DBSession, dash *models.Dashboard, deletedBy int64) error {
+	item, err := d.getTrashedDashboard(sess, dash)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	folderUID := ""
+	if dash.FolderId > 0 {
+		if _, err := sess.Table("dashboard").Where("org_id = ? AND id = ?", dash.OrgId, dash.FolderId).Cols("uid").Get(&folderUID); err != nil {
+			return err
+		}
+	}
+
+	_, err = sess.Insert(&models.DashboardTrash{
+		OrgId:     dash.OrgId,
+		Uid:       dash.Uid,
+		Title:     dash.Title,
+		IsFolder:  dash.IsFolder,
+		FolderUid: folderUID,
+		Data:      string(data),
+		Deleted:   time.Now(),
+		DeletedBy: deletedBy,
+	})
+	return err
+}
+
+func (d *DashboardStore) getTrashedDashboard(sess *sqlstore.DBSession, dash *models.Dashboard) (*trashedDashboard, error) {
+	item := &trashedDashboard{Dashboard: dash}
+
+	if err := sess.Where("dashboard_id = ?", dash.Id).Find(&item.Versions); err != nil {
+		return nil, err
+	}
+	if err := sess.Where("dashboard_id = ?", dash.Id).Find(&item.ACL); err != nil {
+		return nil, err
+	}
+
+	scope := ac.GetResourceScopeUID("dashboards", dash.Uid)
+	if dash.IsFolder {
+		scope = dashboards.ScopeFoldersProvider.GetResourceScopeUID(dash.Uid)
+	}
+	var permissions []ac.Permission
+	if err := sess.Where("scope = ?", scope).Find(&permissions); err != nil {
+		return nil, err
+	}
+	for _, p := range permissions {
+		item.Permissions = append(item.Permissions, &trashedPermission{
+			RoleID: p.RoleID, Action: p.Action, Scope: p.Scope, Updated: p.Updated, Created: p.Created,
+		})
+	}
+
+	if !dash.IsFolder {
+		return item, nil
+	}
+
+	var children []*models.Dashboard
+	if err := sess.Where("org_id = ? AND folder_id = ?", dash.OrgId, dash.Id).Find(&children); err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		childItem, err := d.getTrashedDashboard(sess, child)
+		if err != nil {
+			return nil, err
+		}
+		item.Children = append(item.Children, childItem)
+	}
+
+	if err := sess.Where("org_id = ? AND namespace_uid = ?", dash.OrgId, dash.Uid).Find(&item.AlertRules); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (d *DashboardStore) GetDashboardTrash(ctx context.Context, query *models.GetDashboardTrashQuery) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		sess.Where("org_id = ?", query.OrgId)
+		if query.DeletedBy > 0 {
+			sess.And("deleted_by = ?", query.DeletedBy)
+		}
+
+		query.Result = make([]*models.DashboardTrash, 0)
+		return sess.Omit("data").Desc("deleted").Find(&query.Result)
+	})
+}
+
+func (d *DashboardStore) GetDashboardTrashItem(ctx context.Context, query *models.GetDashboardTrashItemQuery) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var item models.DashboardTrash
+		has, err := sess.Where("org_id = ? AND id = ?", query.OrgId, query.Id).Omit("data").Get(&item)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrDashboardTrashItemNotFound
+		}
+
+		query.Result = &item
+		return nil
+	})
+}
+
+// RestoreDashboardFromTrash restores a dashboard or folder with everything it contained when it was deleted.
+// If its folder no longer exists it is restored to the root (General) folder.
+func (d *DashboardStore) RestoreDashboardFromTrash(ctx context.Context, cmd *models.RestoreDashboardFromTrashCommand) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var trash models.DashboardTrash
+		has, err := sess.Where("org_id = ? AND id = ?", cmd.OrgId, cmd.Id).Get(&trash)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrDashboardTrashItemNotFound
+		}
+
+		var item trashedDashboard
+		if err := json.Unmarshal([]byte(trash.Data), &item); err != nil {
+			return fmt.Errorf("failed to read trash item: %w", err)
+		}
+		if item.Dashboard == nil {
+			return models.ErrDashboardCorrupt
+		}
+
+		var folderID int64
+		if trash.FolderUid != "" {
+			if _, err := sess.Table("dashboard").Where("org_id = ? AND uid = ? AND is_folder = ?", trash.OrgId, trash.FolderUid,
+				d.dialect.BooleanStr(true)).Cols("id").Get(&folderID); err != nil {
+				return err
+			}
+		}
+
+		cmd.Result = make([]*models.Dashboard, 0)
+		if err := d.restoreTrashedDashboard(sess, &item, folderID, &cmd.Result); err != nil {
+			return err
+		}
+
+		_, err = sess.Exec("DELETE FROM dashboard_trash WHERE id = ?", trash.Id)
+		return err
+	})
+}
+
+func (d *DashboardStore) restoreTrashedDashboard(sess *sqlstore.DBSession, item *trashedDashboard, folderID int64, restored *[]*models.Dashboard) error {
+	dash := item.Dashboard
+	dash.FolderId = folderID
+
+	exists, err := sess.Table("dashboard").Where("org_id = ? AND uid = ?", dash.OrgId, dash.Uid).Exist()
+	if err != nil {
+		return err
+	}
+	if exists {
+		return models.ErrDashboardWithSameUIDExists
+	}
+
+	// keep the original id unless it has been reused
+	exists, err = sess.Table("dashboard").Where("id = ?", dash.Id).Exist()
+	if err != nil {
+		return err
+	}
+	if exists {
+		dash.SetId(0)
+	}
+
+	if _, err := getExistingDashboardByTitleAndFolder(sess, dash, d.dialect, false, false); err != nil {
+		return err
+	}
+
+	if _, err := sess.Insert(dash); err != nil {
+		return err
+	}
+	dash.Data.Set("id", dash.Id)
+	*restored = append(*restored, dash)
+
+	for _, tag := range dash.GetTags() {
+		if _, err := sess.Insert(&sqlstore.DashboardTag{DashboardId: dash.Id, Term: tag}); err != nil {
+			return err
+		}
+	}
+
+	for _, version := range item.Versions {
+		version.ID = 0
+		version.DashboardID = dash.Id
+		if _, err := sess.Insert(version); err != nil {
+			return err
+		}
+	}
+
+	for _, acl := range item.ACL {
+		acl.Id = 0
+		acl.DashboardID = dash.Id
+		if _, err := sess.Insert(acl); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range item.Permissions {
+		// the managed role may have been removed together with its user or team
+		exists, err := sess.Table("role").Where("id = ?", p.RoleID).Exist()
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		if _, err := sess.Insert(&ac.Permission{RoleID: p.RoleID, Action: p.Action, Scope: p.Scope, Updated: p.Updated, Created: p.Created}); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range item.Children {
+		if err := d.restoreTrashedDashboard(sess, child, dash.Id, restored); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range item.AlertRules {
+		// skip rules whose uid has been taken since the folder was deleted
+		exists, err := sess.Table("alert_rule").Where("org_id = ? AND uid = ?", rule.OrgID, rule.UID).Exist()
+		if err != nil {
+			return err
+		}
+		if exists {
+			d.log.Warn("Skipping restore of alert rule with existing uid", "uid", rule.UID, "folder", dash.Uid)
+			continue
+		}
+		rule.ID = 0
+		rule.NamespaceUID = dash.Uid
+		if _, err := sess.Insert(rule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *DashboardStore) DeleteDashboardFromTrash(ctx context.Context, cmd *models.DeleteDashboardFromTrashCommand) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM dashboard_trash WHERE org_id = ? AND id = ?", cmd.OrgId, cmd.Id)
+		if err != nil {
+			return err
+		}
+		if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+			return models.ErrDashboardTrashItemNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteExpiredDashboardTrash permanently deletes the items that were moved to the trash before the given time.
+func (d *DashboardStore) DeleteExpiredDashboardTrash(ctx context.Context, olderThan time.Time) (int64, error) {
+	var affected int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM dashboard_trash WHERE deleted < ?", olderThan)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
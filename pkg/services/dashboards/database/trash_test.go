@@ -0,0 +1,227 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationDashboardTrash(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	var sqlStore *sqlstore.SQLStore
+	var dashboardStore *DashboardStore
+	var savedFolder, savedSubfolder, savedDash, savedDash2 *models.Dashboard
+
+	setup := func() {
+		sqlStore = sqlstore.InitTestDB(t)
+		dashboardStore = ProvideDashboardStore(sqlStore)
+		savedFolder = insertTestDashboard(t, dashboardStore, "trash folder", 1, 0, true)
+		savedSubfolder = insertTestDashboard(t, dashboardStore, "trash subfolder", 1, savedFolder.Id, true)
+		savedDash = insertTestDashboard(t, dashboardStore, "trash dash", 1, savedFolder.Id, false, "prod")
+		savedDash2 = insertTestDashboard(t, dashboardStore, "trash dash 2", 1, savedSubfolder.Id, false)
+		insertTestRule(t, sqlStore, savedFolder.OrgId, savedFolder.Uid)
+	}
+
+	getTrash := func(t *testing.T) []*models.DashboardTrash {
+		t.Helper()
+		query := models.GetDashboardTrashQuery{OrgId: 1}
+		require.NoError(t, dashboardStore.GetDashboardTrash(context.Background(), &query))
+		return query.Result
+	}
+
+	t.Run("Should not keep dashboard in trash when deleted permanently", func(t *testing.T) {
+		setup()
+		err := dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{Id: savedDash.Id, OrgId: 1})
+		require.NoError(t, err)
+		require.Empty(t, getTrash(t))
+	})
+
+	t.Run("Should restore dashboard with its tags, versions and permissions", func(t *testing.T) {
+		setup()
+		err := updateDashboardAcl(t, dashboardStore, savedDash.Id, models.DashboardAcl{
+			OrgID: 1, DashboardID: savedDash.Id, UserID: 3, Permission: models.PERMISSION_EDIT,
+		})
+		require.NoError(t, err)
+
+		err = dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{
+			Id: savedDash.Id, OrgId: 1, MoveToTrash: true, DeletedBy: 2,
+		})
+		require.NoError(t, err)
+
+		_, err = dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{Id: savedDash.Id, OrgId: 1})
+		require.ErrorIs(t, err, models.ErrDashboardNotFound)
+
+		trash := getTrash(t)
+		require.Len(t, trash, 1)
+		assert.Equal(t, savedDash.Uid, trash[0].Uid)
+		assert.Equal(t, savedDash.Title, trash[0].Title)
+		assert.Equal(t, savedFolder.Uid, trash[0].FolderUid)
+		assert.False(t, trash[0].IsFolder)
+		assert.EqualValues(t, 2, trash[0].DeletedBy)
+		assert.Empty(t, trash[0].Data)
+
+		cmd := models.RestoreDashboardFromTrashCommand{Id: trash[0].Id, OrgId: 1}
+		require.NoError(t, dashboardStore.RestoreDashboardFromTrash(context.Background(), &cmd))
+		require.Len(t, cmd.Result, 1)
+		assert.Equal(t, savedDash.Id, cmd.Result[0].Id)
+
+		dash, err := dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{Uid: savedDash.Uid, OrgId: 1})
+		require.NoError(t, err)
+		assert.Equal(t, savedDash.Id, dash.Id)
+		assert.Equal(t, savedFolder.Id, dash.FolderId)
+
+		tagsQuery := models.GetDashboardTagsQuery{OrgId: 1}
+		require.NoError(t, dashboardStore.GetDashboardTags(context.Background(), &tagsQuery))
+		require.Len(t, tagsQuery.Result, 1)
+		assert.Equal(t, "prod", tagsQuery.Result[0].Term)
+
+		var versions []*dashver.DashboardVersion
+		err = sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			return sess.Where("dashboard_id = ?", savedDash.Id).Find(&versions)
+		})
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+
+		aclQuery := models.GetDashboardAclInfoListQuery{DashboardID: savedDash.Id, OrgID: 1}
+		require.NoError(t, dashboardStore.GetDashboardAclInfoList(context.Background(), &aclQuery))
+		var userIDs []int64
+		for _, item := range aclQuery.Result {
+			userIDs = append(userIDs, item.UserId)
+		}
+		assert.Contains(t, userIDs, int64(3))
+
+		require.Empty(t, getTrash(t))
+	})
+
+	t.Run("Should restore folder with its subfolders, dashboards and alert rules", func(t *testing.T) {
+		setup()
+		err := dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{
+			Id: savedFolder.Id, OrgId: 1, ForceDeleteFolderRules: true, MoveToTrash: true,
+		})
+		require.NoError(t, err)
+
+		trash := getTrash(t)
+		require.Len(t, trash, 1)
+		assert.True(t, trash[0].IsFolder)
+		assert.Empty(t, trash[0].FolderUid)
+
+		cmd := models.RestoreDashboardFromTrashCommand{Id: trash[0].Id, OrgId: 1}
+		require.NoError(t, dashboardStore.RestoreDashboardFromTrash(context.Background(), &cmd))
+		require.Len(t, cmd.Result, 4)
+		assert.Equal(t, savedFolder.Uid, cmd.Result[0].Uid)
+
+		for _, dash := range []*models.Dashboard{savedSubfolder, savedDash, savedDash2} {
+			restored, err := dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{Uid: dash.Uid, OrgId: 1})
+			require.NoError(t, err)
+			assert.Equal(t, dash.FolderId, restored.FolderId)
+		}
+
+		var ruleUIDs []string
+		err = sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			return sess.Table("alert_rule").Where("namespace_uid = ?", savedFolder.Uid).Cols("uid").Find(&ruleUIDs)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"rule"}, ruleUIDs)
+	})
+
+	t.Run("Should restore dashboard to the root folder when its folder no longer exists", func(t *testing.T) {
+		setup()
+		err := dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{
+			Id: savedDash.Id, OrgId: 1, MoveToTrash: true,
+		})
+		require.NoError(t, err)
+		err = dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{
+			Id: savedFolder.Id, OrgId: 1, ForceDeleteFolderRules: true,
+		})
+		require.NoError(t, err)
+
+		trash := getTrash(t)
+		require.Len(t, trash, 1)
+		cmd := models.RestoreDashboardFromTrashCommand{Id: trash[0].Id, OrgId: 1}
+		require.NoError(t, dashboardStore.RestoreDashboardFromTrash(context.Background(), &cmd))
+
+		dash, err := dashboardStore.GetDashboard(context.Background(), &models.GetDashboardQuery{Uid: savedDash.Uid, OrgId: 1})
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, dash.FolderId)
+	})
+
+	t.Run("Should not restore dashboard when its uid has been taken", func(t *testing.T) {
+		setup()
+		err := dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{
+			Id: savedDash2.Id, OrgId: 1, MoveToTrash: true,
+		})
+		require.NoError(t, err)
+
+		dash := insertTestDashboard(t, dashboardStore, "new dash", 1, 0, false)
+		err = sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			_, err := sess.Exec("UPDATE dashboard SET uid = ? WHERE id = ?", savedDash2.Uid, dash.Id)
+			return err
+		})
+		require.NoError(t, err)
+
+		trash := getTrash(t)
+		require.Len(t, trash, 1)
+		cmd := models.RestoreDashboardFromTrashCommand{Id: trash[0].Id, OrgId: 1}
+		err = dashboardStore.RestoreDashboardFromTrash(context.Background(), &cmd)
+		require.ErrorIs(t, err, models.ErrDashboardWithSameUIDExists)
+		require.Len(t, getTrash(t), 1)
+	})
+
+	t.Run("Should filter trash by the user who deleted", func(t *testing.T) {
+		setup()
+		for i, dash := range []*models.Dashboard{savedDash, savedDash2} {
+			err := dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{
+				Id: dash.Id, OrgId: 1, MoveToTrash: true, DeletedBy: int64(i + 1),
+			})
+			require.NoError(t, err)
+		}
+
+		query := models.GetDashboardTrashQuery{OrgId: 1, DeletedBy: 2}
+		require.NoError(t, dashboardStore.GetDashboardTrash(context.Background(), &query))
+		require.Len(t, query.Result, 1)
+		assert.Equal(t, savedDash2.Uid, query.Result[0].Uid)
+	})
+
+	t.Run("Should delete from trash", func(t *testing.T) {
+		setup()
+		err := dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{
+			Id: savedDash.Id, OrgId: 1, MoveToTrash: true,
+		})
+		require.NoError(t, err)
+		trash := getTrash(t)
+		require.Len(t, trash, 1)
+
+		err = dashboardStore.DeleteDashboardFromTrash(context.Background(), &models.DeleteDashboardFromTrashCommand{Id: trash[0].Id, OrgId: 2})
+		require.ErrorIs(t, err, models.ErrDashboardTrashItemNotFound)
+
+		err = dashboardStore.DeleteDashboardFromTrash(context.Background(), &models.DeleteDashboardFromTrashCommand{Id: trash[0].Id, OrgId: 1})
+		require.NoError(t, err)
+		require.Empty(t, getTrash(t))
+	})
+
+	t.Run("Should delete expired items from trash", func(t *testing.T) {
+		setup()
+		err := dashboardStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{
+			Id: savedDash.Id, OrgId: 1, MoveToTrash: true,
+		})
+		require.NoError(t, err)
+
+		deleted, err := dashboardStore.DeleteExpiredDashboardTrash(context.Background(), time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, deleted)
+
+		deleted, err = dashboardStore.DeleteExpiredDashboardTrash(context.Background(), time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, deleted)
+		require.Empty(t, getTrash(t))
+	})
+}
@@ -13,8 +13,11 @@ type FolderService interface {
 	GetFolderByID(ctx context.Context, user *models.SignedInUser, id int64, orgID int64) (*models.Folder, error)
 	GetFolderByUID(ctx context.Context, user *models.SignedInUser, orgID int64, uid string) (*models.Folder, error)
 	GetFolderByTitle(ctx context.Context, user *models.SignedInUser, orgID int64, title string) (*models.Folder, error)
-	CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title, uid string) (*models.Folder, error)
+	GetFolderByPath(ctx context.Context, user *models.SignedInUser, orgID int64, path string) (*models.Folder, error)
+	GetFolderParents(ctx context.Context, user *models.SignedInUser, orgID int64, uid string) ([]*models.Folder, error)
+	CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title, uid, parentUID string) (*models.Folder, error)
 	UpdateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, existingUid string, cmd *models.UpdateFolderCommand) error
+	MoveFolder(ctx context.Context, user *models.SignedInUser, orgID int64, uid string, cmd *models.MoveFolderCommand) error
 	DeleteFolder(ctx context.Context, user *models.SignedInUser, orgID int64, uid string, forceDeleteRules bool) (*models.Folder, error)
 	MakeUserAdmin(ctx context.Context, orgID int64, userID, folderID int64, setViewAndEditPermissions bool) error
 }
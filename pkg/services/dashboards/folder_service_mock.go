@@ -16,13 +16,13 @@ type FakeFolderService struct {
 	mock.Mock
 }
 
-// CreateFolder provides a mock function with given fields: ctx, user, orgID, title, uid
-func (_m *FakeFolderService) CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title string, uid string) (*models.Folder, error) {
-	ret := _m.Called(ctx, user, orgID, title, uid)
+// CreateFolder provides a mock function with given fields: ctx, user, orgID, title, uid, parentUID
+func (_m *FakeFolderService) CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title string, uid string, parentUID string) (*models.Folder, error) {
+	ret := _m.Called(ctx, user, orgID, title, uid, parentUID)
 
 	var r0 *models.Folder
-	if rf, ok := ret.Get(0).(func(context.Context, *models.SignedInUser, int64, string, string) *models.Folder); ok {
-		r0 = rf(ctx, user, orgID, title, uid)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SignedInUser, int64, string, string, string) *models.Folder); ok {
+		r0 = rf(ctx, user, orgID, title, uid, parentUID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Folder)
@@ -30,8 +30,8 @@ func (_m *FakeFolderService) CreateFolder(ctx context.Context, user *models.Sign
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *models.SignedInUser, int64, string, string) error); ok {
-		r1 = rf(ctx, user, orgID, title, uid)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.SignedInUser, int64, string, string, string) error); ok {
+		r1 = rf(ctx, user, orgID, title, uid, parentUID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -85,6 +85,29 @@ func (_m *FakeFolderService) GetFolderByID(ctx context.Context, user *models.Sig
 	return r0, r1
 }
 
+// GetFolderByPath provides a mock function with given fields: ctx, user, orgID, path
+func (_m *FakeFolderService) GetFolderByPath(ctx context.Context, user *models.SignedInUser, orgID int64, path string) (*models.Folder, error) {
+	ret := _m.Called(ctx, user, orgID, path)
+
+	var r0 *models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SignedInUser, int64, string) *models.Folder); ok {
+		r0 = rf(ctx, user, orgID, path)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.SignedInUser, int64, string) error); ok {
+		r1 = rf(ctx, user, orgID, path)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFolderByTitle provides a mock function with given fields: ctx, user, orgID, title
 func (_m *FakeFolderService) GetFolderByTitle(ctx context.Context, user *models.SignedInUser, orgID int64, title string) (*models.Folder, error) {
 	ret := _m.Called(ctx, user, orgID, title)
@@ -131,6 +154,29 @@ func (_m *FakeFolderService) GetFolderByUID(ctx context.Context, user *models.Si
 	return r0, r1
 }
 
+// GetFolderParents provides a mock function with given fields: ctx, user, orgID, uid
+func (_m *FakeFolderService) GetFolderParents(ctx context.Context, user *models.SignedInUser, orgID int64, uid string) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, user, orgID, uid)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SignedInUser, int64, string) []*models.Folder); ok {
+		r0 = rf(ctx, user, orgID, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.SignedInUser, int64, string) error); ok {
+		r1 = rf(ctx, user, orgID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFolders provides a mock function with given fields: ctx, user, orgID, limit, page
 func (_m *FakeFolderService) GetFolders(ctx context.Context, user *models.SignedInUser, orgID int64, limit int64, page int64) ([]*models.Folder, error) {
 	ret := _m.Called(ctx, user, orgID, limit, page)
@@ -168,6 +214,20 @@ func (_m *FakeFolderService) MakeUserAdmin(ctx context.Context, orgID int64, use
 	return r0
 }
 
+// MoveFolder provides a mock function with given fields: ctx, user, orgID, uid, cmd
+func (_m *FakeFolderService) MoveFolder(ctx context.Context, user *models.SignedInUser, orgID int64, uid string, cmd *models.MoveFolderCommand) error {
+	ret := _m.Called(ctx, user, orgID, uid, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SignedInUser, int64, string, *models.MoveFolderCommand) error); ok {
+		r0 = rf(ctx, user, orgID, uid, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateFolder provides a mock function with given fields: ctx, user, orgID, existingUid, cmd
 func (_m *FakeFolderService) UpdateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, existingUid string, cmd *models.UpdateFolderCommand) error {
 	ret := _m.Called(ctx, user, orgID, existingUid, cmd)
@@ -85,6 +85,52 @@ func (_m *FakeFolderStore) GetFolderByUID(ctx context.Context, orgID int64, uid
 	return r0, r1
 }
 
+// GetFolderChildren provides a mock function with given fields: ctx, orgID, id
+func (_m *FakeFolderStore) GetFolderChildren(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, orgID, id)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Folder); ok {
+		r0 = rf(ctx, orgID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, orgID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFolderParents provides a mock function with given fields: ctx, orgID, id
+func (_m *FakeFolderStore) GetFolderParents(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, orgID, id)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Folder); ok {
+		r0 = rf(ctx, orgID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, orgID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewFakeFolderStore creates a new instance of FakeFolderStore. It also registers the testing.TB interface on the mock and a cleanup function to assert the mocks expectations.
 func NewFakeFolderStore(t testing.TB) *FakeFolderStore {
 	mock := &FakeFolderStore{}
@@ -0,0 +1,214 @@
+package schemaversion
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	gridCellHeight   = 30
+	gridCellVMargin  = 8
+	defaultPanelSpan = 4
+	defaultRowHeight = 250
+	minPanelHeight   = gridCellHeight * 3
+)
+
+// upgradeToGridLayout replaces the rows of the dashboard by panels positioned on the grid,
+// rows that are collapsed, repeated or have a visible title are kept as row panels.
+func (m *migrator) upgradeToGridLayout() {
+	rows := toObjects(m.dash["rows"])
+	if rows == nil {
+		return
+	}
+
+	var maxPanelID int64
+	for _, row := range rows {
+		for _, panel := range toObjects(row["panels"]) {
+			if id, ok := toInt(panel["id"]); ok && id > maxPanelID {
+				maxPanelID = id
+			}
+		}
+	}
+	nextRowID := maxPanelID + 1
+
+	// add special "row" panels if even one row is collapsed, repeated or has visible title
+	showRows := false
+	for _, row := range rows {
+		if truthy(row["collapse"]) || truthy(row["showTitle"]) || truthy(row["repeat"]) {
+			showRows = true
+			break
+		}
+	}
+
+	panels := toList(m.dash["panels"])
+	yPos := 0
+	widthFactor := gridColumnCount / 12
+
+	for _, row := range rows {
+		if truthy(row["repeatIteration"]) {
+			continue
+		}
+
+		height := row["height"]
+		if !truthy(height) {
+			height = defaultRowHeight
+		}
+		rowGridHeight := getGridHeight(height)
+
+		var rowPanel map[string]interface{}
+		collapsed := false
+		if showRows {
+			collapsed = truthy(row["collapse"])
+			rowPanel = map[string]interface{}{
+				"id":     nextRowID,
+				"type":   "row",
+				"panels": []interface{}{},
+				"gridPos": map[string]interface{}{
+					"x": 0, "y": yPos, "w": gridColumnCount, "h": rowGridHeight,
+				},
+			}
+			setDefined(rowPanel, "title", row["title"])
+			setDefined(rowPanel, "collapsed", row["collapse"])
+			setDefined(rowPanel, "repeat", row["repeat"])
+			nextRowID++
+			yPos++
+		}
+
+		area := newRowArea(rowGridHeight, gridColumnCount, yPos)
+
+		for _, panel := range toObjects(row["panels"]) {
+			span, ok := toFloat(panel["span"])
+			if !ok || span == 0 {
+				span = defaultPanelSpan
+			}
+			if minSpan, ok := toFloat(panel["minSpan"]); ok && minSpan != 0 {
+				panel["minSpan"] = math.Min(gridColumnCount, float64(widthFactor)*minSpan)
+			}
+			panelWidth := int(math.Floor(span)) * widthFactor
+			panelHeight := rowGridHeight
+			if truthy(panel["height"]) {
+				panelHeight = getGridHeight(panel["height"])
+			}
+
+			x, y := area.getPanelPosition(panelWidth, false)
+			yPos = area.yPos
+			gridPos := map[string]interface{}{"x": x, "y": yPos + y, "w": panelWidth, "h": panelHeight}
+			panel["gridPos"] = gridPos
+			area.addPanel(x, yPos+y, panelWidth, panelHeight)
+
+			delete(panel, "span")
+
+			if rowPanel != nil && collapsed {
+				rowPanel["panels"] = append(toList(rowPanel["panels"]), panel)
+			} else {
+				panels = append(panels, panel)
+			}
+		}
+
+		if rowPanel != nil {
+			panels = append(panels, rowPanel)
+		}
+		if rowPanel == nil || !collapsed {
+			yPos += rowGridHeight
+		}
+	}
+
+	sort.SliceStable(panels, func(i, j int) bool {
+		a := toObject(toObject(panels[i])["gridPos"])
+		b := toObject(toObject(panels[j])["gridPos"])
+		ay, _ := toFloat(a["y"])
+		by, _ := toFloat(b["y"])
+		if ay != by {
+			return ay < by
+		}
+		ax, _ := toFloat(a["x"])
+		bx, _ := toFloat(b["x"])
+		return ax < bx
+	})
+	m.dash["panels"] = panels
+}
+
+func getGridHeight(height interface{}) int {
+	h, ok := toFloat(height)
+	if !ok {
+		s := strings.TrimSpace(strings.ReplaceAll(toString(height), "px", ""))
+		// parseInt ignores anything after the leading digits
+		end := 0
+		for end < len(s) && (s[end] >= '0' && s[end] <= '9' || end == 0 && s[end] == '-') {
+			end++
+		}
+		n, err := strconv.Atoi(s[:end])
+		if err != nil {
+			n = 0
+		}
+		h = float64(n)
+	}
+	if h < minPanelHeight {
+		h = minPanelHeight
+	}
+	return int(math.Ceil(h / (gridCellHeight + gridCellVMargin)))
+}
+
+// rowArea represents a dashboard row filled by panels, area holds the height filled in each column of the row.
+type rowArea struct {
+	area   []int
+	yPos   int
+	height int
+}
+
+func newRowArea(height, width, yPos int) *rowArea {
+	return &rowArea{area: make([]int, width), yPos: yPos, height: height}
+}
+
+func (r *rowArea) reset() {
+	for i := range r.area {
+		r.area[i] = 0
+	}
+}
+
+// addPanel updates the area after adding a panel.
+func (r *rowArea) addPanel(x, y, w, h int) {
+	for i := x; i < x+w && i < len(r.area); i++ {
+		if r.area[i] == 0 || y+h-r.yPos > r.area[i] {
+			r.area[i] = y + h - r.yPos
+		}
+	}
+}
+
+// getPanelPosition returns the position of a new panel in the row, wrapping to a new row when it does not fit.
+func (r *rowArea) getPanelPosition(panelWidth int, callOnce bool) (int, int) {
+	startPlace, endPlace := -1, -1
+	for i := len(r.area) - 1; i >= 0; i-- {
+		if r.height-r.area[i] <= 0 {
+			break
+		}
+		if endPlace == -1 {
+			endPlace = i
+		} else if i < len(r.area)-1 && r.area[i] <= r.area[i+1] {
+			startPlace = i
+		} else {
+			break
+		}
+	}
+
+	if startPlace != -1 && endPlace != -1 && endPlace-startPlace >= panelWidth-1 {
+		y := 0
+		for _, filled := range r.area[startPlace:] {
+			if filled > y {
+				y = filled
+			}
+		}
+		return startPlace, y
+	}
+	if callOnce {
+		// the panel is wider than the row
+		return 0, 0
+	}
+
+	// wrap to next row
+	r.yPos += r.height
+	r.reset()
+	return r.getPanelPosition(panelWidth, true)
+}
@@ -0,0 +1,177 @@
+// Package schemaversion upgrades dashboard JSON models to the latest schema version.
+//
+// It is a port of the frontend DashboardMigrator so dashboards stored through the API, provisioning or
+// imports can be read by the backend without having to support every historic shape of the model.
+package schemaversion
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// LatestVersion is the schemaVersion dashboards are upgraded to, it must match the frontend DashboardMigrator.
+const LatestVersion = 36
+
+var ErrInvalidDashboard = errors.New("dashboard model is not a JSON object")
+
+// DataSourceRef is a reference to a data source as saved in dashboards since schema version 33.
+type DataSourceRef struct {
+	UID  string `json:"uid,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+// DataSourceLookup finds a data source by name or uid, an empty string returns the default data source.
+// It returns nil when the data source does not exist.
+type DataSourceLookup = func(nameOrUID string) *DataSourceRef
+
+// NeedsMigration returns true when the dashboard has not been upgraded to the latest schema version.
+// Like in the frontend, dashboards without a schemaVersion are considered as version 0.
+func NeedsMigration(dash *simplejson.Json) bool {
+	if dash == nil {
+		return false
+	}
+	return dash.Get("schemaVersion").MustInt(0) < LatestVersion
+}
+
+// Migrate upgrades the dashboard model in place to the latest schema version.
+// The lookup is only used by the migrations that replace data source names with references,
+// when nil the names are kept as data source uids.
+func Migrate(dash *simplejson.Json, lookup DataSourceLookup) error {
+	if dash == nil {
+		return ErrInvalidDashboard
+	}
+	data, ok := dash.Interface().(map[string]interface{})
+	if !ok {
+		return ErrInvalidDashboard
+	}
+	if lookup == nil {
+		lookup = func(string) *DataSourceRef { return nil }
+	}
+
+	m := &migrator{dash: data, lookup: lookup}
+	m.run(dash.Get("schemaVersion").MustInt(0))
+	return nil
+}
+
+// legacyProperties are the properties of old dashboard models that have been replaced by the migrations
+var legacyProperties = []string{"services", "pulldowns", "nav", "sharedCrosshair", "rows"}
+
+type panelUpgrade = func(panel map[string]interface{}) map[string]interface{}
+
+type migrator struct {
+	dash          map[string]interface{}
+	lookup        DataSourceLookup
+	panelUpgrades []panelUpgrade
+}
+
+func (m *migrator) run(oldVersion int) {
+	if oldVersion >= LatestVersion {
+		return
+	}
+
+	// the dashboard model always has panels, templating and annotations lists once loaded
+	m.ensureList("panels")
+	m.ensureNestedList("templating")
+	m.ensureNestedList("annotations")
+
+	for _, step := range steps {
+		if oldVersion < step.version {
+			step.migrate(m)
+		}
+	}
+	m.dash["schemaVersion"] = LatestVersion
+	for _, key := range legacyProperties {
+		delete(m.dash, key)
+	}
+
+	if len(m.panelUpgrades) == 0 {
+		return
+	}
+
+	panels := m.panels()
+	for _, upgrade := range m.panelUpgrades {
+		for i, panel := range panels {
+			panels[i] = upgrade(panel)
+			rowPanels := toList(panels[i]["panels"])
+			for j, rowPanel := range rowPanels {
+				if p, ok := rowPanel.(map[string]interface{}); ok {
+					rowPanels[j] = upgrade(p)
+				}
+			}
+		}
+	}
+	list := make([]interface{}, 0, len(panels))
+	for _, panel := range panels {
+		list = append(list, panel)
+	}
+	m.dash["panels"] = list
+}
+
+func (m *migrator) addPanelUpgrade(upgrade panelUpgrade) {
+	m.panelUpgrades = append(m.panelUpgrades, upgrade)
+}
+
+func (m *migrator) ensureList(key string) {
+	if _, ok := m.dash[key].([]interface{}); !ok {
+		m.dash[key] = []interface{}{}
+	}
+}
+
+func (m *migrator) ensureNestedList(key string) {
+	obj, ok := m.dash[key].(map[string]interface{})
+	if !ok {
+		obj = map[string]interface{}{}
+		m.dash[key] = obj
+	}
+	if _, ok := obj["list"].([]interface{}); !ok {
+		obj["list"] = []interface{}{}
+	}
+}
+
+func (m *migrator) panels() []map[string]interface{} {
+	return toObjects(m.dash["panels"])
+}
+
+func (m *migrator) variables() []map[string]interface{} {
+	return toObjects(toObject(m.dash["templating"])["list"])
+}
+
+func (m *migrator) annotations() []map[string]interface{} {
+	return toObjects(toObject(m.dash["annotations"])["list"])
+}
+
+// nextPanelID returns the id following the highest panel id, including the panels of collapsed rows.
+func (m *migrator) nextPanelID() int64 {
+	var max int64
+	for _, panel := range m.panels() {
+		if id, ok := toInt(panel["id"]); ok && id > max {
+			max = id
+		}
+		for _, rowPanel := range toObjects(panel["panels"]) {
+			if id, ok := toInt(rowPanel["id"]); ok && id > max {
+				max = id
+			}
+		}
+	}
+	return max + 1
+}
+
+// dataSourceRef converts a data source name to a reference, as migrateDatasourceNameToRef does in the frontend.
+func (m *migrator) dataSourceRef(nameOrRef interface{}, returnDefaultAsNull bool) interface{} {
+	if returnDefaultAsNull && (nameOrRef == nil || nameOrRef == "default") {
+		return nil
+	}
+	if ref, ok := nameOrRef.(map[string]interface{}); ok {
+		if _, ok := ref["uid"].(string); ok {
+			return ref
+		}
+	}
+
+	name, _ := nameOrRef.(string)
+	if ds := m.lookup(name); ds != nil {
+		return map[string]interface{}{"type": ds.Type, "uid": ds.UID}
+	}
+	// not found
+	return map[string]interface{}{"uid": nameOrRef}
+}
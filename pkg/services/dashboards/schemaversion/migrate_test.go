@@ -0,0 +1,180 @@
+package schemaversion
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func testLookup(nameOrUID string) *DataSourceRef {
+	switch nameOrUID {
+	case "":
+		return &DataSourceRef{UID: "default-uid", Type: "prometheus"}
+	case "gdev-prometheus", "gdev-prom-uid":
+		return &DataSourceRef{UID: "gdev-prom-uid", Type: "prometheus"}
+	case "-- Grafana --":
+		return &DataSourceRef{UID: "grafana", Type: "datasource"}
+	}
+	return nil
+}
+
+func readDashboard(t *testing.T, path string) *simplejson.Json {
+	t.Helper()
+	// nolint:gosec
+	// We can ignore the gosec G304 warning because this is a test with hardcoded input values
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	dash, err := simplejson.NewJson(data)
+	require.NoError(t, err)
+	return dash
+}
+
+// TestMigrateFixtures migrates a dashboard saved with every schema version and compares it with the expected result.
+// Missing or different results are written next to the fixtures so they can be reviewed and committed.
+func TestMigrateFixtures(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "v*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, inputs)
+
+	for _, input := range inputs {
+		if strings.HasSuffix(input, "-migrated.json") {
+			continue
+		}
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			dash := readDashboard(t, input)
+			require.NoError(t, Migrate(dash, testLookup))
+			require.Equal(t, LatestVersion, dash.Get("schemaVersion").MustInt())
+			require.False(t, NeedsMigration(dash))
+
+			out, err := json.MarshalIndent(dash, "", "  ")
+			require.NoError(t, err)
+
+			update := false
+			savedPath := strings.TrimSuffix(input, ".json") + "-migrated.json"
+			saved, err := os.ReadFile(savedPath)
+			if err != nil {
+				update = true
+				assert.NoError(t, err)
+			} else if !assert.JSONEq(t, string(saved), string(out)) {
+				update = true
+			}
+
+			if update {
+				_ = os.WriteFile(savedPath, append(out, '\n'), 0600)
+			}
+
+			// migrating again does not change the dashboard
+			require.NoError(t, Migrate(dash, testLookup))
+			again, err := json.Marshal(dash)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(out), string(again))
+		})
+	}
+}
+
+func TestMigrateOldSchema(t *testing.T) {
+	dash := readDashboard(t, filepath.Join("testdata", "v0.json"))
+	require.NoError(t, Migrate(dash, testLookup))
+
+	panels := dash.Get("panels")
+	graph := panels.GetIndex(0)
+	singlestat := panels.GetIndex(1)
+	singlestatGauge := panels.GetIndex(2)
+	table := panels.GetIndex(3)
+
+	t.Run("should move time and filtering list", func(t *testing.T) {
+		assert.Equal(t, "now-1d", dash.GetPath("time", "from").MustString())
+		assert.Equal(t, "glob", dash.GetPath("templating", "list").GetIndex(0).Get("allFormat").MustString())
+	})
+
+	t.Run("should move pulldowns to new schema", func(t *testing.T) {
+		assert.Equal(t, "old", dash.GetPath("annotations", "list").GetIndex(0).Get("name").MustString())
+	})
+
+	t.Run("should set panel ids and query refIds", func(t *testing.T) {
+		assert.Equal(t, 1, graph.Get("id").MustInt())
+		assert.Equal(t, "B", graph.Get("targets").GetIndex(1).Get("refId").MustString())
+	})
+
+	t.Run("should upgrade graph legend, series overrides, axes and thresholds", func(t *testing.T) {
+		assert.True(t, graph.GetPath("legend", "show").MustBool())
+		assert.Equal(t, "test", graph.Get("seriesOverrides").GetIndex(0).Get("alias").MustString())
+		assert.Equal(t, 2, graph.Get("seriesOverrides").GetIndex(0).Get("yaxis").MustInt())
+
+		left := graph.Get("yaxes").GetIndex(0)
+		assert.Equal(t, 1, left.Get("min").MustInt())
+		assert.Equal(t, 10, left.Get("max").MustInt())
+		assert.Equal(t, "kbyte", left.Get("format").MustString())
+		assert.Equal(t, "left label", left.Get("label").MustString())
+		assert.Equal(t, 1, left.Get("logBase").MustInt())
+		right := graph.Get("yaxes").GetIndex(1)
+		assert.Equal(t, 5, right.Get("min").MustInt())
+		assert.Equal(t, 15, right.Get("max").MustInt())
+		assert.Equal(t, "ms", right.Get("format").MustString())
+		assert.Equal(t, 2, right.Get("logBase").MustInt())
+
+		thresholds := graph.Get("thresholds")
+		assert.Equal(t, 200, thresholds.GetIndex(0).Get("value").MustInt())
+		assert.Equal(t, 400, thresholds.GetIndex(1).Get("value").MustInt())
+		assert.Equal(t, "yellow", thresholds.GetIndex(0).Get("fillColor").MustString())
+		assert.Equal(t, "red", thresholds.GetIndex(1).Get("fillColor").MustString())
+		assert.Equal(t, "gt", thresholds.GetIndex(0).Get("op").MustString())
+	})
+
+	t.Run("should map singlestat panels to stat and gauge panels", func(t *testing.T) {
+		assert.Equal(t, "stat", singlestat.Get("type").MustString())
+		steps := singlestat.GetPath("fieldConfig", "defaults", "thresholds", "steps")
+		assert.Equal(t, 30, steps.GetIndex(2).Get("value").MustInt())
+		assert.Equal(t, "#FF0000", steps.GetIndex(0).Get("color").MustString())
+
+		assert.Equal(t, "gauge", singlestatGauge.Get("type").MustString())
+		assert.True(t, singlestatGauge.GetPath("options", "showThresholdMarkers").MustBool())
+		assert.False(t, singlestatGauge.GetPath("options", "showThresholdLabels").MustBool(true))
+	})
+
+	t.Run("should remove the minimum of table thresholds and deprecate the table panel", func(t *testing.T) {
+		styles := table.Get("styles")
+		assert.Equal(t, []string{"20", "30"}, styles.GetIndex(0).Get("thresholds").MustStringArray())
+		assert.Equal(t, []string{"200", "300"}, styles.GetIndex(1).Get("thresholds").MustStringArray())
+		assert.Equal(t, "table-old", table.Get("type").MustString())
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	t.Run("should not change a dashboard with the latest schema version", func(t *testing.T) {
+		dash := simplejson.NewFromAny(map[string]interface{}{
+			"schemaVersion": LatestVersion,
+			"panels":        []interface{}{map[string]interface{}{"type": "singlestat"}},
+		})
+		require.NoError(t, Migrate(dash, nil))
+		assert.Equal(t, "singlestat", dash.Get("panels").GetIndex(0).Get("type").MustString())
+	})
+
+	t.Run("should keep data source names as uids without lookup", func(t *testing.T) {
+		dash := simplejson.NewFromAny(map[string]interface{}{
+			"schemaVersion": 32,
+			"panels":        []interface{}{map[string]interface{}{"id": 1, "type": "graph", "datasource": "prometheus"}},
+		})
+		require.NoError(t, Migrate(dash, nil))
+		assert.Equal(t, "prometheus", dash.Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+	})
+
+	t.Run("should fail when the dashboard is not an object", func(t *testing.T) {
+		require.ErrorIs(t, Migrate(simplejson.NewFromAny([]interface{}{}), nil), ErrInvalidDashboard)
+	})
+}
+
+func TestNeedsMigration(t *testing.T) {
+	assert.True(t, NeedsMigration(simplejson.NewFromAny(map[string]interface{}{"schemaVersion": 0})))
+	assert.True(t, NeedsMigration(simplejson.NewFromAny(map[string]interface{}{"schemaVersion": 27})))
+	assert.False(t, NeedsMigration(simplejson.NewFromAny(map[string]interface{}{"schemaVersion": LatestVersion})))
+	assert.True(t, NeedsMigration(simplejson.NewFromAny(map[string]interface{}{"title": "minimal"})))
+	assert.False(t, NeedsMigration(nil))
+}
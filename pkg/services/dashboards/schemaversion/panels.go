@@ -0,0 +1,383 @@
+package schemaversion
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// singlestatKeepProps are the properties kept when the angular singlestat panel is replaced by a stat or gauge panel.
+var singlestatKeepProps = map[string]bool{
+	"id": true, "gridPos": true, "type": true, "title": true, "scopedVars": true, "repeat": true,
+	"repeatIteration": true, "repeatPanelId": true, "repeatDirection": true, "repeatedByRow": true,
+	"minSpan": true, "collapsed": true, "panels": true, "targets": true, "datasource": true, "timeFrom": true,
+	"timeShift": true, "hideTimeOverride": true, "description": true, "links": true, "cacheTimeout": true,
+	"transparent": true, "pluginVersion": true, "transformations": true, "fieldConfig": true,
+	"maxDataPoints": true, "interval": true, "libraryPanel": true,
+}
+
+// reducers are the ids of the field reducers and their aliases
+var reducers = map[string]string{
+	"lastNotNull": "lastNotNull", "current": "lastNotNull", "last": "last", "firstNotNull": "firstNotNull",
+	"first": "first", "min": "min", "max": "max", "mean": "mean", "avg": "mean", "sum": "sum", "total": "sum",
+	"count": "count", "range": "range", "delta": "delta", "step": "step", "diff": "diff", "logmin": "logmin",
+	"allIsZero": "allIsZero", "allIsNull": "allIsNull", "changeCount": "changeCount",
+	"distinctCount": "distinctCount", "diffperc": "diffperc", "allValues": "allValues", "uniqueValues": "uniqueValues",
+}
+
+// migrateSinglestat replaces the angular singlestat panel by a gauge panel when it showed a gauge, or a stat panel.
+func migrateSinglestat(panel map[string]interface{}) map[string]interface{} {
+	prev := map[string]interface{}{}
+	for key, value := range panel {
+		if !singlestatKeepProps[key] {
+			prev[key] = value
+			delete(panel, key)
+		}
+	}
+
+	reducer, ok := reducers[toString(prev["valueName"])]
+	if !ok {
+		reducer = "mean"
+	}
+	reduceOptions := map[string]interface{}{"calcs": []interface{}{reducer}}
+	options := map[string]interface{}{"reduceOptions": reduceOptions, "orientation": "horizontal"}
+
+	defaults := map[string]interface{}{}
+	if truthy(prev["format"]) {
+		defaults["unit"] = prev["format"]
+	}
+	if truthy(prev["tableColumn"]) {
+		reduceOptions["fields"] = "/^" + toString(prev["tableColumn"]) + "$/"
+	}
+	if truthy(prev["nullPointMode"]) {
+		defaults["nullValueMode"] = prev["nullPointMode"]
+	}
+	if truthy(prev["nullText"]) {
+		defaults["noValue"] = prev["nullText"]
+	}
+	if truthy(prev["decimals"]) || isNumber(prev["decimals"]) {
+		defaults["decimals"] = prev["decimals"]
+	}
+
+	// convert thresholds and color values, there is one more color than thresholds
+	var thresholds map[string]interface{}
+	if levels, ok := prev["thresholds"].(string); ok && levels != "" && truthy(prev["colors"]) {
+		values := strings.Split(levels, ",")
+		var steps []interface{}
+		for i, color := range toList(prev["colors"]) {
+			if i == 0 {
+				steps = append(steps, map[string]interface{}{"value": nil, "color": color})
+				continue
+			}
+			var value interface{}
+			if i-1 < len(values) {
+				value = jsonNumber(toNumber(values[i-1]))
+			}
+			steps = append(steps, map[string]interface{}{"value": value, "color": color})
+		}
+		thresholds = map[string]interface{}{"mode": "absolute", "steps": steps}
+		defaults["thresholds"] = thresholds
+	}
+
+	if mappings := convertOldAngularValueMappings(prev, thresholds); len(mappings) > 0 {
+		defaults["mappings"] = mappings
+	}
+
+	gauge := toObject(prev["gauge"])
+	if truthy(gauge["show"]) {
+		setDefined(defaults, "min", gauge["minValue"])
+		setDefined(defaults, "max", gauge["maxValue"])
+	}
+
+	fieldConfig := toObject(panel["fieldConfig"])
+	if fieldConfig == nil {
+		fieldConfig = map[string]interface{}{"overrides": []interface{}{}}
+		panel["fieldConfig"] = fieldConfig
+	}
+	fieldConfig["defaults"] = defaults
+
+	if truthy(gauge["show"]) {
+		panel["type"] = "gauge"
+		setDefined(options, "showThresholdMarkers", gauge["thresholdMarkers"])
+		setDefined(options, "showThresholdLabels", gauge["thresholdLabels"])
+		panel["options"] = options
+		return panel
+	}
+
+	panel["type"] = "stat"
+	sparkline := toObject(prev["sparkline"])
+	options["graphMode"] = "none"
+	if truthy(sparkline["show"]) {
+		options["graphMode"] = "area"
+	}
+	switch {
+	case truthy(prev["colorBackground"]):
+		options["colorMode"] = "background"
+	case truthy(prev["colorValue"]):
+		options["colorMode"] = "value"
+	default:
+		options["colorMode"] = "none"
+		if truthy(sparkline["lineColor"]) && options["graphMode"] == "area" {
+			defaults["color"] = map[string]interface{}{"mode": "fixed", "fixedColor": sparkline["lineColor"]}
+		}
+	}
+	if prev["valueName"] == "name" {
+		options["textMode"] = "name"
+	}
+	panel["options"] = options
+	return panel
+}
+
+// convertOldAngularValueMappings converts the value or range maps of angular panels.
+func convertOldAngularValueMappings(panel map[string]interface{}, migratedThresholds map[string]interface{}) []interface{} {
+	mappingType, _ := toInt(panel["mappingType"])
+	if !truthy(panel["mappingType"]) {
+		if len(toList(panel["valueMaps"])) > 0 {
+			mappingType = 1
+		} else if len(toList(panel["rangeMaps"])) > 0 {
+			mappingType = 2
+		}
+	}
+
+	thresholds := toObject(toObject(toObject(panel["fieldConfig"])["defaults"])["thresholds"])
+	if thresholds == nil {
+		thresholds = migratedThresholds
+	}
+
+	var maps []interface{}
+	switch mappingType {
+	case 1:
+		maps = toList(panel["valueMaps"])
+	case 2:
+		maps = toList(panel["rangeMaps"])
+	}
+
+	var mappings []interface{}
+	for _, m := range maps {
+		old := copyObject(toObject(m))
+		old["type"] = mappingType
+		if mapping := upgradeValueMappings([]interface{}{old}, thresholds); len(mapping) > 0 {
+			mappings = append(mappings, mapping[0])
+		}
+	}
+	return mappings
+}
+
+func upgradeValueMappingsForPanel(panel map[string]interface{}) map[string]interface{} {
+	fieldConfig := toObject(panel["fieldConfig"])
+	if fieldConfig == nil {
+		return panel
+	}
+
+	if defaults := toObject(fieldConfig["defaults"]); defaults != nil && truthy(defaults["mappings"]) {
+		defaults["mappings"] = upgradeValueMappings(toList(defaults["mappings"]), toObject(defaults["thresholds"]))
+	}
+
+	for _, override := range toObjects(fieldConfig["overrides"]) {
+		for _, prop := range toObjects(override["properties"]) {
+			if prop["id"] == "mappings" {
+				prop["value"] = upgradeValueMappings(toList(prop["value"]), nil)
+			}
+		}
+	}
+	return panel
+}
+
+// upgradeValueMappings converts the legacy value and range mappings, value to text mappings are merged in a single value map.
+func upgradeValueMappings(oldMappings []interface{}, thresholds map[string]interface{}) []interface{} {
+	valueMaps := map[string]interface{}{}
+	newMappings := []interface{}{}
+
+	for _, m := range oldMappings {
+		old := toObject(m)
+		if old == nil {
+			continue
+		}
+
+		// already migrated mappings
+		if truthy(old["type"]) && truthy(old["options"]) {
+			if old["type"] == "value" {
+				for k, v := range toObject(old["options"]) {
+					valueMaps[k] = v
+				}
+			} else {
+				newMappings = append(newMappings, old)
+			}
+			continue
+		}
+
+		// use the color we would have picked from thresholds
+		result := map[string]interface{}{}
+		setDefined(result, "text", old["text"])
+		if numeric, ok := parseFloat(old["text"]); ok && thresholds != nil {
+			if color := activeThresholdColor(numeric, toObjects(thresholds["steps"])); color != nil {
+				result["color"] = color
+			}
+		}
+
+		mappingType, _ := toInt(old["type"])
+		switch mappingType {
+		case 1:
+			if old["value"] == nil {
+				break
+			}
+			if old["value"] == "null" {
+				newMappings = append(newMappings, map[string]interface{}{
+					"type":    "special",
+					"options": map[string]interface{}{"match": "null", "result": result},
+				})
+			} else {
+				valueMaps[valueKey(old["value"])] = result
+			}
+		case 2:
+			if old["from"] == "null" || old["to"] == "null" {
+				newMappings = append(newMappings, map[string]interface{}{
+					"type":    "special",
+					"options": map[string]interface{}{"match": "null", "result": result},
+				})
+				break
+			}
+			newMappings = append(newMappings, map[string]interface{}{
+				"type": "range",
+				"options": map[string]interface{}{
+					"from":   jsonNumber(toNumber(old["from"])),
+					"to":     jsonNumber(toNumber(old["to"])),
+					"result": result,
+				},
+			})
+		}
+	}
+
+	if len(valueMaps) > 0 {
+		newMappings = append([]interface{}{map[string]interface{}{"type": "value", "options": valueMaps}}, newMappings...)
+	}
+	return newMappings
+}
+
+func valueKey(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if f, ok := toFloat(v); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	if b, ok := v.(bool); ok {
+		return strconv.FormatBool(b)
+	}
+	return ""
+}
+
+// activeThresholdColor returns the color of the last threshold step lower or equal to the value.
+func activeThresholdColor(value float64, steps []map[string]interface{}) interface{} {
+	if len(steps) == 0 {
+		return nil
+	}
+	active := steps[0]
+	for _, step := range steps {
+		// the base step has a null value, which means -Infinity
+		stepValue, ok := toFloat(step["value"])
+		if !ok {
+			stepValue = math.Inf(-1)
+		}
+		if value < stepValue {
+			break
+		}
+		active = step
+	}
+	return active["color"]
+}
+
+func isCloudWatchQuery(target map[string]interface{}) bool {
+	return hasKeys(target, "dimensions", "namespace", "region", "metricName")
+}
+
+func isLegacyCloudWatchAnnotationQuery(annotation map[string]interface{}) bool {
+	return hasKeys(annotation, "dimensions", "namespace", "region", "prefixMatching", "statistics")
+}
+
+func hasKeys(obj map[string]interface{}, keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := obj[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// migrateCloudWatchQueries splits the CloudWatch metric queries using several statistics into one query per statistic,
+// the new queries are added at the end of the panel queries.
+func migrateCloudWatchQueries(panel map[string]interface{}) {
+	targets := toObjects(panel["targets"])
+	for _, target := range targets {
+		if !isCloudWatchQuery(target) {
+			continue
+		}
+
+		if _, ok := target["metricQueryType"]; !ok {
+			target["metricQueryType"] = 0
+		}
+		if _, ok := target["metricEditorMode"]; !ok {
+			if queryType, _ := toInt(target["metricQueryType"]); queryType == 1 || truthy(target["expression"]) {
+				target["metricEditorMode"] = 1
+			} else {
+				target["metricEditorMode"] = 0
+			}
+		}
+
+		statistics, ok := target["statistics"]
+		if !ok {
+			continue
+		}
+		if stats := toList(statistics); len(stats) > 0 {
+			target["statistic"] = stats[0]
+			for _, stat := range stats[1:] {
+				query := copyObject(target)
+				query["statistic"] = stat
+				delete(query, "statistics")
+				query["refId"] = nextRefID(targets)
+				targets = append(targets, query)
+			}
+		}
+		delete(target, "statistics")
+	}
+
+	if _, ok := panel["targets"].([]interface{}); ok {
+		list := make([]interface{}, 0, len(targets))
+		for _, target := range targets {
+			list = append(list, target)
+		}
+		panel["targets"] = list
+	}
+}
+
+// migrateCloudWatchAnnotationQueries splits the CloudWatch annotation queries using several statistics
+// into one query per statistic.
+func (m *migrator) migrateCloudWatchAnnotationQueries() {
+	annotations := toObject(m.dash["annotations"])
+	list := toList(annotations["list"])
+	for _, annotation := range toObjects(list) {
+		if !isLegacyCloudWatchAnnotationQuery(annotation) {
+			continue
+		}
+		stats := toList(annotation["statistics"])
+		if len(stats) == 0 {
+			continue
+		}
+
+		name := toString(annotation["name"])
+		for _, stat := range stats[1:] {
+			query := copyObject(annotation)
+			delete(query, "statistics")
+			query["statistic"] = stat
+			query["name"] = name + " - " + valueKey(stat)
+			list = append(list, query)
+		}
+		annotation["statistic"] = stats[0]
+		// only change the name of the original if new annotations have been created
+		if len(stats) > 1 {
+			annotation["name"] = name + " - " + valueKey(stats[0])
+		}
+		delete(annotation, "statistics")
+	}
+	annotations["list"] = list
+}
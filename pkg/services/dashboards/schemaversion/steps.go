@@ -0,0 +1,839 @@
+package schemaversion
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// gridColumnCount is the number of columns of the dashboard grid layout
+const gridColumnCount = 24
+
+// steps are the migrations applied to a dashboard with a schema version lower than their version.
+// Each step mirrors the block with the same version in the frontend DashboardMigrator.updateSchema.
+var steps = []struct {
+	version int
+	migrate func(m *migrator)
+}{
+	{2, (*migrator).v2},
+	{3, (*migrator).v3},
+	{4, (*migrator).v4},
+	{6, (*migrator).v6},
+	{7, (*migrator).v7},
+	{8, (*migrator).v8},
+	{9, (*migrator).v9},
+	{10, (*migrator).v10},
+	{12, (*migrator).v12},
+	{13, (*migrator).v13},
+	{14, (*migrator).v14},
+	{16, (*migrator).v16},
+	{17, (*migrator).v17},
+	{18, (*migrator).v18},
+	{19, (*migrator).v19},
+	{20, (*migrator).v20},
+	{21, (*migrator).v21},
+	{22, (*migrator).v22},
+	{23, (*migrator).v23},
+	{24, (*migrator).v24},
+	{26, (*migrator).v26},
+	{27, (*migrator).v27},
+	{28, (*migrator).v28},
+	{29, (*migrator).v29},
+	{30, (*migrator).v30},
+	{31, (*migrator).v31},
+	{33, (*migrator).v33},
+	{34, (*migrator).v34},
+	{35, (*migrator).v35},
+	{36, (*migrator).v36},
+}
+
+func (m *migrator) v2() {
+	if filter := toObject(toObject(m.dash["services"])["filter"]); filter != nil {
+		m.dash["time"] = filter["time"]
+		list := toList(filter["list"])
+		if list == nil {
+			list = []interface{}{}
+		}
+		toObject(m.dash["templating"])["list"] = list
+	}
+
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		// rename panel type
+		if panel["type"] == "graphite" {
+			panel["type"] = "graph"
+		}
+		if panel["type"] != "graph" {
+			return panel
+		}
+
+		if legend, ok := panel["legend"].(bool); ok {
+			panel["legend"] = map[string]interface{}{"show": legend}
+		}
+
+		if grid := toObject(panel["grid"]); grid != nil {
+			if truthy(grid["min"]) {
+				grid["leftMin"] = grid["min"]
+				delete(grid, "min")
+			}
+			if truthy(grid["max"]) {
+				grid["leftMax"] = grid["max"]
+				delete(grid, "max")
+			}
+		}
+
+		for i, key := range []string{"y_format", "y2_format"} {
+			if !truthy(panel[key]) {
+				continue
+			}
+			formats := toList(panel["y_formats"])
+			for len(formats) <= i {
+				formats = append(formats, nil)
+			}
+			formats[i] = panel[key]
+			panel["y_formats"] = formats
+			delete(panel, key)
+		}
+
+		return panel
+	})
+}
+
+func (m *migrator) v3() {
+	// ensure panel IDs
+	maxID := m.nextPanelID()
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if !truthy(panel["id"]) {
+			panel["id"] = maxID
+			maxID++
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v4() {
+	// move aliasYAxis to series overrides
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if panel["type"] != "graph" {
+			return panel
+		}
+
+		if aliases := toObject(panel["aliasYAxis"]); len(aliases) > 0 {
+			// the frontend kept a single override, keep one per alias in a stable order
+			keys := make([]string, 0, len(aliases))
+			for alias := range aliases {
+				keys = append(keys, alias)
+			}
+			sort.Strings(keys)
+
+			overrides := make([]interface{}, 0, len(keys))
+			for _, alias := range keys {
+				overrides = append(overrides, map[string]interface{}{"alias": alias, "yaxis": aliases[alias]})
+			}
+			panel["seriesOverrides"] = overrides
+		}
+		delete(panel, "aliasYAxis")
+
+		return panel
+	})
+}
+
+func (m *migrator) v6() {
+	// move drop-downs to new schema
+	for _, pulldown := range toObjects(m.dash["pulldowns"]) {
+		if pulldown["type"] != "annotations" {
+			continue
+		}
+		list := toList(pulldown["annotations"])
+		if list == nil {
+			list = []interface{}{}
+		}
+		m.dash["annotations"] = map[string]interface{}{"list": list}
+		break
+	}
+
+	// update template variables
+	for _, variable := range m.variables() {
+		if _, ok := variable["datasource"]; !ok {
+			variable["datasource"] = nil
+		}
+		if t, ok := variable["type"]; !ok || t == "filter" {
+			variable["type"] = "query"
+		}
+		if _, ok := variable["allFormat"]; !ok {
+			variable["allFormat"] = "glob"
+		}
+	}
+}
+
+func (m *migrator) v7() {
+	if nav := toList(m.dash["nav"]); len(nav) > 0 {
+		m.dash["timepicker"] = nav[0]
+	}
+
+	// ensure query refIds
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		targets := toObjects(panel["targets"])
+		for _, target := range targets {
+			if !truthy(target["refId"]) {
+				target["refId"] = nextRefID(targets)
+			}
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v8() {
+	// update old influxdb query schema
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		for _, target := range toObjects(panel["targets"]) {
+			if !truthy(target["fields"]) || !truthy(target["tags"]) || !truthy(target["groupBy"]) {
+				continue
+			}
+
+			if truthy(target["rawQuery"]) {
+				delete(target, "fields")
+				delete(target, "fill")
+				continue
+			}
+
+			var selects []interface{}
+			for _, field := range toObjects(target["fields"]) {
+				parts := []interface{}{
+					map[string]interface{}{"type": "field", "params": []interface{}{field["name"]}},
+					map[string]interface{}{"type": field["func"], "params": []interface{}{}},
+				}
+				if truthy(field["mathExpr"]) {
+					parts = append(parts, map[string]interface{}{"type": "math", "params": []interface{}{field["mathExpr"]}})
+				}
+				if truthy(field["asExpr"]) {
+					parts = append(parts, map[string]interface{}{"type": "alias", "params": []interface{}{field["asExpr"]}})
+				}
+				selects = append(selects, parts)
+			}
+			target["select"] = selects
+			delete(target, "fields")
+
+			for _, part := range toObjects(target["groupBy"]) {
+				if part["type"] == "time" && truthy(part["interval"]) {
+					part["params"] = []interface{}{part["interval"]}
+					delete(part, "interval")
+				}
+				if part["type"] == "tag" && truthy(part["key"]) {
+					part["params"] = []interface{}{part["key"]}
+					delete(part, "key")
+				}
+			}
+
+			if truthy(target["fill"]) {
+				target["groupBy"] = append(toList(target["groupBy"]), map[string]interface{}{"type": "fill", "params": []interface{}{target["fill"]}})
+				delete(target, "fill")
+			}
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v9() {
+	// singlestat thresholds no longer include the minimum
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if panel["type"] != "singlestat" {
+			return panel
+		}
+
+		if thresholds, ok := panel["thresholds"].(string); ok && thresholds != "" {
+			if k := strings.Split(thresholds, ","); len(k) >= 3 {
+				panel["thresholds"] = strings.Join(k[1:], ",")
+			}
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v10() {
+	// table thresholds no longer include the minimum
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if panel["type"] != "table" {
+			return panel
+		}
+
+		for _, style := range toObjects(panel["styles"]) {
+			if thresholds := toList(style["thresholds"]); len(thresholds) >= 3 {
+				style["thresholds"] = thresholds[1:]
+			}
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v12() {
+	// update template variables
+	for _, variable := range m.variables() {
+		if truthy(variable["refresh"]) {
+			variable["refresh"] = 1
+		} else {
+			variable["refresh"] = 0
+		}
+		if truthy(variable["hideVariable"]) {
+			variable["hide"] = 2
+		} else if truthy(variable["hideLabel"]) {
+			variable["hide"] = 1
+		}
+	}
+
+	// update graph yaxes changes
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if panel["type"] != "graph" {
+			return panel
+		}
+		grid := toObject(panel["grid"])
+		if grid == nil {
+			return panel
+		}
+
+		if !truthy(panel["yaxes"]) {
+			formats := toList(panel["y_formats"])
+			format := func(i int) interface{} {
+				if i < len(formats) {
+					return formats[i]
+				}
+				return nil
+			}
+			left := map[string]interface{}{}
+			setDefined(left, "show", panel["y-axis"])
+			setDefined(left, "min", grid["leftMin"])
+			setDefined(left, "max", grid["leftMax"])
+			setDefined(left, "logBase", grid["leftLogBase"])
+			setDefined(left, "format", format(0))
+			setDefined(left, "label", panel["leftYAxisLabel"])
+			right := map[string]interface{}{}
+			setDefined(right, "show", panel["y-axis"])
+			setDefined(right, "min", grid["rightMin"])
+			setDefined(right, "max", grid["rightMax"])
+			setDefined(right, "logBase", grid["rightLogBase"])
+			setDefined(right, "format", format(1))
+			setDefined(right, "label", panel["rightYAxisLabel"])
+			panel["yaxes"] = []interface{}{left, right}
+
+			xaxis := map[string]interface{}{}
+			setDefined(xaxis, "show", panel["x-axis"])
+			panel["xaxis"] = xaxis
+
+			for _, key := range []string{"leftMin", "leftMax", "leftLogBase", "rightMin", "rightMax", "rightLogBase"} {
+				delete(grid, key)
+			}
+			for _, key := range []string{"y_formats", "leftYAxisLabel", "rightYAxisLabel", "y-axis", "x-axis"} {
+				delete(panel, key)
+			}
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v13() {
+	// move graph thresholds out of the grid options
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if panel["type"] != "graph" {
+			return panel
+		}
+		grid := toObject(panel["grid"])
+		if grid == nil {
+			return panel
+		}
+
+		thresholds := toList(panel["thresholds"])
+		if thresholds == nil {
+			thresholds = []interface{}{}
+		}
+
+		threshold := func(n string) map[string]interface{} {
+			if !isNumber(grid["threshold"+n]) {
+				return nil
+			}
+			t := map[string]interface{}{"value": grid["threshold"+n], "colorMode": "custom"}
+			if truthy(grid["thresholdLine"]) {
+				t["line"] = true
+				setDefined(t, "lineColor", grid["threshold"+n+"Color"])
+			} else {
+				t["fill"] = true
+				setDefined(t, "fillColor", grid["threshold"+n+"Color"])
+			}
+			return t
+		}
+
+		if t1 := threshold("1"); t1 != nil {
+			if t2 := threshold("2"); t2 != nil {
+				v1, _ := toFloat(t1["value"])
+				v2, _ := toFloat(t2["value"])
+				op := "gt"
+				if v1 > v2 {
+					op = "lt"
+				}
+				t1["op"], t2["op"] = op, op
+				thresholds = append(thresholds, t1, t2)
+			} else {
+				t1["op"] = "gt"
+				thresholds = append(thresholds, t1)
+			}
+		}
+		panel["thresholds"] = thresholds
+
+		for _, key := range []string{"threshold1", "threshold1Color", "threshold2", "threshold2Color", "thresholdLine"} {
+			delete(grid, key)
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v14() {
+	if truthy(m.dash["sharedCrosshair"]) {
+		m.dash["graphTooltip"] = 1
+	} else {
+		m.dash["graphTooltip"] = 0
+	}
+}
+
+func (m *migrator) v16() {
+	m.upgradeToGridLayout()
+}
+
+func (m *migrator) v17() {
+	// replace the minimum span of repeated panels by the maximum number of panels per row
+	factors := []float64{1, 2, 3, 4, 6, 8, 12, 24}
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if minSpan, ok := toFloat(panel["minSpan"]); ok && minSpan != 0 {
+			max := gridColumnCount / minSpan
+			// find the best match compared to the factors of the column count
+			for i, f := range factors {
+				if f > max {
+					if i > 0 {
+						panel["maxPerRow"] = factors[i-1]
+					}
+					break
+				}
+			}
+		}
+		delete(panel, "minSpan")
+		return panel
+	})
+}
+
+func (m *migrator) v18() {
+	// migrate change to gauge options
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		options := toObject(panel["options-gauge"])
+		if options == nil {
+			return panel
+		}
+
+		valueOptions := map[string]interface{}{}
+		for _, key := range []string{"unit", "stat", "decimals", "prefix", "suffix"} {
+			setDefined(valueOptions, key, options[key])
+			delete(options, key)
+		}
+		options["valueOptions"] = valueOptions
+
+		// correct order
+		if thresholds := toList(options["thresholds"]); thresholds != nil {
+			for i, j := 0, len(thresholds)-1; i < j; i, j = i+1, j-1 {
+				thresholds[i], thresholds[j] = thresholds[j], thresholds[i]
+			}
+		}
+
+		// this options prop was due to a bug
+		delete(options, "options")
+		panel["options"] = options
+		delete(panel, "options-gauge")
+		return panel
+	})
+}
+
+func (m *migrator) v19() {
+	// migrate panel links to data links
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if links, ok := panel["links"].([]interface{}); ok {
+			for i, link := range links {
+				links[i] = upgradePanelLink(toObject(link))
+			}
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v20() {
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		updateDataLinks(panel, updateVariablesSyntax)
+		if defaults := toObject(toObject(toObject(panel["options"])["fieldOptions"])["defaults"]); defaults != nil {
+			if title, ok := defaults["title"].(string); ok && title != "" {
+				defaults["title"] = updateVariablesSyntax(title)
+			}
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v21() {
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		updateDataLinks(panel, func(url string) string {
+			return strings.ReplaceAll(url, "__series.labels", "__field.labels")
+		})
+		return panel
+	})
+}
+
+func (m *migrator) v22() {
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if panel["type"] != "table" {
+			return panel
+		}
+		for _, style := range toObjects(panel["styles"]) {
+			style["align"] = "auto"
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v23() {
+	// align the current value of variables with their multi setting
+	for _, variable := range m.variables() {
+		multi, ok := variable["multi"].(bool)
+		if !ok {
+			continue
+		}
+		current := toObject(variable["current"])
+		if current == nil {
+			continue
+		}
+
+		_, isArray := current["value"].([]interface{})
+		if multi && !isArray {
+			current = copyObject(current)
+			current["value"] = convertToMulti(current["value"])
+			current["text"] = convertToMulti(current["text"])
+		} else if !multi && isArray {
+			current = copyObject(current)
+			current["value"] = convertToSingle(current["value"])
+			current["text"] = convertToSingle(current["text"])
+		}
+		variable["current"] = current
+	}
+}
+
+func (m *migrator) v24() {
+	// migrate existing tables to table-old
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		// styles are missing so assumes default settings
+		if panel["type"] == "table" && truthy(panel["styles"]) && panel["table"] != "table2" {
+			panel["type"] = "table-old"
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v26() {
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if panel["type"] != "text2" {
+			return panel
+		}
+		panel["type"] = "text"
+		if options := toObject(panel["options"]); options != nil {
+			delete(options, "angular")
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v27() {
+	for _, variable := range m.variables() {
+		if variable["type"] != "constant" {
+			continue
+		}
+
+		if hide, ok := toInt(variable["hide"]); ok && (hide == 0 || hide == 1) {
+			variable["type"] = "textbox"
+		}
+
+		query, ok := variable["query"]
+		if !ok || query == nil {
+			query = ""
+		}
+		current := map[string]interface{}{"selected": true, "text": query, "value": query}
+		variable["current"] = current
+		variable["options"] = []interface{}{current}
+	}
+}
+
+func (m *migrator) v28() {
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if panel["type"] == "singlestat" {
+			return migrateSinglestat(panel)
+		}
+		return panel
+	})
+
+	for _, variable := range m.variables() {
+		for _, key := range []string{"tags", "tagsQuery", "tagValuesQuery", "useTags"} {
+			delete(variable, key)
+		}
+	}
+}
+
+func (m *migrator) v29() {
+	for _, variable := range m.variables() {
+		if variable["type"] != "query" {
+			continue
+		}
+		if refresh, ok := toInt(variable["refresh"]); !ok || (refresh != 1 && refresh != 2) {
+			variable["refresh"] = 1
+		}
+		if len(toList(variable["options"])) > 0 {
+			variable["options"] = []interface{}{}
+		}
+	}
+}
+
+func (m *migrator) v30() {
+	m.addPanelUpgrade(upgradeValueMappingsForPanel)
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if panel["type"] != "timeseries" && panel["type"] != "xychart" {
+			return panel
+		}
+		options := toObject(panel["options"])
+		if options != nil && truthy(options["tooltipOptions"]) {
+			options["tooltip"] = options["tooltipOptions"]
+			delete(options, "tooltipOptions")
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v31() {
+	// the labels to fields transformation no longer merges the results
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		transformations, ok := panel["transformations"].([]interface{})
+		if !ok {
+			return panel
+		}
+		result := make([]interface{}, 0, len(transformations))
+		for _, t := range transformations {
+			result = append(result, t)
+			if toObject(t)["id"] == "labelsToFields" {
+				result = append(result, map[string]interface{}{"id": "merge", "options": map[string]interface{}{}})
+			}
+		}
+		panel["transformations"] = result
+		return panel
+	})
+}
+
+func (m *migrator) v33() {
+	// replace datasource names with references
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		panel["datasource"] = m.dataSourceRef(panel["datasource"], true)
+
+		for _, target := range toObjects(panel["targets"]) {
+			if ref := m.dataSourceRef(target["datasource"], true); ref != nil {
+				target["datasource"] = ref
+			}
+		}
+		return panel
+	})
+}
+
+func (m *migrator) v34() {
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		migrateCloudWatchQueries(panel)
+		return panel
+	})
+	m.migrateCloudWatchAnnotationQueries()
+}
+
+func (m *migrator) v35() {
+	// keep the x axis visible on time series panels with all axes hidden
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		if panel["type"] != "timeseries" {
+			return panel
+		}
+		fieldConfig := toObject(panel["fieldConfig"])
+		if toObject(toObject(fieldConfig["defaults"])["custom"])["axisPlacement"] != "hidden" {
+			return panel
+		}
+
+		fieldConfig["overrides"] = append(toList(fieldConfig["overrides"]), map[string]interface{}{
+			"matcher": map[string]interface{}{"id": "byType", "options": "time"},
+			"properties": []interface{}{
+				map[string]interface{}{"id": "custom.axisPlacement", "value": "auto"},
+			},
+		})
+		return panel
+	})
+}
+
+func (m *migrator) v36() {
+	// migrate datasource to refs in annotations
+	for _, query := range m.annotations() {
+		query["datasource"] = m.dataSourceRef(query["datasource"], false)
+	}
+
+	// migrate datasource: null to current default
+	defaultDS := m.lookup("")
+	if defaultDS == nil {
+		return
+	}
+	defaultRef := func() map[string]interface{} {
+		return map[string]interface{}{"type": defaultDS.Type, "uid": defaultDS.UID}
+	}
+
+	for _, variable := range m.variables() {
+		if ds, ok := variable["datasource"]; variable["type"] == "query" && ok && ds == nil {
+			variable["datasource"] = defaultRef()
+		}
+	}
+
+	m.addPanelUpgrade(func(panel map[string]interface{}) map[string]interface{} {
+		targets := toObjects(panel["targets"])
+		if _, ok := panel["targets"].([]interface{}); !ok {
+			return panel
+		}
+
+		panelDataSourceWasDefault := false
+		if panel["datasource"] == nil && len(targets) > 0 {
+			panel["datasource"] = defaultRef()
+			panelDataSourceWasDefault = true
+		}
+
+		for _, target := range targets {
+			ds := toObject(target["datasource"])
+			if ds == nil || ds["uid"] == nil {
+				if panelDS := toObject(panel["datasource"]); panelDS != nil {
+					target["datasource"] = copyObject(panelDS)
+				} else {
+					target["datasource"] = map[string]interface{}{}
+				}
+			}
+
+			// when the default data source changed the queries data source is the source of truth
+			if panelDataSourceWasDefault && toObject(target["datasource"])["uid"] != "__expr__" {
+				panel["datasource"] = target["datasource"]
+			}
+		}
+		return panel
+	})
+}
+
+// setDefined sets a key only when the value is defined, like javascript object literals do with undefined values.
+func setDefined(obj map[string]interface{}, key string, value interface{}) {
+	if value != nil {
+		obj[key] = value
+	}
+}
+
+func convertToMulti(value interface{}) interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{value}
+}
+
+func convertToSingle(value interface{}) interface{} {
+	list, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+	if len(list) > 0 {
+		return list[0]
+	}
+	return ""
+}
+
+func upgradePanelLink(link map[string]interface{}) map[string]interface{} {
+	url := toString(link["url"])
+	if url == "" && truthy(link["dashboard"]) {
+		url = "dashboard/db/" + slugifyForURL(toString(link["dashboard"]))
+	}
+	if url == "" && truthy(link["dashUri"]) {
+		url = "dashboard/" + toString(link["dashUri"])
+	}
+	// some models are incomplete and have no dashboard or dashUri
+	if url == "" {
+		url = "/"
+	}
+
+	if truthy(link["keepTime"]) {
+		url = appendQueryToURL(url, "$__url_time_range")
+	}
+	if truthy(link["includeVars"]) {
+		url = appendQueryToURL(url, "$__all_variables")
+	}
+	if params := toString(link["params"]); params != "" {
+		url = appendQueryToURL(url, params)
+	}
+
+	upgraded := map[string]interface{}{"url": url}
+	setDefined(upgraded, "title", link["title"])
+	setDefined(upgraded, "targetBlank", link["targetBlank"])
+	return upgraded
+}
+
+var (
+	slugifyInvalidChars = regexp.MustCompile(`[^\w ]+`)
+	slugifySpaces       = regexp.MustCompile(` +`)
+)
+
+func slugifyForURL(s string) string {
+	s = slugifyInvalidChars.ReplaceAllString(strings.ToLower(s), "")
+	return slugifySpaces.ReplaceAllString(s, "-")
+}
+
+func appendQueryToURL(url, query string) string {
+	if query == "" {
+		return url
+	}
+	if pos := strings.Index(url, "?"); pos != -1 {
+		if len(url)-pos > 1 {
+			url += "&"
+		}
+	} else {
+		url += "?"
+	}
+	return url + query
+}
+
+// updateDataLinks updates the url of the data links of graph panels and of panels with field options.
+func updateDataLinks(panel map[string]interface{}, update func(string) string) {
+	options := toObject(panel["options"])
+	if options == nil {
+		return
+	}
+
+	links := []interface{}{options["dataLinks"]}
+	if defaults := toObject(toObject(options["fieldOptions"])["defaults"]); defaults != nil {
+		links = append(links, defaults["links"])
+	}
+	for _, list := range links {
+		for _, link := range toObjects(list) {
+			if url, ok := link["url"].(string); ok {
+				link["url"] = update(url)
+			}
+		}
+	}
+}
+
+var legacyVariableNamesRegex = regexp.MustCompile(`(__series_name)|(\$__series_name)|(__value_time)|(__field_name)|(\$__field_name)`)
+
+func updateVariablesSyntax(text string) string {
+	return legacyVariableNamesRegex.ReplaceAllStringFunc(text, func(match string) string {
+		switch match {
+		case "__series_name":
+			return "__series.name"
+		case "$__series_name":
+			return "${__series.name}"
+		case "__value_time":
+			return "__value.time"
+		case "__field_name":
+			return "__field.name"
+		case "$__field_name":
+			return "${__field.name}"
+		}
+		return match
+	})
+}
@@ -0,0 +1,162 @@
+package schemaversion
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The dashboard model is decoded with json.Number and may contain values set from Go,
+// these helpers read it with the same loose semantics as the frontend.
+
+func toObject(v interface{}) map[string]interface{} {
+	obj, _ := v.(map[string]interface{})
+	return obj
+}
+
+func toList(v interface{}) []interface{} {
+	list, _ := v.([]interface{})
+	return list
+}
+
+func toObjects(v interface{}) []map[string]interface{} {
+	var objects []map[string]interface{}
+	for _, item := range toList(v) {
+		if obj, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, obj)
+		}
+	}
+	return objects
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func toInt(v interface{}) (int64, bool) {
+	f, ok := toFloat(v)
+	return int64(f), ok
+}
+
+func isNumber(v interface{}) bool {
+	_, ok := toFloat(v)
+	return ok
+}
+
+// parseFloat behaves like the javascript parseFloat, parsing the longest numeric prefix of a string.
+func parseFloat(v interface{}) (float64, bool) {
+	if f, ok := toFloat(v); ok {
+		return f, true
+	}
+	s, ok := v.(string)
+	if !ok {
+		return 0, false
+	}
+	s = strings.TrimSpace(s)
+	for i := len(s); i > 0; i-- {
+		if f, err := strconv.ParseFloat(s[:i], 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// toNumber behaves like the javascript unary plus operator.
+func toNumber(v interface{}) float64 {
+	if f, ok := toFloat(v); ok {
+		return f
+	}
+	switch val := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if val {
+			return 1
+		}
+		return 0
+	case string:
+		val = strings.TrimSpace(val)
+		if val == "" {
+			return 0
+		}
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+	}
+	return math.NaN()
+}
+
+// jsonNumber returns a value that can be encoded as JSON, NaN and infinities are encoded as null like in javascript.
+func jsonNumber(f float64) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return f
+}
+
+func truthy(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		return val != ""
+	}
+	if f, ok := toFloat(v); ok {
+		return f != 0 && !math.IsNaN(f)
+	}
+	return true
+}
+
+func toString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// refID returns the letters used as query refId for a query index: A, B, ..., Z, AA, AB...
+func refID(num int) string {
+	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	if num < len(letters) {
+		return string(letters[num])
+	}
+	return refID(num/len(letters)-1) + string(letters[num%len(letters)])
+}
+
+func nextRefID(targets []map[string]interface{}) string {
+	for num := 0; ; num++ {
+		id := refID(num)
+		used := false
+		for _, target := range targets {
+			if target["refId"] == id {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return id
+		}
+	}
+}
+
+func copyObject(obj map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		c[k] = v
+	}
+	return c
+}
@@ -3,10 +3,18 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 )
 
 // Gets public dashboard via generated Uid
@@ -36,6 +44,13 @@ func (dr *DashboardServiceImpl) GetPublicDashboard(ctx context.Context, dashboar
 		d.Data.Set("time", pdcTimeSettings)
 	}
 
+	if d.Data != nil {
+		d.Data.SetPath([]string{"timepicker", "hidden"}, !pdc.TimeSelectionEnabled)
+		if !pdc.AnnotationsEnabled {
+			d.Data.Del("annotations")
+		}
+	}
+
 	return d, nil
 }
 
@@ -62,6 +77,22 @@ func (dr *DashboardServiceImpl) SavePublicDashboardConfig(ctx context.Context, d
 	cmd.PublicDashboardConfig.PublicDashboard.OrgId = dto.OrgId
 	cmd.PublicDashboardConfig.PublicDashboard.DashboardUid = dto.DashboardUid
 
+	if maxTimeRange := cmd.PublicDashboardConfig.PublicDashboard.MaxTimeRange; maxTimeRange != "" {
+		if d, err := gtime.ParseDuration(maxTimeRange); err != nil || d <= 0 {
+			return nil, models.ErrPublicDashboardInvalidMaxTimeRange
+		}
+	}
+
+	if cmd.PublicDashboardConfig.IsPublic {
+		dashboard, err := dr.dashboardStore.GetDashboard(ctx, &models.GetDashboardQuery{OrgId: dto.OrgId, Uid: dto.DashboardUid})
+		if err != nil {
+			return nil, err
+		}
+		if err := validatePublicDashboardVariables(dashboard.Data); err != nil {
+			return nil, err
+		}
+	}
+
 	pdc, err := dr.dashboardStore.SavePublicDashboardConfig(cmd)
 	if err != nil {
 		return nil, err
@@ -70,7 +101,10 @@ func (dr *DashboardServiceImpl) SavePublicDashboardConfig(ctx context.Context, d
 	return pdc, nil
 }
 
-func (dr *DashboardServiceImpl) BuildPublicDashboardMetricRequest(ctx context.Context, publicDashboardUid string, panelId int64) (dtos.MetricRequest, error) {
+// BuildPublicDashboardMetricRequest returns the queries of a panel on a public dashboard. Template variables are
+// resolved server-side and the time range chosen by the viewer is only used when the public dashboard allows it.
+func (dr *DashboardServiceImpl) BuildPublicDashboardMetricRequest(ctx context.Context, publicDashboardUid string, panelId int64,
+	reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
 	publicDashboardConfig, dashboard, err := dr.dashboardStore.GetPublicDashboard(publicDashboardUid)
 	if err != nil {
 		return dtos.MetricRequest{}, err
@@ -80,11 +114,7 @@ func (dr *DashboardServiceImpl) BuildPublicDashboardMetricRequest(ctx context.Co
 		return dtos.MetricRequest{}, models.ErrPublicDashboardNotFound
 	}
 
-	var timeSettings struct {
-		From string `json:"from"`
-		To   string `json:"to"`
-	}
-	err = json.Unmarshal([]byte(publicDashboardConfig.TimeSettings), &timeSettings)
+	timeRange, err := getPublicDashboardTimeRange(publicDashboardConfig, reqDTO)
 	if err != nil {
 		return dtos.MetricRequest{}, err
 	}
@@ -95,9 +125,162 @@ func (dr *DashboardServiceImpl) BuildPublicDashboardMetricRequest(ctx context.Co
 		return dtos.MetricRequest{}, models.ErrPublicDashboardPanelNotFound
 	}
 
+	variables, err := getPublicDashboardVariables(dashboard.Data, reqDTO.Variables)
+	if err != nil {
+		return dtos.MetricRequest{}, err
+	}
+
+	queries := make([]*simplejson.Json, 0, len(queriesByPanel[panelId]))
+	for _, query := range queriesByPanel[panelId] {
+		queries = append(queries, simplejson.NewFromAny(interpolateVariables(query.Interface(), variables)))
+	}
+
 	return dtos.MetricRequest{
-		From:    timeSettings.From,
-		To:      timeSettings.To,
-		Queries: queriesByPanel[panelId],
+		From:    timeRange.From,
+		To:      timeRange.To,
+		Queries: queries,
 	}, nil
 }
+
+// GetPublicDashboardAnnotations returns the annotations of the built-in Grafana annotation queries of a public
+// dashboard, if the public dashboard has annotations enabled.
+func (dr *DashboardServiceImpl) GetPublicDashboardAnnotations(ctx context.Context, publicDashboardUid string,
+	reqDTO models.PublicDashboardQueryDTO) ([]*models.PublicDashboardAnnotation, error) {
+	publicDashboardConfig, dashboard, err := dr.dashboardStore.GetPublicDashboard(publicDashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !dashboard.IsPublic {
+		return nil, models.ErrPublicDashboardNotFound
+	}
+
+	result := make([]*models.PublicDashboardAnnotation, 0)
+	if !publicDashboardConfig.AnnotationsEnabled {
+		return result, nil
+	}
+
+	timeRange, err := getPublicDashboardTimeRange(publicDashboardConfig, reqDTO)
+	if err != nil {
+		return nil, err
+	}
+	from, err := timeRange.ParseFrom()
+	if err != nil {
+		return nil, models.ErrPublicDashboardInvalidTimeRange
+	}
+	to, err := timeRange.ParseTo()
+	if err != nil {
+		return nil, models.ErrPublicDashboardInvalidTimeRange
+	}
+
+	// the annotations are read on behalf of the public dashboard, which can only see its own dashboard
+	// and the organization annotations matched by tags
+	user := &models.SignedInUser{
+		OrgId:   dashboard.OrgId,
+		OrgRole: models.ROLE_VIEWER,
+		Permissions: map[int64]map[string][]string{
+			dashboard.OrgId: {
+				accesscontrol.ActionAnnotationsRead: {accesscontrol.ScopeAnnotationsTypeDashboard, accesscontrol.ScopeAnnotationsTypeOrganization},
+				dashboards.ActionDashboardsRead:     {dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboard.Uid)},
+			},
+		},
+	}
+
+	repo := annotations.GetRepository()
+	for _, annoQuery := range dashboard.Data.GetPath("annotations", "list").MustArray() {
+		anno := simplejson.NewFromAny(annoQuery)
+		if !anno.Get("enable").MustBool(true) || !isGrafanaAnnotationQuery(anno) {
+			continue
+		}
+
+		query := &annotations.ItemQuery{
+			OrgId:        dashboard.OrgId,
+			From:         from.UnixNano() / int64(time.Millisecond),
+			To:           to.UnixNano() / int64(time.Millisecond),
+			Limit:        anno.GetPath("target", "limit").MustInt64(100),
+			SignedInUser: user,
+		}
+
+		switch getGrafanaAnnotationQueryType(anno) {
+		case "tags":
+			query.Tags = anno.GetPath("target", "tags").MustStringArray()
+			query.MatchAny = anno.GetPath("target", "matchAny").MustBool()
+			if len(query.Tags) == 0 {
+				continue
+			}
+		default:
+			query.DashboardId = dashboard.Id
+		}
+
+		items, err := repo.Find(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			result = append(result, &models.PublicDashboardAnnotation{
+				PanelId: item.PanelId,
+				Time:    item.Time,
+				TimeEnd: item.TimeEnd,
+				Text:    item.Text,
+				Tags:    item.Tags,
+				Color:   anno.Get("iconColor").MustString(),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// getPublicDashboardTimeRange returns the time range chosen by the viewer if the public dashboard allows choosing one,
+// or the time range saved with the public dashboard otherwise.
+func getPublicDashboardTimeRange(pd *models.PublicDashboard, reqDTO models.PublicDashboardQueryDTO) (legacydata.DataTimeRange, error) {
+	if !pd.TimeSelectionEnabled || reqDTO.From == "" || reqDTO.To == "" {
+		var timeSettings struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		}
+		if err := json.Unmarshal([]byte(pd.TimeSettings), &timeSettings); err != nil {
+			return legacydata.DataTimeRange{}, err
+		}
+		return legacydata.NewDataTimeRange(timeSettings.From, timeSettings.To), nil
+	}
+
+	timeRange := legacydata.NewDataTimeRange(reqDTO.From, reqDTO.To)
+	from, err := timeRange.ParseFrom()
+	if err != nil {
+		return legacydata.DataTimeRange{}, models.ErrPublicDashboardInvalidTimeRange
+	}
+	to, err := timeRange.ParseTo()
+	if err != nil || !to.After(from) {
+		return legacydata.DataTimeRange{}, models.ErrPublicDashboardInvalidTimeRange
+	}
+
+	if pd.MaxTimeRange != "" {
+		maxTimeRange, err := gtime.ParseDuration(pd.MaxTimeRange)
+		if err != nil {
+			return legacydata.DataTimeRange{}, models.ErrPublicDashboardInvalidMaxTimeRange
+		}
+		if to.Sub(from) > maxTimeRange {
+			return legacydata.DataTimeRange{}, models.ErrPublicDashboardTimeRangeTooLarge
+		}
+	}
+
+	return timeRange, nil
+}
+
+func isGrafanaAnnotationQuery(anno *simplejson.Json) bool {
+	datasource := anno.Get("datasource")
+	if name, err := datasource.String(); err == nil {
+		return name == grafanads.DatasourceName
+	}
+	uid := datasource.Get("uid").MustString()
+	return uid == grafanads.DatasourceUID || uid == grafanads.DatasourceName
+}
+
+func getGrafanaAnnotationQueryType(anno *simplejson.Json) string {
+	if t := anno.GetPath("target", "type").MustString(); t != "" {
+		return t
+	}
+	return anno.Get("type").MustString()
+}
@@ -3,10 +3,12 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/database"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -46,8 +48,11 @@ func TestGetPublicDashboard(t *testing.T) {
 					Data:     simplejson.NewFromAny(map[string]interface{}{"time": map[string]interface{}{"from": "abc", "to": "123"}}),
 				},
 				err: nil},
-			errResp:  nil,
-			dashResp: &models.Dashboard{IsPublic: true, Data: simplejson.NewFromAny(map[string]interface{}{"time": map[string]interface{}{"from": "now-8", "to": "now"}})},
+			errResp: nil,
+			dashResp: &models.Dashboard{IsPublic: true, Data: simplejson.NewFromAny(map[string]interface{}{
+				"time":       map[string]interface{}{"from": "now-8", "to": "now"},
+				"timepicker": map[string]interface{}{"hidden": true},
+			})},
 		},
 		{
 			name:      "returns ErrPublicDashboardNotFound when isPublic is false",
@@ -123,33 +128,52 @@ func TestSavePublicDashboard(t *testing.T) {
 		assert.Equal(t, dashboard.OrgId, pdc.PublicDashboard.OrgId)
 	})
 
-	t.Run("PLACEHOLDER - dashboard with template variables cannot be saved", func(t *testing.T) {
-		//sqlStore := sqlstore.InitTestDB(t)
-		//dashboardStore := database.ProvideDashboardStore(sqlStore)
-		//dashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, true)
-
-		//service := &DashboardServiceImpl{
-		//log:            log.New("test.logger"),
-		//dashboardStore: dashboardStore,
-		//}
-
-		//dto := &dashboards.SavePublicDashboardConfigDTO{
-		//DashboardUid: dashboard.Uid,
-		//OrgId:        dashboard.OrgId,
-		//PublicDashboardConfig: &models.PublicDashboardConfig{
-		//IsPublic: true,
-		//PublicDashboard: models.PublicDashboard{
-		//DashboardUid: "NOTTHESAME",
-		//OrgId:        9999999,
-		//},
-		//},
-		//}
-
-		//pdc, err := service.SavePublicDashboardConfig(context.Background(), dto)
-		//require.NoError(t, err)
-
-		//assert.Equal(t, dashboard.Uid, pdc.PublicDashboard.DashboardUid)
-		//assert.Equal(t, dashboard.OrgId, pdc.PublicDashboard.OrgId)
+	t.Run("dashboard with unsupported template variables cannot be made public", func(t *testing.T) {
+		sqlStore := sqlstore.InitTestDB(t)
+		dashboardStore := database.ProvideDashboardStore(sqlStore)
+		dashboard := insertTestDashboardWithVariables(t, dashboardStore, "testDashie", []interface{}{
+			map[string]interface{}{"name": "server", "type": "query", "query": "label_values(server)"},
+		})
+
+		service := &DashboardServiceImpl{
+			log:            log.New("test.logger"),
+			dashboardStore: dashboardStore,
+		}
+
+		dto := &dashboards.SavePublicDashboardConfigDTO{
+			DashboardUid: dashboard.Uid,
+			OrgId:        dashboard.OrgId,
+			PublicDashboardConfig: &models.PublicDashboardConfig{
+				IsPublic:        true,
+				PublicDashboard: models.PublicDashboard{},
+			},
+		}
+
+		_, err := service.SavePublicDashboardConfig(context.Background(), dto)
+		require.ErrorIs(t, err, models.ErrPublicDashboardUnsupportedVariable)
+	})
+
+	t.Run("rejects an invalid maximum time range", func(t *testing.T) {
+		sqlStore := sqlstore.InitTestDB(t)
+		dashboardStore := database.ProvideDashboardStore(sqlStore)
+		dashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, true)
+
+		service := &DashboardServiceImpl{
+			log:            log.New("test.logger"),
+			dashboardStore: dashboardStore,
+		}
+
+		dto := &dashboards.SavePublicDashboardConfigDTO{
+			DashboardUid: dashboard.Uid,
+			OrgId:        dashboard.OrgId,
+			PublicDashboardConfig: &models.PublicDashboardConfig{
+				IsPublic:        true,
+				PublicDashboard: models.PublicDashboard{TimeSelectionEnabled: true, MaxTimeRange: "a week"},
+			},
+		}
+
+		_, err := service.SavePublicDashboardConfig(context.Background(), dto)
+		require.ErrorIs(t, err, models.ErrPublicDashboardInvalidMaxTimeRange)
 	})
 }
 
@@ -201,6 +225,7 @@ func TestBuildPublicDashboardMetricRequest(t *testing.T) {
 			context.Background(),
 			pdc.PublicDashboard.Uid,
 			1,
+			models.PublicDashboardQueryDTO{},
 		)
 		require.NoError(t, err)
 
@@ -236,6 +261,7 @@ func TestBuildPublicDashboardMetricRequest(t *testing.T) {
 			context.Background(),
 			pdc.PublicDashboard.Uid,
 			49,
+			models.PublicDashboardQueryDTO{},
 		)
 
 		require.ErrorContains(t, err, "Panel not found")
@@ -246,6 +272,7 @@ func TestBuildPublicDashboardMetricRequest(t *testing.T) {
 			context.Background(),
 			nonPublicPdc.PublicDashboard.Uid,
 			2,
+			models.PublicDashboardQueryDTO{},
 		)
 		require.ErrorContains(t, err, "Public dashboard not found")
 	})
@@ -304,3 +331,184 @@ func insertTestDashboard(t *testing.T, dashboardStore *database.DashboardStore,
 	dash.Data.Set("uid", dash.Uid)
 	return dash
 }
+
+func TestBuildPublicDashboardMetricRequestWithViewerOptions(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	dashboardStore := database.ProvideDashboardStore(sqlStore)
+	dashboard := insertTestDashboardWithVariables(t, dashboardStore, "testDashie", []interface{}{
+		map[string]interface{}{"name": "env", "type": "constant", "query": "prod"},
+		map[string]interface{}{
+			"name":    "server",
+			"type":    "custom",
+			"query":   "web1,web2,db1",
+			"current": map[string]interface{}{"value": []interface{}{"web1", "web2"}},
+		},
+	})
+
+	service := &DashboardServiceImpl{
+		log:            log.New("test.logger"),
+		dashboardStore: dashboardStore,
+	}
+
+	pdc, err := service.SavePublicDashboardConfig(context.Background(), &dashboards.SavePublicDashboardConfigDTO{
+		DashboardUid: dashboard.Uid,
+		OrgId:        dashboard.OrgId,
+		PublicDashboardConfig: &models.PublicDashboardConfig{
+			IsPublic: true,
+			PublicDashboard: models.PublicDashboard{
+				TimeSettings:         `{"from": "now-1h", "to": "now"}`,
+				TimeSelectionEnabled: true,
+				MaxTimeRange:         "1d",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("resolves constant and custom template variables", func(t *testing.T) {
+		reqDTO, err := service.BuildPublicDashboardMetricRequest(context.Background(), pdc.PublicDashboard.Uid, 1, models.PublicDashboardQueryDTO{})
+		require.NoError(t, err)
+
+		require.Equal(t, "now-1h", reqDTO.From)
+		require.Equal(t, "now", reqDTO.To)
+		require.Len(t, reqDTO.Queries, 1)
+		require.Equal(t, `up{env="prod", server=~"{web1,web2}", job="$job"}`, reqDTO.Queries[0].Get("expr").MustString())
+	})
+
+	t.Run("uses the custom variable value chosen by the viewer", func(t *testing.T) {
+		reqDTO, err := service.BuildPublicDashboardMetricRequest(context.Background(), pdc.PublicDashboard.Uid, 1, models.PublicDashboardQueryDTO{
+			Variables: map[string]string{"server": "db1"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, `up{env="prod", server=~"db1", job="$job"}`, reqDTO.Queries[0].Get("expr").MustString())
+	})
+
+	t.Run("rejects variable values that are not an option", func(t *testing.T) {
+		for _, variables := range []map[string]string{{"server": "other"}, {"env": "dev"}, {"job": "api"}} {
+			_, err := service.BuildPublicDashboardMetricRequest(context.Background(), pdc.PublicDashboard.Uid, 1, models.PublicDashboardQueryDTO{
+				Variables: variables,
+			})
+			require.ErrorIs(t, err, models.ErrPublicDashboardInvalidVariable)
+		}
+	})
+
+	t.Run("uses the time range chosen by the viewer", func(t *testing.T) {
+		reqDTO, err := service.BuildPublicDashboardMetricRequest(context.Background(), pdc.PublicDashboard.Uid, 1, models.PublicDashboardQueryDTO{
+			From: "now-6h",
+			To:   "now",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "now-6h", reqDTO.From)
+		require.Equal(t, "now", reqDTO.To)
+	})
+
+	t.Run("rejects time ranges larger than the maximum", func(t *testing.T) {
+		_, err := service.BuildPublicDashboardMetricRequest(context.Background(), pdc.PublicDashboard.Uid, 1, models.PublicDashboardQueryDTO{
+			From: "now-7d",
+			To:   "now",
+		})
+		require.ErrorIs(t, err, models.ErrPublicDashboardTimeRangeTooLarge)
+	})
+
+	t.Run("rejects invalid time ranges", func(t *testing.T) {
+		_, err := service.BuildPublicDashboardMetricRequest(context.Background(), pdc.PublicDashboard.Uid, 1, models.PublicDashboardQueryDTO{
+			From: "now",
+			To:   "now-1h",
+		})
+		require.ErrorIs(t, err, models.ErrPublicDashboardInvalidTimeRange)
+	})
+}
+
+func TestGetPublicDashboardAnnotations(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	dashboardStore := database.ProvideDashboardStore(sqlStore)
+	dashboard := insertTestDashboardWithVariables(t, dashboardStore, "testDashie", nil)
+	otherDashboard := insertTestDashboardWithVariables(t, dashboardStore, "otherDashie", nil)
+
+	service := &DashboardServiceImpl{
+		log:            log.New("test.logger"),
+		dashboardStore: dashboardStore,
+	}
+
+	repo := annotations.GetRepository()
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	for _, item := range []*annotations.Item{
+		{OrgId: 1, DashboardId: dashboard.Id, PanelId: 1, Epoch: now - 1000, EpochEnd: now - 1000, Text: "deploy"},
+		{OrgId: 1, DashboardId: otherDashboard.Id, Epoch: now - 1000, EpochEnd: now - 1000, Text: "other"},
+		{OrgId: 1, DashboardId: dashboard.Id, Epoch: now - 48*3600*1000, EpochEnd: now - 48*3600*1000, Text: "old"},
+	} {
+		require.NoError(t, repo.Save(item))
+	}
+
+	save := func(t *testing.T, annotationsEnabled bool) string {
+		t.Helper()
+		pdc, err := service.SavePublicDashboardConfig(context.Background(), &dashboards.SavePublicDashboardConfigDTO{
+			DashboardUid: dashboard.Uid,
+			OrgId:        dashboard.OrgId,
+			PublicDashboardConfig: &models.PublicDashboardConfig{
+				IsPublic: true,
+				PublicDashboard: models.PublicDashboard{
+					TimeSettings:       `{"from": "now-1h", "to": "now"}`,
+					AnnotationsEnabled: annotationsEnabled,
+				},
+			},
+		})
+		require.NoError(t, err)
+		return pdc.PublicDashboard.Uid
+	}
+
+	t.Run("returns no annotations when disabled", func(t *testing.T) {
+		items, err := service.GetPublicDashboardAnnotations(context.Background(), save(t, false), models.PublicDashboardQueryDTO{})
+		require.NoError(t, err)
+		require.Empty(t, items)
+	})
+
+	t.Run("returns the annotations of the dashboard in the time range", func(t *testing.T) {
+		items, err := service.GetPublicDashboardAnnotations(context.Background(), save(t, true), models.PublicDashboardQueryDTO{})
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, "deploy", items[0].Text)
+		assert.EqualValues(t, 1, items[0].PanelId)
+		assert.Equal(t, "rgba(0, 211, 255, 1)", items[0].Color)
+	})
+}
+
+func insertTestDashboardWithVariables(t *testing.T, dashboardStore *database.DashboardStore, title string, variables []interface{}) *models.Dashboard {
+	t.Helper()
+	cmd := models.SaveDashboardCommand{
+		OrgId: 1,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"id":    nil,
+			"title": title,
+			"annotations": map[string]interface{}{
+				"list": []interface{}{
+					map[string]interface{}{
+						"builtIn":    1,
+						"datasource": map[string]interface{}{"type": "grafana", "uid": "-- Grafana --"},
+						"enable":     true,
+						"iconColor":  "rgba(0, 211, 255, 1)",
+						"name":       "Annotations & Alerts",
+						"target":     map[string]interface{}{"type": "dashboard", "limit": 100},
+						"type":       "dashboard",
+					},
+				},
+			},
+			"templating": map[string]interface{}{"list": variables},
+			"panels": []interface{}{
+				map[string]interface{}{
+					"id": 1,
+					"targets": []interface{}{
+						map[string]interface{}{
+							"datasource": map[string]interface{}{"type": "prometheus", "uid": "ds1"},
+							"expr":       `up{env="$env", server=~"${server:regex}", job="$job"}`,
+							"refId":      "A",
+						},
+					},
+				},
+			},
+		}),
+	}
+	dash, err := dashboardStore.SaveDashboard(cmd)
+	require.NoError(t, err)
+	require.NotNil(t, dash)
+	return dash
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const allVariableValue = "$__all"
+
+// variableRegex matches the $var, ${var}, ${var:format} and [[var]] template variable syntaxes
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::\w+)?\]\]|\$\{(\w+)(?::[^\}]+)?\}`)
+
+// validatePublicDashboardVariables checks that all the template variables of a dashboard can be resolved server-side.
+func validatePublicDashboardVariables(dashboard *simplejson.Json) error {
+	if dashboard == nil {
+		return nil
+	}
+
+	for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+		if t := simplejson.NewFromAny(v).Get("type").MustString(); t != "constant" && t != "custom" {
+			return models.ErrPublicDashboardUnsupportedVariable
+		}
+	}
+
+	return nil
+}
+
+// getPublicDashboardVariables returns the values of the constant and custom template variables of a dashboard.
+// Viewers can choose the value of a custom variable among its options, constants always keep their saved value.
+func getPublicDashboardVariables(dashboard *simplejson.Json, selected map[string]string) (map[string]string, error) {
+	values := make(map[string]string)
+	custom := make(map[string][]string)
+
+	for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		name := variable.Get("name").MustString()
+
+		switch variable.Get("type").MustString() {
+		case "constant":
+			values[name] = variable.Get("query").MustString()
+		case "custom":
+			options := getCustomVariableOptions(variable)
+			custom[name] = options
+			values[name] = formatVariableValues(getCustomVariableCurrent(variable, options))
+		}
+	}
+
+	for name, value := range selected {
+		options, ok := custom[name]
+		if !ok || !containsString(options, value) {
+			return nil, models.ErrPublicDashboardInvalidVariable
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+func getCustomVariableOptions(variable *simplejson.Json) []string {
+	var options []string
+	for _, o := range variable.Get("options").MustArray() {
+		if value := simplejson.NewFromAny(o).Get("value").MustString(); value != "" && value != allVariableValue {
+			options = append(options, value)
+		}
+	}
+	if len(options) > 0 {
+		return options
+	}
+
+	// options are not always saved with the dashboard, the query is a comma separated list of "value" or "text : value"
+	for _, o := range strings.Split(variable.Get("query").MustString(), ",") {
+		if i := strings.Index(o, " : "); i >= 0 {
+			o = o[i+3:]
+		}
+		if o = strings.TrimSpace(o); o != "" {
+			options = append(options, o)
+		}
+	}
+	return options
+}
+
+func getCustomVariableCurrent(variable *simplejson.Json, options []string) []string {
+	current := variable.GetPath("current", "value")
+	values, err := current.StringArray()
+	if err != nil {
+		values = []string{current.MustString()}
+	}
+
+	if len(values) == 1 && values[0] == allVariableValue {
+		if allValue := variable.Get("allValue").MustString(); allValue != "" {
+			return []string{allValue}
+		}
+		return options
+	}
+	return values
+}
+
+// formatVariableValues formats the values of a variable with the default glob format
+func formatVariableValues(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return "{" + strings.Join(values, ",") + "}"
+}
+
+// interpolateVariables replaces the template variables in all the strings of a query.
+func interpolateVariables(value interface{}, variables map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return variableRegex.ReplaceAllStringFunc(v, func(match string) string {
+			groups := variableRegex.FindStringSubmatch(match)
+			for _, name := range groups[1:] {
+				if name == "" {
+					continue
+				}
+				if value, ok := variables[name]; ok {
+					return value
+				}
+			}
+			return match
+		})
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = interpolateVariables(item, variables)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = interpolateVariables(item, variables)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
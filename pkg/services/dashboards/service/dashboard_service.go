@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/schemaversion"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/setting"
@@ -73,6 +74,60 @@ func (dr *DashboardServiceImpl) GetProvisionedDashboardDataByDashboardUID(orgID
 	return dr.dashboardStore.GetProvisionedDataByDashboardUID(orgID, dashboardUID)
 }
 
+func (dr *DashboardServiceImpl) nestedFoldersEnabled() bool {
+	return dr.features != nil && dr.features.IsEnabled(featuremgmt.FlagNestedFolders)
+}
+
+// validateFolderParent checks that moving a folder into its parent folder neither creates a cycle
+// nor makes the folder tree deeper than models.MaxNestedFolderDepth.
+func (dr *DashboardServiceImpl) validateFolderParent(ctx context.Context, folder *models.Dashboard) error {
+	parents, err := dr.dashboardStore.GetFolderParents(ctx, folder.OrgId, folder.FolderId)
+	if err != nil {
+		return err
+	}
+	ancestorIDs := []int64{folder.FolderId}
+	for _, parent := range parents {
+		ancestorIDs = append(ancestorIDs, parent.Id)
+	}
+	height := 1
+	if folder.Id > 0 {
+		for _, id := range ancestorIDs {
+			if id == folder.Id {
+				return models.ErrDashboardFolderCircularReference
+			}
+		}
+		if height, err = dr.folderHeight(ctx, folder.OrgId, folder.Id, 1); err != nil {
+			return err
+		}
+	}
+	if len(ancestorIDs)+height > models.MaxNestedFolderDepth {
+		return models.ErrDashboardFolderMaxDepthExceeded
+	}
+	return nil
+}
+
+// folderHeight returns the number of levels in the folder tree below and including the folder with the given ID.
+func (dr *DashboardServiceImpl) folderHeight(ctx context.Context, orgID, id int64, level int) (int, error) {
+	if level > models.MaxNestedFolderDepth {
+		return level, nil
+	}
+	children, err := dr.dashboardStore.GetFolderChildren(ctx, orgID, id)
+	if err != nil {
+		return 0, err
+	}
+	height := 1
+	for _, child := range children {
+		h, err := dr.folderHeight(ctx, orgID, child.Id, level+1)
+		if err != nil {
+			return 0, err
+		}
+		if h+1 > height {
+			height = h + 1
+		}
+	}
+	return height, nil
+}
+
 func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, dto *dashboards.SaveDashboardDTO, shouldValidateAlerts bool,
 	validateProvisionedDashboard bool) (*models.SaveDashboardCommand, error) {
 	dash := dto.Dashboard
@@ -87,7 +142,12 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 	}
 
 	if dash.IsFolder && dash.FolderId > 0 {
-		return nil, models.ErrDashboardFolderCannotHaveParent
+		if !dr.nestedFoldersEnabled() {
+			return nil, models.ErrDashboardFolderCannotHaveParent
+		}
+		if err := dr.validateFolderParent(ctx, dash); err != nil {
+			return nil, err
+		}
 	}
 
 	if dash.IsFolder && strings.EqualFold(dash.Title, models.RootFolderName) {
@@ -225,6 +285,10 @@ func (dr *DashboardServiceImpl) SaveProvisionedDashboard(ctx context.Context, dt
 		},
 	}
 
+	if err := dr.MigrateDashboardSchema(ctx, dto.Dashboard); err != nil {
+		return nil, err
+	}
+
 	cmd, err := dr.BuildSaveDashboardCommand(ctx, dto, true, false)
 	if err != nil {
 		return nil, err
@@ -354,6 +418,49 @@ func (dr *DashboardServiceImpl) DeleteDashboard(ctx context.Context, dashboardId
 	return dr.deleteDashboard(ctx, dashboardId, orgId, true)
 }
 
+// TrashDashboard deletes a dashboard on behalf of a user. Unless the trash is disabled by setting
+// [dashboards] trash_retention to 0, the dashboard is kept in the trash and can be restored until it expires.
+func (dr *DashboardServiceImpl) TrashDashboard(ctx context.Context, user *models.SignedInUser, dashboardId int64, orgId int64) error {
+	provisionedData, err := dr.GetProvisionedDashboardDataByDashboardID(dashboardId)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "failed to check if dashboard is provisioned", err)
+	}
+
+	if provisionedData != nil {
+		return models.ErrDashboardCannotDeleteProvisionedDashboard
+	}
+
+	cmd := &models.DeleteDashboardCommand{
+		OrgId:       orgId,
+		Id:          dashboardId,
+		MoveToTrash: dr.cfg.DashboardTrashRetention > 0,
+		DeletedBy:   user.UserId,
+	}
+	return dr.dashboardStore.DeleteDashboard(ctx, cmd)
+}
+
+func (dr *DashboardServiceImpl) GetDashboardTrash(ctx context.Context, query *models.GetDashboardTrashQuery) error {
+	return dr.dashboardStore.GetDashboardTrash(ctx, query)
+}
+
+func (dr *DashboardServiceImpl) GetDashboardTrashItem(ctx context.Context, query *models.GetDashboardTrashItemQuery) error {
+	return dr.dashboardStore.GetDashboardTrashItem(ctx, query)
+}
+
+func (dr *DashboardServiceImpl) RestoreDashboardFromTrash(ctx context.Context, cmd *models.RestoreDashboardFromTrashCommand) error {
+	return dr.dashboardStore.RestoreDashboardFromTrash(ctx, cmd)
+}
+
+func (dr *DashboardServiceImpl) DeleteDashboardFromTrash(ctx context.Context, orgID int64, id int64) error {
+	return dr.dashboardStore.DeleteDashboardFromTrash(ctx, &models.DeleteDashboardFromTrashCommand{OrgId: orgID, Id: id})
+}
+
+// DeleteExpiredDashboardTrash permanently deletes the dashboards and folders that have been in the trash
+// for longer than the configured retention, or all of them when the trash has been disabled.
+func (dr *DashboardServiceImpl) DeleteExpiredDashboardTrash(ctx context.Context) (int64, error) {
+	return dr.dashboardStore.DeleteExpiredDashboardTrash(ctx, time.Now().Add(-dr.cfg.DashboardTrashRetention))
+}
+
 func (dr *DashboardServiceImpl) GetDashboardByPublicUid(ctx context.Context, dashboardPublicUid string) (*models.Dashboard, error) {
 	return nil, nil
 }
@@ -430,6 +537,10 @@ func (dr *DashboardServiceImpl) ImportDashboard(ctx context.Context, dto *dashbo
 		dto.Dashboard.Data.Set("refresh", setting.MinRefreshInterval)
 	}
 
+	if err := dr.MigrateDashboardSchema(ctx, dto.Dashboard); err != nil {
+		return nil, err
+	}
+
 	cmd, err := dr.BuildSaveDashboardCommand(ctx, dto, false, true)
 	if err != nil {
 		return nil, err
@@ -493,8 +604,44 @@ func (dr *DashboardServiceImpl) setDefaultPermissions(ctx context.Context, dto *
 }
 
 func (dr *DashboardServiceImpl) GetDashboard(ctx context.Context, query *models.GetDashboardQuery) error {
-	_, err := dr.dashboardStore.GetDashboard(ctx, query)
-	return err
+	dash, err := dr.dashboardStore.GetDashboard(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	// dashboards that were not migrated when they were saved are upgraded when read
+	if dash != nil && !dash.IsFolder {
+		if err := dr.MigrateDashboardSchema(ctx, dash); err != nil {
+			dr.log.Warn("Failed to migrate dashboard schema", "orgId", dash.OrgId, "uid", dash.Uid, "error", err)
+		}
+	}
+	return nil
+}
+
+// MigrateDashboardSchema upgrades the dashboard model to the latest schema version.
+// The data sources of the organization are only loaded when a migration needs them.
+func (dr *DashboardServiceImpl) MigrateDashboardSchema(ctx context.Context, dash *models.Dashboard) error {
+	if !schemaversion.NeedsMigration(dash.Data) {
+		return nil
+	}
+
+	var lookup schemaversion.DataSourceLookup
+	return schemaversion.Migrate(dash.Data, func(nameOrUID string) *schemaversion.DataSourceRef {
+		if lookup == nil {
+			var err error
+			if lookup, err = dr.dashboardStore.GetDataSourceLookup(ctx, dash.OrgId); err != nil {
+				dr.log.Warn("Failed to load data sources for dashboard schema migration", "orgId", dash.OrgId, "error", err)
+			}
+			if lookup == nil {
+				lookup = func(string) *schemaversion.DataSourceRef { return nil }
+			}
+		}
+		return lookup(nameOrUID)
+	})
+}
+
+func (dr *DashboardServiceImpl) MigrateDashboardsSchema(ctx context.Context, cmd *models.MigrateDashboardsSchemaCommand) error {
+	return dr.dashboardStore.MigrateDashboardsSchema(ctx, cmd)
 }
 
 func (dr *DashboardServiceImpl) GetDashboardUIDById(ctx context.Context, query *models.GetDashboardRefByIdQuery) error {
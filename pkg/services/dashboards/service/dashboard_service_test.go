@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -12,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	m "github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/schemaversion"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -23,7 +25,9 @@ func TestIntegrationDashboardService(t *testing.T) {
 	t.Run("Dashboard service tests", func(t *testing.T) {
 		fakeStore := m.FakeDashboardStore{}
 		defer fakeStore.AssertExpectations(t)
+		fakeStore.On("GetDataSourceLookup", mock.Anything, mock.AnythingOfType("int64")).Return(nil, nil).Maybe()
 		service := &DashboardServiceImpl{
+			cfg:                setting.NewCfg(),
 			log:                log.New("test.logger"),
 			dashboardStore:     &fakeStore,
 			dashAlertExtractor: &dummyDashAlertExtractor{},
@@ -154,6 +158,20 @@ func TestIntegrationDashboardService(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, dto.Dashboard.Data.Get("refresh").MustString(), "5m")
 			})
+
+			t.Run("Should migrate the dashboard to the latest schema version", func(t *testing.T) {
+				fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything).Return(true, nil).Once()
+				fakeStore.On("SaveProvisionedDashboard", mock.Anything, mock.Anything).Return(&models.Dashboard{Data: simplejson.New()}, nil).Once()
+				fakeStore.On("SaveAlerts", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+				dto.Dashboard = models.NewDashboard("Dash")
+				dto.Dashboard.Data.Set("schemaVersion", 27)
+				dto.Dashboard.Data.Set("panels", []interface{}{map[string]interface{}{"id": 1, "type": "singlestat"}})
+				_, err := service.SaveProvisionedDashboard(context.Background(), dto, nil)
+				require.NoError(t, err)
+				require.Equal(t, schemaversion.LatestVersion, dto.Dashboard.Data.Get("schemaVersion").MustInt())
+				require.Equal(t, "stat", dto.Dashboard.Data.Get("panels").GetIndex(0).Get("type").MustString())
+			})
 		})
 
 		t.Run("Import dashboard validation", func(t *testing.T) {
@@ -201,6 +219,24 @@ func TestIntegrationDashboardService(t *testing.T) {
 				err := service.DeleteDashboard(context.Background(), 1, 1)
 				require.NoError(t, err)
 			})
+
+			t.Run("TrashDashboard should move it to the trash", func(t *testing.T) {
+				service.cfg.DashboardTrashRetention = 24 * time.Hour
+				args := &models.DeleteDashboardCommand{OrgId: 1, Id: 1, MoveToTrash: true, DeletedBy: 2}
+				fakeStore.On("DeleteDashboard", mock.Anything, args).Return(nil).Once()
+				fakeStore.On("GetProvisionedDataByDashboardID", mock.Anything).Return(nil, nil).Once()
+				err := service.TrashDashboard(context.Background(), &models.SignedInUser{UserId: 2}, 1, 1)
+				require.NoError(t, err)
+			})
+
+			t.Run("TrashDashboard should delete it permanently when the trash is disabled", func(t *testing.T) {
+				service.cfg.DashboardTrashRetention = 0
+				args := &models.DeleteDashboardCommand{OrgId: 1, Id: 1, DeletedBy: 2}
+				fakeStore.On("DeleteDashboard", mock.Anything, args).Return(nil).Once()
+				fakeStore.On("GetProvisionedDataByDashboardID", mock.Anything).Return(nil, nil).Once()
+				err := service.TrashDashboard(context.Background(), &models.SignedInUser{UserId: 2}, 1, 1)
+				require.NoError(t, err)
+			})
 		})
 	})
 }
@@ -38,6 +38,9 @@ func ProvideFolderService(
 ) *FolderServiceImpl {
 	ac.RegisterScopeAttributeResolver(dashboards.NewFolderNameScopeResolver(dashboardStore))
 	ac.RegisterScopeAttributeResolver(dashboards.NewFolderIDScopeResolver(dashboardStore))
+	if features.IsEnabled(featuremgmt.FlagNestedFolders) {
+		ac.RegisterScopeAttributeResolver(dashboards.NewFolderUIDScopeResolver(dashboardStore))
+	}
 
 	return &FolderServiceImpl{
 		cfg:              cfg,
@@ -134,7 +137,7 @@ func (f *FolderServiceImpl) GetFolderByTitle(ctx context.Context, user *models.S
 	return dashFolder, nil
 }
 
-func (f *FolderServiceImpl) CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title, uid string) (*models.Folder, error) {
+func (f *FolderServiceImpl) CreateFolder(ctx context.Context, user *models.SignedInUser, orgID int64, title, uid, parentUID string) (*models.Folder, error) {
 	dashFolder := models.NewDashboardFolder(title)
 	dashFolder.OrgId = orgID
 
@@ -143,6 +146,17 @@ func (f *FolderServiceImpl) CreateFolder(ctx context.Context, user *models.Signe
 		return nil, models.ErrFolderInvalidUID
 	}
 
+	if parentUID != "" && parentUID != accesscontrol.GeneralFolderUID {
+		if !f.features.IsEnabled(featuremgmt.FlagNestedFolders) {
+			return nil, models.ErrDashboardFolderCannotHaveParent
+		}
+		parent, err := f.dashboardStore.GetFolderByUID(ctx, orgID, parentUID)
+		if err != nil {
+			return nil, err
+		}
+		dashFolder.FolderId = parent.Id
+	}
+
 	dashFolder.SetUid(trimmedUID)
 	userID := user.UserId
 	if userID == 0 {
@@ -243,6 +257,142 @@ func (f *FolderServiceImpl) UpdateFolder(ctx context.Context, user *models.Signe
 	return nil
 }
 
+// MoveFolder moves a folder, with its dashboards and subfolders, into the folder with the UID cmd.ParentUid.
+// An empty parent UID moves the folder to the root level.
+func (f *FolderServiceImpl) MoveFolder(ctx context.Context, user *models.SignedInUser, orgID int64, uid string, cmd *models.MoveFolderCommand) error {
+	if !f.features.IsEnabled(featuremgmt.FlagNestedFolders) {
+		return models.ErrDashboardFolderCannotHaveParent
+	}
+
+	query := models.GetDashboardQuery{OrgId: orgID, Uid: uid}
+	if _, err := f.dashboardStore.GetDashboard(ctx, &query); err != nil {
+		return toFolderError(err)
+	}
+	dashFolder := query.Result
+	if !dashFolder.IsFolder {
+		return models.ErrFolderNotFound
+	}
+
+	var parentID int64
+	if cmd.ParentUid != "" && cmd.ParentUid != accesscontrol.GeneralFolderUID {
+		parent, err := f.dashboardStore.GetFolderByUID(ctx, orgID, cmd.ParentUid)
+		if err != nil {
+			return err
+		}
+		parentID = parent.Id
+	}
+	if parentID == dashFolder.FolderId {
+		folder, err := f.dashboardStore.GetFolderByID(ctx, orgID, dashFolder.Id)
+		if err != nil {
+			return err
+		}
+		cmd.Result = folder
+		return nil
+	}
+	dashFolder.FolderId = parentID
+
+	dto := &dashboards.SaveDashboardDTO{
+		Dashboard: dashFolder,
+		OrgId:     orgID,
+		User:      user,
+		Overwrite: true,
+	}
+
+	// the guardian checks that the user can create folders in the new parent folder
+	saveDashboardCmd, err := f.dashboardService.BuildSaveDashboardCommand(ctx, dto, false, false)
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	dash, err := f.dashboardStore.SaveDashboard(*saveDashboardCmd)
+	if err != nil {
+		return toFolderError(err)
+	}
+
+	folder, err := f.dashboardStore.GetFolderByID(ctx, orgID, dash.Id)
+	if err != nil {
+		return err
+	}
+	cmd.Result = folder
+
+	if err := f.bus.Publish(ctx, &events.FolderUpdated{
+		Timestamp: time.Now(),
+		Title:     folder.Title,
+		ID:        dash.Id,
+		UID:       dash.Uid,
+		OrgID:     orgID,
+	}); err != nil {
+		f.log.Error("failed to publish FolderUpdated event", "folder", folder.Title, "user", user.UserId, "error", err)
+	}
+
+	return nil
+}
+
+// GetFolderParents returns the parent folders of a folder that the user can view, starting with its root folder.
+func (f *FolderServiceImpl) GetFolderParents(ctx context.Context, user *models.SignedInUser, orgID int64, uid string) ([]*models.Folder, error) {
+	folder, err := f.GetFolderByUID(ctx, user, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	parents, err := f.dashboardStore.GetFolderParents(ctx, orgID, folder.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*models.Folder, 0, len(parents))
+	for _, parent := range parents {
+		g := guardian.New(ctx, parent.Id, orgID, user)
+		canView, err := g.CanView()
+		if err != nil {
+			return nil, toFolderError(err)
+		}
+		if canView {
+			visible = append(visible, parent)
+		}
+	}
+	return visible, nil
+}
+
+// GetFolderByPath returns the folder with the given path of folder titles separated by "/",
+// for example "Team/Services/API". A path without separator, or a path that does not match
+// any nested folder, is looked up as a root folder title.
+func (f *FolderServiceImpl) GetFolderByPath(ctx context.Context, user *models.SignedInUser, orgID int64, path string) (*models.Folder, error) {
+	titles := strings.Split(path, models.FolderPathSeparator)
+	if len(titles) == 1 || !f.features.IsEnabled(featuremgmt.FlagNestedFolders) {
+		return f.GetFolderByTitle(ctx, user, orgID, path)
+	}
+
+	var parentID int64
+	var folder *models.Folder
+	for _, title := range titles {
+		children, err := f.dashboardStore.GetFolderChildren(ctx, orgID, parentID)
+		if err != nil {
+			return nil, err
+		}
+		folder = nil
+		for _, child := range children {
+			if child.Title == title {
+				folder = child
+				break
+			}
+		}
+		if folder == nil {
+			return f.GetFolderByTitle(ctx, user, orgID, path)
+		}
+		parentID = folder.Id
+	}
+
+	g := guardian.New(ctx, folder.Id, orgID, user)
+	if canView, err := g.CanView(); err != nil || !canView {
+		if err != nil {
+			return nil, toFolderError(err)
+		}
+		return nil, models.ErrFolderAccessDenied
+	}
+
+	return folder, nil
+}
+
 func (f *FolderServiceImpl) DeleteFolder(ctx context.Context, user *models.SignedInUser, orgID int64, uid string, forceDeleteRules bool) (*models.Folder, error) {
 	dashFolder, err := f.dashboardStore.GetFolderByUID(ctx, orgID, uid)
 	if err != nil {
@@ -257,7 +407,13 @@ func (f *FolderServiceImpl) DeleteFolder(ctx context.Context, user *models.Signe
 		return nil, models.ErrFolderAccessDenied
 	}
 
-	deleteCmd := models.DeleteDashboardCommand{OrgId: orgID, Id: dashFolder.Id, ForceDeleteFolderRules: forceDeleteRules}
+	deleteCmd := models.DeleteDashboardCommand{
+		OrgId:                  orgID,
+		Id:                     dashFolder.Id,
+		ForceDeleteFolderRules: forceDeleteRules,
+		MoveToTrash:            f.cfg.DashboardTrashRetention > 0,
+		DeletedBy:              user.UserId,
+	}
 
 	if err := f.dashboardStore.DeleteDashboard(ctx, &deleteCmd); err != nil {
 		return nil, toFolderError(err)
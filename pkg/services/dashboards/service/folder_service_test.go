@@ -31,10 +31,19 @@ func TestIntegrationProvideFolderService(t *testing.T) {
 		cfg := setting.NewCfg()
 		ac := acmock.New()
 
-		ProvideFolderService(cfg, nil, nil, nil, nil, nil, ac, busmock.New())
+		ProvideFolderService(cfg, nil, nil, nil, featuremgmt.WithFeatures(), nil, ac, busmock.New())
 
 		require.Len(t, ac.Calls.RegisterAttributeScopeResolver, 2)
 	})
+
+	t.Run("should register folder uid scope resolver with nested folders", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		ac := acmock.New()
+
+		ProvideFolderService(cfg, nil, nil, nil, featuremgmt.WithFeatures(featuremgmt.FlagNestedFolders), nil, ac, busmock.New())
+
+		require.Len(t, ac.Calls.RegisterAttributeScopeResolver, 3)
+	})
 }
 
 func TestIntegrationFolderService(t *testing.T) {
@@ -93,7 +102,7 @@ func TestIntegrationFolderService(t *testing.T) {
 
 			t.Run("When creating folder should return access denied error", func(t *testing.T) {
 				store.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything).Return(true, nil).Times(2)
-				_, err := service.CreateFolder(context.Background(), user, orgID, folder.Title, folderUID)
+				_, err := service.CreateFolder(context.Background(), user, orgID, folder.Title, folderUID, "")
 				require.Equal(t, err, models.ErrFolderAccessDenied)
 			})
 
@@ -134,7 +143,7 @@ func TestIntegrationFolderService(t *testing.T) {
 				store.On("SaveDashboard", mock.Anything).Return(dash, nil).Once()
 				store.On("GetFolderByID", mock.Anything, orgID, dash.Id).Return(f, nil)
 
-				actualFolder, err := service.CreateFolder(context.Background(), user, orgID, dash.Title, "")
+				actualFolder, err := service.CreateFolder(context.Background(), user, orgID, dash.Title, "", "")
 				require.NoError(t, err)
 				require.Equal(t, f, actualFolder)
 			})
@@ -143,7 +152,7 @@ func TestIntegrationFolderService(t *testing.T) {
 				dash := models.NewDashboardFolder("Test-Folder")
 				dash.Id = rand.Int63()
 
-				_, err := service.CreateFolder(context.Background(), user, orgID, dash.Title, "general")
+				_, err := service.CreateFolder(context.Background(), user, orgID, dash.Title, "general", "")
 				require.ErrorIs(t, err, models.ErrFolderInvalidUID)
 			})
 
@@ -256,3 +265,109 @@ func TestIntegrationFolderService(t *testing.T) {
 		})
 	})
 }
+
+func TestIntegrationFolderServiceMoveFolder(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	setup := func(t *testing.T, features featuremgmt.FeatureToggles) (*FolderServiceImpl, *dashboards.FakeDashboardStore) {
+		store := &dashboards.FakeDashboardStore{}
+		cfg := setting.NewCfg()
+		cfg.IsFeatureToggleEnabled = features.IsEnabled
+		folderPermissions := acmock.NewMockedPermissionsService()
+		dashboardPermissions := acmock.NewMockedPermissionsService()
+		dashboardService := ProvideDashboardService(cfg, store, nil, features, folderPermissions, dashboardPermissions, acmock.New())
+
+		origNewGuardian := guardian.New
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+		t.Cleanup(func() {
+			guardian.New = origNewGuardian
+		})
+
+		return &FolderServiceImpl{
+			cfg:              cfg,
+			log:              log.New("test-folder-service"),
+			dashboardService: dashboardService,
+			dashboardStore:   store,
+			features:         features,
+			permissions:      folderPermissions,
+			bus:              busmock.New(),
+		}, store
+	}
+
+	newFolder := func(title string) *models.Dashboard {
+		dash := models.NewDashboardFolder(title)
+		dash.Id = rand.Int63()
+		dash.Uid = util.GenerateShortUID()
+		return dash
+	}
+
+	expectGetDashboard := func(store *dashboards.FakeDashboardStore, dash *models.Dashboard) {
+		store.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.GetDashboardQuery).Result = dash
+		}).Return(dash, nil)
+	}
+
+	t.Run("should fail when nested folders are disabled", func(t *testing.T) {
+		service, _ := setup(t, featuremgmt.WithFeatures())
+
+		err := service.MoveFolder(context.Background(), user, orgID, "folder", &models.MoveFolderCommand{ParentUid: "parent"})
+		require.ErrorIs(t, err, models.ErrDashboardFolderCannotHaveParent)
+	})
+
+	t.Run("should move folder into parent folder", func(t *testing.T) {
+		service, store := setup(t, featuremgmt.WithFeatures(featuremgmt.FlagNestedFolders))
+		dash := newFolder("Folder")
+		parent := models.DashboardToFolder(newFolder("Parent"))
+		expectGetDashboard(store, dash)
+		store.On("GetFolderByUID", mock.Anything, orgID, parent.Uid).Return(parent, nil)
+		store.On("GetFolderParents", mock.Anything, orgID, parent.Id).Return([]*models.Folder{}, nil)
+		store.On("GetFolderChildren", mock.Anything, orgID, dash.Id).Return([]*models.Folder{}, nil)
+		store.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything).Return(true, nil)
+		var saved models.SaveDashboardCommand
+		store.On("SaveDashboard", mock.Anything).Run(func(args mock.Arguments) {
+			saved = args.Get(0).(models.SaveDashboardCommand)
+		}).Return(dash, nil)
+		moved := models.DashboardToFolder(dash)
+		moved.ParentId = parent.Id
+		moved.ParentUid = parent.Uid
+		store.On("GetFolderByID", mock.Anything, orgID, dash.Id).Return(moved, nil)
+
+		cmd := &models.MoveFolderCommand{ParentUid: parent.Uid}
+		err := service.MoveFolder(context.Background(), user, orgID, dash.Uid, cmd)
+		require.NoError(t, err)
+		require.Equal(t, parent.Id, saved.FolderId)
+		require.Equal(t, moved, cmd.Result)
+	})
+
+	t.Run("should fail to move folder into its own subfolder", func(t *testing.T) {
+		service, store := setup(t, featuremgmt.WithFeatures(featuremgmt.FlagNestedFolders))
+		dash := newFolder("Folder")
+		child := models.DashboardToFolder(newFolder("Child"))
+		child.ParentId = dash.Id
+		expectGetDashboard(store, dash)
+		store.On("GetFolderByUID", mock.Anything, orgID, child.Uid).Return(child, nil)
+		store.On("GetFolderParents", mock.Anything, orgID, child.Id).Return([]*models.Folder{models.DashboardToFolder(dash)}, nil)
+
+		err := service.MoveFolder(context.Background(), user, orgID, dash.Uid, &models.MoveFolderCommand{ParentUid: child.Uid})
+		require.ErrorIs(t, err, models.ErrDashboardFolderCircularReference)
+	})
+
+	t.Run("should fail to move folder when the tree gets too deep", func(t *testing.T) {
+		service, store := setup(t, featuremgmt.WithFeatures(featuremgmt.FlagNestedFolders))
+		dash := newFolder("Folder")
+		parent := models.DashboardToFolder(newFolder("Parent"))
+		ancestors := make([]*models.Folder, 0, models.MaxNestedFolderDepth-1)
+		for i := 0; i < models.MaxNestedFolderDepth-1; i++ {
+			ancestors = append(ancestors, models.DashboardToFolder(newFolder("Ancestor")))
+		}
+		expectGetDashboard(store, dash)
+		store.On("GetFolderByUID", mock.Anything, orgID, parent.Uid).Return(parent, nil)
+		store.On("GetFolderParents", mock.Anything, orgID, parent.Id).Return(ancestors, nil)
+		store.On("GetFolderChildren", mock.Anything, orgID, dash.Id).Return([]*models.Folder{}, nil)
+
+		err := service.MoveFolder(context.Background(), user, orgID, dash.Uid, &models.MoveFolderCommand{ParentUid: parent.Uid})
+		require.ErrorIs(t, err, models.ErrDashboardFolderMaxDepthExceeded)
+	})
+}
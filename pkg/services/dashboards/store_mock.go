@@ -8,7 +8,11 @@ import (
 	models "github.com/grafana/grafana/pkg/models"
 	mock "github.com/stretchr/testify/mock"
 
+	schemaversion "github.com/grafana/grafana/pkg/services/dashboards/schemaversion"
+
 	testing "testing"
+
+	time "time"
 )
 
 // FakeDashboardStore is an autogenerated mock type for the Store type
@@ -30,6 +34,41 @@ func (_m *FakeDashboardStore) DeleteDashboard(ctx context.Context, cmd *models.D
 	return r0
 }
 
+// DeleteDashboardFromTrash provides a mock function with given fields: ctx, cmd
+func (_m *FakeDashboardStore) DeleteDashboardFromTrash(ctx context.Context, cmd *models.DeleteDashboardFromTrashCommand) error {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeleteDashboardFromTrashCommand) error); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpiredDashboardTrash provides a mock function with given fields: ctx, olderThan
+func (_m *FakeDashboardStore) DeleteExpiredDashboardTrash(ctx context.Context, olderThan time.Time) (int64, error) {
+	ret := _m.Called(ctx, olderThan)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, olderThan)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, olderThan)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteOrphanedProvisionedDashboards provides a mock function with given fields: ctx, cmd
 func (_m *FakeDashboardStore) DeleteOrphanedProvisionedDashboards(ctx context.Context, cmd *models.DeleteOrphanedProvisionedDashboardsCommand) error {
 	ret := _m.Called(ctx, cmd)
@@ -118,6 +157,34 @@ func (_m *FakeDashboardStore) GetDashboardTags(ctx context.Context, query *model
 	return r0
 }
 
+// GetDashboardTrash provides a mock function with given fields: ctx, query
+func (_m *FakeDashboardStore) GetDashboardTrash(ctx context.Context, query *models.GetDashboardTrashQuery) error {
+	ret := _m.Called(ctx, query)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetDashboardTrashQuery) error); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDashboardTrashItem provides a mock function with given fields: ctx, query
+func (_m *FakeDashboardStore) GetDashboardTrashItem(ctx context.Context, query *models.GetDashboardTrashItemQuery) error {
+	ret := _m.Called(ctx, query)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GetDashboardTrashItemQuery) error); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetDashboardUIDById provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardStore) GetDashboardUIDById(ctx context.Context, query *models.GetDashboardRefByIdQuery) error {
 	ret := _m.Called(ctx, query)
@@ -160,6 +227,29 @@ func (_m *FakeDashboardStore) GetDashboardsByPluginID(ctx context.Context, query
 	return r0
 }
 
+// GetDataSourceLookup provides a mock function with given fields: ctx, orgID
+func (_m *FakeDashboardStore) GetDataSourceLookup(ctx context.Context, orgID int64) (schemaversion.DataSourceLookup, error) {
+	ret := _m.Called(ctx, orgID)
+
+	var r0 schemaversion.DataSourceLookup
+	if rf, ok := ret.Get(0).(func(context.Context, int64) schemaversion.DataSourceLookup); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(schemaversion.DataSourceLookup)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFolderByID provides a mock function with given fields: ctx, orgID, id
 func (_m *FakeDashboardStore) GetFolderByID(ctx context.Context, orgID int64, id int64) (*models.Folder, error) {
 	ret := _m.Called(ctx, orgID, id)
@@ -229,6 +319,52 @@ func (_m *FakeDashboardStore) GetFolderByUID(ctx context.Context, orgID int64, u
 	return r0, r1
 }
 
+// GetFolderChildren provides a mock function with given fields: ctx, orgID, id
+func (_m *FakeDashboardStore) GetFolderChildren(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, orgID, id)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Folder); ok {
+		r0 = rf(ctx, orgID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, orgID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFolderParents provides a mock function with given fields: ctx, orgID, id
+func (_m *FakeDashboardStore) GetFolderParents(ctx context.Context, orgID int64, id int64) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, orgID, id)
+
+	var r0 []*models.Folder
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*models.Folder); ok {
+		r0 = rf(ctx, orgID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, orgID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProvisionedDashboardData provides a mock function with given fields: name
 func (_m *FakeDashboardStore) GetProvisionedDashboardData(name string) ([]*models.DashboardProvisioning, error) {
 	ret := _m.Called(name)
@@ -381,6 +517,34 @@ func (_m *FakeDashboardStore) HasEditPermissionInFolders(ctx context.Context, qu
 	return r0
 }
 
+// MigrateDashboardsSchema provides a mock function with given fields: ctx, cmd
+func (_m *FakeDashboardStore) MigrateDashboardsSchema(ctx context.Context, cmd *models.MigrateDashboardsSchemaCommand) error {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.MigrateDashboardsSchemaCommand) error); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreDashboardFromTrash provides a mock function with given fields: ctx, cmd
+func (_m *FakeDashboardStore) RestoreDashboardFromTrash(ctx context.Context, cmd *models.RestoreDashboardFromTrashCommand) error {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.RestoreDashboardFromTrashCommand) error); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveAlerts provides a mock function with given fields: ctx, dashID, alerts
 func (_m *FakeDashboardStore) SaveAlerts(ctx context.Context, dashID int64, alerts []*models.Alert) error {
 	ret := _m.Called(ctx, dashID, alerts)
@@ -35,8 +35,15 @@ func (d *DashboardSnapshotStore) DeleteExpiredSnapshots(ctx context.Context, cmd
 			return nil
 		}
 
+		now := time.Now()
+		var storagePaths []string
+		if err := sess.Table("dashboard_snapshot").Where("expires < ? AND storage_path IS NOT NULL AND storage_path <> ''", now).Cols("storage_path").Find(&storagePaths); err != nil {
+			return err
+		}
+		cmd.StoragePaths = storagePaths
+
 		deleteExpiredSQL := "DELETE FROM dashboard_snapshot WHERE expires < ?"
-		expiredResponse, err := sess.Exec(deleteExpiredSQL, time.Now())
+		expiredResponse, err := sess.Exec(deleteExpiredSQL, now)
 		if err != nil {
 			return err
 		}
@@ -64,6 +71,7 @@ func (d *DashboardSnapshotStore) CreateDashboardSnapshot(ctx context.Context, cm
 			ExternalDeleteUrl:  cmd.ExternalDeleteUrl,
 			Dashboard:          simplejson.New(),
 			DashboardEncrypted: cmd.DashboardEncrypted,
+			StoragePath:        cmd.StoragePath,
 			Expires:            expires,
 			Created:            time.Now(),
 			Updated:            time.Now(),
@@ -129,3 +137,25 @@ func (d *DashboardSnapshotStore) SearchDashboardSnapshots(ctx context.Context, q
 		return err
 	})
 }
+
+// GetDashboardSnapshotsInDatabase returns the snapshots with the dashboard stored in the database, after the given id.
+func (d *DashboardSnapshotStore) GetDashboardSnapshotsInDatabase(ctx context.Context, afterID int64, limit int) ([]*dashboardsnapshots.DashboardSnapshot, error) {
+	snapshots := make([]*dashboardsnapshots.DashboardSnapshot, 0)
+	err := d.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("id > ? AND external = ? AND (storage_path IS NULL OR storage_path = '')", afterID, d.store.GetDialect().BooleanStr(false)).
+			Asc("id").
+			Limit(limit).
+			Find(&snapshots)
+	})
+	return snapshots, err
+}
+
+// UpdateDashboardSnapshotStoragePath records that the dashboard of the snapshot was moved to the snapshot storage,
+// removing it from the database.
+func (d *DashboardSnapshotStore) UpdateDashboardSnapshotStoragePath(ctx context.Context, id int64, storagePath string) error {
+	return d.store.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("UPDATE dashboard_snapshot SET storage_path = ?, dashboard = ?, dashboard_encrypted = NULL, updated = ? WHERE id = ?",
+			storagePath, "{}", time.Now(), id)
+		return err
+	})
+}
@@ -1,12 +1,19 @@
 package dashboardsnapshots
 
 import (
+	"errors"
+	"io"
 	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 )
 
+var (
+	ErrSnapshotStorageNotConfigured = errors.New("snapshot storage is not configured")
+	ErrSnapshotDashboardNotFound    = errors.New("snapshot dashboard not found in storage")
+)
+
 // DashboardSnapshot model
 type DashboardSnapshot struct {
 	Id                int64
@@ -25,6 +32,12 @@ type DashboardSnapshot struct {
 
 	Dashboard          *simplejson.Json
 	DashboardEncrypted []byte
+	// StoragePath is the path of the encrypted dashboard in the snapshot storage,
+	// empty when the dashboard is stored in the database
+	StoragePath string
+	// DashboardReader reads the dashboard json, set instead of Dashboard when the query asks for the raw dashboard.
+	// It must be closed by the caller.
+	DashboardReader io.ReadCloser `xorm:"-"`
 }
 
 // DashboardSnapshotDTO without dashboard map
@@ -77,6 +90,7 @@ type CreateDashboardSnapshotCommand struct {
 	UserId int64 `json:"-"`
 
 	DashboardEncrypted []byte `json:"-"`
+	StoragePath        string `json:"-"`
 
 	Result *DashboardSnapshot
 }
@@ -87,11 +101,16 @@ type DeleteDashboardSnapshotCommand struct {
 
 type DeleteExpiredSnapshotsCommand struct {
 	DeletedRows int64
+	// StoragePaths are the paths of the deleted snapshots stored in the snapshot storage
+	StoragePaths []string
 }
 
 type GetDashboardSnapshotQuery struct {
 	Key       string
 	DeleteKey string
+	// Raw returns a reader of the dashboard json in Result.DashboardReader without decoding it,
+	// the dashboards in the snapshot storage are streamed instead of being loaded in memory
+	Raw bool
 
 	Result *DashboardSnapshot
 }
@@ -106,3 +125,9 @@ type GetDashboardSnapshotsQuery struct {
 
 	Result DashboardSnapshotsList
 }
+
+// MoveSnapshotsToStorageCommand moves the dashboards of the snapshots stored in the database to the snapshot storage.
+type MoveSnapshotsToStorageCommand struct {
+	Moved  int64
+	Failed int64
+}
@@ -1,28 +1,74 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
+const moveSnapshotsBatchSize = 100
+
 type ServiceImpl struct {
 	store          dashboardsnapshots.Store
 	secretsService secrets.Service
+	// storage holds the encrypted dashboards of the snapshots, nil when they are stored in the database
+	storage filestorage.FileStorage
+	log     log.Logger
 }
 
 // ServiceImpl implements the dashboardsnapshots Service interface
 var _ dashboardsnapshots.Service = (*ServiceImpl)(nil)
 
-func ProvideService(store dashboardsnapshots.Store, secretsService secrets.Service) *ServiceImpl {
+func ProvideService(cfg *setting.Cfg, store dashboardsnapshots.Store, secretsService secrets.Service) (*ServiceImpl, error) {
 	s := &ServiceImpl{
 		store:          store,
 		secretsService: secretsService,
+		log:            log.New("dashboardsnapshots"),
+	}
+
+	if cfg.SnapshotStorageURL != "" {
+		storage, err := newSnapshotStorage(s.log, cfg.SnapshotStorageURL, cfg.DataPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open snapshot storage: %w", err)
+		}
+		s.storage = storage
+	}
+
+	return s, nil
+}
+
+// newSnapshotStorage opens the storage of the snapshots, either a bucket URL such as s3://bucket or gs://bucket,
+// or a path on disk relative to the data path.
+func newSnapshotStorage(logger log.Logger, storageURL string, dataPath string) (filestorage.FileStorage, error) {
+	if !strings.Contains(storageURL, "://") {
+		path := storageURL
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dataPath, path)
+		}
+		if err := os.MkdirAll(path, 0750); err != nil {
+			return nil, err
+		}
+		storageURL = "file://" + filepath.ToSlash(path)
 	}
 
-	return s
+	bucket, err := filestorage.OpenBucket(context.Background(), storageURL)
+	if err != nil {
+		return nil, err
+	}
+	return filestorage.NewCdkBlobStorage(logger, bucket, "", filestorage.NewAllowAllPathFilter()), nil
 }
 
 func (s *ServiceImpl) CreateDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.CreateDashboardSnapshotCommand) error {
@@ -31,14 +77,26 @@ func (s *ServiceImpl) CreateDashboardSnapshot(ctx context.Context, cmd *dashboar
 		return err
 	}
 
-	encryptedDashboard, err := s.secretsService.Encrypt(ctx, marshalledData, secrets.WithoutScope())
-	if err != nil {
-		return err
+	if s.storage == nil {
+		encryptedDashboard, err := s.secretsService.Encrypt(ctx, marshalledData, secrets.WithoutScope())
+		if err != nil {
+			return err
+		}
+		cmd.DashboardEncrypted = encryptedDashboard
+		return s.store.CreateDashboardSnapshot(ctx, cmd)
 	}
 
-	cmd.DashboardEncrypted = encryptedDashboard
+	storagePath := snapshotStoragePath(cmd.OrgId)
+	if err := s.writeToStorage(ctx, storagePath, marshalledData); err != nil {
+		return err
+	}
+	cmd.StoragePath = storagePath
 
-	return s.store.CreateDashboardSnapshot(ctx, cmd)
+	if err := s.store.CreateDashboardSnapshot(ctx, cmd); err != nil {
+		s.deleteFromStorage(ctx, storagePath)
+		return err
+	}
+	return nil
 }
 
 func (s *ServiceImpl) GetDashboardSnapshot(ctx context.Context, query *dashboardsnapshots.GetDashboardSnapshotQuery) error {
@@ -47,25 +105,74 @@ func (s *ServiceImpl) GetDashboardSnapshot(ctx context.Context, query *dashboard
 		return err
 	}
 
+	if query.Result.StoragePath != "" {
+		reader, err := s.readFromStorage(ctx, query.Result.StoragePath)
+		if err != nil {
+			return err
+		}
+
+		if query.Raw {
+			query.Result.DashboardReader = reader
+			return nil
+		}
+
+		defer func() {
+			if err := reader.Close(); err != nil {
+				s.log.Warn("Failed to close snapshot dashboard", "path", query.Result.StoragePath, "error", err)
+			}
+		}()
+		dashboard, err := simplejson.NewFromReader(reader)
+		if err != nil {
+			return err
+		}
+
+		query.Result.Dashboard = dashboard
+		return nil
+	}
+
 	if query.Result.DashboardEncrypted != nil {
 		decryptedDashboard, err := s.secretsService.Decrypt(ctx, query.Result.DashboardEncrypted)
 		if err != nil {
 			return err
 		}
 
+		if query.Raw {
+			query.Result.DashboardReader = io.NopCloser(bytes.NewReader(decryptedDashboard))
+			return nil
+		}
+
 		dashboard, err := simplejson.NewJson(decryptedDashboard)
 		if err != nil {
 			return err
 		}
 
 		query.Result.Dashboard = dashboard
+	} else if query.Raw && query.Result.Dashboard != nil {
+		// snapshots created before the dashboards were encrypted
+		rawDashboard, err := query.Result.Dashboard.Encode()
+		if err != nil {
+			return err
+		}
+		query.Result.DashboardReader = io.NopCloser(bytes.NewReader(rawDashboard))
 	}
 
-	return err
+	return nil
 }
 
 func (s *ServiceImpl) DeleteDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.DeleteDashboardSnapshotCommand) error {
-	return s.store.DeleteDashboardSnapshot(ctx, cmd)
+	query := dashboardsnapshots.GetDashboardSnapshotQuery{DeleteKey: cmd.DeleteKey}
+	if err := s.store.GetDashboardSnapshot(ctx, &query); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteDashboardSnapshot(ctx, cmd); err != nil {
+		return err
+	}
+
+	if query.Result.StoragePath != "" {
+		s.deleteFromStorage(ctx, query.Result.StoragePath)
+	}
+	return nil
 }
 
 func (s *ServiceImpl) SearchDashboardSnapshots(ctx context.Context, query *dashboardsnapshots.GetDashboardSnapshotsQuery) error {
@@ -73,5 +180,119 @@ func (s *ServiceImpl) SearchDashboardSnapshots(ctx context.Context, query *dashb
 }
 
 func (s *ServiceImpl) DeleteExpiredSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteExpiredSnapshotsCommand) error {
-	return s.store.DeleteExpiredSnapshots(ctx, cmd)
+	if err := s.store.DeleteExpiredSnapshots(ctx, cmd); err != nil {
+		return err
+	}
+
+	for _, storagePath := range cmd.StoragePaths {
+		s.deleteFromStorage(ctx, storagePath)
+	}
+	return nil
+}
+
+// MoveSnapshotsToStorage moves the dashboards of the snapshots stored in the database to the snapshot storage.
+func (s *ServiceImpl) MoveSnapshotsToStorage(ctx context.Context, cmd *dashboardsnapshots.MoveSnapshotsToStorageCommand) error {
+	if s.storage == nil {
+		return dashboardsnapshots.ErrSnapshotStorageNotConfigured
+	}
+
+	var lastID int64
+	for {
+		snapshots, err := s.store.GetDashboardSnapshotsInDatabase(ctx, lastID, moveSnapshotsBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			return nil
+		}
+		lastID = snapshots[len(snapshots)-1].Id
+
+		for _, snapshot := range snapshots {
+			if err := s.moveSnapshotToStorage(ctx, snapshot); err != nil {
+				s.log.Error("Failed to move dashboard snapshot to storage", "id", snapshot.Id, "error", err)
+				cmd.Failed++
+				continue
+			}
+			cmd.Moved++
+		}
+	}
+}
+
+func (s *ServiceImpl) moveSnapshotToStorage(ctx context.Context, snapshot *dashboardsnapshots.DashboardSnapshot) error {
+	var dashboard []byte
+	var err error
+	if snapshot.DashboardEncrypted != nil {
+		dashboard, err = s.secretsService.Decrypt(ctx, snapshot.DashboardEncrypted)
+	} else {
+		// snapshots created before the dashboards were encrypted
+		dashboard, err = snapshot.Dashboard.Encode()
+	}
+	if err != nil {
+		return err
+	}
+
+	storagePath := snapshotStoragePath(snapshot.OrgId)
+	if err := s.writeToStorage(ctx, storagePath, dashboard); err != nil {
+		return err
+	}
+
+	if err := s.store.UpdateDashboardSnapshotStoragePath(ctx, snapshot.Id, storagePath); err != nil {
+		s.deleteFromStorage(ctx, storagePath)
+		return err
+	}
+	return nil
+}
+
+func snapshotStoragePath(orgID int64) string {
+	return filestorage.Join(strconv.FormatInt(orgID, 10), util.GenerateShortUID())
+}
+
+func (s *ServiceImpl) writeToStorage(ctx context.Context, storagePath string, dashboard []byte) error {
+	encrypted, err := s.encryptForStorage(ctx, dashboard)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Upsert(ctx, &filestorage.UpsertFileCommand{
+		Path:     storagePath,
+		MimeType: "application/octet-stream",
+		Contents: encrypted,
+	})
+}
+
+// readFromStorage returns a reader decrypting the dashboard while it is read from the storage,
+// it must be closed by the caller.
+func (s *ServiceImpl) readFromStorage(ctx context.Context, storagePath string) (io.ReadCloser, error) {
+	if s.storage == nil {
+		return nil, dashboardsnapshots.ErrSnapshotStorageNotConfigured
+	}
+
+	file, err := s.storage.Read(ctx, storagePath)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, dashboardsnapshots.ErrSnapshotDashboardNotFound
+	}
+
+	decrypted, err := s.newDecryptingReader(ctx, file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{decrypted, file}, nil
+}
+
+// deleteFromStorage deletes a snapshot dashboard from the storage, failures leave an orphaned file and are only logged.
+func (s *ServiceImpl) deleteFromStorage(ctx context.Context, storagePath string) {
+	if s.storage == nil {
+		s.log.Warn("Snapshot storage is not configured, cannot delete the snapshot dashboard", "path", storagePath)
+		return
+	}
+	if err := s.storage.Delete(ctx, storagePath); err != nil {
+		s.log.Warn("Failed to delete snapshot dashboard from storage", "path", storagePath, "error", err)
+	}
 }
@@ -2,13 +2,16 @@ package service
 
 import (
 	"context"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashsnapdb "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/database"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -19,7 +22,8 @@ func TestDashboardSnapshotsService(t *testing.T) {
 	sqlStore := sqlstore.InitTestDB(t)
 	dsStore := dashsnapdb.ProvideStore(sqlStore)
 	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
-	s := ProvideService(dsStore, secretsService)
+	s, err := ProvideService(setting.NewCfg(), dsStore, secretsService)
+	require.NoError(t, err)
 
 	origSecret := setting.SecretKey
 	setting.SecretKey = "dashboard_snapshot_service_test"
@@ -68,3 +72,102 @@ func TestDashboardSnapshotsService(t *testing.T) {
 		require.Equal(t, rawDashboard, decrypted)
 	})
 }
+
+func TestDashboardSnapshotsServiceWithStorage(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	dsStore := dashsnapdb.ProvideStore(sqlStore)
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+	cfg.SnapshotStorageURL = "snapshots"
+	s, err := ProvideService(cfg, dsStore, secretsService)
+	require.NoError(t, err)
+
+	rawDashboard := []byte(`{"id":123}`)
+	dashboard, err := simplejson.NewJson(rawDashboard)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	t.Run("create dashboard snapshot should store the encrypted dashboard in the storage", func(t *testing.T) {
+		cmd := dashboardsnapshots.CreateDashboardSnapshotCommand{
+			Key:       "stored",
+			DeleteKey: "stored",
+			OrgId:     1,
+			Dashboard: dashboard,
+		}
+		require.NoError(t, s.CreateDashboardSnapshot(ctx, &cmd))
+		require.NotEmpty(t, cmd.Result.StoragePath)
+		require.Empty(t, cmd.Result.DashboardEncrypted)
+
+		file, err := s.storage.Get(ctx, cmd.Result.StoragePath)
+		require.NoError(t, err)
+		require.NotNil(t, file)
+		require.NotContains(t, string(file.Contents), string(rawDashboard))
+
+		query := dashboardsnapshots.GetDashboardSnapshotQuery{Key: "stored", Raw: true}
+		require.NoError(t, s.GetDashboardSnapshot(ctx, &query))
+		require.NotNil(t, query.Result.DashboardReader)
+		decrypted, err := io.ReadAll(query.Result.DashboardReader)
+		require.NoError(t, err)
+		require.NoError(t, query.Result.DashboardReader.Close())
+		require.Equal(t, rawDashboard, decrypted)
+	})
+
+	t.Run("delete dashboard snapshot should delete the dashboard from the storage", func(t *testing.T) {
+		query := dashboardsnapshots.GetDashboardSnapshotQuery{Key: "stored"}
+		require.NoError(t, s.GetDashboardSnapshot(ctx, &query))
+
+		require.NoError(t, s.DeleteDashboardSnapshot(ctx, &dashboardsnapshots.DeleteDashboardSnapshotCommand{DeleteKey: "stored"}))
+
+		file, err := s.storage.Get(ctx, query.Result.StoragePath)
+		require.NoError(t, err)
+		require.Nil(t, file)
+	})
+
+	t.Run("move snapshots to storage should move the dashboards stored in the database", func(t *testing.T) {
+		encrypted, err := secretsService.Encrypt(ctx, rawDashboard, secrets.WithoutScope())
+		require.NoError(t, err)
+		require.NoError(t, dsStore.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{
+			Key:                "in-database",
+			DeleteKey:          "in-database",
+			OrgId:              1,
+			Dashboard:          simplejson.New(),
+			DashboardEncrypted: encrypted,
+		}))
+		// snapshots created before the dashboards were encrypted
+		err = sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Insert(&dashboardsnapshots.DashboardSnapshot{
+				Key:       "legacy",
+				DeleteKey: "legacy",
+				OrgId:     1,
+				Dashboard: dashboard,
+				Expires:   time.Now().Add(time.Hour),
+				Created:   time.Now(),
+				Updated:   time.Now(),
+			})
+			return err
+		})
+		require.NoError(t, err)
+
+		cmd := dashboardsnapshots.MoveSnapshotsToStorageCommand{}
+		require.NoError(t, s.MoveSnapshotsToStorage(ctx, &cmd))
+		require.EqualValues(t, 2, cmd.Moved)
+		require.EqualValues(t, 0, cmd.Failed)
+
+		for _, key := range []string{"in-database", "legacy"} {
+			query := dashboardsnapshots.GetDashboardSnapshotQuery{Key: key}
+			require.NoError(t, s.GetDashboardSnapshot(ctx, &query))
+			require.NotEmpty(t, query.Result.StoragePath)
+			require.Empty(t, query.Result.DashboardEncrypted)
+			decoded, err := query.Result.Dashboard.Encode()
+			require.NoError(t, err)
+			require.Equal(t, rawDashboard, decoded)
+		}
+
+		remaining, err := dsStore.GetDashboardSnapshotsInDatabase(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Empty(t, remaining)
+	})
+}
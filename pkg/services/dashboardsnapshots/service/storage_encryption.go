@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// The dashboards in the snapshot storage are encrypted with a random data key, itself encrypted with the
+// secrets service, so that they can be decrypted while they are read from the storage. A stored dashboard is
+// the length of the encrypted data key on 4 bytes, the encrypted data key, the IV and the dashboard encrypted
+// with AES in CFB mode.
+
+const (
+	dataKeyLength = 32
+	// maxEncryptedDataKeyLength bounds the allocation for the encrypted data key of corrupted files
+	maxEncryptedDataKeyLength = 4096
+)
+
+var errInvalidStoredDashboard = errors.New("invalid encrypted dashboard in snapshot storage")
+
+func (s *ServiceImpl) encryptForStorage(ctx context.Context, dashboard []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeyLength)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	encryptedDataKey, err := s.secretsService.Encrypt(ctx, dataKey, secrets.WithoutScope())
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	headerLength := 4 + len(encryptedDataKey) + aes.BlockSize
+	encrypted := make([]byte, headerLength+len(dashboard))
+	binary.BigEndian.PutUint32(encrypted, uint32(len(encryptedDataKey)))
+	copy(encrypted[4:], encryptedDataKey)
+	iv := encrypted[4+len(encryptedDataKey) : headerLength]
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	cipher.NewCFBEncrypter(block, iv).XORKeyStream(encrypted[headerLength:], dashboard)
+	return encrypted, nil
+}
+
+// newDecryptingReader reads the header of a dashboard from the storage and returns a reader of the decrypted dashboard.
+func (s *ServiceImpl) newDecryptingReader(ctx context.Context, r io.Reader) (io.Reader, error) {
+	var encryptedDataKeyLength uint32
+	if err := binary.Read(r, binary.BigEndian, &encryptedDataKeyLength); err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidStoredDashboard, err)
+	}
+	if encryptedDataKeyLength == 0 || encryptedDataKeyLength > maxEncryptedDataKeyLength {
+		return nil, errInvalidStoredDashboard
+	}
+
+	encryptedDataKey := make([]byte, encryptedDataKeyLength)
+	if _, err := io.ReadFull(r, encryptedDataKey); err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidStoredDashboard, err)
+	}
+	dataKey, err := s.secretsService.Decrypt(ctx, encryptedDataKey)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(r, iv); err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidStoredDashboard, err)
+	}
+	return &cipher.StreamReader{S: cipher.NewCFBDecrypter(block, iv), R: r}, nil
+}
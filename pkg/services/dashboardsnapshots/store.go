@@ -10,4 +10,6 @@ type Store interface {
 	DeleteExpiredSnapshots(context.Context, *DeleteExpiredSnapshotsCommand) error
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) error
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) error
+	GetDashboardSnapshotsInDatabase(ctx context.Context, afterID int64, limit int) ([]*DashboardSnapshot, error)
+	UpdateDashboardSnapshotStoragePath(ctx context.Context, id int64, storagePath string) error
 }
@@ -8,4 +8,5 @@ type Service interface {
 	Get(context.Context, *GetDashboardVersionQuery) (*DashboardVersion, error)
 	DeleteExpired(context.Context, *DeleteExpiredVersionsCommand) error
 	List(context.Context, *ListDashboardVersionsQuery) ([]*DashboardVersionDTO, error)
+	Tag(context.Context, *TagDashboardVersionCommand) error
 }
@@ -2,6 +2,7 @@ package dashverimpl
 
 import (
 	"context"
+	"time"
 
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/sqlstore/db"
@@ -9,16 +10,22 @@ import (
 )
 
 const (
-	maxVersionsToDeletePerBatch = 100
-	maxVersionDeletionBatches   = 50
+	maxVersionsToDeletePerBatch  = 100
+	maxDashboardsPerBatch        = 100
+	maxRetentionDashboardBatches = 50
 )
 
 type Service struct {
+	cfg   *setting.Cfg
 	store store
+	// retentionCursor is the last dashboard processed by the retention policies, so that
+	// the next cleanup continues with the following dashboards
+	retentionCursor int64
 }
 
-func ProvideService(db db.DB) dashver.Service {
+func ProvideService(cfg *setting.Cfg, db db.DB) dashver.Service {
 	return &Service{
+		cfg: cfg,
 		store: &sqlStore{
 			db:      db,
 			dialect: db.GetDialect(),
@@ -35,36 +42,75 @@ func (s *Service) Get(ctx context.Context, query *dashver.GetDashboardVersionQue
 	return version, nil
 }
 
+// DeleteExpired deletes the dashboard versions not kept by the retention policies.
 func (s *Service) DeleteExpired(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand) error {
-	versionsToKeep := setting.DashboardVersionsToKeep
-	if versionsToKeep < 1 {
-		versionsToKeep = 1
+	policy := s.cfg.DashboardVersionsRetention
+	if policy.MaxCount == 0 && policy.MaxAge == 0 && len(policy.Thinning) == 0 {
+		return nil
 	}
 
-	for batch := 0; batch < maxVersionDeletionBatches; batch++ {
-		versionIdsToDelete, batchErr := s.store.GetBatch(ctx, cmd, maxVersionsToDeletePerBatch, versionsToKeep)
-		if batchErr != nil {
-			return batchErr
-		}
+	// the latest version is always kept, and only the count policy applies to the recent versions
+	minVersions := 1
+	if policy.MaxAge == 0 && len(policy.Thinning) == 0 {
+		minVersions = policy.MaxCount
+	}
 
-		if len(versionIdsToDelete) < 1 {
-			return nil
-		}
+	if cmd.DeletedRowsByReason == nil {
+		cmd.DeletedRowsByReason = make(map[string]int64)
+	}
 
-		deleted, err := s.store.DeleteBatch(ctx, cmd, versionIdsToDelete)
+	now := time.Now()
+	for batch := 0; batch < maxRetentionDashboardBatches; batch++ {
+		dashboardIDs, err := s.store.GetRetentionCandidates(ctx, s.retentionCursor, minVersions, maxDashboardsPerBatch)
 		if err != nil {
 			return err
 		}
+		if len(dashboardIDs) == 0 {
+			s.retentionCursor = 0
+			return nil
+		}
 
-		cmd.DeletedRows += deleted
+		for _, dashboardID := range dashboardIDs {
+			versions, err := s.store.ListForRetention(ctx, dashboardID)
+			if err != nil {
+				return err
+			}
 
-		if deleted < int64(maxVersionsToDeletePerBatch) {
+			for reason, ids := range versionsToDelete(versions, policy, now) {
+				for len(ids) > 0 {
+					n := len(ids)
+					if n > maxVersionsToDeletePerBatch {
+						n = maxVersionsToDeletePerBatch
+					}
+
+					deleted, err := s.store.DeleteBatch(ctx, cmd, ids[:n])
+					if err != nil {
+						return err
+					}
+					cmd.DeletedRows += deleted
+					cmd.DeletedRowsByReason[reason] += deleted
+					ids = ids[n:]
+				}
+			}
+			s.retentionCursor = dashboardID
+		}
+
+		if len(dashboardIDs) < maxDashboardsPerBatch {
+			s.retentionCursor = 0
 			break
 		}
 	}
 	return nil
 }
 
+// Tag sets or removes the tag of a dashboard version.
+func (s *Service) Tag(ctx context.Context, cmd *dashver.TagDashboardVersionCommand) error {
+	if len(cmd.Tag) > 255 {
+		return dashver.ErrDashboardVersionTagTooLong
+	}
+	return s.store.Tag(ctx, cmd)
+}
+
 // List all dashboard versions for the given dashboard ID.
 func (s *Service) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error) {
 	if query.Limit == 0 {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
@@ -28,31 +29,132 @@ func TestDashboardVersionService(t *testing.T) {
 }
 
 func TestDeleteExpiredVersions(t *testing.T) {
-	versionsToKeep := 5
-	setting.DashboardVersionsToKeep = versionsToKeep
+	cfg := setting.NewCfg()
+	cfg.DashboardVersionsRetention = setting.DashboardVersionsRetention{MaxCount: 5}
 
 	dashboardVersionStore := newDashboardVersionStoreFake()
-	dashboardVersionService := Service{store: dashboardVersionStore}
+	dashboardVersionService := Service{cfg: cfg, store: dashboardVersionStore}
 
 	t.Run("Don't delete anything if there are no expired versions", func(t *testing.T) {
-		err := dashboardVersionService.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{DeletedRows: 4})
+		cmd := &dashver.DeleteExpiredVersionsCommand{}
+		err := dashboardVersionService.DeleteExpired(context.Background(), cmd)
 		require.Nil(t, err)
+		require.EqualValues(t, 0, cmd.DeletedRows)
 	})
 
 	t.Run("Clean up old dashboard versions successfully", func(t *testing.T) {
-		dashboardVersionStore.ExptectedDeletedVersions = 4
-		dashboardVersionStore.ExpectedVersions = []interface{}{1, 2, 3, 4}
-		err := dashboardVersionService.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{DeletedRows: 4})
+		dashboardVersionStore.ExpectedDashboardIDs = []int64{1}
+		dashboardVersionStore.ExpectedRetentionVersions = testRetentionVersions(7, time.Now(), time.Hour)
+		cmd := &dashver.DeleteExpiredVersionsCommand{}
+		err := dashboardVersionService.DeleteExpired(context.Background(), cmd)
 		require.Nil(t, err)
+		require.EqualValues(t, 2, cmd.DeletedRows)
+		require.EqualValues(t, 2, cmd.DeletedRowsByReason[dashver.RetentionReasonCount])
+		require.Equal(t, []interface{}{int64(6), int64(7)}, dashboardVersionStore.DeletedIDs)
 	})
 
 	t.Run("Clean up old dashboard versions with error", func(t *testing.T) {
 		dashboardVersionStore.ExpectedError = errors.New("some error")
-		err := dashboardVersionService.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{DeletedRows: 4})
+		err := dashboardVersionService.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{})
 		require.NotNil(t, err)
 	})
 }
 
+func TestVersionsToDelete(t *testing.T) {
+	now := time.Date(2022, 6, 30, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	deleted := func(versions []*versionRetentionInfo, policy setting.DashboardVersionsRetention) map[string][]interface{} {
+		return versionsToDelete(versions, policy, now)
+	}
+
+	t.Run("should keep the latest versions", func(t *testing.T) {
+		versions := testRetentionVersions(5, now, time.Hour)
+		res := deleted(versions, setting.DashboardVersionsRetention{MaxCount: 3})
+		require.Equal(t, map[string][]interface{}{dashver.RetentionReasonCount: {int64(4), int64(5)}}, res)
+	})
+
+	t.Run("should keep tagged versions and the latest version", func(t *testing.T) {
+		versions := testRetentionVersions(4, now.Add(-100*day), day)
+		versions[2].Tag = "release-1"
+		res := deleted(versions, setting.DashboardVersionsRetention{MaxCount: 1, MaxAge: 30 * day})
+		require.Equal(t, map[string][]interface{}{dashver.RetentionReasonCount: {int64(2), int64(4)}}, res)
+	})
+
+	t.Run("should delete versions older than the max age", func(t *testing.T) {
+		versions := testRetentionVersions(5, now, 10*day)
+		res := deleted(versions, setting.DashboardVersionsRetention{MaxAge: 25 * day})
+		require.Equal(t, map[string][]interface{}{dashver.RetentionReasonAge: {int64(4), int64(5)}}, res)
+	})
+
+	t.Run("should keep one version per interval of old versions", func(t *testing.T) {
+		// 2 versions per day over 60 days
+		versions := testRetentionVersions(120, now, 12*time.Hour)
+		res := deleted(versions, setting.DashboardVersionsRetention{
+			Thinning: []setting.DashboardVersionThinningRule{
+				{OlderThan: 7 * day, Interval: day},
+				{OlderThan: 30 * day, Interval: 30 * day},
+			},
+		})
+
+		kept := make(map[int64]bool)
+		for _, v := range versions {
+			kept[v.ID] = true
+		}
+		for _, id := range res[dashver.RetentionReasonThinning] {
+			delete(kept, id.(int64))
+		}
+
+		// all versions of the last 7 days, one per day until 30 days and one per 30 days beyond
+		recent, daily, monthly := 0, 0, 0
+		for _, v := range versions {
+			if !kept[v.ID] {
+				continue
+			}
+			switch age := now.Sub(v.Created); {
+			case age < 7*day:
+				recent++
+			case age < 30*day:
+				daily++
+			default:
+				monthly++
+			}
+		}
+		require.Equal(t, 14, recent)
+		require.Equal(t, 23, daily)
+		require.LessOrEqual(t, monthly, 2)
+		require.Greater(t, monthly, 0)
+		require.Empty(t, res[dashver.RetentionReasonCount])
+		require.Empty(t, res[dashver.RetentionReasonAge])
+	})
+
+	t.Run("should thin versions before keeping the latest versions", func(t *testing.T) {
+		// 2 versions per day, the 2 latest versions being on different days
+		versions := testRetentionVersions(6, now.Add(-10*day-6*time.Hour), 12*time.Hour)
+		res := deleted(versions, setting.DashboardVersionsRetention{
+			MaxCount: 3,
+			Thinning: []setting.DashboardVersionThinningRule{{OlderThan: 7 * day, Interval: day}},
+		})
+		require.Equal(t, map[string][]interface{}{
+			dashver.RetentionReasonThinning: {int64(3), int64(5)},
+			dashver.RetentionReasonCount:    {int64(6)},
+		}, res)
+	})
+}
+
+// testRetentionVersions returns versions with ids from 1, latest first, created every interval before latest.
+func testRetentionVersions(count int, latest time.Time, interval time.Duration) []*versionRetentionInfo {
+	versions := make([]*versionRetentionInfo, 0, count)
+	for i := 0; i < count; i++ {
+		versions = append(versions, &versionRetentionInfo{
+			ID:      int64(i + 1),
+			Version: count - i,
+			Created: latest.Add(-time.Duration(i) * interval),
+		})
+	}
+	return versions
+}
+
 func TestListDashboardVersions(t *testing.T) {
 	dashboardVersionStore := newDashboardVersionStoreFake()
 	dashboardVersionService := Service{store: dashboardVersionStore}
@@ -67,11 +169,12 @@ func TestListDashboardVersions(t *testing.T) {
 }
 
 type FakeDashboardVersionStore struct {
-	ExpectedDashboardVersion *dashver.DashboardVersion
-	ExptectedDeletedVersions int64
-	ExpectedVersions         []interface{}
-	ExpectedListVersions     []*dashver.DashboardVersionDTO
-	ExpectedError            error
+	ExpectedDashboardVersion  *dashver.DashboardVersion
+	ExpectedDashboardIDs      []int64
+	ExpectedRetentionVersions []*versionRetentionInfo
+	ExpectedListVersions      []*dashver.DashboardVersionDTO
+	ExpectedError             error
+	DeletedIDs                []interface{}
 }
 
 func newDashboardVersionStoreFake() *FakeDashboardVersionStore {
@@ -82,14 +185,29 @@ func (f *FakeDashboardVersionStore) Get(ctx context.Context, query *dashver.GetD
 	return f.ExpectedDashboardVersion, f.ExpectedError
 }
 
-func (f *FakeDashboardVersionStore) GetBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, perBatch int, versionsToKeep int) ([]interface{}, error) {
-	return f.ExpectedVersions, f.ExpectedError
+func (f *FakeDashboardVersionStore) GetRetentionCandidates(ctx context.Context, afterDashboardID int64, minVersions int, limit int) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, id := range f.ExpectedDashboardIDs {
+		if id > afterDashboardID {
+			ids = append(ids, id)
+		}
+	}
+	return ids, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) ListForRetention(ctx context.Context, dashboardID int64) ([]*versionRetentionInfo, error) {
+	return f.ExpectedRetentionVersions, f.ExpectedError
 }
 
 func (f *FakeDashboardVersionStore) DeleteBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, versionIdsToDelete []interface{}) (int64, error) {
-	return f.ExptectedDeletedVersions, f.ExpectedError
+	f.DeletedIDs = append(f.DeletedIDs, versionIdsToDelete...)
+	return int64(len(versionIdsToDelete)), f.ExpectedError
 }
 
 func (f *FakeDashboardVersionStore) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error) {
 	return f.ExpectedListVersions, f.ExpectedError
 }
+
+func (f *FakeDashboardVersionStore) Tag(ctx context.Context, cmd *dashver.TagDashboardVersionCommand) error {
+	return f.ExpectedError
+}
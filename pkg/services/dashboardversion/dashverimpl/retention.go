@@ -0,0 +1,71 @@
+package dashverimpl
+
+import (
+	"time"
+
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type versionRetentionInfo struct {
+	ID      int64 `xorm:"id"`
+	Version int
+	Created time.Time
+	Tag     string
+}
+
+type thinningBucket struct {
+	rule   int
+	bucket int64
+}
+
+// versionsToDelete returns the ids of the versions of a dashboard deleted by the retention policy, by reason.
+// The versions are sorted by version, latest first. The latest version and tagged versions are always kept.
+// The versions are thinned before the count policy applies, so that the versions kept are spread over time
+// rather than being the latest versions.
+func versionsToDelete(versions []*versionRetentionInfo, policy setting.DashboardVersionsRetention, now time.Time) map[string][]interface{} {
+	toDelete := make(map[string][]interface{})
+	kept := make(map[thinningBucket]bool)
+	keptCount := 0
+
+	for i, v := range versions {
+		if i == 0 || v.Tag != "" {
+			keptCount++
+			continue
+		}
+
+		age := now.Sub(v.Created)
+		if rule := thinningRule(policy.Thinning, age); rule >= 0 {
+			// keep the latest version of each interval
+			bucket := thinningBucket{rule: rule, bucket: v.Created.UnixNano() / int64(policy.Thinning[rule].Interval)}
+			if kept[bucket] {
+				toDelete[dashver.RetentionReasonThinning] = append(toDelete[dashver.RetentionReasonThinning], v.ID)
+				continue
+			}
+			kept[bucket] = true
+		}
+
+		if policy.MaxCount > 0 && keptCount >= policy.MaxCount {
+			toDelete[dashver.RetentionReasonCount] = append(toDelete[dashver.RetentionReasonCount], v.ID)
+			continue
+		}
+		if policy.MaxAge > 0 && age > policy.MaxAge {
+			toDelete[dashver.RetentionReasonAge] = append(toDelete[dashver.RetentionReasonAge], v.ID)
+			continue
+		}
+		keptCount++
+	}
+
+	return toDelete
+}
+
+// thinningRule returns the index of the rule applying to versions of the given age, or -1 when none applies.
+func thinningRule(rules []setting.DashboardVersionThinningRule, age time.Duration) int {
+	rule := -1
+	for i, r := range rules {
+		if age >= r.OlderThan {
+			rule = i
+		}
+	}
+	return rule
+}
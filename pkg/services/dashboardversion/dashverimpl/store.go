@@ -12,9 +12,11 @@ import (
 
 type store interface {
 	Get(context.Context, *dashver.GetDashboardVersionQuery) (*dashver.DashboardVersion, error)
-	GetBatch(context.Context, *dashver.DeleteExpiredVersionsCommand, int, int) ([]interface{}, error)
+	GetRetentionCandidates(ctx context.Context, afterDashboardID int64, minVersions int, limit int) ([]int64, error)
+	ListForRetention(ctx context.Context, dashboardID int64) ([]*versionRetentionInfo, error)
 	DeleteBatch(context.Context, *dashver.DeleteExpiredVersionsCommand, []interface{}) (int64, error)
 	List(context.Context, *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error)
+	Tag(context.Context, *dashver.TagDashboardVersionCommand) error
 }
 
 type sqlStore struct {
@@ -44,23 +46,34 @@ func (ss *sqlStore) Get(ctx context.Context, query *dashver.GetDashboardVersionQ
 	return &version, nil
 }
 
-func (ss *sqlStore) GetBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, perBatch int, versionsToKeep int) ([]interface{}, error) {
-	var versionIds []interface{}
-	err := ss.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		versionIdsToDeleteQuery := `SELECT id
-			FROM dashboard_version, (
-				SELECT dashboard_id, count(version) as count, min(version) as min
-				FROM dashboard_version
-				GROUP BY dashboard_id
-			) AS vtd
-			WHERE dashboard_version.dashboard_id=vtd.dashboard_id
-			AND version < vtd.min + vtd.count - ?
+// GetRetentionCandidates returns the ids of the dashboards with more than minVersions versions, after the given dashboard id.
+func (ss *sqlStore) GetRetentionCandidates(ctx context.Context, afterDashboardID int64, minVersions int, limit int) ([]int64, error) {
+	var dashboardIDs []int64
+	err := ss.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rawSQL := `SELECT dashboard_id
+			FROM dashboard_version
+			WHERE dashboard_id > ?
+			GROUP BY dashboard_id
+			HAVING COUNT(*) > ?
+			ORDER BY dashboard_id
 			LIMIT ?`
 
-		err := sess.SQL(versionIdsToDeleteQuery, versionsToKeep, perBatch).Find(&versionIds)
-		return err
+		return sess.SQL(rawSQL, afterDashboardID, minVersions, limit).Find(&dashboardIDs)
 	})
-	return versionIds, err
+	return dashboardIDs, err
+}
+
+// ListForRetention returns the versions of a dashboard, latest first.
+func (ss *sqlStore) ListForRetention(ctx context.Context, dashboardID int64) ([]*versionRetentionInfo, error) {
+	versions := make([]*versionRetentionInfo, 0)
+	err := ss.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("dashboard_version").
+			Cols("id", "version", "created", "tag").
+			Where("dashboard_id = ?", dashboardID).
+			Desc("version").
+			Find(&versions)
+	})
+	return versions, err
 }
 
 func (ss *sqlStore) DeleteBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, versionIdsToDelete []interface{}) (int64, error) {
@@ -91,6 +104,7 @@ func (ss *sqlStore) List(ctx context.Context, query *dashver.ListDashboardVersio
 				dashboard_version.created,
 				dashboard_version.created_by as created_by_id,
 				dashboard_version.message,
+				dashboard_version.tag,
 				dashboard_version.data,`+
 				ss.dialect.Quote("user")+`.login as created_by`).
 			Join("LEFT", ss.dialect.Quote("user"), `dashboard_version.created_by = `+ss.dialect.Quote("user")+`.id`).
@@ -113,3 +127,21 @@ func (ss *sqlStore) List(ctx context.Context, query *dashver.ListDashboardVersio
 	}
 	return dashboardVersion, nil
 }
+
+func (ss *sqlStore) Tag(ctx context.Context, cmd *dashver.TagDashboardVersionCommand) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Table("dashboard_version").
+			Join("INNER", "dashboard", `dashboard.id = dashboard_version.dashboard_id`).
+			Where("dashboard_version.dashboard_id=? AND dashboard_version.version=? AND dashboard.org_id=?", cmd.DashboardID, cmd.Version, cmd.OrgID).
+			Exist()
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return dashver.ErrDashboardVersionNotFound
+		}
+
+		_, err = sess.Exec("UPDATE dashboard_version SET tag = ? WHERE dashboard_id = ? AND version = ?", cmd.Tag, cmd.DashboardID, cmd.Version)
+		return err
+	})
+}
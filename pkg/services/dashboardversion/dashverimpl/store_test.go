@@ -85,6 +85,55 @@ func TestIntegrationDeleteExpiredVersions(t *testing.T) {
 	})
 }
 
+func TestIntegrationDashboardVersionRetention(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := sqlstore.InitTestDB(t)
+	dashVerStore := sqlStore{db: ss, dialect: ss.Dialect}
+	insertTestDashboard(t, ss, "test dash 61", 1, 0, false, "retention")
+	savedDash := insertTestDashboard(t, ss, "test dash 62", 1, 0, false, "retention")
+	updateTestDashboard(t, ss, savedDash, map[string]interface{}{"tags": "v2"})
+	updateTestDashboard(t, ss, savedDash, map[string]interface{}{"tags": "v3"})
+
+	t.Run("Get the dashboards with more versions than the minimum", func(t *testing.T) {
+		ids, err := dashVerStore.GetRetentionCandidates(context.Background(), 0, 1, 100)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{savedDash.Id}, ids)
+
+		ids, err = dashVerStore.GetRetentionCandidates(context.Background(), savedDash.Id, 1, 100)
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("List the versions of a dashboard, latest first", func(t *testing.T) {
+		versions, err := dashVerStore.ListForRetention(context.Background(), savedDash.Id)
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+		assert.Equal(t, 3, versions[0].Version)
+		assert.Equal(t, 1, versions[2].Version)
+		assert.Empty(t, versions[0].Tag)
+	})
+
+	t.Run("Tag a dashboard version", func(t *testing.T) {
+		err := dashVerStore.Tag(context.Background(), &dashver.TagDashboardVersionCommand{DashboardID: savedDash.Id, OrgID: 1, Version: 2, Tag: "release-1"})
+		require.NoError(t, err)
+
+		versions, err := dashVerStore.ListForRetention(context.Background(), savedDash.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "release-1", versions[1].Tag)
+
+		res, err := dashVerStore.List(context.Background(), &dashver.ListDashboardVersionsQuery{DashboardID: savedDash.Id, OrgID: 1, Limit: 1000})
+		require.NoError(t, err)
+		assert.Equal(t, "release-1", res[1].Tag)
+	})
+
+	t.Run("Attempt to tag a version of another organization", func(t *testing.T) {
+		err := dashVerStore.Tag(context.Background(), &dashver.TagDashboardVersionCommand{DashboardID: savedDash.Id, OrgID: 2, Version: 2, Tag: "release-1"})
+		assert.ErrorIs(t, err, dashver.ErrDashboardVersionNotFound)
+	})
+}
+
 func TestIntegrationListDashboardVersions(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -8,8 +8,16 @@ import (
 )
 
 var (
-	ErrDashboardVersionNotFound = errors.New("dashboard version not found")
-	ErrNoVersionsForDashboardID = errors.New("no dashboard versions found for the given DashboardId")
+	ErrDashboardVersionNotFound   = errors.New("dashboard version not found")
+	ErrNoVersionsForDashboardID   = errors.New("no dashboard versions found for the given DashboardId")
+	ErrDashboardVersionTagTooLong = errors.New("dashboard version tag is too long, max 255 characters")
+)
+
+// Reasons for deleting dashboard versions, used to report the deleted rows by retention policy.
+const (
+	RetentionReasonCount    = "count"
+	RetentionReasonAge      = "age"
+	RetentionReasonThinning = "thinning"
 )
 
 type DashboardVersion struct {
@@ -24,6 +32,8 @@ type DashboardVersion struct {
 
 	Message string           `json:"message"`
 	Data    *simplejson.Json `json:"data"`
+	// Tag names a version, tagged versions are never deleted by the retention policies
+	Tag string `json:"tag"`
 }
 
 type GetDashboardVersionQuery struct {
@@ -34,6 +44,16 @@ type GetDashboardVersionQuery struct {
 
 type DeleteExpiredVersionsCommand struct {
 	DeletedRows int64
+	// DeletedRowsByReason holds the deleted rows by retention policy
+	DeletedRowsByReason map[string]int64
+}
+
+// TagDashboardVersionCommand sets the tag of a dashboard version, an empty tag removes it.
+type TagDashboardVersionCommand struct {
+	DashboardID int64
+	OrgID       int64
+	Version     int
+	Tag         string
 }
 
 type ListDashboardVersionsQuery struct {
@@ -54,6 +74,7 @@ type DashboardVersionDTO struct {
 	Created       time.Time `json:"created"`
 	CreatedBy     string    `json:"createdBy"`
 	Message       string    `json:"message"`
+	Tag           string    `json:"tag"`
 }
 
 // DashboardVersionMeta extends the dashboard version model with the names
@@ -70,4 +91,5 @@ type DashboardVersionMeta struct {
 	Message       string           `json:"message"`
 	Data          *simplejson.Json `json:"data"`
 	CreatedBy     string           `json:"createdBy"`
+	Tag           string           `json:"tag"`
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/limiter"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
@@ -25,7 +26,7 @@ import (
 func ProvideService(dataSourceCache datasources.CacheService, plugReqValidator models.PluginRequestValidator,
 	pluginStore plugins.Store, cfg *setting.Cfg, httpClientProvider httpclient.Provider,
 	oauthTokenService *oauthtoken.Service, dsService datasources.DataSourceService,
-	tracer tracing.Tracer, secretsService secrets.Service) *DataSourceProxyService {
+	tracer tracing.Tracer, secretsService secrets.Service, dataSourceLimiter *limiter.Service) *DataSourceProxyService {
 	return &DataSourceProxyService{
 		DataSourceCache:        dataSourceCache,
 		PluginRequestValidator: plugReqValidator,
@@ -36,6 +37,7 @@ func ProvideService(dataSourceCache datasources.CacheService, plugReqValidator m
 		DataSourcesService:     dsService,
 		tracer:                 tracer,
 		secretsService:         secretsService,
+		dataSourceLimiter:      dataSourceLimiter,
 	}
 }
 
@@ -49,6 +51,7 @@ type DataSourceProxyService struct {
 	DataSourcesService     datasources.DataSourceService
 	tracer                 tracing.Tracer
 	secretsService         secrets.Service
+	dataSourceLimiter      *limiter.Service
 }
 
 func (p *DataSourceProxyService) ProxyDataSourceRequest(c *models.ReqContext) {
@@ -128,6 +131,18 @@ func (p *DataSourceProxyService) proxyDatasourceRequest(c *models.ReqContext, ds
 		}
 		return
 	}
+
+	release, err := p.dataSourceLimiter.Acquire(c.Req.Context(), ds, c.SignedInUser)
+	if err != nil {
+		if errors.Is(err, limiter.ErrLimitReached) {
+			c.JsonApiErr(http.StatusTooManyRequests, util.Capitalize(err.Error()), err)
+		} else {
+			c.JsonApiErr(http.StatusInternalServerError, "Failed to proxy data source request", err)
+		}
+		return
+	}
+	defer release()
+
 	proxy.HandleRequest()
 }
 
@@ -59,4 +59,7 @@ type CacheService interface {
 
 	// GetDatasourceByUID gets a datasource identified by datasource unique identifier (UID).
 	GetDatasourceByUID(ctx context.Context, datasourceUID string, user *models.SignedInUser, skipCache bool) (*models.DataSource, error)
+
+	// Invalidate removes a datasource from the cache, so that it is retrieved from the database the next time.
+	Invalidate(ds *models.DataSource)
 }
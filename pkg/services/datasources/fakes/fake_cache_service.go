@@ -30,3 +30,5 @@ func (c *FakeCacheService) GetDatasourceByUID(ctx context.Context, datasourceUID
 	}
 	return nil, models.ErrDataSourceNotFound
 }
+
+func (c *FakeCacheService) Invalidate(ds *models.DataSource) {}
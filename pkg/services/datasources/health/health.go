@@ -0,0 +1,325 @@
+// Package health checks the health of the data sources in the background, keeps the history of the results and
+// exports them as metrics, so that broken credentials or unreachable data sources are noticed before dashboards
+// fail.
+package health
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	healthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "datasource_health_healthy",
+		Help:      "A gauge of the result of the last health check of a data source, 1 when it was healthy and 0 otherwise",
+	}, []string{"org_id", "datasource_uid", "datasource_type"})
+
+	lastCheck = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "datasource_health_last_check_timestamp_seconds",
+		Help:      "A gauge of the time of the last health check of a data source",
+	}, []string{"org_id", "datasource_uid", "datasource_type"})
+
+	checksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "datasource_health_checks_total",
+		Help:      "A counter of the background health checks of the data sources",
+	}, []string{"datasource_type", "status"})
+
+	checkDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "datasource_health_check_duration_seconds",
+		Help:      "Histogram of how long the background health checks of the data sources took",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"datasource_type"})
+)
+
+// Result is the result of a health check of a data source.
+type Result struct {
+	// Status is the status of the health check: OK, ERROR or UNKNOWN
+	Status     string    `json:"status"`
+	Message    string    `json:"message"`
+	DurationMs int64     `json:"durationMs"`
+	Checked    time.Time `json:"checked"`
+}
+
+// DataSourceHealth is the last health check result of a data source.
+type DataSourceHealth struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Result
+}
+
+// DataSourceHealthHistory is the health of a data source and the results of its recent health checks, most recent
+// first.
+type DataSourceHealthHistory struct {
+	UID    string `json:"uid"`
+	Status string `json:"status,omitempty"`
+	// Since is when the data source got its current status, as far as the history goes
+	Since   *time.Time `json:"since,omitempty"`
+	History []*Result  `json:"history"`
+}
+
+type Service struct {
+	cfg               setting.DataSourceHealthSettings
+	store             store
+	dataSourceService datasources.DataSourceService
+	pluginStore       plugins.Store
+	pluginClient      plugins.Client
+	requestValidator  models.PluginRequestValidator
+	lockService       *serverlock.ServerLockService
+	log               log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, ss *sqlstore.SQLStore, dataSourceService datasources.DataSourceService,
+	pluginStore plugins.Store, pluginClient plugins.Client, requestValidator models.PluginRequestValidator,
+	lockService *serverlock.ServerLockService) *Service {
+	return &Service{
+		cfg:               cfg.DataSourceHealth,
+		store:             &sqlStore{db: ss},
+		dataSourceService: dataSourceService,
+		pluginStore:       pluginStore,
+		pluginClient:      pluginClient,
+		requestValidator:  requestValidator,
+		lockService:       lockService,
+		log:               log.New("datasources.health"),
+	}
+}
+
+// IsDisabled returns true when the background health checks are disabled.
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+// Run checks the health of the data sources every interval. The intervals are jittered, and servers in HA setups
+// take a server lock so that a single server checks the data sources in each interval. Every server exports the
+// last results as metrics.
+func (s *Service) Run(ctx context.Context) error {
+	s.updateMetrics(ctx)
+
+	for {
+		// up to a tenth of the interval earlier or later
+		jitter := time.Duration(rand.Int63n(int64(s.cfg.Interval)/5)) - s.cfg.Interval/10
+		timer := time.NewTimer(s.cfg.Interval + jitter)
+
+		select {
+		case <-timer.C:
+			err := s.lockService.LockAndExecute(ctx, "check data sources health", s.cfg.Interval/2, func(ctx context.Context) {
+				s.checkAll(ctx, time.Now())
+			})
+			if err != nil {
+				s.log.Error("Failed to check the health of the data sources", "error", err)
+			}
+			s.updateMetrics(ctx)
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Health returns the last health check result of the data sources of an organization.
+func (s *Service) Health(ctx context.Context, orgID int64) ([]*DataSourceHealth, error) {
+	latest, err := s.store.Latest(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	health := make([]*DataSourceHealth, 0, len(latest))
+	for _, h := range latest {
+		health = append(health, h.DataSourceHealth)
+	}
+	return health, nil
+}
+
+// History returns the health check results of a data source.
+func (s *Service) History(ctx context.Context, orgID int64, uid string) (*DataSourceHealthHistory, error) {
+	results, err := s.store.History(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &DataSourceHealthHistory{UID: uid, History: results}
+	if len(results) > 0 {
+		history.Status = results[0].Status
+		since := results[0].Checked
+		for _, r := range results[1:] {
+			if r.Status != history.Status {
+				break
+			}
+			since = r.Checked
+		}
+		history.Since = &since
+	}
+	return history, nil
+}
+
+// checkAll checks the health of all the data sources, saves the results and removes the results older than the
+// history retention.
+func (s *Service) checkAll(ctx context.Context, now time.Time) {
+	dataSources, err := s.store.DataSources(ctx)
+	if err != nil {
+		s.log.Error("Failed to get the data sources to check", "error", err)
+		return
+	}
+
+	previous := make(map[dataSourceKey]string)
+	if latest, err := s.store.Latest(ctx, 0); err == nil {
+		for _, h := range latest {
+			previous[dataSourceKey{orgID: h.orgID, uid: h.UID}] = h.Status
+		}
+	} else {
+		s.log.Warn("Failed to get the previous health check results", "error", err)
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.cfg.MaxConcurrentChecks)
+	for _, ds := range dataSources {
+		ds := ds
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result := s.check(ctx, ds)
+			if result == nil {
+				return
+			}
+			if err := s.store.Save(ctx, ds.OrgId, ds.Uid, result); err != nil {
+				s.log.Error("Failed to save the health check result of a data source", "uid", ds.Uid, "orgId", ds.OrgId, "error", err)
+				return
+			}
+
+			prevStatus, checked := previous[dataSourceKey{orgID: ds.OrgId, uid: ds.Uid}]
+			switch {
+			case result.Status != backend.HealthStatusOk.String() && (!checked || prevStatus == backend.HealthStatusOk.String()):
+				s.log.Warn("Data source is unhealthy", "uid", ds.Uid, "name", ds.Name, "orgId", ds.OrgId, "message", result.Message)
+			case result.Status == backend.HealthStatusOk.String() && checked && prevStatus != result.Status:
+				s.log.Info("Data source is healthy again", "uid", ds.Uid, "name", ds.Name, "orgId", ds.OrgId)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := s.store.DeleteOlderThan(ctx, now.Add(-s.cfg.HistoryRetention)); err != nil {
+		s.log.Error("Failed to delete the old health check results", "error", err)
+	}
+}
+
+// check runs the health check of a data source as the "Save & test" button does, without a signed in user. Data
+// sources without backend or forwarding the OAuth identity of users are not checked, nil is returned for them.
+func (s *Service) check(ctx context.Context, ds *models.DataSource) *Result {
+	if ds.JsonData != nil && ds.JsonData.Get("oauthPassThru").MustBool() {
+		return nil
+	}
+
+	start := time.Now()
+	result := &Result{Checked: start}
+	defer func() {
+		// data sources which turn out not to be checked are not counted
+		if result.Status == "" {
+			return
+		}
+		result.DurationMs = time.Since(start).Milliseconds()
+		checksTotal.WithLabelValues(ds.Type, result.Status).Inc()
+		checkDuration.WithLabelValues(ds.Type).Observe(time.Since(start).Seconds())
+	}()
+
+	plugin, exists := s.pluginStore.Plugin(ctx, ds.Type)
+	if !exists {
+		result.Status, result.Message = backend.HealthStatusError.String(), "Data source plugin not found"
+		return result
+	}
+	if !plugin.Backend {
+		return nil
+	}
+	if err := s.requestValidator.Validate(ds.Url, nil); err != nil {
+		result.Status, result.Message = backend.HealthStatusError.String(), err.Error()
+		return result
+	}
+
+	settings, err := adapters.ModelToInstanceSettings(ds, func(ds *models.DataSource) map[string]string {
+		decrypted, err := s.dataSourceService.DecryptedValues(ctx, ds)
+		if err != nil {
+			s.log.Error("Failed to decrypt secure json data", "uid", ds.Uid, "error", err)
+		}
+		return decrypted
+	})
+	if err != nil {
+		result.Status, result.Message = backend.HealthStatusError.String(), err.Error()
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	resp, err := s.pluginClient.CheckHealth(ctx, &backend.CheckHealthRequest{
+		PluginContext: backend.PluginContext{
+			OrgID:                      ds.OrgId,
+			PluginID:                   plugin.ID,
+			DataSourceInstanceSettings: settings,
+		},
+		Headers: map[string]string{},
+	})
+	switch {
+	case errors.Is(err, backendplugin.ErrMethodNotImplemented):
+		return nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Status, result.Message = backend.HealthStatusError.String(), "Health check timed out after "+s.cfg.Timeout.String()
+	case err != nil:
+		result.Status, result.Message = backend.HealthStatusError.String(), err.Error()
+	default:
+		result.Status, result.Message = resp.Status.String(), resp.Message
+	}
+	return result
+}
+
+// updateMetrics exports the last health check results of the data sources, the data sources deleted since the
+// previous update are not exported anymore.
+func (s *Service) updateMetrics(ctx context.Context) {
+	latest, err := s.store.Latest(ctx, 0)
+	if err != nil {
+		s.log.Error("Failed to get the health check results", "error", err)
+		return
+	}
+
+	healthy.Reset()
+	lastCheck.Reset()
+	for _, h := range latest {
+		labels := []string{strconv.FormatInt(h.orgID, 10), h.UID, h.Type}
+		value := 0.0
+		if h.Status == backend.HealthStatusOk.String() {
+			value = 1
+		}
+		healthy.WithLabelValues(labels...).Set(value)
+		lastCheck.WithLabelValues(labels...).Set(float64(h.Checked.Unix()))
+	}
+}
+
+type dataSourceKey struct {
+	orgID int64
+	uid   string
+}
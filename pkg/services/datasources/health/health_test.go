@@ -0,0 +1,147 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/validations"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakePluginStore struct {
+	plugins.Store
+}
+
+func (fakePluginStore) Plugin(_ context.Context, pluginID string) (plugins.PluginDTO, bool) {
+	switch pluginID {
+	case "prometheus", "loki", "elasticsearch":
+		return plugins.PluginDTO{JSONData: plugins.JSONData{ID: pluginID, Backend: true}}, true
+	case "frontend":
+		return plugins.PluginDTO{JSONData: plugins.JSONData{ID: pluginID}}, true
+	}
+	return plugins.PluginDTO{}, false
+}
+
+type fakePluginClient struct {
+	plugins.Client
+	responses map[string]*backend.CheckHealthResult
+	errors    map[string]error
+	checked   []string
+}
+
+func (c *fakePluginClient) CheckHealth(_ context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	uid := req.PluginContext.DataSourceInstanceSettings.UID
+	c.checked = append(c.checked, uid)
+	return c.responses[uid], c.errors[uid]
+}
+
+func TestIntegrationDataSourceHealth(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	ss := sqlstore.InitTestDB(t)
+	for _, ds := range []models.AddDataSourceCommand{
+		{OrgId: 1, Uid: "prom", Name: "Prometheus", Type: "prometheus"},
+		{OrgId: 1, Uid: "loki", Name: "Loki", Type: "loki"},
+		{OrgId: 1, Uid: "elastic", Name: "Elasticsearch", Type: "elasticsearch"},
+		{OrgId: 1, Uid: "frontend", Name: "Frontend", Type: "frontend"},
+		{OrgId: 1, Uid: "oauth", Name: "OAuth", Type: "prometheus", JsonData: simplejson.NewFromAny(map[string]interface{}{"oauthPassThru": true})},
+		{OrgId: 2, Uid: "missing", Name: "Missing plugin", Type: "missing"},
+	} {
+		ds := ds
+		require.NoError(t, ss.AddDataSource(ctx, &ds))
+	}
+
+	cfg := setting.NewCfg()
+	cfg.DataSourceHealth = setting.DataSourceHealthSettings{Enabled: true, Interval: time.Minute, Timeout: time.Second,
+		HistoryRetention: time.Hour, MaxConcurrentChecks: 1}
+	client := &fakePluginClient{
+		responses: map[string]*backend.CheckHealthResult{
+			"prom": {Status: backend.HealthStatusOk, Message: "Data source is working"},
+			"loki": {Status: backend.HealthStatusError, Message: "Unauthorized"},
+		},
+		errors: map[string]error{"elastic": backendplugin.ErrMethodNotImplemented},
+	}
+	s := ProvideService(cfg, ss, &fakeDatasources.FakeDataSourceService{}, fakePluginStore{}, client,
+		validations.ProvideValidator(), serverlock.ProvideService(ss))
+
+	now := time.Now()
+	s.checkAll(ctx, now)
+	require.ElementsMatch(t, []string{"prom", "loki", "elastic"}, client.checked)
+
+	t.Run("The last results of the data sources of an organization should be returned", func(t *testing.T) {
+		health, err := s.Health(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, health, 2)
+		require.Equal(t, "Loki", health[0].Name)
+		require.Equal(t, "ERROR", health[0].Status)
+		require.Equal(t, "Unauthorized", health[0].Message)
+		require.Equal(t, "Prometheus", health[1].Name)
+		require.Equal(t, "OK", health[1].Status)
+
+		health, err = s.Health(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, health, 1)
+		require.Equal(t, "ERROR", health[0].Status)
+		require.Equal(t, "Data source plugin not found", health[0].Message)
+	})
+
+	t.Run("The history should tell since when a data source has its status", func(t *testing.T) {
+		client.responses["loki"] = &backend.CheckHealthResult{Status: backend.HealthStatusOk}
+		s.checkAll(ctx, now)
+		client.responses["loki"] = nil
+		client.errors["loki"] = errors.New("connection refused")
+		s.checkAll(ctx, now)
+
+		history, err := s.History(ctx, 1, "loki")
+		require.NoError(t, err)
+		require.Equal(t, "ERROR", history.Status)
+		require.Len(t, history.History, 3)
+		require.Equal(t, "connection refused", history.History[0].Message)
+		require.Equal(t, history.History[0].Checked, *history.Since)
+
+		history, err = s.History(ctx, 1, "prom")
+		require.NoError(t, err)
+		require.Equal(t, "OK", history.Status)
+		require.Equal(t, history.History[2].Checked, *history.Since)
+	})
+
+	t.Run("The last results should be exported as metrics", func(t *testing.T) {
+		s.updateMetrics(ctx)
+		require.Equal(t, 1.0, testutil.ToFloat64(healthy.WithLabelValues("1", "prom", "prometheus")))
+		require.Equal(t, 0.0, testutil.ToFloat64(healthy.WithLabelValues("1", "loki", "loki")))
+	})
+
+	t.Run("The results older than the history retention should be removed", func(t *testing.T) {
+		s.checkAll(ctx, now.Add(2*time.Hour))
+		history, err := s.History(ctx, 1, "prom")
+		require.NoError(t, err)
+		require.Empty(t, history.History)
+		require.Nil(t, history.Since)
+	})
+}
+
+func TestTruncateMessage(t *testing.T) {
+	require.Equal(t, "connection refused", truncateMessage("connection refused"))
+
+	message := truncateMessage(strings.Repeat("a", maxMessageLength-1) + "é")
+	require.Equal(t, strings.Repeat("a", maxMessageLength-1), message)
+	require.True(t, utf8.ValidString(message))
+}
@@ -0,0 +1,140 @@
+package health
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+// maxMessageLength is the length health check messages are truncated to
+const maxMessageLength = 1000
+
+type store interface {
+	// DataSources returns the data sources of all the organizations.
+	DataSources(ctx context.Context) ([]*models.DataSource, error)
+	Save(ctx context.Context, orgID int64, uid string, result *Result) error
+	// Latest returns the last result of each data source of an organization, or of all the organizations when
+	// orgID is 0.
+	Latest(ctx context.Context, orgID int64) ([]*latestResult, error)
+	// History returns the results of a data source, most recent first.
+	History(ctx context.Context, orgID int64, uid string) ([]*Result, error)
+	DeleteOlderThan(ctx context.Context, t time.Time) error
+}
+
+type latestResult struct {
+	*DataSourceHealth
+	orgID int64
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+type resultRow struct {
+	ID            int64  `xorm:"id"`
+	OrgID         int64  `xorm:"org_id"`
+	DatasourceUID string `xorm:"datasource_uid"`
+	Name          string `xorm:"name"`
+	Type          string `xorm:"type"`
+	Status        string `xorm:"status"`
+	Message       string `xorm:"message"`
+	DurationMs    int64  `xorm:"duration_ms"`
+	Checked       int64  `xorm:"checked"`
+}
+
+func (r *resultRow) result() *Result {
+	return &Result{
+		Status:     r.Status,
+		Message:    r.Message,
+		DurationMs: r.DurationMs,
+		Checked:    time.Unix(r.Checked, 0),
+	}
+}
+
+func (s *sqlStore) DataSources(ctx context.Context) ([]*models.DataSource, error) {
+	dataSources := make([]*models.DataSource, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("data_source").OrderBy("org_id, id").Find(&dataSources)
+	})
+	return dataSources, err
+}
+
+func (s *sqlStore) Save(ctx context.Context, orgID int64, uid string, result *Result) error {
+	message := truncateMessage(result.Message)
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec(`INSERT INTO data_source_health (org_id, datasource_uid, status, message, duration_ms, checked)
+			VALUES (?, ?, ?, ?, ?, ?)`, orgID, uid, result.Status, message, result.DurationMs, result.Checked.Unix())
+		return err
+	})
+}
+
+// truncateMessage cuts the message to maxMessageLength bytes without splitting a multi-byte character.
+func truncateMessage(message string) string {
+	if len(message) <= maxMessageLength {
+		return message
+	}
+	end := maxMessageLength
+	for end > 0 && !utf8.RuneStart(message[end]) {
+		end--
+	}
+	return message[:end]
+}
+
+func (s *sqlStore) Latest(ctx context.Context, orgID int64) ([]*latestResult, error) {
+	rows := make([]*resultRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		sql := `SELECT h.id, h.org_id, h.datasource_uid, ds.name, ds.type, h.status, h.message, h.duration_ms, h.checked
+			FROM data_source_health h
+			INNER JOIN data_source ds ON ds.org_id = h.org_id AND ds.uid = h.datasource_uid
+			WHERE h.id IN (SELECT MAX(id) FROM data_source_health GROUP BY org_id, datasource_uid)`
+		args := []interface{}{}
+		if orgID != 0 {
+			sql += ` AND h.org_id = ?`
+			args = append(args, orgID)
+		}
+		return sess.SQL(sql+` ORDER BY ds.name ASC`, args...).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*latestResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, &latestResult{
+			DataSourceHealth: &DataSourceHealth{UID: row.DatasourceUID, Name: row.Name, Type: row.Type, Result: *row.result()},
+			orgID:            row.OrgID,
+		})
+	}
+	return results, nil
+}
+
+func (s *sqlStore) History(ctx context.Context, orgID int64, uid string) ([]*Result, error) {
+	rows := make([]*resultRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("data_source_health").
+			Where("org_id = ? AND datasource_uid = ?", orgID, uid).
+			Cols("status", "message", "duration_ms", "checked").
+			OrderBy("id DESC").
+			Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*Result, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, row.result())
+	}
+	return results, nil
+}
+
+func (s *sqlStore) DeleteOlderThan(ctx context.Context, t time.Time) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec(`DELETE FROM data_source_health WHERE checked < ?`, t.Unix())
+		return err
+	})
+}
@@ -0,0 +1,422 @@
+// Package limiter limits the requests made to each data source by the query service and the data source proxy.
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Keys of the JSON data of a data source overriding the default limits.
+const (
+	MaxConcurrentQueriesKey = "limitMaxConcurrentQueries"
+	MaxUserShareKey         = "limitMaxUserShare"
+	RequestsPerSecondKey    = "limitRequestsPerSecond"
+	// QueueTimeoutKey is in seconds
+	QueueTimeoutKey = "limitQueueTimeout"
+)
+
+// Reasons of the rejection of a request.
+const (
+	ReasonConcurrency = "concurrency"
+	ReasonUserShare   = "user_share"
+	ReasonRate        = "rate"
+)
+
+// limiterIdleTimeout is how long the limiter of a data source without requests is kept
+const limiterIdleTimeout = 10 * time.Minute
+
+// ErrLimitReached is returned when a request to a data source is rejected by the limits of the data source.
+var ErrLimitReached = errors.New("data source request limit reached")
+
+var (
+	activeRequests = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "datasource_limit_active_requests",
+		Help:      "A gauge of the requests to a data source let through by its limits and not completed yet",
+	}, []string{"org_id", "datasource_uid"})
+
+	queuedRequests = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "datasource_limit_queued_requests",
+		Help:      "A gauge of the requests waiting for the limits of a data source",
+	}, []string{"org_id", "datasource_uid"})
+
+	rejectedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "datasource_limit_rejected_requests_total",
+		Help:      "A counter of the requests to a data source rejected by its limits",
+	}, []string{"org_id", "datasource_uid", "reason"})
+
+	queueDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "datasource_limit_queue_duration_seconds",
+		Help:      "Histogram of how long the requests to a data source waited for its limits",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"org_id", "datasource_uid"})
+)
+
+// LimitError is returned when a request to a data source is rejected by the limits of the data source.
+type LimitError struct {
+	DataSourceName string
+	Reason         string
+	Limit          string
+}
+
+func (e *LimitError) Error() string {
+	switch e.Reason {
+	case ReasonUserShare:
+		return fmt.Sprintf("too many concurrent queries to data source %s by the user, the limit is %s", e.DataSourceName, e.Limit)
+	case ReasonRate:
+		return fmt.Sprintf("too many requests to data source %s, the limit is %s requests per second", e.DataSourceName, e.Limit)
+	default:
+		return fmt.Sprintf("too many concurrent queries to data source %s, the limit is %s", e.DataSourceName, e.Limit)
+	}
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitReached
+}
+
+// Limits are the limits of the requests to a data source.
+type Limits struct {
+	// MaxConcurrentQueries is the number of concurrent requests to the data source, 0 is unlimited
+	MaxConcurrentQueries int
+	// MaxUserShare is the share of MaxConcurrentQueries a single user can take, 0 is unlimited
+	MaxUserShare float64
+	// RequestsPerSecond is the rate of requests to the data source, 0 is unlimited
+	RequestsPerSecond float64
+	// QueueTimeout is how long a request waits for the limits before being rejected, 0 rejects it right away
+	QueueTimeout time.Duration
+}
+
+func (l Limits) unlimited() bool {
+	return l.MaxConcurrentQueries <= 0 && l.RequestsPerSecond <= 0
+}
+
+// maxUserQueries returns the number of concurrent requests a single user can make, 0 is unlimited.
+func (l Limits) maxUserQueries() int {
+	if l.MaxConcurrentQueries <= 0 || l.MaxUserShare <= 0 || l.MaxUserShare >= 1 {
+		return 0
+	}
+	return int(math.Max(1, math.Floor(l.MaxUserShare*float64(l.MaxConcurrentQueries))))
+}
+
+type Service struct {
+	defaults setting.DataSourceLimitsSettings
+	log      log.Logger
+
+	mu       sync.Mutex
+	limiters map[limiterKey]*dataSourceLimiter
+	// lastEviction is when the idle limiters were last evicted
+	lastEviction time.Time
+}
+
+type limiterKey struct {
+	orgID int64
+	uid   string
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	return &Service{
+		defaults: cfg.DataSourceLimits,
+		log:      log.New("datasources.limiter"),
+		limiters: make(map[limiterKey]*dataSourceLimiter),
+	}
+}
+
+// Acquire waits for the limits of a data source to let a request of the user through, for at most the queue
+// timeout of the data source. The returned function must be called once the request completes. A *LimitError
+// wrapping ErrLimitReached is returned when the request is rejected.
+func (s *Service) Acquire(ctx context.Context, ds *models.DataSource, user *models.SignedInUser) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+
+	limits := s.LimitsFor(ds)
+	if limits.unlimited() {
+		return func() {}, nil
+	}
+
+	var userID int64
+	if user != nil {
+		userID = user.UserId
+	}
+	return s.limiter(ds, limits).acquire(ctx, userID)
+}
+
+// LimitsFor returns the limits of a data source, the default limits overridden by the JSON data of the data source.
+func (s *Service) LimitsFor(ds *models.DataSource) Limits {
+	limits := Limits{
+		MaxConcurrentQueries: s.defaults.MaxConcurrentQueries,
+		MaxUserShare:         s.defaults.MaxUserShare,
+		RequestsPerSecond:    s.defaults.RequestsPerSecond,
+		QueueTimeout:         s.defaults.QueueTimeout,
+	}
+	if ds.JsonData == nil {
+		return limits
+	}
+
+	if v, ok := ds.JsonData.CheckGet(MaxConcurrentQueriesKey); ok {
+		limits.MaxConcurrentQueries = v.MustInt(limits.MaxConcurrentQueries)
+	}
+	if v, ok := ds.JsonData.CheckGet(MaxUserShareKey); ok {
+		limits.MaxUserShare = v.MustFloat64(limits.MaxUserShare)
+	}
+	if v, ok := ds.JsonData.CheckGet(RequestsPerSecondKey); ok {
+		limits.RequestsPerSecond = v.MustFloat64(limits.RequestsPerSecond)
+	}
+	if v, ok := ds.JsonData.CheckGet(QueueTimeoutKey); ok {
+		limits.QueueTimeout = time.Duration(v.MustFloat64(limits.QueueTimeout.Seconds()) * float64(time.Second))
+	}
+	return limits
+}
+
+// limiter returns the limiter of a data source, which is updated when the limits or the name of the data source
+// change. The limiters of the data sources without requests for limiterIdleTimeout are evicted.
+func (s *Service) limiter(ds *models.DataSource, limits Limits) *dataSourceLimiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastEviction) >= limiterIdleTimeout {
+		for key, l := range s.limiters {
+			if l.idleSince(now) >= limiterIdleTimeout {
+				delete(s.limiters, key)
+			}
+		}
+		s.lastEviction = now
+	}
+
+	key := limiterKey{orgID: ds.OrgId, uid: ds.Uid}
+	l, exists := s.limiters[key]
+	if !exists {
+		l = newDataSourceLimiter(ds.OrgId, ds.Uid, ds.Name, limits)
+		s.limiters[key] = l
+	} else if l.update(ds.Name, limits) {
+		s.log.Debug("Data source limits changed", "uid", ds.Uid, "limits", limits)
+	}
+	l.touch(now)
+	return l
+}
+
+// dataSourceLimiter enforces the limits of a data source.
+type dataSourceLimiter struct {
+	// org is the organization of the data source as a metric label
+	org string
+	uid string
+
+	mu     sync.Mutex
+	name   string
+	limits Limits
+	rate   *rate.Limiter
+	active int
+	users  map[int64]int
+	// queued is the number of requests waiting for the limits
+	queued   int
+	lastUsed time.Time
+	// changed is closed and replaced whenever a request completes or the limits change
+	changed chan struct{}
+}
+
+func newDataSourceLimiter(orgID int64, uid, name string, limits Limits) *dataSourceLimiter {
+	l := &dataSourceLimiter{
+		org:     strconv.FormatInt(orgID, 10),
+		uid:     uid,
+		name:    name,
+		limits:  limits,
+		users:   make(map[int64]int),
+		changed: make(chan struct{}),
+	}
+	l.rate = newRateLimiter(limits)
+	return l
+}
+
+func newRateLimiter(limits Limits) *rate.Limiter {
+	if limits.RequestsPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(limits.RequestsPerSecond), int(math.Max(1, math.Ceil(limits.RequestsPerSecond))))
+}
+
+// update changes the name and the limits of the data source, keeping the requests let through, and returns true
+// when the limits changed. The queued requests are checked again against the new limits.
+func (l *dataSourceLimiter) update(name string, limits Limits) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.name = name
+	if l.limits == limits {
+		return false
+	}
+	if l.limits.RequestsPerSecond != limits.RequestsPerSecond {
+		l.rate = newRateLimiter(limits)
+	}
+	l.limits = limits
+	l.notify()
+	return true
+}
+
+func (l *dataSourceLimiter) touch(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastUsed = now
+}
+
+// idleSince returns how long the limiter has had no requests, 0 when requests are active or queued.
+func (l *dataSourceLimiter) idleSince(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active > 0 || l.queued > 0 {
+		return 0
+	}
+	return now.Sub(l.lastUsed)
+}
+
+// notify wakes up the queued requests, l.mu must be held.
+func (l *dataSourceLimiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// acquire lets a request through the concurrency limits first, so that a rejected request doesn't take a token
+// of the rate limit, then through the rate limit.
+func (l *dataSourceLimiter) acquire(ctx context.Context, userID int64) (func(), error) {
+	l.mu.Lock()
+	queueTimeout := l.limits.QueueTimeout
+	l.queued++
+	l.mu.Unlock()
+
+	queueCtx := ctx
+	if queueTimeout > 0 {
+		var cancel context.CancelFunc
+		queueCtx, cancel = context.WithTimeout(ctx, queueTimeout)
+		defer cancel()
+	}
+
+	// the queue metrics only count the requests which had to wait for the limits
+	var queuedAt time.Time
+	wait := func() {
+		if queuedAt.IsZero() {
+			queuedAt = time.Now()
+			queuedRequests.WithLabelValues(l.org, l.uid).Inc()
+		}
+	}
+	defer func() {
+		l.mu.Lock()
+		l.queued--
+		l.lastUsed = time.Now()
+		l.mu.Unlock()
+		if !queuedAt.IsZero() {
+			queuedRequests.WithLabelValues(l.org, l.uid).Dec()
+			queueDuration.WithLabelValues(l.org, l.uid).Observe(time.Since(queuedAt).Seconds())
+		}
+	}()
+
+	var rl *rate.Limiter
+	for {
+		l.mu.Lock()
+		reason := l.blocked(userID)
+		if reason == "" {
+			l.active++
+			l.users[userID]++
+			rl = l.rate
+			l.mu.Unlock()
+			activeRequests.WithLabelValues(l.org, l.uid).Inc()
+			break
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		if queueTimeout <= 0 {
+			return nil, l.reject(ctx, reason)
+		}
+		wait()
+		select {
+		case <-changed:
+		case <-queueCtx.Done():
+			return nil, l.reject(ctx, reason)
+		}
+	}
+
+	release := l.releaseFunc(userID)
+	if rl != nil && !rl.Allow() {
+		if queueTimeout <= 0 {
+			release()
+			return nil, l.reject(ctx, ReasonRate)
+		}
+		wait()
+		if err := rl.Wait(queueCtx); err != nil {
+			release()
+			return nil, l.reject(ctx, ReasonRate)
+		}
+	}
+	return release, nil
+}
+
+// blocked returns the reason a request of the user cannot be let through yet, or an empty string.
+func (l *dataSourceLimiter) blocked(userID int64) string {
+	if l.limits.MaxConcurrentQueries <= 0 {
+		return ""
+	}
+	if maxUser := l.limits.maxUserQueries(); maxUser > 0 && l.users[userID] >= maxUser {
+		return ReasonUserShare
+	}
+	if l.active >= l.limits.MaxConcurrentQueries {
+		return ReasonConcurrency
+	}
+	return ""
+}
+
+func (l *dataSourceLimiter) releaseFunc(userID int64) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			l.active--
+			if l.users[userID]--; l.users[userID] <= 0 {
+				delete(l.users, userID)
+			}
+			l.lastUsed = time.Now()
+			l.notify()
+			activeRequests.WithLabelValues(l.org, l.uid).Dec()
+		})
+	}
+}
+
+// reject returns the error of a rejected request, or the error of ctx when the request was canceled.
+func (l *dataSourceLimiter) reject(ctx context.Context, reason string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rejectedRequests.WithLabelValues(l.org, l.uid, reason).Inc()
+	err := &LimitError{DataSourceName: l.name, Reason: reason}
+	switch reason {
+	case ReasonUserShare:
+		err.Limit = fmt.Sprint(l.limits.maxUserQueries())
+	case ReasonRate:
+		err.Limit = fmt.Sprint(l.limits.RequestsPerSecond)
+	default:
+		err.Limit = fmt.Sprint(l.limits.MaxConcurrentQueries)
+	}
+	return err
+}
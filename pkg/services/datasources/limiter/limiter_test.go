@@ -0,0 +1,248 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_LimitsFor(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DataSourceLimits = setting.DataSourceLimitsSettings{
+		MaxConcurrentQueries: 10,
+		MaxUserShare:         0.5,
+		QueueTimeout:         30 * time.Second,
+	}
+	s := ProvideService(cfg)
+
+	t.Run("Data sources without limits in their JSON data have the default limits", func(t *testing.T) {
+		limits := s.LimitsFor(&models.DataSource{})
+		require.Equal(t, Limits{MaxConcurrentQueries: 10, MaxUserShare: 0.5, QueueTimeout: 30 * time.Second}, limits)
+	})
+
+	t.Run("Limits in the JSON data of data sources override the default limits", func(t *testing.T) {
+		limits := s.LimitsFor(&models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{
+			MaxConcurrentQueriesKey: 0,
+			RequestsPerSecondKey:    2.5,
+			QueueTimeoutKey:         5,
+		})})
+		require.Equal(t, Limits{MaxConcurrentQueries: 0, MaxUserShare: 0.5, RequestsPerSecond: 2.5, QueueTimeout: 5 * time.Second}, limits)
+	})
+}
+
+func TestService_Acquire(t *testing.T) {
+	setup := func(t *testing.T, limits setting.DataSourceLimitsSettings) *Service {
+		t.Helper()
+
+		cfg := setting.NewCfg()
+		cfg.DataSourceLimits = limits
+		return ProvideService(cfg)
+	}
+
+	ds := &models.DataSource{OrgId: 1, Uid: "es", Name: "Elasticsearch"}
+	user := func(id int64) *models.SignedInUser {
+		return &models.SignedInUser{UserId: id}
+	}
+
+	t.Run("Queued requests are let through once a request completes", func(t *testing.T) {
+		s := setup(t, setting.DataSourceLimitsSettings{MaxConcurrentQueries: 1, QueueTimeout: time.Second})
+
+		release, err := s.Acquire(context.Background(), ds, user(1))
+		require.NoError(t, err)
+
+		acquired := make(chan error)
+		go func() {
+			release, err := s.Acquire(context.Background(), ds, user(2))
+			if err == nil {
+				release()
+			}
+			acquired <- err
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("request let through above the concurrency limit")
+		case <-time.After(50 * time.Millisecond):
+		}
+		release()
+		require.NoError(t, <-acquired)
+	})
+
+	t.Run("Only the requests waiting for the limits are reported as queued", func(t *testing.T) {
+		s := setup(t, setting.DataSourceLimitsSettings{MaxConcurrentQueries: 1, QueueTimeout: time.Second})
+		ds := &models.DataSource{OrgId: 2, Uid: "queued", Name: "Queued"}
+		observed := testutil.CollectAndCount(queueDuration)
+
+		release, err := s.Acquire(context.Background(), ds, user(1))
+		require.NoError(t, err)
+		require.Equal(t, observed, testutil.CollectAndCount(queueDuration))
+
+		acquired := make(chan error)
+		go func() {
+			release, err := s.Acquire(context.Background(), ds, user(2))
+			if err == nil {
+				release()
+			}
+			acquired <- err
+		}()
+
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(queuedRequests.WithLabelValues("2", "queued")) == 1
+		}, time.Second, 10*time.Millisecond)
+		release()
+		require.NoError(t, <-acquired)
+		require.Equal(t, 0.0, testutil.ToFloat64(queuedRequests.WithLabelValues("2", "queued")))
+		require.Equal(t, observed+1, testutil.CollectAndCount(queueDuration))
+	})
+
+	t.Run("Queued requests are rejected after the queue timeout", func(t *testing.T) {
+		s := setup(t, setting.DataSourceLimitsSettings{MaxConcurrentQueries: 1, QueueTimeout: 50 * time.Millisecond})
+
+		release, err := s.Acquire(context.Background(), ds, user(1))
+		require.NoError(t, err)
+		defer release()
+
+		_, err = s.Acquire(context.Background(), ds, user(1))
+		require.ErrorIs(t, err, ErrLimitReached)
+		var limitErr *LimitError
+		require.True(t, errors.As(err, &limitErr))
+		require.Equal(t, ReasonConcurrency, limitErr.Reason)
+		require.Equal(t, "too many concurrent queries to data source Elasticsearch, the limit is 1", err.Error())
+	})
+
+	t.Run("Users cannot take more than their share of the concurrent requests", func(t *testing.T) {
+		s := setup(t, setting.DataSourceLimitsSettings{MaxConcurrentQueries: 4, MaxUserShare: 0.5})
+
+		for i := 0; i < 2; i++ {
+			_, err := s.Acquire(context.Background(), ds, user(1))
+			require.NoError(t, err)
+		}
+
+		_, err := s.Acquire(context.Background(), ds, user(1))
+		var limitErr *LimitError
+		require.True(t, errors.As(err, &limitErr))
+		require.Equal(t, ReasonUserShare, limitErr.Reason)
+
+		release, err := s.Acquire(context.Background(), ds, user(2))
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("Requests above the rate are rejected", func(t *testing.T) {
+		s := setup(t, setting.DataSourceLimitsSettings{RequestsPerSecond: 1})
+
+		release, err := s.Acquire(context.Background(), ds, user(1))
+		require.NoError(t, err)
+		release()
+
+		_, err = s.Acquire(context.Background(), ds, user(1))
+		var limitErr *LimitError
+		require.True(t, errors.As(err, &limitErr))
+		require.Equal(t, ReasonRate, limitErr.Reason)
+	})
+
+	t.Run("Canceled requests return the error of their context", func(t *testing.T) {
+		s := setup(t, setting.DataSourceLimitsSettings{MaxConcurrentQueries: 1, QueueTimeout: time.Minute})
+
+		release, err := s.Acquire(context.Background(), ds, user(1))
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = s.Acquire(ctx, ds, user(1))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Changed limits apply to the next requests", func(t *testing.T) {
+		s := setup(t, setting.DataSourceLimitsSettings{MaxConcurrentQueries: 1})
+
+		release, err := s.Acquire(context.Background(), ds, user(1))
+		require.NoError(t, err)
+		defer release()
+
+		updated := *ds
+		updated.JsonData = simplejson.NewFromAny(map[string]interface{}{MaxConcurrentQueriesKey: 2})
+		release, err = s.Acquire(context.Background(), &updated, user(1))
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("Requests rejected by the concurrency limit don't take a token of the rate limit", func(t *testing.T) {
+		s := setup(t, setting.DataSourceLimitsSettings{MaxConcurrentQueries: 1, RequestsPerSecond: 2})
+
+		release, err := s.Acquire(context.Background(), ds, user(1))
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			_, err = s.Acquire(context.Background(), ds, user(2))
+			var limitErr *LimitError
+			require.True(t, errors.As(err, &limitErr))
+			require.Equal(t, ReasonConcurrency, limitErr.Reason)
+		}
+		release()
+
+		release, err = s.Acquire(context.Background(), ds, user(2))
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("Requests let through still count once the limits change", func(t *testing.T) {
+		s := setup(t, setting.DataSourceLimitsSettings{MaxConcurrentQueries: 1})
+
+		release, err := s.Acquire(context.Background(), ds, user(1))
+		require.NoError(t, err)
+
+		updated := *ds
+		updated.JsonData = simplejson.NewFromAny(map[string]interface{}{RequestsPerSecondKey: 100})
+		_, err = s.Acquire(context.Background(), &updated, user(2))
+		require.True(t, errors.Is(err, ErrLimitReached))
+
+		release()
+		release, err = s.Acquire(context.Background(), &updated, user(2))
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("Limiters of idle data sources are evicted", func(t *testing.T) {
+		s := setup(t, setting.DataSourceLimitsSettings{MaxConcurrentQueries: 1})
+
+		release, err := s.Acquire(context.Background(), ds, user(1))
+		require.NoError(t, err)
+		idle := &models.DataSource{OrgId: 1, Uid: "loki", Name: "Loki"}
+		idleRelease, err := s.Acquire(context.Background(), idle, user(1))
+		require.NoError(t, err)
+		idleRelease()
+
+		for _, l := range s.limiters {
+			l.lastUsed = time.Now().Add(-2 * limiterIdleTimeout)
+		}
+		s.lastEviction = time.Time{}
+		_, err = s.Acquire(context.Background(), &models.DataSource{OrgId: 1, Uid: "prometheus", Name: "Prometheus"}, user(1))
+		require.NoError(t, err)
+
+		require.Contains(t, s.limiters, limiterKey{orgID: 1, uid: "es"})
+		require.NotContains(t, s.limiters, limiterKey{orgID: 1, uid: "loki"})
+		release()
+	})
+
+	t.Run("Requests to data sources without limits are not limited", func(t *testing.T) {
+		var s *Service
+		release, err := s.Acquire(context.Background(), ds, nil)
+		require.NoError(t, err)
+		release()
+
+		s = setup(t, setting.DataSourceLimitsSettings{})
+		for i := 0; i < 100; i++ {
+			_, err := s.Acquire(context.Background(), ds, nil)
+			require.NoError(t, err)
+		}
+	})
+}
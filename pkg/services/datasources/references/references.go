@@ -0,0 +1,346 @@
+// Package references migrates the references to a data source to another data source or to a template variable,
+// across the dashboards, library panels, alert rules and query history of an org.
+package references
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+var (
+	ErrMissingSource      = errors.New("the data source to migrate from is required")
+	ErrMissingTarget      = errors.New("either the data source or the template variable to migrate to is required")
+	ErrConflictingTargets = errors.New("only one of the data source or the template variable to migrate to can be set")
+	ErrSameDataSource     = errors.New("the data source to migrate to is the data source to migrate from")
+	ErrInvalidVariable    = errors.New("the template variable to migrate to must only contain letters, digits and underscores")
+)
+
+// variableNamePattern matches the names of the template variables which can be referenced as ${name}
+var variableNamePattern = regexp.MustCompile(`^\w+$`)
+
+// MigrateCommand migrates the references to the data source From to the data source To, or to the template
+// variable ToVariable. Only the dashboards with a data source variable of that name listing the data sources of the
+// same type are migrated to a template variable, the others are reported with an error. Alert rules and query
+// history cannot reference template variables, they are left as is when migrating to a template variable.
+type MigrateCommand struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	ToVariable string `json:"toVariable"`
+	// DryRun reports what would change without changing anything
+	DryRun bool `json:"dryRun"`
+
+	OrgID int64                `json:"-"`
+	User  *models.SignedInUser `json:"-"`
+}
+
+func (cmd *MigrateCommand) Validate() error {
+	switch {
+	case cmd.From == "":
+		return ErrMissingSource
+	case cmd.To == "" && cmd.ToVariable == "":
+		return ErrMissingTarget
+	case cmd.To != "" && cmd.ToVariable != "":
+		return ErrConflictingTargets
+	case cmd.To == cmd.From:
+		return ErrSameDataSource
+	case cmd.ToVariable != "" && !variableNamePattern.MatchString(cmd.ToVariable):
+		return ErrInvalidVariable
+	}
+	return nil
+}
+
+// MigrateResult is what changed, or would change in a dry run.
+type MigrateResult struct {
+	DryRun        bool                `json:"dryRun"`
+	Dashboards    []*MigratedResource `json:"dashboards"`
+	LibraryPanels []*MigratedResource `json:"libraryPanels"`
+	AlertRules    []*MigratedResource `json:"alertRules"`
+	// QueryHistory is the number of query history entries of the data source
+	QueryHistory int64 `json:"queryHistory"`
+}
+
+// MigratedResource is a resource with references to the migrated data source.
+type MigratedResource struct {
+	UID        string `json:"uid"`
+	Title      string `json:"title"`
+	References int    `json:"references"`
+	// Error is set when the resource could not be migrated, the other resources are migrated anyway
+	Error string `json:"error,omitempty"`
+}
+
+type Service struct {
+	store             store
+	dashboardService  dashboards.DashboardService
+	libraryElements   libraryelements.Service
+	ruleStore         ngstore.RuleStore
+	dataSourceService datasources.DataSourceService
+	log               log.Logger
+}
+
+func ProvideService(ss *sqlstore.SQLStore, dashboardService dashboards.DashboardService, libraryElements libraryelements.Service,
+	ruleStore ngstore.RuleStore, dataSourceService datasources.DataSourceService) *Service {
+	return &Service{
+		store:             &sqlStore{db: ss},
+		dashboardService:  dashboardService,
+		libraryElements:   libraryElements,
+		ruleStore:         ruleStore,
+		dataSourceService: dataSourceService,
+		log:               log.New("datasources.references"),
+	}
+}
+
+// Migrate rewrites the references to a data source. Each dashboard and library panel is saved as a new version,
+// the resources which cannot be saved are reported with their error in the result.
+func (s *Service) Migrate(ctx context.Context, cmd *MigrateCommand) (*MigrateResult, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	from, err := s.dataSource(ctx, cmd.OrgID, cmd.From)
+	if err != nil {
+		return nil, err
+	}
+	to := newRef(from.Type, "${"+cmd.ToVariable+"}")
+	if cmd.To != "" {
+		ds, err := s.dataSource(ctx, cmd.OrgID, cmd.To)
+		if err != nil {
+			return nil, err
+		}
+		to = newRef(ds.Type, ds.Uid)
+	}
+	r := &rewriter{from: from, to: to}
+
+	result := &MigrateResult{DryRun: cmd.DryRun}
+	if result.Dashboards, err = s.migrateDashboards(ctx, cmd, r); err != nil {
+		return nil, err
+	}
+	if result.LibraryPanels, err = s.migrateLibraryPanels(ctx, cmd, r); err != nil {
+		return nil, err
+	}
+	result.AlertRules = make([]*MigratedResource, 0)
+	if cmd.ToVariable == "" {
+		if result.AlertRules, err = s.migrateAlertRules(ctx, cmd, r); err != nil {
+			return nil, err
+		}
+		if result.QueryHistory, err = s.store.MigrateQueryHistory(ctx, cmd.OrgID, r, cmd.DryRun); err != nil {
+			return nil, err
+		}
+	}
+
+	s.log.Info("Migrated data source references", "orgId", cmd.OrgID, "from", cmd.From, "to", to["uid"], "dryRun", cmd.DryRun,
+		"dashboards", len(result.Dashboards), "libraryPanels", len(result.LibraryPanels), "alertRules", len(result.AlertRules),
+		"queryHistory", result.QueryHistory)
+	return result, nil
+}
+
+func (s *Service) dataSource(ctx context.Context, orgID int64, uid string) (*models.DataSource, error) {
+	query := &models.GetDataSourceQuery{OrgId: orgID, Uid: uid}
+	if err := s.dataSourceService.GetDataSource(ctx, query); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return nil, fmt.Errorf("%w: %s", err, uid)
+		}
+		return nil, err
+	}
+	return query.Result, nil
+}
+
+func (s *Service) migrateDashboards(ctx context.Context, cmd *MigrateCommand, r *rewriter) ([]*MigratedResource, error) {
+	migrated := make([]*MigratedResource, 0)
+	err := s.store.Dashboards(ctx, cmd.OrgID, func(dash *models.Dashboard) {
+		n := r.rewrite(dash.Data.Interface())
+		if n == 0 {
+			return
+		}
+		res := &MigratedResource{UID: dash.Uid, Title: dash.Title, References: n}
+		migrated = append(migrated, res)
+		if cmd.ToVariable != "" && !hasDataSourceVariable(dash.Data, cmd.ToVariable, r.from.Type) {
+			// the panels would refer to a variable which does not exist
+			res.Error = fmt.Sprintf("the dashboard has no %s data source variable named %s", r.from.Type, cmd.ToVariable)
+			return
+		}
+		if cmd.DryRun {
+			return
+		}
+
+		updated := models.NewDashboardFromJson(dash.Data)
+		updated.SetId(dash.Id)
+		updated.SetUid(dash.Uid)
+		updated.SetVersion(dash.Version)
+		updated.OrgId = dash.OrgId
+		updated.FolderId = dash.FolderId
+		_, err := s.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+			OrgId:     cmd.OrgID,
+			User:      cmd.User,
+			Dashboard: updated,
+			Message:   r.message(),
+		}, false)
+		if err != nil {
+			s.log.Warn("Failed to migrate the data source references of a dashboard", "uid", dash.Uid, "error", err)
+			res.Error = err.Error()
+		}
+	})
+	return migrated, err
+}
+
+// hasDataSourceVariable returns true when the dashboard has a data source variable listing the data sources of the type.
+func hasDataSourceVariable(dash *simplejson.Json, name, dsType string) bool {
+	for _, v := range dash.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		if variable.Get("name").MustString() == name && variable.Get("type").MustString() == "datasource" &&
+			variable.Get("query").MustString() == dsType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) migrateLibraryPanels(ctx context.Context, cmd *MigrateCommand, r *rewriter) ([]*MigratedResource, error) {
+	panels, err := s.store.LibraryPanels(ctx, cmd.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated := make([]*MigratedResource, 0)
+	for _, panel := range panels {
+		var model interface{}
+		if err := json.Unmarshal(panel.Model, &model); err != nil {
+			s.log.Warn("Failed to read the model of a library panel", "uid", panel.UID, "error", err)
+			continue
+		}
+		n := r.rewrite(model)
+		if n == 0 {
+			continue
+		}
+		res := &MigratedResource{UID: panel.UID, Title: panel.Name, References: n}
+		migrated = append(migrated, res)
+		if cmd.DryRun {
+			continue
+		}
+
+		data, err := json.Marshal(model)
+		if err != nil {
+			return nil, err
+		}
+		_, err = s.libraryElements.PatchElement(ctx, cmd.User, libraryelements.PatchLibraryElementCommand{
+			FolderID: panel.FolderID,
+			Model:    data,
+			Kind:     int64(models.PanelElement),
+			Version:  panel.Version,
+		}, panel.UID)
+		if err != nil {
+			s.log.Warn("Failed to migrate the data source references of a library panel", "uid", panel.UID, "error", err)
+			res.Error = err.Error()
+		}
+	}
+	return migrated, nil
+}
+
+func (s *Service) migrateAlertRules(ctx context.Context, cmd *MigrateCommand, r *rewriter) ([]*MigratedResource, error) {
+	query := &ngmodels.ListAlertRulesQuery{OrgID: cmd.OrgID}
+	if err := s.ruleStore.ListAlertRules(ctx, query); err != nil {
+		return nil, err
+	}
+
+	migrated := make([]*MigratedResource, 0)
+	for _, rule := range query.Result {
+		updated := *rule
+		updated.Data = make([]ngmodels.AlertQuery, 0, len(rule.Data))
+		n := 0
+		for _, q := range rule.Data {
+			if q.DatasourceUID == r.from.Uid {
+				q.DatasourceUID = r.to["uid"].(string)
+				n++
+				// the model of a query refers to the data source as well
+				var model interface{}
+				if err := json.Unmarshal(q.Model, &model); err == nil && r.rewrite(model) > 0 {
+					if data, err := json.Marshal(model); err == nil {
+						q.Model = data
+					}
+				}
+			}
+			updated.Data = append(updated.Data, q)
+		}
+		if n == 0 {
+			continue
+		}
+
+		res := &MigratedResource{UID: rule.UID, Title: rule.Title, References: n}
+		migrated = append(migrated, res)
+		if cmd.DryRun {
+			continue
+		}
+		if err := s.ruleStore.UpdateAlertRules(ctx, []ngstore.UpdateRule{{Existing: rule, New: updated}}); err != nil {
+			s.log.Warn("Failed to migrate the data source references of an alert rule", "uid", rule.UID, "error", err)
+			res.Error = err.Error()
+		}
+	}
+	return migrated, nil
+}
+
+// rewriter replaces the references to a data source in the JSON models of dashboards, panels and queries.
+type rewriter struct {
+	from *models.DataSource
+	to   map[string]interface{}
+}
+
+func newRef(dsType, uid string) map[string]interface{} {
+	return map[string]interface{}{"type": dsType, "uid": uid}
+}
+
+func (r *rewriter) message() string {
+	return fmt.Sprintf("Migrated data source references from %s to %s", r.from.Uid, r.to["uid"])
+}
+
+// rewrite replaces the datasource fields referring to the data source by UID or by name in a JSON value, and
+// returns the number of replaced references. Panels relying on the default data source are left as is.
+func (r *rewriter) rewrite(v interface{}) int {
+	n := 0
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if key == "datasource" && r.refersTo(value) {
+				v[key] = newRef(r.to["type"].(string), r.to["uid"].(string))
+				n++
+				continue
+			}
+			n += r.rewrite(value)
+		}
+	case []interface{}:
+		for _, value := range v {
+			n += r.rewrite(value)
+		}
+	}
+	return n
+}
+
+func (r *rewriter) refersTo(ref interface{}) bool {
+	switch ref := ref.(type) {
+	case string:
+		return ref != "" && (ref == r.from.Uid || ref == r.from.Name)
+	case map[string]interface{}:
+		uid, _ := ref["uid"].(string)
+		return uid != "" && uid == r.from.Uid
+	}
+	return false
+}
+
+// rewriteQueries rewrites the queries of a query history entry.
+func (r *rewriter) rewriteQueries(queries *simplejson.Json) int {
+	if queries == nil {
+		return 0
+	}
+	return r.rewrite(queries.Interface())
+}
@@ -0,0 +1,215 @@
+package references
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/queryhistory"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestMigrateCommand_Validate(t *testing.T) {
+	testCases := []struct {
+		cmd MigrateCommand
+		err error
+	}{
+		{cmd: MigrateCommand{To: "new"}, err: ErrMissingSource},
+		{cmd: MigrateCommand{From: "old"}, err: ErrMissingTarget},
+		{cmd: MigrateCommand{From: "old", To: "new", ToVariable: "ds"}, err: ErrConflictingTargets},
+		{cmd: MigrateCommand{From: "old", To: "old"}, err: ErrSameDataSource},
+		{cmd: MigrateCommand{From: "old", To: "new"}},
+		{cmd: MigrateCommand{From: "old", ToVariable: "ds"}},
+		{cmd: MigrateCommand{From: "old", ToVariable: "prometheus_2"}},
+		{cmd: MigrateCommand{From: "old", ToVariable: "${ds}"}, err: ErrInvalidVariable},
+		{cmd: MigrateCommand{From: "old", ToVariable: "data source"}, err: ErrInvalidVariable},
+		{cmd: MigrateCommand{From: "old", ToVariable: "ds-1"}, err: ErrInvalidVariable},
+	}
+	for _, tc := range testCases {
+		require.ErrorIs(t, tc.cmd.Validate(), tc.err)
+	}
+}
+
+func TestRewriter(t *testing.T) {
+	from := &models.DataSource{Uid: "old-prom", Name: "Old Prometheus", Type: "prometheus"}
+
+	read := func(t *testing.T, s string) interface{} {
+		t.Helper()
+		var v interface{}
+		require.NoError(t, json.Unmarshal([]byte(s), &v))
+		return v
+	}
+
+	dashboard := `{
+		"annotations": {"list": [{"name": "Deploys", "datasource": "Old Prometheus"}]},
+		"templating": {"list": [{"name": "job", "type": "query", "datasource": {"type": "prometheus", "uid": "old-prom"}}]},
+		"panels": [
+			{"id": 1, "datasource": {"type": "prometheus", "uid": "old-prom"}, "targets": [{"refId": "A", "datasource": {"type": "prometheus", "uid": "old-prom"}}]},
+			{"id": 2, "datasource": "-- Mixed --", "targets": [
+				{"refId": "A", "datasource": "old-prom"},
+				{"refId": "B", "datasource": {"type": "loki", "uid": "loki"}}
+			]},
+			{"id": 3, "datasource": null, "targets": [{"refId": "A"}]},
+			{"id": 4, "type": "row", "panels": [{"id": 5, "datasource": "Old Prometheus"}]}
+		]
+	}`
+
+	t.Run("References by UID and by name should be replaced by references to the new data source", func(t *testing.T) {
+		r := &rewriter{from: from, to: newRef("prometheus", "new-prom")}
+		v := read(t, dashboard)
+		require.Equal(t, 6, r.rewrite(v))
+
+		ref := map[string]interface{}{"type": "prometheus", "uid": "new-prom"}
+		expected := read(t, dashboard)
+		d := expected.(map[string]interface{})
+		d["annotations"].(map[string]interface{})["list"].([]interface{})[0].(map[string]interface{})["datasource"] = ref
+		d["templating"].(map[string]interface{})["list"].([]interface{})[0].(map[string]interface{})["datasource"] = ref
+		panels := d["panels"].([]interface{})
+		panels[0].(map[string]interface{})["datasource"] = ref
+		panels[0].(map[string]interface{})["targets"].([]interface{})[0].(map[string]interface{})["datasource"] = ref
+		panels[1].(map[string]interface{})["targets"].([]interface{})[0].(map[string]interface{})["datasource"] = ref
+		panels[3].(map[string]interface{})["panels"].([]interface{})[0].(map[string]interface{})["datasource"] = ref
+		require.Equal(t, expected, v)
+	})
+
+	t.Run("References replaced by a template variable should keep the type of the data source", func(t *testing.T) {
+		r := &rewriter{from: from, to: newRef(from.Type, "${prometheus}")}
+		v := read(t, `{"panels": [{"id": 1, "datasource": "old-prom"}]}`)
+		require.Equal(t, 1, r.rewrite(v))
+		require.Equal(t, read(t, `{"panels": [{"id": 1, "datasource": {"type": "prometheus", "uid": "${prometheus}"}}]}`), v)
+	})
+
+	t.Run("Models without references should be left as is", func(t *testing.T) {
+		r := &rewriter{from: from, to: newRef("prometheus", "new-prom")}
+		v := read(t, `{"panels": [{"id": 1, "datasource": {"uid": "loki"}}, {"id": 2, "datasource": ""}]}`)
+		require.Zero(t, r.rewrite(v))
+		require.Equal(t, read(t, `{"panels": [{"id": 1, "datasource": {"uid": "loki"}}, {"id": 2, "datasource": ""}]}`), v)
+	})
+}
+
+type fakeStore struct {
+	dashboards []*models.Dashboard
+}
+
+func (f *fakeStore) Dashboards(_ context.Context, _ int64, fn func(dash *models.Dashboard)) error {
+	for _, dash := range f.dashboards {
+		fn(dash)
+	}
+	return nil
+}
+
+func (f *fakeStore) LibraryPanels(context.Context, int64) ([]*libraryPanel, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) MigrateQueryHistory(context.Context, int64, *rewriter, bool) (int64, error) {
+	return 0, nil
+}
+
+func TestMigrateToVariable(t *testing.T) {
+	newDashboard := func(uid, variables string) *models.Dashboard {
+		data, err := simplejson.NewJson([]byte(`{
+			"uid": "` + uid + `",
+			"title": "` + uid + `",
+			"templating": {"list": ` + variables + `},
+			"panels": [{"id": 1, "datasource": {"type": "prometheus", "uid": "old-prom"}}]
+		}`))
+		require.NoError(t, err)
+		dash := models.NewDashboardFromJson(data)
+		dash.OrgId = 1
+		return dash
+	}
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("SaveDashboard", mock.Anything, mock.MatchedBy(func(dto *dashboards.SaveDashboardDTO) bool {
+		return dto.Dashboard.Uid == "with-variable"
+	}), false).Return(&models.Dashboard{}, nil).Once()
+
+	s := &Service{
+		store: &fakeStore{dashboards: []*models.Dashboard{
+			newDashboard("with-variable", `[{"name": "ds", "type": "datasource", "query": "prometheus"}]`),
+			newDashboard("without-variable", `[]`),
+			newDashboard("with-query-variable", `[{"name": "ds", "type": "query", "query": "prometheus"}]`),
+			newDashboard("with-loki-variable", `[{"name": "ds", "type": "datasource", "query": "loki"}]`),
+		}},
+		dashboardService: dashboardService,
+		dataSourceService: &fakeDatasources.FakeDataSourceService{DataSources: []*models.DataSource{
+			{OrgId: 1, Uid: "old-prom", Name: "Old Prometheus", Type: "prometheus"},
+		}},
+		log: log.New("test"),
+	}
+
+	result, err := s.Migrate(context.Background(), &MigrateCommand{From: "old-prom", ToVariable: "ds", OrgID: 1})
+	require.NoError(t, err)
+	require.Equal(t, []*MigratedResource{
+		{UID: "with-variable", Title: "with-variable", References: 1},
+		{UID: "without-variable", Title: "without-variable", References: 1,
+			Error: "the dashboard has no prometheus data source variable named ds"},
+		{UID: "with-query-variable", Title: "with-query-variable", References: 1,
+			Error: "the dashboard has no prometheus data source variable named ds"},
+		{UID: "with-loki-variable", Title: "with-loki-variable", References: 1,
+			Error: "the dashboard has no prometheus data source variable named ds"},
+	}, result.Dashboards)
+	require.Empty(t, result.AlertRules)
+}
+
+func TestIntegrationMigrateQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	ss := sqlstore.InitTestDB(t)
+	store := &sqlStore{db: ss}
+
+	queries := func(uid string) *simplejson.Json {
+		return simplejson.NewFromAny([]interface{}{
+			map[string]interface{}{"refId": "A", "expr": "up", "datasource": map[string]interface{}{"type": "prometheus", "uid": uid}},
+		})
+	}
+	require.NoError(t, ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(
+			&queryhistory.QueryHistory{UID: "q1", OrgID: 1, DatasourceUID: "old-prom", CreatedBy: 1, CreatedAt: 1, Queries: queries("old-prom")},
+			&queryhistory.QueryHistory{UID: "q2", OrgID: 1, DatasourceUID: "loki", CreatedBy: 1, CreatedAt: 1, Queries: simplejson.New()},
+			&queryhistory.QueryHistory{UID: "q3", OrgID: 2, DatasourceUID: "old-prom", CreatedBy: 1, CreatedAt: 1, Queries: queries("old-prom")},
+		)
+		return err
+	}))
+	r := &rewriter{from: &models.DataSource{Uid: "old-prom", Type: "prometheus"}, to: newRef("prometheus", "new-prom")}
+
+	get := func(uid string) *queryhistory.QueryHistory {
+		entry := &queryhistory.QueryHistory{}
+		require.NoError(t, ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Where("uid = ?", uid).Get(entry)
+			return err
+		}))
+		return entry
+	}
+
+	t.Run("A dry run should count the entries without changing them", func(t *testing.T) {
+		migrated, err := store.MigrateQueryHistory(ctx, 1, r, true)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), migrated)
+		require.Equal(t, "old-prom", get("q1").DatasourceUID)
+	})
+
+	t.Run("The entries of the data source in the org should be migrated", func(t *testing.T) {
+		migrated, err := store.MigrateQueryHistory(ctx, 1, r, false)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), migrated)
+
+		entry := get("q1")
+		require.Equal(t, "new-prom", entry.DatasourceUID)
+		require.Equal(t, queries("new-prom"), entry.Queries)
+		require.Equal(t, "loki", get("q2").DatasourceUID)
+		require.Equal(t, "old-prom", get("q3").DatasourceUID)
+	})
+}
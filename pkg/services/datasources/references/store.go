@@ -0,0 +1,96 @@
+package references
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/queryhistory"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+// dashboardsBatchSize is the number of dashboards read at once
+const dashboardsBatchSize = 100
+
+type store interface {
+	// Dashboards calls fn with each dashboard of an org, folders excluded.
+	Dashboards(ctx context.Context, orgID int64, fn func(dash *models.Dashboard)) error
+	LibraryPanels(ctx context.Context, orgID int64) ([]*libraryPanel, error)
+	// MigrateQueryHistory rewrites the query history entries of the data source migrated by r, and returns the
+	// number of entries. Nothing is changed in a dry run.
+	MigrateQueryHistory(ctx context.Context, orgID int64, r *rewriter, dryRun bool) (int64, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Dashboards(ctx context.Context, orgID int64, fn func(dash *models.Dashboard)) error {
+	var lastID int64
+	for {
+		batch := make([]*models.Dashboard, 0, dashboardsBatchSize)
+		err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			return sess.Where("org_id = ? AND is_folder = ? AND id > ?", orgID, s.db.GetDialect().BooleanStr(false), lastID).
+				Asc("id").
+				Limit(dashboardsBatchSize).
+				Find(&batch)
+		})
+		if err != nil {
+			return err
+		}
+
+		// the dashboards are handled outside of the session, as they may be saved
+		for _, dash := range batch {
+			fn(dash)
+		}
+
+		if len(batch) < dashboardsBatchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].Id
+	}
+}
+
+type libraryPanel struct {
+	UID      string `xorm:"uid"`
+	Name     string `xorm:"name"`
+	FolderID int64  `xorm:"folder_id"`
+	Model    []byte `xorm:"model"`
+	Version  int64  `xorm:"version"`
+}
+
+func (s *sqlStore) LibraryPanels(ctx context.Context, orgID int64) ([]*libraryPanel, error) {
+	panels := make([]*libraryPanel, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("library_element").
+			Where("org_id = ? AND kind = ?", orgID, models.PanelElement).
+			Cols("uid", "name", "folder_id", "model", "version").
+			Asc("id").
+			Find(&panels)
+	})
+	return panels, err
+}
+
+func (s *sqlStore) MigrateQueryHistory(ctx context.Context, orgID int64, r *rewriter, dryRun bool) (int64, error) {
+	var migrated int64
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		entries := make([]*queryhistory.QueryHistory, 0)
+		if err := sess.Where("org_id = ? AND datasource_uid = ?", orgID, r.from.Uid).Find(&entries); err != nil {
+			return err
+		}
+		migrated = int64(len(entries))
+		if dryRun {
+			return nil
+		}
+
+		for _, entry := range entries {
+			r.rewriteQueries(entry.Queries)
+			entry.DatasourceUID = r.to["uid"].(string)
+			if _, err := sess.ID(entry.ID).Cols("datasource_uid", "queries").Update(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return migrated, err
+}
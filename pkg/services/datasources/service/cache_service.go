@@ -96,6 +96,13 @@ func (dc *CacheServiceImpl) GetDatasourceByUID(
 	return ds, nil
 }
 
+func (dc *CacheServiceImpl) Invalidate(ds *models.DataSource) {
+	dc.CacheService.Delete(idKey(ds.Id))
+	if ds.Uid != "" {
+		dc.CacheService.Delete(uidKey(ds.OrgId, ds.Uid))
+	}
+}
+
 func idKey(id int64) string {
 	return fmt.Sprintf("ds-%d", id)
 }
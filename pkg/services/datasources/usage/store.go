@@ -0,0 +1,211 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/searchV2/extract"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/db"
+)
+
+// dashboardsBatchSize is the number of dashboards read at once when looking for the dashboards using a data source
+const dashboardsBatchSize = 200
+
+type store interface {
+	Dashboards(ctx context.Context, orgID int64, uid string, lookup extract.DatasourceLookup) ([]*DashboardUsage, error)
+	LibraryPanels(ctx context.Context, orgID int64, uid string, lookup extract.DatasourceLookup) ([]*LibraryPanelUsage, error)
+	// QueryCounts returns the saved query counts of the data source by user ID.
+	QueryCounts(ctx context.Context, orgID int64, uid string) (map[int64]*queryCount, error)
+	// QueryHistoryCounts returns the number of query history entries of the data source by user ID.
+	QueryHistoryCounts(ctx context.Context, orgID int64, uid string) (map[int64]*queryCount, error)
+	SaveQueryCounts(ctx context.Context, counts map[queryKey]*queryCount) error
+	UserLogins(ctx context.Context, ids []int64) (map[int64]string, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+type dashboardRow struct {
+	ID       int64  `xorm:"id"`
+	UID      string `xorm:"uid"`
+	Title    string `xorm:"title"`
+	FolderID int64  `xorm:"folder_id"`
+	Data     []byte `xorm:"data"`
+}
+
+func (s *sqlStore) Dashboards(ctx context.Context, orgID int64, uid string, lookup extract.DatasourceLookup) ([]*DashboardUsage, error) {
+	dashboards := make([]*DashboardUsage, 0)
+
+	var lastID int64
+	for {
+		rows := make([]*dashboardRow, 0, dashboardsBatchSize)
+		err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			return sess.Table("dashboard").
+				Where("org_id = ? AND is_folder = ? AND id > ?", orgID, s.db.GetDialect().BooleanStr(false), lastID).
+				Cols("id", "uid", "title", "folder_id", "data").
+				OrderBy("id ASC").
+				Limit(dashboardsBatchSize).
+				Find(&rows)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			// the references read before an error are still worth reporting
+			info, _ := extract.ReadDashboard(bytes.NewReader(row.Data), lookup)
+			if info == nil {
+				continue
+			}
+			// the data sources of a dashboard do not include the ones of the panels in collapsed rows
+			panels := panelsUsing(info.Panels, uid, make([]*PanelUsage, 0))
+			if len(panels) == 0 && !usesDataSource(info.Datasource, uid) {
+				continue
+			}
+			dashboards = append(dashboards, &DashboardUsage{
+				UID:      row.UID,
+				Title:    row.Title,
+				FolderID: row.FolderID,
+				Panels:   panels,
+			})
+		}
+
+		if len(rows) < dashboardsBatchSize {
+			return dashboards, nil
+		}
+		lastID = rows[len(rows)-1].ID
+	}
+}
+
+func panelsUsing(panels []extract.PanelInfo, uid string, result []*PanelUsage) []*PanelUsage {
+	for _, panel := range panels {
+		if usesDataSource(panel.Datasource, uid) {
+			result = append(result, &PanelUsage{ID: panel.ID, Title: panel.Title})
+		}
+		result = panelsUsing(panel.Collapsed, uid, result)
+	}
+	return result
+}
+
+type libraryPanelRow struct {
+	UID      string `xorm:"uid"`
+	Name     string `xorm:"name"`
+	FolderID int64  `xorm:"folder_id"`
+	Model    []byte `xorm:"model"`
+}
+
+func (s *sqlStore) LibraryPanels(ctx context.Context, orgID int64, uid string, lookup extract.DatasourceLookup) ([]*LibraryPanelUsage, error) {
+	rows := make([]*libraryPanelRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("library_element").
+			Where("org_id = ? AND kind = ?", orgID, models.PanelElement).
+			Cols("uid", "name", "folder_id", "model").
+			OrderBy("name ASC").
+			Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	panels := make([]*LibraryPanelUsage, 0)
+	for _, row := range rows {
+		// the model of a library panel is read as the only panel of a dashboard
+		data := append(append([]byte(`{"panels":[`), row.Model...), ']', '}')
+		info, _ := extract.ReadDashboard(bytes.NewReader(data), lookup)
+		if info == nil || !usesDataSource(info.Datasource, uid) {
+			continue
+		}
+		panels = append(panels, &LibraryPanelUsage{UID: row.UID, Name: row.Name, FolderID: row.FolderID})
+	}
+	return panels, nil
+}
+
+type queryCountRow struct {
+	UserID   int64 `xorm:"user_id"`
+	Count    int64 `xorm:"query_count"`
+	LastUsed int64 `xorm:"last_used"`
+}
+
+func (s *sqlStore) QueryCounts(ctx context.Context, orgID int64, uid string) (map[int64]*queryCount, error) {
+	rows := make([]*queryCountRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("data_source_usage").
+			Where("org_id = ? AND datasource_uid = ?", orgID, uid).
+			Cols("user_id", "query_count", "last_used").
+			Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return countsByUser(rows), nil
+}
+
+func (s *sqlStore) QueryHistoryCounts(ctx context.Context, orgID int64, uid string) (map[int64]*queryCount, error) {
+	rows := make([]*queryCountRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.SQL(`SELECT created_by AS user_id, COUNT(*) AS query_count, MAX(created_at) AS last_used
+			FROM query_history
+			WHERE org_id = ? AND datasource_uid = ?
+			GROUP BY created_by`, orgID, uid).
+			Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return countsByUser(rows), nil
+}
+
+func countsByUser(rows []*queryCountRow) map[int64]*queryCount {
+	counts := make(map[int64]*queryCount, len(rows))
+	for _, row := range rows {
+		counts[row.UserID] = &queryCount{count: row.Count, lastUsed: row.LastUsed}
+	}
+	return counts
+}
+
+func (s *sqlStore) SaveQueryCounts(ctx context.Context, counts map[queryKey]*queryCount) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for key, c := range counts {
+			res, err := sess.Exec(`UPDATE data_source_usage
+				SET query_count = query_count + ?, last_used = CASE WHEN last_used > ? THEN last_used ELSE ? END
+				WHERE org_id = ? AND datasource_uid = ? AND user_id = ?`,
+				c.count, c.lastUsed, c.lastUsed, key.orgID, key.uid, key.userID)
+			if err != nil {
+				return err
+			}
+			if affected, err := res.RowsAffected(); err != nil {
+				return err
+			} else if affected > 0 {
+				continue
+			}
+
+			if _, err := sess.Exec(`INSERT INTO data_source_usage (org_id, datasource_uid, user_id, query_count, last_used)
+				VALUES (?, ?, ?, ?, ?)`, key.orgID, key.uid, key.userID, c.count, c.lastUsed); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) UserLogins(ctx context.Context, ids []int64) (map[int64]string, error) {
+	logins := make(map[int64]string, len(ids))
+	if len(ids) == 0 {
+		return logins, nil
+	}
+
+	users := make([]*models.User, 0, len(ids))
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("user").In("id", ids).Cols("id", "login").Find(&users)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		logins[u.Id] = u.Login
+	}
+	return logins, nil
+}
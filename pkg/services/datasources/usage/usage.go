@@ -0,0 +1,306 @@
+// Package usage reports what depends on a data source: the dashboards, alert rules and library panels querying it,
+// and the users who queried it and when.
+package usage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/searchV2"
+	"github.com/grafana/grafana/pkg/services/searchV2/extract"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// flushInterval is how often the recorded queries are saved
+const flushInterval = time.Minute
+
+// DataSourceUsage is what depends on a data source.
+type DataSourceUsage struct {
+	UID           string               `json:"uid"`
+	Dashboards    []*DashboardUsage    `json:"dashboards"`
+	AlertRules    []*AlertRuleUsage    `json:"alertRules"`
+	LibraryPanels []*LibraryPanelUsage `json:"libraryPanels"`
+	Users         []*UserUsage         `json:"users"`
+	// QueryCount is the number of queries made to the data source through the query API
+	QueryCount int64 `json:"queryCount"`
+	// LastUsed is the time of the last query to the data source or query history entry of the data source
+	LastUsed *time.Time `json:"lastUsed,omitempty"`
+}
+
+// DashboardUsage is a dashboard with panels querying a data source.
+type DashboardUsage struct {
+	UID      string        `json:"uid"`
+	Title    string        `json:"title"`
+	FolderID int64         `json:"folderId"`
+	Panels   []*PanelUsage `json:"panels"`
+}
+
+type PanelUsage struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+// AlertRuleUsage is an alert rule with queries to a data source.
+type AlertRuleUsage struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	FolderUID string `json:"folderUid"`
+	RuleGroup string `json:"ruleGroup"`
+}
+
+// LibraryPanelUsage is a library panel querying a data source.
+type LibraryPanelUsage struct {
+	UID      string `json:"uid"`
+	Name     string `json:"name"`
+	FolderID int64  `json:"folderId"`
+}
+
+// UserUsage is how much a user queried a data source. Queries made without a signed in user, such as the queries
+// of public dashboards, are counted for the user ID 0.
+type UserUsage struct {
+	UserID            int64      `json:"userId"`
+	Login             string     `json:"login"`
+	QueryCount        int64      `json:"queryCount"`
+	QueryHistoryCount int64      `json:"queryHistoryCount"`
+	LastUsed          *time.Time `json:"lastUsed,omitempty"`
+}
+
+type Service struct {
+	store     store
+	sqlStore  *sqlstore.SQLStore
+	ruleStore ngstore.RuleStore
+	log       log.Logger
+
+	mu      sync.Mutex
+	pending map[queryKey]*queryCount
+}
+
+type queryKey struct {
+	orgID  int64
+	uid    string
+	userID int64
+}
+
+type queryCount struct {
+	count    int64
+	lastUsed int64
+}
+
+func ProvideService(ss *sqlstore.SQLStore, ruleStore ngstore.RuleStore) *Service {
+	return &Service{
+		store:     &sqlStore{db: ss},
+		sqlStore:  ss,
+		ruleStore: ruleStore,
+		log:       log.New("datasources.usage"),
+		pending:   make(map[queryKey]*queryCount),
+	}
+}
+
+// RecordQuery counts a query of a user to a data source. The counts are saved every minute.
+func (s *Service) RecordQuery(orgID int64, uid string, userID int64) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := queryKey{orgID: orgID, uid: uid, userID: userID}
+	c, ok := s.pending[key]
+	if !ok {
+		c = &queryCount{}
+		s.pending[key] = c
+	}
+	c.count++
+	c.lastUsed = time.Now().Unix()
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flush(ctx)
+		case <-ctx.Done():
+			// save the queries recorded since the last flush before shutting down
+			s.flush(context.Background())
+			return ctx.Err()
+		}
+	}
+}
+
+// flush saves the recorded queries, they are kept for the next flush when they cannot be saved.
+func (s *Service) flush(ctx context.Context) {
+	s.mu.Lock()
+	counts := s.pending
+	s.pending = make(map[queryKey]*queryCount)
+	s.mu.Unlock()
+
+	if len(counts) == 0 {
+		return
+	}
+	if err := s.store.SaveQueryCounts(ctx, counts); err != nil {
+		s.log.Error("Failed to save the data source query counts", "error", err)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for key, c := range counts {
+			if p, ok := s.pending[key]; ok {
+				p.count += c.count
+				if c.lastUsed > p.lastUsed {
+					p.lastUsed = c.lastUsed
+				}
+				continue
+			}
+			s.pending[key] = c
+		}
+	}
+}
+
+// Usage returns what depends on the data source with the given UID in an org.
+func (s *Service) Usage(ctx context.Context, orgID int64, uid string) (*DataSourceUsage, error) {
+	usage := &DataSourceUsage{UID: uid}
+
+	lookup, err := searchV2.LoadDatasourceLookup(ctx, orgID, s.sqlStore)
+	if err != nil {
+		return nil, err
+	}
+	if usage.Dashboards, err = s.store.Dashboards(ctx, orgID, uid, lookup); err != nil {
+		return nil, err
+	}
+	if usage.LibraryPanels, err = s.store.LibraryPanels(ctx, orgID, uid, lookup); err != nil {
+		return nil, err
+	}
+	if usage.AlertRules, err = s.alertRules(ctx, orgID, uid); err != nil {
+		return nil, err
+	}
+	if usage.Users, err = s.users(ctx, orgID, uid); err != nil {
+		return nil, err
+	}
+
+	for _, u := range usage.Users {
+		usage.QueryCount += u.QueryCount
+		if u.LastUsed != nil && (usage.LastUsed == nil || u.LastUsed.After(*usage.LastUsed)) {
+			usage.LastUsed = u.LastUsed
+		}
+	}
+	return usage, nil
+}
+
+func (s *Service) alertRules(ctx context.Context, orgID int64, uid string) ([]*AlertRuleUsage, error) {
+	query := &ngmodels.ListAlertRulesQuery{OrgID: orgID}
+	if err := s.ruleStore.ListAlertRules(ctx, query); err != nil {
+		return nil, err
+	}
+
+	rules := make([]*AlertRuleUsage, 0)
+	for _, rule := range query.Result {
+		for _, q := range rule.Data {
+			if q.DatasourceUID == uid {
+				rules = append(rules, &AlertRuleUsage{
+					UID:       rule.UID,
+					Title:     rule.Title,
+					FolderUID: rule.NamespaceUID,
+					RuleGroup: rule.RuleGroup,
+				})
+				break
+			}
+		}
+	}
+	return rules, nil
+}
+
+// users combines the saved query counts, the queries recorded since the last flush and the query history.
+func (s *Service) users(ctx context.Context, orgID int64, uid string) ([]*UserUsage, error) {
+	counts, err := s.store.QueryCounts(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.store.QueryHistoryCounts(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	for key, c := range s.pending {
+		if key.orgID != orgID || key.uid != uid {
+			continue
+		}
+		saved := counts[key.userID]
+		if saved == nil {
+			saved = &queryCount{}
+			counts[key.userID] = saved
+		}
+		saved.count += c.count
+		if c.lastUsed > saved.lastUsed {
+			saved.lastUsed = c.lastUsed
+		}
+	}
+	s.mu.Unlock()
+
+	users := make(map[int64]*UserUsage, len(counts)+len(history))
+	user := func(id int64) *UserUsage {
+		if _, ok := users[id]; !ok {
+			users[id] = &UserUsage{UserID: id}
+		}
+		return users[id]
+	}
+	used := func(u *UserUsage, at int64) {
+		if t := time.Unix(at, 0); at > 0 && (u.LastUsed == nil || t.After(*u.LastUsed)) {
+			u.LastUsed = &t
+		}
+	}
+	for id, c := range counts {
+		u := user(id)
+		u.QueryCount = c.count
+		used(u, c.lastUsed)
+	}
+	for id, c := range history {
+		u := user(id)
+		u.QueryHistoryCount = c.count
+		used(u, c.lastUsed)
+	}
+
+	ids := make([]int64, 0, len(users))
+	for id := range users {
+		ids = append(ids, id)
+	}
+	logins, err := s.store.UserLogins(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*UserUsage, 0, len(users))
+	for id, u := range users {
+		u.Login = logins[id]
+		result = append(result, u)
+	}
+	// the users who queried the data source last come first
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].LastUsed, result[j].LastUsed
+		if a == nil || b == nil || a.Equal(*b) {
+			if (a == nil) != (b == nil) {
+				return b == nil
+			}
+			return result[i].UserID < result[j].UserID
+		}
+		return a.After(*b)
+	})
+	return result, nil
+}
+
+func usesDataSource(refs []extract.DataSourceRef, uid string) bool {
+	for _, ref := range refs {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/queryhistory"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestIntegrationDataSourceUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	ss := sqlstore.InitTestDB(t)
+	ruleStore := ngstore.NewFakeRuleStore(t)
+	s := ProvideService(ss, ruleStore)
+
+	for _, ds := range []models.AddDataSourceCommand{
+		{OrgId: 1, Uid: "prom", Name: "Prometheus", Type: "prometheus", IsDefault: true},
+		{OrgId: 1, Uid: "loki", Name: "Loki", Type: "loki"},
+	} {
+		ds := ds
+		require.NoError(t, ss.AddDataSource(ctx, &ds))
+	}
+	viewer, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "viewer"})
+	require.NoError(t, err)
+
+	insertDashboard := func(orgID int64, uid string, data map[string]interface{}) {
+		data["uid"] = uid
+		dash := models.NewDashboardFromJson(simplejson.NewFromAny(data))
+		dash.OrgId = orgID
+		dash.Created = time.Now()
+		dash.Updated = time.Now()
+		require.NoError(t, ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Insert(dash)
+			return err
+		}))
+	}
+	insertDashboard(1, "by-uid", map[string]interface{}{"title": "By UID", "panels": []interface{}{
+		map[string]interface{}{"id": 1, "title": "Logs", "datasource": map[string]interface{}{"uid": "loki", "type": "loki"}},
+		map[string]interface{}{"id": 2, "title": "Metrics", "datasource": "Prometheus"},
+	}})
+	insertDashboard(1, "default", map[string]interface{}{"title": "Default", "panels": []interface{}{
+		map[string]interface{}{"id": 1, "title": "Row", "type": "row", "collapsed": true, "panels": []interface{}{
+			map[string]interface{}{"id": 2, "title": "Collapsed", "datasource": nil},
+		}},
+	}})
+	insertDashboard(2, "other-org", map[string]interface{}{"title": "Other org", "panels": []interface{}{
+		map[string]interface{}{"id": 1, "title": "Logs", "datasource": map[string]interface{}{"uid": "loki"}},
+	}})
+
+	require.NoError(t, ss.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec(`INSERT INTO library_element (org_id, folder_id, uid, name, kind, type, description, model, created, created_by, updated, updated_by, version)
+			VALUES (?, 0, ?, ?, ?, 'graph', '', ?, ?, 1, ?, 1, 1)`,
+			1, "lib-loki", "Loki logs", models.PanelElement, `{"title": "Logs", "datasource": {"uid": "loki"}}`, time.Now(), time.Now())
+		if err != nil {
+			return err
+		}
+		_, err = sess.Insert(&queryhistory.QueryHistory{UID: "q1", OrgID: 1, DatasourceUID: "loki", CreatedBy: viewer.Id,
+			CreatedAt: 1000, Queries: simplejson.New()})
+		return err
+	}))
+
+	ruleStore.PutRule(ctx,
+		&ngmodels.AlertRule{OrgID: 1, UID: "loki-rule", Title: "Errors", NamespaceUID: "folder", RuleGroup: "logs",
+			Data: []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: "loki"}, {RefID: "B", DatasourceUID: "-100"}}},
+		&ngmodels.AlertRule{OrgID: 1, UID: "prom-rule", Title: "Latency", NamespaceUID: "folder", RuleGroup: "metrics",
+			Data: []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: "prom"}}},
+	)
+
+	s.RecordQuery(1, "loki", viewer.Id)
+	s.RecordQuery(1, "loki", viewer.Id)
+	s.flush(ctx)
+	s.RecordQuery(1, "loki", viewer.Id)
+	s.RecordQuery(1, "loki", 0)
+	s.RecordQuery(2, "loki", viewer.Id)
+
+	t.Run("Usage should combine what depends on the data source", func(t *testing.T) {
+		usage, err := s.Usage(ctx, 1, "loki")
+		require.NoError(t, err)
+
+		require.Len(t, usage.Dashboards, 1)
+		require.Equal(t, "by-uid", usage.Dashboards[0].UID)
+		require.Equal(t, []*PanelUsage{{ID: 1, Title: "Logs"}}, usage.Dashboards[0].Panels)
+
+		require.Equal(t, []*AlertRuleUsage{{UID: "loki-rule", Title: "Errors", FolderUID: "folder", RuleGroup: "logs"}}, usage.AlertRules)
+		require.Equal(t, []*LibraryPanelUsage{{UID: "lib-loki", Name: "Loki logs"}}, usage.LibraryPanels)
+
+		require.Equal(t, int64(4), usage.QueryCount)
+		require.NotNil(t, usage.LastUsed)
+		require.Len(t, usage.Users, 2)
+		users := map[int64]*UserUsage{}
+		for _, u := range usage.Users {
+			users[u.UserID] = u
+		}
+		require.Equal(t, "viewer", users[viewer.Id].Login)
+		require.Equal(t, int64(3), users[viewer.Id].QueryCount)
+		require.Equal(t, int64(1), users[viewer.Id].QueryHistoryCount)
+		require.Equal(t, int64(1), users[0].QueryCount)
+	})
+
+	t.Run("References to the default data source should resolve to it", func(t *testing.T) {
+		usage, err := s.Usage(ctx, 1, "prom")
+		require.NoError(t, err)
+
+		require.Len(t, usage.Dashboards, 2)
+		require.Equal(t, []*PanelUsage{{ID: 2, Title: "Metrics"}}, usage.Dashboards[0].Panels)
+		require.Equal(t, "default", usage.Dashboards[1].UID)
+		require.Equal(t, []*PanelUsage{{ID: 2, Title: "Collapsed"}}, usage.Dashboards[1].Panels)
+		require.Len(t, usage.AlertRules, 1)
+		require.Empty(t, usage.LibraryPanels)
+		require.Empty(t, usage.Users)
+		require.Nil(t, usage.LastUsed)
+	})
+
+	t.Run("Saving query counts should add to the saved counts", func(t *testing.T) {
+		s.flush(ctx)
+		require.Empty(t, s.pending)
+
+		counts, err := s.store.QueryCounts(ctx, 1, "loki")
+		require.NoError(t, err)
+		require.Equal(t, int64(3), counts[viewer.Id].count)
+		require.Equal(t, int64(1), counts[0].count)
+
+		counts, err = s.store.QueryCounts(ctx, 2, "loki")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), counts[viewer.Id].count)
+	})
+}
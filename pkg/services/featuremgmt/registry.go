@@ -258,5 +258,10 @@ var (
 			Description: "Enables work-in-progress internationalization",
 			State:       FeatureStateAlpha,
 		},
+		{
+			Name:        "nestedFolders",
+			Description: "Enables folders inside folders for dashboards and alert rules",
+			State:       FeatureStateAlpha,
+		},
 	}
 )
@@ -190,4 +190,8 @@ const (
 	// FlagInternationalization
 	// Enables work-in-progress internationalization
 	FlagInternationalization = "internationalization"
+
+	// FlagNestedFolders
+	// Enables folders inside folders for dashboards and alert rules
+	FlagNestedFolders = "nestedFolders"
 )
@@ -130,13 +130,20 @@ func (a *AccessControlDashboardGuardian) CanDelete() (bool, error) {
 }
 
 func (a *AccessControlDashboardGuardian) CanCreate(folderID int64, isFolder bool) (bool, error) {
-	if isFolder {
+	if isFolder && folderID == 0 {
 		return a.evaluate(accesscontrol.EvalPermission(dashboards.ActionFoldersCreate))
 	}
 	folder, err := a.loadParentFolder(folderID)
 	if err != nil {
 		return false, err
 	}
+	if isFolder {
+		// creating a subfolder requires write access to its parent folder
+		return a.evaluate(accesscontrol.EvalAll(
+			accesscontrol.EvalPermission(dashboards.ActionFoldersCreate),
+			accesscontrol.EvalPermission(dashboards.ActionFoldersWrite, dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.Uid)),
+		))
+	}
 	return a.evaluate(accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate, dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.Uid)))
 }
 
@@ -521,11 +521,35 @@ func TestAccessControlDashboardGuardian_CanCreate(t *testing.T) {
 			permissions: []accesscontrol.Permission{},
 			expected:    false,
 		},
+		{
+			desc:     "should be able to create subfolder with write permissions on the parent folder",
+			isFolder: true,
+			folderID: 1,
+			permissions: []accesscontrol.Permission{
+				{Action: dashboards.ActionFoldersCreate},
+				{Action: dashboards.ActionFoldersWrite, Scope: "folders:uid:1"},
+			},
+			expected: true,
+		},
+		{
+			desc:     "should not be able to create subfolder without write permissions on the parent folder",
+			isFolder: true,
+			folderID: 1,
+			permissions: []accesscontrol.Permission{
+				{Action: dashboards.ActionFoldersCreate},
+				{Action: dashboards.ActionFoldersWrite, Scope: "folders:uid:2"},
+			},
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			guardian, _ := setupAccessControlGuardianTest(t, "0", tt.permissions, nil)
+			var dashSvc dashboards.DashboardService
+			if tt.folderID > 0 {
+				dashSvc = testDashSvc(t)
+			}
+			guardian, _ := setupAccessControlGuardianTest(t, "0", tt.permissions, dashSvc)
 
 			can, err := guardian.CanCreate(tt.folderID, tt.isFolder)
 			require.NoError(t, err)
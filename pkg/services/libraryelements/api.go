@@ -3,9 +3,11 @@ package libraryelements
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/web"
@@ -20,6 +22,10 @@ func (l *LibraryElementService) registerAPIEndpoints() {
 		entities.Get("/:uid/connections/", middleware.ReqSignedIn, routing.Wrap(l.getConnectionsHandler))
 		entities.Get("/name/:name", middleware.ReqSignedIn, routing.Wrap(l.getByNameHandler))
 		entities.Patch("/:uid", middleware.ReqSignedIn, routing.Wrap(l.patchHandler))
+		entities.Get("/:uid/versions", middleware.ReqSignedIn, routing.Wrap(l.getVersionsHandler))
+		entities.Get("/:uid/versions/:version", middleware.ReqSignedIn, routing.Wrap(l.getVersionHandler))
+		entities.Get("/:uid/versions/:version/diff", middleware.ReqSignedIn, routing.Wrap(l.diffVersionsHandler))
+		entities.Post("/:uid/versions/:version/restore", middleware.ReqSignedIn, routing.Wrap(l.restoreVersionHandler))
 	})
 }
 
@@ -160,6 +166,79 @@ func (l *LibraryElementService) getByNameHandler(c *models.ReqContext) response.
 	return response.JSON(http.StatusOK, LibraryElementArrayResponse{Result: elements})
 }
 
+// getVersionsHandler handles GET /api/library-elements/:uid/versions.
+func (l *LibraryElementService) getVersionsHandler(c *models.ReqContext) response.Response {
+	query := searchLibraryElementVersionsQuery{
+		limit: c.QueryInt("limit"),
+		start: c.QueryInt("start"),
+	}
+	versions, err := l.getLibraryElementVersions(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], query)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element versions")
+	}
+
+	return response.JSON(http.StatusOK, LibraryElementVersionsResponse{Result: versions})
+}
+
+// getVersionHandler handles GET /api/library-elements/:uid/versions/:version.
+func (l *LibraryElementService) getVersionHandler(c *models.ReqContext) response.Response {
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	elementVersion, err := l.getLibraryElementVersionByUID(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], version)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element version")
+	}
+
+	return response.JSON(http.StatusOK, LibraryElementVersionResponse{Result: elementVersion})
+}
+
+// diffVersionsHandler handles GET /api/library-elements/:uid/versions/:version/diff.
+// The version is compared to the version given by the base query parameter, by default the previous version.
+func (l *LibraryElementService) diffVersionsHandler(c *models.ReqContext) response.Response {
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+	base := c.QueryInt64("base")
+	if base == 0 {
+		base = version - 1
+	}
+
+	diffType := dashdiffs.ParseDiffType(c.Query("diffType"))
+	result, err := l.diffLibraryElementVersions(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], base, version, diffType)
+	if errors.Is(err, dashdiffs.ErrNilDiff) {
+		// identical versions
+		result, err = &dashdiffs.Result{Delta: []byte{}}, nil
+	}
+	if err != nil {
+		return toLibraryElementError(err, "Unable to compute diff")
+	}
+
+	if diffType == dashdiffs.DiffDelta {
+		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
+	}
+
+	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
+}
+
+// restoreVersionHandler handles POST /api/library-elements/:uid/versions/:version/restore.
+func (l *LibraryElementService) restoreVersionHandler(c *models.ReqContext) response.Response {
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	element, err := l.restoreLibraryElementVersion(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], version)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to restore library element version")
+	}
+
+	return response.JSON(http.StatusOK, LibraryElementResponse{Result: element})
+}
+
 func toLibraryElementError(err error, message string) response.Response {
 	if errors.Is(err, errLibraryElementAlreadyExists) {
 		return response.Error(400, errLibraryElementAlreadyExists.Error(), err)
@@ -170,6 +249,9 @@ func toLibraryElementError(err error, message string) response.Response {
 	if errors.Is(err, errLibraryElementDashboardNotFound) {
 		return response.Error(404, errLibraryElementDashboardNotFound.Error(), err)
 	}
+	if errors.Is(err, errLibraryElementVersionNotFound) {
+		return response.Error(404, errLibraryElementVersionNotFound.Error(), err)
+	}
 	if errors.Is(err, errLibraryElementVersionMismatch) {
 		return response.Error(412, errLibraryElementVersionMismatch.Error(), err)
 	}
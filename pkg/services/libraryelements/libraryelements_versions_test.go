@@ -0,0 +1,143 @@
+package libraryelements
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestLibraryElementVersions(t *testing.T) {
+	patchPanel := func(t *testing.T, sc scenarioContext, version int64, model string) {
+		t.Helper()
+		cmd := PatchLibraryElementCommand{
+			FolderID: -1,
+			Model:    []byte(model),
+			Kind:     int64(models.PanelElement),
+			Version:  version,
+		}
+		sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+		sc.reqContext.Req.Body = mockRequestBody(cmd)
+		resp := sc.service.patchHandler(sc.reqContext)
+		require.Equal(t, 200, resp.Status())
+	}
+
+	getVersions := func(t *testing.T, sc scenarioContext) []LibraryElementVersionDTO {
+		t.Helper()
+		sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+		err := sc.reqContext.Req.ParseForm()
+		require.NoError(t, err)
+		resp := sc.service.getVersionsHandler(sc.reqContext)
+		require.Equal(t, 200, resp.Status())
+		var result LibraryElementVersionsResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		return result.Result
+	}
+
+	getVersion := func(t *testing.T, sc scenarioContext, version string) response.Response {
+		t.Helper()
+		sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": version})
+		return sc.service.getVersionHandler(sc.reqContext)
+	}
+
+	scenarioWithPanel(t, "When an admin patches a library panel, it should keep the versions with the affected dashboards",
+		func(t *testing.T, sc scenarioContext) {
+			dash := models.Dashboard{
+				Title: "Testing versions",
+				Data:  simplejson.NewFromAny(map[string]interface{}{"panels": []interface{}{}}),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+			err := sc.service.ConnectElementsToDashboard(context.Background(), sc.reqContext.SignedInUser, []string{sc.initialResult.Result.UID}, dashInDB.Id)
+			require.NoError(t, err)
+
+			patchPanel(t, sc, 1, `{"id": 1, "title": "Text - Library Panel", "type": "text", "description": "An updated description"}`)
+
+			versions := getVersions(t, sc)
+			require.Len(t, versions, 2)
+			require.Equal(t, int64(2), versions[0].Version)
+			require.Equal(t, int64(1), versions[0].ConnectedDashboards)
+			require.Nil(t, versions[0].Model)
+			require.Equal(t, int64(1), versions[1].Version)
+			require.Equal(t, int64(0), versions[1].ConnectedDashboards)
+
+			resp := getVersion(t, sc, "2")
+			require.Equal(t, 200, resp.Status())
+			var result LibraryElementVersionResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &result))
+			require.Equal(t, "An updated description", result.Result.Description)
+			require.Equal(t, []LibraryElementAffectedDashboardDTO{{ID: dashInDB.Id, UID: dashInDB.Uid, Title: "Testing versions"}}, result.Result.AffectedDashboards)
+			require.Equal(t, userInDbName, result.Result.CreatedBy.Name)
+
+			resp = getVersion(t, sc, "3")
+			require.Equal(t, 404, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin diffs two versions of a library panel, it should return the diff of the models",
+		func(t *testing.T, sc scenarioContext) {
+			patchPanel(t, sc, 1, `{"id": 1, "title": "Text - Library Panel", "type": "text", "description": "An updated description"}`)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "2"})
+			err := sc.reqContext.Req.ParseForm()
+			require.NoError(t, err)
+			sc.reqContext.Req.Form.Add("diffType", "delta")
+			resp := sc.service.diffVersionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			var delta map[string]interface{}
+			require.NoError(t, json.Unmarshal(resp.Body(), &delta))
+			require.Contains(t, delta, "description")
+			require.NotContains(t, delta, "title")
+		})
+
+	scenarioWithPanel(t, "When an admin restores a version of a library panel, it should save it as a new version",
+		func(t *testing.T, sc scenarioContext) {
+			patchPanel(t, sc, 1, `{"id": 1, "title": "Text - Library Panel", "type": "graph", "description": "A broken edit"}`)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "1"})
+			resp := sc.service.restoreVersionHandler(sc.reqContext)
+			result := validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, int64(3), result.Result.Version)
+			require.Equal(t, "text", result.Result.Type)
+			require.Equal(t, "A description", result.Result.Description)
+
+			versions := getVersions(t, sc)
+			require.Len(t, versions, 3)
+			require.Equal(t, int64(1), versions[0].RestoredFrom)
+		})
+
+	scenarioWithPanel(t, "When an admin patches a library panel without history, it should keep the previous version",
+		func(t *testing.T, sc scenarioContext) {
+			err := sc.sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+				_, err := sess.Exec("DELETE FROM library_element_version")
+				return err
+			})
+			require.NoError(t, err)
+
+			patchPanel(t, sc, 1, `{"id": 1, "title": "Text - Library Panel", "type": "text", "description": "An updated description"}`)
+
+			versions := getVersions(t, sc)
+			require.Len(t, versions, 2)
+			require.Equal(t, "A description", versions[1].Description)
+		})
+
+	scenarioWithPanel(t, "When an admin deletes a library panel, it should delete its versions",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			err := sc.sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+				count, err := sess.Table("library_element_version").Count()
+				require.Equal(t, int64(0), count)
+				return err
+			})
+			require.NoError(t, err)
+		})
+}
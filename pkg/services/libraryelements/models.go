@@ -135,6 +135,67 @@ type LibraryElementConnectionDTO struct {
 	CreatedBy    LibraryElementDTOMetaUser `json:"createdBy"`
 }
 
+// libraryElementVersion is the model for the versions of a library element.
+type libraryElementVersion struct {
+	ID           int64 `xorm:"pk autoincr 'id'"`
+	OrgID        int64 `xorm:"org_id"`
+	ElementID    int64 `xorm:"element_id"`
+	Version      int64
+	FolderID     int64 `xorm:"folder_id"`
+	Name         string
+	Description  string
+	Model        json.RawMessage
+	RestoredFrom int64
+	// ConnectedDashboards are the ids of the dashboards connected to the element when the version was saved
+	ConnectedDashboards []int64
+	Created             time.Time
+	CreatedBy           int64
+}
+
+// libraryElementVersionWithMeta is the model used to retrieve versions with additional meta information.
+type libraryElementVersionWithMeta struct {
+	ID                  int64 `xorm:"pk autoincr 'id'"`
+	OrgID               int64 `xorm:"org_id"`
+	ElementID           int64 `xorm:"element_id"`
+	Version             int64
+	FolderID            int64 `xorm:"folder_id"`
+	Name                string
+	Description         string
+	Model               json.RawMessage
+	RestoredFrom        int64
+	ConnectedDashboards []int64
+	Created             time.Time
+	CreatedBy           int64
+	CreatedByName       string
+	CreatedByEmail      string
+}
+
+// LibraryElementVersionDTO is the frontend DTO for the versions of a library element.
+type LibraryElementVersionDTO struct {
+	ID           int64           `json:"id"`
+	ElementID    int64           `json:"elementId"`
+	Version      int64           `json:"version"`
+	FolderID     int64           `json:"folderId"`
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	Model        json.RawMessage `json:"model,omitempty"`
+	RestoredFrom int64           `json:"restoredFrom,omitempty"`
+	// ConnectedDashboards is the number of dashboards connected to the element when the version was saved.
+	ConnectedDashboards int64 `json:"connectedDashboards"`
+	// AffectedDashboards are the dashboards connected to the element when the version was saved, only
+	// returned for a single version.
+	AffectedDashboards []LibraryElementAffectedDashboardDTO `json:"affectedDashboards,omitempty"`
+	Created            time.Time                            `json:"created"`
+	CreatedBy          LibraryElementDTOMetaUser            `json:"createdBy"`
+}
+
+// LibraryElementAffectedDashboardDTO is a dashboard using a version of a library element.
+type LibraryElementAffectedDashboardDTO struct {
+	ID    int64  `json:"id"`
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+}
+
 var (
 	// errLibraryElementAlreadyExists is an error for when the user tries to add a library element that already exists.
 	errLibraryElementAlreadyExists = errors.New("library element with that name or UID already exists")
@@ -154,6 +215,8 @@ var (
 	errLibraryElementInvalidUID = errors.New("uid contains illegal characters")
 	// errLibraryElementUIDTooLong is an error for when the uid of a library element is invalid
 	errLibraryElementUIDTooLong = errors.New("uid too long, max 40 characters")
+	// errLibraryElementVersionNotFound is an error for when a version of a library element can't be found.
+	errLibraryElementVersionNotFound = errors.New("library element version could not be found")
 )
 
 // Commands
@@ -200,6 +263,15 @@ type PatchLibraryElementCommand struct {
 	Version int64 `json:"version" binding:"Required"`
 	// required: false
 	UID string `json:"uid"`
+
+	// restoredFrom is the version restored by the patch
+	restoredFrom int64
+}
+
+// searchLibraryElementVersionsQuery is the query used for listing the versions of an element
+type searchLibraryElementVersionsQuery struct {
+	limit int
+	start int
 }
 
 // searchLibraryElementsQuery is the query used for searching for Elements
@@ -234,6 +306,16 @@ type LibraryElementConnectionsResponse struct {
 	Result []LibraryElementConnectionDTO `json:"result"`
 }
 
+// LibraryElementVersionResponse is a response struct for LibraryElementVersionDTO.
+type LibraryElementVersionResponse struct {
+	Result LibraryElementVersionDTO `json:"result"`
+}
+
+// LibraryElementVersionsResponse is a response struct for an array of LibraryElementVersionDTO.
+type LibraryElementVersionsResponse struct {
+	Result []LibraryElementVersionDTO `json:"result"`
+}
+
 // DeleteLibraryElementResponse is the response struct for deleting a library element.
 type DeleteLibraryElementResponse struct {
 	ID      int64  `json:"id"`
@@ -0,0 +1,267 @@
+package libraryelements
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+const selectLibraryElementVersionWithMeta = `
+SELECT lev.id, lev.org_id, lev.element_id, lev.version, lev.folder_id, lev.name, lev.description, lev.model, lev.restored_from, lev.connected_dashboards, lev.created, lev.created_by
+	, u1.login AS created_by_name
+	, u1.email AS created_by_email
+FROM library_element_version AS lev
+`
+
+// writeLibraryElementVersion adds a version to the history of a library element, with the dashboards connected to the element.
+func writeLibraryElementVersion(session *sqlstore.DBSession, element LibraryElement, restoredFrom int64, created time.Time, createdBy int64) error {
+	var connections []struct {
+		ConnectionID int64 `xorm:"connection_id"`
+	}
+	sql := "SELECT connection_id FROM " + models.LibraryElementConnectionTableName + " WHERE element_id=? AND kind=1"
+	if err := session.SQL(sql, element.ID).Find(&connections); err != nil {
+		return err
+	}
+	connectedDashboards := make([]int64, 0, len(connections))
+	for _, connection := range connections {
+		connectedDashboards = append(connectedDashboards, connection.ConnectionID)
+	}
+
+	version := libraryElementVersion{
+		OrgID:               element.OrgID,
+		ElementID:           element.ID,
+		Version:             element.Version,
+		FolderID:            element.FolderID,
+		Name:                element.Name,
+		Description:         element.Description,
+		Model:               element.Model,
+		RestoredFrom:        restoredFrom,
+		ConnectedDashboards: connectedDashboards,
+		Created:             created,
+		CreatedBy:           createdBy,
+	}
+	_, err := session.Insert(&version)
+	return err
+}
+
+// ensureLibraryElementVersion adds the current version of a library element to its history when missing,
+// which is the case for elements last changed before the history was kept.
+func ensureLibraryElementVersion(session *sqlstore.DBSession, element LibraryElementWithMeta) error {
+	exists, err := session.Table("library_element_version").Where("element_id=? AND version=?", element.ID, element.Version).Exist()
+	if err != nil || exists {
+		return err
+	}
+
+	return writeLibraryElementVersion(session, LibraryElement{
+		ID:          element.ID,
+		OrgID:       element.OrgID,
+		FolderID:    element.FolderID,
+		Name:        element.Name,
+		Description: element.Description,
+		Model:       element.Model,
+		Version:     element.Version,
+	}, 0, element.Updated, element.UpdatedBy)
+}
+
+func getLibraryElementVersion(dialect migrator.Dialect, session *sqlstore.DBSession, elementID int64, version int64) (libraryElementVersionWithMeta, error) {
+	versions := make([]libraryElementVersionWithMeta, 0)
+	sql := selectLibraryElementVersionWithMeta +
+		"LEFT JOIN " + dialect.Quote("user") + " AS u1 ON lev.created_by = u1.id" +
+		" WHERE lev.element_id=? AND lev.version=?"
+	if err := session.SQL(sql, elementID, version).Find(&versions); err != nil {
+		return libraryElementVersionWithMeta{}, err
+	}
+	if len(versions) == 0 {
+		return libraryElementVersionWithMeta{}, errLibraryElementVersionNotFound
+	}
+
+	return versions[0], nil
+}
+
+func toLibraryElementVersionDTO(version libraryElementVersionWithMeta) LibraryElementVersionDTO {
+	return LibraryElementVersionDTO{
+		ID:                  version.ID,
+		ElementID:           version.ElementID,
+		Version:             version.Version,
+		FolderID:            version.FolderID,
+		Name:                version.Name,
+		Description:         version.Description,
+		Model:               version.Model,
+		RestoredFrom:        version.RestoredFrom,
+		ConnectedDashboards: int64(len(version.ConnectedDashboards)),
+		Created:             version.Created,
+		CreatedBy: LibraryElementDTOMetaUser{
+			ID:        version.CreatedBy,
+			Name:      version.CreatedByName,
+			AvatarURL: dtos.GetGravatarUrl(version.CreatedByEmail),
+		},
+	}
+}
+
+// getLibraryElementVersions gets the versions of a Library Element, latest first.
+func (l *LibraryElementService) getLibraryElementVersions(c context.Context, signedInUser *models.SignedInUser, uid string, query searchLibraryElementVersionsQuery) ([]LibraryElementVersionDTO, error) {
+	if query.limit <= 0 {
+		query.limit = 100
+	}
+	if query.start < 0 {
+		query.start = 0
+	}
+
+	versions := make([]LibraryElementVersionDTO, 0)
+	err := l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		element, err := getLibraryElement(l.SQLStore.Dialect, session, uid, signedInUser.OrgId)
+		if err != nil {
+			return err
+		}
+		if err := l.requireViewPermissionsOnFolder(c, signedInUser, element.FolderID); err != nil {
+			return err
+		}
+
+		var libraryElementVersions []libraryElementVersionWithMeta
+		builder := sqlstore.SQLBuilder{}
+		builder.Write(selectLibraryElementVersionWithMeta)
+		builder.Write("LEFT JOIN " + l.SQLStore.Dialect.Quote("user") + " AS u1 ON lev.created_by = u1.id")
+		builder.Write(" WHERE lev.element_id=?", element.ID)
+		builder.Write(" ORDER BY lev.version DESC")
+		builder.Write(l.SQLStore.Dialect.LimitOffset(int64(query.limit), int64(query.start)))
+		if err := session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&libraryElementVersions); err != nil {
+			return err
+		}
+
+		for _, version := range libraryElementVersions {
+			dto := toLibraryElementVersionDTO(version)
+			dto.Model = nil
+			versions = append(versions, dto)
+		}
+		return nil
+	})
+
+	return versions, err
+}
+
+// getLibraryElementVersionByUID gets a version of a Library Element, with the dashboards it affected.
+func (l *LibraryElementService) getLibraryElementVersionByUID(c context.Context, signedInUser *models.SignedInUser, uid string, version int64) (LibraryElementVersionDTO, error) {
+	var dto LibraryElementVersionDTO
+	err := l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		element, err := getLibraryElement(l.SQLStore.Dialect, session, uid, signedInUser.OrgId)
+		if err != nil {
+			return err
+		}
+		if err := l.requireViewPermissionsOnFolder(c, signedInUser, element.FolderID); err != nil {
+			return err
+		}
+
+		elementVersion, err := getLibraryElementVersion(l.SQLStore.Dialect, session, element.ID, version)
+		if err != nil {
+			return err
+		}
+		dto = toLibraryElementVersionDTO(elementVersion)
+		dto.AffectedDashboards = make([]LibraryElementAffectedDashboardDTO, 0)
+		if len(elementVersion.ConnectedDashboards) == 0 {
+			return nil
+		}
+
+		var dashboards []struct {
+			ID    int64  `xorm:"id"`
+			UID   string `xorm:"uid"`
+			Title string
+		}
+		params := make([]interface{}, 0, len(elementVersion.ConnectedDashboards)+1)
+		params = append(params, signedInUser.OrgId)
+		for _, id := range elementVersion.ConnectedDashboards {
+			params = append(params, id)
+		}
+		builder := sqlstore.SQLBuilder{}
+		builder.Write("SELECT dashboard.id, dashboard.uid, dashboard.title FROM dashboard")
+		builder.Write(" WHERE dashboard.org_id=? AND dashboard.id IN (?"+strings.Repeat(",?", len(elementVersion.ConnectedDashboards)-1)+")", params...)
+		if signedInUser.OrgRole != models.ROLE_ADMIN {
+			builder.WriteDashboardPermissionFilter(signedInUser, models.PERMISSION_VIEW)
+		}
+		builder.Write(" ORDER BY dashboard.title ASC")
+		if err := session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&dashboards); err != nil {
+			return err
+		}
+
+		for _, dashboard := range dashboards {
+			dto.AffectedDashboards = append(dto.AffectedDashboards, LibraryElementAffectedDashboardDTO{
+				ID:    dashboard.ID,
+				UID:   dashboard.UID,
+				Title: dashboard.Title,
+			})
+		}
+		return nil
+	})
+
+	return dto, err
+}
+
+// restoreLibraryElementVersion restores a version of a Library Element, saving it as a new version.
+func (l *LibraryElementService) restoreLibraryElementVersion(c context.Context, signedInUser *models.SignedInUser, uid string, version int64) (LibraryElementDTO, error) {
+	var cmd PatchLibraryElementCommand
+	err := l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		element, err := getLibraryElement(l.SQLStore.Dialect, session, uid, signedInUser.OrgId)
+		if err != nil {
+			return err
+		}
+		elementVersion, err := getLibraryElementVersion(l.SQLStore.Dialect, session, element.ID, version)
+		if err != nil {
+			return err
+		}
+
+		cmd = PatchLibraryElementCommand{
+			FolderID:     -1,
+			Name:         elementVersion.Name,
+			Model:        elementVersion.Model,
+			Kind:         element.Kind,
+			Version:      element.Version,
+			restoredFrom: version,
+		}
+		return nil
+	})
+	if err != nil {
+		return LibraryElementDTO{}, err
+	}
+
+	return l.patchLibraryElement(c, signedInUser, cmd, uid)
+}
+
+// diffLibraryElementVersions computes the diff of the models of two versions of a Library Element.
+func (l *LibraryElementService) diffLibraryElementVersions(c context.Context, signedInUser *models.SignedInUser, uid string, baseVersion int64, newVersion int64, diffType dashdiffs.DiffType) (*dashdiffs.Result, error) {
+	var baseModel, newModel *simplejson.Json
+	err := l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		element, err := getLibraryElement(l.SQLStore.Dialect, session, uid, signedInUser.OrgId)
+		if err != nil {
+			return err
+		}
+		if err := l.requireViewPermissionsOnFolder(c, signedInUser, element.FolderID); err != nil {
+			return err
+		}
+
+		base, err := getLibraryElementVersion(l.SQLStore.Dialect, session, element.ID, baseVersion)
+		if err != nil {
+			return err
+		}
+		if baseModel, err = simplejson.NewJson(base.Model); err != nil {
+			return err
+		}
+
+		compared, err := getLibraryElementVersion(l.SQLStore.Dialect, session, element.ID, newVersion)
+		if err != nil {
+			return err
+		}
+		newModel, err = simplejson.NewJson(compared.Model)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dashdiffs.CalculateDiff(c, &dashdiffs.Options{OrgId: signedInUser.OrgId, DiffType: diffType}, baseModel, newModel)
+}
@@ -2,6 +2,7 @@ package features
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -13,10 +14,20 @@ import (
 // CommentHandler manages all the `grafana/comment/*` channels.
 type CommentHandler struct {
 	permissionChecker *commentmodel.PermissionChecker
+	publisher         models.ChannelPublisher
 }
 
-func NewCommentHandler(permissionChecker *commentmodel.PermissionChecker) *CommentHandler {
-	return &CommentHandler{permissionChecker: permissionChecker}
+func NewCommentHandler(permissionChecker *commentmodel.PermissionChecker, publisher models.ChannelPublisher) *CommentHandler {
+	return &CommentHandler{permissionChecker: permissionChecker, publisher: publisher}
+}
+
+// PublishEvent broadcasts a comment event to the subscribers of the comments of an object.
+func (h *CommentHandler) PublishEvent(orgID int64, objectType string, objectID string, event commentmodel.Event) error {
+	msg, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return h.publisher(orgID, "grafana/comment/"+objectType+"/"+objectID, msg)
 }
 
 // GetHandlerForPath called on init.
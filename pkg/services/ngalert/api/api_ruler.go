@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -39,6 +40,7 @@ type RulerSrv struct {
 	log             log.Logger
 	cfg             *setting.UnifiedAlertingSettings
 	ac              accesscontrol.AccessControl
+	auditService    audit.Service
 }
 
 var (
@@ -152,6 +154,10 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *models.ReqContext) response.Respons
 			OrgID: c.SignedInUser.OrgId,
 			UID:   uid,
 		})
+		srv.logAuditEvent(c, audit.NewEvent(c, audit.ActionAlertRuleDelete, audit.KindAlertRule, uid).WithChange(util.DynMap{
+			"namespaceUid": namespace.Uid,
+			"ruleGroup":    ruleGroup,
+		}, nil))
 	}
 
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rules deleted"})
@@ -445,9 +451,20 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *models.ReqContext, groupKey ngmod
 		return response.JSON(http.StatusAccepted, util.DynMap{"message": "no changes detected in the rule group"})
 	}
 
+	srv.logAuditEvent(c, audit.NewEvent(c, audit.ActionAlertRuleGroupUpdate, audit.KindAlertRuleGroup, groupKey.NamespaceUID+"/"+groupKey.RuleGroup).
+		WithChange(finalChanges.auditSummary()))
+
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
 }
 
+// logAuditEvent records the event if the audit log is configured.
+func (srv RulerSrv) logAuditEvent(c *models.ReqContext, event *audit.Event) {
+	if srv.auditService == nil {
+		return
+	}
+	srv.auditService.Log(c.Req.Context(), event)
+}
+
 func toGettableRuleGroupConfig(groupName string, rules []*ngmodels.AlertRule, namespaceID int64, provenanceRecords map[string]ngmodels.Provenance) apimodels.GettableRuleGroupConfig {
 	ruleNodes := make([]apimodels.GettableExtendedRuleNode, 0, len(rules))
 	var interval time.Duration
@@ -526,6 +543,23 @@ func (c *changes) isEmpty() bool {
 	return len(c.Update)+len(c.New)+len(c.Delete) == 0
 }
 
+// auditSummary returns the state of the changed rules before and after the changes are applied.
+func (c *changes) auditSummary() (before, after []ngmodels.AlertRule) {
+	before = make([]ngmodels.AlertRule, 0, len(c.Update)+len(c.Delete))
+	after = make([]ngmodels.AlertRule, 0, len(c.Update)+len(c.New))
+	for _, update := range c.Update {
+		before = append(before, *update.Existing)
+		after = append(after, *update.New)
+	}
+	for _, rule := range c.Delete {
+		before = append(before, *rule)
+	}
+	for _, rule := range c.New {
+		after = append(after, *rule)
+	}
+	return before, after
+}
+
 // verifyProvisionedRulesNotAffected check that neither of provisioned alerts are affected by changes.
 // Returns errProvisionedResource if there is at least one rule in groups affected by changes that was provisioned.
 func verifyProvisionedRulesNotAffected(ctx context.Context, provenanceStore provisioning.ProvisioningStore, orgID int64, ch *changes) error {
@@ -128,3 +128,5 @@ func (f fakeCacheService) GetDatasourceByUID(ctx context.Context, datasourceUID
 
 	return f.datasource, nil
 }
+
+func (f fakeCacheService) Invalidate(ds *models.DataSource) {}
@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -37,7 +38,7 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore, expressionService *expr.Service, dataProxy *datasourceproxy.DataSourceProxyService,
 	quotaService *quota.QuotaService, secretsService secrets.Service, notificationService notifications.Service, m *metrics.NGAlert,
 	folderService dashboards.FolderService, ac accesscontrol.AccessControl, dashboardService dashboards.DashboardService, renderService rendering.Service,
-	bus bus.Bus) (*AlertNG, error) {
+	bus bus.Bus, auditService audit.Service, dbStore *store.DBstore) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                 cfg,
 		DataSourceCache:     dataSourceCache,
@@ -56,6 +57,8 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		dashboardService:    dashboardService,
 		renderService:       renderService,
 		bus:                 bus,
+		auditService:        auditService,
+		store:               dbStore,
 	}
 
 	if ng.IsDisabled() {
@@ -94,21 +97,15 @@ type AlertNG struct {
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	accesscontrol        accesscontrol.AccessControl
 
-	bus bus.Bus
+	bus          bus.Bus
+	auditService audit.Service
+	store        *store.DBstore
 }
 
 func (ng *AlertNG) init() error {
 	var err error
 
-	store := &store.DBstore{
-		BaseInterval:     ng.Cfg.UnifiedAlerting.BaseInterval,
-		DefaultInterval:  ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval,
-		SQLStore:         ng.SQLStore,
-		Logger:           ng.Log,
-		FolderService:    ng.folderService,
-		AccessControl:    ng.accesscontrol,
-		DashboardService: ng.dashboardService,
-	}
+	store := ng.store
 
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
@@ -189,6 +186,7 @@ func (ng *AlertNG) init() error {
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
 		AlertRules:           alertRuleService,
+		AuditService:         ng.auditService,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -338,6 +338,9 @@ func (st DBstore) GetUserVisibleNamespaces(ctx context.Context, orgID int64, use
 		},
 	}
 
+	// the parents of the nested folders are fetched once, when the first nested folder is found
+	var parents func(parentID int64) []*models.Folder
+
 	var page int64 = 1
 	for {
 		query := searchQuery
@@ -355,10 +358,22 @@ func (st DBstore) GetUserVisibleNamespaces(ctx context.Context, orgID int64, use
 			if !hit.IsFolder {
 				continue
 			}
+			title := hit.Title
+			if hit.FolderID > 0 {
+				if parents == nil {
+					parents, err = st.visibleFolderParents(ctx, orgID, user)
+					if err != nil {
+						return nil, err
+					}
+				}
+				// nested folders are identified by the path of folder titles from their root folder
+				title = namespacePath(parents(hit.FolderID), hit.Title)
+			}
 			namespaceMap[hit.UID] = &models.Folder{
-				Id:    hit.ID,
-				Uid:   hit.UID,
-				Title: hit.Title,
+				Id:       hit.ID,
+				Uid:      hit.UID,
+				Title:    title,
+				ParentId: hit.FolderID,
 			}
 		}
 		page += 1
@@ -366,9 +381,74 @@ func (st DBstore) GetUserVisibleNamespaces(ctx context.Context, orgID int64, use
 	return namespaceMap, nil
 }
 
+// visibleFolderParents returns a function returning the parent folders a user can view of a folder, starting with its
+// root folder, given the ID of its parent. All the folders of the organization are fetched at once, rather than the
+// parents of every folder one by one.
+func (st DBstore) visibleFolderParents(ctx context.Context, orgID int64, user *models.SignedInUser) (func(parentID int64) []*models.Folder, error) {
+	var folders []*models.Dashboard
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ? AND is_folder = ?", orgID, st.SQLStore.Dialect.BooleanStr(true)).
+			Cols("id", "uid", "title", "folder_id").
+			Find(&folders)
+	})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*models.Dashboard, len(folders))
+	for _, f := range folders {
+		byID[f.Id] = f
+	}
+
+	visible := make(map[int64]bool)
+	for page := int64(1); ; page++ {
+		hits, err := st.DashboardService.FindDashboards(ctx, &models.FindPersistedDashboardsQuery{
+			OrgId:        orgID,
+			SignedInUser: user,
+			Type:         searchstore.TypeFolder,
+			Permission:   models.PERMISSION_VIEW,
+			Sort:         models.SortOption{},
+			Page:         page,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(hits) == 0 {
+			break
+		}
+		for _, hit := range hits {
+			visible[hit.ID] = true
+		}
+	}
+
+	return func(parentID int64) []*models.Folder {
+		var parents []*models.Folder
+		// the depth of the tree is limited, so a corrupt tree with a cycle cannot loop forever
+		for id, depth := parentID, 0; id > 0 && depth < models.MaxNestedFolderDepth; depth++ {
+			folder, ok := byID[id]
+			if !ok {
+				break
+			}
+			if visible[id] {
+				parents = append([]*models.Folder{{Id: folder.Id, Uid: folder.Uid, Title: folder.Title, ParentId: folder.FolderId}}, parents...)
+			}
+			id = folder.FolderId
+		}
+		return parents
+	}, nil
+}
+
+func namespacePath(parents []*models.Folder, title string) string {
+	titles := make([]string, 0, len(parents)+1)
+	for _, parent := range parents {
+		titles = append(titles, parent.Title)
+	}
+	return strings.Join(append(titles, title), models.FolderPathSeparator)
+}
+
 // GetNamespaceByTitle is a handler for retrieving a namespace by its title. Alerting rules follow a Grafana folder-like structure which we call namespaces.
+// The title of a nested folder is the path of folder titles from its root folder, for example "Team/Services".
 func (st DBstore) GetNamespaceByTitle(ctx context.Context, namespace string, orgID int64, user *models.SignedInUser, withCanSave bool) (*models.Folder, error) {
-	folder, err := st.FolderService.GetFolderByTitle(ctx, user, orgID, namespace)
+	folder, err := st.FolderService.GetFolderByPath(ctx, user, orgID, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 // TimeNow makes it possible to test usage of time
@@ -38,3 +39,16 @@ type DBstore struct {
 	AccessControl    accesscontrol.AccessControl
 	DashboardService dashboards.DashboardService
 }
+
+func ProvideDBStore(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, folderService dashboards.FolderService,
+	ac accesscontrol.AccessControl, dashboardService dashboards.DashboardService) *DBstore {
+	return &DBstore{
+		BaseInterval:     cfg.UnifiedAlerting.BaseInterval,
+		DefaultInterval:  cfg.UnifiedAlerting.DefaultRuleEvaluationInterval,
+		SQLStore:         sqlStore,
+		Logger:           log.New("ngalert.dbstore"),
+		FolderService:    folderService,
+		AccessControl:    ac,
+		DashboardService: dashboardService,
+	}
+}
@@ -62,9 +62,11 @@ func SetupTestEnv(t *testing.T, baseInterval time.Duration) (*ngalert.AlertNG, *
 		features, folderPermissions, ac, bus,
 	)
 
+	fakeDashboardService := &dashboards.FakeDashboardService{}
 	ng, err := ngalert.ProvideService(
 		cfg, nil, routing.NewRouteRegister(), sqlStore, nil, nil, nil, nil,
-		secretsService, nil, m, folderService, ac, &dashboards.FakeDashboardService{}, nil, bus,
+		secretsService, nil, m, folderService, ac, fakeDashboardService, nil, bus, nil,
+		store.ProvideDBStore(cfg, sqlStore, folderService, ac, fakeDashboardService),
 	)
 	require.NoError(t, err)
 	return ng, &store.DBstore{
@@ -0,0 +1,111 @@
+package datasources
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+// SecretsStore is the store the secrets of the provisioned datasources are refreshed through.
+type SecretsStore interface {
+	Store
+	DecryptedValues(ctx context.Context, ds *models.DataSource) (map[string]string, error)
+}
+
+// RefreshSecrets reads the provisioning config files again to resolve the secure json data referencing Vault secrets,
+// and updates the datasources whose secrets changed since they were provisioned, so that rotated secrets are used
+// without restarting Grafana. The other properties of the datasources are left untouched. It returns the updated
+// datasources.
+func RefreshSecrets(ctx context.Context, configDirectory string, store SecretsStore, orgStore utils.OrgStore) ([]*models.DataSource, error) {
+	logger := log.New("provisioning.datasources")
+	cr := &configReader{log: logger, orgStore: orgStore}
+	configs, err := cr.readConfig(ctx, configDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated []*models.DataSource
+	for _, cfg := range configs {
+		for _, ds := range cfg.Datasources {
+			if ds == nil || !referencesVault(ds.SecureJSONRaw) {
+				continue
+			}
+
+			query := &models.GetDataSourceQuery{OrgId: ds.OrgID, Name: ds.Name}
+			if err := store.GetDataSource(ctx, query); err != nil {
+				if errors.Is(err, models.ErrDataSourceNotFound) {
+					continue
+				}
+				return updated, err
+			}
+
+			current, err := store.DecryptedValues(ctx, query.Result)
+			if err != nil {
+				return updated, err
+			}
+			changed := make(map[string]string)
+			for key, raw := range ds.SecureJSONRaw {
+				if isVaultReference(raw) && current[key] != ds.SecureJSONData[key] {
+					changed[key] = ds.SecureJSONData[key]
+				}
+			}
+			if len(changed) == 0 {
+				continue
+			}
+
+			cmd := secretsUpdateCommand(query.Result, changed)
+			if err := store.UpdateDataSource(ctx, cmd); err != nil {
+				// another server of a high availability setup updated the datasource first
+				if errors.Is(err, models.ErrDataSourceUpdatingOldVersion) {
+					continue
+				}
+				return updated, err
+			}
+			logger.Info("updated the secrets of a datasource", "name", ds.Name, "uid", query.Result.Uid, "orgId", ds.OrgID)
+			updated = append(updated, cmd.Result)
+		}
+	}
+
+	return updated, nil
+}
+
+func referencesVault(raw map[string]string) bool {
+	for _, v := range raw {
+		if isVaultReference(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func isVaultReference(raw string) bool {
+	return strings.Contains(raw, "$__vault{")
+}
+
+// secretsUpdateCommand returns the command updating secure json data of a datasource, the values of secure json data
+// which are not in secureJSONData are kept.
+func secretsUpdateCommand(ds *models.DataSource, secureJSONData map[string]string) *models.UpdateDataSourceCommand {
+	return &models.UpdateDataSourceCommand{
+		Id:              ds.Id,
+		Uid:             ds.Uid,
+		OrgId:           ds.OrgId,
+		Name:            ds.Name,
+		Type:            ds.Type,
+		Access:          ds.Access,
+		Url:             ds.Url,
+		User:            ds.User,
+		Database:        ds.Database,
+		BasicAuth:       ds.BasicAuth,
+		BasicAuthUser:   ds.BasicAuthUser,
+		WithCredentials: ds.WithCredentials,
+		IsDefault:       ds.IsDefault,
+		JsonData:        ds.JsonData,
+		SecureJsonData:  secureJSONData,
+		ReadOnly:        ds.ReadOnly,
+		Version:         ds.Version,
+	}
+}
@@ -0,0 +1,109 @@
+package datasources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type secretsSpyStore struct {
+	spyStore
+	decrypted map[string]string
+}
+
+func (s *secretsSpyStore) DecryptedValues(ctx context.Context, ds *models.DataSource) (map[string]string, error) {
+	return s.decrypted, nil
+}
+
+func (s *secretsSpyStore) UpdateDataSource(ctx context.Context, cmd *models.UpdateDataSourceCommand) error {
+	cmd.Result = &models.DataSource{Id: cmd.Id, Uid: cmd.Uid, OrgId: cmd.OrgId, Name: cmd.Name}
+	return s.spyStore.UpdateDataSource(ctx, cmd)
+}
+
+func TestRefreshSecrets(t *testing.T) {
+	var mu sync.Mutex
+	password := "first"
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("X-Vault-Token") != "root" || r.URL.Path != "/v1/secret/data/grafana/loki" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"password":"` + password + `"}}}`))
+	}))
+	t.Cleanup(vault.Close)
+
+	cfg := setting.NewCfg()
+	err := cfg.Load(setting.CommandLineArgs{
+		HomePath: "../../../../",
+		Args: []string{
+			"cfg:keystore.vault.url=" + vault.URL,
+			"cfg:keystore.vault.auth_method=token",
+			"cfg:keystore.vault.token=root",
+			"cfg:paths.data=" + t.TempDir(),
+			"cfg:paths.logs=" + t.TempDir(),
+		},
+	})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "datasources.yaml"), []byte(`apiVersion: 1
+
+datasources:
+  - name: Loki
+    type: loki
+    version: 2
+    secureJsonData:
+      basicAuthPassword: $__vault{kv:secret/grafana/loki:password}
+      httpHeaderValue1: static
+  - name: Prometheus
+    type: prometheus
+    secureJsonData:
+      basicAuthPassword: static
+`), 0600))
+
+	store := &secretsSpyStore{
+		spyStore: spyStore{items: []*models.DataSource{
+			{Id: 1, Uid: "loki", OrgId: 1, Name: "Loki", Type: "loki", Access: models.DS_ACCESS_PROXY, Url: "http://loki:3100", Version: 3},
+			{Id: 2, Uid: "prometheus", OrgId: 1, Name: "Prometheus", Type: "prometheus"},
+		}},
+		decrypted: map[string]string{"basicAuthPassword": "first", "httpHeaderValue1": "static"},
+	}
+	orgStore := &mockOrgStore{ExpectedOrg: &models.Org{Id: 1}}
+
+	t.Run("Datasources whose secrets didn't change should not be updated", func(t *testing.T) {
+		updated, err := RefreshSecrets(context.Background(), dir, store, orgStore)
+		require.NoError(t, err)
+		require.Empty(t, updated)
+		require.Empty(t, store.updated)
+	})
+
+	t.Run("Only the rotated secrets of the datasources should be updated", func(t *testing.T) {
+		mu.Lock()
+		password = "second"
+		mu.Unlock()
+
+		updated, err := RefreshSecrets(context.Background(), dir, store, orgStore)
+		require.NoError(t, err)
+		require.Len(t, updated, 1)
+		require.Equal(t, "loki", updated[0].Uid)
+
+		require.Len(t, store.updated, 1)
+		cmd := store.updated[0]
+		require.Equal(t, map[string]string{"basicAuthPassword": "second"}, cmd.SecureJsonData)
+		require.Equal(t, "http://loki:3100", cmd.Url)
+		require.Equal(t, models.DsAccess(models.DS_ACCESS_PROXY), cmd.Access)
+		// the version of the datasource in the database guards against concurrent updates
+		require.Equal(t, 3, cmd.Version)
+	})
+}
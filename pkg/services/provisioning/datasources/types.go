@@ -46,6 +46,9 @@ type upsertDataSourceFromConfig struct {
 	SecureJSONData  map[string]string
 	Editable        bool
 	UID             string
+
+	// SecureJSONRaw are the values of SecureJSONData before their interpolation
+	SecureJSONRaw map[string]string
 }
 
 type configsV0 struct {
@@ -137,6 +140,7 @@ func (cfg *configsV1) mapToDatasourceFromConfig(apiVersion int64) *configs {
 			Editable:        ds.Editable.Value(),
 			Version:         ds.Version.Value(),
 			UID:             ds.UID.Value(),
+			SecureJSONRaw:   ds.SecureJSONData.Raw,
 		})
 	}
 
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
 	plugifaces "github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/alerting"
@@ -27,7 +29,7 @@ import (
 func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginStore plugifaces.Store,
 	encryptionService encryption.Internal, notificatonService *notifications.NotificationService,
 	dashboardProvisioningService dashboardservice.DashboardProvisioningService,
-	datasourceService datasourceservice.DataSourceService,
+	datasourceService datasourceservice.DataSourceService, datasourceCache datasourceservice.CacheService,
 	dashboardService dashboardservice.DashboardService,
 	alertingService *alerting.AlertNotificationService, pluginSettings pluginsettings.Service,
 ) (*ProvisioningServiceImpl, error) {
@@ -41,10 +43,12 @@ func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginStore p
 		newDashboardProvisioner:      dashboards.New,
 		provisionNotifiers:           notifiers.Provision,
 		provisionDatasources:         datasources.Provision,
+		refreshDatasourceSecrets:     datasources.RefreshSecrets,
 		provisionPlugins:             plugins.Provision,
 		dashboardProvisioningService: dashboardProvisioningService,
 		dashboardService:             dashboardService,
 		datasourceService:            datasourceService,
+		datasourceCache:              datasourceCache,
 		alertingService:              alertingService,
 		pluginsSettings:              pluginSettings,
 	}
@@ -65,11 +69,12 @@ type ProvisioningService interface {
 // Add a public constructor for overriding service to be able to instantiate OSS as fallback
 func NewProvisioningServiceImpl() *ProvisioningServiceImpl {
 	return &ProvisioningServiceImpl{
-		log:                     log.New("provisioning"),
-		newDashboardProvisioner: dashboards.New,
-		provisionNotifiers:      notifiers.Provision,
-		provisionDatasources:    datasources.Provision,
-		provisionPlugins:        plugins.Provision,
+		log:                      log.New("provisioning"),
+		newDashboardProvisioner:  dashboards.New,
+		provisionNotifiers:       notifiers.Provision,
+		provisionDatasources:     datasources.Provision,
+		refreshDatasourceSecrets: datasources.RefreshSecrets,
+		provisionPlugins:         plugins.Provision,
 	}
 }
 
@@ -101,11 +106,13 @@ type ProvisioningServiceImpl struct {
 	dashboardProvisioner         dashboards.DashboardProvisioner
 	provisionNotifiers           func(context.Context, string, notifiers.Manager, notifiers.SQLStore, encryption.Internal, *notifications.NotificationService) error
 	provisionDatasources         func(context.Context, string, datasources.Store, utils.OrgStore) error
+	refreshDatasourceSecrets     func(context.Context, string, datasources.SecretsStore, utils.OrgStore) ([]*models.DataSource, error)
 	provisionPlugins             func(context.Context, string, plugins.Store, plugifaces.Store, pluginsettings.Service) error
 	mutex                        sync.Mutex
 	dashboardProvisioningService dashboardservice.DashboardProvisioningService
 	dashboardService             dashboardservice.DashboardService
 	datasourceService            datasourceservice.DataSourceService
+	datasourceCache              datasourceservice.CacheService
 	alertingService              *alerting.AlertNotificationService
 	pluginsSettings              pluginsettings.Service
 }
@@ -130,6 +137,10 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 }
 
 func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
+	if ps.Cfg.Vault.Enabled() && ps.Cfg.Vault.SecretsRefreshInterval > 0 {
+		go ps.pollDatasourceSecrets(ctx)
+	}
+
 	err := ps.ProvisionDashboards(ctx)
 	if err != nil {
 		ps.log.Error("Failed to provision dashboard", "error", err)
@@ -168,6 +179,38 @@ func (ps *ProvisioningServiceImpl) ProvisionDatasources(ctx context.Context) err
 	return nil
 }
 
+// RefreshDatasourceSecrets resolves the Vault secrets of the provisioned datasources again, updates the datasources
+// whose secrets were rotated and removes them from the datasource cache.
+func (ps *ProvisioningServiceImpl) RefreshDatasourceSecrets(ctx context.Context) error {
+	datasourcePath := filepath.Join(ps.Cfg.ProvisioningPath, "datasources")
+	updated, err := ps.refreshDatasourceSecrets(ctx, datasourcePath, ps.datasourceService, ps.SQLStore)
+	// the datasources updated before an error are invalidated too
+	for _, ds := range updated {
+		ps.datasourceCache.Invalidate(ds)
+	}
+	if err != nil {
+		err = fmt.Errorf("%v: %w", "Datasource secrets refresh error", err)
+		ps.log.Error("Failed to refresh the secrets of data sources", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (ps *ProvisioningServiceImpl) pollDatasourceSecrets(ctx context.Context) {
+	ticker := time.NewTicker(ps.Cfg.Vault.SecretsRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// errors are logged, the secrets are refreshed again on the next tick
+			_ = ps.RefreshDatasourceSecrets(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
 	appPath := filepath.Join(ps.Cfg.ProvisioningPath, "plugins")
 	if err := ps.provisionPlugins(ctx, appPath, ps.SQLStore, ps.pluginStore, ps.pluginsSettings); err != nil {
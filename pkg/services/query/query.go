@@ -16,6 +16,8 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/limiter"
+	"github.com/grafana/grafana/pkg/services/datasources/usage"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
@@ -39,6 +41,8 @@ func ProvideService(
 	dataSourceService datasources.DataSourceService,
 	pluginClient plugins.Client,
 	oAuthTokenService oauthtoken.OAuthTokenService,
+	dataSourceLimiter *limiter.Service,
+	dataSourceUsage *usage.Service,
 ) *Service {
 	g := &Service{
 		cfg:                    cfg,
@@ -48,6 +52,8 @@ func ProvideService(
 		dataSourceService:      dataSourceService,
 		pluginClient:           pluginClient,
 		oAuthTokenService:      oAuthTokenService,
+		dataSourceLimiter:      dataSourceLimiter,
+		dataSourceUsage:        dataSourceUsage,
 		log:                    log.New("query_data"),
 	}
 	g.log.Info("Query Service initialization")
@@ -62,6 +68,8 @@ type Service struct {
 	dataSourceService      datasources.DataSourceService
 	pluginClient           plugins.Client
 	oAuthTokenService      oauthtoken.OAuthTokenService
+	dataSourceLimiter      *limiter.Service
+	dataSourceUsage        *usage.Service
 	log                    log.Logger
 }
 
@@ -201,6 +209,18 @@ func (s *Service) handleQueryData(ctx context.Context, user *models.SignedInUser
 
 	ctx = httpclient.WithContextualMiddleware(ctx, middlewares...)
 
+	release, err := s.dataSourceLimiter.Acquire(ctx, ds, user)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var userID int64
+	if user != nil {
+		userID = user.UserId
+	}
+	s.dataSourceUsage.RecordQuery(ds.OrgId, ds.Uid, userID)
+
 	return s.pluginClient.QueryData(ctx, req)
 }
 
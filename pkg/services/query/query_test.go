@@ -116,7 +116,7 @@ func setup(t *testing.T) *testContext {
 		dataSourceCache:        dc,
 		oauthTokenService:      tc,
 		pluginRequestValidator: rv,
-		queryService:           query.ProvideService(nil, dc, nil, rv, ds, pc, tc),
+		queryService:           query.ProvideService(nil, dc, nil, rv, ds, pc, tc, nil, nil),
 	}
 }
 
@@ -172,6 +172,8 @@ func (c *fakeDataSourceCache) GetDatasourceByUID(ctx context.Context, datasource
 	return c.ds, nil
 }
 
+func (c *fakeDataSourceCache) Invalidate(ds *models.DataSource) {}
+
 type fakePluginClient struct {
 	plugins.Client
 
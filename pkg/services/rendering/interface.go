@@ -3,6 +3,7 @@ package rendering
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -17,8 +18,36 @@ type RenderType string
 const (
 	RenderCSV RenderType = "csv"
 	RenderPNG RenderType = "png"
+	RenderSVG RenderType = "svg"
 )
 
+// Priority is the priority of a render request. When the image renderer is busy, the queued requests of a higher
+// priority are rendered first.
+type Priority int
+
+const (
+	// PriorityInteractive is the priority of the renders users are waiting for, it is the default.
+	PriorityInteractive Priority = iota
+	// PriorityAlerting is the priority of the screenshots of alert notifications.
+	PriorityAlerting
+	// PriorityBackground is the priority of the renders nobody is waiting for, such as dashboard thumbnails.
+	PriorityBackground
+)
+
+var priorities = []Priority{PriorityInteractive, PriorityAlerting, PriorityBackground}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityAlerting:
+		return "alerting"
+	case PriorityBackground:
+		return "background"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
 type TimeoutOpts struct {
 	Timeout                  time.Duration // Timeout param passed to image-renderer service
 	RequestTimeoutMultiplier time.Duration // RequestTimeoutMultiplier used for plugin/HTTP request context timeout
@@ -48,6 +77,7 @@ type Opts struct {
 	Encoding          string
 	Timezone          string
 	ConcurrentLimit   int
+	Priority          Priority
 	DeviceScaleFactor float64
 	Headers           map[string][]string
 	Theme             models.Theme
@@ -69,6 +99,7 @@ type CSVOpts struct {
 	Encoding        string
 	Timezone        string
 	ConcurrentLimit int
+	Priority        Priority
 	Headers         map[string][]string
 }
 
@@ -81,6 +112,12 @@ type RenderCSVResult struct {
 	FileName string
 }
 
+// PanelRenderer renders single panels in process, without the image renderer plugin.
+type PanelRenderer interface {
+	// RenderPanel renders the panel of a d-solo path to filePath, as an image of the given type.
+	RenderPanel(ctx context.Context, opts Opts, rt RenderType, filePath string) error
+}
+
 type renderFunc func(ctx context.Context, renderKey string, options Opts) (*RenderResult, error)
 type renderCSVFunc func(ctx context.Context, renderKey string, options CSVOpts) (*RenderCSVResult, error)
 
@@ -0,0 +1,127 @@
+package native
+
+import (
+	"math"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// segment is a part of a bar gauge filled with the color of a threshold.
+type segment struct {
+	from, to float64
+	color    string
+}
+
+// drawBarGauge draws the reduced value of each series as a bar between min and max, with its name and value.
+func drawBarGauge(c canvas, b box, p *panelModel, s []*series, size int, t theme) {
+	gap := float64(4 * size)
+	lineHeight := float64(textHeight(size))
+	min, max := valueRange(s)
+	if s[0].config.Min == nil {
+		min = math.Min(min, 0)
+	}
+
+	values := make([]float64, len(s))
+	texts := make([]string, len(s))
+	for i, serie := range s {
+		v, ok := reduce(serie.values, p.Options.calc())
+		values[i] = v
+		texts[i] = formatValue(v, serie.config.Unit, serie.config.Decimals)
+		if !ok {
+			values[i] = min
+			texts[i] = "No data"
+		}
+	}
+
+	// the bars are side by side in wide panels
+	vertical := p.Options.Orientation == "vertical" ||
+		(p.Options.Orientation != "horizontal" && len(s) > 1 && b.w > b.h)
+	if vertical {
+		column := (b.w - gap*float64(len(s)-1)) / float64(len(s))
+		for i, serie := range s {
+			x := b.x + float64(i)*(column+gap)
+			col := fieldColor(values[i], serie.config, i, min, max, "thresholds", t)
+			c.text(x+column/2, b.y, truncate(texts[i], column, size), size, col, alignCenter)
+			c.text(x+column/2, b.y+b.h-lineHeight, truncate(serie.name, column, size), size, t.text, alignCenter)
+			bar := box{x: x, y: b.y + lineHeight + gap, w: column, h: b.h - 2*(lineHeight+gap)}
+			drawBar(c, bar, true, values[i], serie.config, min, max, i, p.Options.DisplayMode, t)
+		}
+		return
+	}
+
+	row := (b.h - gap*float64(len(s)-1)) / float64(len(s))
+	valueWidth := 0.0
+	for _, text := range texts {
+		valueWidth = math.Max(valueWidth, float64(textWidth(text, size)))
+	}
+	for i, serie := range s {
+		y := b.y + float64(i)*(row+gap)
+		col := fieldColor(values[i], serie.config, i, min, max, "thresholds", t)
+		c.text(b.x, y, truncate(serie.name, b.w, size), size, t.text, alignLeft)
+		bar := box{x: b.x, y: y + lineHeight + gap, w: b.w - valueWidth - gap, h: row - lineHeight - gap}
+		drawBar(c, bar, false, values[i], serie.config, min, max, i, p.Options.DisplayMode, t)
+		c.text(b.x+b.w, bar.y+(bar.h-lineHeight)/2, texts[i], size, col, alignRight)
+	}
+}
+
+// drawBar draws a bar filled up to a value. A basic bar has the color of the value, the others are filled with
+// the colors of the thresholds the bar crosses.
+func drawBar(c canvas, b box, vertical bool, v float64, config data.FieldConfig, min, max float64, index int, mode string, t theme) {
+	if b.w <= 0 || b.h <= 0 {
+		return
+	}
+	c.fillRect(b.x, b.y, b.w, b.h, t.empty)
+
+	segments := []segment{{from: min, to: v, color: ""}}
+	colorMode, _ := config.Color["mode"].(string)
+	if mode != "basic" && (colorMode == "" || colorMode == "thresholds") {
+		segments = thresholdSegments(config, min, max, v)
+	}
+	for _, seg := range segments {
+		from := math.Max(0, math.Min(1, (seg.from-min)/(max-min)))
+		to := math.Max(0, math.Min(1, (seg.to-min)/(max-min)))
+		if to <= from {
+			continue
+		}
+		col := fieldColor(v, config, index, min, max, "thresholds", t)
+		if seg.color != "" {
+			col = parseColor(seg.color, t)
+		}
+		if vertical {
+			c.fillRect(b.x, b.y+b.h*(1-to), b.w, b.h*(to-from), col)
+		} else {
+			c.fillRect(b.x+b.w*from, b.y, b.w*(to-from), b.h, col)
+		}
+	}
+}
+
+// thresholdSegments splits the range from min to a value by the thresholds of the field config.
+func thresholdSegments(config data.FieldConfig, min, max, v float64) []segment {
+	if config.Thresholds == nil || len(config.Thresholds.Steps) == 0 {
+		return []segment{{from: min, to: v, color: "green"}}
+	}
+
+	steps := config.Thresholds.Steps
+	absolute := func(value float64) float64 {
+		if config.Thresholds.Mode == data.ThresholdsModePercentage {
+			return min + value/100*(max-min)
+		}
+		return value
+	}
+
+	segments := make([]segment, 0, len(steps))
+	for i, step := range steps {
+		from := min
+		if i > 0 {
+			from = math.Max(absolute(float64(step.Value)), min)
+		}
+		to := v
+		if i+1 < len(steps) {
+			to = math.Min(absolute(float64(steps[i+1].Value)), v)
+		}
+		if to > from {
+			segments = append(segments, segment{from: from, to: to, color: step.Color})
+		}
+	}
+	return segments
+}
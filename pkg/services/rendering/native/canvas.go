@@ -0,0 +1,176 @@
+package native
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+)
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+	alignRight
+)
+
+type point struct {
+	x, y float64
+}
+
+// canvas is what the panels are drawn on, the coordinates are in pixels from the top left corner.
+type canvas interface {
+	fillRect(x, y, w, h float64, c color.NRGBA)
+	polyline(points []point, width float64, c color.NRGBA)
+	// area fills the area between a polyline, with increasing x coordinates, and the horizontal line at baseline.
+	area(points []point, baseline float64, c color.NRGBA)
+	// text draws a line of text, y is the top of the text.
+	text(x, y float64, s string, size int, c color.NRGBA, a align)
+	encode(w io.Writer) error
+}
+
+func alignedX(x float64, s string, size int, a align) float64 {
+	switch a {
+	case alignCenter:
+		return x - float64(textWidth(s, size))/2
+	case alignRight:
+		return x - float64(textWidth(s, size))
+	}
+	return x
+}
+
+// rasterCanvas draws PNG images.
+type rasterCanvas struct {
+	img *image.NRGBA
+}
+
+func newRasterCanvas(width, height int) *rasterCanvas {
+	return &rasterCanvas{img: image.NewNRGBA(image.Rect(0, 0, width, height))}
+}
+
+func (c *rasterCanvas) fillRect(x, y, w, h float64, col color.NRGBA) {
+	r := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+	draw.Draw(c.img, r, image.NewUniform(col), image.Point{}, draw.Over)
+}
+
+func (c *rasterCanvas) polyline(points []point, width float64, col color.NRGBA) {
+	for i := 1; i < len(points); i++ {
+		p0, p1 := points[i-1], points[i]
+		steps := math.Ceil(math.Max(math.Abs(p1.x-p0.x), math.Abs(p1.y-p0.y)))
+		for s := 0.0; s <= steps; s++ {
+			t := 0.0
+			if steps > 0 {
+				t = s / steps
+			}
+			x, y := p0.x+(p1.x-p0.x)*t, p0.y+(p1.y-p0.y)*t
+			c.fillRect(x-width/2, y-width/2, width, width, col)
+		}
+	}
+}
+
+func (c *rasterCanvas) area(points []point, baseline float64, col color.NRGBA) {
+	for i := 1; i < len(points); i++ {
+		p0, p1 := points[i-1], points[i]
+		// each column is filled once, so that translucent areas are even
+		for x := math.Ceil(p0.x); x < math.Ceil(p1.x); x++ {
+			y := p0.y
+			if p1.x > p0.x {
+				y += (p1.y - p0.y) * (x - p0.x) / (p1.x - p0.x)
+			}
+			c.fillRect(x, math.Min(y, baseline), 1, math.Abs(baseline-y), col)
+		}
+	}
+}
+
+func (c *rasterCanvas) text(x, y float64, s string, size int, col color.NRGBA, a align) {
+	x = alignedX(x, s, size, a)
+	for _, r := range s {
+		for row, bits := range glyph(r) {
+			for column, bit := range bits {
+				if bit == '#' {
+					c.fillRect(x+float64(column*size), y+float64(row*size), float64(size), float64(size), col)
+				}
+			}
+		}
+		x += float64(glyphAdvance * size)
+	}
+}
+
+func (c *rasterCanvas) encode(w io.Writer) error {
+	return png.Encode(w, c.img)
+}
+
+// svgCanvas draws SVG images, the texts are drawn with a monospace font matching the size of the bitmap font.
+type svgCanvas struct {
+	width, height int
+	body          strings.Builder
+}
+
+func newSVGCanvas(width, height int) *svgCanvas {
+	return &svgCanvas{width: width, height: height}
+}
+
+func svgPaint(attr string, c color.NRGBA) string {
+	paint := fmt.Sprintf(`%s="#%02x%02x%02x"`, attr, c.R, c.G, c.B)
+	if c.A < 255 {
+		paint += fmt.Sprintf(` %s-opacity="%.3f"`, attr, float64(c.A)/255)
+	}
+	return paint
+}
+
+func svgPoints(points []point) string {
+	coords := make([]string, 0, len(points))
+	for _, p := range points {
+		coords = append(coords, fmt.Sprintf("%.1f,%.1f", p.x, p.y))
+	}
+	return strings.Join(coords, " ")
+}
+
+func (c *svgCanvas) fillRect(x, y, w, h float64, col color.NRGBA) {
+	fmt.Fprintf(&c.body, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" %s/>`+"\n", x, y, w, h, svgPaint("fill", col))
+}
+
+func (c *svgCanvas) polyline(points []point, width float64, col color.NRGBA) {
+	if len(points) < 2 {
+		return
+	}
+	fmt.Fprintf(&c.body, `<polyline points="%s" fill="none" stroke-width="%.1f" stroke-linejoin="round" %s/>`+"\n",
+		svgPoints(points), width, svgPaint("stroke", col))
+}
+
+func (c *svgCanvas) area(points []point, baseline float64, col color.NRGBA) {
+	if len(points) < 2 {
+		return
+	}
+	polygon := make([]point, 0, len(points)+2)
+	polygon = append(polygon, point{points[0].x, baseline})
+	polygon = append(polygon, points...)
+	polygon = append(polygon, point{points[len(points)-1].x, baseline})
+	fmt.Fprintf(&c.body, `<polygon points="%s" %s/>`+"\n", svgPoints(polygon), svgPaint("fill", col))
+}
+
+func (c *svgCanvas) text(x, y float64, s string, size int, col color.NRGBA, a align) {
+	anchor := "start"
+	switch a {
+	case alignCenter:
+		anchor = "middle"
+	case alignRight:
+		anchor = "end"
+	}
+	fmt.Fprintf(&c.body, `<text x="%.1f" y="%.1f" font-family="monospace" font-size="%d" text-anchor="%s" %s>`,
+		x, y+float64(textHeight(size)), glyphAdvance*size*10/6, anchor, svgPaint("fill", col))
+	_ = xml.EscapeText(&c.body, []byte(s))
+	c.body.WriteString("</text>\n")
+}
+
+func (c *svgCanvas) encode(w io.Writer) error {
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n%s</svg>\n",
+		c.width, c.height, c.width, c.height, c.body.String())
+	return err
+}
@@ -0,0 +1,143 @@
+package native
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// theme holds the colors of the panels, matching the colors of the Grafana themes.
+type theme struct {
+	background color.NRGBA
+	text       color.NRGBA
+	weakText   color.NRGBA
+	grid       color.NRGBA
+	// empty is the color of the unfilled part of bar gauges
+	empty color.NRGBA
+}
+
+var (
+	darkTheme = theme{
+		background: color.NRGBA{R: 0x18, G: 0x1b, B: 0x1f, A: 0xff},
+		text:       color.NRGBA{R: 0xcc, G: 0xcc, B: 0xdc, A: 0xff},
+		weakText:   color.NRGBA{R: 0xcc, G: 0xcc, B: 0xdc, A: 0x99},
+		grid:       color.NRGBA{R: 0xcc, G: 0xcc, B: 0xdc, A: 0x1a},
+		empty:      color.NRGBA{R: 0xcc, G: 0xcc, B: 0xdc, A: 0x0f},
+	}
+	lightTheme = theme{
+		background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		text:       color.NRGBA{R: 0x24, G: 0x29, B: 0x2e, A: 0xff},
+		weakText:   color.NRGBA{R: 0x24, G: 0x29, B: 0x2e, A: 0xbf},
+		grid:       color.NRGBA{R: 0x24, G: 0x29, B: 0x2e, A: 0x1f},
+		empty:      color.NRGBA{R: 0x24, G: 0x29, B: 0x2e, A: 0x0f},
+	}
+)
+
+func themeOf(t models.Theme) theme {
+	if t == models.ThemeLight {
+		return lightTheme
+	}
+	return darkTheme
+}
+
+// namedColors are the colors of the color picker of the panel editor.
+var namedColors = map[string]string{
+	"green": "#73BF69", "dark-green": "#37872D", "semi-dark-green": "#56A64B", "light-green": "#96D98D", "super-light-green": "#C8F2C2",
+	"red": "#F2495C", "dark-red": "#C4162A", "semi-dark-red": "#E02F44", "light-red": "#FF7383", "super-light-red": "#FFA6B0",
+	"yellow": "#FADE2A", "dark-yellow": "#E0B400", "semi-dark-yellow": "#F2CC0C", "light-yellow": "#FFEE52", "super-light-yellow": "#FFF899",
+	"orange": "#FF9830", "dark-orange": "#FA6400", "semi-dark-orange": "#FF780A", "light-orange": "#FFB357", "super-light-orange": "#FFCB7D",
+	"blue": "#5794F2", "dark-blue": "#1F60C4", "semi-dark-blue": "#3274D9", "light-blue": "#8AB8FF", "super-light-blue": "#C0D8FF",
+	"purple": "#B877D9", "dark-purple": "#8F3BB8", "semi-dark-purple": "#A352CC", "light-purple": "#CA95E5", "super-light-purple": "#DEB6F2",
+}
+
+// classicPalette is the palette of the "palette-classic" color mode, the series are colored in order.
+var classicPalette = []string{
+	"#7EB26D", "#EAB839", "#6ED0E0", "#EF843C", "#E24D42", "#1F78C1", "#BA43A9", "#705DA0",
+	"#508642", "#CCA300", "#447EBC", "#C15C17", "#890F02", "#0A437C", "#6D1F62", "#584477",
+}
+
+// parseColor parses the named, hexadecimal and rgb(a) colors of the panels. The color of the text is used for
+// the colors which cannot be parsed.
+func parseColor(s string, t theme) color.NRGBA {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if named, ok := namedColors[s]; ok {
+		s = strings.ToLower(named)
+	}
+
+	switch {
+	case s == "transparent":
+		return color.NRGBA{}
+	case strings.HasPrefix(s, "#"):
+		if c, ok := parseHexColor(s[1:]); ok {
+			return c
+		}
+	case strings.HasPrefix(s, "rgb"):
+		if c, ok := parseRGBColor(s); ok {
+			return c
+		}
+	}
+	return t.text
+}
+
+func parseHexColor(s string) (color.NRGBA, bool) {
+	if len(s) == 3 || len(s) == 4 {
+		expanded := make([]byte, 0, len(s)*2)
+		for i := range s {
+			expanded = append(expanded, s[i], s[i])
+		}
+		s = string(expanded)
+	}
+	if len(s) != 6 && len(s) != 8 {
+		return color.NRGBA{}, false
+	}
+	if len(s) == 6 {
+		s += "ff"
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, true
+}
+
+func parseRGBColor(s string) (color.NRGBA, bool) {
+	start, end := strings.Index(s, "("), strings.LastIndex(s, ")")
+	if start < 0 || end < start {
+		return color.NRGBA{}, false
+	}
+	parts := strings.Split(s[start+1:end], ",")
+	if len(parts) != 3 && len(parts) != 4 {
+		return color.NRGBA{}, false
+	}
+	values := make([]float64, 0, 4)
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return color.NRGBA{}, false
+		}
+		values = append(values, v)
+	}
+	c := color.NRGBA{R: clampByte(values[0]), G: clampByte(values[1]), B: clampByte(values[2]), A: 0xff}
+	if len(values) == 4 {
+		c.A = clampByte(values[3] * 255)
+	}
+	return c, true
+}
+
+func clampByte(v float64) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+// withAlpha returns a color with its opacity multiplied by alpha.
+func withAlpha(c color.NRGBA, alpha float64) color.NRGBA {
+	c.A = clampByte(float64(c.A) * alpha)
+	return c
+}
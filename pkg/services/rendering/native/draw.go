@@ -0,0 +1,119 @@
+package native
+
+import (
+	"math"
+	"time"
+)
+
+// supportedPanels are the panel types the native renderer draws, graph panels are drawn as time series.
+var supportedPanels = map[string]bool{
+	"timeseries": true,
+	"graph":      true,
+	"stat":       true,
+	"bargauge":   true,
+}
+
+type box struct {
+	x, y, w, h float64
+}
+
+// inset returns the box with a padding on each side.
+func (b box) inset(padding float64) box {
+	return box{x: b.x + padding, y: b.y + padding, w: math.Max(b.w-2*padding, 0), h: math.Max(b.h-2*padding, 0)}
+}
+
+type timeRange struct {
+	from, to time.Time
+	location *time.Location
+}
+
+// textSize returns the size of the texts of a panel, the images of the size of a dashboard panel are drawn at
+// size 1.
+func textSize(width, height int) int {
+	size := int(math.Min(float64(width), float64(height)) / 250)
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+// fitSize returns the largest size at which a text fits in a box.
+func fitSize(s string, w, h float64) int {
+	width := textWidth(s, 1)
+	if width == 0 {
+		return 1
+	}
+	size := int(math.Min(w/float64(width), h/float64(glyphHeight)))
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+// truncate shortens a text to fit in a width.
+func truncate(s string, width float64, size int) string {
+	if float64(textWidth(s, size)) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && float64(textWidth(string(runes)+"…", size)) > width {
+		runes = runes[:len(runes)-1]
+	}
+	if len(runes) == 0 {
+		return ""
+	}
+	return string(runes) + "…"
+}
+
+// drawPanel draws a panel with its title, and the series of its queries or the error of its queries.
+func drawPanel(c canvas, width, height int, p *panelModel, s []*series, queryErr error, tr timeRange, t theme) {
+	c.fillRect(0, 0, float64(width), float64(height), t.background)
+
+	size := textSize(width, height)
+	padding := float64(4 * size)
+	body := box{w: float64(width), h: float64(height)}.inset(padding)
+	if p.Title != "" {
+		c.text(body.x, body.y, truncate(p.Title, body.w, size), size, t.text, alignLeft)
+		titleHeight := float64(textHeight(size)) + padding
+		body.y += titleHeight
+		body.h = math.Max(body.h-titleHeight, 0)
+	}
+
+	if queryErr != nil {
+		drawMessage(c, body, "Error: "+queryErr.Error(), size, t)
+		return
+	}
+	if !hasValues(s) {
+		message := "No data"
+		if p.FieldConfig.Defaults.NoValue != "" {
+			message = p.FieldConfig.Defaults.NoValue
+		}
+		drawMessage(c, body, message, size, t)
+		return
+	}
+
+	switch p.Type {
+	case "stat":
+		drawStat(c, body, p, s, size, t)
+	case "bargauge":
+		drawBarGauge(c, body, p, s, size, t)
+	default:
+		drawTimeSeries(c, body, p, s, tr, size, t)
+	}
+}
+
+func drawMessage(c canvas, b box, message string, size int, t theme) {
+	message = truncate(message, b.w, size)
+	c.text(b.x+b.w/2, b.y+(b.h-float64(textHeight(size)))/2, message, size, t.weakText, alignCenter)
+}
+
+func hasValues(s []*series) bool {
+	for _, serie := range s {
+		for _, v := range serie.values {
+			if v != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	green = parseColor("green", darkTheme)
+	red   = parseColor("red", darkTheme)
+)
+
+func testFieldConfig(unit string) fieldConfig {
+	return fieldConfig{Defaults: data.FieldConfig{
+		Unit: unit,
+		Thresholds: &data.ThresholdsConfig{
+			Mode:  data.ThresholdsModeAbsolute,
+			Steps: []data.Threshold{{Color: "green"}, {Color: "red", Value: 80}},
+		},
+	}}
+}
+
+func testFrames(values ...float64) data.Frames {
+	start := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	times := make([]time.Time, 0, len(values))
+	for i := range values {
+		times = append(times, start.Add(time.Duration(i)*time.Minute))
+	}
+	return data.Frames{data.NewFrame("",
+		data.NewField("time", nil, times),
+		data.NewField("cpu", data.Labels{"host": "a"}, values),
+	)}
+}
+
+func testTimeRange() timeRange {
+	start := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	return timeRange{from: start, to: start.Add(10 * time.Minute), location: time.UTC}
+}
+
+func pixel(t *testing.T, c *rasterCanvas, x, y int) color.NRGBA {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, c.encode(&buf))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
+
+func TestSeriesOf(t *testing.T) {
+	fc := testFieldConfig("percent")
+	fc.Overrides = []fieldOverride{{}}
+	fc.Overrides[0].Matcher.ID = "byName"
+	fc.Overrides[0].Matcher.Options = `cpu{host="a"}`
+	fc.Overrides[0].Properties = append(fc.Overrides[0].Properties, struct {
+		ID    string          `json:"id"`
+		Value json.RawMessage `json:"value"`
+	}{ID: "unit", Value: json.RawMessage(`"percentunit"`)})
+
+	s := seriesOf(testFrames(1, 2, 3), fc)
+	require.Len(t, s, 1)
+	require.Equal(t, `cpu{host="a"}`, s[0].name)
+	require.Equal(t, "percentunit", s[0].config.Unit)
+	require.NotNil(t, s[0].config.Thresholds)
+	require.Len(t, s[0].times, 3)
+}
+
+func TestThresholdColor(t *testing.T) {
+	config := testFieldConfig("").Defaults
+	require.Equal(t, "green", thresholdColor(79, config, 0, 100))
+	require.Equal(t, "red", thresholdColor(80, config, 0, 100))
+
+	config.Thresholds.Mode = data.ThresholdsModePercentage
+	require.Equal(t, "green", thresholdColor(150, config, 0, 200))
+	require.Equal(t, "red", thresholdColor(170, config, 0, 200))
+}
+
+func TestReduce(t *testing.T) {
+	one, three := 1.0, 3.0
+	values := []*float64{&one, &three, nil}
+
+	testCases := map[string]float64{"lastNotNull": 3, "firstNotNull": 1, "min": 1, "max": 3, "mean": 2, "sum": 4, "count": 3, "range": 2}
+	for calc, expected := range testCases {
+		v, ok := reduce(values, calc)
+		require.True(t, ok, calc)
+		require.Equal(t, expected, v, calc)
+	}
+	_, ok := reduce(values, "last")
+	require.False(t, ok)
+}
+
+func TestDrawPanel(t *testing.T) {
+	t.Run("A stat panel with a background color mode should be filled with the color of the value", func(t *testing.T) {
+		p := &panelModel{Type: "stat", Title: "CPU", FieldConfig: testFieldConfig("percent")}
+		p.Options.ColorMode = "background"
+		p.Options.GraphMode = "none"
+
+		c := newRasterCanvas(400, 200)
+		drawPanel(c, 400, 200, p, seriesOf(testFrames(50, 90), p.FieldConfig), nil, testTimeRange(), darkTheme)
+		require.Equal(t, red, pixel(t, c, 20, 190))
+
+		c = newRasterCanvas(400, 200)
+		drawPanel(c, 400, 200, p, seriesOf(testFrames(90, 50), p.FieldConfig), nil, testTimeRange(), darkTheme)
+		require.Equal(t, green, pixel(t, c, 20, 190))
+	})
+
+	t.Run("A bar gauge should be filled up to the value with the colors of the thresholds", func(t *testing.T) {
+		p := &panelModel{Type: "bargauge", FieldConfig: testFieldConfig("")}
+		min, max := data.ConfFloat64(0), data.ConfFloat64(100)
+		p.FieldConfig.Defaults.Min, p.FieldConfig.Defaults.Max = &min, &max
+
+		c := newRasterCanvas(400, 100)
+		drawPanel(c, 400, 100, p, seriesOf(testFrames(90), p.FieldConfig), nil, testTimeRange(), darkTheme)
+		require.Equal(t, green, pixel(t, c, 50, 60))
+		require.Equal(t, red, pixel(t, c, 330, 60))
+		require.NotEqual(t, red, pixel(t, c, 370, 60))
+	})
+
+	t.Run("A time series panel should be drawn as SVG with its legend", func(t *testing.T) {
+		p := &panelModel{Type: "timeseries", Title: "CPU <usage>", FieldConfig: testFieldConfig("percent")}
+
+		c := newSVGCanvas(800, 300)
+		drawPanel(c, 800, 300, p, seriesOf(testFrames(10, 20, 30, 40), p.FieldConfig), nil, testTimeRange(), lightTheme)
+		var buf bytes.Buffer
+		require.NoError(t, c.encode(&buf))
+		svg := buf.String()
+		require.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="800" height="300"`))
+		require.Contains(t, svg, "CPU &lt;usage&gt;")
+		require.Contains(t, svg, "<polyline")
+		require.Contains(t, svg, "40%")
+		require.Contains(t, svg, "12:05")
+		require.Contains(t, svg, "cpu{host=&#34;a&#34;}")
+	})
+
+	t.Run("Panels without values should show the no value text", func(t *testing.T) {
+		p := &panelModel{Type: "timeseries"}
+		p.FieldConfig.Defaults.NoValue = "Nothing to see"
+
+		c := newSVGCanvas(400, 200)
+		drawPanel(c, 400, 200, p, nil, nil, testTimeRange(), darkTheme)
+		var buf bytes.Buffer
+		require.NoError(t, c.encode(&buf))
+		require.Contains(t, buf.String(), "Nothing to see")
+	})
+}
@@ -0,0 +1,323 @@
+package native
+
+import (
+	"encoding/json"
+	"image/color"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// series is a numeric field of a data frame, with the times of its values when the frame has a time field.
+type series struct {
+	name   string
+	config data.FieldConfig
+	times  []time.Time
+	values []*float64
+}
+
+// fieldConfig is the field config of a panel.
+type fieldConfig struct {
+	Defaults  data.FieldConfig `json:"defaults"`
+	Overrides []fieldOverride  `json:"overrides"`
+}
+
+type fieldOverride struct {
+	Matcher struct {
+		ID      string `json:"id"`
+		Options string `json:"options"`
+	} `json:"matcher"`
+	Properties []struct {
+		ID    string          `json:"id"`
+		Value json.RawMessage `json:"value"`
+	} `json:"properties"`
+}
+
+// seriesOf returns the numeric fields of the frames, with their field config set to the field config of the
+// data source, completed by the field config of the panel.
+func seriesOf(frames data.Frames, fc fieldConfig) []*series {
+	result := make([]*series, 0)
+	for _, frame := range frames {
+		var times []time.Time
+		for _, field := range frame.Fields {
+			if field.Type().Time() {
+				times = timesOf(field)
+				break
+			}
+		}
+
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			s := &series{name: fieldName(frame, field), times: times, values: make([]*float64, field.Len())}
+			for i := range s.values {
+				if v, err := field.NullableFloatAt(i); err == nil && v != nil && !math.IsNaN(*v) {
+					s.values[i] = v
+				}
+			}
+			if field.Config != nil {
+				s.config = *field.Config
+			}
+			s.config = withDefaults(s.config, fc.Defaults)
+			if s.config.DisplayName != "" {
+				s.name = s.config.DisplayName
+			}
+			for _, override := range fc.Overrides {
+				if override.matches(s.name) {
+					override.apply(&s.config)
+				}
+			}
+			if s.config.DisplayName != "" {
+				s.name = s.config.DisplayName
+			}
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func timesOf(field *data.Field) []time.Time {
+	times := make([]time.Time, field.Len())
+	for i := range times {
+		switch t := field.At(i).(type) {
+		case time.Time:
+			times[i] = t
+		case *time.Time:
+			if t != nil {
+				times[i] = *t
+			}
+		}
+	}
+	return times
+}
+
+// fieldName returns the name of a field as the panels display it by default.
+func fieldName(frame *data.Frame, field *data.Field) string {
+	if field.Config != nil && field.Config.DisplayNameFromDS != "" {
+		return field.Config.DisplayNameFromDS
+	}
+
+	name := field.Name
+	if name == "" || name == "Value" {
+		name = frame.Name
+	}
+	if len(field.Labels) == 0 {
+		if name == "" {
+			return "Value"
+		}
+		return name
+	}
+
+	keys := make([]string, 0, len(field.Labels))
+	for k := range field.Labels {
+		if k == "__name__" {
+			name = field.Labels[k]
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	labels := make([]string, 0, len(keys))
+	for _, k := range keys {
+		labels = append(labels, k+`="`+field.Labels[k]+`"`)
+	}
+	if len(labels) == 0 {
+		return name
+	}
+	return name + "{" + strings.Join(labels, ", ") + "}"
+}
+
+// withDefaults completes the unset properties of a field config with the defaults of the panel.
+func withDefaults(c data.FieldConfig, defaults data.FieldConfig) data.FieldConfig {
+	if c.DisplayName == "" {
+		c.DisplayName = defaults.DisplayName
+	}
+	if c.Unit == "" {
+		c.Unit = defaults.Unit
+	}
+	if c.Decimals == nil {
+		c.Decimals = defaults.Decimals
+	}
+	if c.Min == nil {
+		c.Min = defaults.Min
+	}
+	if c.Max == nil {
+		c.Max = defaults.Max
+	}
+	if c.Thresholds == nil {
+		c.Thresholds = defaults.Thresholds
+	}
+	if c.Color == nil {
+		c.Color = defaults.Color
+	}
+	if c.NoValue == "" {
+		c.NoValue = defaults.NoValue
+	}
+	if c.Custom == nil {
+		c.Custom = defaults.Custom
+	}
+	return c
+}
+
+func (o fieldOverride) matches(name string) bool {
+	switch o.Matcher.ID {
+	case "byName":
+		return o.Matcher.Options == name
+	case "byRegexp":
+		re, err := regexp.Compile(o.Matcher.Options)
+		return err == nil && re.MatchString(name)
+	}
+	return false
+}
+
+// apply sets the properties of the override supported by the native renderer.
+func (o fieldOverride) apply(c *data.FieldConfig) {
+	for _, p := range o.Properties {
+		switch p.ID {
+		case "displayName":
+			_ = json.Unmarshal(p.Value, &c.DisplayName)
+		case "unit":
+			_ = json.Unmarshal(p.Value, &c.Unit)
+		case "decimals":
+			_ = json.Unmarshal(p.Value, &c.Decimals)
+		case "min":
+			_ = json.Unmarshal(p.Value, &c.Min)
+		case "max":
+			_ = json.Unmarshal(p.Value, &c.Max)
+		case "thresholds":
+			_ = json.Unmarshal(p.Value, &c.Thresholds)
+		case "color":
+			_ = json.Unmarshal(p.Value, &c.Color)
+		}
+	}
+}
+
+// valueRange returns the range of the values of the series, min and max of their field config take precedence.
+func valueRange(s []*series) (float64, float64) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, serie := range s {
+		for _, v := range serie.values {
+			if v != nil {
+				min, max = math.Min(min, *v), math.Max(max, *v)
+			}
+		}
+		if serie.config.Min != nil {
+			min = float64(*serie.config.Min)
+		}
+		if serie.config.Max != nil {
+			max = float64(*serie.config.Max)
+		}
+	}
+	if math.IsInf(min, 0) || math.IsInf(max, 0) {
+		return 0, 1
+	}
+	if min == max {
+		if min == 0 {
+			return 0, 1
+		}
+		return math.Min(min, 0), math.Max(max, 0)
+	}
+	return min, max
+}
+
+// thresholdColor returns the color of the threshold step of a value, the first step is the base color. In
+// percentage mode, the value is placed between min and max.
+func thresholdColor(v float64, c data.FieldConfig, min, max float64) string {
+	if c.Thresholds == nil || len(c.Thresholds.Steps) == 0 {
+		return "green"
+	}
+	if c.Thresholds.Mode == data.ThresholdsModePercentage && max > min {
+		v = (v - min) / (max - min) * 100
+	}
+
+	steps := c.Thresholds.Steps
+	col := steps[0].Color
+	for _, step := range steps[1:] {
+		if v < float64(step.Value) {
+			break
+		}
+		col = step.Color
+	}
+	return col
+}
+
+// fieldColor returns the color of a value of a field, according to the color mode of its field config or
+// defaultMode when the mode is not set. The series are colored in order of index with the palette modes.
+func fieldColor(v float64, c data.FieldConfig, index int, min, max float64, defaultMode string, t theme) color.NRGBA {
+	mode, _ := c.Color["mode"].(string)
+	if mode == "" {
+		mode = defaultMode
+	}
+
+	switch mode {
+	case "fixed", "shades":
+		fixed, _ := c.Color["fixedColor"].(string)
+		return parseColor(fixed, t)
+	case "thresholds":
+		return parseColor(thresholdColor(v, c, min, max), t)
+	}
+	return parseColor(classicPalette[index%len(classicPalette)], t)
+}
+
+// reduce reduces the values of a series to a single value with a calculation of the panel editor, the last value
+// which is not null is used for the calculations which are not supported.
+func reduce(values []*float64, calc string) (float64, bool) {
+	notNull := make([]float64, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			notNull = append(notNull, *v)
+		}
+	}
+
+	switch calc {
+	case "count":
+		return float64(len(values)), true
+	case "last":
+		if len(values) == 0 || values[len(values)-1] == nil {
+			return 0, false
+		}
+		return *values[len(values)-1], true
+	case "first":
+		if len(values) == 0 || values[0] == nil {
+			return 0, false
+		}
+		return *values[0], true
+	}
+
+	if len(notNull) == 0 {
+		return 0, false
+	}
+	switch calc {
+	case "firstNotNull":
+		return notNull[0], true
+	case "min", "max", "range":
+		min, max := notNull[0], notNull[0]
+		for _, v := range notNull {
+			min, max = math.Min(min, v), math.Max(max, v)
+		}
+		switch calc {
+		case "min":
+			return min, true
+		case "max":
+			return max, true
+		}
+		return max - min, true
+	case "sum", "mean":
+		sum := 0.0
+		for _, v := range notNull {
+			sum += v
+		}
+		if calc == "mean" {
+			return sum / float64(len(notNull)), true
+		}
+		return sum, true
+	case "diff":
+		return notNull[len(notNull)-1] - notNull[0], true
+	}
+	return notNull[len(notNull)-1], true
+}
@@ -0,0 +1,140 @@
+package native
+
+// The PNG images are drawn with a 5x7 bitmap font, so that they do not depend on the fonts of the host. Each glyph
+// is drawn in a cell of glyphAdvance x glyphHeight pixels, scaled by the size of the text.
+const (
+	glyphWidth   = 5
+	glyphHeight  = 7
+	glyphAdvance = glyphWidth + 1
+)
+
+// glyphs are the rows of the glyphs, from top to bottom, the characters without a glyph are drawn as a box.
+var glyphs = map[rune][glyphHeight]string{
+	' ':  {"     ", "     ", "     ", "     ", "     ", "     ", "     "},
+	'!':  {"  #  ", "  #  ", "  #  ", "  #  ", "  #  ", "     ", "  #  "},
+	'"':  {" # # ", " # # ", "     ", "     ", "     ", "     ", "     "},
+	'#':  {" # # ", " # # ", "#####", " # # ", "#####", " # # ", " # # "},
+	'$':  {"  #  ", " ####", "# #  ", " ### ", "  # #", "#### ", "  #  "},
+	'%':  {"##   ", "##  #", "   # ", "  #  ", " #   ", "#  ##", "   ##"},
+	'&':  {" ##  ", "#  # ", "# #  ", " #   ", "# # #", "#  # ", " ## #"},
+	'\'': {"  #  ", "  #  ", "     ", "     ", "     ", "     ", "     "},
+	'(':  {"   # ", "  #  ", " #   ", " #   ", " #   ", "  #  ", "   # "},
+	')':  {" #   ", "  #  ", "   # ", "   # ", "   # ", "  #  ", " #   "},
+	'*':  {"     ", "  #  ", "# # #", " ### ", "# # #", "  #  ", "     "},
+	'+':  {"     ", "  #  ", "  #  ", "#####", "  #  ", "  #  ", "     "},
+	',':  {"     ", "     ", "     ", "     ", "  ## ", "   # ", "  #  "},
+	'-':  {"     ", "     ", "     ", "#####", "     ", "     ", "     "},
+	'.':  {"     ", "     ", "     ", "     ", "     ", " ##  ", " ##  "},
+	'/':  {"     ", "    #", "   # ", "  #  ", " #   ", "#    ", "     "},
+	'0':  {" ### ", "#   #", "#  ##", "# # #", "##  #", "#   #", " ### "},
+	'1':  {"  #  ", " ##  ", "  #  ", "  #  ", "  #  ", "  #  ", " ### "},
+	'2':  {" ### ", "#   #", "    #", "   # ", "  #  ", " #   ", "#####"},
+	'3':  {"#####", "   # ", "  #  ", "   # ", "    #", "#   #", " ### "},
+	'4':  {"   # ", "  ## ", " # # ", "#  # ", "#####", "   # ", "   # "},
+	'5':  {"#####", "#    ", "#### ", "    #", "    #", "#   #", " ### "},
+	'6':  {"  ## ", " #   ", "#    ", "#### ", "#   #", "#   #", " ### "},
+	'7':  {"#####", "    #", "   # ", "  #  ", " #   ", " #   ", " #   "},
+	'8':  {" ### ", "#   #", "#   #", " ### ", "#   #", "#   #", " ### "},
+	'9':  {" ### ", "#   #", "#   #", " ####", "    #", "   # ", " ##  "},
+	':':  {"     ", " ##  ", " ##  ", "     ", " ##  ", " ##  ", "     "},
+	';':  {"     ", " ##  ", " ##  ", "     ", " ##  ", "  #  ", " #   "},
+	'<':  {"   # ", "  #  ", " #   ", "#    ", " #   ", "  #  ", "   # "},
+	'=':  {"     ", "     ", "#####", "     ", "#####", "     ", "     "},
+	'>':  {" #   ", "  #  ", "   # ", "    #", "   # ", "  #  ", " #   "},
+	'?':  {" ### ", "#   #", "    #", "   # ", "  #  ", "     ", "  #  "},
+	'@':  {" ### ", "#   #", "    #", " ## #", "# # #", "# # #", " ### "},
+	'A':  {" ### ", "#   #", "#   #", "#####", "#   #", "#   #", "#   #"},
+	'B':  {"#### ", "#   #", "#   #", "#### ", "#   #", "#   #", "#### "},
+	'C':  {" ### ", "#   #", "#    ", "#    ", "#    ", "#   #", " ### "},
+	'D':  {"###  ", "#  # ", "#   #", "#   #", "#   #", "#  # ", "###  "},
+	'E':  {"#####", "#    ", "#    ", "#### ", "#    ", "#    ", "#####"},
+	'F':  {"#####", "#    ", "#    ", "#### ", "#    ", "#    ", "#    "},
+	'G':  {" ### ", "#   #", "#    ", "# ###", "#   #", "#   #", " ####"},
+	'H':  {"#   #", "#   #", "#   #", "#####", "#   #", "#   #", "#   #"},
+	'I':  {" ### ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  ", " ### "},
+	'J':  {"  ###", "   # ", "   # ", "   # ", "   # ", "#  # ", " ##  "},
+	'K':  {"#   #", "#  # ", "# #  ", "##   ", "# #  ", "#  # ", "#   #"},
+	'L':  {"#    ", "#    ", "#    ", "#    ", "#    ", "#    ", "#####"},
+	'M':  {"#   #", "## ##", "# # #", "# # #", "#   #", "#   #", "#   #"},
+	'N':  {"#   #", "#   #", "##  #", "# # #", "#  ##", "#   #", "#   #"},
+	'O':  {" ### ", "#   #", "#   #", "#   #", "#   #", "#   #", " ### "},
+	'P':  {"#### ", "#   #", "#   #", "#### ", "#    ", "#    ", "#    "},
+	'Q':  {" ### ", "#   #", "#   #", "#   #", "# # #", "#  # ", " ## #"},
+	'R':  {"#### ", "#   #", "#   #", "#### ", "# #  ", "#  # ", "#   #"},
+	'S':  {" ####", "#    ", "#    ", " ### ", "    #", "    #", "#### "},
+	'T':  {"#####", "  #  ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  "},
+	'U':  {"#   #", "#   #", "#   #", "#   #", "#   #", "#   #", " ### "},
+	'V':  {"#   #", "#   #", "#   #", "#   #", "#   #", " # # ", "  #  "},
+	'W':  {"#   #", "#   #", "#   #", "# # #", "# # #", "# # #", " # # "},
+	'X':  {"#   #", "#   #", " # # ", "  #  ", " # # ", "#   #", "#   #"},
+	'Y':  {"#   #", "#   #", " # # ", "  #  ", "  #  ", "  #  ", "  #  "},
+	'Z':  {"#####", "    #", "   # ", "  #  ", " #   ", "#    ", "#####"},
+	'[':  {" ### ", " #   ", " #   ", " #   ", " #   ", " #   ", " ### "},
+	'\\': {"     ", "#    ", " #   ", "  #  ", "   # ", "    #", "     "},
+	']':  {" ### ", "   # ", "   # ", "   # ", "   # ", "   # ", " ### "},
+	'^':  {"  #  ", " # # ", "#   #", "     ", "     ", "     ", "     "},
+	'_':  {"     ", "     ", "     ", "     ", "     ", "     ", "#####"},
+	'`':  {" #   ", "  #  ", "     ", "     ", "     ", "     ", "     "},
+	'a':  {"     ", "     ", " ### ", "    #", " ####", "#   #", " ####"},
+	'b':  {"#    ", "#    ", "# ## ", "##  #", "#   #", "#   #", "#### "},
+	'c':  {"     ", "     ", " ### ", "#    ", "#    ", "#   #", " ### "},
+	'd':  {"    #", "    #", " ## #", "#  ##", "#   #", "#   #", " ####"},
+	'e':  {"     ", "     ", " ### ", "#   #", "#####", "#    ", " ### "},
+	'f':  {"  ## ", " #  #", " #   ", "###  ", " #   ", " #   ", " #   "},
+	'g':  {"     ", "     ", " ####", "#   #", " ####", "    #", " ### "},
+	'h':  {"#    ", "#    ", "# ## ", "##  #", "#   #", "#   #", "#   #"},
+	'i':  {"  #  ", "     ", " ##  ", "  #  ", "  #  ", "  #  ", " ### "},
+	'j':  {"   # ", "     ", "  ## ", "   # ", "   # ", "#  # ", " ##  "},
+	'k':  {"#    ", "#    ", "#  # ", "# #  ", "##   ", "# #  ", "#  # "},
+	'l':  {" ##  ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  ", " ### "},
+	'm':  {"     ", "     ", "## # ", "# # #", "# # #", "#   #", "#   #"},
+	'n':  {"     ", "     ", "# ## ", "##  #", "#   #", "#   #", "#   #"},
+	'o':  {"     ", "     ", " ### ", "#   #", "#   #", "#   #", " ### "},
+	'p':  {"     ", "     ", "#### ", "#   #", "#### ", "#    ", "#    "},
+	'q':  {"     ", "     ", " ## #", "#  ##", " ####", "    #", "    #"},
+	'r':  {"     ", "     ", "# ## ", "##  #", "#    ", "#    ", "#    "},
+	's':  {"     ", "     ", " ### ", "#    ", " ### ", "    #", "#### "},
+	't':  {" #   ", " #   ", "###  ", " #   ", " #   ", " #  #", "  ## "},
+	'u':  {"     ", "     ", "#   #", "#   #", "#   #", "#  ##", " ## #"},
+	'v':  {"     ", "     ", "#   #", "#   #", "#   #", " # # ", "  #  "},
+	'w':  {"     ", "     ", "#   #", "#   #", "# # #", "# # #", " # # "},
+	'x':  {"     ", "     ", "#   #", " # # ", "  #  ", " # # ", "#   #"},
+	'y':  {"     ", "     ", "#   #", "#   #", " ####", "    #", " ### "},
+	'z':  {"     ", "     ", "#####", "   # ", "  #  ", " #   ", "#####"},
+	'{':  {"   # ", "  #  ", "  #  ", " #   ", "  #  ", "  #  ", "   # "},
+	'|':  {"  #  ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  "},
+	'}':  {" #   ", "  #  ", "  #  ", "   # ", "  #  ", "  #  ", " #   "},
+	'~':  {"     ", "     ", " #   ", "# # #", "   # ", "     ", "     "},
+	'°':  {" ##  ", "#  # ", "#  # ", " ##  ", "     ", "     ", "     "},
+	'µ':  {"     ", "     ", "#   #", "#   #", "#  ##", "## # ", "#    "},
+	'£':  {"  ## ", " #  #", " #   ", "###  ", " #   ", " #   ", "#####"},
+	'€':  {"  ###", " #   ", "#### ", " #   ", "#### ", " #   ", "  ###"},
+	'…':  {"     ", "     ", "     ", "     ", "     ", "     ", "# # #"},
+}
+
+var missingGlyph = [glyphHeight]string{"#####", "#   #", "#   #", "#   #", "#   #", "#   #", "#####"}
+
+// glyph returns the rows of the glyph of a character.
+func glyph(r rune) [glyphHeight]string {
+	if g, ok := glyphs[r]; ok {
+		return g
+	}
+	return missingGlyph
+}
+
+// textWidth returns the width of a text drawn at a size, in pixels.
+func textWidth(s string, size int) int {
+	n := 0
+	for range s {
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return (n*glyphAdvance - 1) * size
+}
+
+// textHeight returns the height of a text drawn at a size, in pixels.
+func textHeight(size int) int {
+	return glyphHeight * size
+}
@@ -0,0 +1,230 @@
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrUnsupportedPath = errors.New("only the panels of d-solo paths can be rendered natively")
+	ErrPanelNotFound   = errors.New("panel not found")
+)
+
+// panelRequest is what a d-solo path asks for.
+type panelRequest struct {
+	dashboardUID string
+	panelID      int64
+	from         string
+	to           string
+	// variables are the values of the template variables set by the var- parameters
+	variables map[string][]string
+}
+
+// parsePath parses a d-solo path, e.g. d-solo/<uid>/<slug>?orgId=1&panelId=2&from=now-1h&to=now&var-job=api.
+func parsePath(path string) (*panelRequest, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	segments := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(segments) < 2 || segments[0] != "d-solo" || segments[1] == "" {
+		return nil, ErrUnsupportedPath
+	}
+
+	params := u.Query()
+	panelID, err := strconv.ParseInt(strings.TrimPrefix(params.Get("panelId"), "panel-"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid panel ID %q", ErrUnsupportedPath, params.Get("panelId"))
+	}
+
+	req := &panelRequest{
+		dashboardUID: segments[1],
+		panelID:      panelID,
+		from:         params.Get("from"),
+		to:           params.Get("to"),
+		variables:    map[string][]string{},
+	}
+	for key, values := range params {
+		if name := strings.TrimPrefix(key, "var-"); name != key {
+			req.variables[name] = values
+		}
+	}
+	return req, nil
+}
+
+// panelModel is the model of a panel, only what the native renderer uses is read.
+type panelModel struct {
+	ID            int64                    `json:"id"`
+	Type          string                   `json:"type"`
+	Title         string                   `json:"title"`
+	Datasource    interface{}              `json:"datasource"`
+	Targets       []map[string]interface{} `json:"targets"`
+	MaxDataPoints int64                    `json:"maxDataPoints"`
+	FieldConfig   fieldConfig              `json:"fieldConfig"`
+	Options       panelOptions             `json:"options"`
+}
+
+type panelOptions struct {
+	ReduceOptions struct {
+		Calcs []string `json:"calcs"`
+	} `json:"reduceOptions"`
+	Orientation string `json:"orientation"`
+	// ColorMode is how the stat panel colors its values, "value", "background" or "none"
+	ColorMode string `json:"colorMode"`
+	// GraphMode is "area" when the stat panel draws a sparkline
+	GraphMode string `json:"graphMode"`
+	// TextMode is what the stat panel shows, "auto", "value", "value_and_name", "name" or "none"
+	TextMode string `json:"textMode"`
+	// DisplayMode is how the bar gauge panel fills its bars, "gradient", "lcd" or "basic"
+	DisplayMode string `json:"displayMode"`
+	Legend      struct {
+		ShowLegend  *bool  `json:"showLegend"`
+		DisplayMode string `json:"displayMode"`
+	} `json:"legend"`
+}
+
+// calc returns the calculation reducing the values of the stat and bar gauge panels.
+func (o panelOptions) calc() string {
+	if len(o.ReduceOptions.Calcs) == 0 {
+		return "lastNotNull"
+	}
+	return o.ReduceOptions.Calcs[0]
+}
+
+// findPanel finds a panel of a dashboard by ID, including the panels of collapsed rows.
+func findPanel(dashboard *simplejson.Json, id int64) (*panelModel, error) {
+	raw := findPanelJSON(dashboard.Get("panels").MustArray(), id)
+	if raw == nil {
+		return nil, ErrPanelNotFound
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	panel := &panelModel{}
+	if err := json.Unmarshal(data, panel); err != nil {
+		return nil, fmt.Errorf("failed to read the model of panel %d: %w", id, err)
+	}
+	return panel, nil
+}
+
+func findPanelJSON(panels []interface{}, id int64) map[string]interface{} {
+	for _, p := range panels {
+		panel, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if panelID, ok := panel["id"].(json.Number); ok && panelID.String() == strconv.FormatInt(id, 10) {
+			return panel
+		}
+		if panelID, ok := panel["id"].(float64); ok && int64(panelID) == id {
+			return panel
+		}
+		if nested, ok := panel["panels"].([]interface{}); ok {
+			if found := findPanelJSON(nested, id); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// variables are the values of the template variables of a dashboard.
+type variables map[string][]string
+
+// variablesOf returns the current values of the template variables of a dashboard, overridden by the values of the
+// request. The "All" value is replaced by the custom all value or by all the options of the variable.
+func variablesOf(dashboard *simplejson.Json, overrides map[string][]string) variables {
+	vars := variables{}
+	for _, v := range dashboard.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		name := variable.Get("name").MustString()
+		if name == "" {
+			continue
+		}
+
+		values := stringValues(variable.Get("current").Get("value").Interface())
+		if len(values) == 1 && values[0] == "$__all" {
+			values = nil
+			if all := variable.Get("allValue").MustString(); all != "" {
+				values = []string{all}
+			} else {
+				for _, o := range variable.Get("options").MustArray() {
+					if value := simplejson.NewFromAny(o).Get("value").MustString(); value != "" && value != "$__all" {
+						values = append(values, value)
+					}
+				}
+			}
+		}
+		vars[name] = values
+	}
+	for name, values := range overrides {
+		vars[name] = values
+	}
+	return vars
+}
+
+func stringValues(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, value := range v {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+var variablePattern = regexp.MustCompile(`\$(\w+)|\$\{(\w+)(?::[^}]*)?\}|\[\[(\w+)(?::[^\]]*)?\]\]`)
+
+// interpolate replaces the template variables of a string by their values. Variables with several values are
+// replaced by a regular expression matching any of them, the unknown variables are left as is for the data
+// sources to replace, e.g. $__interval.
+func (vars variables) interpolate(s string) string {
+	return variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variablePattern.FindStringSubmatch(match)
+		name := groups[1] + groups[2] + groups[3]
+		values, ok := vars[name]
+		if !ok {
+			return match
+		}
+		if len(values) == 1 {
+			return values[0]
+		}
+		return "(" + strings.Join(values, "|") + ")"
+	})
+}
+
+// interpolateJSON replaces the template variables of the strings of a JSON value.
+func (vars variables) interpolateJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return vars.interpolate(v)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			result[key] = vars.interpolateJSON(value)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, value := range v {
+			result = append(result, vars.interpolateJSON(value))
+		}
+		return result
+	}
+	return v
+}
@@ -0,0 +1,81 @@
+package native
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestParsePath(t *testing.T) {
+	t.Run("A d-solo path should be parsed", func(t *testing.T) {
+		req, err := parsePath("d-solo/abc/my-dashboard?orgId=1&panelId=4&from=now-1h&to=now&var-job=api&var-job=web&width=1000")
+		require.NoError(t, err)
+		require.Equal(t, "abc", req.dashboardUID)
+		require.Equal(t, int64(4), req.panelID)
+		require.Equal(t, "now-1h", req.from)
+		require.Equal(t, "now", req.to)
+		require.Equal(t, map[string][]string{"job": {"api", "web"}}, req.variables)
+	})
+
+	t.Run("Dashboard paths and paths without panel should not be supported", func(t *testing.T) {
+		_, err := parsePath("d/abc/my-dashboard?orgId=1&panelId=4")
+		require.ErrorIs(t, err, ErrUnsupportedPath)
+		_, err = parsePath("d-solo/abc/my-dashboard?orgId=1")
+		require.ErrorIs(t, err, ErrUnsupportedPath)
+	})
+}
+
+func TestFindPanel(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{"id": 1, "type": "timeseries", "title": "Requests"},
+			{"id": 2, "type": "row", "collapsed": true, "panels": [
+				{"id": 3, "type": "stat", "title": "Errors", "fieldConfig": {"defaults": {"unit": "percent",
+					"thresholds": {"mode": "absolute", "steps": [{"color": "green", "value": null}, {"color": "red", "value": 5}]}}}}
+			]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	panel, err := findPanel(dashboard, 3)
+	require.NoError(t, err)
+	require.Equal(t, "stat", panel.Type)
+	require.Equal(t, "percent", panel.FieldConfig.Defaults.Unit)
+	require.Equal(t, data.ThresholdsModeAbsolute, panel.FieldConfig.Defaults.Thresholds.Mode)
+	require.Len(t, panel.FieldConfig.Defaults.Thresholds.Steps, 2)
+
+	_, err = findPanel(dashboard, 4)
+	require.ErrorIs(t, err, ErrPanelNotFound)
+}
+
+func TestVariables(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"templating": {"list": [
+			{"name": "env", "current": {"value": "prod"}},
+			{"name": "job", "current": {"value": ["api", "web"]}},
+			{"name": "instance", "current": {"value": ["$__all"]}, "options": [
+				{"value": "$__all"}, {"value": "a"}, {"value": "b"}
+			]},
+			{"name": "region", "current": {"value": "$__all"}, "allValue": ".*"}
+		]}
+	}`))
+	require.NoError(t, err)
+
+	vars := variablesOf(dashboard, map[string][]string{"env": {"dev"}})
+	require.Equal(t, `up{env="dev", job=~"(api|web)", instance=~"(a|b)", region=~".*"} $__interval`,
+		vars.interpolate(`up{env="$env", job=~"${job:regex}", instance=~"[[instance]]", region=~"$region"} $__interval`))
+
+	interpolated := vars.interpolateJSON(map[string]interface{}{
+		"expr":       "rate(requests{env=\"$env\"}[5m])",
+		"datasource": map[string]interface{}{"uid": "${env}"},
+		"hide":       false,
+	})
+	require.Equal(t, map[string]interface{}{
+		"expr":       "rate(requests{env=\"dev\"}[5m])",
+		"datasource": map[string]interface{}{"uid": "dev"},
+		"hide":       false,
+	}, interpolated)
+}
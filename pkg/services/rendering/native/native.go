@@ -0,0 +1,267 @@
+// Package native renders time series, stat and bar gauge panels in process, from the data frames of their
+// queries. It is the fallback of the rendering service when the image renderer plugin is not available, see the
+// fallback_mode setting of the rendering section.
+package native
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+var (
+	ErrUnsupportedPanel = errors.New("panel type cannot be rendered natively")
+	ErrAccessDenied     = errors.New("access denied to the dashboard")
+)
+
+const (
+	defaultWidth  = 1000
+	defaultHeight = 500
+)
+
+type Renderer struct {
+	dashboardService  dashboards.DashboardService
+	dataSourceService datasources.DataSourceService
+	queryService      *query.Service
+	accessControl     accesscontrol.AccessControl
+	log               log.Logger
+}
+
+func ProvideService(dashboardService dashboards.DashboardService, dataSourceService datasources.DataSourceService,
+	queryService *query.Service, accessControl accesscontrol.AccessControl) *Renderer {
+	return &Renderer{
+		dashboardService:  dashboardService,
+		dataSourceService: dataSourceService,
+		queryService:      queryService,
+		accessControl:     accessControl,
+		log:               log.New("rendering.native"),
+	}
+}
+
+// RenderPanel renders the panel of a d-solo path, as the user of the auth options. The panel is drawn with the
+// current values of the template variables of the dashboard, unless the path sets them.
+func (r *Renderer) RenderPanel(ctx context.Context, opts rendering.Opts, rt rendering.RenderType, filePath string) error {
+	req, err := parsePath(opts.Path)
+	if err != nil {
+		return err
+	}
+	user, err := r.signedInUser(ctx, opts.AuthOpts)
+	if err != nil {
+		return err
+	}
+
+	dashQuery := &models.GetDashboardQuery{Uid: req.dashboardUID, OrgId: opts.OrgID}
+	if err := r.dashboardService.GetDashboard(ctx, dashQuery); err != nil {
+		return err
+	}
+	dash := dashQuery.Result
+	if canView, err := guardian.New(ctx, dash.Id, opts.OrgID, user).CanView(); err != nil || !canView {
+		return ErrAccessDenied
+	}
+
+	panel, err := findPanel(dash.Data, req.panelID)
+	if err != nil {
+		return err
+	}
+	if !supportedPanels[panel.Type] {
+		return fmt.Errorf("%w: %s", ErrUnsupportedPanel, panel.Type)
+	}
+
+	from, to := req.from, req.to
+	if from == "" {
+		from = dash.Data.Get("time").Get("from").MustString("now-6h")
+	}
+	if to == "" {
+		to = dash.Data.Get("time").Get("to").MustString("now")
+	}
+	tr, err := parseTimeRange(from, to, opts.Timezone)
+	if err != nil {
+		return err
+	}
+
+	width, height := opts.Width, opts.Height
+	if width <= 0 {
+		width = defaultWidth
+	}
+	if height <= 0 {
+		height = defaultHeight
+	}
+
+	frames, queryErr := r.queryPanel(ctx, user, panel, variablesOf(dash.Data, req.variables), tr, from, to, width)
+	if queryErr != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		r.log.Debug("Failed to query a panel", "dashboardUid", req.dashboardUID, "panelId", req.panelID, "error", queryErr)
+	}
+
+	var c canvas = newRasterCanvas(width, height)
+	if rt == rendering.RenderSVG {
+		c = newSVGCanvas(width, height)
+	}
+	drawPanel(c, width, height, panel, seriesOf(frames, panel.FieldConfig), queryErr, tr, themeOf(opts.Theme))
+
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	if err := c.encode(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// signedInUser returns the user of the auth options with its permissions, which the dashboard guardian and the data
+// source permissions are checked with when access control is enabled.
+func (r *Renderer) signedInUser(ctx context.Context, opts rendering.AuthOpts) (*models.SignedInUser, error) {
+	user := &models.SignedInUser{OrgId: opts.OrgID, UserId: opts.UserID, OrgRole: opts.OrgRole}
+	if r.accessControl == nil || r.accessControl.IsDisabled() {
+		return user, nil
+	}
+
+	permissions, err := r.accessControl.GetUserPermissions(ctx, user, accesscontrol.Options{ReloadCache: false})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the permissions of the user: %w", err)
+	}
+	user.Permissions = map[int64]map[string][]string{opts.OrgID: accesscontrol.GroupScopesByAction(permissions)}
+	return user, nil
+}
+
+func parseTimeRange(from, to, timezone string) (timeRange, error) {
+	location := time.UTC
+	if timezone != "" {
+		if l, err := time.LoadLocation(timezone); err == nil {
+			location = l
+		}
+	}
+
+	dataTimeRange := legacydata.NewDataTimeRange(from, to)
+	fromTime, err := dataTimeRange.ParseFrom(legacydata.WithLocation(location))
+	if err != nil {
+		return timeRange{}, fmt.Errorf("invalid time range: %w", err)
+	}
+	toTime, err := dataTimeRange.ParseTo(legacydata.WithLocation(location))
+	if err != nil {
+		return timeRange{}, fmt.Errorf("invalid time range: %w", err)
+	}
+	if !toTime.After(fromTime) {
+		return timeRange{}, fmt.Errorf("invalid time range: %s to %s", from, to)
+	}
+	return timeRange{from: fromTime, to: toTime, location: location}, nil
+}
+
+// queryPanel runs the queries of a panel, the frames are returned in the order of the queries.
+func (r *Renderer) queryPanel(ctx context.Context, user *models.SignedInUser, panel *panelModel, vars variables,
+	tr timeRange, from, to string, width int) (data.Frames, error) {
+	maxDataPoints := panel.MaxDataPoints
+	if maxDataPoints <= 0 {
+		maxDataPoints = int64(width)
+	}
+	intervalMs := tr.to.Sub(tr.from).Milliseconds() / maxDataPoints
+	if intervalMs < 1 {
+		intervalMs = 1
+	}
+
+	queries := make([]*simplejson.Json, 0, len(panel.Targets))
+	refIDs := make([]string, 0, len(panel.Targets))
+	for _, target := range panel.Targets {
+		if hide, _ := target["hide"].(bool); hide {
+			continue
+		}
+		q := vars.interpolateJSON(target).(map[string]interface{})
+		ref := q["datasource"]
+		if ref == nil {
+			ref = vars.interpolateJSON(panel.Datasource)
+		}
+		ds, err := r.dataSourceRef(ctx, user, ref)
+		if err != nil {
+			return nil, err
+		}
+		q["datasource"] = ds
+		q["maxDataPoints"] = maxDataPoints
+		q["intervalMs"] = intervalMs
+		queries = append(queries, simplejson.NewFromAny(q))
+		refID, _ := q["refId"].(string)
+		refIDs = append(refIDs, refID)
+	}
+	if len(queries) == 0 {
+		return nil, nil
+	}
+
+	resp, err := r.queryService.QueryDataMultipleSources(ctx, user, false, dtos.MetricRequest{
+		From:    from,
+		To:      to,
+		Queries: queries,
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make(data.Frames, 0)
+	for _, refID := range refIDs {
+		res, ok := resp.Responses[refID]
+		if !ok {
+			continue
+		}
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		frames = append(frames, res.Frames...)
+	}
+	return frames, nil
+}
+
+// dataSourceRef resolves a data source reference of a panel or a query, by UID or by name as older dashboards
+// reference data sources by name. References without UID are references to the default data source.
+func (r *Renderer) dataSourceRef(ctx context.Context, user *models.SignedInUser, ref interface{}) (map[string]interface{}, error) {
+	var uid string
+	switch ref := ref.(type) {
+	case map[string]interface{}:
+		uid, _ = ref["uid"].(string)
+	case string:
+		uid = ref
+	}
+
+	switch {
+	case uid == "":
+		query := &models.GetDefaultDataSourceQuery{OrgId: user.OrgId, User: user}
+		if err := r.dataSourceService.GetDefaultDataSource(ctx, query); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"uid": query.Result.Uid, "type": query.Result.Type}, nil
+	case uid == grafanads.DatasourceUID || uid == "-- Grafana --":
+		return map[string]interface{}{"uid": grafanads.DatasourceUID}, nil
+	case expr.IsDataSource(uid):
+		return map[string]interface{}{"uid": expr.DatasourceUID, "type": expr.DatasourceType}, nil
+	}
+
+	query := &models.GetDataSourceQuery{OrgId: user.OrgId, Uid: uid}
+	err := r.dataSourceService.GetDataSource(ctx, query)
+	if errors.Is(err, models.ErrDataSourceNotFound) {
+		query = &models.GetDataSourceQuery{OrgId: user.OrgId, Name: uid}
+		err = r.dataSourceService.GetDataSource(ctx, query)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find data source %q: %w", uid, err)
+	}
+	return map[string]interface{}{"uid": query.Result.Uid, "type": query.Result.Type}, nil
+}
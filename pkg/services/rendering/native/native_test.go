@@ -0,0 +1,36 @@
+package native
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/rendering"
+)
+
+func TestRenderer_SignedInUser(t *testing.T) {
+	opts := rendering.AuthOpts{OrgID: 2, UserID: 3, OrgRole: models.ROLE_VIEWER}
+
+	t.Run("The user should have its permissions when access control is enabled", func(t *testing.T) {
+		r := &Renderer{accessControl: accesscontrolmock.New().WithPermissions([]accesscontrol.Permission{
+			{Action: "dashboards:read", Scope: "dashboards:uid:abc"},
+		})}
+
+		user, err := r.signedInUser(context.Background(), opts)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), user.UserId)
+		require.Equal(t, map[string][]string{"dashboards:read": {"dashboards:uid:abc"}}, user.Permissions[2])
+	})
+
+	t.Run("The user should have no permissions when access control is disabled", func(t *testing.T) {
+		r := &Renderer{accessControl: accesscontrolmock.New().WithDisabled()}
+
+		user, err := r.signedInUser(context.Background(), opts)
+		require.NoError(t, err)
+		require.Nil(t, user.Permissions)
+	})
+}
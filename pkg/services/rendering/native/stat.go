@@ -0,0 +1,114 @@
+package native
+
+import (
+	"image/color"
+	"math"
+	"time"
+)
+
+var backgroundText = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+// drawStat draws the reduced value of each series in a grid, colored by the color mode of the panel, with a
+// sparkline of the series unless the graph mode is none.
+func drawStat(c canvas, b box, p *panelModel, s []*series, size int, t theme) {
+	gap := float64(4 * size)
+	min, max := valueRange(s)
+
+	// the values are laid out side by side in wide panels
+	vertical := p.Options.Orientation == "vertical" || (p.Options.Orientation != "horizontal" && b.h > b.w)
+	cell := box{w: (b.w - gap*float64(len(s)-1)) / float64(len(s)), h: b.h}
+	if vertical {
+		cell = box{w: b.w, h: (b.h - gap*float64(len(s)-1)) / float64(len(s))}
+	}
+
+	for i, serie := range s {
+		cell.x, cell.y = b.x, b.y
+		if vertical {
+			cell.y += float64(i) * (cell.h + gap)
+		} else {
+			cell.x += float64(i) * (cell.w + gap)
+		}
+
+		value, ok := reduce(serie.values, p.Options.calc())
+		text := formatValue(value, serie.config.Unit, serie.config.Decimals)
+		if !ok {
+			text = "No data"
+			if serie.config.NoValue != "" {
+				text = serie.config.NoValue
+			}
+		}
+
+		col := fieldColor(value, serie.config, i, min, max, "thresholds", t)
+		textColor := col
+		switch p.Options.ColorMode {
+		case "background":
+			c.fillRect(cell.x, cell.y, cell.w, cell.h, col)
+			textColor = backgroundText
+		case "none":
+			textColor = t.text
+		}
+
+		if p.Options.GraphMode != "none" && serie.times != nil && cell.h > cell.w/4 {
+			graph := box{x: cell.x, y: cell.y + cell.h/2, w: cell.w, h: cell.h / 2}
+			sparkColor := col
+			if p.Options.ColorMode == "background" {
+				sparkColor = withAlpha(backgroundText, 0.5)
+			}
+			drawSparkline(c, graph, serie, sparkColor, size)
+		}
+
+		showName := p.Options.TextMode == "value_and_name" || p.Options.TextMode == "name" ||
+			((p.Options.TextMode == "" || p.Options.TextMode == "auto") && len(s) > 1)
+		showValue := p.Options.TextMode != "name" && p.Options.TextMode != "none"
+		area := cell.inset(gap)
+		if showName {
+			nameSize := size
+			if !showValue {
+				nameSize = fitSize(serie.name, area.w, area.h/2)
+			}
+			c.text(area.x+area.w/2, area.y, truncate(serie.name, area.w, nameSize), nameSize, textColor, alignCenter)
+			nameHeight := float64(textHeight(nameSize)) + gap
+			area.y += nameHeight
+			area.h = math.Max(area.h-nameHeight, 0)
+		}
+		if showValue {
+			valueSize := fitSize(text, area.w*0.8, area.h/2)
+			c.text(area.x+area.w/2, area.y+(area.h/2-float64(textHeight(valueSize)))/2, text, valueSize, textColor, alignCenter)
+		}
+	}
+}
+
+// drawSparkline draws the values of a series as a filled line over the time range of the series.
+func drawSparkline(c canvas, b box, s *series, col color.NRGBA, size int) {
+	var first, last time.Time
+	min, max := math.Inf(1), math.Inf(-1)
+	for i, v := range s.values {
+		if v == nil {
+			continue
+		}
+		if first.IsZero() {
+			first = s.times[i]
+		}
+		last = s.times[i]
+		min, max = math.Min(min, *v), math.Max(max, *v)
+	}
+	if !last.After(first) {
+		return
+	}
+	if max == min {
+		min, max = min-1, max+1
+	}
+
+	points := make([]point, 0, len(s.values))
+	for i, v := range s.values {
+		if v == nil {
+			continue
+		}
+		points = append(points, point{
+			x: b.x + float64(s.times[i].Sub(first))/float64(last.Sub(first))*b.w,
+			y: b.y + b.h - (*v-min)/(max-min)*b.h,
+		})
+	}
+	c.area(points, b.y+b.h, withAlpha(col, 0.2))
+	c.polyline(points, float64(size), col)
+}
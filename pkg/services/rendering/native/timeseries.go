@@ -0,0 +1,264 @@
+package native
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// timeSteps are the steps between the ticks of the time axis.
+var timeSteps = []time.Duration{
+	time.Second, 5 * time.Second, 10 * time.Second, 15 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 2 * 24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour, 365 * 24 * time.Hour,
+}
+
+// drawTimeSeries draws the series as lines over time, with the axes, the thresholds and the legend.
+func drawTimeSeries(c canvas, b box, p *panelModel, s []*series, tr timeRange, size int, t theme) {
+	gap := float64(4 * size)
+	lineHeight := float64(textHeight(size))
+
+	showLegend := p.Options.Legend.DisplayMode != "hidden"
+	if p.Options.Legend.ShowLegend != nil {
+		showLegend = *p.Options.Legend.ShowLegend
+	}
+	legendHeight := 0.0
+	if showLegend {
+		legendHeight = lineHeight + gap
+	}
+
+	config := s[0].config
+	ticks, min, max := valueTicks(s, int(math.Max((b.h-legendHeight)/(lineHeight*4), 2)))
+	labels := make([]string, 0, len(ticks))
+	axisWidth := 0.0
+	for _, tick := range ticks {
+		label := formatValue(tick, config.Unit, config.Decimals)
+		labels = append(labels, label)
+		axisWidth = math.Max(axisWidth, float64(textWidth(label, size)))
+	}
+	axisWidth += gap
+
+	plot := box{x: b.x + axisWidth, y: b.y + lineHeight/2, w: b.w - axisWidth, h: b.h - lineHeight*1.5 - gap - legendHeight}
+	if plot.w <= 0 || plot.h <= 0 {
+		return
+	}
+	yOf := func(v float64) float64 {
+		y := plot.y + plot.h - (v-min)/(max-min)*plot.h
+		return math.Max(plot.y, math.Min(plot.y+plot.h, y))
+	}
+	span := tr.to.Sub(tr.from)
+	xOf := func(at time.Time) float64 {
+		return plot.x + float64(at.Sub(tr.from))/float64(span)*plot.w
+	}
+
+	for i, tick := range ticks {
+		y := yOf(tick)
+		c.fillRect(plot.x, y, plot.w, 1, t.grid)
+		c.text(plot.x-gap, y-lineHeight/2, labels[i], size, t.weakText, alignRight)
+	}
+
+	format := timeFormat(span)
+	labelWidth := float64(textWidth(time.Time{}.Format(format), size)) + 4*gap
+	for _, tick := range timeTicks(tr, int(plot.w/labelWidth)) {
+		x := xOf(tick)
+		c.fillRect(x, plot.y, 1, plot.h, t.grid)
+		label := tick.In(tr.location).Format(format)
+		// the labels at the edges would be cut
+		if half := float64(textWidth(label, size)) / 2; x-half < b.x || x+half > b.x+b.w {
+			continue
+		}
+		c.text(x, plot.y+plot.h+gap, label, size, t.weakText, alignCenter)
+	}
+
+	drawThresholds(c, plot, config, min, max, yOf, size, t)
+
+	colors := make([]color.NRGBA, 0, len(s))
+	for i, serie := range s {
+		last, _ := reduce(serie.values, "lastNotNull")
+		col := fieldColor(last, serie.config, i, min, max, "palette-classic", t)
+		colors = append(colors, col)
+		if serie.times == nil {
+			continue
+		}
+
+		lineWidth := customNumber(serie.config.Custom, "lineWidth", 1) * float64(size)
+		fillOpacity := customNumber(serie.config.Custom, "fillOpacity", 0) / 100
+		// the lines are broken by null values
+		segment := make([]point, 0, len(serie.values))
+		flush := func() {
+			if fillOpacity > 0 {
+				c.area(segment, plot.y+plot.h, withAlpha(col, fillOpacity))
+			}
+			if len(segment) == 1 {
+				c.fillRect(segment[0].x-lineWidth, segment[0].y-lineWidth, 2*lineWidth, 2*lineWidth, col)
+			}
+			c.polyline(segment, lineWidth, col)
+			segment = segment[:0]
+		}
+		for j, v := range serie.values {
+			at := serie.times[j]
+			if v == nil || at.Before(tr.from) || at.After(tr.to) {
+				if len(segment) > 0 {
+					flush()
+				}
+				continue
+			}
+			segment = append(segment, point{x: xOf(at), y: yOf(*v)})
+		}
+		if len(segment) > 0 {
+			flush()
+		}
+	}
+
+	if showLegend {
+		drawLegend(c, box{x: plot.x, y: b.y + b.h - lineHeight, w: plot.w, h: lineHeight}, s, colors, size, t)
+	}
+}
+
+// drawThresholds draws the thresholds as lines and areas, according to the thresholds style of the field config.
+func drawThresholds(c canvas, plot box, config data.FieldConfig, min, max float64, yOf func(float64) float64, size int, t theme) {
+	style, _ := config.Custom["thresholdsStyle"].(map[string]interface{})
+	mode, _ := style["mode"].(string)
+	if mode == "" || mode == "off" || config.Thresholds == nil {
+		return
+	}
+
+	steps := config.Thresholds.Steps
+	for i, step := range steps {
+		col := parseColor(step.Color, t)
+		from := float64(step.Value)
+		if config.Thresholds.Mode == data.ThresholdsModePercentage {
+			from = min + from/100*(max-min)
+		}
+		to := max
+		if i+1 < len(steps) {
+			to = float64(steps[i+1].Value)
+			if config.Thresholds.Mode == data.ThresholdsModePercentage {
+				to = min + to/100*(max-min)
+			}
+		}
+		if i == 0 {
+			from = min
+		}
+
+		switch mode {
+		case "area", "line+area", "dashed+area":
+			c.fillRect(plot.x, yOf(to), plot.w, yOf(from)-yOf(to), withAlpha(col, 0.15))
+		}
+		if i == 0 {
+			continue
+		}
+		y := yOf(from)
+		switch mode {
+		case "line", "line+area":
+			c.fillRect(plot.x, y, plot.w, float64(size), col)
+		case "dashed", "dashed+area":
+			dash := float64(10 * size)
+			for x := plot.x; x < plot.x+plot.w; x += 2 * dash {
+				c.fillRect(x, y, math.Min(dash, plot.x+plot.w-x), float64(size), col)
+			}
+		}
+	}
+}
+
+func drawLegend(c canvas, b box, s []*series, colors []color.NRGBA, size int, t theme) {
+	swatch := float64(textHeight(size))
+	x := b.x
+	for i, serie := range s {
+		remaining := b.x + b.w - x - swatch*1.5
+		if remaining <= 0 {
+			return
+		}
+		name := truncate(serie.name, remaining, size)
+		c.fillRect(x, b.y+swatch/3, swatch, swatch/3, colors[i])
+		c.text(x+swatch*1.5, b.y, name, size, t.text, alignLeft)
+		x += swatch*1.5 + float64(textWidth(name, size)) + 2*swatch
+	}
+}
+
+// customNumber returns a number of the custom field config, e.g. the width of the lines.
+func customNumber(custom map[string]interface{}, key string, defaultValue float64) float64 {
+	switch v := custom[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return defaultValue
+}
+
+// valueTicks returns the ticks of the value axis, and the range of the axis. The range of the values is extended
+// to the closest ticks, unless min or max are set.
+func valueTicks(s []*series, count int) ([]float64, float64, float64) {
+	min, max := valueRange(s)
+	step := niceStep((max - min) / float64(count))
+	if s[0].config.Min == nil {
+		min = math.Floor(min/step) * step
+	}
+	if s[0].config.Max == nil {
+		max = math.Ceil(max/step) * step
+	}
+
+	ticks := make([]float64, 0, count+1)
+	for i := math.Ceil(min / step); i*step <= max+step/1e6; i++ {
+		ticks = append(ticks, i*step)
+	}
+	return ticks, min, max
+}
+
+// niceStep rounds a step up to 1, 2 or 5 times a power of ten.
+func niceStep(step float64) float64 {
+	if step <= 0 || math.IsNaN(step) || math.IsInf(step, 0) {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(step)))
+	for _, m := range []float64{1, 2, 5} {
+		if step <= m*magnitude {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// timeTicks returns at most count ticks of the time axis, aligned to the time zone of the time range.
+func timeTicks(tr timeRange, count int) []time.Time {
+	span := tr.to.Sub(tr.from)
+	if count < 1 || span <= 0 {
+		return nil
+	}
+	step := timeSteps[len(timeSteps)-1]
+	for _, s := range timeSteps {
+		if span/s <= time.Duration(count) {
+			step = s
+			break
+		}
+	}
+
+	_, offset := tr.from.In(tr.location).Zone()
+	shift := time.Duration(offset) * time.Second
+	first := tr.from.Add(shift).Truncate(step).Add(-shift)
+	if first.Before(tr.from) {
+		first = first.Add(step)
+	}
+
+	ticks := make([]time.Time, 0, count)
+	for at := first; !at.After(tr.to); at = at.Add(step) {
+		ticks = append(ticks, at)
+	}
+	return ticks
+}
+
+func timeFormat(span time.Duration) string {
+	switch {
+	case span <= 5*time.Minute:
+		return "15:04:05"
+	case span <= 24*time.Hour:
+		return "15:04"
+	case span <= 7*24*time.Hour:
+		return "01/02 15:04"
+	}
+	return "2006-01-02"
+}
@@ -0,0 +1,162 @@
+package native
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	shortUnits   = []string{"", " K", " Mil", " Bil", " Tri", " Quadr", " Quint", " Sext", " Sept"}
+	bytesUnits   = []string{" B", " KiB", " MiB", " GiB", " TiB", " PiB", " EiB"}
+	decBytesUnit = []string{" B", " kB", " MB", " GB", " TB", " PB", " EB"}
+	bitsUnits    = []string{" b", " Kib", " Mib", " Gib", " Tib", " Pib", " Eib"}
+	decBitsUnits = []string{" b", " kb", " Mb", " Gb", " Tb", " Pb", " Eb"}
+	bpsUnits     = []string{" bps", " Kbps", " Mbps", " Gbps", " Tbps", " Pbps"}
+	bytesPSUnits = []string{" B/s", " kB/s", " MB/s", " GB/s", " TB/s", " PB/s"}
+	hertzUnits   = []string{" Hz", " kHz", " MHz", " GHz", " THz"}
+
+	// suffixes are the units formatted as a suffix of the value
+	suffixes = map[string]string{
+		"percent":    "%",
+		"reqps":      " req/s",
+		"rps":        " rps",
+		"ops":        " ops/s",
+		"wps":        " wps",
+		"iops":       " io/s",
+		"celsius":    "°C",
+		"fahrenheit": "°F",
+		"kelvin":     " K",
+	}
+
+	// prefixes are the units formatted as a prefix of the value
+	prefixes = map[string]string{
+		"currencyUSD": "$",
+		"currencyEUR": "€",
+		"currencyGBP": "£",
+	}
+
+	// secondsPerUnit are the durations of the time units, in seconds
+	secondsPerUnit = map[string]float64{
+		"ns": 1e-9, "µs": 1e-6, "us": 1e-6, "ms": 1e-3, "s": 1, "m": 60, "h": 3600, "d": 86400,
+	}
+)
+
+// formatValue formats a value in a unit of the panel editor, with the given number of decimals or with as many
+// decimals as the size of the value calls for. The most common units are supported, the other units are appended
+// to the value.
+func formatValue(v float64, unit string, decimals *uint16) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+
+	if suffix, ok := suffixes[unit]; ok {
+		return formatDecimal(v, decimals) + suffix
+	}
+	if prefix, ok := prefixes[unit]; ok {
+		return prefix + formatDecimal(v, decimals)
+	}
+	if seconds, ok := secondsPerUnit[unit]; ok {
+		if v == 0 {
+			return formatDecimal(v, decimals) + " " + unit
+		}
+		return formatDuration(v*seconds, decimals)
+	}
+
+	switch unit {
+	case "", "none":
+		return formatDecimal(v, decimals)
+	case "short":
+		return formatScaled(v, 1000, shortUnits, decimals)
+	case "percentunit":
+		return formatDecimal(v*100, decimals) + "%"
+	case "bytes":
+		return formatScaled(v, 1024, bytesUnits, decimals)
+	case "decbytes":
+		return formatScaled(v, 1000, decBytesUnit, decimals)
+	case "bits":
+		return formatScaled(v, 1024, bitsUnits, decimals)
+	case "decbits":
+		return formatScaled(v, 1000, decBitsUnits, decimals)
+	case "bps":
+		return formatScaled(v, 1000, bpsUnits, decimals)
+	case "Bps":
+		return formatScaled(v, 1000, bytesPSUnits, decimals)
+	case "hertz":
+		return formatScaled(v, 1000, hertzUnits, decimals)
+	}
+
+	switch {
+	case strings.HasPrefix(unit, "prefix:"):
+		return strings.TrimPrefix(unit, "prefix:") + formatDecimal(v, decimals)
+	case strings.HasPrefix(unit, "suffix:"):
+		return formatDecimal(v, decimals) + strings.TrimPrefix(unit, "suffix:")
+	}
+	return formatDecimal(v, decimals) + " " + unit
+}
+
+// formatScaled divides a value by factor as long as there is a larger unit.
+func formatScaled(v float64, factor float64, units []string, decimals *uint16) string {
+	i := 0
+	for math.Abs(v) >= factor && i < len(units)-1 {
+		v /= factor
+		i++
+	}
+	return formatDecimal(v, decimals) + units[i]
+}
+
+// formatDuration formats a duration in seconds in the largest time unit it is at least one of.
+func formatDuration(seconds float64, decimals *uint16) string {
+	abs := math.Abs(seconds)
+	switch {
+	case abs < 1e-6:
+		return formatDecimal(seconds*1e9, decimals) + " ns"
+	case abs < 1e-3:
+		return formatDecimal(seconds*1e6, decimals) + " µs"
+	case abs < 1:
+		return formatDecimal(seconds*1e3, decimals) + " ms"
+	case abs < 60:
+		return formatDecimal(seconds, decimals) + " s"
+	case abs < 3600:
+		return formatDecimal(seconds/60, decimals) + " min"
+	case abs < 86400:
+		return formatDecimal(seconds/3600, decimals) + " hour"
+	case abs < 604800:
+		return formatDecimal(seconds/86400, decimals) + " day"
+	case abs < 31536000:
+		return formatDecimal(seconds/604800, decimals) + " week"
+	}
+	return formatDecimal(seconds/31536000, decimals) + " year"
+}
+
+// formatDecimal formats a value with the given number of decimals, or with three significant digits and no
+// trailing zeros.
+func formatDecimal(v float64, decimals *uint16) string {
+	if decimals != nil {
+		return strconv.FormatFloat(v, 'f', int(*decimals), 64)
+	}
+
+	dec := 0
+	if v != 0 {
+		dec = 2 - int(math.Floor(math.Log10(math.Abs(v))))
+	}
+	if dec < 0 {
+		dec = 0
+	}
+	if dec > 10 {
+		dec = 10
+	}
+	s := strconv.FormatFloat(v, 'f', dec, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
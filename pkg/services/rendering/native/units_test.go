@@ -0,0 +1,45 @@
+package native
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatValue(t *testing.T) {
+	two := uint16(2)
+	testCases := []struct {
+		value    float64
+		unit     string
+		decimals *uint16
+		expected string
+	}{
+		{value: 1234.5678, expected: "1235"},
+		{value: 12.345, expected: "12.3"},
+		{value: 0.012345, expected: "0.0123"},
+		{value: 1.5, expected: "1.5"},
+		{value: 0, expected: "0"},
+		{value: 1.5, decimals: &two, expected: "1.50"},
+		{value: 1500, unit: "short", expected: "1.5 K"},
+		{value: 2500000, unit: "short", expected: "2.5 Mil"},
+		{value: 42, unit: "percent", expected: "42%"},
+		{value: 0.42, unit: "percentunit", expected: "42%"},
+		{value: 1536, unit: "bytes", expected: "1.5 KiB"},
+		{value: 1500, unit: "decbytes", expected: "1.5 kB"},
+		{value: 0.25, unit: "s", expected: "250 ms"},
+		{value: 90, unit: "s", expected: "1.5 min"},
+		{value: 1500, unit: "ms", expected: "1.5 s"},
+		{value: 0, unit: "ms", expected: "0 ms"},
+		{value: 12, unit: "reqps", expected: "12 req/s"},
+		{value: 21.5, unit: "celsius", expected: "21.5°C"},
+		{value: 9.99, unit: "currencyUSD", expected: "$9.99"},
+		{value: 3, unit: "suffix: apples", expected: "3 apples"},
+		{value: 3, unit: "prefix:#", expected: "#3"},
+		{value: 3, unit: "widgets", expected: "3 widgets"},
+		{value: math.NaN(), unit: "short", expected: "NaN"},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, formatValue(tc.value, tc.unit, tc.decimals), "%v %s", tc.value, tc.unit)
+	}
+}
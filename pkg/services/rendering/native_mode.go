@@ -0,0 +1,41 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+func (rs *RenderingService) renderNatively(ctx context.Context, _ string, opts Opts) (*RenderResult, error) {
+	// only single panels can be rendered natively
+	if !strings.HasPrefix(opts.Path, "d-solo/") {
+		rs.log.Warn("Could not render image, only single panels can be rendered natively", "path", opts.Path)
+		if opts.ErrorRenderUnavailable {
+			return nil, ErrRenderUnavailable
+		}
+		return rs.renderUnavailableImage(), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, getRequestTimeout(opts.TimeoutOpts))
+	defer cancel()
+
+	rt := RenderPNG
+	if opts.Encoding == string(RenderSVG) {
+		rt = RenderSVG
+	}
+	filePath, err := rs.getNewFilePath(rt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rs.panelRenderer.RenderPanel(ctx, opts, rt, filePath)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		rs.log.Info("Rendering timed out")
+		return nil, ErrTimeout
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &RenderResult{FilePath: filePath}, nil
+}
@@ -0,0 +1,333 @@
+package rendering
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	queueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "rendering_queue_depth",
+		Help:      "A gauge of the renders waiting in the rendering queue",
+	}, []string{"priority"})
+
+	queueWaitDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "rendering_queue_wait_duration_seconds",
+		Help:      "Histogram of how long renders waited in the rendering queue before being sent to the image renderer",
+		Buckets:   []float64{.01, .05, .1, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"priority"})
+
+	deduplicatedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "rendering_deduplicated_requests_total",
+		Help:      "A counter of the render requests sharing the result of an identical render in flight",
+	}, []string{"priority"})
+)
+
+// renderedFile is the result of a queued render.
+type renderedFile struct {
+	path string
+	name string
+}
+
+type renderJob struct {
+	key      string
+	orgID    int64
+	priority Priority
+	run      func(ctx context.Context) (renderedFile, error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	queued time.Time
+
+	// guarded by the mutex of the queue
+	started  bool
+	finished bool
+	waiters  int
+
+	// collectMu makes the requests sharing the job collect its result one at a time, so that the file is
+	// copied for every request but the last one before the last one gets the file.
+	collectMu sync.Mutex
+	done      chan struct{}
+	result    renderedFile
+	err       error
+}
+
+// orgQueue is the queue of the jobs of a priority. The jobs are queued per organization and the organizations
+// take turns, so that an organization rendering many images doesn't hold up the renders of the others.
+type orgQueue struct {
+	orgs []int64
+	jobs map[int64][]*renderJob
+}
+
+func (q *orgQueue) push(job *renderJob) {
+	if len(q.jobs[job.orgID]) == 0 {
+		q.orgs = append(q.orgs, job.orgID)
+	}
+	q.jobs[job.orgID] = append(q.jobs[job.orgID], job)
+}
+
+func (q *orgQueue) pop() *renderJob {
+	if len(q.orgs) == 0 {
+		return nil
+	}
+	orgID := q.orgs[0]
+	jobs := q.jobs[orgID]
+	job := jobs[0]
+
+	q.orgs = q.orgs[1:]
+	if len(jobs) == 1 {
+		delete(q.jobs, orgID)
+	} else {
+		q.jobs[orgID] = jobs[1:]
+		q.orgs = append(q.orgs, orgID)
+	}
+	return job
+}
+
+func (q *orgQueue) remove(job *renderJob) {
+	jobs := q.jobs[job.orgID]
+	for i, j := range jobs {
+		if j != job {
+			continue
+		}
+		if len(jobs) > 1 {
+			q.jobs[job.orgID] = append(jobs[:i:i], jobs[i+1:]...)
+			return
+		}
+		delete(q.jobs, job.orgID)
+		for k, orgID := range q.orgs {
+			if orgID == job.orgID {
+				q.orgs = append(q.orgs[:k:k], q.orgs[k+1:]...)
+				break
+			}
+		}
+		return
+	}
+}
+
+// renderQueue limits how many renders are sent to the image renderer at once. Waiting renders are started by
+// priority, and by organization in turns within a priority. Identical requests share the same render, which is
+// cancelled when all of them are.
+type renderQueue struct {
+	mu          sync.Mutex
+	concurrency int
+	running     int
+	inFlight    map[string]*renderJob
+	waiting     map[Priority]*orgQueue
+
+	// copyFile copies a rendered file for a request sharing the render of another request, as callers may
+	// remove the file once they are done with it.
+	copyFile func(path string) (string, error)
+	// removeFile removes a rendered file no request collected, as all of them left before the render finished.
+	removeFile func(path string)
+}
+
+func newRenderQueue(concurrency int, copyFile func(path string) (string, error), removeFile func(path string)) *renderQueue {
+	q := &renderQueue{
+		concurrency: concurrency,
+		inFlight:    make(map[string]*renderJob),
+		waiting:     make(map[Priority]*orgQueue),
+		copyFile:    copyFile,
+		removeFile:  removeFile,
+	}
+	for _, p := range priorities {
+		q.waiting[p] = &orgQueue{jobs: make(map[int64][]*renderJob)}
+	}
+	return q
+}
+
+// do queues a render, or joins an identical render in flight, and waits for its result. It returns the error of
+// the context when the context ends first.
+func (q *renderQueue) do(ctx context.Context, key string, orgID int64, priority Priority,
+	run func(ctx context.Context) (renderedFile, error)) (renderedFile, error) {
+	if _, ok := q.waiting[priority]; !ok {
+		priority = PriorityBackground
+	}
+
+	q.mu.Lock()
+	job, ok := q.inFlight[key]
+	if ok {
+		job.waiters++
+		deduplicatedRequests.WithLabelValues(priority.String()).Inc()
+		// a queued render is moved up when a request of a higher priority joins it
+		if !job.started && priority < job.priority {
+			q.waiting[job.priority].remove(job)
+			queueDepth.WithLabelValues(job.priority.String()).Dec()
+			job.priority = priority
+			q.push(job)
+		}
+	} else {
+		jobCtx, cancel := context.WithCancel(detachedContext{ctx})
+		job = &renderJob{
+			key:      key,
+			orgID:    orgID,
+			priority: priority,
+			run:      run,
+			ctx:      jobCtx,
+			cancel:   cancel,
+			queued:   time.Now(),
+			waiters:  1,
+			done:     make(chan struct{}),
+		}
+		q.inFlight[key] = job
+		q.push(job)
+		q.dispatch()
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-job.done:
+		return q.collect(job)
+	case <-ctx.Done():
+		q.leave(job)
+		return renderedFile{}, ctx.Err()
+	}
+}
+
+func (q *renderQueue) push(job *renderJob) {
+	q.waiting[job.priority].push(job)
+	queueDepth.WithLabelValues(job.priority.String()).Inc()
+}
+
+// dispatch starts the waiting jobs while the concurrency allows it. The queue must be locked.
+func (q *renderQueue) dispatch() {
+	for q.concurrency <= 0 || q.running < q.concurrency {
+		var job *renderJob
+		for _, p := range priorities {
+			if job = q.waiting[p].pop(); job != nil {
+				break
+			}
+		}
+		if job == nil {
+			return
+		}
+
+		queueDepth.WithLabelValues(job.priority.String()).Dec()
+		queueWaitDuration.WithLabelValues(job.priority.String()).Observe(time.Since(job.queued).Seconds())
+		job.started = true
+		q.running++
+		go q.execute(job)
+	}
+}
+
+func (q *renderQueue) execute(job *renderJob) {
+	result, err := job.run(job.ctx)
+	job.cancel()
+
+	q.mu.Lock()
+	q.running--
+	if q.inFlight[job.key] == job {
+		delete(q.inFlight, job.key)
+	}
+	job.finished = true
+	abandoned := job.waiters == 0
+	q.dispatch()
+	q.mu.Unlock()
+
+	job.result, job.err = result, err
+	close(job.done)
+	if abandoned {
+		q.discard(job)
+	}
+}
+
+// discard removes the file of a finished job all the requests of which left.
+func (q *renderQueue) discard(job *renderJob) {
+	if job.err == nil && job.result.path != "" && q.removeFile != nil {
+		q.removeFile(job.result.path)
+	}
+}
+
+// collect returns the result of a job to one of its requests. Every request but the last one gets a copy of the
+// rendered file.
+func (q *renderQueue) collect(job *renderJob) (renderedFile, error) {
+	job.collectMu.Lock()
+	defer job.collectMu.Unlock()
+
+	q.mu.Lock()
+	job.waiters--
+	last := job.waiters == 0
+	q.mu.Unlock()
+
+	if job.err != nil || last || job.result.path == "" {
+		return job.result, job.err
+	}
+
+	path, err := q.copyFile(job.result.path)
+	if err != nil {
+		return renderedFile{}, err
+	}
+	return renderedFile{path: path, name: job.result.name}, nil
+}
+
+// leave detaches a request from its job, the job is cancelled when no request waits for it anymore. The file of
+// a job which finished is removed when its last request leaves, as no request collects it.
+func (q *renderQueue) leave(job *renderJob) {
+	q.mu.Lock()
+	job.waiters--
+	if job.waiters > 0 {
+		q.mu.Unlock()
+		return
+	}
+	if job.finished {
+		q.mu.Unlock()
+		// the result is set right after the job is marked finished
+		<-job.done
+		q.discard(job)
+		return
+	}
+	defer q.mu.Unlock()
+
+	if q.inFlight[job.key] == job {
+		delete(q.inFlight, job.key)
+	}
+	if !job.started {
+		q.waiting[job.priority].remove(job)
+		queueDepth.WithLabelValues(job.priority.String()).Dec()
+	}
+	job.cancel()
+}
+
+// detachedContext keeps the values of the context of the request queuing a render, but not its deadline and
+// cancellation, as the render may be shared with other requests.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+// queueKey identifies identical render requests in the queue.
+func queueKey(opts Opts) string {
+	return fmt.Sprintf("%s|%d|%d|%s|%s|%s|%s|%d|%d|%g|%s|%s|%v|%v", RenderPNG, opts.OrgID, opts.UserID, opts.OrgRole,
+		opts.Path, opts.Encoding, opts.Timezone, opts.Width, opts.Height, opts.DeviceScaleFactor, opts.Theme,
+		opts.Timeout, opts.ErrorOpts, headersKey(opts.Headers))
+}
+
+// csvQueueKey identifies identical CSV render requests in the queue.
+func csvQueueKey(opts CSVOpts) string {
+	return fmt.Sprintf("%s|%d|%d|%s|%s|%s|%s|%s|%v", RenderCSV, opts.OrgID, opts.UserID, opts.OrgRole,
+		opts.Path, opts.Encoding, opts.Timezone, opts.Timeout, headersKey(opts.Headers))
+}
+
+func headersKey(headers map[string][]string) string {
+	keys := make([]string, 0, len(headers))
+	for k, values := range headers {
+		keys = append(keys, k+"="+strings.Join(values, ","))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "&")
+}
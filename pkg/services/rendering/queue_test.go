@@ -0,0 +1,252 @@
+package rendering
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// blockQueue queues a render blocking the queue until the returned function is called.
+func blockQueue(t *testing.T, q *renderQueue) func() {
+	t.Helper()
+	started, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		_, _ = q.do(context.Background(), "blocker", 0, PriorityInteractive, func(context.Context) (renderedFile, error) {
+			close(started)
+			<-release
+			return renderedFile{}, nil
+		})
+	}()
+	<-started
+	return func() { close(release) }
+}
+
+// waitForQueued waits until n renders wait in the queue.
+func waitForQueued(t *testing.T, q *renderQueue, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		queued := 0
+		for _, oq := range q.waiting {
+			for _, jobs := range oq.jobs {
+				queued += len(jobs)
+			}
+		}
+		return queued == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestRenderQueue(t *testing.T) {
+	t.Run("Queued renders should be started by priority and by organization in turns", func(t *testing.T) {
+		q := newRenderQueue(1, nil, nil)
+		release := blockQueue(t, q)
+
+		var mu sync.Mutex
+		var order []string
+		var wg sync.WaitGroup
+		queue := func(name string, orgID int64, priority Priority) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := q.do(context.Background(), name, orgID, priority, func(context.Context) (renderedFile, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					order = append(order, name)
+					return renderedFile{}, nil
+				})
+				require.NoError(t, err)
+			}()
+		}
+
+		queued := 0
+		for _, r := range []struct {
+			name     string
+			orgID    int64
+			priority Priority
+		}{
+			{"thumbnail", 1, PriorityBackground},
+			{"screenshot", 1, PriorityAlerting},
+			{"org 1 first", 1, PriorityInteractive},
+			{"org 1 second", 1, PriorityInteractive},
+			{"org 1 third", 1, PriorityInteractive},
+			{"org 2", 2, PriorityInteractive},
+		} {
+			queue(r.name, r.orgID, r.priority)
+			queued++
+			waitForQueued(t, q, queued)
+		}
+
+		release()
+		wg.Wait()
+		require.Equal(t, []string{"org 1 first", "org 2", "org 1 second", "org 1 third", "screenshot", "thumbnail"}, order)
+	})
+
+	t.Run("Identical requests should share a render and get their own copy of the file", func(t *testing.T) {
+		dir := t.TempDir()
+		rs := &RenderingService{Cfg: &setting.Cfg{ImagesDir: dir, CSVsDir: dir}, log: log.New("test")}
+		q := newRenderQueue(1, rs.copyRenderedFile, rs.removeRenderedFile)
+		release := blockQueue(t, q)
+
+		var renders int32
+		files := make([]renderedFile, 3)
+		var wg sync.WaitGroup
+		for i := range files {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var err error
+				files[i], err = q.do(context.Background(), "key", 1, PriorityBackground, func(context.Context) (renderedFile, error) {
+					atomic.AddInt32(&renders, 1)
+					path := filepath.Join(dir, "rendered.png")
+					return renderedFile{path: path}, os.WriteFile(path, []byte("image"), 0600)
+				})
+				require.NoError(t, err)
+			}()
+		}
+		require.Eventually(t, func() bool {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			return q.inFlight["key"] != nil && q.inFlight["key"].waiters == len(files)
+		}, time.Second, time.Millisecond)
+		release()
+		wg.Wait()
+
+		require.Equal(t, int32(1), renders)
+		paths := map[string]bool{}
+		for _, f := range files {
+			content, err := os.ReadFile(f.path)
+			require.NoError(t, err)
+			require.Equal(t, "image", string(content))
+			paths[f.path] = true
+		}
+		require.Len(t, paths, 3)
+	})
+
+	t.Run("A request of a higher priority should move up the identical render it joins", func(t *testing.T) {
+		q := newRenderQueue(1, func(path string) (string, error) { return path, nil }, nil)
+		release := blockQueue(t, q)
+
+		var mu sync.Mutex
+		var order []string
+		var wg sync.WaitGroup
+		queue := func(key string, priority Priority) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := q.do(context.Background(), key, 1, priority, func(context.Context) (renderedFile, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					order = append(order, key)
+					return renderedFile{}, nil
+				})
+				require.NoError(t, err)
+			}()
+		}
+		queue("screenshot", PriorityAlerting)
+		waitForQueued(t, q, 1)
+		queue("thumbnail", PriorityBackground)
+		waitForQueued(t, q, 2)
+		queue("thumbnail", PriorityInteractive)
+		require.Eventually(t, func() bool {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			return q.inFlight["thumbnail"].priority == PriorityInteractive
+		}, time.Second, time.Millisecond)
+
+		release()
+		wg.Wait()
+		require.Equal(t, []string{"thumbnail", "screenshot"}, order)
+	})
+
+	t.Run("Renders should be cancelled when all their requests are", func(t *testing.T) {
+		q := newRenderQueue(1, nil, nil)
+
+		started := make(chan struct{})
+		ctx, cancel := context.WithCancel(context.Background())
+		renderErr := make(chan error, 1)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = q.do(ctx, "running", 1, PriorityInteractive, func(ctx context.Context) (renderedFile, error) {
+				close(started)
+				<-ctx.Done()
+				renderErr <- ctx.Err()
+				return renderedFile{}, ctx.Err()
+			})
+		}()
+		<-started
+
+		queuedCtx, cancelQueued := context.WithCancel(context.Background())
+		var queuedErr error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, queuedErr = q.do(queuedCtx, "queued", 1, PriorityInteractive, func(context.Context) (renderedFile, error) {
+				return renderedFile{}, fmt.Errorf("cancelled renders should not be started")
+			})
+		}()
+		waitForQueued(t, q, 1)
+
+		cancelQueued()
+		waitForQueued(t, q, 0)
+		cancel()
+		wg.Wait()
+
+		require.ErrorIs(t, queuedErr, context.Canceled)
+		require.ErrorIs(t, <-renderErr, context.Canceled)
+		require.Eventually(t, func() bool {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			return q.running == 0 && len(q.inFlight) == 0
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("The files of renders all the requests of which left should be removed", func(t *testing.T) {
+		dir := t.TempDir()
+		rs := &RenderingService{Cfg: &setting.Cfg{ImagesDir: dir, CSVsDir: dir}, log: log.New("test")}
+		q := newRenderQueue(1, rs.copyRenderedFile, rs.removeRenderedFile)
+
+		// the request leaves while the render runs
+		started, finish := make(chan struct{}), make(chan struct{})
+		ctx, cancel := context.WithCancel(context.Background())
+		path := filepath.Join(dir, "running.png")
+		go func() {
+			<-started
+			cancel()
+		}()
+		_, err := q.do(ctx, "running", 1, PriorityInteractive, func(context.Context) (renderedFile, error) {
+			close(started)
+			<-finish
+			return renderedFile{path: path}, os.WriteFile(path, []byte("image"), 0600)
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		close(finish)
+		require.Eventually(t, func() bool {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			return q.running == 0
+		}, time.Second, time.Millisecond)
+		require.NoFileExists(t, path)
+
+		// the request leaves once the render finished
+		path = filepath.Join(dir, "finished.png")
+		require.NoError(t, os.WriteFile(path, []byte("image"), 0600))
+		job := &renderJob{waiters: 1, finished: true, done: make(chan struct{}), result: renderedFile{path: path}}
+		close(job.done)
+		q.leave(job)
+		require.NoFileExists(t, path)
+	})
+}
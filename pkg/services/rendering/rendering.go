@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net/url"
 	"os"
@@ -39,6 +40,8 @@ type RenderingService struct {
 	version         string
 	versionMutex    sync.RWMutex
 	capabilities    []Capability
+	panelRenderer   PanelRenderer
+	queue           *renderQueue
 
 	perRequestRenderKeyProvider renderKeyProvider
 	Cfg                         *setting.Cfg
@@ -46,7 +49,8 @@ type RenderingService struct {
 	RendererPluginManager       plugins.RendererManager
 }
 
-func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, rm plugins.RendererManager) (*RenderingService, error) {
+func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, rm plugins.RendererManager,
+	panelRenderer PanelRenderer) (*RenderingService, error) {
 	// ensure ImagesDir exists
 	err := os.MkdirAll(cfg.ImagesDir, 0700)
 	if err != nil {
@@ -98,7 +102,9 @@ func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, rm p
 		RendererPluginManager: rm,
 		log:                   logger,
 		domain:                domain,
+		panelRenderer:         panelRenderer,
 	}
+	s.queue = newRenderQueue(cfg.RendererQueueConcurrency, s.copyRenderedFile, s.removeRenderedFile)
 	return s, nil
 }
 
@@ -161,6 +167,14 @@ func (rs *RenderingService) Run(ctx context.Context) error {
 		return nil
 	}
 
+	if rs.nativeAvailable() {
+		rs.log = rs.log.New("renderer", "native")
+		rs.log.Info("No image renderer found/installed, rendering panels natively")
+		rs.renderAction = rs.renderNatively
+		<-ctx.Done()
+		return nil
+	}
+
 	rs.log.Debug("No image renderer found/installed. " +
 		"For image rendering support please install the grafana-image-renderer plugin. " +
 		"Read more at https://grafana.com/docs/grafana/latest/administration/image_rendering/")
@@ -177,8 +191,14 @@ func (rs *RenderingService) remoteAvailable() bool {
 	return rs.Cfg.RendererUrl != ""
 }
 
+// nativeAvailable returns true when panels are rendered natively, as no image renderer is available.
+func (rs *RenderingService) nativeAvailable() bool {
+	return rs.Cfg.RendererFallbackMode == setting.RendererFallbackNative && rs.panelRenderer != nil &&
+		!rs.remoteAvailable() && !rs.pluginAvailable()
+}
+
 func (rs *RenderingService) IsAvailable() bool {
-	return rs.remoteAvailable() || rs.pluginAvailable()
+	return rs.remoteAvailable() || rs.pluginAvailable() || rs.nativeAvailable()
 }
 
 func (rs *RenderingService) Version() string {
@@ -259,23 +279,34 @@ func (rs *RenderingService) render(ctx context.Context, opts Opts, renderKeyProv
 		return rs.renderUnavailableImage(), nil
 	}
 
-	rs.log.Info("Rendering", "path", opts.Path)
+	rs.log.Info("Rendering", "path", opts.Path, "priority", opts.Priority)
 	if math.IsInf(opts.DeviceScaleFactor, 0) || math.IsNaN(opts.DeviceScaleFactor) || opts.DeviceScaleFactor == 0 {
 		opts.DeviceScaleFactor = 1
 	}
-	renderKey, err := renderKeyProvider.get(ctx, opts.AuthOpts)
-	if err != nil {
-		return nil, err
-	}
-
-	defer renderKeyProvider.afterRequest(ctx, opts.AuthOpts, renderKey)
 
 	defer func() {
 		metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, -1)))
 	}()
 
 	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
-	return rs.renderAction(ctx, renderKey, opts)
+	file, err := rs.queue.do(ctx, queueKey(opts), opts.OrgID, opts.Priority, func(ctx context.Context) (renderedFile, error) {
+		renderKey, err := renderKeyProvider.get(ctx, opts.AuthOpts)
+		if err != nil {
+			return renderedFile{}, err
+		}
+
+		defer renderKeyProvider.afterRequest(ctx, opts.AuthOpts, renderKey)
+
+		result, err := rs.renderAction(ctx, renderKey, opts)
+		if err != nil || result == nil {
+			return renderedFile{}, err
+		}
+		return renderedFile{path: result.FilePath}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RenderResult{FilePath: file.path}, nil
 }
 
 func (rs *RenderingService) RenderCSV(ctx context.Context, opts CSVOpts, session Session) (*RenderCSVResult, error) {
@@ -298,24 +329,36 @@ func (rs *RenderingService) renderCSV(ctx context.Context, opts CSVOpts, renderK
 		return nil, ErrConcurrentLimitReached
 	}
 
-	if !rs.IsAvailable() {
+	// the native renderer only renders images
+	if !rs.remoteAvailable() && !rs.pluginAvailable() {
 		return nil, ErrRenderUnavailable
 	}
 
-	rs.log.Info("Rendering", "path", opts.Path)
-	renderKey, err := renderKeyProvider.get(ctx, opts.AuthOpts)
-	if err != nil {
-		return nil, err
-	}
-
-	defer renderKeyProvider.afterRequest(ctx, opts.AuthOpts, renderKey)
+	rs.log.Info("Rendering", "path", opts.Path, "priority", opts.Priority)
 
 	defer func() {
 		metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, -1)))
 	}()
 
 	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
-	return rs.renderCSVAction(ctx, renderKey, opts)
+	file, err := rs.queue.do(ctx, csvQueueKey(opts), opts.OrgID, opts.Priority, func(ctx context.Context) (renderedFile, error) {
+		renderKey, err := renderKeyProvider.get(ctx, opts.AuthOpts)
+		if err != nil {
+			return renderedFile{}, err
+		}
+
+		defer renderKeyProvider.afterRequest(ctx, opts.AuthOpts, renderKey)
+
+		result, err := rs.renderCSVAction(ctx, renderKey, opts)
+		if err != nil || result == nil {
+			return renderedFile{}, err
+		}
+		return renderedFile{path: result.FilePath, name: result.FileName}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RenderCSVResult{FilePath: file.path, FileName: file.name}, nil
 }
 
 func (rs *RenderingService) getNewFilePath(rt RenderType) (string, error) {
@@ -326,14 +369,73 @@ func (rs *RenderingService) getNewFilePath(rt RenderType) (string, error) {
 
 	ext := "png"
 	folder := rs.Cfg.ImagesDir
-	if rt == RenderCSV {
+	switch rt {
+	case RenderCSV:
 		ext = "csv"
 		folder = rs.Cfg.CSVsDir
+	case RenderSVG:
+		ext = "svg"
 	}
 
 	return filepath.Abs(filepath.Join(folder, fmt.Sprintf("%s.%s", rand, ext)))
 }
 
+// isRenderedFile returns true when a file was rendered in the images or CSVs directory, rather than being a file
+// such as the image of rendering errors.
+func (rs *RenderingService) isRenderedFile(path string) (bool, error) {
+	folder := filepath.Dir(path)
+	imagesDir, err := filepath.Abs(rs.Cfg.ImagesDir)
+	if err != nil {
+		return false, err
+	}
+	csvsDir, err := filepath.Abs(rs.Cfg.CSVsDir)
+	if err != nil {
+		return false, err
+	}
+	return folder == imagesDir || folder == csvsDir, nil
+}
+
+// copyRenderedFile copies a file rendered in the images or CSVs directory to a new file of the same directory.
+// Other files, such as the images of rendering errors, are not copied.
+func (rs *RenderingService) copyRenderedFile(path string) (string, error) {
+	if rendered, err := rs.isRenderedFile(path); err != nil || !rendered {
+		return path, err
+	}
+	folder := filepath.Dir(path)
+
+	rand, err := util.GetRandomString(20)
+	if err != nil {
+		return "", err
+	}
+	copyPath := filepath.Join(folder, rand+filepath.Ext(path))
+
+	src, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(filepath.Clean(copyPath), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return "", err
+	}
+	return copyPath, dst.Close()
+}
+
+// removeRenderedFile removes a file rendered in the images or CSVs directory, other files are kept.
+func (rs *RenderingService) removeRenderedFile(path string) {
+	if rendered, err := rs.isRenderedFile(path); err != nil || !rendered {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		rs.log.Warn("Failed to remove a rendered file no request waits for", "path", path, "error", err)
+	}
+}
+
 func (rs *RenderingService) getURL(path string) string {
 	if rs.Cfg.RendererUrl != "" {
 		// The backend rendering service can potentially be remote.
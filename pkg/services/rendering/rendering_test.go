@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
@@ -119,6 +120,61 @@ func TestRenderUnavailableError(t *testing.T) {
 	assert.Nil(t, result)
 }
 
+type fakePanelRenderer struct {
+	opts Opts
+	rt   RenderType
+}
+
+func (r *fakePanelRenderer) RenderPanel(_ context.Context, opts Opts, rt RenderType, filePath string) error {
+	r.opts, r.rt = opts, rt
+	return os.WriteFile(filePath, []byte("image"), 0600)
+}
+
+type fakeRenderKeyProvider struct{}
+
+func (fakeRenderKeyProvider) get(context.Context, AuthOpts) (string, error) { return "key", nil }
+
+func (fakeRenderKeyProvider) afterRequest(context.Context, AuthOpts, string) {}
+
+func TestRenderNatively(t *testing.T) {
+	panelRenderer := &fakePanelRenderer{}
+	rs := RenderingService{
+		Cfg:                   &setting.Cfg{ImagesDir: t.TempDir(), RendererFallbackMode: setting.RendererFallbackNative},
+		log:                   log.New("test"),
+		RendererPluginManager: unavailableRendererManager{},
+		panelRenderer:         panelRenderer,
+	}
+	rs.queue = newRenderQueue(0, rs.copyRenderedFile, rs.removeRenderedFile)
+	rs.renderAction = rs.renderNatively
+	require.True(t, rs.IsAvailable())
+
+	t.Run("Panels should be rendered by the panel renderer", func(t *testing.T) {
+		opts := Opts{Path: "d-solo/abc/dashboard?orgId=1&panelId=2", Encoding: "svg", ConcurrentLimit: 1,
+			TimeoutOpts: TimeoutOpts{Timeout: time.Second}}
+		result, err := rs.render(context.Background(), opts, fakeRenderKeyProvider{})
+		require.NoError(t, err)
+		require.Equal(t, ".svg", filepath.Ext(result.FilePath))
+		require.Equal(t, RenderSVG, panelRenderer.rt)
+		require.Equal(t, opts.Path, panelRenderer.opts.Path)
+	})
+
+	t.Run("Dashboards should not be rendered natively", func(t *testing.T) {
+		opts := Opts{Path: "d/abc/dashboard?orgId=1", ConcurrentLimit: 1, ErrorOpts: ErrorOpts{ErrorRenderUnavailable: true}}
+		_, err := rs.render(context.Background(), opts, fakeRenderKeyProvider{})
+		require.ErrorIs(t, err, ErrRenderUnavailable)
+	})
+
+	t.Run("CSVs should not be rendered natively", func(t *testing.T) {
+		_, err := rs.renderCSV(context.Background(), CSVOpts{ConcurrentLimit: 1}, fakeRenderKeyProvider{})
+		require.ErrorIs(t, err, ErrRenderUnavailable)
+	})
+
+	t.Run("The native renderer should only be used when the fallback mode is native", func(t *testing.T) {
+		rs.Cfg.RendererFallbackMode = ""
+		require.False(t, rs.IsAvailable())
+	})
+}
+
 func TestRenderLimitImage(t *testing.T) {
 	path, err := filepath.Abs("../../../")
 	require.NoError(t, err)
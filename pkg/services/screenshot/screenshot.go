@@ -81,7 +81,8 @@ type ScreenshotService interface {
 	Take(ctx context.Context, opts ScreenshotOptions) (*Screenshot, error)
 }
 
-// BrowserScreenshotService takes screenshots using a headless browser.
+// BrowserScreenshotService takes screenshots using a headless browser, or the
+// native renderer when it is the fallback mode of the rendering service.
 type BrowserScreenshotService struct {
 	ds dashboards.DashboardService
 	rs rendering.Service
@@ -134,6 +135,7 @@ func (s *BrowserScreenshotService) Take(ctx context.Context, opts ScreenshotOpti
 		Height:          opts.Height,
 		Theme:           opts.Theme,
 		ConcurrentLimit: setting.AlertingRenderLimit,
+		Priority:        rendering.PriorityAlerting,
 		Path:            path,
 	}
 
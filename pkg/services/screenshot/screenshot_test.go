@@ -108,6 +108,7 @@ func TestBrowserScreenshotService(t *testing.T) {
 		Theme:           DefaultTheme,
 		Path:            "d-solo/foo/bar?orgId=2&panelId=4",
 		ConcurrentLimit: setting.AlertingRenderLimit,
+		Priority:        rendering.PriorityAlerting,
 	}
 
 	opts.DashboardUID = "foo"
@@ -40,6 +40,8 @@ type Query struct {
 	FolderIds     []int64
 	Permission    models.PermissionType
 	Sort          string
+	// IncludeSubfolders also searches the subfolders of FolderIds when nested folders are enabled.
+	IncludeSubfolders bool
 
 	Result models.HitList
 }
@@ -70,6 +72,8 @@ func (s *SearchService) SearchHandler(ctx context.Context, query *Query) error {
 		Limit:         query.Limit,
 		Page:          query.Page,
 		Permission:    query.Permission,
+
+		IncludeSubfolders: query.IncludeSubfolders,
 	}
 
 	if sortOpt, exists := s.sortOptions[query.Sort]; exists {
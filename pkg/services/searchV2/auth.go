@@ -37,10 +37,13 @@ func (a *simpleSQLAuthService) getDashboardTableAuthFilter(user *models.SignedIn
 			Dialect:         a.sql.Dialect,
 			UserId:          user.UserId,
 			PermissionLevel: models.PERMISSION_VIEW,
+			NestedFolders:   permissions.NestedFoldersEnabled(a.sql.Cfg),
 		}
 	}
 
-	return permissions.NewAccessControlDashboardPermissionFilter(user, models.PERMISSION_VIEW, searchstore.TypeDashboard)
+	filter := permissions.NewAccessControlDashboardPermissionFilter(user, models.PERMISSION_VIEW, searchstore.TypeDashboard)
+	filter.NestedFolders = permissions.NestedFoldersEnabled(a.sql.Cfg)
+	return filter
 }
 
 func (a *simpleSQLAuthService) GetDashboardReadFilter(user *models.SignedInUser) (ResourceFilter, error) {
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
 )
 
 const (
@@ -67,13 +68,32 @@ func initIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDashbo
 		return nil
 	}
 
-	// First index the folders to construct folderIdLookup.
-	folderIdLookup := make(map[int64]string, 50)
+	// First collect the folders to construct folderLocationLookup.
+	folderUIDs := make(map[int64]string, 50)
+	folderParentIDs := make(map[int64]int64, 50)
 	for _, dash := range dashboards {
 		if !dash.isFolder {
 			continue
 		}
-		doc := getFolderDashboardDoc(dash)
+		uid := dash.uid
+		if uid == "" {
+			uid = "general"
+		}
+		folderUIDs[dash.id] = uid
+		folderParentIDs[dash.id] = dash.folderID
+	}
+	folderLocationLookup := getFolderLocations(folderUIDs, folderParentIDs)
+
+	// Then index the folders, nested folders are located in their parent folder.
+	for _, dash := range dashboards {
+		if !dash.isFolder {
+			continue
+		}
+		var location string
+		if dash.folderID > 0 {
+			location = folderLocationLookup[dash.folderID]
+		}
+		doc := getFolderDashboardDoc(dash, location)
 		if err := extendDoc(dash.uid, doc); err != nil {
 			return nil, nil, err
 		}
@@ -81,11 +101,6 @@ func initIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDashbo
 		if err := flushIfRequired(false); err != nil {
 			return nil, nil, err
 		}
-		uid := dash.uid
-		if uid == "" {
-			uid = "general"
-		}
-		folderIdLookup[dash.id] = uid
 	}
 
 	// Then each dashboard.
@@ -93,8 +108,7 @@ func initIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDashbo
 		if dash.isFolder {
 			continue
 		}
-		folderUID := folderIdLookup[dash.folderID]
-		location := folderUID
+		location := folderLocationLookup[dash.folderID]
 		doc := getNonFolderDashboardDoc(dash, location)
 		if err := extendDoc(dash.uid, doc); err != nil {
 			return nil, nil, err
@@ -131,7 +145,31 @@ func initIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDashbo
 	return reader, writer, err
 }
 
-func getFolderDashboardDoc(dash dashboard) *bluge.Document {
+// getFolderLocations returns the location of each folder: the UIDs of its parent folders
+// and its own UID separated by "/".
+func getFolderLocations(uids map[int64]string, parentIDs map[int64]int64) map[int64]string {
+	locations := make(map[int64]string, len(uids))
+	var locate func(id int64, depth int) string
+	locate = func(id int64, depth int) string {
+		if location, ok := locations[id]; ok {
+			return location
+		}
+		location := uids[id]
+		if parentID := parentIDs[id]; parentID > 0 && depth < models.MaxNestedFolderDepth {
+			location = locate(parentID, depth+1) + "/" + location
+		}
+		locations[id] = location
+		return location
+	}
+	for id := range uids {
+		locate(id, 0)
+	}
+	return locations
+}
+
+// getFolderDashboardDoc returns the document for a folder. The location is empty for root folders,
+// and the location of the parent folder for nested folders.
+func getFolderDashboardDoc(dash dashboard, location string) *bluge.Document {
 	uid := dash.uid
 	url := fmt.Sprintf("/dashboards/f/%s/%s", dash.uid, dash.slug)
 	if uid == "" {
@@ -141,8 +179,12 @@ func getFolderDashboardDoc(dash dashboard) *bluge.Document {
 		dash.info.Description = ""
 	}
 
-	return newSearchDocument(uid, dash.info.Title, dash.info.Description, url).
+	doc := newSearchDocument(uid, dash.info.Title, dash.info.Description, url).
 		AddField(bluge.NewKeywordField(documentFieldKind, string(entityKindFolder)).Aggregatable().StoreValue())
+	if location != "" {
+		doc.AddField(bluge.NewKeywordField(documentFieldLocation, location).Aggregatable().StoreValue())
+	}
+	return doc
 }
 
 func getNonFolderDashboardDoc(dash dashboard, location string) *bluge.Document {
@@ -314,11 +356,20 @@ func getDocsIDsByLocationPrefix(reader *bluge.Reader, prefix string) ([]string,
 }
 
 func getDashboardLocation(reader *bluge.Reader, dashboardUID string) (string, bool, error) {
+	return getDocumentLocation(reader, dashboardUID, entityKindDashboard)
+}
+
+// getFolderLocation returns the indexed location of a folder, which is empty for root folders.
+func getFolderLocation(reader *bluge.Reader, folderUID string) (string, bool, error) {
+	return getDocumentLocation(reader, folderUID, entityKindFolder)
+}
+
+func getDocumentLocation(reader *bluge.Reader, uid string, kind entityKind) (string, bool, error) {
 	var dashboardLocation string
 	var found bool
 	fullQuery := bluge.NewBooleanQuery()
-	fullQuery.AddMust(bluge.NewTermQuery(dashboardUID).SetField(documentFieldUID))
-	fullQuery.AddMust(bluge.NewTermQuery(string(entityKindDashboard)).SetField(documentFieldKind))
+	fullQuery.AddMust(bluge.NewTermQuery(uid).SetField(documentFieldUID))
+	fullQuery.AddMust(bluge.NewTermQuery(string(kind)).SetField(documentFieldKind))
 	req := bluge.NewAllMatches(fullQuery)
 	documentMatchIterator, err := reader.Search(context.Background(), req)
 	if err != nil {
@@ -326,11 +377,11 @@ func getDashboardLocation(reader *bluge.Reader, dashboardUID string) (string, bo
 	}
 	match, err := documentMatchIterator.Next()
 	for err == nil && match != nil {
+		found = true
 		// load the identifier for this match
 		err = match.VisitStoredFields(func(field string, value []byte) bool {
 			if field == documentFieldLocation {
 				dashboardLocation = string(value)
-				found = true
 				return false
 			}
 			return true
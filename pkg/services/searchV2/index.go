@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/searchV2/extract"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/store"
@@ -37,6 +38,7 @@ type eventStore interface {
 
 // While we migrate away from internal IDs... this lets us lookup values in SQL
 // NOTE: folderId is unique across all orgs
+// The lookup returns the location of the folder: the UIDs of its parent folders and its own UID separated by "/".
 type folderUIDLookup = func(ctx context.Context, folderId int64) (string, error)
 
 type dashboard struct {
@@ -347,6 +349,18 @@ func (i *dashboardIndex) applyEvent(ctx context.Context, orgID int64, kind store
 		return err
 	}
 
+	if len(dbDashboards) > 0 && dbDashboards[0].isFolder {
+		moved, err := i.isFolderMoved(ctx, orgID, dbDashboards[0])
+		if err != nil {
+			return err
+		}
+		if moved {
+			// The location of everything inside the moved folder changes.
+			_, err := i.buildOrgIndex(ctx, orgID)
+			return err
+		}
+	}
+
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
@@ -385,6 +399,26 @@ func (i *dashboardIndex) applyEvent(ctx context.Context, orgID int64, kind store
 	return nil
 }
 
+// isFolderMoved returns true when an indexed folder was moved into another folder.
+func (i *dashboardIndex) isFolderMoved(ctx context.Context, orgID int64, folder dashboard) (bool, error) {
+	reader, ok := i.getOrgReader(orgID)
+	if !ok {
+		return false, nil
+	}
+	indexedLocation, ok, err := getFolderLocation(reader, folder.uid)
+	if err != nil || !ok {
+		return false, err
+	}
+	var location string
+	if folder.folderID > 0 {
+		location, err = i.folderIdLookup(ctx, folder.folderID)
+		if err != nil {
+			return false, err
+		}
+	}
+	return location != indexedLocation, nil
+}
+
 func (i *dashboardIndex) removeDashboard(_ context.Context, writer *bluge.Writer, reader *bluge.Reader, dashboardUID string) (*bluge.Reader, error) {
 	dashboardLocation, ok, err := getDashboardLocation(reader, dashboardUID)
 	if err != nil {
@@ -420,7 +454,15 @@ func (i *dashboardIndex) removeDashboard(_ context.Context, writer *bluge.Writer
 }
 
 func (i *dashboardIndex) removeFolder(_ context.Context, writer *bluge.Writer, reader *bluge.Reader, folderUID string) (*bluge.Reader, error) {
-	ids, err := getDocsIDsByLocationPrefix(reader, folderUID)
+	folderLocation, _, err := getFolderLocation(reader, folderUID)
+	if err != nil {
+		return nil, err
+	}
+	prefix := folderUID
+	if folderLocation != "" {
+		prefix = folderLocation + "/" + folderUID
+	}
+	ids, err := getDocsIDsByLocationPrefix(reader, prefix)
 	if err != nil {
 		return nil, err
 	}
@@ -452,7 +494,15 @@ func (i *dashboardIndex) updateDashboard(ctx context.Context, orgID int64, write
 
 	var doc *bluge.Document
 	if dash.isFolder {
-		doc = getFolderDashboardDoc(dash)
+		var location string
+		if dash.folderID > 0 {
+			var err error
+			location, err = i.folderIdLookup(ctx, dash.folderID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		doc = getFolderDashboardDoc(dash, location)
 		if err := extendDoc(dash.uid, doc); err != nil {
 			return nil, err
 		}
@@ -543,7 +593,7 @@ func (l sqlDashboardLoader) LoadDashboards(ctx context.Context, orgID int64, das
 	}
 
 	// key will allow name or uid
-	lookup, err := loadDatasourceLookup(ctx, orgID, l.sql)
+	lookup, err := LoadDatasourceLookup(ctx, orgID, l.sql)
 	if err != nil {
 		return dashboards, err
 	}
@@ -606,18 +656,26 @@ func (l sqlDashboardLoader) LoadDashboards(ctx context.Context, orgID int64, das
 
 func newFolderIDLookup(sql *sqlstore.SQLStore) folderUIDLookup {
 	return func(ctx context.Context, folderID int64) (string, error) {
-		uid := ""
+		var uids []string
 		err := sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
-			res, err := sess.Query("SELECT uid FROM dashboard WHERE id=?", folderID)
-			if err != nil {
-				return err
-			}
-			if len(res) > 0 {
-				uid = string(res[0]["uid"])
+			// Walk up the folder tree, nested folders store their parent in folder_id.
+			for id := folderID; id > 0 && len(uids) < models.MaxNestedFolderDepth; {
+				res, err := sess.Query("SELECT uid, folder_id FROM dashboard WHERE id=?", id)
+				if err != nil {
+					return err
+				}
+				if len(res) == 0 {
+					break
+				}
+				uids = append([]string{string(res[0]["uid"])}, uids...)
+				id, err = strconv.ParseInt(string(res[0]["folder_id"]), 10, 64)
+				if err != nil {
+					return err
+				}
 			}
 			return nil
 		})
-		return uid, err
+		return strings.Join(uids, "/"), err
 	}
 }
 
@@ -639,7 +697,9 @@ type datasourceQueryResult struct {
 	IsDefault bool   `xorm:"is_default"`
 }
 
-func loadDatasourceLookup(ctx context.Context, orgID int64, sql *sqlstore.SQLStore) (extract.DatasourceLookup, error) {
+// LoadDatasourceLookup returns a lookup resolving the data source references of dashboards in an org by UID or name,
+// empty references resolve to the default data source.
+func LoadDatasourceLookup(ctx context.Context, orgID int64, sql *sqlstore.SQLStore) (extract.DatasourceLookup, error) {
 	byUID := make(map[string]*extract.DataSourceRef, 50)
 	byName := make(map[string]*extract.DataSourceRef, 50)
 	var defaultDS *extract.DataSourceRef
@@ -469,6 +469,75 @@ func TestDashboardIndex_Folders(t *testing.T) {
 	})
 }
 
+var dashboardsWithNestedFolders = []dashboard{
+	{
+		id:       1,
+		uid:      "1",
+		isFolder: true,
+		info: &extract.DashboardInfo{
+			Title: "Root folder",
+		},
+	},
+	{
+		id:       2,
+		uid:      "2",
+		isFolder: true,
+		folderID: 1,
+		info: &extract.DashboardInfo{
+			Title: "Nested folder",
+		},
+	},
+	{
+		id:       3,
+		uid:      "3",
+		folderID: 2,
+		info: &extract.DashboardInfo{
+			Title: "Dashboard in nested folder",
+		},
+	},
+	{
+		id:  4,
+		uid: "4",
+		info: &extract.DashboardInfo{
+			Title: "Dashboard in root",
+		},
+	},
+}
+
+func TestDashboardIndex_NestedFolders(t *testing.T) {
+	t.Run("nested-folders-dashboard-has-folder-path", func(t *testing.T) {
+		_, reader, _ := initTestIndexFromDashes(t, dashboardsWithNestedFolders)
+		location, ok, err := getDashboardLocation(reader, "3")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "1/2", location)
+
+		location, ok, err = getFolderLocation(reader, "2")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "1", location)
+
+		location, ok, err = getFolderLocation(reader, "1")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Empty(t, location)
+	})
+	t.Run("nested-folders-removed-on-folder-removed", func(t *testing.T) {
+		index, reader, writer := initTestIndexFromDashes(t, dashboardsWithNestedFolders)
+		newReader, err := index.removeFolder(context.Background(), writer, reader, "1")
+		require.NoError(t, err)
+		resp := doSearchQuery(context.Background(), testLogger, newReader, testAllowAllFilter,
+			DashboardQuery{Query: "dashboard", Kind: []string{string(entityKindDashboard)}},
+			&NoopQueryExtender{}, "")
+		custom, ok := resp.Frames[0].Meta.Custom.(*customMeta)
+		require.True(t, ok)
+		require.Equal(t, uint64(1), custom.Count) // the dashboard in root
+		_, ok, err = getFolderLocation(newReader, "2")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
+
 var dashboardsWithPanels = []dashboard{
 	{
 		id:  1,
@@ -229,7 +229,7 @@ func (r *SQLAnnotationRepo) Find(ctx context.Context, query *annotations.ItemQue
 		}
 
 		if !ac.IsDisabled(r.sql.Cfg) {
-			acFilter, acArgs, err := getAccessControlFilter(query.SignedInUser)
+			acFilter, acArgs, err := getAccessControlFilter(query.SignedInUser, permissions.NestedFoldersEnabled(r.sql.Cfg))
 			if err != nil {
 				return err
 			}
@@ -255,7 +255,7 @@ func (r *SQLAnnotationRepo) Find(ctx context.Context, query *annotations.ItemQue
 	return items, err
 }
 
-func getAccessControlFilter(user *models.SignedInUser) (string, []interface{}, error) {
+func getAccessControlFilter(user *models.SignedInUser, nestedFolders bool) (string, []interface{}, error) {
 	if user == nil || user.Permissions[user.OrgId] == nil {
 		return "", nil, errors.New("missing permissions")
 	}
@@ -277,7 +277,9 @@ func getAccessControlFilter(user *models.SignedInUser) (string, []interface{}, e
 		}
 		// annotation read permission with scope annotations:type:dashboard allows listing annotations from dashboards which the user can view
 		if t == annotations.Dashboard.String() {
-			dashboardFilter, dashboardParams := permissions.NewAccessControlDashboardPermissionFilter(user, models.PERMISSION_VIEW, searchstore.TypeDashboard).Where()
+			acFilter := permissions.NewAccessControlDashboardPermissionFilter(user, models.PERMISSION_VIEW, searchstore.TypeDashboard)
+			acFilter.NestedFolders = nestedFolders
+			dashboardFilter, dashboardParams := acFilter.Where()
 			filter := fmt.Sprintf("a.dashboard_id IN(SELECT id FROM dashboard WHERE %s)", dashboardFilter)
 			filters = append(filters, filter)
 			params = dashboardParams
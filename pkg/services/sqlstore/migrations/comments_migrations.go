@@ -43,4 +43,11 @@ func addCommentMigrations(mg *Migrator) {
 	mg.AddMigration("create comment table", NewAddTableMigration(commentTable))
 	mg.AddMigration("add index comment.group_id", NewAddIndexMigration(commentTable, commentTable.Indices[0]))
 	mg.AddMigration("add index comment.created", NewAddIndexMigration(commentTable, commentTable.Indices[1]))
+
+	mg.AddMigration("add parent_id column to comment", NewAddColumnMigration(commentTable, &Column{
+		Name: "parent_id", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add index comment.parent_id", NewAddIndexMigration(commentTable, &Index{
+		Cols: []string{"parent_id"}, Type: IndexType,
+	}))
 }
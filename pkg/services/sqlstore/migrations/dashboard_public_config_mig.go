@@ -30,4 +30,14 @@ func addPublicDashboardMigration(mg *Migrator) {
 	// recreate table with proper primary key type
 	mg.AddMigration("recreate dashboard public config v1", NewAddTableMigration(dashboardPublicCfgV1))
 	addTableIndicesMigrations(mg, "v1", dashboardPublicCfgV1)
+
+	mg.AddMigration("add time_selection_enabled column to dashboard_public_config", NewAddColumnMigration(dashboardPublicCfgV1, &Column{
+		Name: "time_selection_enabled", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add max_time_range column to dashboard_public_config", NewAddColumnMigration(dashboardPublicCfgV1, &Column{
+		Name: "max_time_range", Type: DB_NVarchar, Length: 40, Nullable: true,
+	}))
+	mg.AddMigration("add annotations_enabled column to dashboard_public_config", NewAddColumnMigration(dashboardPublicCfgV1, &Column{
+		Name: "annotations_enabled", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
 }
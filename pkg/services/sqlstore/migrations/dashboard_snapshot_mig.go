@@ -71,4 +71,9 @@ func addDashboardSnapshotMigrations(mg *Migrator) {
 
 	mg.AddMigration("Change dashboard_encrypted column to MEDIUMBLOB", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_snapshot MODIFY dashboard_encrypted MEDIUMBLOB;"))
+
+	// the encrypted dashboard json is stored in object storage at this path instead of the database
+	mg.AddMigration("Add storage_path column to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "storage_path", Type: DB_NVarchar, Length: 255, Nullable: true,
+	}))
 }
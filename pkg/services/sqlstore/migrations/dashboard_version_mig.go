@@ -56,4 +56,9 @@ FROM dashboard;`
 	// change column type of dashboard_version.data
 	mg.AddMigration("alter dashboard_version.data to mediumtext v1", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_version MODIFY data MEDIUMTEXT;"))
+
+	// tagged versions are never deleted by the retention policies
+	mg.AddMigration("Add column tag in dashboard_version", NewAddColumnMigration(dashboardVersionV1, &Column{
+		Name: "tag", Type: DB_NVarchar, Length: 255, Nullable: true,
+	}))
 }
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDataSourceUsageMigrations(mg *Migrator) {
+	dataSourceUsageV1 := Table{
+		Name: "data_source_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "datasource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "query_count", Type: DB_BigInt, Nullable: false},
+			{Name: "last_used", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "datasource_uid", "user_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create data_source_usage table v1", NewAddTableMigration(dataSourceUsageV1))
+	addTableIndicesMigrations(mg, "v1", dataSourceUsageV1)
+}
@@ -92,6 +92,11 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddManagedFolderAlertActionsMigration(mg)
 	accesscontrol.AddActionNameMigrator(mg)
 	addPlaylistUIDMigration(mg)
+	addAuditEventMigrations(mg)
+	addDashboardTrashMigrations(mg)
+	addDashboardReportMigrations(mg)
+	addDataSourceUsageMigrations(mg)
+	addDataSourceHealthMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
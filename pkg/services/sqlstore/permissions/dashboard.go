@@ -1,13 +1,16 @@
 package permissions
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 type DashboardPermissionFilter struct {
@@ -16,6 +19,13 @@ type DashboardPermissionFilter struct {
 	UserId          int64
 	OrgId           int64
 	PermissionLevel models.PermissionType
+	// NestedFolders makes permissions on a folder apply to its subfolders and their dashboards.
+	NestedFolders bool
+}
+
+// NestedFoldersEnabled returns true if the nestedFolders feature toggle is enabled.
+func NestedFoldersEnabled(cfg *setting.Cfg) bool {
+	return cfg != nil && cfg.IsFeatureToggleEnabled != nil && cfg.IsFeatureToggleEnabled(featuremgmt.FlagNestedFolders)
 }
 
 func (d DashboardPermissionFilter) Where() (string, []interface{}) {
@@ -29,15 +39,20 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 	}
 
 	falseStr := d.Dialect.BooleanStr(false)
+	depth := 1
+	if d.NestedFolders {
+		depth = models.MaxNestedFolderDepth
+	}
+	parentFoldersJoin, parentFolderIDs, parentFoldersWithoutACL := ParentFoldersSQL(falseStr, depth)
 
 	sql := `(
 		dashboard.id IN (
 			SELECT distinct DashboardId from (
 				SELECT d.id AS DashboardId
-					FROM dashboard AS d
+					FROM dashboard AS d` + parentFoldersJoin + `
 					LEFT JOIN dashboard_acl AS da ON
 						da.dashboard_id = d.id OR
-						da.dashboard_id = d.folder_id
+						da.dashboard_id IN (` + parentFolderIDs + `)
 					WHERE
 						d.org_id = ? AND
 						da.permission >= ? AND
@@ -48,14 +63,13 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 						)
 				UNION
 				SELECT d.id AS DashboardId
-					FROM dashboard AS d
-					LEFT JOIN dashboard AS folder on folder.id = d.folder_id
+					FROM dashboard AS d` + parentFoldersJoin + `
 					LEFT JOIN dashboard_acl AS da ON
 						(
 							-- include default permissions -->
 							da.org_id = -1 AND (
-							  (folder.id IS NOT NULL AND folder.has_acl = ` + falseStr + `) OR
-							  (folder.id IS NULL AND d.has_acl = ` + falseStr + `)
+							  (f1.id IS NOT NULL AND ` + parentFoldersWithoutACL + `) OR
+							  (f1.id IS NULL AND d.has_acl = ` + falseStr + `)
 							)
 						)
 					WHERE
@@ -77,8 +91,29 @@ func (d DashboardPermissionFilter) Where() (string, []interface{}) {
 	return sql, params
 }
 
+// ParentFoldersSQL returns SQL for up to depth parent folders of a dashboard or folder aliased "d":
+//   - join joins the parent folders as "f1" for the direct parent, "f2" for its parent, and so on
+//   - ids is the list of the IDs of the parent folders, for use in "IN (...)"
+//   - withoutACL is true if none of the parent folders has its own permissions
+func ParentFoldersSQL(falseStr string, depth int) (join, ids, withoutACL string) {
+	joins := make([]string, 0, depth)
+	idList := make([]string, 0, depth)
+	noACL := make([]string, 0, depth)
+	child := "d"
+	for i := 1; i <= depth; i++ {
+		parent := fmt.Sprintf("f%d", i)
+		joins = append(joins, fmt.Sprintf("\n\t\t\t\t\tLEFT JOIN dashboard AS %s ON %s.id = %s.folder_id", parent, parent, child))
+		idList = append(idList, child+".folder_id")
+		noACL = append(noACL, fmt.Sprintf("(%s.id IS NULL OR %s.has_acl = %s)", parent, parent, falseStr))
+		child = parent
+	}
+	return strings.Join(joins, ""), strings.Join(idList, ", "), strings.Join(noACL, " AND ")
+}
+
 type AccessControlDashboardPermissionFilter struct {
-	User             *models.SignedInUser
+	User *models.SignedInUser
+	// NestedFolders makes permissions on a folder apply to its subfolders and their dashboards.
+	NestedFolders    bool
 	dashboardActions []string
 	folderActions    []string
 }
@@ -103,6 +138,8 @@ func NewAccessControlDashboardPermissionFilter(user *models.SignedInUser, permis
 	return AccessControlDashboardPermissionFilter{User: user, folderActions: folderActions, dashboardActions: dashboardActions}
 }
 
+// Where returns the filter for the dashboards and folders the user has access to. Permissions on a folder
+// apply to the dashboards in it, and with NestedFolders to its subfolders and their dashboards.
 func (f AccessControlDashboardPermissionFilter) Where() (string, []interface{}) {
 	var args []interface{}
 	builder := strings.Builder{}
@@ -115,12 +152,18 @@ func (f AccessControlDashboardPermissionFilter) Where() (string, []interface{})
 		builder.WriteString(dashFilter.Where)
 		args = append(args, dashFilter.Args...)
 
-		builder.WriteString(" OR dashboard.folder_id IN(SELECT id FROM dashboard WHERE ")
-		dashFolderFilter, _ := accesscontrol.Filter(f.User, "dashboard.uid", dashboards.ScopeFoldersPrefix, f.dashboardActions...)
-
-		builder.WriteString(dashFolderFilter.Where)
+		if f.NestedFolders {
+			builder.WriteString(" OR dashboard.folder_id IN(")
+			dashFolders, dashFolderArgs := f.nestedFolderSelect(f.dashboardActions)
+			builder.WriteString(dashFolders)
+			args = append(args, dashFolderArgs...)
+		} else {
+			builder.WriteString(" OR dashboard.folder_id IN(SELECT id FROM dashboard WHERE ")
+			dashFolderFilter, _ := accesscontrol.Filter(f.User, "dashboard.uid", dashboards.ScopeFoldersPrefix, f.dashboardActions...)
+			builder.WriteString(dashFolderFilter.Where)
+			args = append(args, dashFolderFilter.Args...)
+		}
 		builder.WriteString(")) AND NOT dashboard.is_folder)")
-		args = append(args, dashFolderFilter.Args...)
 	}
 
 	if len(f.folderActions) > 0 {
@@ -128,11 +171,28 @@ func (f AccessControlDashboardPermissionFilter) Where() (string, []interface{})
 			builder.WriteString(" OR ")
 		}
 		builder.WriteString("(")
-		folderFilter, _ := accesscontrol.Filter(f.User, "dashboard.uid", dashboards.ScopeFoldersPrefix, f.folderActions...)
-		builder.WriteString(folderFilter.Where)
+		if f.NestedFolders {
+			builder.WriteString("dashboard.id IN(")
+			folders, folderArgs := f.nestedFolderSelect(f.folderActions)
+			builder.WriteString(folders)
+			builder.WriteString(")")
+			args = append(args, folderArgs...)
+		} else {
+			folderFilter, _ := accesscontrol.Filter(f.User, "dashboard.uid", dashboards.ScopeFoldersPrefix, f.folderActions...)
+			builder.WriteString(folderFilter.Where)
+			args = append(args, folderFilter.Args...)
+		}
 		builder.WriteString(" AND dashboard.is_folder)")
-		args = append(args, folderFilter.Args...)
 	}
 	builder.WriteString(")")
 	return builder.String(), args
 }
+
+// nestedFolderSelect selects the folders that the user can perform the actions on, because of
+// permissions on the folders themselves or on one of their parents.
+func (f AccessControlDashboardPermissionFilter) nestedFolderSelect(actions []string) (string, []interface{}) {
+	return searchstore.NestedFolderSelect(models.MaxNestedFolderDepth, func(alias string) (string, []interface{}) {
+		folderFilter, _ := accesscontrol.Filter(f.User, alias+".uid", dashboards.ScopeFoldersPrefix, actions...)
+		return folderFilter.Where, folderFilter.Args
+	})
+}
@@ -102,6 +102,7 @@ func TestAccessControlDashboardPermissionFilter_Where(t *testing.T) {
 		title            string
 		dashboardActions []string
 		folderActions    []string
+		nestedFolders    bool
 		expectedResult   string
 	}{
 		{
@@ -128,6 +129,13 @@ func TestAccessControlDashboardPermissionFilter_Where(t *testing.T) {
 			folderActions:    nil,
 			expectedResult:   "()",
 		},
+		{
+			title:            "folder and dashboard actions are defined with nested folders",
+			dashboardActions: []string{"test"},
+			folderActions:    []string{"test"},
+			nestedFolders:    true,
+			expectedResult:   "((( 1 = 0 OR dashboard.folder_id IN(SELECT f0.id FROM dashboard AS f0 LEFT JOIN dashboard AS f1 ON f1.id = f0.folder_id LEFT JOIN dashboard AS f2 ON f2.id = f1.folder_id LEFT JOIN dashboard AS f3 ON f3.id = f2.folder_id WHERE f0.is_folder AND ( 1 = 0 OR  1 = 0 OR  1 = 0 OR  1 = 0))) AND NOT dashboard.is_folder) OR (dashboard.id IN(SELECT f0.id FROM dashboard AS f0 LEFT JOIN dashboard AS f1 ON f1.id = f0.folder_id LEFT JOIN dashboard AS f2 ON f2.id = f1.folder_id LEFT JOIN dashboard AS f3 ON f3.id = f2.folder_id WHERE f0.is_folder AND ( 1 = 0 OR  1 = 0 OR  1 = 0 OR  1 = 0)) AND dashboard.is_folder))",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -136,6 +144,7 @@ func TestAccessControlDashboardPermissionFilter_Where(t *testing.T) {
 				User:             &models.SignedInUser{Permissions: map[int64]map[string][]string{}},
 				dashboardActions: testCase.dashboardActions,
 				folderActions:    testCase.folderActions,
+				NestedFolders:    testCase.nestedFolders,
 			}
 
 			query, args := filter.Where()
@@ -95,6 +95,49 @@ func (f FolderFilter) Where() (string, []interface{}) {
 	return sqlIDin("dashboard.folder_id", f.IDs)
 }
 
+// NestedFolderFilter matches the dashboards and folders that are in one of
+// the folders, or in any of their subfolders up to Depth levels deep.
+type NestedFolderFilter struct {
+	IDs   []int64
+	Depth int
+}
+
+func (f NestedFolderFilter) Where() (string, []interface{}) {
+	if len(f.IDs) == 0 {
+		return "", nil
+	}
+	inFolders, params := sqlIDin("dashboard.folder_id", f.IDs)
+	subfolders, subfolderParams := NestedFolderSelect(f.Depth, func(alias string) (string, []interface{}) {
+		return sqlIDin(alias+".id", f.IDs)
+	})
+	return fmt.Sprintf("(%s OR dashboard.folder_id IN (%s))", inFolders, subfolders), append(params, subfolderParams...)
+}
+
+// NestedFolderSelect returns a query for the IDs of the folders for which
+// match is true, either for the folder itself or for one of its parents.
+// match is called with the table alias of each of the depth levels of the
+// folder tree, from the folder itself up to the root.
+func NestedFolderSelect(depth int, match func(alias string) (string, []interface{})) (string, []interface{}) {
+	var sql strings.Builder
+	sql.WriteString("SELECT f0.id FROM dashboard AS f0")
+	for i := 1; i < depth; i++ {
+		fmt.Fprintf(&sql, " LEFT JOIN dashboard AS f%d ON f%d.id = f%d.folder_id", i, i, i-1)
+	}
+
+	var params []interface{}
+	sql.WriteString(" WHERE f0.is_folder AND (")
+	for i := 0; i < depth; i++ {
+		if i > 0 {
+			sql.WriteString(" OR ")
+		}
+		where, args := match(fmt.Sprintf("f%d", i))
+		sql.WriteString(where)
+		params = append(params, args...)
+	}
+	sql.WriteString(")")
+	return sql.String(), params
+}
+
 type DashboardIDFilter struct {
 	IDs []int64
 }
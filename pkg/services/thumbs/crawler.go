@@ -294,6 +294,7 @@ func (r *simpleCrawler) walk(ctx context.Context, id int) {
 			AuthOpts:          authOpts,
 			TimeoutOpts:       r.opts.TimeoutOpts,
 			ConcurrentLimit:   r.opts.ConcurrentLimit,
+			Priority:          rendering.PriorityBackground,
 			Theme:             r.opts.Theme,
 			DeviceScaleFactor: -5, // negative numbers will render larger and then scale down.
 		}, renderingSession)
@@ -33,6 +33,11 @@ var expanders = []registeredExpander{
 		priority: -5,
 		expander: fileExpander{},
 	},
+	{
+		name:     "vault",
+		priority: 0,
+		expander: &vaultExpander{},
+	},
 }
 
 func AddExpander(name string, priority int64, e Expander) {
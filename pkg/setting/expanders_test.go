@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -66,6 +69,57 @@ func TestExpandVar_FileDoesNotExist(t *testing.T) {
 	assert.Empty(t, got)
 }
 
+func TestExpandConfig_Vault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" || r.URL.Path != "/v1/secret/data/grafana/smtp" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"user":"grafana","password":"s3cr3t"}}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	const key = "GF_TEST_SETTING_EXPANDER_VAULT_TOKEN"
+	require.NoError(t, os.Setenv(key, "root"))
+	t.Cleanup(func() {
+		_ = os.Unsetenv(key)
+	})
+
+	file, err := ini.Load([]byte(fmt.Sprintf(`
+[keystore.vault]
+url = %s
+auth_method = token
+token = $__env{%s}
+
+[smtp]
+user = $__vault{kv:secret/grafana/smtp:user}
+password = $__vault{kv:secret/grafana/smtp:password}
+`, server.URL, key)))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		// the other tests expect vault not to be configured
+		require.NoError(t, expandConfig(ini.Empty()))
+	})
+
+	require.NoError(t, expandConfig(file))
+	assert.Equal(t, "grafana", file.Section("smtp").Key("user").String())
+	assert.Equal(t, "s3cr3t", file.Section("smtp").Key("password").String())
+
+	got, err := ExpandVar("$__vault{kv:secret/grafana/smtp:password}")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", got)
+
+	for _, invalid := range []string{
+		"$__vault{kv:secret/grafana/missing:password}",
+		"$__vault{kv:secret:password}",
+		"$__vault{database:database/creds/grafana:password}",
+		"$__vault{kv:secret/grafana/smtp}",
+	} {
+		_, err := ExpandVar(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
 func TestExpanderRegex(t *testing.T) {
 	tests := map[string][][]string{
 		// we should not expand variables where there are none
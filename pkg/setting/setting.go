@@ -49,6 +49,9 @@ const (
 	ApplicationName  = "Grafana"
 )
 
+// RendererFallbackNative is the rendering fallback mode rendering panels in process.
+const RendererFallbackNative = "native"
+
 // zoneInfo names environment variable for setting the path to look for the timezone database in go
 const zoneInfo = "ZONEINFO"
 
@@ -92,8 +95,7 @@ var (
 	SnapShotRemoveExpired bool
 
 	// Dashboard history
-	DashboardVersionsToKeep int
-	MinRefreshInterval      string
+	MinRefreshInterval string
 
 	// User settings
 	AllowUserSignUp         bool
@@ -238,6 +240,11 @@ type Cfg struct {
 	RendererUrl                    string
 	RendererCallbackUrl            string
 	RendererConcurrentRequestLimit int
+	// RendererFallbackMode is how images are rendered when no image renderer is available,
+	// RendererFallbackNative renders single panels in process.
+	RendererFallbackMode string
+	// RendererQueueConcurrency is how many queued renders are sent to the image renderer at once, 0 for no limit.
+	RendererQueueConcurrency int
 
 	// Security
 	DisableInitAdminCreation          bool
@@ -258,17 +265,24 @@ type Cfg struct {
 	CSPTemplate           string
 	AngularSupportEnabled bool
 
-	TempDataLifetime                 time.Duration
-	PluginsEnableAlpha               bool
-	PluginsAppsSkipVerifyTLS         bool
-	PluginSettings                   PluginSettings
-	PluginsAllowUnsigned             []string
-	PluginCatalogURL                 string
-	PluginCatalogHiddenPlugins       []string
-	PluginAdminEnabled               bool
-	PluginAdminExternalManageEnabled bool
-	DisableSanitizeHtml              bool
-	EnterpriseLicensePath            string
+	TempDataLifetime                   time.Duration
+	PluginsEnableAlpha                 bool
+	PluginsAppsSkipVerifyTLS           bool
+	PluginSettings                     PluginSettings
+	PluginsAllowUnsigned               []string
+	PluginCatalogURL                   string
+	PluginCatalogHiddenPlugins         []string
+	PluginAdminEnabled                 bool
+	PluginAdminExternalManageEnabled   bool
+	PluginsHotReloadEnabled            bool
+	PluginsHotReloadInterval           time.Duration
+	PluginsPrivateRepository           string
+	PluginsHealthCheckInterval         time.Duration
+	PluginsHealthCheckFailureThreshold int
+	PluginsCircuitBreakerOpenDuration  time.Duration
+	PluginsRestartMaxBackoff           time.Duration
+	DisableSanitizeHtml                bool
+	EnterpriseLicensePath              string
 
 	// Metrics
 	MetricsEndpointEnabled           bool
@@ -279,12 +293,16 @@ type Cfg struct {
 
 	// Dashboards
 	DefaultHomeDashboardPath string
+	DashboardTrashRetention  time.Duration
+
+	DashboardVersionsRetention DashboardVersionsRetention
 
 	// Auth
 	LoginCookieName              string
 	LoginMaxInactiveLifetime     time.Duration
 	LoginMaxLifetime             time.Duration
 	TokenRotationIntervalMinutes int
+	MaxConcurrentSessions        int
 	SigV4AuthEnabled             bool
 	SigV4VerboseLogging          bool
 	BasicAuthEnabled             bool
@@ -375,6 +393,8 @@ type Cfg struct {
 
 	// Snapshots
 	SnapshotPublicMode bool
+	// SnapshotStorageURL is the bucket URL or disk path where snapshot dashboards are stored, the database when empty
+	SnapshotStorageURL string
 
 	ErrTemplateName string
 
@@ -438,6 +458,21 @@ type Cfg struct {
 
 	DashboardPreviews DashboardPreviewsSettings
 
+	// Audit log
+	Audit AuditSettings
+
+	// Scheduled dashboard reports
+	DashboardReports DashboardReportsSettings
+
+	// Default limits of the requests to each data source
+	DataSourceLimits DataSourceLimitsSettings
+
+	// Background health checks of the data sources
+	DataSourceHealth DataSourceHealthSettings
+
+	// HashiCorp Vault used by the vault expander
+	Vault VaultSettings
+
 	// Access Control
 	RBACEnabled         bool
 	RBACPermissionCache bool
@@ -915,11 +950,20 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	// read dashboard settings
 	dashboards := iniFile.Section("dashboards")
-	DashboardVersionsToKeep = dashboards.Key("versions_to_keep").MustInt(20)
 	MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
 
+	trashRetention, err := gtime.ParseDuration(valueAsString(dashboards, "trash_retention", "30d"))
+	if err != nil {
+		return err
+	}
+	cfg.DashboardTrashRetention = trashRetention
+
+	if err := cfg.readDashboardVersionsSettings(dashboards); err != nil {
+		return err
+	}
+
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
 	}
@@ -1010,6 +1054,18 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	cfg.DashboardPreviews = readDashboardPreviewsSettings(iniFile)
 
+	if err := cfg.readAuditSettings(iniFile); err != nil {
+		return err
+	}
+
+	if err := cfg.readDashboardReportsSettings(iniFile); err != nil {
+		return err
+	}
+
+	cfg.readDataSourceLimitsSettings(iniFile)
+	cfg.readDataSourceHealthSettings(iniFile)
+	cfg.readVaultSettings(iniFile)
+
 	if VerifyEmailEnabled && !cfg.Smtp.Enabled {
 		cfg.Logger.Warn("require_email_validation is enabled but smtp is disabled")
 	}
@@ -1268,6 +1324,11 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 		cfg.TokenRotationIntervalMinutes = 2
 	}
 
+	cfg.MaxConcurrentSessions = auth.Key("max_concurrent_sessions_per_user").MustInt(0)
+	if cfg.MaxConcurrentSessions < 0 {
+		cfg.MaxConcurrentSessions = 0
+	}
+
 	DisableLoginForm = auth.Key("disable_login_form").MustBool(false)
 	DisableSignoutMenu = auth.Key("disable_signout_menu").MustBool(false)
 	OAuthAutoLogin = auth.Key("oauth_auto_login").MustBool(false)
@@ -1407,6 +1468,8 @@ func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	}
 
 	cfg.RendererConcurrentRequestLimit = renderSec.Key("concurrent_render_request_limit").MustInt(30)
+	cfg.RendererFallbackMode = valueAsString(renderSec, "fallback_mode", "")
+	cfg.RendererQueueConcurrency = renderSec.Key("queue_concurrency").MustInt(5)
 	cfg.ImagesDir = filepath.Join(cfg.DataPath, "png")
 	cfg.CSVsDir = filepath.Join(cfg.DataPath, "csv")
 
@@ -1445,6 +1508,7 @@ func readSnapshotsSettings(cfg *Cfg, iniFile *ini.File) error {
 	ExternalEnabled = snapshots.Key("external_enabled").MustBool(true)
 	SnapShotRemoveExpired = snapshots.Key("snapshot_remove_expired").MustBool(true)
 	cfg.SnapshotPublicMode = snapshots.Key("public_mode").MustBool(false)
+	cfg.SnapshotStorageURL = valueAsString(snapshots, "storage_url", "")
 
 	return nil
 }
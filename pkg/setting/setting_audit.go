@@ -0,0 +1,53 @@
+package setting
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"gopkg.in/ini.v1"
+)
+
+type AuditSettings struct {
+	Enabled bool
+	// Retention is how long audit events are kept in the database, zero keeps them forever
+	Retention time.Duration
+	// Sinks are the additional destinations audit events are exported to, such as "file" or "loki"
+	Sinks []string
+
+	FilePath string
+
+	LokiURL      string
+	LokiTenantID string
+	LokiUser     string
+	LokiPassword string
+}
+
+func (cfg *Cfg) readAuditSettings(iniFile *ini.File) error {
+	section := iniFile.Section("audit")
+
+	s := AuditSettings{}
+	s.Enabled = section.Key("enabled").MustBool(false)
+
+	retention, err := gtime.ParseDuration(valueAsString(section, "retention", "90d"))
+	if err != nil {
+		return err
+	}
+	s.Retention = retention
+
+	for _, sink := range strings.Split(valueAsString(section, "sinks", ""), ",") {
+		if sink = strings.TrimSpace(sink); sink != "" {
+			s.Sinks = append(s.Sinks, sink)
+		}
+	}
+
+	s.FilePath = valueAsString(section, "file_path", filepath.Join(cfg.LogsPath, "audit.log"))
+	s.LokiURL = valueAsString(section, "loki_url", "")
+	s.LokiTenantID = valueAsString(section, "loki_tenant_id", "")
+	s.LokiUser = valueAsString(section, "loki_basic_auth_user", "")
+	s.LokiPassword = valueAsString(section, "loki_basic_auth_password", "")
+
+	cfg.Audit = s
+	return nil
+}
@@ -0,0 +1,36 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"gopkg.in/ini.v1"
+)
+
+type DashboardReportsSettings struct {
+	Enabled bool
+	// RenderTimeout is the timeout of rendering the dashboard or a panel of a report
+	RenderTimeout time.Duration
+	// ConcurrentRenderLimit is the number of concurrent renderings above which reports fail to render
+	ConcurrentRenderLimit int
+	// HistoryRetention is how long deliveries of reports are kept, zero keeps them forever
+	HistoryRetention time.Duration
+}
+
+func (cfg *Cfg) readDashboardReportsSettings(iniFile *ini.File) error {
+	section := iniFile.Section("dashboard_reports")
+
+	s := DashboardReportsSettings{}
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.RenderTimeout = section.Key("render_timeout").MustDuration(60 * time.Second)
+	s.ConcurrentRenderLimit = section.Key("concurrent_render_limit").MustInt(5)
+
+	retention, err := gtime.ParseDuration(valueAsString(section, "history_retention", "90d"))
+	if err != nil {
+		return err
+	}
+	s.HistoryRetention = retention
+
+	cfg.DashboardReports = s
+	return nil
+}
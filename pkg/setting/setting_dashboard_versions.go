@@ -0,0 +1,89 @@
+package setting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"gopkg.in/ini.v1"
+)
+
+// DashboardVersionsRetention is the policy deciding which dashboard versions are deleted by the cleanup.
+// The latest version of a dashboard and tagged versions are never deleted.
+type DashboardVersionsRetention struct {
+	// MaxCount is the number of latest versions kept per dashboard once thinned, zero keeps any number of versions
+	MaxCount int
+	// MaxAge is how long versions are kept, zero keeps them regardless of their age
+	MaxAge time.Duration
+	// Thinning reduces the number of old versions, sorted by age
+	Thinning []DashboardVersionThinningRule
+}
+
+// DashboardVersionThinningRule keeps one version per Interval of the versions older than OlderThan.
+type DashboardVersionThinningRule struct {
+	OlderThan time.Duration
+	Interval  time.Duration
+}
+
+func (cfg *Cfg) readDashboardVersionsSettings(section *ini.Section) error {
+	r := DashboardVersionsRetention{}
+	// versions_to_keep = 0 has always kept the latest version, a negative value keeps any number of versions
+	r.MaxCount = section.Key("versions_to_keep").MustInt(20)
+	switch {
+	case r.MaxCount < 0:
+		r.MaxCount = 0
+	case r.MaxCount == 0:
+		r.MaxCount = 1
+	}
+
+	maxAge, err := gtime.ParseDuration(valueAsString(section, "versions_max_age", "0"))
+	if err != nil {
+		return err
+	}
+	r.MaxAge = maxAge
+
+	thinning, err := parseDashboardVersionThinning(valueAsString(section, "versions_thinning", ""))
+	if err != nil {
+		return err
+	}
+	r.Thinning = thinning
+
+	cfg.DashboardVersionsRetention = r
+	return nil
+}
+
+// parseDashboardVersionThinning parses a comma separated list of <older than>:<interval> rules, e.g. "7d:1d, 90d:1M".
+func parseDashboardVersionThinning(s string) ([]DashboardVersionThinningRule, error) {
+	var rules []DashboardVersionThinningRule
+	for _, rule := range strings.Split(s, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		parts := strings.Split(rule, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid dashboard versions thinning rule %q, expected <older than>:<interval>", rule)
+		}
+		olderThan, err := gtime.ParseDuration(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid dashboard versions thinning rule %q: %w", rule, err)
+		}
+		interval, err := gtime.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid dashboard versions thinning rule %q: %w", rule, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid dashboard versions thinning rule %q, the interval must be positive", rule)
+		}
+
+		rules = append(rules, DashboardVersionThinningRule{OlderThan: olderThan, Interval: interval})
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].OlderThan < rules[j].OlderThan
+	})
+	return rules, nil
+}
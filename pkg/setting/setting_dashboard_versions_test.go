@@ -0,0 +1,56 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadDashboardVersionsSettings(t *testing.T) {
+	const day = 24 * time.Hour
+
+	t.Run("should keep the versions_to_keep latest versions by default", func(t *testing.T) {
+		cfg := NewCfg()
+		require.NoError(t, cfg.readDashboardVersionsSettings(ini.Empty().Section("dashboards")))
+		require.Equal(t, DashboardVersionsRetention{MaxCount: 20}, cfg.DashboardVersionsRetention)
+	})
+
+	t.Run("should parse max age and thinning rules sorted by age", func(t *testing.T) {
+		f := ini.Empty()
+		s := f.Section("dashboards")
+		_, err := s.NewKey("versions_to_keep", "-1")
+		require.NoError(t, err)
+		_, err = s.NewKey("versions_max_age", "2y")
+		require.NoError(t, err)
+		_, err = s.NewKey("versions_thinning", "90d:1M, 7d:1d")
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.NoError(t, cfg.readDashboardVersionsSettings(s))
+		require.Equal(t, 0, cfg.DashboardVersionsRetention.MaxCount)
+		require.Equal(t, time.Duration(2*365.25*float64(day)), cfg.DashboardVersionsRetention.MaxAge)
+		require.Len(t, cfg.DashboardVersionsRetention.Thinning, 2)
+		require.Equal(t, DashboardVersionThinningRule{OlderThan: 7 * day, Interval: day}, cfg.DashboardVersionsRetention.Thinning[0])
+		require.Equal(t, 90*day, cfg.DashboardVersionsRetention.Thinning[1].OlderThan)
+	})
+
+	t.Run("should keep the latest version when versions_to_keep is 0", func(t *testing.T) {
+		f := ini.Empty()
+		s := f.Section("dashboards")
+		_, err := s.NewKey("versions_to_keep", "0")
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.NoError(t, cfg.readDashboardVersionsSettings(s))
+		require.Equal(t, 1, cfg.DashboardVersionsRetention.MaxCount)
+	})
+
+	t.Run("should fail on invalid thinning rules", func(t *testing.T) {
+		for _, rule := range []string{"7d", "7d:1d:1M", "week:1d", "7d:0"} {
+			_, err := parseDashboardVersionThinning(rule)
+			require.Error(t, err, rule)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// DataSourceHealthSettings configure the background health checks of the data sources.
+type DataSourceHealthSettings struct {
+	Enabled bool
+	// Interval is how often the health of each data source is checked
+	Interval time.Duration
+	// Timeout is how long the health check of a data source may take
+	Timeout time.Duration
+	// HistoryRetention is how long the results of the health checks are kept
+	HistoryRetention time.Duration
+	// MaxConcurrentChecks is the number of data sources checked at the same time
+	MaxConcurrentChecks int
+}
+
+func (cfg *Cfg) readDataSourceHealthSettings(iniFile *ini.File) {
+	section := iniFile.Section("datasource_health")
+
+	s := DataSourceHealthSettings{}
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.Interval = section.Key("interval").MustDuration(5 * time.Minute)
+	if s.Interval < time.Minute {
+		cfg.Logger.Warn("interval of datasource_health must be at least 1m, using 1m", "value", s.Interval)
+		s.Interval = time.Minute
+	}
+	s.Timeout = section.Key("timeout").MustDuration(30 * time.Second)
+	s.HistoryRetention = section.Key("history_retention").MustDuration(24 * time.Hour)
+	s.MaxConcurrentChecks = section.Key("max_concurrent_checks").MustInt(4)
+	if s.MaxConcurrentChecks < 1 {
+		s.MaxConcurrentChecks = 1
+	}
+
+	cfg.DataSourceHealth = s
+}
@@ -0,0 +1,36 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// DataSourceLimitsSettings are the default limits of the requests to each data source, which the data sources
+// can override in their JSON data.
+type DataSourceLimitsSettings struct {
+	// MaxConcurrentQueries is the number of concurrent requests to a data source, 0 is unlimited
+	MaxConcurrentQueries int
+	// MaxUserShare is the share of MaxConcurrentQueries a single user can take, 0 is unlimited
+	MaxUserShare float64
+	// RequestsPerSecond is the rate of requests to a data source, 0 is unlimited
+	RequestsPerSecond float64
+	// QueueTimeout is how long a request waits for the limits of a data source before being rejected
+	QueueTimeout time.Duration
+}
+
+func (cfg *Cfg) readDataSourceLimitsSettings(iniFile *ini.File) {
+	section := iniFile.Section("datasource_limits")
+
+	s := DataSourceLimitsSettings{}
+	s.MaxConcurrentQueries = section.Key("max_concurrent_queries").MustInt(0)
+	s.MaxUserShare = section.Key("max_user_share").MustFloat64(0)
+	if s.MaxUserShare < 0 || s.MaxUserShare > 1 {
+		cfg.Logger.Warn("max_user_share of datasource_limits must be between 0 and 1, ignoring it", "value", s.MaxUserShare)
+		s.MaxUserShare = 0
+	}
+	s.RequestsPerSecond = section.Key("requests_per_second").MustFloat64(0)
+	s.QueueTimeout = section.Key("queue_timeout").MustDuration(30 * time.Second)
+
+	cfg.DataSourceLimits = s
+}
@@ -2,6 +2,7 @@ package setting
 
 import (
 	"strings"
+	"time"
 
 	"gopkg.in/ini.v1"
 )
@@ -37,6 +38,22 @@ func (cfg *Cfg) readPluginSettings(iniFile *ini.File) error {
 	cfg.PluginCatalogURL = pluginsSection.Key("plugin_catalog_url").MustString("https://grafana.com/grafana/plugins/")
 	cfg.PluginAdminEnabled = pluginsSection.Key("plugin_admin_enabled").MustBool(true)
 	cfg.PluginAdminExternalManageEnabled = pluginsSection.Key("plugin_admin_external_manage_enabled").MustBool(false)
+	cfg.PluginsPrivateRepository = pluginsSection.Key("private_repository").MustString("")
+	cfg.PluginsHotReloadEnabled = pluginsSection.Key("hot_reload_enabled").MustBool(false)
+	cfg.PluginsHotReloadInterval = pluginsSection.Key("hot_reload_interval").MustDuration(10 * time.Second)
+	if cfg.PluginsHotReloadInterval <= 0 {
+		cfg.PluginsHotReloadInterval = 10 * time.Second
+	}
+	cfg.PluginsHealthCheckInterval = pluginsSection.Key("health_check_interval").MustDuration(30 * time.Second)
+	cfg.PluginsHealthCheckFailureThreshold = pluginsSection.Key("health_check_failure_threshold").MustInt(3)
+	cfg.PluginsCircuitBreakerOpenDuration = pluginsSection.Key("circuit_breaker_open_duration").MustDuration(30 * time.Second)
+	if cfg.PluginsCircuitBreakerOpenDuration <= 0 {
+		cfg.PluginsCircuitBreakerOpenDuration = 30 * time.Second
+	}
+	cfg.PluginsRestartMaxBackoff = pluginsSection.Key("restart_max_backoff").MustDuration(5 * time.Minute)
+	if cfg.PluginsRestartMaxBackoff <= 0 {
+		cfg.PluginsRestartMaxBackoff = 5 * time.Minute
+	}
 
 	catalogHiddenPlugins := pluginsSection.Key("plugin_catalog_hidden_plugins").MustString("")
 	for _, plug := range strings.Split(catalogHiddenPlugins, ",") {
@@ -0,0 +1,126 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/infra/vault"
+)
+
+// VaultSettings configure the access to HashiCorp Vault of the vault expander.
+type VaultSettings struct {
+	URL             string
+	Namespace       string
+	AuthMethod      string
+	Token           string
+	AppRoleMount    string
+	AppRoleRoleID   string
+	AppRoleSecretID string
+	Timeout         time.Duration
+	// SecretsRefreshInterval is how often the Vault secrets of the provisioned data sources are resolved again,
+	// 0 when they are only resolved when the data sources are provisioned
+	SecretsRefreshInterval time.Duration
+}
+
+// Enabled returns true when an auth method is set, Vault isn't used otherwise.
+func (s VaultSettings) Enabled() bool {
+	return s.AuthMethod != ""
+}
+
+func (cfg *Cfg) readVaultSettings(iniFile *ini.File) {
+	cfg.Vault = readVaultSection(iniFile)
+}
+
+func readVaultSection(iniFile *ini.File) VaultSettings {
+	section := iniFile.Section("keystore.vault")
+
+	s := VaultSettings{}
+	s.URL = section.Key("url").MustString("")
+	s.Namespace = section.Key("namespace").MustString("")
+	s.AuthMethod = section.Key("auth_method").MustString("")
+	s.Token = section.Key("token").MustString("")
+	s.AppRoleMount = section.Key("approle_mount").MustString("approle")
+	s.AppRoleRoleID = section.Key("approle_role_id").MustString("")
+	s.AppRoleSecretID = section.Key("approle_secret_id").MustString("")
+	s.Timeout = section.Key("timeout").MustDuration(10 * time.Second)
+	if s.Timeout <= 0 {
+		s.Timeout = 10 * time.Second
+	}
+	s.SecretsRefreshInterval = section.Key("secrets_refresh_interval").MustDuration(5 * time.Minute)
+	return s
+}
+
+// vaultExpander expands $__vault{kv:<mount>/<path>:<field>} to the field of a secret of a KV version 2 secrets
+// engine. It is set up with the [keystore.vault] section once the env and file expanders have run, so that the
+// credentials of Vault can be read from the environment or from files.
+type vaultExpander struct {
+	mu      sync.RWMutex
+	client  *vault.Client
+	timeout time.Duration
+}
+
+func (e *vaultExpander) SetupExpander(file *ini.File) error {
+	s := readVaultSection(file)
+
+	var client *vault.Client
+	if s.Enabled() {
+		var err error
+		client, err = vault.NewClient(vault.Config{
+			URL:             s.URL,
+			Namespace:       s.Namespace,
+			AuthMethod:      s.AuthMethod,
+			Token:           s.Token,
+			AppRoleMount:    s.AppRoleMount,
+			AppRoleRoleID:   s.AppRoleRoleID,
+			AppRoleSecretID: s.AppRoleSecretID,
+			Timeout:         s.Timeout,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.client, e.timeout = client, s.Timeout
+	return nil
+}
+
+func (e *vaultExpander) Expand(s string) (string, error) {
+	e.mu.RLock()
+	client, timeout := e.client, e.timeout
+	e.mu.RUnlock()
+	if client == nil {
+		return "", fmt.Errorf("vault is not configured, set auth_method in the [keystore.vault] section")
+	}
+
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return "", fmt.Errorf("invalid vault secret %q, expected <secrets engine>:<path>:<field>", s)
+	}
+	engine, path, field := parts[0], parts[1], parts[2]
+	if engine != "kv" {
+		return "", fmt.Errorf("unsupported secrets engine %q, only kv is supported", engine)
+	}
+	mount, secretPath, ok := cut(strings.Trim(path, "/"), "/")
+	if !ok {
+		return "", fmt.Errorf("invalid vault secret path %q, expected <mount>/<path>", path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return client.ReadKVField(ctx, mount, secretPath, field)
+}
+
+// cut is strings.Cut, which isn't available with the version of Go of the module.
+func cut(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -110,6 +110,35 @@ export const Math: FC<Props> = ({ labelWidth, onChange, query, onRunQuery }) =>
             name="floor"
             description="rounds the number down to the nearest integer value. It's able to operate on series or escalar values."
           />
+          <DocumentedFunction
+            name="exp, sqrt"
+            description="returns e raised to the power of its argument, or the square root of its argument. It's able to operate on series or scalar values."
+          />
+          <DocumentedFunction
+            name="pow"
+            description="raises its first argument to the power of the constant second argument, for example pow($A, 2)"
+          />
+          <DocumentedFunction
+            name="clamp_min, clamp_max"
+            description="limits values to the constant second argument, for example clamp_min($A, 0)"
+          />
+          <DocumentedFunction
+            name="delta, increase, rate"
+            description="returns the difference, counter increase, or per-second counter increase between each point of a series and the previous one"
+          />
+          <DocumentedFunction
+            name="moving_avg, moving_sum"
+            description='returns the average or sum of the points of a series in a window ending at each point, for example moving_avg($A, "5m")'
+          />
+          <DocumentedFunction name="cumsum" description="returns the running total of a series" />
+          <DocumentedFunction
+            name="time_shift"
+            description='moves the points of a series forward in time, for example $A - time_shift($A, "1d")'
+          />
+          <DocumentedFunction
+            name="timestamp, hour"
+            description="returns the time of each point of a series as seconds since the epoch, or its hour of the day in UTC"
+          />
         </div>
         <div>
           See our additional documentation on{' '}